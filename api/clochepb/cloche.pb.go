@@ -30,6 +30,8 @@ type RunWorkflowRequest struct {
 	KeepContainer bool                   `protobuf:"varint,5,opt,name=keep_container,json=keepContainer,proto3" json:"keep_container,omitempty"`
 	Title         string                 `protobuf:"bytes,6,opt,name=title,proto3" json:"title,omitempty"`
 	IssueId       string                 `protobuf:"bytes,7,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	// Overrides for the workflow's declared params ("cloche run --param").
+	Params        map[string]string `protobuf:"bytes,8,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -113,6 +115,13 @@ func (x *RunWorkflowRequest) GetIssueId() string {
 	return ""
 }
 
+func (x *RunWorkflowRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
 type RunWorkflowResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
@@ -173,6 +182,83 @@ func (x *RunWorkflowResponse) GetAttemptId() string {
 	return ""
 }
 
+type RunWorkflowSyncResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RunId     string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	TaskId    string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	AttemptId string                 `protobuf:"bytes,3,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	// Terminal run state: "succeeded", "failed", or "cancelled".
+	State         string `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	ErrorMessage  string `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunWorkflowSyncResponse) Reset() {
+	*x = RunWorkflowSyncResponse{}
+	mi := &file_cloche_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunWorkflowSyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunWorkflowSyncResponse) ProtoMessage() {}
+
+func (x *RunWorkflowSyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunWorkflowSyncResponse.ProtoReflect.Descriptor instead.
+func (*RunWorkflowSyncResponse) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RunWorkflowSyncResponse) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *RunWorkflowSyncResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *RunWorkflowSyncResponse) GetAttemptId() string {
+	if x != nil {
+		return x.AttemptId
+	}
+	return ""
+}
+
+func (x *RunWorkflowSyncResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *RunWorkflowSyncResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
 type GetStatusRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	RunId string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
@@ -185,7 +271,7 @@ type GetStatusRequest struct {
 
 func (x *GetStatusRequest) Reset() {
 	*x = GetStatusRequest{}
-	mi := &file_cloche_proto_msgTypes[2]
+	mi := &file_cloche_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -197,7 +283,7 @@ func (x *GetStatusRequest) String() string {
 func (*GetStatusRequest) ProtoMessage() {}
 
 func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[2]
+	mi := &file_cloche_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -210,7 +296,7 @@ func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
 func (*GetStatusRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{2}
+	return file_cloche_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *GetStatusRequest) GetRunId() string {
@@ -245,14 +331,19 @@ type GetStatusResponse struct {
 	// Populated when state == "waiting": RFC3339 timestamp of the last poll invocation.
 	LastPollAt string `protobuf:"bytes,13,opt,name=last_poll_at,json=lastPollAt,proto3" json:"last_poll_at,omitempty"`
 	// Populated when state == "waiting": number of times the poll script has been invoked.
-	PollCount     int32 `protobuf:"varint,14,opt,name=poll_count,json=pollCount,proto3" json:"poll_count,omitempty"`
+	PollCount int32 `protobuf:"varint,14,opt,name=poll_count,json=pollCount,proto3" json:"poll_count,omitempty"`
+	// RFC3339 timestamp of when the run started; empty if it has not started.
+	StartedAt string `protobuf:"bytes,15,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	// Populated when state == "pending": 1-based place in the workflow's
+	// max_concurrent queue. Zero when the run is not queued.
+	QueuePosition int32 `protobuf:"varint,16,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetStatusResponse) Reset() {
 	*x = GetStatusResponse{}
-	mi := &file_cloche_proto_msgTypes[3]
+	mi := &file_cloche_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -264,7 +355,7 @@ func (x *GetStatusResponse) String() string {
 func (*GetStatusResponse) ProtoMessage() {}
 
 func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[3]
+	mi := &file_cloche_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -277,7 +368,7 @@ func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
 func (*GetStatusResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{3}
+	return file_cloche_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GetStatusResponse) GetRunId() string {
@@ -378,6 +469,20 @@ func (x *GetStatusResponse) GetPollCount() int32 {
 	return 0
 }
 
+func (x *GetStatusResponse) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *GetStatusResponse) GetQueuePosition() int32 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
 type StepExecutionStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	StepName      string                 `protobuf:"bytes,1,opt,name=step_name,json=stepName,proto3" json:"step_name,omitempty"`
@@ -387,14 +492,16 @@ type StepExecutionStatus struct {
 	InputTokens   int64                  `protobuf:"varint,5,opt,name=input_tokens,json=inputTokens,proto3" json:"input_tokens,omitempty"`
 	OutputTokens  int64                  `protobuf:"varint,6,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"`
 	AgentName     string                 `protobuf:"bytes,7,opt,name=agent_name,json=agentName,proto3" json:"agent_name,omitempty"`
-	Skipped       bool                   `protobuf:"varint,8,opt,name=skipped,proto3" json:"skipped,omitempty"` // true when the step's skip script bypassed execution
+	Skipped       bool                   `protobuf:"varint,8,opt,name=skipped,proto3" json:"skipped,omitempty"`                          // true when the step's skip script bypassed execution
+	PromptPath    string                 `protobuf:"bytes,9,opt,name=prompt_path,json=promptPath,proto3" json:"prompt_path,omitempty"`   // project-relative saved prompt, when [runs] save_prompts is set
+	ExitCode      *int32                 `protobuf:"varint,10,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"` // exit code of the step's process; unset when unknown
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StepExecutionStatus) Reset() {
 	*x = StepExecutionStatus{}
-	mi := &file_cloche_proto_msgTypes[4]
+	mi := &file_cloche_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -406,7 +513,7 @@ func (x *StepExecutionStatus) String() string {
 func (*StepExecutionStatus) ProtoMessage() {}
 
 func (x *StepExecutionStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[4]
+	mi := &file_cloche_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -419,7 +526,7 @@ func (x *StepExecutionStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StepExecutionStatus.ProtoReflect.Descriptor instead.
 func (*StepExecutionStatus) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{4}
+	return file_cloche_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *StepExecutionStatus) GetStepName() string {
@@ -478,6 +585,20 @@ func (x *StepExecutionStatus) GetSkipped() bool {
 	return false
 }
 
+func (x *StepExecutionStatus) GetPromptPath() string {
+	if x != nil {
+		return x.PromptPath
+	}
+	return ""
+}
+
+func (x *StepExecutionStatus) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
 type StreamLogsRequest struct {
 	state    protoimpl.MessageState `protogen:"open.v1"`
 	RunId    string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
@@ -488,11 +609,9 @@ type StreamLogsRequest struct {
 	Limit int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"` // When > 0, display only the last N lines of output.
 	// Passed via gRPC metadata header "x-cloche-limit" until proto is regenerated.
 	// id accepts colon-delimited IDs at any level:
-	//
-	//	task_id                        — stream logs for the latest attempt of a task
-	//	task_id:attempt_id             — stream logs for a specific attempt
-	//	task_id:attempt_id:step_name   — stream logs for a specific step
-	//
+	//   task_id                        — stream logs for the latest attempt of a task
+	//   task_id:attempt_id             — stream logs for a specific attempt
+	//   task_id:attempt_id:step_name   — stream logs for a specific step
 	// When set, takes priority over run_id + step_name.
 	Id            string `protobuf:"bytes,6,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
@@ -501,7 +620,7 @@ type StreamLogsRequest struct {
 
 func (x *StreamLogsRequest) Reset() {
 	*x = StreamLogsRequest{}
-	mi := &file_cloche_proto_msgTypes[5]
+	mi := &file_cloche_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -513,7 +632,7 @@ func (x *StreamLogsRequest) String() string {
 func (*StreamLogsRequest) ProtoMessage() {}
 
 func (x *StreamLogsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[5]
+	mi := &file_cloche_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -526,7 +645,7 @@ func (x *StreamLogsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamLogsRequest.ProtoReflect.Descriptor instead.
 func (*StreamLogsRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{5}
+	return file_cloche_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *StreamLogsRequest) GetRunId() string {
@@ -584,7 +703,7 @@ type LogEntry struct {
 
 func (x *LogEntry) Reset() {
 	*x = LogEntry{}
-	mi := &file_cloche_proto_msgTypes[6]
+	mi := &file_cloche_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -596,7 +715,7 @@ func (x *LogEntry) String() string {
 func (*LogEntry) ProtoMessage() {}
 
 func (x *LogEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[6]
+	mi := &file_cloche_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -609,7 +728,7 @@ func (x *LogEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
 func (*LogEntry) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{6}
+	return file_cloche_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *LogEntry) GetType() string {
@@ -659,7 +778,7 @@ type StopRunRequest struct {
 
 func (x *StopRunRequest) Reset() {
 	*x = StopRunRequest{}
-	mi := &file_cloche_proto_msgTypes[7]
+	mi := &file_cloche_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -671,7 +790,7 @@ func (x *StopRunRequest) String() string {
 func (*StopRunRequest) ProtoMessage() {}
 
 func (x *StopRunRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[7]
+	mi := &file_cloche_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -684,7 +803,7 @@ func (x *StopRunRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopRunRequest.ProtoReflect.Descriptor instead.
 func (*StopRunRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{7}
+	return file_cloche_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *StopRunRequest) GetTaskId() string {
@@ -702,7 +821,7 @@ type StopRunResponse struct {
 
 func (x *StopRunResponse) Reset() {
 	*x = StopRunResponse{}
-	mi := &file_cloche_proto_msgTypes[8]
+	mi := &file_cloche_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -714,7 +833,7 @@ func (x *StopRunResponse) String() string {
 func (*StopRunResponse) ProtoMessage() {}
 
 func (x *StopRunResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[8]
+	mi := &file_cloche_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -727,7 +846,7 @@ func (x *StopRunResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopRunResponse.ProtoReflect.Descriptor instead.
 func (*StopRunResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{8}
+	return file_cloche_proto_rawDescGZIP(), []int{9}
 }
 
 type ShutdownRequest struct {
@@ -739,7 +858,7 @@ type ShutdownRequest struct {
 
 func (x *ShutdownRequest) Reset() {
 	*x = ShutdownRequest{}
-	mi := &file_cloche_proto_msgTypes[9]
+	mi := &file_cloche_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -751,7 +870,7 @@ func (x *ShutdownRequest) String() string {
 func (*ShutdownRequest) ProtoMessage() {}
 
 func (x *ShutdownRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[9]
+	mi := &file_cloche_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -764,7 +883,7 @@ func (x *ShutdownRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShutdownRequest.ProtoReflect.Descriptor instead.
 func (*ShutdownRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{9}
+	return file_cloche_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ShutdownRequest) GetForce() bool {
@@ -782,7 +901,7 @@ type ShutdownResponse struct {
 
 func (x *ShutdownResponse) Reset() {
 	*x = ShutdownResponse{}
-	mi := &file_cloche_proto_msgTypes[10]
+	mi := &file_cloche_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -794,7 +913,7 @@ func (x *ShutdownResponse) String() string {
 func (*ShutdownResponse) ProtoMessage() {}
 
 func (x *ShutdownResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[10]
+	mi := &file_cloche_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -807,7 +926,7 @@ func (x *ShutdownResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShutdownResponse.ProtoReflect.Descriptor instead.
 func (*ShutdownResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{10}
+	return file_cloche_proto_rawDescGZIP(), []int{11}
 }
 
 type DeleteContainerRequest struct {
@@ -819,7 +938,7 @@ type DeleteContainerRequest struct {
 
 func (x *DeleteContainerRequest) Reset() {
 	*x = DeleteContainerRequest{}
-	mi := &file_cloche_proto_msgTypes[11]
+	mi := &file_cloche_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -831,7 +950,7 @@ func (x *DeleteContainerRequest) String() string {
 func (*DeleteContainerRequest) ProtoMessage() {}
 
 func (x *DeleteContainerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[11]
+	mi := &file_cloche_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -844,7 +963,7 @@ func (x *DeleteContainerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteContainerRequest.ProtoReflect.Descriptor instead.
 func (*DeleteContainerRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{11}
+	return file_cloche_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *DeleteContainerRequest) GetId() string {
@@ -862,7 +981,7 @@ type DeleteContainerResponse struct {
 
 func (x *DeleteContainerResponse) Reset() {
 	*x = DeleteContainerResponse{}
-	mi := &file_cloche_proto_msgTypes[12]
+	mi := &file_cloche_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -874,7 +993,7 @@ func (x *DeleteContainerResponse) String() string {
 func (*DeleteContainerResponse) ProtoMessage() {}
 
 func (x *DeleteContainerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[12]
+	mi := &file_cloche_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -887,7 +1006,7 @@ func (x *DeleteContainerResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteContainerResponse.ProtoReflect.Descriptor instead.
 func (*DeleteContainerResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{12}
+	return file_cloche_proto_rawDescGZIP(), []int{13}
 }
 
 type ExtractRunRequest struct {
@@ -902,7 +1021,7 @@ type ExtractRunRequest struct {
 
 func (x *ExtractRunRequest) Reset() {
 	*x = ExtractRunRequest{}
-	mi := &file_cloche_proto_msgTypes[13]
+	mi := &file_cloche_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -914,7 +1033,7 @@ func (x *ExtractRunRequest) String() string {
 func (*ExtractRunRequest) ProtoMessage() {}
 
 func (x *ExtractRunRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[13]
+	mi := &file_cloche_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -927,7 +1046,7 @@ func (x *ExtractRunRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExtractRunRequest.ProtoReflect.Descriptor instead.
 func (*ExtractRunRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{13}
+	return file_cloche_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *ExtractRunRequest) GetId() string {
@@ -969,7 +1088,7 @@ type ExtractRunResponse struct {
 
 func (x *ExtractRunResponse) Reset() {
 	*x = ExtractRunResponse{}
-	mi := &file_cloche_proto_msgTypes[14]
+	mi := &file_cloche_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -981,7 +1100,7 @@ func (x *ExtractRunResponse) String() string {
 func (*ExtractRunResponse) ProtoMessage() {}
 
 func (x *ExtractRunResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[14]
+	mi := &file_cloche_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -994,7 +1113,7 @@ func (x *ExtractRunResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExtractRunResponse.ProtoReflect.Descriptor instead.
 func (*ExtractRunResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{14}
+	return file_cloche_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *ExtractRunResponse) GetTargetDir() string {
@@ -1018,32 +1137,31 @@ func (x *ExtractRunResponse) GetCommitSha() string {
 	return ""
 }
 
-type ListRunsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	All           bool                   `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
-	ProjectDir    string                 `protobuf:"bytes,2,opt,name=project_dir,json=projectDir,proto3" json:"project_dir,omitempty"`
-	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
-	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
-	TaskId        string                 `protobuf:"bytes,5,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type PruneRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ProjectDir           string                 `protobuf:"bytes,1,opt,name=project_dir,json=projectDir,proto3" json:"project_dir,omitempty"`                                    // empty prunes every project
+	RunsOlderThanSeconds int64                  `protobuf:"varint,2,opt,name=runs_older_than_seconds,json=runsOlderThanSeconds,proto3" json:"runs_older_than_seconds,omitempty"` // delete finished runs completed longer ago than this; 0 keeps all runs
+	Snapshots            bool                   `protobuf:"varint,3,opt,name=snapshots,proto3" json:"snapshots,omitempty"`                                                       // remove snapshots for attempts with no remaining runs
+	Containers           bool                   `protobuf:"varint,4,opt,name=containers,proto3" json:"containers,omitempty"`                                                     // remove containers of finished runs that were not kept
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
-func (x *ListRunsRequest) Reset() {
-	*x = ListRunsRequest{}
-	mi := &file_cloche_proto_msgTypes[15]
+func (x *PruneRequest) Reset() {
+	*x = PruneRequest{}
+	mi := &file_cloche_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListRunsRequest) String() string {
+func (x *PruneRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListRunsRequest) ProtoMessage() {}
+func (*PruneRequest) ProtoMessage() {}
 
-func (x *ListRunsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[15]
+func (x *PruneRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1054,68 +1172,64 @@ func (x *ListRunsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListRunsRequest.ProtoReflect.Descriptor instead.
-func (*ListRunsRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{15}
-}
-
-func (x *ListRunsRequest) GetAll() bool {
-	if x != nil {
-		return x.All
-	}
-	return false
+// Deprecated: Use PruneRequest.ProtoReflect.Descriptor instead.
+func (*PruneRequest) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *ListRunsRequest) GetProjectDir() string {
+func (x *PruneRequest) GetProjectDir() string {
 	if x != nil {
 		return x.ProjectDir
 	}
 	return ""
 }
 
-func (x *ListRunsRequest) GetState() string {
+func (x *PruneRequest) GetRunsOlderThanSeconds() int64 {
 	if x != nil {
-		return x.State
+		return x.RunsOlderThanSeconds
 	}
-	return ""
+	return 0
 }
 
-func (x *ListRunsRequest) GetLimit() int32 {
+func (x *PruneRequest) GetSnapshots() bool {
 	if x != nil {
-		return x.Limit
+		return x.Snapshots
 	}
-	return 0
+	return false
 }
 
-func (x *ListRunsRequest) GetTaskId() string {
+func (x *PruneRequest) GetContainers() bool {
 	if x != nil {
-		return x.TaskId
+		return x.Containers
 	}
-	return ""
+	return false
 }
 
-type ListRunsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Runs          []*RunSummary          `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type PruneResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	DeletedRuns       []string               `protobuf:"bytes,1,rep,name=deleted_runs,json=deletedRuns,proto3" json:"deleted_runs,omitempty"`
+	RemovedSnapshots  []string               `protobuf:"bytes,2,rep,name=removed_snapshots,json=removedSnapshots,proto3" json:"removed_snapshots,omitempty"` // snapshot directory paths
+	RemovedContainers []string               `protobuf:"bytes,3,rep,name=removed_containers,json=removedContainers,proto3" json:"removed_containers,omitempty"`
+	Errors            []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"` // per-item failures; pruning continues past them
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
-func (x *ListRunsResponse) Reset() {
-	*x = ListRunsResponse{}
-	mi := &file_cloche_proto_msgTypes[16]
+func (x *PruneResponse) Reset() {
+	*x = PruneResponse{}
+	mi := &file_cloche_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListRunsResponse) String() string {
+func (x *PruneResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListRunsResponse) ProtoMessage() {}
+func (*PruneResponse) ProtoMessage() {}
 
-func (x *ListRunsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[16]
+func (x *PruneResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1126,55 +1240,493 @@ func (x *ListRunsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListRunsResponse.ProtoReflect.Descriptor instead.
-func (*ListRunsResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use PruneResponse.ProtoReflect.Descriptor instead.
+func (*PruneResponse) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *ListRunsResponse) GetRuns() []*RunSummary {
+func (x *PruneResponse) GetDeletedRuns() []string {
 	if x != nil {
-		return x.Runs
+		return x.DeletedRuns
 	}
 	return nil
 }
 
-type RunSummary struct {
-	state        protoimpl.MessageState `protogen:"open.v1"`
-	RunId        string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
-	WorkflowName string                 `protobuf:"bytes,2,opt,name=workflow_name,json=workflowName,proto3" json:"workflow_name,omitempty"`
-	State        string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
-	StartedAt    string                 `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	ErrorMessage string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
-	ContainerId  string                 `protobuf:"bytes,6,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	Title        string                 `protobuf:"bytes,7,opt,name=title,proto3" json:"title,omitempty"`
-	IsHost       bool                   `protobuf:"varint,8,opt,name=is_host,json=isHost,proto3" json:"is_host,omitempty"`
-	ProjectDir   string                 `protobuf:"bytes,9,opt,name=project_dir,json=projectDir,proto3" json:"project_dir,omitempty"`
-	TaskId       string                 `protobuf:"bytes,10,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	// Populated when state == "waiting": the human step being polled.
-	WaitingStep string `protobuf:"bytes,11,opt,name=waiting_step,json=waitingStep,proto3" json:"waiting_step,omitempty"`
-	// Populated when state == "waiting": RFC3339 timestamp of the last poll invocation.
-	LastPollAt string `protobuf:"bytes,12,opt,name=last_poll_at,json=lastPollAt,proto3" json:"last_poll_at,omitempty"`
-	// Populated when state == "waiting": number of times the poll script has been invoked.
-	PollCount     int32 `protobuf:"varint,13,opt,name=poll_count,json=pollCount,proto3" json:"poll_count,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *PruneResponse) GetRemovedSnapshots() []string {
+	if x != nil {
+		return x.RemovedSnapshots
+	}
+	return nil
 }
 
-func (x *RunSummary) Reset() {
-	*x = RunSummary{}
-	mi := &file_cloche_proto_msgTypes[17]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *PruneResponse) GetRemovedContainers() []string {
+	if x != nil {
+		return x.RemovedContainers
+	}
+	return nil
 }
 
-func (x *RunSummary) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
+func (x *PruneResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type DeleteRunsRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ProjectDir       string                 `protobuf:"bytes,1,opt,name=project_dir,json=projectDir,proto3" json:"project_dir,omitempty"`                      // empty matches every project
+	State            string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`                                                  // succeeded, failed or cancelled; empty matches any finished run
+	WorkflowName     string                 `protobuf:"bytes,3,opt,name=workflow_name,json=workflowName,proto3" json:"workflow_name,omitempty"`                // empty matches every workflow
+	OlderThanSeconds int64                  `protobuf:"varint,4,opt,name=older_than_seconds,json=olderThanSeconds,proto3" json:"older_than_seconds,omitempty"` // only runs completed longer ago than this; 0 = any age
+	DryRun           bool                   `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`                                 // list the matching runs without deleting them
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DeleteRunsRequest) Reset() {
+	*x = DeleteRunsRequest{}
+	mi := &file_cloche_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRunsRequest) ProtoMessage() {}
+
+func (x *DeleteRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRunsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRunsRequest) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeleteRunsRequest) GetProjectDir() string {
+	if x != nil {
+		return x.ProjectDir
+	}
+	return ""
+}
+
+func (x *DeleteRunsRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *DeleteRunsRequest) GetWorkflowName() string {
+	if x != nil {
+		return x.WorkflowName
+	}
+	return ""
+}
+
+func (x *DeleteRunsRequest) GetOlderThanSeconds() int64 {
+	if x != nil {
+		return x.OlderThanSeconds
+	}
+	return 0
+}
+
+func (x *DeleteRunsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type DeleteRunsResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Runs             []*RunSummary          `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`                                                 // runs deleted, or that would be with dry_run
+	RemovedArtifacts []string               `protobuf:"bytes,2,rep,name=removed_artifacts,json=removedArtifacts,proto3" json:"removed_artifacts,omitempty"` // on-disk paths removed
+	Errors           []string               `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`                                             // per-item failures; deletion continues past them
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DeleteRunsResponse) Reset() {
+	*x = DeleteRunsResponse{}
+	mi := &file_cloche_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRunsResponse) ProtoMessage() {}
+
+func (x *DeleteRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRunsResponse.ProtoReflect.Descriptor instead.
+func (*DeleteRunsResponse) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteRunsResponse) GetRuns() []*RunSummary {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+func (x *DeleteRunsResponse) GetRemovedArtifacts() []string {
+	if x != nil {
+		return x.RemovedArtifacts
+	}
+	return nil
+}
+
+func (x *DeleteRunsResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type EvolveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectDir    string                 `protobuf:"bytes,1,opt,name=project_dir,json=projectDir,proto3" json:"project_dir,omitempty"`
+	WorkflowName  string                 `protobuf:"bytes,2,opt,name=workflow_name,json=workflowName,proto3" json:"workflow_name,omitempty"`
+	SinceRunId    string                 `protobuf:"bytes,3,opt,name=since_run_id,json=sinceRunId,proto3" json:"since_run_id,omitempty"` // collect runs started after this run; empty uses the last evolution
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvolveRequest) Reset() {
+	*x = EvolveRequest{}
+	mi := &file_cloche_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvolveRequest) ProtoMessage() {}
+
+func (x *EvolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvolveRequest.ProtoReflect.Descriptor instead.
+func (*EvolveRequest) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *EvolveRequest) GetProjectDir() string {
+	if x != nil {
+		return x.ProjectDir
+	}
+	return ""
+}
+
+func (x *EvolveRequest) GetWorkflowName() string {
+	if x != nil {
+		return x.WorkflowName
+	}
+	return ""
+}
+
+func (x *EvolveRequest) GetSinceRunId() string {
+	if x != nil {
+		return x.SinceRunId
+	}
+	return ""
+}
+
+type EvolveResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	EvolutionId    string                 `protobuf:"bytes,1,opt,name=evolution_id,json=evolutionId,proto3" json:"evolution_id,omitempty"`
+	Classification string                 `protobuf:"bytes,2,opt,name=classification,proto3" json:"classification,omitempty"`
+	RunsCollected  int32                  `protobuf:"varint,3,opt,name=runs_collected,json=runsCollected,proto3" json:"runs_collected,omitempty"`
+	Changes        int32                  `protobuf:"varint,4,opt,name=changes,proto3" json:"changes,omitempty"`
+	KnowledgeDelta string                 `protobuf:"bytes,5,opt,name=knowledge_delta,json=knowledgeDelta,proto3" json:"knowledge_delta,omitempty"`
+	// One line per change, e.g. "add_step .cloche/develop.cloche: added step
+	// lint; wired lint:fail -> abort".
+	ChangeDetails []string `protobuf:"bytes,6,rep,name=change_details,json=changeDetails,proto3" json:"change_details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvolveResponse) Reset() {
+	*x = EvolveResponse{}
+	mi := &file_cloche_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvolveResponse) ProtoMessage() {}
+
+func (x *EvolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvolveResponse.ProtoReflect.Descriptor instead.
+func (*EvolveResponse) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *EvolveResponse) GetEvolutionId() string {
+	if x != nil {
+		return x.EvolutionId
+	}
+	return ""
+}
+
+func (x *EvolveResponse) GetClassification() string {
+	if x != nil {
+		return x.Classification
+	}
+	return ""
+}
+
+func (x *EvolveResponse) GetRunsCollected() int32 {
+	if x != nil {
+		return x.RunsCollected
+	}
+	return 0
+}
+
+func (x *EvolveResponse) GetChanges() int32 {
+	if x != nil {
+		return x.Changes
+	}
+	return 0
+}
+
+func (x *EvolveResponse) GetKnowledgeDelta() string {
+	if x != nil {
+		return x.KnowledgeDelta
+	}
+	return ""
+}
+
+func (x *EvolveResponse) GetChangeDetails() []string {
+	if x != nil {
+		return x.ChangeDetails
+	}
+	return nil
+}
+
+type ListRunsRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	All        bool                   `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
+	ProjectDir string                 `protobuf:"bytes,2,opt,name=project_dir,json=projectDir,proto3" json:"project_dir,omitempty"`
+	State      string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	Limit      int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	TaskId     string                 `protobuf:"bytes,5,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// sort is "started_at" (default), "completed_at" or "updated_at".
+	Sort          string `protobuf:"bytes,6,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunsRequest) Reset() {
+	*x = ListRunsRequest{}
+	mi := &file_cloche_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsRequest) ProtoMessage() {}
+
+func (x *ListRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListRunsRequest) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListRunsRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *ListRunsRequest) GetProjectDir() string {
+	if x != nil {
+		return x.ProjectDir
+	}
+	return ""
+}
+
+func (x *ListRunsRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ListRunsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRunsRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ListRunsRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type ListRunsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          []*RunSummary          `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunsResponse) Reset() {
+	*x = ListRunsResponse{}
+	mi := &file_cloche_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsResponse) ProtoMessage() {}
+
+func (x *ListRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cloche_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListRunsResponse) Descriptor() ([]byte, []int) {
+	return file_cloche_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListRunsResponse) GetRuns() []*RunSummary {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+type RunSummary struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	RunId        string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	WorkflowName string                 `protobuf:"bytes,2,opt,name=workflow_name,json=workflowName,proto3" json:"workflow_name,omitempty"`
+	State        string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	StartedAt    string                 `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	ErrorMessage string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	ContainerId  string                 `protobuf:"bytes,6,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Title        string                 `protobuf:"bytes,7,opt,name=title,proto3" json:"title,omitempty"`
+	IsHost       bool                   `protobuf:"varint,8,opt,name=is_host,json=isHost,proto3" json:"is_host,omitempty"`
+	ProjectDir   string                 `protobuf:"bytes,9,opt,name=project_dir,json=projectDir,proto3" json:"project_dir,omitempty"`
+	TaskId       string                 `protobuf:"bytes,10,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// Populated when state == "waiting": the human step being polled.
+	WaitingStep string `protobuf:"bytes,11,opt,name=waiting_step,json=waitingStep,proto3" json:"waiting_step,omitempty"`
+	// Populated when state == "waiting": RFC3339 timestamp of the last poll invocation.
+	LastPollAt string `protobuf:"bytes,12,opt,name=last_poll_at,json=lastPollAt,proto3" json:"last_poll_at,omitempty"`
+	// Populated when state == "waiting": number of times the poll script has been invoked.
+	PollCount int32 `protobuf:"varint,13,opt,name=poll_count,json=pollCount,proto3" json:"poll_count,omitempty"`
+	// Populated for finished runs: distinct steps that completed, and step
+	// executions including retries.
+	StepCount    int32 `protobuf:"varint,14,opt,name=step_count,json=stepCount,proto3" json:"step_count,omitempty"`
+	AttemptCount int32 `protobuf:"varint,15,opt,name=attempt_count,json=attemptCount,proto3" json:"attempt_count,omitempty"`
+	// Populated for finished runs: seconds from start to completion.
+	DurationSeconds int64 `protobuf:"varint,16,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RunSummary) Reset() {
+	*x = RunSummary{}
+	mi := &file_cloche_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
 func (*RunSummary) ProtoMessage() {}
 
 func (x *RunSummary) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[17]
+	mi := &file_cloche_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1187,7 +1739,7 @@ func (x *RunSummary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RunSummary.ProtoReflect.Descriptor instead.
 func (*RunSummary) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{17}
+	return file_cloche_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *RunSummary) GetRunId() string {
@@ -1281,6 +1833,27 @@ func (x *RunSummary) GetPollCount() int32 {
 	return 0
 }
 
+func (x *RunSummary) GetStepCount() int32 {
+	if x != nil {
+		return x.StepCount
+	}
+	return 0
+}
+
+func (x *RunSummary) GetAttemptCount() int32 {
+	if x != nil {
+		return x.AttemptCount
+	}
+	return 0
+}
+
+func (x *RunSummary) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
 type EnableLoopRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ProjectDir    string                 `protobuf:"bytes,1,opt,name=project_dir,json=projectDir,proto3" json:"project_dir,omitempty"`
@@ -1291,7 +1864,7 @@ type EnableLoopRequest struct {
 
 func (x *EnableLoopRequest) Reset() {
 	*x = EnableLoopRequest{}
-	mi := &file_cloche_proto_msgTypes[18]
+	mi := &file_cloche_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1303,7 +1876,7 @@ func (x *EnableLoopRequest) String() string {
 func (*EnableLoopRequest) ProtoMessage() {}
 
 func (x *EnableLoopRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[18]
+	mi := &file_cloche_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1316,7 +1889,7 @@ func (x *EnableLoopRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnableLoopRequest.ProtoReflect.Descriptor instead.
 func (*EnableLoopRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{18}
+	return file_cloche_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *EnableLoopRequest) GetProjectDir() string {
@@ -1341,7 +1914,7 @@ type EnableLoopResponse struct {
 
 func (x *EnableLoopResponse) Reset() {
 	*x = EnableLoopResponse{}
-	mi := &file_cloche_proto_msgTypes[19]
+	mi := &file_cloche_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1353,7 +1926,7 @@ func (x *EnableLoopResponse) String() string {
 func (*EnableLoopResponse) ProtoMessage() {}
 
 func (x *EnableLoopResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[19]
+	mi := &file_cloche_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1366,7 +1939,7 @@ func (x *EnableLoopResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnableLoopResponse.ProtoReflect.Descriptor instead.
 func (*EnableLoopResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{19}
+	return file_cloche_proto_rawDescGZIP(), []int{26}
 }
 
 type DisableLoopRequest struct {
@@ -1378,7 +1951,7 @@ type DisableLoopRequest struct {
 
 func (x *DisableLoopRequest) Reset() {
 	*x = DisableLoopRequest{}
-	mi := &file_cloche_proto_msgTypes[20]
+	mi := &file_cloche_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1390,7 +1963,7 @@ func (x *DisableLoopRequest) String() string {
 func (*DisableLoopRequest) ProtoMessage() {}
 
 func (x *DisableLoopRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[20]
+	mi := &file_cloche_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1403,7 +1976,7 @@ func (x *DisableLoopRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DisableLoopRequest.ProtoReflect.Descriptor instead.
 func (*DisableLoopRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{20}
+	return file_cloche_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *DisableLoopRequest) GetProjectDir() string {
@@ -1421,7 +1994,7 @@ type DisableLoopResponse struct {
 
 func (x *DisableLoopResponse) Reset() {
 	*x = DisableLoopResponse{}
-	mi := &file_cloche_proto_msgTypes[21]
+	mi := &file_cloche_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1433,7 +2006,7 @@ func (x *DisableLoopResponse) String() string {
 func (*DisableLoopResponse) ProtoMessage() {}
 
 func (x *DisableLoopResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[21]
+	mi := &file_cloche_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1446,7 +2019,7 @@ func (x *DisableLoopResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DisableLoopResponse.ProtoReflect.Descriptor instead.
 func (*DisableLoopResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{21}
+	return file_cloche_proto_rawDescGZIP(), []int{28}
 }
 
 type ResumeLoopRequest struct {
@@ -1458,7 +2031,7 @@ type ResumeLoopRequest struct {
 
 func (x *ResumeLoopRequest) Reset() {
 	*x = ResumeLoopRequest{}
-	mi := &file_cloche_proto_msgTypes[22]
+	mi := &file_cloche_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1470,7 +2043,7 @@ func (x *ResumeLoopRequest) String() string {
 func (*ResumeLoopRequest) ProtoMessage() {}
 
 func (x *ResumeLoopRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[22]
+	mi := &file_cloche_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1483,7 +2056,7 @@ func (x *ResumeLoopRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResumeLoopRequest.ProtoReflect.Descriptor instead.
 func (*ResumeLoopRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{22}
+	return file_cloche_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *ResumeLoopRequest) GetProjectDir() string {
@@ -1501,7 +2074,7 @@ type ResumeLoopResponse struct {
 
 func (x *ResumeLoopResponse) Reset() {
 	*x = ResumeLoopResponse{}
-	mi := &file_cloche_proto_msgTypes[23]
+	mi := &file_cloche_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1513,7 +2086,7 @@ func (x *ResumeLoopResponse) String() string {
 func (*ResumeLoopResponse) ProtoMessage() {}
 
 func (x *ResumeLoopResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[23]
+	mi := &file_cloche_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1526,7 +2099,7 @@ func (x *ResumeLoopResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResumeLoopResponse.ProtoReflect.Descriptor instead.
 func (*ResumeLoopResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{23}
+	return file_cloche_proto_rawDescGZIP(), []int{30}
 }
 
 type QuiesceRunsRequest struct {
@@ -1538,7 +2111,7 @@ type QuiesceRunsRequest struct {
 
 func (x *QuiesceRunsRequest) Reset() {
 	*x = QuiesceRunsRequest{}
-	mi := &file_cloche_proto_msgTypes[24]
+	mi := &file_cloche_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1550,7 +2123,7 @@ func (x *QuiesceRunsRequest) String() string {
 func (*QuiesceRunsRequest) ProtoMessage() {}
 
 func (x *QuiesceRunsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[24]
+	mi := &file_cloche_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1563,7 +2136,7 @@ func (x *QuiesceRunsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QuiesceRunsRequest.ProtoReflect.Descriptor instead.
 func (*QuiesceRunsRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{24}
+	return file_cloche_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *QuiesceRunsRequest) GetProjectDir() string {
@@ -1583,7 +2156,7 @@ type QuiesceRunsResponse struct {
 
 func (x *QuiesceRunsResponse) Reset() {
 	*x = QuiesceRunsResponse{}
-	mi := &file_cloche_proto_msgTypes[25]
+	mi := &file_cloche_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1595,7 +2168,7 @@ func (x *QuiesceRunsResponse) String() string {
 func (*QuiesceRunsResponse) ProtoMessage() {}
 
 func (x *QuiesceRunsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[25]
+	mi := &file_cloche_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1608,7 +2181,7 @@ func (x *QuiesceRunsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QuiesceRunsResponse.ProtoReflect.Descriptor instead.
 func (*QuiesceRunsResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{25}
+	return file_cloche_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *QuiesceRunsResponse) GetParkedCount() int32 {
@@ -1628,7 +2201,7 @@ type GetProjectInfoRequest struct {
 
 func (x *GetProjectInfoRequest) Reset() {
 	*x = GetProjectInfoRequest{}
-	mi := &file_cloche_proto_msgTypes[26]
+	mi := &file_cloche_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1640,7 +2213,7 @@ func (x *GetProjectInfoRequest) String() string {
 func (*GetProjectInfoRequest) ProtoMessage() {}
 
 func (x *GetProjectInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[26]
+	mi := &file_cloche_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1653,7 +2226,7 @@ func (x *GetProjectInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetProjectInfoRequest.ProtoReflect.Descriptor instead.
 func (*GetProjectInfoRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{26}
+	return file_cloche_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *GetProjectInfoRequest) GetProjectDir() string {
@@ -1681,7 +2254,7 @@ type Repository struct {
 
 func (x *Repository) Reset() {
 	*x = Repository{}
-	mi := &file_cloche_proto_msgTypes[27]
+	mi := &file_cloche_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1693,7 +2266,7 @@ func (x *Repository) String() string {
 func (*Repository) ProtoMessage() {}
 
 func (x *Repository) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[27]
+	mi := &file_cloche_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1706,7 +2279,7 @@ func (x *Repository) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Repository.ProtoReflect.Descriptor instead.
 func (*Repository) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{27}
+	return file_cloche_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *Repository) GetName() string {
@@ -1755,7 +2328,7 @@ type GetProjectInfoResponse struct {
 
 func (x *GetProjectInfoResponse) Reset() {
 	*x = GetProjectInfoResponse{}
-	mi := &file_cloche_proto_msgTypes[28]
+	mi := &file_cloche_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1767,7 +2340,7 @@ func (x *GetProjectInfoResponse) String() string {
 func (*GetProjectInfoResponse) ProtoMessage() {}
 
 func (x *GetProjectInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[28]
+	mi := &file_cloche_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1780,7 +2353,7 @@ func (x *GetProjectInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetProjectInfoResponse.ProtoReflect.Descriptor instead.
 func (*GetProjectInfoResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{28}
+	return file_cloche_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *GetProjectInfoResponse) GetProjectDir() string {
@@ -1910,7 +2483,7 @@ type GetVersionRequest struct {
 
 func (x *GetVersionRequest) Reset() {
 	*x = GetVersionRequest{}
-	mi := &file_cloche_proto_msgTypes[29]
+	mi := &file_cloche_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1922,7 +2495,7 @@ func (x *GetVersionRequest) String() string {
 func (*GetVersionRequest) ProtoMessage() {}
 
 func (x *GetVersionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[29]
+	mi := &file_cloche_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1935,7 +2508,7 @@ func (x *GetVersionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetVersionRequest.ProtoReflect.Descriptor instead.
 func (*GetVersionRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{29}
+	return file_cloche_proto_rawDescGZIP(), []int{36}
 }
 
 type GetVersionResponse struct {
@@ -1947,7 +2520,7 @@ type GetVersionResponse struct {
 
 func (x *GetVersionResponse) Reset() {
 	*x = GetVersionResponse{}
-	mi := &file_cloche_proto_msgTypes[30]
+	mi := &file_cloche_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1959,7 +2532,7 @@ func (x *GetVersionResponse) String() string {
 func (*GetVersionResponse) ProtoMessage() {}
 
 func (x *GetVersionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[30]
+	mi := &file_cloche_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1972,7 +2545,7 @@ func (x *GetVersionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetVersionResponse.ProtoReflect.Descriptor instead.
 func (*GetVersionResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{30}
+	return file_cloche_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *GetVersionResponse) GetVersion() string {
@@ -1995,7 +2568,7 @@ type ListTasksRequest struct {
 
 func (x *ListTasksRequest) Reset() {
 	*x = ListTasksRequest{}
-	mi := &file_cloche_proto_msgTypes[31]
+	mi := &file_cloche_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2007,7 +2580,7 @@ func (x *ListTasksRequest) String() string {
 func (*ListTasksRequest) ProtoMessage() {}
 
 func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[31]
+	mi := &file_cloche_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2020,7 +2593,7 @@ func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
 func (*ListTasksRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{31}
+	return file_cloche_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *ListTasksRequest) GetAll() bool {
@@ -2072,7 +2645,7 @@ type TaskSummary struct {
 
 func (x *TaskSummary) Reset() {
 	*x = TaskSummary{}
-	mi := &file_cloche_proto_msgTypes[32]
+	mi := &file_cloche_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2084,7 +2657,7 @@ func (x *TaskSummary) String() string {
 func (*TaskSummary) ProtoMessage() {}
 
 func (x *TaskSummary) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[32]
+	mi := &file_cloche_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2097,7 +2670,7 @@ func (x *TaskSummary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TaskSummary.ProtoReflect.Descriptor instead.
 func (*TaskSummary) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{32}
+	return file_cloche_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *TaskSummary) GetTaskId() string {
@@ -2179,7 +2752,7 @@ type ListTasksResponse struct {
 
 func (x *ListTasksResponse) Reset() {
 	*x = ListTasksResponse{}
-	mi := &file_cloche_proto_msgTypes[33]
+	mi := &file_cloche_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2191,7 +2764,7 @@ func (x *ListTasksResponse) String() string {
 func (*ListTasksResponse) ProtoMessage() {}
 
 func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[33]
+	mi := &file_cloche_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2204,7 +2777,7 @@ func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
 func (*ListTasksResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{33}
+	return file_cloche_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *ListTasksResponse) GetTasks() []*TaskSummary {
@@ -2223,7 +2796,7 @@ type GetTaskRequest struct {
 
 func (x *GetTaskRequest) Reset() {
 	*x = GetTaskRequest{}
-	mi := &file_cloche_proto_msgTypes[34]
+	mi := &file_cloche_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2235,7 +2808,7 @@ func (x *GetTaskRequest) String() string {
 func (*GetTaskRequest) ProtoMessage() {}
 
 func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[34]
+	mi := &file_cloche_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2248,7 +2821,7 @@ func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
 func (*GetTaskRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{34}
+	return file_cloche_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *GetTaskRequest) GetTaskId() string {
@@ -2271,7 +2844,7 @@ type AttemptSummary struct {
 
 func (x *AttemptSummary) Reset() {
 	*x = AttemptSummary{}
-	mi := &file_cloche_proto_msgTypes[35]
+	mi := &file_cloche_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2283,7 +2856,7 @@ func (x *AttemptSummary) String() string {
 func (*AttemptSummary) ProtoMessage() {}
 
 func (x *AttemptSummary) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[35]
+	mi := &file_cloche_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2296,7 +2869,7 @@ func (x *AttemptSummary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AttemptSummary.ProtoReflect.Descriptor instead.
 func (*AttemptSummary) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{35}
+	return file_cloche_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *AttemptSummary) GetAttemptId() string {
@@ -2347,7 +2920,7 @@ type GetTaskResponse struct {
 
 func (x *GetTaskResponse) Reset() {
 	*x = GetTaskResponse{}
-	mi := &file_cloche_proto_msgTypes[36]
+	mi := &file_cloche_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2359,7 +2932,7 @@ func (x *GetTaskResponse) String() string {
 func (*GetTaskResponse) ProtoMessage() {}
 
 func (x *GetTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[36]
+	mi := &file_cloche_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2372,7 +2945,7 @@ func (x *GetTaskResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetTaskResponse.ProtoReflect.Descriptor instead.
 func (*GetTaskResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{36}
+	return file_cloche_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *GetTaskResponse) GetTaskId() string {
@@ -2419,7 +2992,7 @@ type GetAttemptRequest struct {
 
 func (x *GetAttemptRequest) Reset() {
 	*x = GetAttemptRequest{}
-	mi := &file_cloche_proto_msgTypes[37]
+	mi := &file_cloche_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2431,7 +3004,7 @@ func (x *GetAttemptRequest) String() string {
 func (*GetAttemptRequest) ProtoMessage() {}
 
 func (x *GetAttemptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[37]
+	mi := &file_cloche_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2444,7 +3017,7 @@ func (x *GetAttemptRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAttemptRequest.ProtoReflect.Descriptor instead.
 func (*GetAttemptRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{37}
+	return file_cloche_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *GetAttemptRequest) GetAttemptId() string {
@@ -2468,7 +3041,7 @@ type GetAttemptResponse struct {
 
 func (x *GetAttemptResponse) Reset() {
 	*x = GetAttemptResponse{}
-	mi := &file_cloche_proto_msgTypes[38]
+	mi := &file_cloche_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2480,7 +3053,7 @@ func (x *GetAttemptResponse) String() string {
 func (*GetAttemptResponse) ProtoMessage() {}
 
 func (x *GetAttemptResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[38]
+	mi := &file_cloche_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2493,7 +3066,7 @@ func (x *GetAttemptResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAttemptResponse.ProtoReflect.Descriptor instead.
 func (*GetAttemptResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{38}
+	return file_cloche_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *GetAttemptResponse) GetAttemptId() string {
@@ -2553,7 +3126,7 @@ type CompleteRequest struct {
 
 func (x *CompleteRequest) Reset() {
 	*x = CompleteRequest{}
-	mi := &file_cloche_proto_msgTypes[39]
+	mi := &file_cloche_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2565,7 +3138,7 @@ func (x *CompleteRequest) String() string {
 func (*CompleteRequest) ProtoMessage() {}
 
 func (x *CompleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[39]
+	mi := &file_cloche_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2578,7 +3151,7 @@ func (x *CompleteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CompleteRequest.ProtoReflect.Descriptor instead.
 func (*CompleteRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{39}
+	return file_cloche_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *CompleteRequest) GetWords() []string {
@@ -2611,7 +3184,7 @@ type CompleteResponse struct {
 
 func (x *CompleteResponse) Reset() {
 	*x = CompleteResponse{}
-	mi := &file_cloche_proto_msgTypes[40]
+	mi := &file_cloche_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2623,7 +3196,7 @@ func (x *CompleteResponse) String() string {
 func (*CompleteResponse) ProtoMessage() {}
 
 func (x *CompleteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[40]
+	mi := &file_cloche_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2636,7 +3209,7 @@ func (x *CompleteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CompleteResponse.ProtoReflect.Descriptor instead.
 func (*CompleteResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{40}
+	return file_cloche_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *CompleteResponse) GetCompletions() []string {
@@ -2657,7 +3230,7 @@ type GetUsageRequest struct {
 
 func (x *GetUsageRequest) Reset() {
 	*x = GetUsageRequest{}
-	mi := &file_cloche_proto_msgTypes[41]
+	mi := &file_cloche_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2669,7 +3242,7 @@ func (x *GetUsageRequest) String() string {
 func (*GetUsageRequest) ProtoMessage() {}
 
 func (x *GetUsageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[41]
+	mi := &file_cloche_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2682,7 +3255,7 @@ func (x *GetUsageRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUsageRequest.ProtoReflect.Descriptor instead.
 func (*GetUsageRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{41}
+	return file_cloche_proto_rawDescGZIP(), []int{48}
 }
 
 func (x *GetUsageRequest) GetProjectDir() string {
@@ -2715,7 +3288,7 @@ type GetUsageResponse struct {
 
 func (x *GetUsageResponse) Reset() {
 	*x = GetUsageResponse{}
-	mi := &file_cloche_proto_msgTypes[42]
+	mi := &file_cloche_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2727,7 +3300,7 @@ func (x *GetUsageResponse) String() string {
 func (*GetUsageResponse) ProtoMessage() {}
 
 func (x *GetUsageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[42]
+	mi := &file_cloche_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2740,7 +3313,7 @@ func (x *GetUsageResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUsageResponse.ProtoReflect.Descriptor instead.
 func (*GetUsageResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{42}
+	return file_cloche_proto_rawDescGZIP(), []int{49}
 }
 
 func (x *GetUsageResponse) GetSummaries() []*UsageSummary {
@@ -2763,7 +3336,7 @@ type UsageSummary struct {
 
 func (x *UsageSummary) Reset() {
 	*x = UsageSummary{}
-	mi := &file_cloche_proto_msgTypes[43]
+	mi := &file_cloche_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2775,7 +3348,7 @@ func (x *UsageSummary) String() string {
 func (*UsageSummary) ProtoMessage() {}
 
 func (x *UsageSummary) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[43]
+	mi := &file_cloche_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2788,7 +3361,7 @@ func (x *UsageSummary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UsageSummary.ProtoReflect.Descriptor instead.
 func (*UsageSummary) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{43}
+	return file_cloche_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *UsageSummary) GetAgentName() string {
@@ -2842,7 +3415,7 @@ type ConsoleInput struct {
 
 func (x *ConsoleInput) Reset() {
 	*x = ConsoleInput{}
-	mi := &file_cloche_proto_msgTypes[44]
+	mi := &file_cloche_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2854,7 +3427,7 @@ func (x *ConsoleInput) String() string {
 func (*ConsoleInput) ProtoMessage() {}
 
 func (x *ConsoleInput) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[44]
+	mi := &file_cloche_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2867,7 +3440,7 @@ func (x *ConsoleInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConsoleInput.ProtoReflect.Descriptor instead.
 func (*ConsoleInput) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{44}
+	return file_cloche_proto_rawDescGZIP(), []int{51}
 }
 
 func (x *ConsoleInput) GetPayload() isConsoleInput_Payload {
@@ -2941,7 +3514,7 @@ type ConsoleOutput struct {
 
 func (x *ConsoleOutput) Reset() {
 	*x = ConsoleOutput{}
-	mi := &file_cloche_proto_msgTypes[45]
+	mi := &file_cloche_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2953,7 +3526,7 @@ func (x *ConsoleOutput) String() string {
 func (*ConsoleOutput) ProtoMessage() {}
 
 func (x *ConsoleOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[45]
+	mi := &file_cloche_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2966,7 +3539,7 @@ func (x *ConsoleOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConsoleOutput.ProtoReflect.Descriptor instead.
 func (*ConsoleOutput) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{45}
+	return file_cloche_proto_rawDescGZIP(), []int{52}
 }
 
 func (x *ConsoleOutput) GetPayload() isConsoleOutput_Payload {
@@ -3038,7 +3611,7 @@ type ConsoleStart struct {
 
 func (x *ConsoleStart) Reset() {
 	*x = ConsoleStart{}
-	mi := &file_cloche_proto_msgTypes[46]
+	mi := &file_cloche_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3050,7 +3623,7 @@ func (x *ConsoleStart) String() string {
 func (*ConsoleStart) ProtoMessage() {}
 
 func (x *ConsoleStart) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[46]
+	mi := &file_cloche_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3063,7 +3636,7 @@ func (x *ConsoleStart) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConsoleStart.ProtoReflect.Descriptor instead.
 func (*ConsoleStart) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{46}
+	return file_cloche_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *ConsoleStart) GetProjectDir() string {
@@ -3105,7 +3678,7 @@ type ConsoleStarted struct {
 
 func (x *ConsoleStarted) Reset() {
 	*x = ConsoleStarted{}
-	mi := &file_cloche_proto_msgTypes[47]
+	mi := &file_cloche_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3117,7 +3690,7 @@ func (x *ConsoleStarted) String() string {
 func (*ConsoleStarted) ProtoMessage() {}
 
 func (x *ConsoleStarted) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[47]
+	mi := &file_cloche_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3130,7 +3703,7 @@ func (x *ConsoleStarted) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConsoleStarted.ProtoReflect.Descriptor instead.
 func (*ConsoleStarted) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{47}
+	return file_cloche_proto_rawDescGZIP(), []int{54}
 }
 
 func (x *ConsoleStarted) GetContainerId() string {
@@ -3158,7 +3731,7 @@ type TerminalSize struct {
 
 func (x *TerminalSize) Reset() {
 	*x = TerminalSize{}
-	mi := &file_cloche_proto_msgTypes[48]
+	mi := &file_cloche_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3170,7 +3743,7 @@ func (x *TerminalSize) String() string {
 func (*TerminalSize) ProtoMessage() {}
 
 func (x *TerminalSize) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[48]
+	mi := &file_cloche_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3183,7 +3756,7 @@ func (x *TerminalSize) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TerminalSize.ProtoReflect.Descriptor instead.
 func (*TerminalSize) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{48}
+	return file_cloche_proto_rawDescGZIP(), []int{55}
 }
 
 func (x *TerminalSize) GetRows() uint32 {
@@ -3210,7 +3783,7 @@ type ConsoleExited struct {
 
 func (x *ConsoleExited) Reset() {
 	*x = ConsoleExited{}
-	mi := &file_cloche_proto_msgTypes[49]
+	mi := &file_cloche_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3222,7 +3795,7 @@ func (x *ConsoleExited) String() string {
 func (*ConsoleExited) ProtoMessage() {}
 
 func (x *ConsoleExited) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[49]
+	mi := &file_cloche_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3235,7 +3808,7 @@ func (x *ConsoleExited) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConsoleExited.ProtoReflect.Descriptor instead.
 func (*ConsoleExited) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{49}
+	return file_cloche_proto_rawDescGZIP(), []int{56}
 }
 
 func (x *ConsoleExited) GetExitCode() int32 {
@@ -3257,7 +3830,7 @@ type GetContextKeyRequest struct {
 
 func (x *GetContextKeyRequest) Reset() {
 	*x = GetContextKeyRequest{}
-	mi := &file_cloche_proto_msgTypes[50]
+	mi := &file_cloche_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3269,7 +3842,7 @@ func (x *GetContextKeyRequest) String() string {
 func (*GetContextKeyRequest) ProtoMessage() {}
 
 func (x *GetContextKeyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[50]
+	mi := &file_cloche_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3282,7 +3855,7 @@ func (x *GetContextKeyRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetContextKeyRequest.ProtoReflect.Descriptor instead.
 func (*GetContextKeyRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{50}
+	return file_cloche_proto_rawDescGZIP(), []int{57}
 }
 
 func (x *GetContextKeyRequest) GetTaskId() string {
@@ -3323,7 +3896,7 @@ type GetContextKeyResponse struct {
 
 func (x *GetContextKeyResponse) Reset() {
 	*x = GetContextKeyResponse{}
-	mi := &file_cloche_proto_msgTypes[51]
+	mi := &file_cloche_proto_msgTypes[58]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3335,7 +3908,7 @@ func (x *GetContextKeyResponse) String() string {
 func (*GetContextKeyResponse) ProtoMessage() {}
 
 func (x *GetContextKeyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[51]
+	mi := &file_cloche_proto_msgTypes[58]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3348,7 +3921,7 @@ func (x *GetContextKeyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetContextKeyResponse.ProtoReflect.Descriptor instead.
 func (*GetContextKeyResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{51}
+	return file_cloche_proto_rawDescGZIP(), []int{58}
 }
 
 func (x *GetContextKeyResponse) GetValue() string {
@@ -3378,7 +3951,7 @@ type SetContextKeyRequest struct {
 
 func (x *SetContextKeyRequest) Reset() {
 	*x = SetContextKeyRequest{}
-	mi := &file_cloche_proto_msgTypes[52]
+	mi := &file_cloche_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3390,7 +3963,7 @@ func (x *SetContextKeyRequest) String() string {
 func (*SetContextKeyRequest) ProtoMessage() {}
 
 func (x *SetContextKeyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[52]
+	mi := &file_cloche_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3403,7 +3976,7 @@ func (x *SetContextKeyRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetContextKeyRequest.ProtoReflect.Descriptor instead.
 func (*SetContextKeyRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{52}
+	return file_cloche_proto_rawDescGZIP(), []int{59}
 }
 
 func (x *SetContextKeyRequest) GetTaskId() string {
@@ -3449,7 +4022,7 @@ type SetContextKeyResponse struct {
 
 func (x *SetContextKeyResponse) Reset() {
 	*x = SetContextKeyResponse{}
-	mi := &file_cloche_proto_msgTypes[53]
+	mi := &file_cloche_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3461,7 +4034,7 @@ func (x *SetContextKeyResponse) String() string {
 func (*SetContextKeyResponse) ProtoMessage() {}
 
 func (x *SetContextKeyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[53]
+	mi := &file_cloche_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3474,7 +4047,7 @@ func (x *SetContextKeyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetContextKeyResponse.ProtoReflect.Descriptor instead.
 func (*SetContextKeyResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{53}
+	return file_cloche_proto_rawDescGZIP(), []int{60}
 }
 
 type ListContextKeysRequest struct {
@@ -3488,7 +4061,7 @@ type ListContextKeysRequest struct {
 
 func (x *ListContextKeysRequest) Reset() {
 	*x = ListContextKeysRequest{}
-	mi := &file_cloche_proto_msgTypes[54]
+	mi := &file_cloche_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3500,7 +4073,7 @@ func (x *ListContextKeysRequest) String() string {
 func (*ListContextKeysRequest) ProtoMessage() {}
 
 func (x *ListContextKeysRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[54]
+	mi := &file_cloche_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3513,7 +4086,7 @@ func (x *ListContextKeysRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListContextKeysRequest.ProtoReflect.Descriptor instead.
 func (*ListContextKeysRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{54}
+	return file_cloche_proto_rawDescGZIP(), []int{61}
 }
 
 func (x *ListContextKeysRequest) GetTaskId() string {
@@ -3546,7 +4119,7 @@ type ListContextKeysResponse struct {
 
 func (x *ListContextKeysResponse) Reset() {
 	*x = ListContextKeysResponse{}
-	mi := &file_cloche_proto_msgTypes[55]
+	mi := &file_cloche_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3558,7 +4131,7 @@ func (x *ListContextKeysResponse) String() string {
 func (*ListContextKeysResponse) ProtoMessage() {}
 
 func (x *ListContextKeysResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[55]
+	mi := &file_cloche_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3571,7 +4144,7 @@ func (x *ListContextKeysResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListContextKeysResponse.ProtoReflect.Descriptor instead.
 func (*ListContextKeysResponse) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{55}
+	return file_cloche_proto_rawDescGZIP(), []int{62}
 }
 
 func (x *ListContextKeysResponse) GetKeys() []string {
@@ -3598,7 +4171,7 @@ type AgentMessage struct {
 
 func (x *AgentMessage) Reset() {
 	*x = AgentMessage{}
-	mi := &file_cloche_proto_msgTypes[56]
+	mi := &file_cloche_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3610,7 +4183,7 @@ func (x *AgentMessage) String() string {
 func (*AgentMessage) ProtoMessage() {}
 
 func (x *AgentMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[56]
+	mi := &file_cloche_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3623,7 +4196,7 @@ func (x *AgentMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentMessage.ProtoReflect.Descriptor instead.
 func (*AgentMessage) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{56}
+	return file_cloche_proto_rawDescGZIP(), []int{63}
 }
 
 func (x *AgentMessage) GetPayload() isAgentMessage_Payload {
@@ -3728,7 +4301,7 @@ type DaemonMessage struct {
 
 func (x *DaemonMessage) Reset() {
 	*x = DaemonMessage{}
-	mi := &file_cloche_proto_msgTypes[57]
+	mi := &file_cloche_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3740,7 +4313,7 @@ func (x *DaemonMessage) String() string {
 func (*DaemonMessage) ProtoMessage() {}
 
 func (x *DaemonMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[57]
+	mi := &file_cloche_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3753,7 +4326,7 @@ func (x *DaemonMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DaemonMessage.ProtoReflect.Descriptor instead.
 func (*DaemonMessage) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{57}
+	return file_cloche_proto_rawDescGZIP(), []int{64}
 }
 
 func (x *DaemonMessage) GetPayload() isDaemonMessage_Payload {
@@ -3838,7 +4411,7 @@ type AgentReady struct {
 
 func (x *AgentReady) Reset() {
 	*x = AgentReady{}
-	mi := &file_cloche_proto_msgTypes[58]
+	mi := &file_cloche_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3850,7 +4423,7 @@ func (x *AgentReady) String() string {
 func (*AgentReady) ProtoMessage() {}
 
 func (x *AgentReady) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[58]
+	mi := &file_cloche_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3863,7 +4436,7 @@ func (x *AgentReady) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentReady.ProtoReflect.Descriptor instead.
 func (*AgentReady) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{58}
+	return file_cloche_proto_rawDescGZIP(), []int{65}
 }
 
 func (x *AgentReady) GetRunId() string {
@@ -3894,7 +4467,7 @@ type ExecuteStep struct {
 
 func (x *ExecuteStep) Reset() {
 	*x = ExecuteStep{}
-	mi := &file_cloche_proto_msgTypes[59]
+	mi := &file_cloche_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3906,7 +4479,7 @@ func (x *ExecuteStep) String() string {
 func (*ExecuteStep) ProtoMessage() {}
 
 func (x *ExecuteStep) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[59]
+	mi := &file_cloche_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3919,7 +4492,7 @@ func (x *ExecuteStep) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteStep.ProtoReflect.Descriptor instead.
 func (*ExecuteStep) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{59}
+	return file_cloche_proto_rawDescGZIP(), []int{66}
 }
 
 func (x *ExecuteStep) GetStepName() string {
@@ -3961,17 +4534,21 @@ func (x *ExecuteStep) GetResume() bool {
 type StepResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	Result        string                 `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`                           // "success", "fail", custom result names
-	Output        string                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`                           // step output content (for output mappings)
-	TokenUsage    *TokenUsage            `protobuf:"bytes,4,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"` // optional token usage
-	Skipped       bool                   `protobuf:"varint,5,opt,name=skipped,proto3" json:"skipped,omitempty"`                        // true when the step's skip script bypassed execution
+	Result        string                 `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`                            // "success", "fail", custom result names
+	Output        string                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`                            // step output content (for output mappings)
+	TokenUsage    *TokenUsage            `protobuf:"bytes,4,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"`  // optional token usage
+	Skipped       bool                   `protobuf:"varint,5,opt,name=skipped,proto3" json:"skipped,omitempty"`                         // true when the step's skip script bypassed execution
+	Seq           int64                  `protobuf:"varint,6,opt,name=seq,proto3" json:"seq,omitempty"`                                 // emit-order sequence; see StepStarted.seq
+	PromptText    string                 `protobuf:"bytes,7,opt,name=prompt_text,json=promptText,proto3" json:"prompt_text,omitempty"`  // assembled prompt sent to the agent, for agent prompt steps
+	GitRef        string                 `protobuf:"bytes,8,opt,name=git_ref,json=gitRef,proto3" json:"git_ref,omitempty"`              // commit snapshotting the workspace the step left behind
+	ExitCode      *int32                 `protobuf:"varint,9,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"` // exit code of the step's process; unset when no process ran to completion
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StepResult) Reset() {
 	*x = StepResult{}
-	mi := &file_cloche_proto_msgTypes[60]
+	mi := &file_cloche_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3983,7 +4560,7 @@ func (x *StepResult) String() string {
 func (*StepResult) ProtoMessage() {}
 
 func (x *StepResult) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[60]
+	mi := &file_cloche_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3996,7 +4573,7 @@ func (x *StepResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StepResult.ProtoReflect.Descriptor instead.
 func (*StepResult) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{60}
+	return file_cloche_proto_rawDescGZIP(), []int{67}
 }
 
 func (x *StepResult) GetRequestId() string {
@@ -4034,6 +4611,34 @@ func (x *StepResult) GetSkipped() bool {
 	return false
 }
 
+func (x *StepResult) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *StepResult) GetPromptText() string {
+	if x != nil {
+		return x.PromptText
+	}
+	return ""
+}
+
+func (x *StepResult) GetGitRef() string {
+	if x != nil {
+		return x.GitRef
+	}
+	return ""
+}
+
+func (x *StepResult) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
 // StepLog carries a single real-time log line from a step.
 type StepLog struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -4046,7 +4651,7 @@ type StepLog struct {
 
 func (x *StepLog) Reset() {
 	*x = StepLog{}
-	mi := &file_cloche_proto_msgTypes[61]
+	mi := &file_cloche_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4058,7 +4663,7 @@ func (x *StepLog) String() string {
 func (*StepLog) ProtoMessage() {}
 
 func (x *StepLog) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[61]
+	mi := &file_cloche_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4071,7 +4676,7 @@ func (x *StepLog) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StepLog.ProtoReflect.Descriptor instead.
 func (*StepLog) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{61}
+	return file_cloche_proto_rawDescGZIP(), []int{68}
 }
 
 func (x *StepLog) GetStepName() string {
@@ -4097,16 +4702,22 @@ func (x *StepLog) GetTimestamp() int64 {
 
 // StepStarted signals that the agent has begun executing a step.
 type StepStarted struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	StepName      string                 `protobuf:"bytes,2,opt,name=step_name,json=stepName,proto3" json:"step_name,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	StepName  string                 `protobuf:"bytes,2,opt,name=step_name,json=stepName,proto3" json:"step_name,omitempty"`
+	// seq is assigned by the agent when it emits the event: a counter that
+	// starts at 1 for each agent session and increases by one across its
+	// StepStarted and StepResult messages, so captures can be ordered logically
+	// even when fanout steps race. The daemon offsets it past the run's earlier
+	// captures. 0 if unset.
+	Seq           int64 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StepStarted) Reset() {
 	*x = StepStarted{}
-	mi := &file_cloche_proto_msgTypes[62]
+	mi := &file_cloche_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4118,7 +4729,7 @@ func (x *StepStarted) String() string {
 func (*StepStarted) ProtoMessage() {}
 
 func (x *StepStarted) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[62]
+	mi := &file_cloche_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4131,7 +4742,7 @@ func (x *StepStarted) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StepStarted.ProtoReflect.Descriptor instead.
 func (*StepStarted) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{62}
+	return file_cloche_proto_rawDescGZIP(), []int{69}
 }
 
 func (x *StepStarted) GetRequestId() string {
@@ -4148,6 +4759,13 @@ func (x *StepStarted) GetStepName() string {
 	return ""
 }
 
+func (x *StepStarted) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
 // HostWorkflowRequest is sent by the agent to request the daemon run a host workflow.
 type HostWorkflowRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -4160,7 +4778,7 @@ type HostWorkflowRequest struct {
 
 func (x *HostWorkflowRequest) Reset() {
 	*x = HostWorkflowRequest{}
-	mi := &file_cloche_proto_msgTypes[63]
+	mi := &file_cloche_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4172,7 +4790,7 @@ func (x *HostWorkflowRequest) String() string {
 func (*HostWorkflowRequest) ProtoMessage() {}
 
 func (x *HostWorkflowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[63]
+	mi := &file_cloche_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4185,7 +4803,7 @@ func (x *HostWorkflowRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HostWorkflowRequest.ProtoReflect.Descriptor instead.
 func (*HostWorkflowRequest) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{63}
+	return file_cloche_proto_rawDescGZIP(), []int{70}
 }
 
 func (x *HostWorkflowRequest) GetRequestId() string {
@@ -4221,7 +4839,7 @@ type HostWorkflowResult struct {
 
 func (x *HostWorkflowResult) Reset() {
 	*x = HostWorkflowResult{}
-	mi := &file_cloche_proto_msgTypes[64]
+	mi := &file_cloche_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4233,7 +4851,7 @@ func (x *HostWorkflowResult) String() string {
 func (*HostWorkflowResult) ProtoMessage() {}
 
 func (x *HostWorkflowResult) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[64]
+	mi := &file_cloche_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4246,7 +4864,7 @@ func (x *HostWorkflowResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HostWorkflowResult.ProtoReflect.Descriptor instead.
 func (*HostWorkflowResult) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{64}
+	return file_cloche_proto_rawDescGZIP(), []int{71}
 }
 
 func (x *HostWorkflowResult) GetRequestId() string {
@@ -4280,7 +4898,7 @@ type StepCancelled struct {
 
 func (x *StepCancelled) Reset() {
 	*x = StepCancelled{}
-	mi := &file_cloche_proto_msgTypes[65]
+	mi := &file_cloche_proto_msgTypes[72]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4292,7 +4910,7 @@ func (x *StepCancelled) String() string {
 func (*StepCancelled) ProtoMessage() {}
 
 func (x *StepCancelled) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[65]
+	mi := &file_cloche_proto_msgTypes[72]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4305,7 +4923,7 @@ func (x *StepCancelled) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StepCancelled.ProtoReflect.Descriptor instead.
 func (*StepCancelled) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{65}
+	return file_cloche_proto_rawDescGZIP(), []int{72}
 }
 
 func (x *StepCancelled) GetRequestId() string {
@@ -4324,7 +4942,7 @@ type Shutdown struct {
 
 func (x *Shutdown) Reset() {
 	*x = Shutdown{}
-	mi := &file_cloche_proto_msgTypes[66]
+	mi := &file_cloche_proto_msgTypes[73]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4336,7 +4954,7 @@ func (x *Shutdown) String() string {
 func (*Shutdown) ProtoMessage() {}
 
 func (x *Shutdown) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[66]
+	mi := &file_cloche_proto_msgTypes[73]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4349,7 +4967,7 @@ func (x *Shutdown) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Shutdown.ProtoReflect.Descriptor instead.
 func (*Shutdown) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{66}
+	return file_cloche_proto_rawDescGZIP(), []int{73}
 }
 
 // TokenUsage carries token consumption for a single agent step execution.
@@ -4363,7 +4981,7 @@ type TokenUsage struct {
 
 func (x *TokenUsage) Reset() {
 	*x = TokenUsage{}
-	mi := &file_cloche_proto_msgTypes[67]
+	mi := &file_cloche_proto_msgTypes[74]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4375,7 +4993,7 @@ func (x *TokenUsage) String() string {
 func (*TokenUsage) ProtoMessage() {}
 
 func (x *TokenUsage) ProtoReflect() protoreflect.Message {
-	mi := &file_cloche_proto_msgTypes[67]
+	mi := &file_cloche_proto_msgTypes[74]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4388,7 +5006,7 @@ func (x *TokenUsage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TokenUsage.ProtoReflect.Descriptor instead.
 func (*TokenUsage) Descriptor() ([]byte, []int) {
-	return file_cloche_proto_rawDescGZIP(), []int{67}
+	return file_cloche_proto_rawDescGZIP(), []int{74}
 }
 
 func (x *TokenUsage) GetInputTokens() int64 {
@@ -4409,7 +5027,7 @@ var File_cloche_proto protoreflect.FileDescriptor
 
 const file_cloche_proto_rawDesc = "" +
 	"\n" +
-	"\fcloche.proto\x12\tcloche.v1\"\xe0\x01\n" +
+	"\fcloche.proto\x12\tcloche.v1\"\xde\x02\n" +
 	"\x12RunWorkflowRequest\x12#\n" +
 	"\rworkflow_name\x18\x01 \x01(\tR\fworkflowName\x12\x1f\n" +
 	"\vproject_dir\x18\x02 \x01(\tR\n" +
@@ -4418,15 +5036,26 @@ const file_cloche_proto_rawDesc = "" +
 	"\x06prompt\x18\x04 \x01(\tR\x06prompt\x12%\n" +
 	"\x0ekeep_container\x18\x05 \x01(\bR\rkeepContainer\x12\x14\n" +
 	"\x05title\x18\x06 \x01(\tR\x05title\x12\x19\n" +
-	"\bissue_id\x18\a \x01(\tR\aissueId\"d\n" +
+	"\bissue_id\x18\a \x01(\tR\aissueId\x12A\n" +
+	"\x06params\x18\b \x03(\v2).cloche.v1.RunWorkflowRequest.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"d\n" +
 	"\x13RunWorkflowResponse\x12\x15\n" +
 	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x17\n" +
 	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x1d\n" +
 	"\n" +
-	"attempt_id\x18\x03 \x01(\tR\tattemptId\"9\n" +
+	"attempt_id\x18\x03 \x01(\tR\tattemptId\"\xa3\x01\n" +
+	"\x17RunWorkflowSyncResponse\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x03 \x01(\tR\tattemptId\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\x12#\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\"9\n" +
 	"\x10GetStatusRequest\x12\x15\n" +
 	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x0e\n" +
-	"\x02id\x18\x02 \x01(\tR\x02id\"\x87\x04\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xcd\x04\n" +
 	"\x11GetStatusResponse\x12\x15\n" +
 	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12#\n" +
 	"\rworkflow_name\x18\x02 \x01(\tR\fworkflowName\x12\x14\n" +
@@ -4444,7 +5073,10 @@ const file_cloche_proto_rawDesc = "" +
 	"\flast_poll_at\x18\r \x01(\tR\n" +
 	"lastPollAt\x12\x1d\n" +
 	"\n" +
-	"poll_count\x18\x0e \x01(\x05R\tpollCount\"\x8d\x02\n" +
+	"poll_count\x18\x0e \x01(\x05R\tpollCount\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x0f \x01(\tR\tstartedAt\x12%\n" +
+	"\x0equeue_position\x18\x10 \x01(\x05R\rqueuePosition\"\xde\x02\n" +
 	"\x13StepExecutionStatus\x12\x1b\n" +
 	"\tstep_name\x18\x01 \x01(\tR\bstepName\x12\x16\n" +
 	"\x06result\x18\x02 \x01(\tR\x06result\x12\x1d\n" +
@@ -4455,7 +5087,13 @@ const file_cloche_proto_rawDesc = "" +
 	"\routput_tokens\x18\x06 \x01(\x03R\foutputTokens\x12\x1d\n" +
 	"\n" +
 	"agent_name\x18\a \x01(\tR\tagentName\x12\x18\n" +
-	"\askipped\x18\b \x01(\bR\askipped\"\xa0\x01\n" +
+	"\askipped\x18\b \x01(\bR\askipped\x12\x1f\n" +
+	"\vprompt_path\x18\t \x01(\tR\n" +
+	"promptPath\x12 \n" +
+	"\texit_code\x18\n" +
+	" \x01(\x05H\x00R\bexitCode\x88\x01\x01B\f\n" +
+	"\n" +
+	"_exit_code\"\xa0\x01\n" +
 	"\x11StreamLogsRequest\x12\x15\n" +
 	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x1b\n" +
 	"\tstep_name\x18\x02 \x01(\tR\bstepName\x12\x19\n" +
@@ -4488,16 +5126,54 @@ const file_cloche_proto_rawDesc = "" +
 	"target_dir\x18\x01 \x01(\tR\ttargetDir\x12\x16\n" +
 	"\x06branch\x18\x02 \x01(\tR\x06branch\x12\x1d\n" +
 	"\n" +
-	"commit_sha\x18\x03 \x01(\tR\tcommitSha\"\x89\x01\n" +
+	"commit_sha\x18\x03 \x01(\tR\tcommitSha\"\xa4\x01\n" +
+	"\fPruneRequest\x12\x1f\n" +
+	"\vproject_dir\x18\x01 \x01(\tR\n" +
+	"projectDir\x125\n" +
+	"\x17runs_older_than_seconds\x18\x02 \x01(\x03R\x14runsOlderThanSeconds\x12\x1c\n" +
+	"\tsnapshots\x18\x03 \x01(\bR\tsnapshots\x12\x1e\n" +
+	"\n" +
+	"containers\x18\x04 \x01(\bR\n" +
+	"containers\"\xa6\x01\n" +
+	"\rPruneResponse\x12!\n" +
+	"\fdeleted_runs\x18\x01 \x03(\tR\vdeletedRuns\x12+\n" +
+	"\x11removed_snapshots\x18\x02 \x03(\tR\x10removedSnapshots\x12-\n" +
+	"\x12removed_containers\x18\x03 \x03(\tR\x11removedContainers\x12\x16\n" +
+	"\x06errors\x18\x04 \x03(\tR\x06errors\"\xb6\x01\n" +
+	"\x11DeleteRunsRequest\x12\x1f\n" +
+	"\vproject_dir\x18\x01 \x01(\tR\n" +
+	"projectDir\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12#\n" +
+	"\rworkflow_name\x18\x03 \x01(\tR\fworkflowName\x12,\n" +
+	"\x12older_than_seconds\x18\x04 \x01(\x03R\x10olderThanSeconds\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\"\x84\x01\n" +
+	"\x12DeleteRunsResponse\x12)\n" +
+	"\x04runs\x18\x01 \x03(\v2\x15.cloche.v1.RunSummaryR\x04runs\x12+\n" +
+	"\x11removed_artifacts\x18\x02 \x03(\tR\x10removedArtifacts\x12\x16\n" +
+	"\x06errors\x18\x03 \x03(\tR\x06errors\"w\n" +
+	"\rEvolveRequest\x12\x1f\n" +
+	"\vproject_dir\x18\x01 \x01(\tR\n" +
+	"projectDir\x12#\n" +
+	"\rworkflow_name\x18\x02 \x01(\tR\fworkflowName\x12 \n" +
+	"\fsince_run_id\x18\x03 \x01(\tR\n" +
+	"sinceRunId\"\xec\x01\n" +
+	"\x0eEvolveResponse\x12!\n" +
+	"\fevolution_id\x18\x01 \x01(\tR\vevolutionId\x12&\n" +
+	"\x0eclassification\x18\x02 \x01(\tR\x0eclassification\x12%\n" +
+	"\x0eruns_collected\x18\x03 \x01(\x05R\rrunsCollected\x12\x18\n" +
+	"\achanges\x18\x04 \x01(\x05R\achanges\x12'\n" +
+	"\x0fknowledge_delta\x18\x05 \x01(\tR\x0eknowledgeDelta\x12%\n" +
+	"\x0echange_details\x18\x06 \x03(\tR\rchangeDetails\"\x9d\x01\n" +
 	"\x0fListRunsRequest\x12\x10\n" +
 	"\x03all\x18\x01 \x01(\bR\x03all\x12\x1f\n" +
 	"\vproject_dir\x18\x02 \x01(\tR\n" +
 	"projectDir\x12\x14\n" +
 	"\x05state\x18\x03 \x01(\tR\x05state\x12\x14\n" +
 	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x17\n" +
-	"\atask_id\x18\x05 \x01(\tR\x06taskId\"=\n" +
+	"\atask_id\x18\x05 \x01(\tR\x06taskId\x12\x12\n" +
+	"\x04sort\x18\x06 \x01(\tR\x04sort\"=\n" +
 	"\x10ListRunsResponse\x12)\n" +
-	"\x04runs\x18\x01 \x03(\v2\x15.cloche.v1.RunSummaryR\x04runs\"\x92\x03\n" +
+	"\x04runs\x18\x01 \x03(\v2\x15.cloche.v1.RunSummaryR\x04runs\"\x81\x04\n" +
 	"\n" +
 	"RunSummary\x12\x15\n" +
 	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12#\n" +
@@ -4517,7 +5193,11 @@ const file_cloche_proto_rawDesc = "" +
 	"\flast_poll_at\x18\f \x01(\tR\n" +
 	"lastPollAt\x12\x1d\n" +
 	"\n" +
-	"poll_count\x18\r \x01(\x05R\tpollCount\"[\n" +
+	"poll_count\x18\r \x01(\x05R\tpollCount\x12\x1d\n" +
+	"\n" +
+	"step_count\x18\x0e \x01(\x05R\tstepCount\x12#\n" +
+	"\rattempt_count\x18\x0f \x01(\x05R\fattemptCount\x12)\n" +
+	"\x10duration_seconds\x18\x10 \x01(\x03R\x0fdurationSeconds\"[\n" +
 	"\x11EnableLoopRequest\x12\x1f\n" +
 	"\vproject_dir\x18\x01 \x01(\tR\n" +
 	"projectDir\x12%\n" +
@@ -4722,7 +5402,7 @@ const file_cloche_proto_rawDesc = "" +
 	"\x06resume\x18\x06 \x01(\bR\x06resume\x1a9\n" +
 	"\vConfigEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xad\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa9\x02\n" +
 	"\n" +
 	"StepResult\x12\x1d\n" +
 	"\n" +
@@ -4731,15 +5411,23 @@ const file_cloche_proto_rawDesc = "" +
 	"\x06output\x18\x03 \x01(\tR\x06output\x126\n" +
 	"\vtoken_usage\x18\x04 \x01(\v2\x15.cloche.v1.TokenUsageR\n" +
 	"tokenUsage\x12\x18\n" +
-	"\askipped\x18\x05 \x01(\bR\askipped\"X\n" +
+	"\askipped\x18\x05 \x01(\bR\askipped\x12\x10\n" +
+	"\x03seq\x18\x06 \x01(\x03R\x03seq\x12\x1f\n" +
+	"\vprompt_text\x18\a \x01(\tR\n" +
+	"promptText\x12\x17\n" +
+	"\agit_ref\x18\b \x01(\tR\x06gitRef\x12 \n" +
+	"\texit_code\x18\t \x01(\x05H\x00R\bexitCode\x88\x01\x01B\f\n" +
+	"\n" +
+	"_exit_code\"X\n" +
 	"\aStepLog\x12\x1b\n" +
 	"\tstep_name\x18\x01 \x01(\tR\bstepName\x12\x12\n" +
 	"\x04line\x18\x02 \x01(\tR\x04line\x12\x1c\n" +
-	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\"I\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\"[\n" +
 	"\vStepStarted\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1b\n" +
-	"\tstep_name\x18\x02 \x01(\tR\bstepName\"\xcc\x01\n" +
+	"\tstep_name\x18\x02 \x01(\tR\bstepName\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x03R\x03seq\"\xcc\x01\n" +
 	"\x13HostWorkflowRequest\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\x12#\n" +
@@ -4761,9 +5449,10 @@ const file_cloche_proto_rawDesc = "" +
 	"\n" +
 	"TokenUsage\x12!\n" +
 	"\finput_tokens\x18\x01 \x01(\x03R\vinputTokens\x12#\n" +
-	"\routput_tokens\x18\x02 \x01(\x03R\foutputTokens2\x97\x0e\n" +
+	"\routput_tokens\x18\x02 \x01(\x03R\foutputTokens2\xb3\x10\n" +
 	"\rClocheService\x12L\n" +
-	"\vRunWorkflow\x12\x1d.cloche.v1.RunWorkflowRequest\x1a\x1e.cloche.v1.RunWorkflowResponse\x12F\n" +
+	"\vRunWorkflow\x12\x1d.cloche.v1.RunWorkflowRequest\x1a\x1e.cloche.v1.RunWorkflowResponse\x12T\n" +
+	"\x0fRunWorkflowSync\x12\x1d.cloche.v1.RunWorkflowRequest\x1a\".cloche.v1.RunWorkflowSyncResponse\x12F\n" +
 	"\tGetStatus\x12\x1b.cloche.v1.GetStatusRequest\x1a\x1c.cloche.v1.GetStatusResponse\x12A\n" +
 	"\n" +
 	"StreamLogs\x12\x1c.cloche.v1.StreamLogsRequest\x1a\x13.cloche.v1.LogEntry0\x01\x12@\n" +
@@ -4776,7 +5465,11 @@ const file_cloche_proto_rawDesc = "" +
 	"\bShutdown\x12\x1a.cloche.v1.ShutdownRequest\x1a\x1b.cloche.v1.ShutdownResponse\x12X\n" +
 	"\x0fDeleteContainer\x12!.cloche.v1.DeleteContainerRequest\x1a\".cloche.v1.DeleteContainerResponse\x12I\n" +
 	"\n" +
-	"ExtractRun\x12\x1c.cloche.v1.ExtractRunRequest\x1a\x1d.cloche.v1.ExtractRunResponse\x12I\n" +
+	"ExtractRun\x12\x1c.cloche.v1.ExtractRunRequest\x1a\x1d.cloche.v1.ExtractRunResponse\x12:\n" +
+	"\x05Prune\x12\x17.cloche.v1.PruneRequest\x1a\x18.cloche.v1.PruneResponse\x12I\n" +
+	"\n" +
+	"DeleteRuns\x12\x1c.cloche.v1.DeleteRunsRequest\x1a\x1d.cloche.v1.DeleteRunsResponse\x12=\n" +
+	"\x06Evolve\x12\x18.cloche.v1.EvolveRequest\x1a\x19.cloche.v1.EvolveResponse\x12I\n" +
 	"\n" +
 	"EnableLoop\x12\x1c.cloche.v1.EnableLoopRequest\x1a\x1d.cloche.v1.EnableLoopResponse\x12L\n" +
 	"\vDisableLoop\x12\x1d.cloche.v1.DisableLoopRequest\x1a\x1e.cloche.v1.DisableLoopResponse\x12I\n" +
@@ -4806,156 +5499,174 @@ func file_cloche_proto_rawDescGZIP() []byte {
 	return file_cloche_proto_rawDescData
 }
 
-var file_cloche_proto_msgTypes = make([]protoimpl.MessageInfo, 70)
+var file_cloche_proto_msgTypes = make([]protoimpl.MessageInfo, 78)
 var file_cloche_proto_goTypes = []any{
 	(*RunWorkflowRequest)(nil),      // 0: cloche.v1.RunWorkflowRequest
 	(*RunWorkflowResponse)(nil),     // 1: cloche.v1.RunWorkflowResponse
-	(*GetStatusRequest)(nil),        // 2: cloche.v1.GetStatusRequest
-	(*GetStatusResponse)(nil),       // 3: cloche.v1.GetStatusResponse
-	(*StepExecutionStatus)(nil),     // 4: cloche.v1.StepExecutionStatus
-	(*StreamLogsRequest)(nil),       // 5: cloche.v1.StreamLogsRequest
-	(*LogEntry)(nil),                // 6: cloche.v1.LogEntry
-	(*StopRunRequest)(nil),          // 7: cloche.v1.StopRunRequest
-	(*StopRunResponse)(nil),         // 8: cloche.v1.StopRunResponse
-	(*ShutdownRequest)(nil),         // 9: cloche.v1.ShutdownRequest
-	(*ShutdownResponse)(nil),        // 10: cloche.v1.ShutdownResponse
-	(*DeleteContainerRequest)(nil),  // 11: cloche.v1.DeleteContainerRequest
-	(*DeleteContainerResponse)(nil), // 12: cloche.v1.DeleteContainerResponse
-	(*ExtractRunRequest)(nil),       // 13: cloche.v1.ExtractRunRequest
-	(*ExtractRunResponse)(nil),      // 14: cloche.v1.ExtractRunResponse
-	(*ListRunsRequest)(nil),         // 15: cloche.v1.ListRunsRequest
-	(*ListRunsResponse)(nil),        // 16: cloche.v1.ListRunsResponse
-	(*RunSummary)(nil),              // 17: cloche.v1.RunSummary
-	(*EnableLoopRequest)(nil),       // 18: cloche.v1.EnableLoopRequest
-	(*EnableLoopResponse)(nil),      // 19: cloche.v1.EnableLoopResponse
-	(*DisableLoopRequest)(nil),      // 20: cloche.v1.DisableLoopRequest
-	(*DisableLoopResponse)(nil),     // 21: cloche.v1.DisableLoopResponse
-	(*ResumeLoopRequest)(nil),       // 22: cloche.v1.ResumeLoopRequest
-	(*ResumeLoopResponse)(nil),      // 23: cloche.v1.ResumeLoopResponse
-	(*QuiesceRunsRequest)(nil),      // 24: cloche.v1.QuiesceRunsRequest
-	(*QuiesceRunsResponse)(nil),     // 25: cloche.v1.QuiesceRunsResponse
-	(*GetProjectInfoRequest)(nil),   // 26: cloche.v1.GetProjectInfoRequest
-	(*Repository)(nil),              // 27: cloche.v1.Repository
-	(*GetProjectInfoResponse)(nil),  // 28: cloche.v1.GetProjectInfoResponse
-	(*GetVersionRequest)(nil),       // 29: cloche.v1.GetVersionRequest
-	(*GetVersionResponse)(nil),      // 30: cloche.v1.GetVersionResponse
-	(*ListTasksRequest)(nil),        // 31: cloche.v1.ListTasksRequest
-	(*TaskSummary)(nil),             // 32: cloche.v1.TaskSummary
-	(*ListTasksResponse)(nil),       // 33: cloche.v1.ListTasksResponse
-	(*GetTaskRequest)(nil),          // 34: cloche.v1.GetTaskRequest
-	(*AttemptSummary)(nil),          // 35: cloche.v1.AttemptSummary
-	(*GetTaskResponse)(nil),         // 36: cloche.v1.GetTaskResponse
-	(*GetAttemptRequest)(nil),       // 37: cloche.v1.GetAttemptRequest
-	(*GetAttemptResponse)(nil),      // 38: cloche.v1.GetAttemptResponse
-	(*CompleteRequest)(nil),         // 39: cloche.v1.CompleteRequest
-	(*CompleteResponse)(nil),        // 40: cloche.v1.CompleteResponse
-	(*GetUsageRequest)(nil),         // 41: cloche.v1.GetUsageRequest
-	(*GetUsageResponse)(nil),        // 42: cloche.v1.GetUsageResponse
-	(*UsageSummary)(nil),            // 43: cloche.v1.UsageSummary
-	(*ConsoleInput)(nil),            // 44: cloche.v1.ConsoleInput
-	(*ConsoleOutput)(nil),           // 45: cloche.v1.ConsoleOutput
-	(*ConsoleStart)(nil),            // 46: cloche.v1.ConsoleStart
-	(*ConsoleStarted)(nil),          // 47: cloche.v1.ConsoleStarted
-	(*TerminalSize)(nil),            // 48: cloche.v1.TerminalSize
-	(*ConsoleExited)(nil),           // 49: cloche.v1.ConsoleExited
-	(*GetContextKeyRequest)(nil),    // 50: cloche.v1.GetContextKeyRequest
-	(*GetContextKeyResponse)(nil),   // 51: cloche.v1.GetContextKeyResponse
-	(*SetContextKeyRequest)(nil),    // 52: cloche.v1.SetContextKeyRequest
-	(*SetContextKeyResponse)(nil),   // 53: cloche.v1.SetContextKeyResponse
-	(*ListContextKeysRequest)(nil),  // 54: cloche.v1.ListContextKeysRequest
-	(*ListContextKeysResponse)(nil), // 55: cloche.v1.ListContextKeysResponse
-	(*AgentMessage)(nil),            // 56: cloche.v1.AgentMessage
-	(*DaemonMessage)(nil),           // 57: cloche.v1.DaemonMessage
-	(*AgentReady)(nil),              // 58: cloche.v1.AgentReady
-	(*ExecuteStep)(nil),             // 59: cloche.v1.ExecuteStep
-	(*StepResult)(nil),              // 60: cloche.v1.StepResult
-	(*StepLog)(nil),                 // 61: cloche.v1.StepLog
-	(*StepStarted)(nil),             // 62: cloche.v1.StepStarted
-	(*HostWorkflowRequest)(nil),     // 63: cloche.v1.HostWorkflowRequest
-	(*HostWorkflowResult)(nil),      // 64: cloche.v1.HostWorkflowResult
-	(*StepCancelled)(nil),           // 65: cloche.v1.StepCancelled
-	(*Shutdown)(nil),                // 66: cloche.v1.Shutdown
-	(*TokenUsage)(nil),              // 67: cloche.v1.TokenUsage
-	nil,                             // 68: cloche.v1.ExecuteStep.ConfigEntry
-	nil,                             // 69: cloche.v1.HostWorkflowRequest.EnvEntry
+	(*RunWorkflowSyncResponse)(nil), // 2: cloche.v1.RunWorkflowSyncResponse
+	(*GetStatusRequest)(nil),        // 3: cloche.v1.GetStatusRequest
+	(*GetStatusResponse)(nil),       // 4: cloche.v1.GetStatusResponse
+	(*StepExecutionStatus)(nil),     // 5: cloche.v1.StepExecutionStatus
+	(*StreamLogsRequest)(nil),       // 6: cloche.v1.StreamLogsRequest
+	(*LogEntry)(nil),                // 7: cloche.v1.LogEntry
+	(*StopRunRequest)(nil),          // 8: cloche.v1.StopRunRequest
+	(*StopRunResponse)(nil),         // 9: cloche.v1.StopRunResponse
+	(*ShutdownRequest)(nil),         // 10: cloche.v1.ShutdownRequest
+	(*ShutdownResponse)(nil),        // 11: cloche.v1.ShutdownResponse
+	(*DeleteContainerRequest)(nil),  // 12: cloche.v1.DeleteContainerRequest
+	(*DeleteContainerResponse)(nil), // 13: cloche.v1.DeleteContainerResponse
+	(*ExtractRunRequest)(nil),       // 14: cloche.v1.ExtractRunRequest
+	(*ExtractRunResponse)(nil),      // 15: cloche.v1.ExtractRunResponse
+	(*PruneRequest)(nil),            // 16: cloche.v1.PruneRequest
+	(*PruneResponse)(nil),           // 17: cloche.v1.PruneResponse
+	(*DeleteRunsRequest)(nil),       // 18: cloche.v1.DeleteRunsRequest
+	(*DeleteRunsResponse)(nil),      // 19: cloche.v1.DeleteRunsResponse
+	(*EvolveRequest)(nil),           // 20: cloche.v1.EvolveRequest
+	(*EvolveResponse)(nil),          // 21: cloche.v1.EvolveResponse
+	(*ListRunsRequest)(nil),         // 22: cloche.v1.ListRunsRequest
+	(*ListRunsResponse)(nil),        // 23: cloche.v1.ListRunsResponse
+	(*RunSummary)(nil),              // 24: cloche.v1.RunSummary
+	(*EnableLoopRequest)(nil),       // 25: cloche.v1.EnableLoopRequest
+	(*EnableLoopResponse)(nil),      // 26: cloche.v1.EnableLoopResponse
+	(*DisableLoopRequest)(nil),      // 27: cloche.v1.DisableLoopRequest
+	(*DisableLoopResponse)(nil),     // 28: cloche.v1.DisableLoopResponse
+	(*ResumeLoopRequest)(nil),       // 29: cloche.v1.ResumeLoopRequest
+	(*ResumeLoopResponse)(nil),      // 30: cloche.v1.ResumeLoopResponse
+	(*QuiesceRunsRequest)(nil),      // 31: cloche.v1.QuiesceRunsRequest
+	(*QuiesceRunsResponse)(nil),     // 32: cloche.v1.QuiesceRunsResponse
+	(*GetProjectInfoRequest)(nil),   // 33: cloche.v1.GetProjectInfoRequest
+	(*Repository)(nil),              // 34: cloche.v1.Repository
+	(*GetProjectInfoResponse)(nil),  // 35: cloche.v1.GetProjectInfoResponse
+	(*GetVersionRequest)(nil),       // 36: cloche.v1.GetVersionRequest
+	(*GetVersionResponse)(nil),      // 37: cloche.v1.GetVersionResponse
+	(*ListTasksRequest)(nil),        // 38: cloche.v1.ListTasksRequest
+	(*TaskSummary)(nil),             // 39: cloche.v1.TaskSummary
+	(*ListTasksResponse)(nil),       // 40: cloche.v1.ListTasksResponse
+	(*GetTaskRequest)(nil),          // 41: cloche.v1.GetTaskRequest
+	(*AttemptSummary)(nil),          // 42: cloche.v1.AttemptSummary
+	(*GetTaskResponse)(nil),         // 43: cloche.v1.GetTaskResponse
+	(*GetAttemptRequest)(nil),       // 44: cloche.v1.GetAttemptRequest
+	(*GetAttemptResponse)(nil),      // 45: cloche.v1.GetAttemptResponse
+	(*CompleteRequest)(nil),         // 46: cloche.v1.CompleteRequest
+	(*CompleteResponse)(nil),        // 47: cloche.v1.CompleteResponse
+	(*GetUsageRequest)(nil),         // 48: cloche.v1.GetUsageRequest
+	(*GetUsageResponse)(nil),        // 49: cloche.v1.GetUsageResponse
+	(*UsageSummary)(nil),            // 50: cloche.v1.UsageSummary
+	(*ConsoleInput)(nil),            // 51: cloche.v1.ConsoleInput
+	(*ConsoleOutput)(nil),           // 52: cloche.v1.ConsoleOutput
+	(*ConsoleStart)(nil),            // 53: cloche.v1.ConsoleStart
+	(*ConsoleStarted)(nil),          // 54: cloche.v1.ConsoleStarted
+	(*TerminalSize)(nil),            // 55: cloche.v1.TerminalSize
+	(*ConsoleExited)(nil),           // 56: cloche.v1.ConsoleExited
+	(*GetContextKeyRequest)(nil),    // 57: cloche.v1.GetContextKeyRequest
+	(*GetContextKeyResponse)(nil),   // 58: cloche.v1.GetContextKeyResponse
+	(*SetContextKeyRequest)(nil),    // 59: cloche.v1.SetContextKeyRequest
+	(*SetContextKeyResponse)(nil),   // 60: cloche.v1.SetContextKeyResponse
+	(*ListContextKeysRequest)(nil),  // 61: cloche.v1.ListContextKeysRequest
+	(*ListContextKeysResponse)(nil), // 62: cloche.v1.ListContextKeysResponse
+	(*AgentMessage)(nil),            // 63: cloche.v1.AgentMessage
+	(*DaemonMessage)(nil),           // 64: cloche.v1.DaemonMessage
+	(*AgentReady)(nil),              // 65: cloche.v1.AgentReady
+	(*ExecuteStep)(nil),             // 66: cloche.v1.ExecuteStep
+	(*StepResult)(nil),              // 67: cloche.v1.StepResult
+	(*StepLog)(nil),                 // 68: cloche.v1.StepLog
+	(*StepStarted)(nil),             // 69: cloche.v1.StepStarted
+	(*HostWorkflowRequest)(nil),     // 70: cloche.v1.HostWorkflowRequest
+	(*HostWorkflowResult)(nil),      // 71: cloche.v1.HostWorkflowResult
+	(*StepCancelled)(nil),           // 72: cloche.v1.StepCancelled
+	(*Shutdown)(nil),                // 73: cloche.v1.Shutdown
+	(*TokenUsage)(nil),              // 74: cloche.v1.TokenUsage
+	nil,                             // 75: cloche.v1.RunWorkflowRequest.ParamsEntry
+	nil,                             // 76: cloche.v1.ExecuteStep.ConfigEntry
+	nil,                             // 77: cloche.v1.HostWorkflowRequest.EnvEntry
 }
 var file_cloche_proto_depIdxs = []int32{
-	4,  // 0: cloche.v1.GetStatusResponse.step_executions:type_name -> cloche.v1.StepExecutionStatus
-	17, // 1: cloche.v1.ListRunsResponse.runs:type_name -> cloche.v1.RunSummary
-	17, // 2: cloche.v1.GetProjectInfoResponse.active_runs:type_name -> cloche.v1.RunSummary
-	27, // 3: cloche.v1.GetProjectInfoResponse.repositories:type_name -> cloche.v1.Repository
-	32, // 4: cloche.v1.ListTasksResponse.tasks:type_name -> cloche.v1.TaskSummary
-	35, // 5: cloche.v1.GetTaskResponse.attempts:type_name -> cloche.v1.AttemptSummary
-	43, // 6: cloche.v1.GetUsageResponse.summaries:type_name -> cloche.v1.UsageSummary
-	46, // 7: cloche.v1.ConsoleInput.start:type_name -> cloche.v1.ConsoleStart
-	48, // 8: cloche.v1.ConsoleInput.resize:type_name -> cloche.v1.TerminalSize
-	47, // 9: cloche.v1.ConsoleOutput.started:type_name -> cloche.v1.ConsoleStarted
-	49, // 10: cloche.v1.ConsoleOutput.exited:type_name -> cloche.v1.ConsoleExited
-	58, // 11: cloche.v1.AgentMessage.ready:type_name -> cloche.v1.AgentReady
-	60, // 12: cloche.v1.AgentMessage.step_result:type_name -> cloche.v1.StepResult
-	61, // 13: cloche.v1.AgentMessage.step_log:type_name -> cloche.v1.StepLog
-	62, // 14: cloche.v1.AgentMessage.step_started:type_name -> cloche.v1.StepStarted
-	63, // 15: cloche.v1.AgentMessage.host_request:type_name -> cloche.v1.HostWorkflowRequest
-	59, // 16: cloche.v1.DaemonMessage.execute_step:type_name -> cloche.v1.ExecuteStep
-	65, // 17: cloche.v1.DaemonMessage.step_cancelled:type_name -> cloche.v1.StepCancelled
-	64, // 18: cloche.v1.DaemonMessage.host_result:type_name -> cloche.v1.HostWorkflowResult
-	66, // 19: cloche.v1.DaemonMessage.shutdown:type_name -> cloche.v1.Shutdown
-	68, // 20: cloche.v1.ExecuteStep.config:type_name -> cloche.v1.ExecuteStep.ConfigEntry
-	67, // 21: cloche.v1.StepResult.token_usage:type_name -> cloche.v1.TokenUsage
-	69, // 22: cloche.v1.HostWorkflowRequest.env:type_name -> cloche.v1.HostWorkflowRequest.EnvEntry
-	0,  // 23: cloche.v1.ClocheService.RunWorkflow:input_type -> cloche.v1.RunWorkflowRequest
-	2,  // 24: cloche.v1.ClocheService.GetStatus:input_type -> cloche.v1.GetStatusRequest
-	5,  // 25: cloche.v1.ClocheService.StreamLogs:input_type -> cloche.v1.StreamLogsRequest
-	7,  // 26: cloche.v1.ClocheService.StopRun:input_type -> cloche.v1.StopRunRequest
-	15, // 27: cloche.v1.ClocheService.ListRuns:input_type -> cloche.v1.ListRunsRequest
-	31, // 28: cloche.v1.ClocheService.ListTasks:input_type -> cloche.v1.ListTasksRequest
-	34, // 29: cloche.v1.ClocheService.GetTask:input_type -> cloche.v1.GetTaskRequest
-	37, // 30: cloche.v1.ClocheService.GetAttempt:input_type -> cloche.v1.GetAttemptRequest
-	9,  // 31: cloche.v1.ClocheService.Shutdown:input_type -> cloche.v1.ShutdownRequest
-	11, // 32: cloche.v1.ClocheService.DeleteContainer:input_type -> cloche.v1.DeleteContainerRequest
-	13, // 33: cloche.v1.ClocheService.ExtractRun:input_type -> cloche.v1.ExtractRunRequest
-	18, // 34: cloche.v1.ClocheService.EnableLoop:input_type -> cloche.v1.EnableLoopRequest
-	20, // 35: cloche.v1.ClocheService.DisableLoop:input_type -> cloche.v1.DisableLoopRequest
-	22, // 36: cloche.v1.ClocheService.ResumeLoop:input_type -> cloche.v1.ResumeLoopRequest
-	24, // 37: cloche.v1.ClocheService.QuiesceRuns:input_type -> cloche.v1.QuiesceRunsRequest
-	26, // 38: cloche.v1.ClocheService.GetProjectInfo:input_type -> cloche.v1.GetProjectInfoRequest
-	29, // 39: cloche.v1.ClocheService.GetVersion:input_type -> cloche.v1.GetVersionRequest
-	39, // 40: cloche.v1.ClocheService.Complete:input_type -> cloche.v1.CompleteRequest
-	41, // 41: cloche.v1.ClocheService.GetUsage:input_type -> cloche.v1.GetUsageRequest
-	44, // 42: cloche.v1.ClocheService.Console:input_type -> cloche.v1.ConsoleInput
-	50, // 43: cloche.v1.ClocheService.GetContextKey:input_type -> cloche.v1.GetContextKeyRequest
-	52, // 44: cloche.v1.ClocheService.SetContextKey:input_type -> cloche.v1.SetContextKeyRequest
-	54, // 45: cloche.v1.ClocheService.ListContextKeys:input_type -> cloche.v1.ListContextKeysRequest
-	56, // 46: cloche.v1.ClocheService.AgentSession:input_type -> cloche.v1.AgentMessage
-	1,  // 47: cloche.v1.ClocheService.RunWorkflow:output_type -> cloche.v1.RunWorkflowResponse
-	3,  // 48: cloche.v1.ClocheService.GetStatus:output_type -> cloche.v1.GetStatusResponse
-	6,  // 49: cloche.v1.ClocheService.StreamLogs:output_type -> cloche.v1.LogEntry
-	8,  // 50: cloche.v1.ClocheService.StopRun:output_type -> cloche.v1.StopRunResponse
-	16, // 51: cloche.v1.ClocheService.ListRuns:output_type -> cloche.v1.ListRunsResponse
-	33, // 52: cloche.v1.ClocheService.ListTasks:output_type -> cloche.v1.ListTasksResponse
-	36, // 53: cloche.v1.ClocheService.GetTask:output_type -> cloche.v1.GetTaskResponse
-	38, // 54: cloche.v1.ClocheService.GetAttempt:output_type -> cloche.v1.GetAttemptResponse
-	10, // 55: cloche.v1.ClocheService.Shutdown:output_type -> cloche.v1.ShutdownResponse
-	12, // 56: cloche.v1.ClocheService.DeleteContainer:output_type -> cloche.v1.DeleteContainerResponse
-	14, // 57: cloche.v1.ClocheService.ExtractRun:output_type -> cloche.v1.ExtractRunResponse
-	19, // 58: cloche.v1.ClocheService.EnableLoop:output_type -> cloche.v1.EnableLoopResponse
-	21, // 59: cloche.v1.ClocheService.DisableLoop:output_type -> cloche.v1.DisableLoopResponse
-	23, // 60: cloche.v1.ClocheService.ResumeLoop:output_type -> cloche.v1.ResumeLoopResponse
-	25, // 61: cloche.v1.ClocheService.QuiesceRuns:output_type -> cloche.v1.QuiesceRunsResponse
-	28, // 62: cloche.v1.ClocheService.GetProjectInfo:output_type -> cloche.v1.GetProjectInfoResponse
-	30, // 63: cloche.v1.ClocheService.GetVersion:output_type -> cloche.v1.GetVersionResponse
-	40, // 64: cloche.v1.ClocheService.Complete:output_type -> cloche.v1.CompleteResponse
-	42, // 65: cloche.v1.ClocheService.GetUsage:output_type -> cloche.v1.GetUsageResponse
-	45, // 66: cloche.v1.ClocheService.Console:output_type -> cloche.v1.ConsoleOutput
-	51, // 67: cloche.v1.ClocheService.GetContextKey:output_type -> cloche.v1.GetContextKeyResponse
-	53, // 68: cloche.v1.ClocheService.SetContextKey:output_type -> cloche.v1.SetContextKeyResponse
-	55, // 69: cloche.v1.ClocheService.ListContextKeys:output_type -> cloche.v1.ListContextKeysResponse
-	57, // 70: cloche.v1.ClocheService.AgentSession:output_type -> cloche.v1.DaemonMessage
-	47, // [47:71] is the sub-list for method output_type
-	23, // [23:47] is the sub-list for method input_type
-	23, // [23:23] is the sub-list for extension type_name
-	23, // [23:23] is the sub-list for extension extendee
-	0,  // [0:23] is the sub-list for field type_name
+	75, // 0: cloche.v1.RunWorkflowRequest.params:type_name -> cloche.v1.RunWorkflowRequest.ParamsEntry
+	5,  // 1: cloche.v1.GetStatusResponse.step_executions:type_name -> cloche.v1.StepExecutionStatus
+	24, // 2: cloche.v1.DeleteRunsResponse.runs:type_name -> cloche.v1.RunSummary
+	24, // 3: cloche.v1.ListRunsResponse.runs:type_name -> cloche.v1.RunSummary
+	24, // 4: cloche.v1.GetProjectInfoResponse.active_runs:type_name -> cloche.v1.RunSummary
+	34, // 5: cloche.v1.GetProjectInfoResponse.repositories:type_name -> cloche.v1.Repository
+	39, // 6: cloche.v1.ListTasksResponse.tasks:type_name -> cloche.v1.TaskSummary
+	42, // 7: cloche.v1.GetTaskResponse.attempts:type_name -> cloche.v1.AttemptSummary
+	50, // 8: cloche.v1.GetUsageResponse.summaries:type_name -> cloche.v1.UsageSummary
+	53, // 9: cloche.v1.ConsoleInput.start:type_name -> cloche.v1.ConsoleStart
+	55, // 10: cloche.v1.ConsoleInput.resize:type_name -> cloche.v1.TerminalSize
+	54, // 11: cloche.v1.ConsoleOutput.started:type_name -> cloche.v1.ConsoleStarted
+	56, // 12: cloche.v1.ConsoleOutput.exited:type_name -> cloche.v1.ConsoleExited
+	65, // 13: cloche.v1.AgentMessage.ready:type_name -> cloche.v1.AgentReady
+	67, // 14: cloche.v1.AgentMessage.step_result:type_name -> cloche.v1.StepResult
+	68, // 15: cloche.v1.AgentMessage.step_log:type_name -> cloche.v1.StepLog
+	69, // 16: cloche.v1.AgentMessage.step_started:type_name -> cloche.v1.StepStarted
+	70, // 17: cloche.v1.AgentMessage.host_request:type_name -> cloche.v1.HostWorkflowRequest
+	66, // 18: cloche.v1.DaemonMessage.execute_step:type_name -> cloche.v1.ExecuteStep
+	72, // 19: cloche.v1.DaemonMessage.step_cancelled:type_name -> cloche.v1.StepCancelled
+	71, // 20: cloche.v1.DaemonMessage.host_result:type_name -> cloche.v1.HostWorkflowResult
+	73, // 21: cloche.v1.DaemonMessage.shutdown:type_name -> cloche.v1.Shutdown
+	76, // 22: cloche.v1.ExecuteStep.config:type_name -> cloche.v1.ExecuteStep.ConfigEntry
+	74, // 23: cloche.v1.StepResult.token_usage:type_name -> cloche.v1.TokenUsage
+	77, // 24: cloche.v1.HostWorkflowRequest.env:type_name -> cloche.v1.HostWorkflowRequest.EnvEntry
+	0,  // 25: cloche.v1.ClocheService.RunWorkflow:input_type -> cloche.v1.RunWorkflowRequest
+	0,  // 26: cloche.v1.ClocheService.RunWorkflowSync:input_type -> cloche.v1.RunWorkflowRequest
+	3,  // 27: cloche.v1.ClocheService.GetStatus:input_type -> cloche.v1.GetStatusRequest
+	6,  // 28: cloche.v1.ClocheService.StreamLogs:input_type -> cloche.v1.StreamLogsRequest
+	8,  // 29: cloche.v1.ClocheService.StopRun:input_type -> cloche.v1.StopRunRequest
+	22, // 30: cloche.v1.ClocheService.ListRuns:input_type -> cloche.v1.ListRunsRequest
+	38, // 31: cloche.v1.ClocheService.ListTasks:input_type -> cloche.v1.ListTasksRequest
+	41, // 32: cloche.v1.ClocheService.GetTask:input_type -> cloche.v1.GetTaskRequest
+	44, // 33: cloche.v1.ClocheService.GetAttempt:input_type -> cloche.v1.GetAttemptRequest
+	10, // 34: cloche.v1.ClocheService.Shutdown:input_type -> cloche.v1.ShutdownRequest
+	12, // 35: cloche.v1.ClocheService.DeleteContainer:input_type -> cloche.v1.DeleteContainerRequest
+	14, // 36: cloche.v1.ClocheService.ExtractRun:input_type -> cloche.v1.ExtractRunRequest
+	16, // 37: cloche.v1.ClocheService.Prune:input_type -> cloche.v1.PruneRequest
+	18, // 38: cloche.v1.ClocheService.DeleteRuns:input_type -> cloche.v1.DeleteRunsRequest
+	20, // 39: cloche.v1.ClocheService.Evolve:input_type -> cloche.v1.EvolveRequest
+	25, // 40: cloche.v1.ClocheService.EnableLoop:input_type -> cloche.v1.EnableLoopRequest
+	27, // 41: cloche.v1.ClocheService.DisableLoop:input_type -> cloche.v1.DisableLoopRequest
+	29, // 42: cloche.v1.ClocheService.ResumeLoop:input_type -> cloche.v1.ResumeLoopRequest
+	31, // 43: cloche.v1.ClocheService.QuiesceRuns:input_type -> cloche.v1.QuiesceRunsRequest
+	33, // 44: cloche.v1.ClocheService.GetProjectInfo:input_type -> cloche.v1.GetProjectInfoRequest
+	36, // 45: cloche.v1.ClocheService.GetVersion:input_type -> cloche.v1.GetVersionRequest
+	46, // 46: cloche.v1.ClocheService.Complete:input_type -> cloche.v1.CompleteRequest
+	48, // 47: cloche.v1.ClocheService.GetUsage:input_type -> cloche.v1.GetUsageRequest
+	51, // 48: cloche.v1.ClocheService.Console:input_type -> cloche.v1.ConsoleInput
+	57, // 49: cloche.v1.ClocheService.GetContextKey:input_type -> cloche.v1.GetContextKeyRequest
+	59, // 50: cloche.v1.ClocheService.SetContextKey:input_type -> cloche.v1.SetContextKeyRequest
+	61, // 51: cloche.v1.ClocheService.ListContextKeys:input_type -> cloche.v1.ListContextKeysRequest
+	63, // 52: cloche.v1.ClocheService.AgentSession:input_type -> cloche.v1.AgentMessage
+	1,  // 53: cloche.v1.ClocheService.RunWorkflow:output_type -> cloche.v1.RunWorkflowResponse
+	2,  // 54: cloche.v1.ClocheService.RunWorkflowSync:output_type -> cloche.v1.RunWorkflowSyncResponse
+	4,  // 55: cloche.v1.ClocheService.GetStatus:output_type -> cloche.v1.GetStatusResponse
+	7,  // 56: cloche.v1.ClocheService.StreamLogs:output_type -> cloche.v1.LogEntry
+	9,  // 57: cloche.v1.ClocheService.StopRun:output_type -> cloche.v1.StopRunResponse
+	23, // 58: cloche.v1.ClocheService.ListRuns:output_type -> cloche.v1.ListRunsResponse
+	40, // 59: cloche.v1.ClocheService.ListTasks:output_type -> cloche.v1.ListTasksResponse
+	43, // 60: cloche.v1.ClocheService.GetTask:output_type -> cloche.v1.GetTaskResponse
+	45, // 61: cloche.v1.ClocheService.GetAttempt:output_type -> cloche.v1.GetAttemptResponse
+	11, // 62: cloche.v1.ClocheService.Shutdown:output_type -> cloche.v1.ShutdownResponse
+	13, // 63: cloche.v1.ClocheService.DeleteContainer:output_type -> cloche.v1.DeleteContainerResponse
+	15, // 64: cloche.v1.ClocheService.ExtractRun:output_type -> cloche.v1.ExtractRunResponse
+	17, // 65: cloche.v1.ClocheService.Prune:output_type -> cloche.v1.PruneResponse
+	19, // 66: cloche.v1.ClocheService.DeleteRuns:output_type -> cloche.v1.DeleteRunsResponse
+	21, // 67: cloche.v1.ClocheService.Evolve:output_type -> cloche.v1.EvolveResponse
+	26, // 68: cloche.v1.ClocheService.EnableLoop:output_type -> cloche.v1.EnableLoopResponse
+	28, // 69: cloche.v1.ClocheService.DisableLoop:output_type -> cloche.v1.DisableLoopResponse
+	30, // 70: cloche.v1.ClocheService.ResumeLoop:output_type -> cloche.v1.ResumeLoopResponse
+	32, // 71: cloche.v1.ClocheService.QuiesceRuns:output_type -> cloche.v1.QuiesceRunsResponse
+	35, // 72: cloche.v1.ClocheService.GetProjectInfo:output_type -> cloche.v1.GetProjectInfoResponse
+	37, // 73: cloche.v1.ClocheService.GetVersion:output_type -> cloche.v1.GetVersionResponse
+	47, // 74: cloche.v1.ClocheService.Complete:output_type -> cloche.v1.CompleteResponse
+	49, // 75: cloche.v1.ClocheService.GetUsage:output_type -> cloche.v1.GetUsageResponse
+	52, // 76: cloche.v1.ClocheService.Console:output_type -> cloche.v1.ConsoleOutput
+	58, // 77: cloche.v1.ClocheService.GetContextKey:output_type -> cloche.v1.GetContextKeyResponse
+	60, // 78: cloche.v1.ClocheService.SetContextKey:output_type -> cloche.v1.SetContextKeyResponse
+	62, // 79: cloche.v1.ClocheService.ListContextKeys:output_type -> cloche.v1.ListContextKeysResponse
+	64, // 80: cloche.v1.ClocheService.AgentSession:output_type -> cloche.v1.DaemonMessage
+	53, // [53:81] is the sub-list for method output_type
+	25, // [25:53] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_cloche_proto_init() }
@@ -4963,36 +5674,38 @@ func file_cloche_proto_init() {
 	if File_cloche_proto != nil {
 		return
 	}
-	file_cloche_proto_msgTypes[44].OneofWrappers = []any{
+	file_cloche_proto_msgTypes[5].OneofWrappers = []any{}
+	file_cloche_proto_msgTypes[51].OneofWrappers = []any{
 		(*ConsoleInput_Start)(nil),
 		(*ConsoleInput_Stdin)(nil),
 		(*ConsoleInput_Resize)(nil),
 	}
-	file_cloche_proto_msgTypes[45].OneofWrappers = []any{
+	file_cloche_proto_msgTypes[52].OneofWrappers = []any{
 		(*ConsoleOutput_Started)(nil),
 		(*ConsoleOutput_Stdout)(nil),
 		(*ConsoleOutput_Exited)(nil),
 	}
-	file_cloche_proto_msgTypes[56].OneofWrappers = []any{
+	file_cloche_proto_msgTypes[63].OneofWrappers = []any{
 		(*AgentMessage_Ready)(nil),
 		(*AgentMessage_StepResult)(nil),
 		(*AgentMessage_StepLog)(nil),
 		(*AgentMessage_StepStarted)(nil),
 		(*AgentMessage_HostRequest)(nil),
 	}
-	file_cloche_proto_msgTypes[57].OneofWrappers = []any{
+	file_cloche_proto_msgTypes[64].OneofWrappers = []any{
 		(*DaemonMessage_ExecuteStep)(nil),
 		(*DaemonMessage_StepCancelled)(nil),
 		(*DaemonMessage_HostResult)(nil),
 		(*DaemonMessage_Shutdown)(nil),
 	}
+	file_cloche_proto_msgTypes[67].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cloche_proto_rawDesc), len(file_cloche_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   70,
+			NumMessages:   78,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,6 +20,7 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	ClocheService_RunWorkflow_FullMethodName     = "/cloche.v1.ClocheService/RunWorkflow"
+	ClocheService_RunWorkflowSync_FullMethodName = "/cloche.v1.ClocheService/RunWorkflowSync"
 	ClocheService_GetStatus_FullMethodName       = "/cloche.v1.ClocheService/GetStatus"
 	ClocheService_StreamLogs_FullMethodName      = "/cloche.v1.ClocheService/StreamLogs"
 	ClocheService_StopRun_FullMethodName         = "/cloche.v1.ClocheService/StopRun"
@@ -30,6 +31,9 @@ const (
 	ClocheService_Shutdown_FullMethodName        = "/cloche.v1.ClocheService/Shutdown"
 	ClocheService_DeleteContainer_FullMethodName = "/cloche.v1.ClocheService/DeleteContainer"
 	ClocheService_ExtractRun_FullMethodName      = "/cloche.v1.ClocheService/ExtractRun"
+	ClocheService_Prune_FullMethodName           = "/cloche.v1.ClocheService/Prune"
+	ClocheService_DeleteRuns_FullMethodName      = "/cloche.v1.ClocheService/DeleteRuns"
+	ClocheService_Evolve_FullMethodName          = "/cloche.v1.ClocheService/Evolve"
 	ClocheService_EnableLoop_FullMethodName      = "/cloche.v1.ClocheService/EnableLoop"
 	ClocheService_DisableLoop_FullMethodName     = "/cloche.v1.ClocheService/DisableLoop"
 	ClocheService_ResumeLoop_FullMethodName      = "/cloche.v1.ClocheService/ResumeLoop"
@@ -50,6 +54,11 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ClocheServiceClient interface {
 	RunWorkflow(ctx context.Context, in *RunWorkflowRequest, opts ...grpc.CallOption) (*RunWorkflowResponse, error)
+	// RunWorkflowSync starts a workflow like RunWorkflow but blocks until the run
+	// reaches a terminal state, returning the final state in the same call. The
+	// wait ends with the client's context error if it is cancelled or its
+	// deadline passes; the run itself keeps going.
+	RunWorkflowSync(ctx context.Context, in *RunWorkflowRequest, opts ...grpc.CallOption) (*RunWorkflowSyncResponse, error)
 	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
 	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
 	StopRun(ctx context.Context, in *StopRunRequest, opts ...grpc.CallOption) (*StopRunResponse, error)
@@ -61,6 +70,16 @@ type ClocheServiceClient interface {
 	DeleteContainer(ctx context.Context, in *DeleteContainerRequest, opts ...grpc.CallOption) (*DeleteContainerResponse, error)
 	// ExtractRun copies the container workspace to a branch or directory on the host.
 	ExtractRun(ctx context.Context, in *ExtractRunRequest, opts ...grpc.CallOption) (*ExtractRunResponse, error)
+	// Prune deletes old finished runs, removes workspace snapshots that no
+	// longer belong to a run, and removes leftover containers of finished runs.
+	Prune(ctx context.Context, in *PruneRequest, opts ...grpc.CallOption) (*PruneResponse, error)
+	// DeleteRuns deletes finished runs matching a filter, with their captures,
+	// containers and on-disk artifacts. dry_run reports the matches only.
+	DeleteRuns(ctx context.Context, in *DeleteRunsRequest, opts ...grpc.CallOption) (*DeleteRunsResponse, error)
+	// Evolve runs an evolution pass for a workflow immediately, bypassing the
+	// post-run debounce. since_run_id overrides the collection window that is
+	// normally derived from the previous evolution.
+	Evolve(ctx context.Context, in *EvolveRequest, opts ...grpc.CallOption) (*EvolveResponse, error)
 	EnableLoop(ctx context.Context, in *EnableLoopRequest, opts ...grpc.CallOption) (*EnableLoopResponse, error)
 	DisableLoop(ctx context.Context, in *DisableLoopRequest, opts ...grpc.CallOption) (*DisableLoopResponse, error)
 	ResumeLoop(ctx context.Context, in *ResumeLoopRequest, opts ...grpc.CallOption) (*ResumeLoopResponse, error)
@@ -111,6 +130,16 @@ func (c *clocheServiceClient) RunWorkflow(ctx context.Context, in *RunWorkflowRe
 	return out, nil
 }
 
+func (c *clocheServiceClient) RunWorkflowSync(ctx context.Context, in *RunWorkflowRequest, opts ...grpc.CallOption) (*RunWorkflowSyncResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunWorkflowSyncResponse)
+	err := c.cc.Invoke(ctx, ClocheService_RunWorkflowSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *clocheServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetStatusResponse)
@@ -220,6 +249,36 @@ func (c *clocheServiceClient) ExtractRun(ctx context.Context, in *ExtractRunRequ
 	return out, nil
 }
 
+func (c *clocheServiceClient) Prune(ctx context.Context, in *PruneRequest, opts ...grpc.CallOption) (*PruneResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PruneResponse)
+	err := c.cc.Invoke(ctx, ClocheService_Prune_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clocheServiceClient) DeleteRuns(ctx context.Context, in *DeleteRunsRequest, opts ...grpc.CallOption) (*DeleteRunsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRunsResponse)
+	err := c.cc.Invoke(ctx, ClocheService_DeleteRuns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clocheServiceClient) Evolve(ctx context.Context, in *EvolveRequest, opts ...grpc.CallOption) (*EvolveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EvolveResponse)
+	err := c.cc.Invoke(ctx, ClocheService_Evolve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *clocheServiceClient) EnableLoop(ctx context.Context, in *EnableLoopRequest, opts ...grpc.CallOption) (*EnableLoopResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(EnableLoopResponse)
@@ -361,6 +420,11 @@ type ClocheService_AgentSessionClient = grpc.BidiStreamingClient[AgentMessage, D
 // for forward compatibility.
 type ClocheServiceServer interface {
 	RunWorkflow(context.Context, *RunWorkflowRequest) (*RunWorkflowResponse, error)
+	// RunWorkflowSync starts a workflow like RunWorkflow but blocks until the run
+	// reaches a terminal state, returning the final state in the same call. The
+	// wait ends with the client's context error if it is cancelled or its
+	// deadline passes; the run itself keeps going.
+	RunWorkflowSync(context.Context, *RunWorkflowRequest) (*RunWorkflowSyncResponse, error)
 	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
 	StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error
 	StopRun(context.Context, *StopRunRequest) (*StopRunResponse, error)
@@ -372,6 +436,16 @@ type ClocheServiceServer interface {
 	DeleteContainer(context.Context, *DeleteContainerRequest) (*DeleteContainerResponse, error)
 	// ExtractRun copies the container workspace to a branch or directory on the host.
 	ExtractRun(context.Context, *ExtractRunRequest) (*ExtractRunResponse, error)
+	// Prune deletes old finished runs, removes workspace snapshots that no
+	// longer belong to a run, and removes leftover containers of finished runs.
+	Prune(context.Context, *PruneRequest) (*PruneResponse, error)
+	// DeleteRuns deletes finished runs matching a filter, with their captures,
+	// containers and on-disk artifacts. dry_run reports the matches only.
+	DeleteRuns(context.Context, *DeleteRunsRequest) (*DeleteRunsResponse, error)
+	// Evolve runs an evolution pass for a workflow immediately, bypassing the
+	// post-run debounce. since_run_id overrides the collection window that is
+	// normally derived from the previous evolution.
+	Evolve(context.Context, *EvolveRequest) (*EvolveResponse, error)
 	EnableLoop(context.Context, *EnableLoopRequest) (*EnableLoopResponse, error)
 	DisableLoop(context.Context, *DisableLoopRequest) (*DisableLoopResponse, error)
 	ResumeLoop(context.Context, *ResumeLoopRequest) (*ResumeLoopResponse, error)
@@ -415,6 +489,9 @@ type UnimplementedClocheServiceServer struct{}
 func (UnimplementedClocheServiceServer) RunWorkflow(context.Context, *RunWorkflowRequest) (*RunWorkflowResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method RunWorkflow not implemented")
 }
+func (UnimplementedClocheServiceServer) RunWorkflowSync(context.Context, *RunWorkflowRequest) (*RunWorkflowSyncResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RunWorkflowSync not implemented")
+}
 func (UnimplementedClocheServiceServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetStatus not implemented")
 }
@@ -445,6 +522,15 @@ func (UnimplementedClocheServiceServer) DeleteContainer(context.Context, *Delete
 func (UnimplementedClocheServiceServer) ExtractRun(context.Context, *ExtractRunRequest) (*ExtractRunResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ExtractRun not implemented")
 }
+func (UnimplementedClocheServiceServer) Prune(context.Context, *PruneRequest) (*PruneResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Prune not implemented")
+}
+func (UnimplementedClocheServiceServer) DeleteRuns(context.Context, *DeleteRunsRequest) (*DeleteRunsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteRuns not implemented")
+}
+func (UnimplementedClocheServiceServer) Evolve(context.Context, *EvolveRequest) (*EvolveResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Evolve not implemented")
+}
 func (UnimplementedClocheServiceServer) EnableLoop(context.Context, *EnableLoopRequest) (*EnableLoopResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method EnableLoop not implemented")
 }
@@ -523,6 +609,24 @@ func _ClocheService_RunWorkflow_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ClocheService_RunWorkflowSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunWorkflowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClocheServiceServer).RunWorkflowSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClocheService_RunWorkflowSync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClocheServiceServer).RunWorkflowSync(ctx, req.(*RunWorkflowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ClocheService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetStatusRequest)
 	if err := dec(in); err != nil {
@@ -696,6 +800,60 @@ func _ClocheService_ExtractRun_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ClocheService_Prune_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClocheServiceServer).Prune(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClocheService_Prune_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClocheServiceServer).Prune(ctx, req.(*PruneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClocheService_DeleteRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClocheServiceServer).DeleteRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClocheService_DeleteRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClocheServiceServer).DeleteRuns(ctx, req.(*DeleteRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClocheService_Evolve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClocheServiceServer).Evolve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClocheService_Evolve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClocheServiceServer).Evolve(ctx, req.(*EvolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ClocheService_EnableLoop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EnableLoopRequest)
 	if err := dec(in); err != nil {
@@ -919,6 +1077,10 @@ var ClocheService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RunWorkflow",
 			Handler:    _ClocheService_RunWorkflow_Handler,
 		},
+		{
+			MethodName: "RunWorkflowSync",
+			Handler:    _ClocheService_RunWorkflowSync_Handler,
+		},
 		{
 			MethodName: "GetStatus",
 			Handler:    _ClocheService_GetStatus_Handler,
@@ -955,6 +1117,18 @@ var ClocheService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ExtractRun",
 			Handler:    _ClocheService_ExtractRun_Handler,
 		},
+		{
+			MethodName: "Prune",
+			Handler:    _ClocheService_Prune_Handler,
+		},
+		{
+			MethodName: "DeleteRuns",
+			Handler:    _ClocheService_DeleteRuns_Handler,
+		},
+		{
+			MethodName: "Evolve",
+			Handler:    _ClocheService_Evolve_Handler,
+		},
 		{
 			MethodName: "EnableLoop",
 			Handler:    _ClocheService_EnableLoop_Handler,
@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/cloche-dev/cloche/internal/agent"
@@ -34,17 +37,57 @@ func main() {
 	}
 
 	runID := os.Getenv("CLOCHE_RUN_ID")
+	workflowName := os.Getenv("CLOCHE_WORKFLOW_NAME")
 	taskID := os.Getenv("CLOCHE_TASK_ID")
 	attemptID := os.Getenv("CLOCHE_ATTEMPT_ID")
 
 	workDir, _ := os.Getwd()
 
+	// --concurrency N (or CLOCHE_AGENT_CONCURRENCY) lets up to N fanout
+	// agent steps run at once in the shared workspace; the default runs them
+	// one at a time.
+	concurrencyStr := os.Getenv("CLOCHE_AGENT_CONCURRENCY")
+	for i := 1; i < len(os.Args); i++ {
+		switch {
+		case os.Args[i] == "--concurrency" && i+1 < len(os.Args):
+			i++
+			concurrencyStr = os.Args[i]
+		case strings.HasPrefix(os.Args[i], "--concurrency="):
+			concurrencyStr = strings.TrimPrefix(os.Args[i], "--concurrency=")
+		}
+	}
+	concurrency := 1
+	if concurrencyStr != "" {
+		n, err := strconv.Atoi(concurrencyStr)
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "error: --concurrency must be a positive integer, got %q\n", concurrencyStr)
+			os.Exit(1)
+		}
+		concurrency = n
+	}
+
+	promptChars, _ := strconv.Atoi(os.Getenv("CLOCHE_PROMPT_CHARS"))
+
+	// CLOCHE_PARAMS carries "cloche run --param" overrides as a JSON object.
+	var params map[string]string
+	if raw := os.Getenv("CLOCHE_PARAMS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &params); err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid CLOCHE_PARAMS: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	sess := agent.NewSession(agent.SessionConfig{
-		Addr:      addr,
-		RunID:     runID,
-		AttemptID: attemptID,
-		TaskID:    taskID,
-		WorkDir:   workDir,
+		Addr:         addr,
+		RunID:        runID,
+		WorkflowName: workflowName,
+		AttemptID:    attemptID,
+		TaskID:       taskID,
+		WorkDir:      workDir,
+		Concurrency:  concurrency,
+		PromptChars:  promptChars,
+		Params:       params,
+		SavePrompts:  os.Getenv("CLOCHE_SAVE_PROMPTS") == "1",
 	})
 
 	if err := sess.Run(ctx); err != nil {
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/cloche-dev/cloche/internal/adapters/agents/prompt"
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/dsl"
+	"github.com/cloche-dev/cloche/internal/logstream"
+)
+
+// agentRunTaskID names the scratch state directory (.cloche/runs/<id>/) that
+// "cloche agent run" uses for the user prompt and attempt counter. It is
+// cleared before and after each invocation so every run is a first attempt.
+const agentRunTaskID = "agent-run"
+
+// agentRunOptions configures a single local agent step execution.
+type agentRunOptions struct {
+	WorkflowFile string
+	WorkflowName string // required when the file declares several workflows
+	StepName     string
+	Prompt       string // user request, as set by "cloche run --prompt"
+	AgentCommand string // overrides every other agent_command source
+	WorkDir      string
+}
+
+func cmdAgent(args []string) {
+	if len(args) == 0 || args[0] != "run" {
+		fmt.Fprintf(os.Stderr, "usage: cloche agent run <workflow-file> <step> [--prompt <text>] [--workflow <name>] [--agent-command <cmd>]\n")
+		os.Exit(1)
+	}
+
+	var opts agentRunOptions
+	var positional []string
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--prompt", "-p":
+			if i+1 < len(rest) {
+				i++
+				opts.Prompt = rest[i]
+			}
+		case "--workflow", "-w":
+			if i+1 < len(rest) {
+				i++
+				opts.WorkflowName = rest[i]
+			}
+		case "--agent-command":
+			if i+1 < len(rest) {
+				i++
+				opts.AgentCommand = rest[i]
+			}
+		default:
+			if strings.HasPrefix(rest[i], "-") {
+				fmt.Fprintf(os.Stderr, "cloche agent run: unknown flag %q\n", rest[i])
+				os.Exit(1)
+			}
+			positional = append(positional, rest[i])
+		}
+	}
+	if len(positional) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: cloche agent run <workflow-file> <step> [--prompt <text>] [--workflow <name>] [--agent-command <cmd>]\n")
+		os.Exit(1)
+	}
+	opts.WorkflowFile, opts.StepName = positional[0], positional[1]
+	opts.WorkDir, _ = os.Getwd()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	os.Exit(agentRun(ctx, opts, os.Stdout, os.Stderr))
+}
+
+// agentRun executes one agent step from a workflow file in opts.WorkDir
+// through the prompt adapter, without a daemon, and prints the step's output
+// and result. Returns 0 when the agent produced a result, 1 otherwise.
+// Separated for testability.
+func agentRun(ctx context.Context, opts agentRunOptions, stdout, stderr io.Writer) int {
+	wf, err := loadAgentRunWorkflow(opts.WorkflowFile, opts.WorkflowName)
+	if err != nil {
+		fmt.Fprintf(stderr, "cloche agent run: %v\n", err)
+		return 1
+	}
+	step, ok := wf.Steps[opts.StepName]
+	if !ok {
+		fmt.Fprintf(stderr, "cloche agent run: workflow %q has no step %q\n", wf.Name, opts.StepName)
+		return 1
+	}
+	if step.Type != domain.StepTypeAgent {
+		fmt.Fprintf(stderr, "cloche agent run: step %q is a %s step, not an agent step\n", step.Name, step.Type)
+		return 1
+	}
+
+	adapter := prompt.New()
+	if cmd, ok := os.LookupEnv("CLOCHE_AGENT_COMMAND"); ok {
+		adapter.Commands = prompt.ParseCommands(cmd)
+	}
+	// Same precedence as a real run: step (or its agent declaration) over the
+	// workflow's host/container block over the environment.
+	for _, block := range []string{"host", "container"} {
+		if cmd := wf.Config[block+".agent_command"]; cmd != "" {
+			adapter.Commands = prompt.ParseCommands(cmd)
+		}
+		if args := wf.Config[block+".agent_args"]; args != "" {
+			adapter.ExplicitArgs = domain.SplitArgs(args)
+		}
+	}
+	if cmd := step.Config["agent_command"]; cmd != "" {
+		adapter.Commands = prompt.ParseCommands(cmd)
+	}
+	if args := step.Config["agent_args"]; args != "" {
+		adapter.ExplicitArgs = domain.SplitArgs(args)
+	}
+	if opts.AgentCommand != "" {
+		adapter.Commands = prompt.ParseCommands(opts.AgentCommand)
+	}
+	adapter.TaskID = agentRunTaskID
+
+	stateDir := filepath.Join(opts.WorkDir, ".cloche", "runs", agentRunTaskID)
+	_ = os.RemoveAll(stateDir)
+	defer os.RemoveAll(stateDir)
+	if opts.Prompt != "" {
+		if err := os.MkdirAll(stateDir, 0755); err != nil {
+			fmt.Fprintf(stderr, "cloche agent run: %v\n", err)
+			return 1
+		}
+		if err := os.WriteFile(filepath.Join(stateDir, "prompt.txt"), []byte(opts.Prompt), 0644); err != nil {
+			fmt.Fprintf(stderr, "cloche agent run: %v\n", err)
+			return 1
+		}
+	}
+
+	// The adapter appends each invocation's output to the step log; remember
+	// where this one starts so only its output is printed.
+	logPath := filepath.Join(opts.WorkDir, ".cloche", "output", step.Name+".log")
+	var offset int64
+	if info, err := os.Stat(logPath); err == nil {
+		offset = info.Size()
+	}
+
+	fmt.Fprintf(stderr, "Running step %q of workflow %q with %s...\n", step.Name, wf.Name, strings.Join(adapter.Commands, ","))
+	sr, err := adapter.Execute(ctx, step, opts.WorkDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "cloche agent run: %v\n", err)
+		return 1
+	}
+
+	if data, err := os.ReadFile(logPath); err == nil && int64(len(data)) > offset {
+		out := logstream.ParseClaudeStream(data[offset:])
+		fmt.Fprint(stdout, string(out))
+		if len(out) > 0 && out[len(out)-1] != '\n' {
+			fmt.Fprintln(stdout)
+		}
+	}
+	fmt.Fprintf(stdout, "\nResult: %s\n", colorStatus(sr.Result))
+	if sr.Usage != nil {
+		fmt.Fprintf(stdout, "Tokens: %d in, %d out\n", sr.Usage.InputTokens, sr.Usage.OutputTokens)
+	}
+	return 0
+}
+
+// loadAgentRunWorkflow parses path and returns the named workflow, or the
+// only workflow in the file when name is empty, with agents, context files,
+// params, and defaults resolved into its steps as for a real run.
+func loadAgentRunWorkflow(path, name string) (*domain.Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	wfs, err := dsl.ParseAll(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	var wf *domain.Workflow
+	if name != "" {
+		wf = wfs[name]
+		if wf == nil {
+			return nil, fmt.Errorf("%s does not declare workflow %q", path, name)
+		}
+	} else if len(wfs) == 1 {
+		for _, only := range wfs {
+			wf = only
+		}
+	} else {
+		names := make([]string, 0, len(wfs))
+		for n := range wfs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%s declares several workflows (%s); pick one with --workflow", path, strings.Join(names, ", "))
+	}
+
+	if err := wf.Validate(); err != nil {
+		return nil, err
+	}
+	wf.Resolve()
+	return wf, nil
+}
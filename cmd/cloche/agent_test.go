@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentRun_SingleStep(t *testing.T) {
+	dir := t.TempDir()
+	mockAgent := filepath.Join(dir, "mock-agent.sh")
+	require.NoError(t, os.WriteFile(mockAgent, []byte("#!/bin/sh\ncat > captured_prompt.txt\necho 'reviewed the change'\necho CLOCHE_RESULT:needs-work\n"), 0755))
+
+	wfPath := filepath.Join(dir, "develop.cloche")
+	require.NoError(t, os.WriteFile(wfPath, []byte(`workflow develop {
+  step review {
+    prompt = "Review the diff."
+    results = [approved, needs-work]
+  }
+  step test {
+    run = "make test"
+    results = [success, fail]
+  }
+  review:approved -> test
+  review:needs-work -> abort
+  test:success -> done
+  test:fail -> abort
+}
+`), 0644))
+
+	opts := agentRunOptions{
+		WorkflowFile: wfPath,
+		StepName:     "review",
+		Prompt:       "Focus on error handling.",
+		AgentCommand: mockAgent,
+		WorkDir:      dir,
+	}
+	var stdout, stderr bytes.Buffer
+	code := agentRun(context.Background(), opts, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+
+	assert.Contains(t, stdout.String(), "reviewed the change")
+	assert.Contains(t, stdout.String(), "Result: ")
+	assert.Contains(t, stdout.String(), "needs-work")
+
+	captured, err := os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(captured), "Review the diff.")
+	assert.Contains(t, string(captured), "## User Request\nFocus on error handling.")
+	assert.Contains(t, string(captured), "CLOCHE_RESULT:approved")
+
+	// Scratch run state is removed afterwards.
+	assert.NoDirExists(t, filepath.Join(dir, ".cloche", "runs", agentRunTaskID))
+
+	// Script steps are rejected.
+	opts.StepName = "test"
+	stderr.Reset()
+	assert.Equal(t, 1, agentRun(context.Background(), opts, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `step "test" is a script step, not an agent step`)
+}
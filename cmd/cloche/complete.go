@@ -15,9 +15,9 @@ import (
 
 // completionSubcommands is the canonical list of all cloche subcommands.
 var completionSubcommands = []string{
-	"complete", "delete", "get", "health", "help", "init", "list", "logs",
-	"loop", "poll", "project", "resume", "run", "set", "shutdown", "status",
-	"stop", "tasks", "validate", "workflow",
+	"agent", "complete", "delete", "evolve", "get", "health", "help", "init", "list", "logs",
+	"loop", "poll", "project", "prune", "resume", "rm", "run", "set", "shutdown", "status",
+	"steps", "stop", "tasks", "validate", "workflow",
 }
 
 // cmdComplete handles `cloche complete --index <n> -- <word0> <word1> ...`.
@@ -87,10 +87,7 @@ func resolveCompletions(index int, words []string) []string {
 // queryDaemonCompletions contacts the daemon and calls the Complete RPC.
 // Returns nil if the daemon is unavailable or returns an error.
 func queryDaemonCompletions(index int, words []string, projectDir string) []string {
-	addr := os.Getenv("CLOCHE_ADDR")
-	if addr == "" {
-		addr = config.DefaultAddr()
-	}
+	addr := config.ClientAddr()
 
 	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -150,12 +147,14 @@ func staticCompletions(subcommand string, index int, words []string, cur string)
 		switch prev {
 		case "--state", "-s":
 			candidates = []string{"running", "pending", "succeeded", "failed", "cancelled"}
+		case "--sort":
+			candidates = []string{"started_at", "completed_at", "updated_at"}
 		default:
-			candidates = []string{"--all", "--runs", "--state", "-s", "--project", "-p", "--limit", "-n"}
+			candidates = []string{"--all", "--runs", "--sort", "--state", "-s", "--project", "-p", "--limit", "-n"}
 		}
 
 	case "loop":
-		candidates = []string{"once", "stop", "resume", "--max", "--hard"}
+		candidates = []string{"once", "stop", "resume", "--max", "--hard", "--poll-interval", "--poll-timeout"}
 
 	case "resume":
 		candidates = []string{"--no-rebuild", "--clean"}
@@ -167,6 +166,16 @@ func staticCompletions(subcommand string, index int, words []string, cur string)
 	case "shutdown":
 		candidates = []string{"--force", "-f"}
 
+	case "rm":
+		switch prev {
+		case "--workflow", "-w":
+			candidates = localWorkflowNames()
+		case "--older-than":
+			// no static candidates
+		default:
+			candidates = []string{"--failed", "--older-than", "--workflow", "-w", "--dry-run", "-n", "--yes", "-y", "--project", "-p", "--all"}
+		}
+
 	case "init":
 		switch prev {
 		case "--base-image":
@@ -178,7 +187,14 @@ func staticCompletions(subcommand string, index int, words []string, cur string)
 		}
 
 	case "validate":
-		// no flags yet
+		switch prev {
+		case "--workflow":
+			candidates = localWorkflowNames()
+		case "--project":
+			// no static candidates
+		default:
+			candidates = []string{"--project", "--workflow", "--json", "--strict"}
+		}
 
 	case "health", "project", "tasks":
 		// no flags
@@ -86,10 +86,7 @@ func cmdDoctor(args []string) {
 		}
 	}
 
-	addr := os.Getenv("CLOCHE_ADDR")
-	if addr == "" {
-		addr = config.DefaultAddr()
-	}
+	addr := config.ClientAddr()
 
 	dr := &doctorRunner{
 		verbose:    verbose,
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/cloche-dev/cloche/internal/adapters/sqlite"
+	"github.com/cloche-dev/cloche/internal/config"
+	"github.com/cloche-dev/cloche/internal/evolution"
+)
+
+func cmdEvolve(client pb.ClocheServiceClient, args []string) {
+	req := &pb.EvolveRequest{}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--since":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "cloche evolve: --since requires a run ID\n")
+				os.Exit(1)
+			}
+			i++
+			req.SinceRunId = args[i]
+		case "--project", "-p":
+			if i+1 < len(args) {
+				i++
+				req.ProjectDir = args[i]
+			}
+		default:
+			if req.WorkflowName == "" && !strings.HasPrefix(args[i], "-") {
+				req.WorkflowName = args[i]
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "cloche evolve: unknown argument %q\n", args[i])
+			os.Exit(1)
+		}
+	}
+
+	if req.WorkflowName == "" {
+		fmt.Fprintf(os.Stderr, "usage: cloche evolve <workflow> [--since <run-id>] [--project <dir>]\n       cloche evolve --explain <evolution-id> [--project <dir>]\n")
+		os.Exit(1)
+	}
+	if req.ProjectDir == "" {
+		req.ProjectDir, _ = os.Getwd()
+	}
+
+	// A pass makes several LLM calls; the default 30s command timeout is far
+	// too short, so wait for it to finish.
+	os.Exit(evolveRun(context.Background(), client, req, os.Stdout, os.Stderr))
+}
+
+// evolveRun calls the Evolve RPC and prints a summary of the pass.
+// Returns 0 on success, 1 if the RPC failed. Separated for testability.
+func evolveRun(ctx context.Context, client pb.ClocheServiceClient, req *pb.EvolveRequest, stdout, stderr io.Writer) int {
+	resp, err := client.Evolve(ctx, req)
+	if err != nil {
+		fmt.Fprintf(stderr, "cloche evolve: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "Evolution:      %s\n", resp.EvolutionId)
+	if resp.Classification != "" {
+		fmt.Fprintf(stdout, "Classification: %s\n", resp.Classification)
+	}
+	fmt.Fprintf(stdout, "Runs collected: %d\n", resp.RunsCollected)
+	fmt.Fprintf(stdout, "Changes:        %d\n", resp.Changes)
+	for _, detail := range resp.ChangeDetails {
+		fmt.Fprintf(stdout, "  %s\n", detail)
+	}
+	if resp.KnowledgeDelta != "" {
+		fmt.Fprintf(stdout, "Knowledge:      %s\n", resp.KnowledgeDelta)
+	}
+	return 0
+}
+
+// hasExplainFlag reports whether an evolve command line asks for --explain,
+// which is answered from local records without a daemon connection.
+func hasExplainFlag(args []string) bool {
+	for _, arg := range args {
+		if arg == "--explain" {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdEvolveExplain prints the reasoning trail of one evolution, read from
+// the project's audit log (.cloche/evolution/log.jsonl) or, for an entry no
+// longer in the log, from the daemon's database.
+func cmdEvolveExplain(args []string) {
+	var id, projectDir string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--explain":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "cloche evolve: --explain requires an evolution ID\n")
+				os.Exit(1)
+			}
+			i++
+			id = args[i]
+		case "--project", "-p":
+			if i+1 < len(args) {
+				i++
+				projectDir = args[i]
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "cloche evolve: unknown argument %q\n", args[i])
+			os.Exit(1)
+		}
+	}
+	if projectDir == "" {
+		projectDir, _ = os.Getwd()
+	}
+
+	audit := &evolution.AuditLogger{ProjectDir: projectDir}
+	result, err := audit.Find(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cloche evolve: %v\n", err)
+		os.Exit(1)
+	}
+	if result == nil {
+		result = lookupEvolutionInStore(id)
+	}
+	if result == nil {
+		fmt.Fprintf(os.Stderr, "cloche evolve: evolution %q not found in %s\n", id, projectDir)
+		os.Exit(1)
+	}
+	writeEvolutionExplanation(os.Stdout, result)
+}
+
+// lookupEvolutionInStore returns the stored record of an evolution as a
+// result, or nil if the database is unavailable or has no such entry. The
+// store keeps the classification but not the lessons or change details.
+func lookupEvolutionInStore(id string) *evolution.EvolutionResult {
+	store, err := sqlite.NewStore(envOrDefault("CLOCHE_DB", config.DefaultDBPath()))
+	if err != nil {
+		return nil
+	}
+	defer store.Close()
+	entry, err := store.GetEvolution(context.Background(), id)
+	if err != nil || entry == nil {
+		return nil
+	}
+	return &evolution.EvolutionResult{
+		ID:                       entry.ID,
+		ProjectDir:               entry.ProjectDir,
+		WorkflowName:             entry.WorkflowName,
+		TriggerRunID:             entry.TriggerRunID,
+		Timestamp:                entry.CreatedAt.Format(time.RFC3339),
+		Classification:           entry.Classification,
+		ClassificationRationale:  entry.ClassificationRationale,
+		ClassificationConfidence: entry.ClassificationConfidence,
+		KnowledgeDelta:           entry.KnowledgeDelta,
+	}
+}
+
+// writeEvolutionExplanation renders why an evolution made its changes: the
+// classification and its rationale, the lessons the reflector drew from the
+// collected runs, and the changes applied for them.
+func writeEvolutionExplanation(w io.Writer, r *evolution.EvolutionResult) {
+	fmt.Fprintf(w, "Evolution:      %s\n", r.ID)
+	fmt.Fprintf(w, "Workflow:       %s\n", r.WorkflowName)
+	if r.Timestamp != "" {
+		fmt.Fprintf(w, "Time:           %s\n", r.Timestamp)
+	}
+	if r.TriggerRunID != "" {
+		fmt.Fprintf(w, "Trigger run:    %s\n", r.TriggerRunID)
+	}
+	fmt.Fprintf(w, "Runs collected: %d\n", r.RunsCollected)
+
+	fmt.Fprintln(w)
+	classification := r.Classification
+	if classification == "" {
+		classification = "(none)"
+	}
+	if r.ClassificationConfidence != "" {
+		classification += " (confidence: " + r.ClassificationConfidence + ")"
+	}
+	fmt.Fprintf(w, "Classification: %s\n", classification)
+	if r.ClassificationRationale != "" {
+		fmt.Fprintf(w, "  %s\n", r.ClassificationRationale)
+	}
+
+	fmt.Fprintln(w)
+	if len(r.Lessons) == 0 {
+		fmt.Fprintln(w, "Lessons: none recorded")
+	} else {
+		fmt.Fprintf(w, "Lessons (%d):\n", len(r.Lessons))
+	}
+	for i, l := range r.Lessons {
+		fmt.Fprintf(w, "  %d. [%s] %s\n", i+1, l.Category, l.Insight)
+		if l.SuggestedAction != "" {
+			fmt.Fprintf(w, "     Action:     %s\n", l.SuggestedAction)
+		}
+		if l.Confidence != "" {
+			fmt.Fprintf(w, "     Confidence: %s\n", l.Confidence)
+		}
+		for _, e := range l.Evidence {
+			fmt.Fprintf(w, "     Evidence:   %s\n", e)
+		}
+	}
+
+	fmt.Fprintln(w)
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(w, "Changes: none")
+	} else {
+		fmt.Fprintf(w, "Changes (%d):\n", len(r.Changes))
+	}
+	for _, c := range r.Changes {
+		fmt.Fprintf(w, "  %s %s\n", c.Type, c.File)
+		if c.Reason != "" {
+			fmt.Fprintf(w, "    Reason:   %s\n", c.Reason)
+		}
+		for _, line := range c.Summary {
+			fmt.Fprintf(w, "    - %s\n", line)
+		}
+		if c.Snapshot != "" {
+			fmt.Fprintf(w, "    Snapshot: %s\n", c.Snapshot)
+		}
+	}
+	if r.KnowledgeDelta != "" {
+		fmt.Fprintf(w, "\nKnowledge: %s\n", r.KnowledgeDelta)
+	}
+}
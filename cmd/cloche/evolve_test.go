@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/cloche-dev/cloche/internal/evolution"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type mockEvolveClient struct {
+	pb.ClocheServiceClient
+	req  *pb.EvolveRequest
+	resp *pb.EvolveResponse
+	err  error
+}
+
+func (m *mockEvolveClient) Evolve(_ context.Context, req *pb.EvolveRequest, _ ...grpc.CallOption) (*pb.EvolveResponse, error) {
+	m.req = req
+	return m.resp, m.err
+}
+
+func TestEvolveRun_PrintsSummary(t *testing.T) {
+	mock := &mockEvolveClient{resp: &pb.EvolveResponse{
+		EvolutionId:    "evo-1",
+		Classification: "bug",
+		RunsCollected:  4,
+		Changes:        2,
+		KnowledgeDelta: "2 lessons applied",
+		ChangeDetails: []string{
+			"add_script scripts/lint.sh",
+			"add_step .cloche/develop.cloche: added step lint; wired lint:fail -> abort",
+		},
+	}}
+	req := &pb.EvolveRequest{ProjectDir: "/p", WorkflowName: "develop", SinceRunId: "a1-develop"}
+
+	var stdout, stderr bytes.Buffer
+	code := evolveRun(context.Background(), mock, req, &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	assert.Same(t, req, mock.req)
+	out := stdout.String()
+	assert.Contains(t, out, "Evolution:      evo-1\n")
+	assert.Contains(t, out, "Runs collected: 4\n")
+	assert.Contains(t, out, "Changes:        2\n  add_script scripts/lint.sh\n  add_step .cloche/develop.cloche: added step lint; wired lint:fail -> abort\n")
+	assert.Empty(t, stderr.String())
+}
+
+func TestEvolveRun_Error(t *testing.T) {
+	mock := &mockEvolveClient{err: errors.New("evolution is not enabled on this daemon")}
+
+	var stdout, stderr bytes.Buffer
+	code := evolveRun(context.Background(), mock, &pb.EvolveRequest{WorkflowName: "develop"}, &stdout, &stderr)
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), "evolution is not enabled")
+}
+
+func TestWriteEvolutionExplanation(t *testing.T) {
+	result := &evolution.EvolutionResult{
+		ID:                       "evo-1",
+		WorkflowName:             "develop",
+		TriggerRunID:             "a1-develop",
+		Timestamp:                "2026-03-01T12:00:00Z",
+		Classification:           "bug",
+		ClassificationRationale:  "the implement step keeps skipping tests",
+		ClassificationConfidence: "high",
+		RunsCollected:            3,
+		Lessons: []evolution.Lesson{{
+			ID:              "lesson-1",
+			Category:        "prompt_improvement",
+			Insight:         "Tests are skipped before committing",
+			SuggestedAction: "Require running go test before finishing",
+			Evidence:        []string{"a1-develop: test step failed", "b2-develop: test step failed"},
+			Confidence:      "medium",
+		}},
+		Changes: []evolution.Change{{
+			Type:     "prompt_update",
+			File:     ".cloche/prompts/implement.md",
+			Reason:   "Tests are skipped before committing",
+			Snapshot: "20260301T120000-implement.md",
+		}},
+		KnowledgeDelta: "1 lessons applied",
+	}
+
+	var out bytes.Buffer
+	writeEvolutionExplanation(&out, result)
+
+	assert.Equal(t, `Evolution:      evo-1
+Workflow:       develop
+Time:           2026-03-01T12:00:00Z
+Trigger run:    a1-develop
+Runs collected: 3
+
+Classification: bug (confidence: high)
+  the implement step keeps skipping tests
+
+Lessons (1):
+  1. [prompt_improvement] Tests are skipped before committing
+     Action:     Require running go test before finishing
+     Confidence: medium
+     Evidence:   a1-develop: test step failed
+     Evidence:   b2-develop: test step failed
+
+Changes (1):
+  prompt_update .cloche/prompts/implement.md
+    Reason:   Tests are skipped before committing
+    Snapshot: 20260301T120000-implement.md
+
+Knowledge: 1 lessons applied
+`, out.String())
+}
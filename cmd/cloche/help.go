@@ -119,10 +119,13 @@ automatically and a task ID is printed alongside the run ID.
 
 Usage:
   cloche run <workflow>[:<step>] [--prompt "..."] [--title "..."] [--issue ID] [--keep-container]
+             [--param key=value]... [--wait [--timeout DURATION]]
 
 Arguments:
   <workflow>           Name of the workflow to run. Must match a
-                       .cloche/<workflow>.cloche file in the project.
+                       .cloche/<workflow>.cloche file in the project. Run
+                       from a subdirectory, the project root is the nearest
+                       directory above with .cloche/ or the git repo root.
   <workflow>:<step>    Run starting at a specific step within the workflow.
                        Execution begins at <step> instead of the entry step.
 
@@ -134,6 +137,12 @@ Flags:
                        Without this flag, a User-Initiated task is created.
   --keep-container     Do not remove the container after the run completes.
                        Useful for debugging.
+  --param key=value    Override a param declared in the workflow's params
+                       block. Repeatable.
+  --wait               Block until the run finishes and print its final state.
+                       Exits non-zero unless the run succeeded.
+  --timeout DURATION   With --wait, give up waiting after DURATION (e.g. 45m).
+                       The run itself keeps going in the daemon.
 
 The command prints the workflow ID, task ID, and attempt ID on success.
 Use the task ID with "cloche status", "cloche logs", and "cloche list".
@@ -143,6 +152,8 @@ Examples:
   cloche run develop -p "Fix the broken CSV parser" --title "CSV fix"
   cloche run develop:review -p "Check the implementation"
   cloche run build --keep-container
+  cloche run main --param target=./internal/...
+  cloche run develop -p "Fix auth bug" --wait --timeout 1h
   cloche run develop -p "Fix auth bug" -i TASK-123
 `,
 
@@ -237,7 +248,8 @@ Streams or displays log output. The first argument accepts any level of
 the ID hierarchy.
 
 Usage:
-  cloche logs <id> [--type <full|script|llm>] [-f] [-l <n>]
+  cloche logs <id> [--type <full|script|llm>] [-f] [-l <n>] [--json]
+              [--timestamps <relative|absolute|off>]
 
 Arguments:
   <id>    Any of the following:
@@ -253,8 +265,16 @@ Flags:
                                    script  — script/command output only
                                    llm     — LLM interaction logs only
   --follow, -f                   Stream logs in real time (blocks until the
-                                 run completes or is stopped).
+                                 run completes or is stopped). Reconnects
+                                 and resumes if the stream drops.
   --limit, -l <n>                Display only the last n lines of output.
+  --json                         Emit one JSON object per log entry (NDJSON)
+                                 with type, step, result, timestamp, and
+                                 message fields.
+  --timestamps <mode>            Prefix each entry with its time: relative
+                                 (elapsed since the run started, +HH:MM:SS),
+                                 absolute (local wall-clock time), or off
+                                 (default). Ignored with --json.
 
 Flags are combinable: cloche logs a3f7:develop:implement -l 20 -f
 
@@ -267,12 +287,14 @@ Examples:
   cloche logs TASK-123:a3f7:implement
   cloche logs a3f7 --type script
   cloche logs a3f7:develop -f -l 50
+  cloche logs a3f7 --json | jq -r .message
+  cloche logs a3f7:develop -f --timestamps=relative
 `,
 
 	"poll": `cloche poll — Wait for runs or steps to finish
 
-Polls the daemon every 2 seconds until all targets reach a terminal state
-(succeeded, failed, or cancelled).
+Polls the daemon every 2 seconds (see --poll-interval) until all targets
+reach a terminal state (succeeded, failed, or cancelled).
 
 Accepts any level of the ID hierarchy:
   task ID        shandalar-1234          — most recent run for the task
@@ -287,17 +309,23 @@ With a single ID, prints step-level progress.
 With multiple IDs, displays a compact status summary.
 
 Usage:
-  cloche poll <id> [id...] [--no-color]
+  cloche poll <id> [id...] [--poll-interval <dur>] [--poll-timeout <dur>] [--no-color]
 
 Arguments:
   <id>    One or more IDs at any level of the hierarchy.
 
 Flags:
-  --no-color   Disable ANSI color output (also respects NO_COLOR env var).
+  --no-color              Disable ANSI color output (also respects NO_COLOR
+                          env var).
+  --poll-interval <dur>   Time between status checks (default: 2s, or
+                          CLOCHE_POLL_INTERVAL).
+  --poll-timeout <dur>    Give up and exit 1 after this long (default: wait
+                          forever, or CLOCHE_POLL_TIMEOUT).
 
 Exit codes:
   0    All runs (or steps) succeeded.
-  1    Any run failed, was cancelled, or the container died.
+  1    Any run failed, was cancelled, the container died, or the poll
+       timeout passed.
 
 Examples:
   cloche poll a133
@@ -322,9 +350,15 @@ Flags:
   --state, -s STATE  Filter by task status (pending, running, succeeded, failed, cancelled).
   --limit, -n NUM    Limit the number of results returned.
   --runs             Show flat run listing instead of task-oriented view.
+  --sort KEY         With --runs, order by started_at (default: running runs
+                     first, then newest start), completed_at (unfinished runs
+                     first, then most recently finished) or updated_at (most
+                     recently changed).
 
 Output columns (default): task ID, status, attempt count, latest attempt ID, title.
-Output columns (--runs):   workflow ID, workflow, state, type, task ID, title, error.
+Output columns (--runs):   workflow ID, workflow, state, type, task ID, steps,
+                           duration, title, error. Steps and duration are
+                           shown once a run finishes.
 
 Examples:
   cloche list
@@ -334,6 +368,7 @@ Examples:
   cloche list --all --state failed --limit 5
   cloche list -p /home/user/project -s succeeded -n 20
   cloche list --runs
+  cloche list --runs --sort completed_at
 `,
 
 	"stop": `cloche stop — Stop all active runs for a task
@@ -406,6 +441,142 @@ Examples:
   cloche extract TASK-42
 `,
 
+	"prune": `cloche prune — Remove old runs, orphaned snapshots, and leftover containers
+
+Housekeeping for the daemon's run history and disk usage. Each kind of
+cleanup is opt-in; at least one flag selecting what to prune is required.
+Active runs are never touched.
+
+Usage:
+  cloche prune [--runs-older-than <age>] [--snapshots] [--containers]
+               [--project <dir> | --all]
+
+Flags:
+  --runs-older-than <age>   Delete finished runs (succeeded, failed, cancelled)
+                            that completed longer ago than <age>, together with
+                            their step records and any container still present.
+                            Accepts Go durations (12h) or days (30d).
+  --snapshots               Remove workspace snapshots whose attempt no longer
+                            has any run.
+  --containers              Remove containers left behind by finished runs.
+                            Containers retained with --keep-container are kept.
+  -p, --project <dir>       Project to prune (default: current directory).
+  --all                     Prune every project known to the daemon.
+
+Output:
+  One section per selected cleanup with the count and each removed item.
+  Items that could not be removed are reported on stderr.
+
+Exit codes:
+  0    Pruning succeeded.
+  1    The daemon rejected the request or some items could not be removed.
+
+Examples:
+  cloche prune --runs-older-than 30d
+  cloche prune --runs-older-than 30d --snapshots --containers
+  cloche prune --containers --all
+`,
+
+	"rm": `cloche rm — Delete finished runs matching a filter
+
+Bulk cleanup of run history. Deletes finished runs (succeeded, failed,
+cancelled) that match every given filter, together with their step records,
+any container still present, and their files under .cloche/. An attempt's
+log directory is removed once none of its runs remain. Active runs are never
+touched. At least one filter is required.
+
+The matching runs are listed first and you are asked to confirm. Without a
+terminal to answer the prompt, pass --yes.
+
+Usage:
+  cloche rm [--failed] [--older-than <age>] [--workflow <name>]
+            [--dry-run] [--yes] [--project <dir> | --all]
+
+Flags:
+  --failed                  Only failed runs.
+  --older-than <age>        Only runs that completed longer ago than <age>.
+                            Accepts Go durations (12h) or days (30d).
+  -w, --workflow <name>     Only runs of this workflow.
+  -n, --dry-run             List the matching runs without deleting them.
+  -y, --yes                 Delete without asking for confirmation.
+  -p, --project <dir>       Project to clean up (default: the project
+                            containing the current directory).
+  --all                     Match runs of every project known to the daemon.
+
+Exit codes:
+  0    Runs deleted, nothing matched, dry run, or deletion declined.
+  1    The daemon rejected the request, confirmation was unavailable, or
+       some items could not be removed.
+
+Examples:
+  cloche rm --failed --dry-run
+  cloche rm --failed --older-than 7d
+  cloche rm --workflow develop --older-than 30d --yes
+`,
+
+	"steps": `cloche steps — Show a run's steps with results and durations
+
+Prints one row per step execution in the order the steps ran: the step name,
+which attempt of that step it was within the run (steps revisited by a retry
+loop count up), the result, when it started, how long it took, and the exit
+code of the step's process ("-" when none was recorded). Steps that are still
+running show their elapsed time.
+
+Usage:
+  cloche steps <run-id>
+
+Arguments:
+  <run-id>    A run ID, task ID, or attempt ID (resolved like "cloche status").
+
+Exit codes:
+  0    The table was printed.
+  1    The run could not be found.
+
+Examples:
+  cloche steps a1b2-develop
+  cloche steps TASK-123
+`,
+
+	"evolve": `cloche evolve — Run an evolution pass for a workflow now
+
+Runs the evolution pipeline (classify, collect, reflect, apply lessons) for
+one workflow immediately instead of waiting for the debounce after a run
+completes. The daemon must have evolution enabled and an LLM command set.
+
+By default the pass collects the runs started after the previous evolution's
+trigger run, exactly like an automatic pass. --since reconsiders a chosen
+range instead. The pass records the newest collected run as its trigger, so
+later automatic passes continue from there.
+
+--explain prints why a past evolution made its changes: the classification
+and its rationale, the lessons drawn from the collected runs (insight,
+suggested action, confidence, evidence), and the changes applied. It reads
+.cloche/evolution/log.jsonl, falling back to the daemon's database for
+entries no longer in the log, and does not contact the daemon.
+
+Usage:
+  cloche evolve <workflow> [--since <run-id>] [--project <dir>]
+  cloche evolve --explain <evolution-id> [--project <dir>]
+
+Flags:
+  --since <run-id>      Collect runs of the workflow started after this run.
+  --explain <id>        Print the reasoning trail of an earlier evolution.
+  -p, --project <dir>   Project directory (default: current directory).
+
+Output:
+  The evolution ID, the run classification, how many runs were collected,
+  and how many changes were applied.
+
+Exit codes:
+  0    The pass completed (possibly with no changes).
+  1    The daemon rejected the request or the pass failed.
+
+Examples:
+  cloche evolve develop
+  cloche evolve develop --since a1b2-develop
+  cloche evolve --explain evo-1712345678901234567
+`,
+
 	"tasks": `cloche tasks — Show task pipeline and assignment state
 
 Queries the daemon's HTTP API for the current task list, showing which
@@ -435,7 +606,8 @@ picks up and runs tasks from the task pipeline.
 
 Usage:
   cloche loop [--max <n>]     Start the orchestration loop
-  cloche loop once            Run one task then stop the loop
+  cloche loop once [--poll-interval <dur>] [--poll-timeout <dur>]
+                              Run one task then stop the loop
   cloche loop stop [--hard]   Stop the orchestration loop
 
 Flags:
@@ -446,7 +618,9 @@ Flags:
 
 The "once" subcommand starts the loop, waits for a single task to be
 picked up and completed, then automatically stops the loop. Exits 0
-on success, 1 on failure or cancellation.
+on success, 1 on failure or cancellation. --poll-interval and
+--poll-timeout (or CLOCHE_POLL_INTERVAL and CLOCHE_POLL_TIMEOUT) control
+how often it checks and when it gives up, as for "cloche poll".
 
 Plain "cloche loop stop" only halts new dispatch — runs already in flight
 remain resumable and will fire again when the daemon restarts (e.g. for a
@@ -529,6 +703,9 @@ Arguments:
 
 Flags:
   --project <dir>, -p <dir>    Project directory (default: current directory).
+  --format <text|json>         Graph output format (default: text). json
+                               prints name, location, entry_step, steps,
+                               wires, and collects.
 
 When listing, workflows are grouped by type (container or host). When
 showing a specific workflow, the output is a graph with step boxes and
@@ -540,6 +717,7 @@ Examples:
   cloche workflow
   cloche workflow develop
   cloche workflow main -p /path/to/project
+  cloche workflow develop --format json
   cloche workflow --project ../other-project
 `,
 
@@ -566,7 +744,8 @@ Output includes:
   Workflows         Known container and host workflow names
 
 Environment:
-  CLOCHE_ADDR    Daemon gRPC address (default: 127.0.0.1:50051)
+  CLOCHE_ADDR    Daemon gRPC address (default: the address cloched wrote to
+                 ~/.config/cloche/daemon.addr, else 127.0.0.1:50051)
 
 Examples:
   cloche project
@@ -574,6 +753,37 @@ Examples:
   cloche project --name my-app
 `,
 
+	"agent": `cloche agent run — Run one agent step locally for prompt debugging
+
+Runs a single agent step from a workflow file against the current directory,
+without the daemon, a container, or the rest of the workflow. The prompt is
+assembled exactly as in a real run (template, context files, user request,
+result instructions), the agent is invoked once, and its output and result
+are printed. Useful when iterating on a prompt.
+
+Usage:
+  cloche agent run <workflow-file> <step> [--prompt <text>]
+                   [--workflow <name>] [--agent-command <cmd>]
+
+Arguments:
+  <workflow-file>   Path to a .cloche file.
+  <step>            Name of an agent step in the workflow.
+
+Flags:
+  --prompt, -p <text>       User request, as passed to "cloche run --prompt".
+  --workflow, -w <name>     Workflow to use when the file declares several.
+  --agent-command <cmd>     Agent command(s), comma-separated; overrides the
+                            step, workflow, and CLOCHE_AGENT_COMMAND.
+
+The agent runs in the current directory and may modify it. KV-store
+{{ $var }} lookups other than built-ins are not available, and the step's
+output is also appended to .cloche/output/<step>.log as in a real run.
+
+Examples:
+  cloche agent run .cloche/develop.cloche implement -p "Add a --verbose flag"
+  cloche agent run .cloche/develop.cloche review --agent-command codex
+`,
+
 	"validate": `cloche validate — Validate project configuration and workflows
 
 Parses and validates all config and workflow files in the project's .cloche/
@@ -581,11 +791,15 @@ directory. Checks syntax, result wiring, terminal coverage, orphan steps,
 file references, and cross-file consistency.
 
 Usage:
-  cloche validate [--project <path>] [--workflow <name>]
+  cloche validate [--project <path>] [--workflow <name>] [--json] [--strict]
 
 Flags:
   --project <path>    Project directory to validate (default: current directory).
   --workflow <name>   Validate only the named workflow instead of all workflows.
+  --json              Print a JSON report: {"valid", "errors", "warnings",
+                      "diagnostics": [{"severity", "file", "line", "col",
+                      "message"}]}. line/col are omitted when unknown.
+  --strict            Treat warnings as errors for the exit code.
 
 Checks performed:
   config.toml         Parses correctly, fields are valid.
@@ -596,14 +810,19 @@ Checks performed:
                       script run paths resolve to .cloche/scripts/.
   Cross-file          workflow_name references resolve to defined workflows.
 
+Unrecognized step config keys and agent retry loops with no way out are
+reported as warnings; everything else is an error. Earlier versions treated
+them as errors; pass --strict to keep failing on them.
+
 Exit codes:
-  0    All configuration valid.
-  1    One or more errors found.
+  0    All configuration valid (warnings allowed unless --strict).
+  1    One or more errors found, or warnings with --strict.
 
 Examples:
   cloche validate
   cloche validate --project /path/to/project
   cloche validate --workflow develop
+  cloche validate --json --strict
 `,
 
 	"console": `cloche console — Start an interactive agent session in a container
@@ -771,22 +990,27 @@ Project Setup:
 Workflow Info:
   workflow   List workflows or show a workflow as an ASCII-art graph
   validate   Validate project configuration and workflow definitions
+  agent      Run one agent step locally to debug its prompt
 
 Workflow Runs:
   run        Launch a workflow run in a container
   resume     Resume a failed workflow run from a specific step
   status     Show daemon overview or check a specific run's status
+  steps      Show a run's steps with results and durations
   logs       Show or stream logs for a run
   poll       Wait for one or more runs to finish (blocks until terminal)
   list       List runs for current project (or all projects)
   stop       Stop a running workflow
   delete     Delete a retained container
   extract    Extract container results to a local directory or git worktree
+  prune      Remove old runs, orphaned snapshots, and leftover containers
+  rm         Delete finished runs matching a filter (e.g. all failed runs)
   console    Start an interactive agent session in a container
 
 Orchestration:
   tasks      Show task pipeline and assignment state
   loop       Start or stop the orchestration loop
+  evolve     Run an evolution pass for a workflow now
   activity   Show project activity log (attempt/step timestamps and outcomes)
 
 Context Store (for use inside workflow steps):
@@ -798,7 +1022,8 @@ Daemon:
   version    Print CLI, daemon, and agent version information
 
 Environment Variables:
-  CLOCHE_ADDR          Daemon gRPC address (default: 127.0.0.1:50051)
+  CLOCHE_ADDR          Daemon gRPC address (default: the address in
+                       ~/.config/cloche/daemon.addr, else 127.0.0.1:50051)
   CLOCHE_HTTP          Daemon HTTP address (for health/tasks commands)
   CLOCHE_RUN_ID        Workflow ID for the current run (set automatically in steps)
   CLOCHE_PROJECT_DIR   Project directory override for get/set commands
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+// sliceLogStream replays a fixed list of entries, then returns io.EOF.
+type sliceLogStream struct {
+	entries []*pb.LogEntry
+}
+
+func (s *sliceLogStream) Recv() (*pb.LogEntry, error) {
+	if len(s.entries) == 0 {
+		return nil, io.EOF
+	}
+	e := s.entries[0]
+	s.entries = s.entries[1:]
+	return e, nil
+}
+
+func TestWriteLogs_JSONEmitsOneObjectPerLine(t *testing.T) {
+	stream := &sliceLogStream{entries: []*pb.LogEntry{
+		{Type: "step_started", StepName: "implement", Timestamp: "2026-03-01T10:00:00Z"},
+		{Type: "log", StepName: "implement", Message: "line with \"quotes\"\nand newline"},
+		{Type: "step_completed", StepName: "implement", Result: "success", Timestamp: "2026-03-01T10:05:00Z"},
+		{Type: "run_completed", Result: "succeeded"},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeLogs(&buf, stream, true, nil))
+
+	var lines []string
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	require.Len(t, lines, 4)
+
+	for _, line := range lines {
+		var obj map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &obj), "line is not valid JSON: %s", line)
+		for _, key := range []string{"type", "step", "result", "timestamp", "message"} {
+			assert.Contains(t, obj, key)
+		}
+	}
+
+	var completed logRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &completed))
+	assert.Equal(t, logRecord{
+		Type:      "step_completed",
+		Step:      "implement",
+		Result:    "success",
+		Timestamp: "2026-03-01T10:05:00Z",
+	}, completed)
+
+	var logLine logRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &logLine))
+	assert.Equal(t, "line with \"quotes\"\nand newline", logLine.Message)
+}
+
+func TestWriteLogs_HumanFormatIsDefault(t *testing.T) {
+	stream := &sliceLogStream{entries: []*pb.LogEntry{
+		{Type: "step_started", StepName: "implement"},
+		{Type: "step_completed", StepName: "implement", Result: "success"},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeLogs(&buf, stream, false, nil))
+
+	out := buf.String()
+	assert.True(t, strings.Contains(out, "--- implement started ---"))
+	assert.True(t, strings.Contains(out, "--- implement: success ---"))
+	assert.False(t, strings.HasPrefix(strings.TrimSpace(out), "{"))
+}
+
+func TestWriteLogs_RelativeTimestamps(t *testing.T) {
+	runStart := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	stream := &sliceLogStream{entries: []*pb.LogEntry{
+		// Capture-based entries use time.Time.String(); live lines use RFC3339.
+		{Type: "step_started", StepName: "implement", Timestamp: runStart.Add(2 * time.Second).String()},
+		{Type: "log", StepName: "implement", Message: "compiling", Timestamp: "2026-03-01T10:01:05Z"},
+		{Type: "log", StepName: "implement", Message: "no timestamp"},
+		{Type: "step_completed", StepName: "implement", Result: "success", Timestamp: "2026-03-01T11:02:03.5Z"},
+	}}
+
+	stamp, err := newLogTimestamper("relative", runStart)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, writeLogs(&buf, stream, false, stamp))
+
+	assert.Equal(t, strings.Join([]string{
+		"[+00:00:02] --- implement started ---",
+		"[+00:01:05] compiling",
+		"no timestamp",
+		"[+01:02:03] --- implement: success ---",
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestNewLogTimestamper_Modes(t *testing.T) {
+	stamp, err := newLogTimestamper("off", time.Time{})
+	require.NoError(t, err)
+	assert.Nil(t, stamp, "off prints entries unstamped")
+
+	stamp, err = newLogTimestamper("relative", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "", stamp("2026-03-01T10:00:00Z"), "relative without a run start stamps nothing")
+
+	_, err = newLogTimestamper("elapsed", time.Time{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--timestamps must be relative, absolute, or off")
+}
+
+// failingLogStream replays entries, then fails with err instead of io.EOF.
+type failingLogStream struct {
+	sliceLogStream
+	err error
+}
+
+func (s *failingLogStream) Recv() (*pb.LogEntry, error) {
+	if len(s.entries) == 0 {
+		return nil, s.err
+	}
+	return s.sliceLogStream.Recv()
+}
+
+func TestWriteLogsResuming_ReconnectsAfterTransientError(t *testing.T) {
+	first := &failingLogStream{
+		sliceLogStream: sliceLogStream{entries: []*pb.LogEntry{
+			{Type: "log", Message: "one", Timestamp: "2026-03-01T10:00:00Z"},
+			{Type: "log", Message: "two", Timestamp: "2026-03-01T10:00:01Z"},
+		}},
+		err: grpcStatus.Error(codes.Unavailable, "connection reset"),
+	}
+	// The daemon replays the run's log from the start on the new stream,
+	// including a line logged in the same second as the last one printed.
+	second := &sliceLogStream{entries: []*pb.LogEntry{
+		{Type: "log", Message: "one", Timestamp: "2026-03-01T10:00:00Z"},
+		{Type: "log", Message: "two", Timestamp: "2026-03-01T10:00:01Z"},
+		{Type: "log", Message: "three", Timestamp: "2026-03-01T10:00:01Z"},
+		{Type: "log", Message: "four", Timestamp: "2026-03-01T10:00:02Z"},
+		{Type: "run_completed", Result: "succeeded", Timestamp: "2026-03-01 10:00:03 +0000 UTC"},
+	}}
+	reopens := 0
+	reopen := func() (logEntryReceiver, error) {
+		reopens++
+		if reopens == 1 {
+			return nil, grpcStatus.Error(codes.Unavailable, "daemon restarting")
+		}
+		return second, nil
+	}
+	clock := &fakeClock{}
+
+	var out, errOut bytes.Buffer
+	require.NoError(t, writeLogsResuming(&out, &errOut, first, reopen, false, nil, clock))
+
+	assert.Equal(t, "one\ntwo\nthree\nfour\n\nRun result: succeeded\n", out.String())
+	assert.Equal(t, 2, reopens)
+	assert.Equal(t, []time.Duration{logReconnectMin, 2 * logReconnectMin}, clock.sleeps)
+	assert.Contains(t, errOut.String(), "log stream interrupted (connection reset); reconnecting in 500ms")
+}
+
+func TestWriteLogsResuming_TrimsReplayedFullLog(t *testing.T) {
+	first := &failingLogStream{
+		sliceLogStream: sliceLogStream{entries: []*pb.LogEntry{
+			{Type: "log", Message: "[status] step implement started", Timestamp: "2026-03-01T10:00:00Z"},
+			{Type: "log", Message: "[llm] reading", Timestamp: "2026-03-01T10:00:05Z"},
+		}},
+		err: grpcStatus.Error(codes.Unavailable, "eof"),
+	}
+	// The run finished while disconnected, so the daemon serves full.log.
+	second := &sliceLogStream{entries: []*pb.LogEntry{
+		{Type: "full_log", Message: "[2026-03-01T09:59:59Z] [status] run started\n" +
+			"[2026-03-01T10:00:00Z] [status] step implement started\n" +
+			"[2026-03-01T10:00:05Z] [llm] reading\n" +
+			"[2026-03-01T10:00:05Z] [llm] writing\n" +
+			"  continued\n" +
+			"[2026-03-01T10:00:06Z] [llm] done\n"},
+	}}
+
+	var out bytes.Buffer
+	err := writeLogsResuming(&out, io.Discard, first, func() (logEntryReceiver, error) { return second, nil }, false, nil, &fakeClock{})
+	require.NoError(t, err)
+	assert.Equal(t, "[status] step implement started\n"+
+		"[llm] reading\n"+
+		"[2026-03-01T10:00:05Z] [llm] writing\n"+
+		"  continued\n"+
+		"[2026-03-01T10:00:06Z] [llm] done\n", out.String(),
+		"lines already printed are dropped, including ones in the cursor's second")
+}
+
+func TestWriteLogsResuming_StopsOnPermanentError(t *testing.T) {
+	stream := &failingLogStream{err: grpcStatus.Error(codes.NotFound, "run not found")}
+	reopen := func() (logEntryReceiver, error) {
+		t.Fatal("must not reconnect after a permanent error")
+		return nil, nil
+	}
+
+	err := writeLogsResuming(io.Discard, io.Discard, stream, reopen, false, nil, &fakeClock{})
+	assert.Equal(t, codes.NotFound, grpcStatus.Code(err))
+
+	stream = &failingLogStream{err: grpcStatus.Error(codes.Internal, "corrupt log")}
+	err = writeLogsResuming(io.Discard, io.Discard, stream, reopen, false, nil, &fakeClock{})
+	assert.Equal(t, codes.Internal, grpcStatus.Code(err), "an Internal error is a daemon bug, not a dropped connection")
+}
@@ -133,6 +133,13 @@ func main() {
 		}
 		cmdDoctor(os.Args[2:])
 		return
+	case "agent":
+		if hasHelpFlag(os.Args[2:]) {
+			printSubcommandHelp("agent")
+			return
+		}
+		cmdAgent(os.Args[2:])
+		return
 	case "debug":
 		cmdDebug(os.Args[2:])
 		return
@@ -140,13 +147,20 @@ func main() {
 		// No help flag handling: complete must be fast and quiet.
 		cmdComplete(os.Args[2:])
 		return
+	case "evolve":
+		// --explain reads the project's evolution records; no daemon needed.
+		if hasExplainFlag(os.Args[2:]) && !hasHelpFlag(os.Args[2:]) {
+			cmdEvolveExplain(os.Args[2:])
+			return
+		}
 	}
 
 	// Handle --help for daemon commands before connecting
 	daemonCmds := map[string]bool{
 		"run": true, "resume": true, "status": true, "logs": true, "poll": true,
 		"list": true, "stop": true, "delete": true, "loop": true, "shutdown": true,
-		"console": true, "extract": true,
+		"console": true, "extract": true, "prune": true, "evolve": true,
+		"steps": true, "rm": true,
 	}
 	if daemonCmds[os.Args[1]] && hasHelpFlag(os.Args[2:]) {
 		printSubcommandHelp(os.Args[1])
@@ -154,10 +168,7 @@ func main() {
 	}
 
 	// Commands that need a daemon connection
-	addr := os.Getenv("CLOCHE_ADDR")
-	if addr == "" {
-		addr = config.DefaultAddr()
-	}
+	addr := config.ClientAddr()
 
 	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -177,6 +188,8 @@ func main() {
 		cmdResume(ctx, client, os.Args[2:])
 	case "status":
 		cmdStatus(ctx, client, os.Args[2:])
+	case "steps":
+		cmdSteps(ctx, client, os.Args[2:])
 	case "logs":
 		cmdLogs(client, os.Args[2:])
 	case "poll":
@@ -195,6 +208,12 @@ func main() {
 		cmdConsole(client, os.Args[2:])
 	case "extract":
 		cmdExtract(ctx, client, os.Args[2:])
+	case "prune":
+		cmdPrune(ctx, client, os.Args[2:])
+	case "rm":
+		cmdRm(ctx, client, os.Args[2:])
+	case "evolve":
+		cmdEvolve(client, os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
 		printTopLevelHelp()
@@ -204,7 +223,9 @@ func main() {
 
 func cmdRun(ctx context.Context, client pb.ClocheServiceClient, args []string) {
 	var workflowSpec, prompt, title, issueID string
-	var keepContainer bool
+	var keepContainer, wait bool
+	var waitTimeout time.Duration
+	var params map[string]string
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -225,6 +246,31 @@ func cmdRun(ctx context.Context, client pb.ClocheServiceClient, args []string) {
 			}
 		case "--keep-container":
 			keepContainer = true
+		case "--wait":
+			wait = true
+		case "--timeout":
+			if i+1 < len(args) {
+				i++
+				d, err := time.ParseDuration(args[i])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error: invalid --timeout %q: %v\n", args[i], err)
+					os.Exit(1)
+				}
+				waitTimeout = d
+			}
+		case "--param":
+			if i+1 < len(args) {
+				i++
+				key, value, ok := strings.Cut(args[i], "=")
+				if !ok || key == "" {
+					fmt.Fprintf(os.Stderr, "error: invalid --param %q: expected key=value\n", args[i])
+					os.Exit(1)
+				}
+				if params == nil {
+					params = make(map[string]string)
+				}
+				params[key] = value
+			}
 		default:
 			if workflowSpec == "" && !strings.HasPrefix(args[i], "-") {
 				workflowSpec = args[i]
@@ -233,31 +279,30 @@ func cmdRun(ctx context.Context, client pb.ClocheServiceClient, args []string) {
 	}
 
 	if workflowSpec == "" {
-		fmt.Fprintf(os.Stderr, "usage: cloche run <workflow>[:<step>] [--prompt \"...\"] [--title \"...\"] [--issue ID]\n")
+		fmt.Fprintf(os.Stderr, "usage: cloche run <workflow>[:<step>] [--prompt \"...\"] [--title \"...\"] [--issue ID] [--param key=value] [--wait [--timeout DURATION]]\n")
 		os.Exit(1)
 	}
 
-	// workflowSpec is "workflow" or "workflow:step"; extract the workflow name for image lookup.
-	workflowName, _, _ := strings.Cut(workflowSpec, ":")
-
 	cwd, _ := os.Getwd()
-
-	// Resolve image from workflow file (soft failure — fall back to daemon default).
-	// Try loading from any .cloche file; only extract image for container workflows.
-	var image string
-	if wf, err := loadWorkflow(cwd, workflowName); err == nil && wf.Location == domain.LocationContainer {
-		image = wf.Config["container.image"]
+	req := runRequest(cwd, workflowSpec)
+	req.Prompt = prompt
+	req.KeepContainer = keepContainer
+	req.Title = title
+	req.IssueId = issueID
+	req.Params = params
+
+	if wait {
+		// The default 30s command timeout is far too short for a whole run;
+		// wait indefinitely unless --timeout bounds it.
+		waitCtx, cancel := context.WithCancel(context.Background())
+		if waitTimeout > 0 {
+			waitCtx, cancel = context.WithTimeout(context.Background(), waitTimeout)
+		}
+		defer cancel()
+		os.Exit(runAndWait(waitCtx, client, req, os.Stdout, os.Stderr))
 	}
 
-	resp, err := client.RunWorkflow(ctx, &pb.RunWorkflowRequest{
-		WorkflowName:  workflowSpec,
-		ProjectDir:    cwd,
-		Image:         image,
-		Prompt:        prompt,
-		KeepContainer: keepContainer,
-		Title:         title,
-		IssueId:       issueID,
-	})
+	resp, err := client.RunWorkflow(ctx, req)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
@@ -271,6 +316,71 @@ func cmdRun(ctx context.Context, client pb.ClocheServiceClient, args []string) {
 	}
 }
 
+// runRequest builds the request for running workflowSpec ("workflow" or
+// "workflow:step") from dir. The run targets the project root containing dir,
+// so a run started from a subdirectory finds the project's workflows and
+// copies the whole tree.
+func runRequest(dir, workflowSpec string) *pb.RunWorkflowRequest {
+	projectDir := findProjectRoot(dir)
+	workflowName, _, _ := strings.Cut(workflowSpec, ":")
+
+	// Resolve image from workflow file (soft failure — fall back to daemon default).
+	// Try loading from any .cloche file; only extract image for container workflows.
+	var image string
+	if wf, err := loadWorkflow(projectDir, workflowName); err == nil && wf.Location == domain.LocationContainer {
+		image = wf.Config["container.image"]
+	}
+
+	return &pb.RunWorkflowRequest{
+		WorkflowName: workflowSpec,
+		ProjectDir:   projectDir,
+		Image:        image,
+	}
+}
+
+// findProjectRoot returns the nearest directory at or above dir that holds a
+// .cloche directory or is a git repository root. When neither is found, dir
+// itself is the project root.
+func findProjectRoot(dir string) string {
+	for d := dir; ; {
+		if info, err := os.Stat(filepath.Join(d, ".cloche")); err == nil && info.IsDir() {
+			return d
+		}
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// runAndWait starts a run via RunWorkflowSync and reports its final state.
+// Returns 0 when the run succeeded, 1 otherwise.
+func runAndWait(ctx context.Context, client pb.ClocheServiceClient, req *pb.RunWorkflowRequest, stdout, stderr io.Writer) int {
+	resp, err := client.RunWorkflowSync(ctx, req)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Run %s %s\n", resp.RunId, resp.State)
+	if resp.TaskId != "" {
+		fmt.Fprintf(stdout, "Task:        %s\n", resp.TaskId)
+	}
+	if resp.AttemptId != "" {
+		fmt.Fprintf(stdout, "Attempt:     %s\n", resp.AttemptId)
+	}
+	if resp.State != string(domain.RunStateSucceeded) {
+		if resp.ErrorMessage != "" {
+			fmt.Fprintf(stderr, "Error: %s\n", resp.ErrorMessage)
+		}
+		return 1
+	}
+	return 0
+}
+
 // parseResumeArg parses a resume argument which can be:
 //   - a task ID ("cloche-k4gh") — no colons, resolved server-side
 //   - a run ID ("pqpm-main") — no colons, resolved server-side
@@ -439,6 +549,14 @@ func cmdStatusTaskLatest(ctx context.Context, client pb.ClocheServiceClient, tas
 		}
 	}
 
+	// A pending attempt may be held behind its workflow's max_concurrent
+	// limit; surface where it sits in that queue.
+	if resp.Status == "pending" && latest.AttemptId != "" {
+		if statusResp, err := client.GetStatus(ctx, &pb.GetStatusRequest{Id: latest.AttemptId}); err == nil && statusResp.QueuePosition > 0 {
+			fmt.Printf("Queued:  position %d\n", statusResp.QueuePosition)
+		}
+	}
+
 	// Show token usage across all attempts for this task.
 	printTaskTokenUsage(ctx, client, taskID, resp.ProjectDir)
 }
@@ -786,6 +904,7 @@ func cmdList(ctx context.Context, client pb.ClocheServiceClient, args []string)
 	var projectDir, stateFilter string
 	var limit int32
 	var runs bool // --runs flag to show flat run listing instead of tasks
+	var sortBy string
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -793,6 +912,15 @@ func cmdList(ctx context.Context, client pb.ClocheServiceClient, args []string)
 			all = true
 		case "--runs":
 			runs = true
+		case "--sort":
+			if i+1 < len(args) {
+				i++
+				if _, err := domain.ParseRunOrder(args[i]); err != nil {
+					fmt.Fprintf(os.Stderr, "error: --sort: %v\n", err)
+					os.Exit(1)
+				}
+				sortBy = args[i]
+			}
 		case "--project", "-p":
 			if i+1 < len(args) {
 				i++
@@ -817,9 +945,13 @@ func cmdList(ctx context.Context, client pb.ClocheServiceClient, args []string)
 	}
 
 	if runs {
-		cmdListRuns(ctx, client, all, projectDir, stateFilter, limit)
+		cmdListRuns(ctx, client, all, projectDir, stateFilter, sortBy, limit)
 		return
 	}
+	if sortBy != "" {
+		fmt.Fprintf(os.Stderr, "error: --sort applies to the run listing; add --runs\n")
+		os.Exit(1)
+	}
 
 	// Default: task-oriented listing
 	req := &pb.ListTasksRequest{
@@ -873,10 +1005,11 @@ func cmdList(ctx context.Context, client pb.ClocheServiceClient, args []string)
 }
 
 // cmdListRuns shows a flat run listing (legacy mode, accessible via --runs).
-func cmdListRuns(ctx context.Context, client pb.ClocheServiceClient, all bool, projectDir, stateFilter string, limit int32) {
+func cmdListRuns(ctx context.Context, client pb.ClocheServiceClient, all bool, projectDir, stateFilter, sortBy string, limit int32) {
 	req := &pb.ListRunsRequest{
 		State: stateFilter,
 		Limit: limit,
+		Sort:  sortBy,
 	}
 	if projectDir != "" {
 		req.ProjectDir = projectDir
@@ -899,7 +1032,7 @@ func cmdListRuns(ctx context.Context, client pb.ClocheServiceClient, all bool, p
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "RUN ID\tWORKFLOW\tSTATE\tTYPE\tTASK ID\tTITLE\tERROR")
+	fmt.Fprintln(w, "RUN ID\tWORKFLOW\tSTATE\tTYPE\tTASK ID\tSTEPS\tDURATION\tTITLE\tERROR")
 	for _, run := range resp.Runs {
 		runType := "container"
 		if run.IsHost {
@@ -922,22 +1055,44 @@ func cmdListRuns(ctx context.Context, client pb.ClocheServiceClient, all bool, p
 				state = fmt.Sprintf("%s [%q]", run.State, run.WaitingStep)
 			}
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			run.RunId, run.WorkflowName, state, runType,
-			run.TaskId, title, errMsg)
+			run.TaskId, formatRunSteps(run), formatRunDuration(run), title, errMsg)
 	}
 	w.Flush()
 }
 
+// formatRunSteps renders a finished run's step count, with the total
+// executions when retries ran some steps more than once, e.g. "4 (6 attempts)".
+// Unfinished runs, which have no recorded counts, show "-".
+func formatRunSteps(run *pb.RunSummary) string {
+	if run.AttemptCount == 0 {
+		return "-"
+	}
+	if run.AttemptCount == run.StepCount {
+		return fmt.Sprintf("%d", run.StepCount)
+	}
+	return fmt.Sprintf("%d (%d attempts)", run.StepCount, run.AttemptCount)
+}
+
+// formatRunDuration renders a finished run's wall time, or "-" when the run
+// has not finished.
+func formatRunDuration(run *pb.RunSummary) string {
+	if run.DurationSeconds == 0 {
+		return "-"
+	}
+	return formatStepDuration(time.Duration(run.DurationSeconds) * time.Second)
+}
+
 func cmdLogs(client pb.ClocheServiceClient, args []string) {
 	if len(args) < 1 {
-		fmt.Fprintf(os.Stderr, "usage: cloche logs <id> [--type <full|script|llm>] [-f] [-l <n>]\n")
+		fmt.Fprintf(os.Stderr, "usage: cloche logs <id> [--type <full|script|llm>] [-f] [-l <n>] [--json] [--timestamps <relative|absolute|off>]\n")
 		fmt.Fprintf(os.Stderr, "  <id>: task ID, attempt ID (a133), workflow ID (a133:develop), or step ID (a133:develop:review)\n")
 		os.Exit(1)
 	}
 
-	var stepFilter, typeFilter string
-	var follow bool
+	var stepFilter, typeFilter, timestamps string
+	var follow, asJSON bool
 	var limit int
 	id := args[0]
 
@@ -955,6 +1110,13 @@ func cmdLogs(client pb.ClocheServiceClient, args []string) {
 			}
 		case "--follow", "-f":
 			follow = true
+		case "--json":
+			asJSON = true
+		case "--timestamps":
+			if i+1 < len(args) {
+				i++
+				timestamps = args[i]
+			}
 		case "--limit", "-l":
 			if i+1 < len(args) {
 				i++
@@ -965,11 +1127,32 @@ func cmdLogs(client pb.ClocheServiceClient, args []string) {
 				}
 				limit = n
 			}
+		default:
+			if v, ok := strings.CutPrefix(args[i], "--timestamps="); ok {
+				timestamps = v
+			}
 		}
 	}
 
 	// Use background context — log output can be large and follow mode blocks.
 	ctx := context.Background()
+
+	// Relative timestamps count from the run's start, which the log stream
+	// itself does not carry.
+	var runStart time.Time
+	if timestamps == "relative" {
+		status, err := client.GetStatus(ctx, &pb.GetStatusRequest{Id: id})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		runStart, _ = time.Parse(time.RFC3339Nano, status.StartedAt)
+	}
+	stamp, err := newLogTimestamper(timestamps, runStart)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
 	var mdPairs []string
 	if follow {
 		mdPairs = append(mdPairs, "x-cloche-follow", "true")
@@ -984,59 +1167,341 @@ func cmdLogs(client pb.ClocheServiceClient, args []string) {
 
 	// Pass id via the Id field so the server can resolve task IDs, attempt IDs,
 	// run IDs, and composite IDs (task:attempt:step).
-	stream, err := client.StreamLogs(ctx, &pb.StreamLogsRequest{
+	req := &pb.StreamLogsRequest{
 		Id:       id,
 		StepName: stepFilter,
 		LogType:  typeFilter,
-	})
+	}
+	stream, err := client.StreamLogs(ctx, req)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// A transient error mid-follow reconnects instead of ending the tail.
+	reopen := func() (logEntryReceiver, error) { return client.StreamLogs(ctx, req) }
+	if follow {
+		err = writeLogsResuming(os.Stdout, os.Stderr, stream, reopen, asJSON, stamp, realClock{})
+	} else {
+		err = writeLogs(os.Stdout, stream, asJSON, stamp)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading logs: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// logEntryReceiver is the subset of the StreamLogs client stream used by
+// writeLogs, so tests can feed entries without a live daemon.
+type logEntryReceiver interface {
+	Recv() (*pb.LogEntry, error)
+}
+
+// writeLogs drains stream into w until EOF, rendering each entry either in
+// the human format or as one JSON object per line. A non-nil stamp prefixes
+// human-format entries with their timestamp; JSON output always carries the
+// raw timestamp instead.
+func writeLogs(w io.Writer, stream logEntryReceiver, asJSON bool, stamp logTimestamper) error {
+	var enc *json.Encoder
+	if asJSON {
+		enc = json.NewEncoder(w)
+	}
+
 	for {
 		entry, err := stream.Recv()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				break
+				return nil
 			}
-			fmt.Fprintf(os.Stderr, "error reading logs: %v\n", err)
-			os.Exit(1)
+			return err
 		}
 
-		switch entry.Type {
-		case "step_started":
-			fmt.Printf("--- %s started ---\n", entry.StepName)
-			if entry.Message != "" {
-				fmt.Printf("%s\n", entry.Message)
+		if enc != nil {
+			if err := enc.Encode(newLogRecord(entry)); err != nil {
+				return err
 			}
-		case "step_completed":
-			fmt.Printf("--- %s: %s ---\n", entry.StepName, entry.Result)
-			if entry.Message != "" {
-				fmt.Printf("%s\n", entry.Message)
+			continue
+		}
+		prefix := ""
+		if stamp != nil {
+			prefix = stamp(entry.Timestamp)
+		}
+		printLogEntry(w, entry, prefix)
+	}
+}
+
+// Reconnect backoff for "cloche logs -f" after a transient stream error.
+const (
+	logReconnectMin = 500 * time.Millisecond
+	logReconnectMax = 10 * time.Second
+)
+
+// writeLogsResuming is writeLogs for follow mode: when the stream fails with a
+// transient error (the daemon restarted or the connection dropped), it waits
+// with exponential backoff, opens a new stream with reopen and carries on
+// from the last entry it printed. It returns when a stream ends cleanly,
+// which the daemon does once the run finishes, or on a non-transient error.
+func writeLogsResuming(w, errw io.Writer, stream logEntryReceiver, reopen func() (logEntryReceiver, error), asJSON bool, stamp logTimestamper, clock pollClock) error {
+	cursor := &logCursor{}
+	backoff := logReconnectMin
+	var err error
+	for {
+		if stream != nil {
+			delivered := cursor.delivered
+			err = writeLogs(w, cursor.resume(stream), asJSON, stamp)
+			if err == nil || !isTransientStreamError(err) || cursor.completed {
+				return err
 			}
-		case "run_completed":
-			fmt.Printf("\nRun result: %s\n", entry.Result)
-			if entry.Message != "" {
-				fmt.Printf("Error:      %s\n", entry.Message)
+			if cursor.delivered > delivered {
+				backoff = logReconnectMin
 			}
-		case "full_log":
-			fmt.Print(string(logstream.ParseClaudeStream([]byte(entry.Message))))
-		case "log_chunk":
-			// Continuation chunk from a chunked log response (large files).
-			fmt.Print(string(logstream.ParseClaudeStream([]byte(entry.Message))))
-		case "log":
-			// Live-streamed log line from an active run.
-			fmt.Println(entry.Message)
-		default:
-			// Handles filtered log entries like "script_log", "llm_log", "step_log"
-			if entry.StepName != "" {
-				fmt.Printf("--- %s ---\n", entry.StepName)
+		}
+		fmt.Fprintf(errw, "log stream interrupted (%s); reconnecting in %s\n", grpcStatus.Convert(err).Message(), backoff)
+		clock.Sleep(backoff)
+		backoff = min(backoff*2, logReconnectMax)
+
+		stream, err = reopen()
+		if err != nil {
+			if !isTransientStreamError(err) {
+				return err
 			}
-			if entry.Message != "" {
-				fmt.Print(string(logstream.ParseClaudeStream([]byte(entry.Message))))
+			stream = nil
+		}
+	}
+}
+
+// isTransientStreamError reports whether a log stream error is worth
+// reconnecting after: the daemon is unreachable or the transport broke.
+func isTransientStreamError(err error) bool {
+	return grpcStatus.Code(err) == codes.Unavailable
+}
+
+// logCursor remembers how far a followed log got, so a resumed stream, which
+// replays the run's log from the start, prints only what is new. Entries are
+// ordered by timestamp; since live timestamps have one-second resolution, it
+// also counts the entries and full.log lines printed at the latest timestamp.
+type logCursor struct {
+	since     time.Time // timestamp of the newest entry printed
+	atSince   int       // entries and full.log lines printed with timestamp since
+	replayed  int       // entries and full.log lines with timestamp since seen on the current stream
+	skipping  bool      // the last entry or full.log line was dropped
+	inFullLog bool      // log_chunk entries continue full.log content
+	delivered int       // entries printed in total
+	completed bool      // the run_completed entry was printed
+}
+
+// resume wraps a new stream so that entries already printed are dropped.
+func (c *logCursor) resume(stream logEntryReceiver) logEntryReceiver {
+	c.replayed = 0
+	c.skipping = false
+	c.inFullLog = false
+	return &resumedLogStream{stream: stream, cursor: c}
+}
+
+// admit reports whether entry is new and advances the cursor past it.
+// full.log content is trimmed to its new lines rather than dropped whole.
+func (c *logCursor) admit(entry *pb.LogEntry) bool {
+	if entry.Type == "log_chunk" {
+		if c.inFullLog {
+			return c.admitFullLog(entry)
+		}
+		return !c.skipping && c.deliver(entry)
+	}
+	c.inFullLog = entry.Type == "full_log"
+	if c.inFullLog {
+		return c.admitFullLog(entry)
+	}
+
+	c.skipping = false
+	if t, ok := parseLogTimestamp(entry.Timestamp); ok {
+		c.skipping = c.printed(t)
+	}
+	return !c.skipping && c.deliver(entry)
+}
+
+// printed reports whether the next entry or full.log line stamped t was
+// already printed, and otherwise advances the cursor past it. Of the ones
+// stamped with the cursor's own second, as many as were printed before are
+// skipped.
+func (c *logCursor) printed(t time.Time) bool {
+	switch {
+	case t.Before(c.since):
+		return true
+	case t.Equal(c.since):
+		c.replayed++
+		if c.replayed <= c.atSince {
+			return true
+		}
+		c.atSince++
+		return false
+	default:
+		c.since, c.atSince, c.replayed = t, 1, 1
+		return false
+	}
+}
+
+// admitFullLog keeps the lines of full.log content not already printed,
+// moving the cursor past each kept line. Lines without a timestamp follow
+// the line before them.
+func (c *logCursor) admitFullLog(entry *pb.LogEntry) bool {
+	var kept []string
+	for _, line := range strings.SplitAfter(entry.Message, "\n") {
+		if line == "" {
+			continue
+		}
+		if ts, _, ok := strings.Cut(line, "] "); ok && strings.HasPrefix(ts, "[") {
+			if t, err := time.Parse(time.RFC3339, ts[1:]); err == nil {
+				c.skipping = c.printed(t)
 			}
 		}
+		if !c.skipping {
+			kept = append(kept, line)
+		}
+	}
+	entry.Message = strings.Join(kept, "")
+	return entry.Message != "" && c.deliver(entry)
+}
+
+func (c *logCursor) deliver(entry *pb.LogEntry) bool {
+	c.delivered++
+	if entry.Type == "run_completed" {
+		c.completed = true
+	}
+	return true
+}
+
+// resumedLogStream passes through the entries of a stream that logCursor
+// admits.
+type resumedLogStream struct {
+	stream logEntryReceiver
+	cursor *logCursor
+}
+
+func (r *resumedLogStream) Recv() (*pb.LogEntry, error) {
+	for {
+		entry, err := r.stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if r.cursor.admit(entry) {
+			return entry, nil
+		}
+	}
+}
+
+// logTimestamper returns the prefix shown before a log entry for its raw
+// timestamp, or "" when the timestamp is missing or unparseable.
+type logTimestamper func(timestamp string) string
+
+// newLogTimestamper returns the timestamper for a --timestamps mode: nil for
+// "off" (the default), elapsed time since runStart for "relative", and local
+// wall-clock time for "absolute".
+func newLogTimestamper(mode string, runStart time.Time) (logTimestamper, error) {
+	switch mode {
+	case "", "off":
+		return nil, nil
+	case "relative":
+		return func(timestamp string) string {
+			t, ok := parseLogTimestamp(timestamp)
+			if !ok || runStart.IsZero() {
+				return ""
+			}
+			return "[" + formatElapsed(t.Sub(runStart)) + "] "
+		}, nil
+	case "absolute":
+		return func(timestamp string) string {
+			t, ok := parseLogTimestamp(timestamp)
+			if !ok {
+				return ""
+			}
+			return "[" + t.Local().Format("2006-01-02 15:04:05") + "] "
+		}, nil
+	}
+	return nil, fmt.Errorf("--timestamps must be relative, absolute, or off, got %q", mode)
+}
+
+// parseLogTimestamp parses a log entry timestamp. The daemon sends RFC3339 for
+// live lines and time.Time.String() for entries built from step captures.
+func parseLogTimestamp(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, true
+	}
+	// Drop the monotonic clock reading ("m=+1.23") if present.
+	if i := strings.Index(s, " m="); i >= 0 {
+		s = s[:i]
+	}
+	if t, err := time.Parse(goTimeLayout, s); err == nil && !t.IsZero() {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// formatElapsed renders d as a fixed-width +HH:MM:SS so stamped lines align.
+// Entries logged before the run start clamp to zero.
+func formatElapsed(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	secs := int(d / time.Second)
+	return fmt.Sprintf("+%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+
+// logRecord is the JSON-lines shape emitted by "cloche logs --json". Field
+// names are stable so the output can be piped into jq and similar tools.
+type logRecord struct {
+	Type      string `json:"type"`
+	Step      string `json:"step"`
+	Result    string `json:"result"`
+	Timestamp string `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+func newLogRecord(entry *pb.LogEntry) logRecord {
+	return logRecord{
+		Type:      entry.Type,
+		Step:      entry.StepName,
+		Result:    entry.Result,
+		Timestamp: entry.Timestamp,
+		Message:   entry.Message,
+	}
+}
+
+// printLogEntry renders a single log entry in the human-readable format.
+// prefix (e.g. a timestamp) is written before the entry's header or line;
+// raw log content is left unprefixed.
+func printLogEntry(w io.Writer, entry *pb.LogEntry, prefix string) {
+	switch entry.Type {
+	case "step_started":
+		fmt.Fprintf(w, "%s--- %s started ---\n", prefix, entry.StepName)
+		if entry.Message != "" {
+			fmt.Fprintf(w, "%s\n", entry.Message)
+		}
+	case "step_completed":
+		fmt.Fprintf(w, "%s--- %s: %s ---\n", prefix, entry.StepName, entry.Result)
+		if entry.Message != "" {
+			fmt.Fprintf(w, "%s\n", entry.Message)
+		}
+	case "run_completed":
+		fmt.Fprintf(w, "\n%sRun result: %s\n", prefix, entry.Result)
+		if entry.Message != "" {
+			fmt.Fprintf(w, "Error:      %s\n", entry.Message)
+		}
+	case "full_log":
+		fmt.Fprint(w, string(logstream.ParseClaudeStream([]byte(entry.Message))))
+	case "log_chunk":
+		// Continuation chunk from a chunked log response (large files).
+		fmt.Fprint(w, string(logstream.ParseClaudeStream([]byte(entry.Message))))
+	case "log":
+		// Live-streamed log line from an active run.
+		fmt.Fprintln(w, prefix+entry.Message)
+	default:
+		// Handles filtered log entries like "script_log", "llm_log", "step_log"
+		if entry.StepName != "" {
+			fmt.Fprintf(w, "%s--- %s ---\n", prefix, entry.StepName)
+		}
+		if entry.Message != "" {
+			fmt.Fprint(w, string(logstream.ParseClaudeStream([]byte(entry.Message))))
+		}
 	}
 }
 
@@ -1098,7 +1563,22 @@ func cmdLoop(ctx context.Context, client pb.ClocheServiceClient, args []string)
 
 	// Check for "once" subcommand
 	if len(args) > 0 && args[0] == "once" {
-		cmdLoopOnce(ctx, client, cwd)
+		settings, err := pollSettingsFromEnv()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		for i := 1; i < len(args); i++ {
+			n, err := settings.parseFlag(args, i)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			if n > 0 {
+				i += n - 1
+			}
+		}
+		cmdLoopOnce(ctx, client, cwd, settings)
 		return
 	}
 
@@ -1141,7 +1621,7 @@ func cmdLoop(ctx context.Context, client pb.ClocheServiceClient, args []string)
 
 // cmdLoopOnce enables the orchestration loop with max_concurrent=1, waits for
 // exactly one run to reach a terminal state, then disables the loop.
-func cmdLoopOnce(ctx context.Context, client pb.ClocheServiceClient, projectDir string) {
+func cmdLoopOnce(ctx context.Context, client pb.ClocheServiceClient, projectDir string, settings pollSettings) {
 	// Snapshot current run IDs so we can detect a new one.
 	existing := make(map[string]bool)
 	if resp, err := client.ListRuns(ctx, &pb.ListRunsRequest{ProjectDir: projectDir}); err == nil {
@@ -1162,7 +1642,7 @@ func cmdLoopOnce(ctx context.Context, client pb.ClocheServiceClient, projectDir
 	fmt.Println("Orchestration loop started (once mode).")
 
 	// Run the polling loop; stopLoop ensures the loop is always disabled.
-	exitCode := loopOnceWait(ctx, client, projectDir, existing)
+	exitCode := loopOnceWait(ctx, client, projectDir, existing, settings)
 
 	_, _ = client.DisableLoop(ctx, &pb.DisableLoopRequest{ProjectDir: projectDir})
 	fmt.Println("Orchestration loop stopped.")
@@ -1170,16 +1650,21 @@ func cmdLoopOnce(ctx context.Context, client pb.ClocheServiceClient, projectDir
 }
 
 // loopOnceWait polls until a new run reaches a terminal state. Returns 0 on
-// success, 1 on failure/cancellation/error.
-func loopOnceWait(ctx context.Context, client pb.ClocheServiceClient, projectDir string, existing map[string]bool) int {
-	pollInterval := 3 * time.Second
-	for {
-		time.Sleep(pollInterval)
+// success, 1 on failure/cancellation/error or when the poll timeout passes.
+func loopOnceWait(ctx context.Context, client pb.ClocheServiceClient, projectDir string, existing map[string]bool, settings pollSettings) int {
+	exitCode := 0
+	first := true
+	err := settings.wait(func() (bool, error) {
+		// The loop has only just been enabled; give it one interval to
+		// dispatch before the first check.
+		if first {
+			first = false
+			return false, nil
+		}
 
 		resp, err := client.ListRuns(ctx, &pb.ListRunsRequest{ProjectDir: projectDir})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error polling runs: %v\n", err)
-			return 1
+			return false, fmt.Errorf("polling runs: %w", err)
 		}
 
 		for _, r := range resp.Runs {
@@ -1190,17 +1675,25 @@ func loopOnceWait(ctx context.Context, client pb.ClocheServiceClient, projectDir
 			switch domain.RunState(r.State) {
 			case domain.RunStateSucceeded:
 				fmt.Printf("Run %s succeeded.\n", r.RunId)
-				return 0
+				return true, nil
 			case domain.RunStateFailed:
 				fmt.Printf("Run %s failed.\n", r.RunId)
-				return 1
+				exitCode = 1
+				return true, nil
 			case domain.RunStateCancelled:
 				fmt.Printf("Run %s cancelled.\n", r.RunId)
-				return 1
+				exitCode = 1
+				return true, nil
 			}
 			// Still pending/running — keep polling.
 		}
+		return false, nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
 	}
+	return exitCode
 }
 
 func cmdTasks(args []string) {
@@ -1314,10 +1807,7 @@ func cmdShutdown(ctx context.Context, client pb.ClocheServiceClient, args []stri
 
 	if restart {
 		if daemonWasRunning {
-			daemonAddr := os.Getenv("CLOCHE_ADDR")
-			if daemonAddr == "" {
-				daemonAddr = config.DefaultAddr()
-			}
+			daemonAddr := config.ClientAddr()
 			fmt.Print("Waiting for daemon to exit...")
 			if err := waitForDaemonExit(daemonAddr, 30*time.Second); err != nil {
 				fmt.Fprintf(os.Stderr, "\nerror: %v\n", err)
@@ -1394,13 +1884,9 @@ func resolveRunContext() (taskID, attemptID, runID string, err error) {
 	return taskID, attemptID, runID, nil
 }
 
-// dialDaemon creates a gRPC connection to the daemon using CLOCHE_ADDR or the
-// default socket address.
+// dialDaemon creates a gRPC connection to the daemon at config.ClientAddr.
 func dialDaemon() (*grpc.ClientConn, error) {
-	addr := os.Getenv("CLOCHE_ADDR")
-	if addr == "" {
-		addr = config.DefaultAddr()
-	}
+	addr := config.ClientAddr()
 	return grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 }
 
@@ -1509,10 +1995,7 @@ func cmdVersion() {
 
 	// Try to get daemon version via gRPC
 	daemonVersion := "<unavailable>"
-	addr := os.Getenv("CLOCHE_ADDR")
-	if addr == "" {
-		addr = config.DefaultAddr()
-	}
+	addr := config.ClientAddr()
 	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err == nil {
 		defer conn.Close()
@@ -12,26 +12,41 @@ import (
 )
 
 func cmdPoll(client pb.ClocheServiceClient, args []string) {
-	// Filter out --no-color (handled globally) and collect IDs.
+	settings, err := pollSettingsFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Filter out --no-color (handled globally) and poll flags; collect IDs.
 	var ids []string
-	for _, arg := range args {
-		if arg == "--no-color" {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--no-color" {
+			continue
+		}
+		n, err := settings.parseFlag(args, i)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if n > 0 {
+			i += n - 1
 			continue
 		}
-		ids = append(ids, arg)
+		ids = append(ids, args[i])
 	}
 
 	if len(ids) < 1 {
-		fmt.Fprintf(os.Stderr, "usage: cloche poll <id> [id...]\n")
+		fmt.Fprintf(os.Stderr, "usage: cloche poll <id> [id...] [--poll-interval <dur>] [--poll-timeout <dur>]\n")
 		fmt.Fprintf(os.Stderr, "  <id> may be a task ID, attempt ID, workflow ID (attempt:workflow),\n")
 		fmt.Fprintf(os.Stderr, "  or step ID (attempt:workflow:step)\n")
 		os.Exit(1)
 	}
 
 	if len(ids) == 1 {
-		cmdPollSingle(client, ids[0])
+		os.Exit(cmdPollSingle(client, ids[0], settings))
 	} else {
-		exitCode := cmdPollMulti(client, ids, os.Stdout, os.Stderr)
+		exitCode := cmdPollMulti(client, ids, settings, os.Stdout, os.Stderr)
 		os.Exit(exitCode)
 	}
 }
@@ -46,24 +61,26 @@ func extractStepName(id string) string {
 	return ""
 }
 
-func cmdPollSingle(client pb.ClocheServiceClient, id string) {
-	pollInterval := 2 * time.Second
+// cmdPollSingle polls one ID, printing step-level progress, and returns the
+// process exit code.
+func cmdPollSingle(client pb.ClocheServiceClient, id string, settings pollSettings) int {
 	containerDeadThreshold := 1 * time.Minute
 
 	var lastStepCount int
 	var lastState string
+	var lastQueuePosition int32
+	exitCode := 0
 
 	// Detect step-level ID: 3 colon-separated parts → last part is step name.
 	stepName := extractStepName(id)
 
-	for {
+	err := settings.wait(func() (bool, error) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		resp, err := client.GetStatus(ctx, &pb.GetStatusRequest{Id: id})
 		cancel()
 
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return false, err
 		}
 
 		// Print new step events
@@ -82,27 +99,33 @@ func cmdPollSingle(client pb.ClocheServiceClient, id string) {
 		if stepName != "" {
 			for _, exec := range resp.StepExecutions {
 				if exec.StepName == stepName && exec.Result != "" {
-					os.Exit(0)
+					return true, nil
 				}
 			}
 		}
 
-		// Print state changes
-		if resp.State != lastState {
+		// Print state changes, including moves up a max_concurrent queue.
+		if resp.State != lastState || resp.QueuePosition != lastQueuePosition {
 			ts := time.Now().Format("15:04:05")
-			fmt.Printf("[%s] Run %s is %s\n", ts, colorID(resp.RunId), colorStatus(resp.State))
+			if resp.QueuePosition > 0 {
+				fmt.Printf("[%s] Run %s is %s (queued, position %d)\n", ts, colorID(resp.RunId), colorStatus(resp.State), resp.QueuePosition)
+			} else {
+				fmt.Printf("[%s] Run %s is %s\n", ts, colorID(resp.RunId), colorStatus(resp.State))
+			}
 			lastState = resp.State
+			lastQueuePosition = resp.QueuePosition
 		}
 
 		// Check terminal states
 		switch resp.State {
 		case "succeeded":
-			os.Exit(0)
+			return true, nil
 		case "failed", "cancelled":
 			if resp.ErrorMessage != "" {
 				fmt.Fprintf(os.Stderr, "Error: %s\n", resp.ErrorMessage)
 			}
-			os.Exit(1)
+			exitCode = 1
+			return true, nil
 		}
 
 		// Check container death
@@ -111,12 +134,17 @@ func cmdPollSingle(client pb.ClocheServiceClient, id string) {
 			if err == nil && time.Since(deadSince) > containerDeadThreshold {
 				fmt.Fprintf(os.Stderr, "[%s] Container has been dead for >1 minute (since %s)\n",
 					time.Now().Format("15:04:05"), deadSince.Format("15:04:05"))
-				os.Exit(1)
+				exitCode = 1
+				return true, nil
 			}
 		}
-
-		time.Sleep(pollInterval)
+		return false, nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
 	}
+	return exitCode
 }
 
 // isTerminalState returns true if the run state is a terminal state.
@@ -130,11 +158,10 @@ func isTerminalState(state string) bool {
 
 // cmdPollMulti polls multiple IDs and displays a compact status summary.
 // Returns 0 if all runs succeeded, 1 if any failed or were cancelled.
-func cmdPollMulti(client pb.ClocheServiceClient, ids []string, stdout, stderr io.Writer) int {
-	pollInterval := 2 * time.Second
+func cmdPollMulti(client pb.ClocheServiceClient, ids []string, settings pollSettings, stdout, stderr io.Writer) int {
 	states := make(map[string]string, len(ids))
 
-	for {
+	err := settings.wait(func() (bool, error) {
 		changed := false
 		for _, id := range ids {
 			if isTerminalState(states[id]) {
@@ -166,24 +193,22 @@ func cmdPollMulti(client pb.ClocheServiceClient, ids []string, stdout, stderr io
 		}
 
 		// Check if all runs are in terminal states
-		allDone := true
 		for _, id := range ids {
 			if !isTerminalState(states[id]) && states[id] != "error" {
-				allDone = false
-				break
+				return false, nil
 			}
 		}
+		return true, nil
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
 
-		if allDone {
-			for _, id := range ids {
-				s := states[id]
-				if s != "succeeded" {
-					return 1
-				}
-			}
-			return 0
+	for _, id := range ids {
+		if states[id] != "succeeded" {
+			return 1
 		}
-
-		time.Sleep(pollInterval)
 	}
+	return 0
 }
@@ -86,7 +86,7 @@ func TestCmdPollMulti_AllSucceeded(t *testing.T) {
 	}
 
 	var stdout, stderr bytes.Buffer
-	exitCode := cmdPollMulti(client, []string{"run1", "run2"}, &stdout, &stderr)
+	exitCode := cmdPollMulti(client, []string{"run1", "run2"}, testPollSettings(), &stdout, &stderr)
 
 	if exitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", exitCode)
@@ -114,7 +114,7 @@ func TestCmdPollMulti_OneFailed(t *testing.T) {
 	}
 
 	var stdout, stderr bytes.Buffer
-	exitCode := cmdPollMulti(client, []string{"run1", "run2"}, &stdout, &stderr)
+	exitCode := cmdPollMulti(client, []string{"run1", "run2"}, testPollSettings(), &stdout, &stderr)
 
 	if exitCode != 1 {
 		t.Errorf("expected exit code 1 when a run failed, got %d", exitCode)
@@ -131,7 +131,7 @@ func TestCmdPollMulti_OneCancelled(t *testing.T) {
 	}
 
 	var stdout, stderr bytes.Buffer
-	exitCode := cmdPollMulti(client, []string{"run1", "run2"}, &stdout, &stderr)
+	exitCode := cmdPollMulti(client, []string{"run1", "run2"}, testPollSettings(), &stdout, &stderr)
 
 	if exitCode != 1 {
 		t.Errorf("expected exit code 1 when a run was cancelled, got %d", exitCode)
@@ -146,7 +146,7 @@ func TestCmdPollMulti_ErrorState(t *testing.T) {
 	// run2 is not in the mock, so GetStatus returns an error
 
 	var stdout, stderr bytes.Buffer
-	exitCode := cmdPollMulti(client, []string{"run1", "run2"}, &stdout, &stderr)
+	exitCode := cmdPollMulti(client, []string{"run1", "run2"}, testPollSettings(), &stdout, &stderr)
 
 	if exitCode != 1 {
 		t.Errorf("expected exit code 1 when a run has error, got %d", exitCode)
@@ -168,7 +168,7 @@ func TestCmdPollMulti_OutputFormat(t *testing.T) {
 	}
 
 	var stdout, stderr bytes.Buffer
-	_ = cmdPollMulti(client, []string{"aaa", "bbb"}, &stdout, &stderr)
+	_ = cmdPollMulti(client, []string{"aaa", "bbb"}, testPollSettings(), &stdout, &stderr)
 
 	output := stdout.String()
 	lines := strings.Split(strings.TrimSpace(output), "\n")
@@ -194,7 +194,7 @@ func TestCmdPollMulti_OnlyPrintsOnChange(t *testing.T) {
 	}
 
 	var stdout, stderr bytes.Buffer
-	_ = cmdPollMulti(client, []string{"run1"}, &stdout, &stderr)
+	_ = cmdPollMulti(client, []string{"run1"}, testPollSettings(), &stdout, &stderr)
 
 	output := stdout.String()
 	count := strings.Count(output, "run1: running")
@@ -232,7 +232,7 @@ func TestCmdPollMulti_UsesIdField(t *testing.T) {
 	}
 
 	var stdout, stderr bytes.Buffer
-	exitCode := cmdPollMulti(client, []string{"a133:develop"}, &stdout, &stderr)
+	exitCode := cmdPollMulti(client, []string{"a133:develop"}, testPollSettings(), &stdout, &stderr)
 
 	if exitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", exitCode)
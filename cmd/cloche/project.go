@@ -15,10 +15,7 @@ import (
 )
 
 func cmdProject(args []string) {
-	addr := os.Getenv("CLOCHE_ADDR")
-	if addr == "" {
-		addr = config.DefaultAddr()
-	}
+	addr := config.ClientAddr()
 	if err := projectCommand(args, addr, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
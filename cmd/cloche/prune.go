@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+)
+
+func cmdPrune(ctx context.Context, client pb.ClocheServiceClient, args []string) {
+	req := &pb.PruneRequest{}
+	var all bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--runs-older-than":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "cloche prune: --runs-older-than requires a duration\n")
+				os.Exit(1)
+			}
+			i++
+			d, err := parseAgeArg(args[i])
+			if err != nil || d <= 0 {
+				fmt.Fprintf(os.Stderr, "cloche prune: invalid --runs-older-than value %q (e.g. 30d, 12h)\n", args[i])
+				os.Exit(1)
+			}
+			req.RunsOlderThanSeconds = int64(d / time.Second)
+		case "--snapshots":
+			req.Snapshots = true
+		case "--containers":
+			req.Containers = true
+		case "--all":
+			all = true
+		case "--project", "-p":
+			if i+1 < len(args) {
+				i++
+				req.ProjectDir = args[i]
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "cloche prune: unknown argument %q\n", args[i])
+			os.Exit(1)
+		}
+	}
+
+	if req.RunsOlderThanSeconds == 0 && !req.Snapshots && !req.Containers {
+		fmt.Fprintf(os.Stderr, "usage: cloche prune [--runs-older-than <age>] [--snapshots] [--containers] [--project <dir> | --all]\n")
+		os.Exit(1)
+	}
+	if req.ProjectDir == "" && !all {
+		req.ProjectDir, _ = os.Getwd()
+	}
+
+	os.Exit(pruneRun(ctx, client, req, os.Stdout, os.Stderr))
+}
+
+// pruneRun calls the Prune RPC and reports what was removed.
+// Returns 0 on success, 1 if the RPC failed or any item could not be removed.
+// Separated for testability.
+func pruneRun(ctx context.Context, client pb.ClocheServiceClient, req *pb.PruneRequest, stdout, stderr io.Writer) int {
+	resp, err := client.Prune(ctx, req)
+	if err != nil {
+		fmt.Fprintf(stderr, "cloche prune: %v\n", err)
+		return 1
+	}
+
+	report := func(label string, items []string) {
+		fmt.Fprintf(stdout, "%s: %d\n", label, len(items))
+		for _, item := range items {
+			fmt.Fprintf(stdout, "  %s\n", item)
+		}
+	}
+	if req.RunsOlderThanSeconds > 0 {
+		report("Deleted runs", resp.DeletedRuns)
+	}
+	if req.Snapshots {
+		report("Removed snapshots", resp.RemovedSnapshots)
+	}
+	if req.Containers || len(resp.RemovedContainers) > 0 {
+		report("Removed containers", resp.RemovedContainers)
+	}
+
+	for _, e := range resp.Errors {
+		fmt.Fprintf(stderr, "cloche prune: %s\n", e)
+	}
+	if len(resp.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// parseAgeArg parses an age such as "30d" or "12h". Go durations are accepted
+// as-is; a trailing "d" is read as whole days.
+func parseAgeArg(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
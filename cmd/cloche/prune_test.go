@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type mockPruneClient struct {
+	pb.ClocheServiceClient
+	req  *pb.PruneRequest
+	resp *pb.PruneResponse
+}
+
+func (m *mockPruneClient) Prune(_ context.Context, req *pb.PruneRequest, _ ...grpc.CallOption) (*pb.PruneResponse, error) {
+	m.req = req
+	return m.resp, nil
+}
+
+func TestPruneRun_ReportsRemovedItems(t *testing.T) {
+	mock := &mockPruneClient{resp: &pb.PruneResponse{
+		DeletedRuns:       []string{"run-1", "run-2"},
+		RemovedSnapshots:  []string{"/p/.cloche/runs/T/snapshots/a1"},
+		RemovedContainers: []string{"ctr-1"},
+	}}
+	req := &pb.PruneRequest{ProjectDir: "/p", RunsOlderThanSeconds: 60, Snapshots: true, Containers: true}
+
+	var stdout, stderr bytes.Buffer
+	code := pruneRun(context.Background(), mock, req, &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	assert.Same(t, req, mock.req)
+	out := stdout.String()
+	assert.Contains(t, out, "Deleted runs: 2\n  run-1\n  run-2\n")
+	assert.Contains(t, out, "Removed snapshots: 1\n  /p/.cloche/runs/T/snapshots/a1\n")
+	assert.Contains(t, out, "Removed containers: 1\n  ctr-1\n")
+	assert.Empty(t, stderr.String())
+}
+
+func TestPruneRun_ItemErrorsFail(t *testing.T) {
+	mock := &mockPruneClient{resp: &pb.PruneResponse{Errors: []string{"container ctr-1 (run r): busy"}}}
+
+	var stdout, stderr bytes.Buffer
+	code := pruneRun(context.Background(), mock, &pb.PruneRequest{Containers: true}, &stdout, &stderr)
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout.String(), "Removed containers: 0")
+	assert.Contains(t, stderr.String(), "container ctr-1 (run r): busy")
+}
+
+func TestParseAgeArg(t *testing.T) {
+	d, err := parseAgeArg("30d")
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, d)
+
+	d, err = parseAgeArg("12h")
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Hour, d)
+
+	_, err = parseAgeArg("xd")
+	assert.Error(t, err)
+}
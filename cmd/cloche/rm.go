@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+)
+
+func cmdRm(ctx context.Context, client pb.ClocheServiceClient, args []string) {
+	req := &pb.DeleteRunsRequest{}
+	var all, yes bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--failed":
+			req.State = "failed"
+		case "--older-than":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "cloche rm: --older-than requires a duration\n")
+				os.Exit(1)
+			}
+			i++
+			d, err := parseAgeArg(args[i])
+			if err != nil || d <= 0 {
+				fmt.Fprintf(os.Stderr, "cloche rm: invalid --older-than value %q (e.g. 30d, 12h)\n", args[i])
+				os.Exit(1)
+			}
+			req.OlderThanSeconds = int64(d / time.Second)
+		case "--workflow", "-w":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "cloche rm: --workflow requires a name\n")
+				os.Exit(1)
+			}
+			i++
+			req.WorkflowName = args[i]
+		case "--dry-run", "-n":
+			req.DryRun = true
+		case "--yes", "-y":
+			yes = true
+		case "--all":
+			all = true
+		case "--project", "-p":
+			if i+1 < len(args) {
+				i++
+				req.ProjectDir = args[i]
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "cloche rm: unknown argument %q\n", args[i])
+			os.Exit(1)
+		}
+	}
+
+	// Refuse an unfiltered delete so a bare "cloche rm" cannot wipe history.
+	if req.State == "" && req.OlderThanSeconds == 0 && req.WorkflowName == "" {
+		fmt.Fprintf(os.Stderr, "usage: cloche rm [--failed] [--older-than <age>] [--workflow <name>] [--dry-run] [--yes] [--project <dir> | --all]\n")
+		os.Exit(1)
+	}
+	if req.ProjectDir == "" && !all {
+		// Like cloche run, target the project containing the working directory.
+		cwd, _ := os.Getwd()
+		req.ProjectDir = findProjectRoot(cwd)
+	}
+
+	var confirm io.Reader
+	if stdinOverride != nil || isTerminal(os.Stdin) {
+		confirm = getStdin()
+	}
+	os.Exit(rmRun(ctx, client, req, yes, confirm, os.Stdout, os.Stderr))
+}
+
+// rmRun previews the runs matching req, asks for confirmation on confirm
+// unless yes is set, and deletes them. A nil confirm means no one can answer,
+// so the delete is refused without yes. Returns 0 on success (including a dry
+// run or a declined prompt) and 1 on any failure. Separated for testability.
+func rmRun(ctx context.Context, client pb.ClocheServiceClient, req *pb.DeleteRunsRequest, yes bool, confirm io.Reader, stdout, stderr io.Writer) int {
+	preview := &pb.DeleteRunsRequest{
+		ProjectDir:       req.ProjectDir,
+		State:            req.State,
+		WorkflowName:     req.WorkflowName,
+		OlderThanSeconds: req.OlderThanSeconds,
+		DryRun:           true,
+	}
+	resp, err := client.DeleteRuns(ctx, preview)
+	if err != nil {
+		fmt.Fprintf(stderr, "cloche rm: %v\n", err)
+		return 1
+	}
+	if len(resp.Runs) == 0 {
+		fmt.Fprintln(stdout, "No matching runs.")
+		return 0
+	}
+
+	fmt.Fprintf(stdout, "Matching runs: %d\n", len(resp.Runs))
+	for _, r := range resp.Runs {
+		fmt.Fprintf(stdout, "  %s  %s  %s\n", r.RunId, r.WorkflowName, r.State)
+	}
+	if req.DryRun {
+		return 0
+	}
+
+	if !yes {
+		if confirm == nil {
+			fmt.Fprintf(stderr, "cloche rm: refusing to delete without confirmation; rerun with --yes\n")
+			return 1
+		}
+		fmt.Fprintf(stderr, "Delete %d run(s)? [y/N] ", len(resp.Runs))
+		line, _ := bufio.NewReader(confirm).ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+			fmt.Fprintln(stdout, "Nothing deleted.")
+			return 0
+		}
+	}
+
+	resp, err = client.DeleteRuns(ctx, req)
+	if err != nil {
+		fmt.Fprintf(stderr, "cloche rm: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Deleted runs: %d\n", len(resp.Runs))
+	for _, r := range resp.Runs {
+		fmt.Fprintf(stdout, "  %s\n", r.RunId)
+	}
+	if len(resp.RemovedArtifacts) > 0 {
+		fmt.Fprintf(stdout, "Removed artifacts: %d\n", len(resp.RemovedArtifacts))
+		for _, path := range resp.RemovedArtifacts {
+			fmt.Fprintf(stdout, "  %s\n", path)
+		}
+	}
+
+	for _, e := range resp.Errors {
+		fmt.Fprintf(stderr, "cloche rm: %s\n", e)
+	}
+	if len(resp.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
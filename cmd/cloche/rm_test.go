@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type mockDeleteRunsClient struct {
+	pb.ClocheServiceClient
+	reqs    []*pb.DeleteRunsRequest
+	matches []*pb.RunSummary
+}
+
+func (m *mockDeleteRunsClient) DeleteRuns(_ context.Context, req *pb.DeleteRunsRequest, _ ...grpc.CallOption) (*pb.DeleteRunsResponse, error) {
+	m.reqs = append(m.reqs, req)
+	resp := &pb.DeleteRunsResponse{Runs: m.matches}
+	if !req.DryRun {
+		resp.RemovedArtifacts = []string{"/p/.cloche/runs/r1"}
+	}
+	return resp, nil
+}
+
+func newMockDeleteRunsClient() *mockDeleteRunsClient {
+	return &mockDeleteRunsClient{matches: []*pb.RunSummary{
+		{RunId: "r1", WorkflowName: "develop", State: "failed"},
+		{RunId: "r2", WorkflowName: "develop", State: "failed"},
+	}}
+}
+
+func TestRmRun_ConfirmedDeletes(t *testing.T) {
+	mock := newMockDeleteRunsClient()
+	req := &pb.DeleteRunsRequest{ProjectDir: "/p", State: "failed"}
+
+	var stdout, stderr bytes.Buffer
+	code := rmRun(context.Background(), mock, req, false, strings.NewReader("y\n"), &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	assert.Len(t, mock.reqs, 2)
+	assert.True(t, mock.reqs[0].DryRun, "matches are previewed first")
+	assert.Equal(t, "failed", mock.reqs[0].State)
+	assert.Same(t, req, mock.reqs[1])
+	assert.Contains(t, stdout.String(), "Matching runs: 2\n  r1  develop  failed\n")
+	assert.Contains(t, stdout.String(), "Deleted runs: 2\n  r1\n  r2\n")
+	assert.Contains(t, stdout.String(), "Removed artifacts: 1\n  /p/.cloche/runs/r1\n")
+	assert.Contains(t, stderr.String(), "Delete 2 run(s)? [y/N]")
+}
+
+func TestRmRun_DeclinedDeletesNothing(t *testing.T) {
+	mock := newMockDeleteRunsClient()
+
+	var stdout, stderr bytes.Buffer
+	code := rmRun(context.Background(), mock, &pb.DeleteRunsRequest{State: "failed"}, false, strings.NewReader("n\n"), &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	assert.Len(t, mock.reqs, 1)
+	assert.Contains(t, stdout.String(), "Nothing deleted.")
+}
+
+func TestRmRun_DryRunAndNoTerminal(t *testing.T) {
+	mock := newMockDeleteRunsClient()
+	var stdout, stderr bytes.Buffer
+	code := rmRun(context.Background(), mock, &pb.DeleteRunsRequest{State: "failed", DryRun: true}, false, nil, &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Len(t, mock.reqs, 1)
+
+	mock = newMockDeleteRunsClient()
+	stdout.Reset()
+	code = rmRun(context.Background(), mock, &pb.DeleteRunsRequest{State: "failed"}, false, nil, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Len(t, mock.reqs, 1, "no confirmation source means no delete")
+	assert.Contains(t, stderr.String(), "rerun with --yes")
+
+	mock = newMockDeleteRunsClient()
+	code = rmRun(context.Background(), mock, &pb.DeleteRunsRequest{State: "failed"}, true, nil, &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Len(t, mock.reqs, 2)
+}
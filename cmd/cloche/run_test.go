@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"google.golang.org/grpc"
+)
+
+// runSyncMockClient implements just the RunWorkflowSync RPC.
+type runSyncMockClient struct {
+	pb.ClocheServiceClient
+	resp *pb.RunWorkflowSyncResponse
+	req  *pb.RunWorkflowRequest
+}
+
+func (m *runSyncMockClient) RunWorkflowSync(_ context.Context, req *pb.RunWorkflowRequest, _ ...grpc.CallOption) (*pb.RunWorkflowSyncResponse, error) {
+	m.req = req
+	return m.resp, nil
+}
+
+func TestRunAndWait_Succeeded(t *testing.T) {
+	client := &runSyncMockClient{resp: &pb.RunWorkflowSyncResponse{
+		RunId: "a1b2-develop", TaskId: "user-a1b2", AttemptId: "a1b2", State: "succeeded",
+	}}
+	var stdout, stderr bytes.Buffer
+
+	code := runAndWait(context.Background(), client, &pb.RunWorkflowRequest{WorkflowName: "develop"}, &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("expected exit 0, got %d", code)
+	}
+	if client.req.WorkflowName != "develop" {
+		t.Errorf("expected request to be forwarded, got %q", client.req.WorkflowName)
+	}
+	if !strings.Contains(stdout.String(), "Run a1b2-develop succeeded") {
+		t.Errorf("unexpected stdout: %q", stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("expected empty stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunAndWait_Failed(t *testing.T) {
+	client := &runSyncMockClient{resp: &pb.RunWorkflowSyncResponse{
+		RunId: "a1b2-develop", State: "failed", ErrorMessage: "step test failed",
+	}}
+	var stdout, stderr bytes.Buffer
+
+	code := runAndWait(context.Background(), client, &pb.RunWorkflowRequest{}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "step test failed") {
+		t.Errorf("expected error message on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunRequest_FromNestedDirectory(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".cloche"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	wf := `workflow develop {
+  container {
+    image = "project-image:latest"
+  }
+  step code {
+    prompt = "do it"
+    results = [success]
+  }
+  code:success -> done
+}
+`
+	if err := os.WriteFile(filepath.Join(root, ".cloche", "develop.cloche"), []byte(wf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(root, "internal", "api")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	req := runRequest(nested, "develop:code")
+	if req.ProjectDir != root {
+		t.Errorf("expected project dir %q, got %q", root, req.ProjectDir)
+	}
+	if req.WorkflowName != "develop:code" {
+		t.Errorf("expected workflow spec to be forwarded, got %q", req.WorkflowName)
+	}
+	if req.Image != "project-image:latest" {
+		t.Errorf("expected image from the project's workflow, got %q", req.Image)
+	}
+}
+
+func TestFindProjectRoot(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(repo, "cmd", "tool")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := findProjectRoot(nested); got != repo {
+		t.Errorf("expected git repo root %q, got %q", repo, got)
+	}
+
+	// A .cloche directory nearer than the repo root marks a nested project.
+	sub := filepath.Join(repo, "cmd")
+	if err := os.MkdirAll(filepath.Join(sub, ".cloche"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := findProjectRoot(nested); got != sub {
+		t.Errorf("expected nested project root %q, got %q", sub, got)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+)
+
+// goTimeLayout is the layout of time.Time.String(), which the daemon uses for
+// step execution timestamps.
+const goTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+func cmdSteps(ctx context.Context, client pb.ClocheServiceClient, args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: cloche steps <run-id>\n")
+		os.Exit(1)
+	}
+	os.Exit(stepsRun(ctx, client, args[0], os.Stdout, os.Stderr))
+}
+
+// stepsRun fetches a run's step executions and prints them as a table.
+// Returns 0 on success, 1 if the run could not be loaded. Separated for
+// testability.
+func stepsRun(ctx context.Context, client pb.ClocheServiceClient, id string, stdout, stderr io.Writer) int {
+	resp, err := client.GetStatus(ctx, &pb.GetStatusRequest{Id: id})
+	if err != nil {
+		fmt.Fprintf(stderr, "cloche steps: %v\n", err)
+		return 1
+	}
+	printSteps(stdout, resp, time.Now())
+	return 0
+}
+
+// printSteps writes one row per step execution in run order. ATTEMPT counts
+// executions of the same step within the run, so a step revisited by a retry
+// loop shows 1, 2, 3... Steps still running show their elapsed time as of now.
+// EXIT is the exit code of the step's process, or "-" when none was recorded.
+func printSteps(w io.Writer, resp *pb.GetStatusResponse, now time.Time) {
+	fmt.Fprintf(w, "Run: %s (%s, %s)\n", resp.RunId, resp.WorkflowName, resp.State)
+	if len(resp.StepExecutions) == 0 {
+		fmt.Fprintln(w, "No steps have run yet.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tATTEMPT\tRESULT\tSTARTED\tDURATION\tEXIT")
+	attempts := map[string]int{}
+	for _, exec := range resp.StepExecutions {
+		attempts[exec.StepName]++
+
+		result := exec.Result
+		if exec.Skipped {
+			result = "skipped"
+		}
+
+		started, startErr := time.Parse(goTimeLayout, exec.StartedAt)
+		completed, doneErr := time.Parse(goTimeLayout, exec.CompletedAt)
+		startedCol, durationCol := "-", "-"
+		if startErr == nil && !started.IsZero() {
+			startedCol = started.Local().Format("2006-01-02 15:04:05")
+			if doneErr == nil && !completed.IsZero() {
+				durationCol = formatStepDuration(completed.Sub(started))
+			} else {
+				durationCol = formatStepDuration(now.Sub(started)) + " (running)"
+			}
+		}
+		if result == "" {
+			result = "running"
+		}
+		exitCol := "-"
+		if exec.ExitCode != nil {
+			exitCol = fmt.Sprint(*exec.ExitCode)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n",
+			exec.StepName, attempts[exec.StepName], colorStatus(result), startedCol, durationCol, exitCol)
+	}
+	tw.Flush()
+}
+
+// formatStepDuration renders a step duration at a precision suited to its
+// length: sub-second steps in milliseconds, longer ones in s, m+s or h+m.
+func formatStepDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "-"
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+type mockStepsClient struct {
+	pb.ClocheServiceClient
+	resp *pb.GetStatusResponse
+	err  error
+}
+
+func (m *mockStepsClient) GetStatus(_ context.Context, _ *pb.GetStatusRequest, _ ...grpc.CallOption) (*pb.GetStatusResponse, error) {
+	return m.resp, m.err
+}
+
+func TestPrintSteps_Table(t *testing.T) {
+	base := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	at := func(d time.Duration) string { return base.Add(d).String() }
+	zero := time.Time{}.String()
+
+	resp := &pb.GetStatusResponse{
+		RunId:        "a1b2-develop",
+		WorkflowName: "develop",
+		State:        "running",
+		StepExecutions: []*pb.StepExecutionStatus{
+			{StepName: "implement", Result: "success", StartedAt: at(0), CompletedAt: at(4*time.Minute + 12*time.Second), ExitCode: proto.Int32(0)},
+			{StepName: "lint", Skipped: true, StartedAt: at(5 * time.Minute), CompletedAt: at(5*time.Minute + 250*time.Millisecond)},
+			{StepName: "test", Result: "fail", StartedAt: at(6 * time.Minute), CompletedAt: at(6*time.Minute + 38200*time.Millisecond), ExitCode: proto.Int32(2)},
+			{StepName: "test", StartedAt: at(10 * time.Minute), CompletedAt: zero},
+		},
+	}
+
+	var out bytes.Buffer
+	printSteps(&out, resp, base.Add(11*time.Minute))
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "Run: a1b2-develop (develop, running)", lines[0])
+	assert.Equal(t, []string{"STEP", "ATTEMPT", "RESULT", "STARTED", "DURATION", "EXIT"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"implement", "1", "success", "2026-10-16", "09:00:00", "4m12s", "0"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"lint", "1", "skipped", "2026-10-16", "09:05:00", "250ms", "-"}, strings.Fields(lines[3]))
+	assert.Equal(t, []string{"test", "1", "fail", "2026-10-16", "09:06:00", "38.2s", "2"}, strings.Fields(lines[4]))
+	assert.Equal(t, []string{"test", "2", "running", "2026-10-16", "09:10:00", "1m0s", "(running)", "-"}, strings.Fields(lines[5]))
+
+	// Columns line up across rows.
+	col := strings.Index(lines[1], "RESULT")
+	for _, line := range lines[2:] {
+		assert.Equal(t, " ", line[col-1:col], "row %q", line)
+		assert.NotEqual(t, " ", line[col:col+1], "row %q", line)
+	}
+}
+
+func TestPrintSteps_NoSteps(t *testing.T) {
+	var out bytes.Buffer
+	printSteps(&out, &pb.GetStatusResponse{RunId: "r1", WorkflowName: "develop", State: "pending"}, time.Now())
+	assert.Equal(t, "Run: r1 (develop, pending)\nNo steps have run yet.\n", out.String())
+}
+
+func TestStepsRun_Error(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := stepsRun(context.Background(), &mockStepsClient{err: errors.New("run not found")}, "nope", &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), "run not found")
+	assert.Empty(t, stdout.String())
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -14,6 +16,7 @@ import (
 
 func cmdValidate(args []string) {
 	var projectDir, workflowFilter string
+	var jsonOut, strict bool
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--project":
@@ -26,6 +29,10 @@ func cmdValidate(args []string) {
 				i++
 				workflowFilter = args[i]
 			}
+		case "--json":
+			jsonOut = true
+		case "--strict":
+			strict = true
 		}
 	}
 
@@ -38,35 +45,125 @@ func cmdValidate(args []string) {
 		}
 	}
 
-	errs := validateProject(projectDir, workflowFilter)
-	if len(errs) > 0 {
-		for _, e := range errs {
-			fmt.Fprintln(os.Stderr, e)
+	issues := validateProjectIssues(projectDir, workflowFilter)
+	os.Exit(reportValidation(os.Stdout, os.Stderr, issues, jsonOut, strict))
+}
+
+// reportValidation prints issues as text or JSON and returns the exit code:
+// 1 when there are errors, or warnings under strict; 0 otherwise.
+func reportValidation(stdout, stderr io.Writer, issues []validateIssue, jsonOut, strict bool) int {
+	var errCount, warnCount int
+	for _, is := range issues {
+		if is.Severity == dsl.SeverityError {
+			errCount++
+		} else {
+			warnCount++
+		}
+	}
+	exit := 0
+	if errCount > 0 || (strict && warnCount > 0) {
+		exit = 1
+	}
+
+	if jsonOut {
+		report := struct {
+			Valid       bool            `json:"valid"`
+			Errors      int             `json:"errors"`
+			Warnings    int             `json:"warnings"`
+			Diagnostics []validateIssue `json:"diagnostics"`
+		}{exit == 0, errCount, warnCount, issues}
+		if report.Diagnostics == nil {
+			report.Diagnostics = []validateIssue{}
 		}
-		os.Exit(1)
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return exit
 	}
 
-	fmt.Println("All configuration valid.")
+	for _, is := range issues {
+		if is.Severity == dsl.SeverityError {
+			fmt.Fprintln(stderr, is)
+		} else {
+			fmt.Fprintf(stderr, "warning: %s\n", is)
+		}
+	}
+	if exit == 0 {
+		fmt.Fprintln(stdout, "All configuration valid.")
+	}
+	return exit
+}
+
+// validateIssue is one problem found by validate. File is relative to
+// .cloche/ (empty for project-level problems); Line and Col are set when the
+// problem can be pinned to a position in the file.
+type validateIssue struct {
+	Severity dsl.Severity
+	File     string
+	Line     int
+	Col      int
+	Message  string
+}
+
+func validateError(file, format string, args ...any) validateIssue {
+	return validateIssue{Severity: dsl.SeverityError, File: file, Message: fmt.Sprintf(format, args...)}
+}
+
+func validateWarning(file, format string, args ...any) validateIssue {
+	return validateIssue{Severity: dsl.SeverityWarning, File: file, Message: fmt.Sprintf(format, args...)}
 }
 
-// validateProject performs all validation checks and returns a list of errors.
+func (is validateIssue) String() string {
+	switch {
+	case is.File == "":
+		return is.Message
+	case is.Line > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", is.File, is.Line, is.Col, is.Message)
+	default:
+		return is.File + ": " + is.Message
+	}
+}
+
+func (is validateIssue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Severity string `json:"severity"`
+		File     string `json:"file,omitempty"`
+		Line     int    `json:"line,omitempty"`
+		Col      int    `json:"col,omitempty"`
+		Message  string `json:"message"`
+	}{is.Severity.String(), is.File, is.Line, is.Col, is.Message})
+}
+
+// validateProject performs all validation checks and returns the errors;
+// warnings are left out.
 func validateProject(projectDir, workflowFilter string) []string {
+	var errs []string
+	for _, is := range validateProjectIssues(projectDir, workflowFilter) {
+		if is.Severity == dsl.SeverityError {
+			errs = append(errs, is.String())
+		}
+	}
+	return errs
+}
+
+// validateProjectIssues performs all validation checks and returns every
+// error and warning found.
+func validateProjectIssues(projectDir, workflowFilter string) []validateIssue {
 	clocheDir := filepath.Join(projectDir, ".cloche")
 
 	info, err := os.Stat(clocheDir)
 	if err != nil || !info.IsDir() {
-		return []string{fmt.Sprintf("%s: .cloche directory not found", projectDir)}
+		return []validateIssue{validateError("", "%s: .cloche directory not found", projectDir)}
 	}
 
-	var errs []string
+	var issues []validateIssue
 
 	// 1. Validate config.toml
-	configErrs := validateConfig(projectDir)
-	errs = append(errs, configErrs...)
+	issues = append(issues, validateConfig(projectDir)...)
 
 	// 2. Parse and validate workflow files
-	workflows, parseErrs := parseAllWorkflowFiles(clocheDir)
-	errs = append(errs, parseErrs...)
+	workflows, parseIssues := parseAllWorkflowFiles(clocheDir)
+	issues = append(issues, parseIssues...)
 
 	// Filter to specific workflow if requested
 	if workflowFilter != "" {
@@ -77,74 +174,102 @@ func validateProject(projectDir, workflowFilter string) []string {
 			}
 		}
 		if len(filtered) == 0 {
-			errs = append(errs, fmt.Sprintf("workflow %q not found", workflowFilter))
-			return errs
+			issues = append(issues, validateError("", "workflow %q not found", workflowFilter))
+			return issues
 		}
 		workflows = filtered
 	}
 
+	// Positioned structure errors and unknown config keys, once per file.
+	files := make(map[string]string)
+	for _, wfi := range workflows {
+		files[wfi.file] = wfi.source
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, d := range dsl.Diagnostics(files[name]) {
+			issues = append(issues, validateIssue{
+				Severity: d.Severity,
+				File:     name,
+				Line:     d.Line,
+				Col:      d.Col,
+				Message:  d.Message,
+			})
+		}
+	}
+
 	// Validate each workflow
 	for _, wfi := range workflows {
-		wfErrs := validateWorkflow(wfi, clocheDir)
-		errs = append(errs, wfErrs...)
+		issues = append(issues, validateWorkflow(wfi, clocheDir)...)
 	}
 
 	// 3. Cross-file consistency (only when not filtering)
 	if workflowFilter == "" {
-		crossErrs := validateCrossFile(projectDir, workflows)
-		errs = append(errs, crossErrs...)
-		containerErrs := validateCrossContainerIDs(workflows)
-		errs = append(errs, containerErrs...)
+		issues = append(issues, validateCrossFile(projectDir, workflows)...)
+		issues = append(issues, validateCrossContainerIDs(workflows)...)
 	}
 
-	return errs
+	return issues
 }
 
 type workflowFileInfo struct {
 	workflow *domain.Workflow
 	file     string // filename relative to .cloche/
+	source   string // contents of file
 }
 
 // parseAllWorkflowFiles parses all .cloche files and returns workflows keyed by name.
-func parseAllWorkflowFiles(clocheDir string) (map[string]*workflowFileInfo, []string) {
+func parseAllWorkflowFiles(clocheDir string) (map[string]*workflowFileInfo, []validateIssue) {
 	entries, err := filepath.Glob(filepath.Join(clocheDir, "*.cloche"))
 	if err != nil {
-		return nil, []string{fmt.Sprintf("%s: %v", clocheDir, err)}
+		return nil, []validateIssue{validateError("", "%s: %v", clocheDir, err)}
 	}
 
 	workflows := make(map[string]*workflowFileInfo)
-	var errs []string
+	var issues []validateIssue
 
 	for _, path := range entries {
+		filename := filepath.Base(path)
 		data, err := os.ReadFile(path)
 		if err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			issues = append(issues, validateError(filename, "%v", err))
 			continue
 		}
 
-		wfs, err := dsl.ParseAll(string(data))
+		wfs, err := dsl.ParseAll(string(data), dsl.WithStrictEnv())
 		if err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			issue := validateError(filename, "%v", err)
+			// Syntax errors also fail a non-strict parse, and Diagnostics
+			// reports exactly that error with its position. Strict env
+			// errors keep the parser's message.
+			if _, perr := dsl.ParseAll(string(data)); perr != nil {
+				if diags := dsl.Diagnostics(string(data)); len(diags) == 1 {
+					issue.Line, issue.Col, issue.Message = diags[0].Line, diags[0].Col, diags[0].Message
+				}
+			}
+			issues = append(issues, issue)
 			continue
 		}
 
-		filename := filepath.Base(path)
 		for name, wf := range wfs {
 			if existing, ok := workflows[name]; ok {
-				errs = append(errs, fmt.Sprintf(
-					"%s: workflow %q already defined in %s",
-					filename, name, existing.file))
+				issues = append(issues, validateError(filename,
+					"workflow %q already defined in %s", name, existing.file))
 				continue
 			}
-			workflows[name] = &workflowFileInfo{workflow: wf, file: filename}
+			workflows[name] = &workflowFileInfo{workflow: wf, file: filename, source: string(data)}
 		}
 	}
 
-	return workflows, errs
+	return workflows, issues
 }
 
 // validateConfig checks that config.toml parses and is valid.
-func validateConfig(projectDir string) []string {
+func validateConfig(projectDir string) []validateIssue {
 	configPath := filepath.Join(projectDir, ".cloche", "config.toml")
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// config.toml is optional — defaults are used
@@ -153,45 +278,39 @@ func validateConfig(projectDir string) []string {
 
 	_, err := config.Load(projectDir)
 	if err != nil {
-		return []string{fmt.Sprintf("config.toml: %v", err)}
+		return []validateIssue{validateError("config.toml", "%v", err)}
 	}
 
 	return nil
 }
 
-// validateWorkflow validates a single workflow's structure.
-func validateWorkflow(wfi *workflowFileInfo, clocheDir string) []string {
+// validateWorkflow validates a single workflow's structure beyond what
+// dsl.Diagnostics reports (entry step, wiring, orphans, config keys).
+func validateWorkflow(wfi *workflowFileInfo, clocheDir string) []validateIssue {
 	wf := wfi.workflow
-	var errs []string
-
-	// Run domain validation (entry step, wiring, orphans, output mappings)
-	if err := wf.Validate(); err != nil {
-		errs = append(errs, fmt.Sprintf("%s: %v", wfi.file, err))
-	}
+	var issues []validateIssue
 
 	// Location validation
 	if err := wf.ValidateLocation(); err != nil {
-		errs = append(errs, fmt.Sprintf("%s: %v", wfi.file, err))
+		issues = append(issues, validateError(wfi.file, "%v", err))
 	}
 
-	// Config key warnings (treat as errors for validate command)
-	for _, w := range wf.ValidateConfig() {
-		errs = append(errs, fmt.Sprintf("%s: %v", wfi.file, w))
+	// Agent retry loops need a give-up path
+	for _, w := range wf.ValidateLoops() {
+		issues = append(issues, validateWarning(wfi.file, "%s", w))
 	}
 
 	// Terminal coverage: every path must eventually reach done or abort
-	termErrs := validateTerminalCoverage(wf, wfi.file)
-	errs = append(errs, termErrs...)
+	issues = append(issues, validateTerminalCoverage(wf, wfi.file)...)
 
 	// Prompt and script file references
-	refErrs := validateFileReferences(wf, wfi.file, clocheDir)
-	errs = append(errs, refErrs...)
+	issues = append(issues, validateFileReferences(wf, wfi.file, clocheDir)...)
 
-	return errs
+	return issues
 }
 
 // validateTerminalCoverage checks that every path through the workflow reaches done or abort.
-func validateTerminalCoverage(wf *domain.Workflow, filename string) []string {
+func validateTerminalCoverage(wf *domain.Workflow, filename string) []validateIssue {
 	if wf.EntryStep == "" {
 		return nil // already reported by Validate()
 	}
@@ -202,6 +321,9 @@ func validateTerminalCoverage(wf *domain.Workflow, filename string) []string {
 	for _, wire := range wf.Wiring {
 		sr := stepResult{wire.From, wire.Result}
 		adj[sr] = append(adj[sr], wire.To)
+		if wire.Cleanup != "" {
+			adj[sr] = append(adj[sr], wire.Cleanup)
+		}
 	}
 	for _, c := range wf.Collects {
 		for _, cond := range c.Conditions {
@@ -212,7 +334,7 @@ func validateTerminalCoverage(wf *domain.Workflow, filename string) []string {
 
 	// DFS from entry step. A step is "terminal-safe" if all its results lead
 	// (directly or transitively) to done/abort. Track visited to handle cycles.
-	var errs []string
+	var issues []validateIssue
 	// Use memoization: true = reaches terminal, false = doesn't
 	memo := make(map[string]*bool)
 
@@ -278,29 +400,38 @@ func validateTerminalCoverage(wf *domain.Workflow, filename string) []string {
 				}
 			}
 			if !anyTerminal {
-				errs = append(errs, fmt.Sprintf(
-					"%s: workflow %q: step %q result %q does not reach a terminal (done/abort)",
-					filename, wf.Name, name, result))
+				issues = append(issues, validateError(filename,
+					"workflow %q: step %q result %q does not reach a terminal (done/abort)",
+					wf.Name, name, result))
 			}
 		}
 	}
 
-	return errs
+	return issues
 }
 
-// validateFileReferences checks that prompt file() and script run references exist.
-func validateFileReferences(wf *domain.Workflow, filename, clocheDir string) []string {
-	var errs []string
+// validateFileReferences checks that prompt file(), context_files, and script
+// run references exist.
+func validateFileReferences(wf *domain.Workflow, filename, clocheDir string) []validateIssue {
+	var issues []validateIssue
+
+	for _, ref := range wf.ContextFiles {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(clocheDir), ref)); os.IsNotExist(err) {
+			issues = append(issues, validateError(filename,
+				"workflow %q: context_files references missing file %q",
+				wf.Name, ref))
+		}
+	}
 
 	for _, step := range wf.Steps {
 		// Check prompt file references: file("prompts/foo.md")
-		if promptVal, ok := step.Config["prompt"]; ok {
-			if ref := extractFileRef(promptVal); ref != "" {
+		for _, key := range []string{"system", "prompt"} {
+			if ref := extractFileRef(step.Config[key]); ref != "" {
 				path := filepath.Join(filepath.Dir(clocheDir), ref)
 				if _, err := os.Stat(path); os.IsNotExist(err) {
-					errs = append(errs, fmt.Sprintf(
-						"%s: workflow %q: step %q references missing file %q",
-						filename, wf.Name, step.Name, ref))
+					issues = append(issues, validateError(filename,
+						"workflow %q: step %q references missing file %q",
+						wf.Name, step.Name, ref))
 				}
 			}
 		}
@@ -311,15 +442,15 @@ func validateFileReferences(wf *domain.Workflow, filename, clocheDir string) []s
 			if scriptRef != "" {
 				path := filepath.Join(filepath.Dir(clocheDir), scriptRef)
 				if _, err := os.Stat(path); os.IsNotExist(err) {
-					errs = append(errs, fmt.Sprintf(
-						"%s: workflow %q: step %q references missing script %q",
-						filename, wf.Name, step.Name, scriptRef))
+					issues = append(issues, validateError(filename,
+						"workflow %q: step %q references missing script %q",
+						wf.Name, step.Name, scriptRef))
 				}
 			}
 		}
 	}
 
-	return errs
+	return issues
 }
 
 // extractFileRef extracts the path from file("path") syntax.
@@ -350,7 +481,7 @@ func extractScriptRef(val string) string {
 //	(a) all blocks share the exact same config
 //	(b) one has full config and the others only declare id (or use the implicit default)
 //	(c) all only declare id (no container config beyond the id field)
-func validateCrossContainerIDs(workflows map[string]*workflowFileInfo) []string {
+func validateCrossContainerIDs(workflows map[string]*workflowFileInfo) []validateIssue {
 	type wfContainerInfo struct {
 		wfName    string
 		file      string
@@ -393,7 +524,7 @@ func validateCrossContainerIDs(workflows map[string]*workflowFileInfo) []string
 	}
 	sort.Strings(containerIDs)
 
-	var errs []string
+	var issues []validateIssue
 	for _, containerID := range containerIDs {
 		infos := groups[containerID]
 		if len(infos) <= 1 {
@@ -417,15 +548,21 @@ func validateCrossContainerIDs(workflows map[string]*workflowFileInfo) []string
 		first := withConfig[0]
 		for _, other := range withConfig[1:] {
 			if !containerConfigsEqual(first.config, other.config) {
-				errs = append(errs, fmt.Sprintf(
-					"%s: workflow %q: container id %q: config conflicts with workflow %q in %s",
-					other.file, other.wfName, containerID, first.wfName, first.file))
+				issues = append(issues, validateError(other.file,
+					"workflow %q: container id %q: config conflicts with workflow %q in %s",
+					other.wfName, containerID, first.wfName, first.file))
 			}
 		}
 	}
 
-	sort.Strings(errs)
-	return errs
+	sortValidateIssues(issues)
+	return issues
+}
+
+// sortValidateIssues orders issues by their printed form for deterministic
+// output.
+func sortValidateIssues(issues []validateIssue) {
+	sort.Slice(issues, func(i, j int) bool { return issues[i].String() < issues[j].String() })
 }
 
 func containerConfigsEqual(a, b map[string]string) bool {
@@ -441,14 +578,14 @@ func containerConfigsEqual(a, b map[string]string) bool {
 }
 
 // validateCrossFile checks that workflows referenced in config exist as files and vice versa.
-func validateCrossFile(projectDir string, workflows map[string]*workflowFileInfo) []string {
+func validateCrossFile(projectDir string, workflows map[string]*workflowFileInfo) []validateIssue {
 	configPath := filepath.Join(projectDir, ".cloche", "config.toml")
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return nil
 	}
 
 	// Check that workflow steps (workflow_name references) point to existing workflows
-	var errs []string
+	var issues []validateIssue
 	workflowNames := make(map[string]bool)
 	for name := range workflows {
 		workflowNames[name] = true
@@ -459,16 +596,16 @@ func validateCrossFile(projectDir string, workflows map[string]*workflowFileInfo
 			if step.Type == domain.StepTypeWorkflow {
 				refName := step.Config["workflow_name"]
 				if refName != "" && !workflowNames[refName] {
-					errs = append(errs, fmt.Sprintf(
-						"%s: workflow %q: step %q references undefined workflow %q",
-						wfi.file, wfi.workflow.Name, step.Name, refName))
+					issues = append(issues, validateError(wfi.file,
+						"workflow %q: step %q references undefined workflow %q",
+						wfi.workflow.Name, step.Name, refName))
 				}
 			}
 		}
 	}
 
 	// Sort errors for deterministic output
-	sort.Strings(errs)
+	sortValidateIssues(issues)
 
-	return errs
+	return issues
 }
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/cloche-dev/cloche/internal/dsl"
 )
 
 func setupValidProject(t *testing.T) string {
@@ -148,6 +152,60 @@ func TestValidateProject_OrphanStep(t *testing.T) {
 	}
 }
 
+func TestValidateProject_ExplicitStart(t *testing.T) {
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	os.MkdirAll(clocheDir, 0755)
+
+	// "report" is declared first but only reachable from "build"; without the
+	// explicit start, "build" would be reported as an orphan.
+	os.WriteFile(filepath.Join(clocheDir, "test.cloche"), []byte(`workflow test {
+  start = build
+  step report {
+    run = "echo report"
+    results = [success]
+  }
+  step build {
+    run = "make"
+    results = [success, fail]
+  }
+  build:success -> report
+  build:fail -> abort
+  report:success -> done
+}`), 0644)
+
+	errs := validateProject(dir, "")
+	if len(errs) > 0 {
+		t.Errorf("expected no errors, got: %v", errs)
+	}
+}
+
+func TestValidateProject_UnknownStart(t *testing.T) {
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	os.MkdirAll(clocheDir, 0755)
+
+	os.WriteFile(filepath.Join(clocheDir, "test.cloche"), []byte(`workflow test {
+  start = missing
+  step a {
+    run = "echo a"
+    results = [success]
+  }
+  a:success -> done
+}`), 0644)
+
+	errs := validateProject(dir, "")
+	found := false
+	for _, e := range errs {
+		if strings.Contains(e, `start step "missing" not found`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected unknown start step error, got: %v", errs)
+	}
+}
+
 func TestValidateProject_MissingPromptFile(t *testing.T) {
 	dir := t.TempDir()
 	clocheDir := filepath.Join(dir, ".cloche")
@@ -174,6 +232,33 @@ func TestValidateProject_MissingPromptFile(t *testing.T) {
 	}
 }
 
+func TestValidateProject_MissingContextFile(t *testing.T) {
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	os.MkdirAll(clocheDir, 0755)
+
+	os.WriteFile(filepath.Join(clocheDir, "test.cloche"), []byte(`workflow test {
+  context_files = ["docs/arch.md"]
+  step impl {
+    prompt = "implement it"
+    results = [success, fail]
+  }
+  impl:success -> done
+  impl:fail -> abort
+}`), 0644)
+
+	errs := validateProject(dir, "")
+	found := false
+	for _, e := range errs {
+		if strings.Contains(e, "context_files") && strings.Contains(e, "docs/arch.md") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected missing context file error, got: %v", errs)
+	}
+}
+
 func TestValidateProject_MissingScript(t *testing.T) {
 	dir := t.TempDir()
 	clocheDir := filepath.Join(dir, ".cloche")
@@ -612,3 +697,161 @@ func TestValidateProject_ContainerID_DefaultConflict(t *testing.T) {
 		t.Errorf("expected container id conflict error for default id, got: %v", errs)
 	}
 }
+
+// validateJSON runs validation on dir and decodes the JSON report.
+func validateJSON(t *testing.T, dir string, strict bool) (int, map[string]any) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := reportValidation(&stdout, &stderr, validateProjectIssues(dir, ""), true, strict)
+	var report map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout.String(), err)
+	}
+	return code, report
+}
+
+func TestReportValidation_JSONValid(t *testing.T) {
+	dir := setupValidProject(t)
+
+	code, report := validateJSON(t, dir, true)
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if report["valid"] != true || report["errors"] != 0.0 || report["warnings"] != 0.0 {
+		t.Errorf("unexpected report: %v", report)
+	}
+	if diags, ok := report["diagnostics"].([]any); !ok || len(diags) != 0 {
+		t.Errorf("diagnostics = %v, want empty array", report["diagnostics"])
+	}
+}
+
+func TestReportValidation_JSONWarningOnly(t *testing.T) {
+	dir := setupValidProject(t)
+	os.WriteFile(filepath.Join(dir, ".cloche", "develop.cloche"), []byte(`workflow develop {
+  step test {
+    run = "go test ./..."
+    retries = 3
+    results = [success, fail]
+  }
+  test:success -> done
+  test:fail -> abort
+}`), 0644)
+
+	code, report := validateJSON(t, dir, false)
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0 for warnings without --strict", code)
+	}
+	if report["valid"] != true || report["errors"] != 0.0 || report["warnings"] != 1.0 {
+		t.Errorf("unexpected report: %v", report)
+	}
+	diags := report["diagnostics"].([]any)
+	if len(diags) != 1 {
+		t.Fatalf("diagnostics = %v, want 1", diags)
+	}
+	want := map[string]any{
+		"severity": "warning",
+		"file":     "develop.cloche",
+		"line":     4.0,
+		"col":      5.0,
+		"message":  `step "test" has unrecognized config key "retries"`,
+	}
+	for k, v := range want {
+		if diags[0].(map[string]any)[k] != v {
+			t.Errorf("diagnostic %s = %v, want %v", k, diags[0].(map[string]any)[k], v)
+		}
+	}
+
+	code, report = validateJSON(t, dir, true)
+	if code != 1 || report["valid"] != false {
+		t.Errorf("--strict: exit code = %d, valid = %v; want 1, false", code, report["valid"])
+	}
+}
+
+func TestReportValidation_StrictKeepsOldDefault(t *testing.T) {
+	// Unrecognized config keys and retry loops with no way out used to
+	// fail validate. They are warnings now; --strict still fails on them.
+	dir := setupValidProject(t)
+	os.WriteFile(filepath.Join(dir, ".cloche", "develop.cloche"), []byte(`workflow develop {
+  step implement {
+    prompt = file(".cloche/prompts/implement.md")
+    retries = 3
+    results = [success, fail]
+  }
+  step test {
+    run = "go test ./..."
+    results = [success, fail]
+  }
+  implement:success -> test
+  implement:fail -> implement
+  test:success -> implement
+  test:fail -> implement
+}`), 0644)
+
+	issues := validateProjectIssues(dir, "")
+	if len(issues) != 2 {
+		t.Fatalf("issues = %v, want the unknown key and the loop", issues)
+	}
+	for _, is := range issues {
+		if is.Severity != dsl.SeverityWarning {
+			t.Errorf("issue %q severity = %v, want warning", is, is.Severity)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := reportValidation(&stdout, &stderr, issues, false, false); code != 0 {
+		t.Errorf("exit code = %d, want 0 without --strict", code)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	if code := reportValidation(&stdout, &stderr, issues, false, true); code != 1 {
+		t.Errorf("--strict: exit code = %d, want 1", code)
+	}
+	for _, want := range []string{`unrecognized config key "retries"`, "no way out"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("--strict: stderr %q missing %q", stderr.String(), want)
+		}
+	}
+	if strings.Contains(stdout.String(), "All configuration valid.") {
+		t.Errorf("--strict: stdout = %q", stdout.String())
+	}
+}
+
+func TestReportValidation_JSONError(t *testing.T) {
+	dir := setupValidProject(t)
+	os.WriteFile(filepath.Join(dir, ".cloche", "develop.cloche"), []byte(`workflow develop {
+  step test {
+    run = "go test ./..."
+    results = [success, fail]
+  }
+  test:success -> done
+}`), 0644)
+
+	code, report := validateJSON(t, dir, false)
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if report["valid"] != false || report["errors"] != 1.0 {
+		t.Errorf("unexpected report: %v", report)
+	}
+	diag := report["diagnostics"].([]any)[0].(map[string]any)
+	if diag["severity"] != "error" || diag["file"] != "develop.cloche" || diag["line"] != 1.0 ||
+		!strings.Contains(diag["message"].(string), "not wired") {
+		t.Errorf("unexpected diagnostic: %v", diag)
+	}
+}
+
+func TestReportValidation_TextWarnings(t *testing.T) {
+	issues := []validateIssue{
+		validateWarning("develop.cloche", "workflow %q: loop", "develop"),
+	}
+	var stdout, stderr bytes.Buffer
+	if code := reportValidation(&stdout, &stderr, issues, false, false); code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if got := stderr.String(); got != "warning: develop.cloche: workflow \"develop\": loop\n" {
+		t.Errorf("stderr = %q", got)
+	}
+	if !strings.Contains(stdout.String(), "All configuration valid.") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Defaults for commands that block until the daemon reports a result
+// (cloche poll, cloche loop once). Override with --poll-interval and
+// --poll-timeout, or CLOCHE_POLL_INTERVAL and CLOCHE_POLL_TIMEOUT.
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultPollTimeout  = 0 // wait forever
+)
+
+// errPollTimeout is returned by pollSettings.wait when the deadline passes
+// before the check reports done.
+var errPollTimeout = errors.New("gave up waiting")
+
+// pollClock abstracts time so the wait loop can be tested without sleeping.
+type pollClock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// pollSettings controls how often a blocking command polls the daemon and
+// how long it waits overall.
+type pollSettings struct {
+	Interval time.Duration // delay between checks
+	Timeout  time.Duration // overall deadline; 0 waits forever
+	clock    pollClock
+}
+
+// pollSettingsFromEnv returns the defaults, overridden by CLOCHE_POLL_INTERVAL
+// and CLOCHE_POLL_TIMEOUT when set.
+func pollSettingsFromEnv() (pollSettings, error) {
+	p := pollSettings{Interval: defaultPollInterval, Timeout: defaultPollTimeout}
+	if v := os.Getenv("CLOCHE_POLL_INTERVAL"); v != "" {
+		if err := p.set("CLOCHE_POLL_INTERVAL", v); err != nil {
+			return p, err
+		}
+	}
+	if v := os.Getenv("CLOCHE_POLL_TIMEOUT"); v != "" {
+		if err := p.set("CLOCHE_POLL_TIMEOUT", v); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// parseFlag consumes --poll-interval or --poll-timeout at args[i]. It returns
+// the number of arguments consumed (0 if args[i] is not a poll flag).
+func (p *pollSettings) parseFlag(args []string, i int) (int, error) {
+	name := args[i]
+	if name != "--poll-interval" && name != "--poll-timeout" {
+		return 0, nil
+	}
+	if i+1 >= len(args) {
+		return 0, fmt.Errorf("%s requires a duration (e.g. 5s)", name)
+	}
+	return 2, p.set(name, args[i+1])
+}
+
+func (p *pollSettings) set(name, value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s: invalid duration %q", name, value)
+	}
+	switch name {
+	case "--poll-interval", "CLOCHE_POLL_INTERVAL":
+		if d <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", name, value)
+		}
+		p.Interval = d
+	default:
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", name, value)
+		}
+		p.Timeout = d
+	}
+	return nil
+}
+
+// wait calls check until it reports done, sleeping Interval between calls.
+// It returns check's error, or errPollTimeout once Timeout has elapsed.
+func (p pollSettings) wait(check func() (done bool, err error)) error {
+	clock := p.clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	start := clock.Now()
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		if p.Timeout > 0 && clock.Now().Sub(start) >= p.Timeout {
+			return fmt.Errorf("%w after %s", errPollTimeout, p.Timeout)
+		}
+		clock.Sleep(p.Interval)
+	}
+}
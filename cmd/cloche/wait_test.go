@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advances only when Sleep is called.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+// testPollSettings returns default poll settings on a fake clock so polling
+// tests finish without real sleeps.
+func testPollSettings() pollSettings {
+	return pollSettings{Interval: defaultPollInterval, clock: &fakeClock{now: time.Unix(0, 0)}}
+}
+
+func TestPollSettingsWait_StopsWhenDone(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	p := pollSettings{Interval: 5 * time.Second, clock: clock}
+
+	calls := 0
+	err := p.wait(func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second}, clock.sleeps)
+}
+
+func TestPollSettingsWait_GivesUpAtTimeout(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	p := pollSettings{Interval: time.Second, Timeout: 3 * time.Second, clock: clock}
+
+	calls := 0
+	err := p.wait(func() (bool, error) {
+		calls++
+		return false, nil
+	})
+	require.ErrorIs(t, err, errPollTimeout)
+	assert.Contains(t, err.Error(), "after 3s")
+	assert.Equal(t, 4, calls, "checks at 0s, 1s, 2s and 3s")
+}
+
+func TestPollSettingsWait_ReturnsCheckError(t *testing.T) {
+	p := pollSettings{Interval: time.Second, clock: &fakeClock{}}
+	boom := errors.New("boom")
+	err := p.wait(func() (bool, error) { return false, boom })
+	assert.Equal(t, boom, err)
+}
+
+func TestPollSettingsFromEnv(t *testing.T) {
+	t.Setenv("CLOCHE_POLL_INTERVAL", "")
+	t.Setenv("CLOCHE_POLL_TIMEOUT", "")
+	p, err := pollSettingsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, defaultPollInterval, p.Interval)
+	assert.Zero(t, p.Timeout)
+
+	t.Setenv("CLOCHE_POLL_INTERVAL", "250ms")
+	t.Setenv("CLOCHE_POLL_TIMEOUT", "10m")
+	p, err = pollSettingsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, p.Interval)
+	assert.Equal(t, 10*time.Minute, p.Timeout)
+
+	t.Setenv("CLOCHE_POLL_INTERVAL", "0s")
+	_, err = pollSettingsFromEnv()
+	assert.ErrorContains(t, err, "CLOCHE_POLL_INTERVAL must be positive")
+}
+
+func TestPollSettingsParseFlag(t *testing.T) {
+	p := pollSettings{Interval: defaultPollInterval}
+	args := []string{"a133", "--poll-interval", "1s", "--poll-timeout", "30s"}
+
+	n, err := p.parseFlag(args, 0)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = p.parseFlag(args, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = p.parseFlag(args, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, time.Second, p.Interval)
+	assert.Equal(t, 30*time.Second, p.Timeout)
+
+	_, err = p.parseFlag([]string{"--poll-timeout"}, 0)
+	assert.ErrorContains(t, err, "requires a duration")
+	_, err = p.parseFlag([]string{"--poll-timeout", "soon"}, 0)
+	assert.ErrorContains(t, err, `invalid duration "soon"`)
+}
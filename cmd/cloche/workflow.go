@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,6 +14,7 @@ import (
 
 func cmdWorkflow(args []string) {
 	var projectDir, workflowName string
+	format := "text"
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--project", "-p":
@@ -20,6 +22,11 @@ func cmdWorkflow(args []string) {
 				i++
 				projectDir = args[i]
 			}
+		case "--format":
+			if i+1 < len(args) {
+				i++
+				format = args[i]
+			}
 		default:
 			if !strings.HasPrefix(args[i], "-") && workflowName == "" {
 				workflowName = args[i]
@@ -36,10 +43,15 @@ func cmdWorkflow(args []string) {
 		}
 	}
 
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "error: unknown format %q (want text or json)\n", format)
+		os.Exit(1)
+	}
+
 	if workflowName == "" {
 		listWorkflows(projectDir)
 	} else {
-		showWorkflow(projectDir, workflowName)
+		showWorkflow(projectDir, workflowName, format)
 	}
 }
 
@@ -140,15 +152,114 @@ func loadWorkflow(projectDir, name string) (*domain.Workflow, error) {
 	return nil, fmt.Errorf("workflow %q not found", name)
 }
 
-func showWorkflow(projectDir, name string) {
+func showWorkflow(projectDir, name, format string) {
 	wf, err := loadWorkflow(projectDir, name)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+	if format == "json" {
+		data, err := renderWorkflowJSON(wf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
 	fmt.Print(renderWorkflowGraph(wf, isTTY()))
 }
 
+type workflowGraphJSON struct {
+	Name      string                `json:"name"`
+	Location  string                `json:"location"`
+	EntryStep string                `json:"entry_step"`
+	Steps     []workflowStepJSON    `json:"steps"`
+	Wires     []workflowWireJSON    `json:"wires"`
+	Collects  []workflowCollectJSON `json:"collects,omitempty"`
+}
+
+type workflowStepJSON struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Results []string `json:"results"`
+}
+
+type workflowWireJSON struct {
+	From     string `json:"from"`
+	Result   string `json:"result"`
+	To       string `json:"to"`
+	Cleanup  string `json:"cleanup,omitempty"`
+	Implicit bool   `json:"implicit,omitempty"`
+}
+
+type workflowCollectJSON struct {
+	Mode       string                  `json:"mode"`
+	Conditions []workflowConditionJSON `json:"conditions"`
+	To         string                  `json:"to"`
+}
+
+type workflowConditionJSON struct {
+	Step   string `json:"step"`
+	Result string `json:"result"`
+}
+
+// renderWorkflowJSON renders the workflow's steps and wiring as JSON. Steps
+// are listed in the same BFS order as the text graph, followed by any steps
+// it does not reach, sorted by name.
+func renderWorkflowJSON(w *domain.Workflow) ([]byte, error) {
+	g := workflowGraphJSON{
+		Name:      w.Name,
+		Location:  string(w.Location),
+		EntryStep: w.EntryStep,
+		Steps:     []workflowStepJSON{},
+		Wires:     []workflowWireJSON{},
+	}
+
+	order := bfsOrder(w)
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		seen[name] = true
+	}
+	var rest []string
+	for name := range w.Steps {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range append(order, rest...) {
+		step, ok := w.Steps[name]
+		if !ok {
+			continue
+		}
+		results := step.Results
+		if results == nil {
+			results = []string{}
+		}
+		g.Steps = append(g.Steps, workflowStepJSON{Name: name, Type: string(step.Type), Results: results})
+	}
+
+	for _, wire := range w.Wiring {
+		g.Wires = append(g.Wires, workflowWireJSON{
+			From:     wire.From,
+			Result:   wire.Result,
+			To:       wire.To,
+			Cleanup:  wire.Cleanup,
+			Implicit: wire.Implicit,
+		})
+	}
+	for _, c := range w.Collects {
+		jc := workflowCollectJSON{Mode: string(c.Mode), To: c.To}
+		for _, cond := range c.Conditions {
+			jc.Conditions = append(jc.Conditions, workflowConditionJSON{Step: cond.Step, Result: cond.Result})
+		}
+		g.Collects = append(g.Collects, jc)
+	}
+
+	return json.MarshalIndent(g, "", "  ")
+}
+
 // ANSI color codes for wire colorization.
 const (
 	colorReset   = "\033[0m"
@@ -237,6 +348,9 @@ func renderStepBox(buf *strings.Builder, step *domain.Step, isEntry bool, nameWi
 	border := strings.Repeat("─", nameWidth+2)
 
 	suffix := fmt.Sprintf(" %s", step.Type)
+	if step.ResultsInferred {
+		suffix += " (results inferred)"
+	}
 	if isEntry {
 		suffix += "  ◀ entry"
 	}
@@ -364,6 +478,10 @@ func bfsOrder(w *domain.Workflow) []string {
 			if wire.From != current {
 				continue
 			}
+			if wire.Cleanup != "" && !visited[wire.Cleanup] {
+				visited[wire.Cleanup] = true
+				queue = append(queue, wire.Cleanup)
+			}
 			if wire.To == domain.StepDone || wire.To == domain.StepAbort {
 				continue
 			}
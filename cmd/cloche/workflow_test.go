@@ -626,3 +626,110 @@ func TestRenderWorkflowGraph_CollectBlock(t *testing.T) {
 		t.Errorf("expected collect block in output:\n%s", output)
 	}
 }
+
+func TestRenderWorkflowGraph_InferredResults(t *testing.T) {
+	wf := &domain.Workflow{
+		Name:      "notify",
+		Location:  domain.LocationContainer,
+		EntryStep: "announce",
+		Steps: map[string]*domain.Step{
+			"announce": {Name: "announce", Type: domain.StepTypeScript, Results: []string{"success", "fail"}, ResultsInferred: true},
+		},
+		Wiring: []domain.Wire{
+			{From: "announce", Result: "success", To: "done"},
+			{From: "announce", Result: "fail", To: "done", Implicit: true},
+		},
+	}
+
+	output := renderWorkflowGraph(wf, false)
+	if !strings.Contains(output, "script (results inferred)") {
+		t.Errorf("expected inferred results marker, got:\n%s", output)
+	}
+}
+
+func TestRenderWorkflowJSON(t *testing.T) {
+	wf := &domain.Workflow{
+		Name:      "test-wf",
+		Location:  domain.LocationContainer,
+		EntryStep: "build",
+		Steps: map[string]*domain.Step{
+			"build":  {Name: "build", Type: domain.StepTypeScript, Results: []string{"success", "fail"}},
+			"lint":   {Name: "lint", Type: domain.StepTypeScript, Results: []string{"success"}},
+			"deploy": {Name: "deploy", Type: domain.StepTypeAgent, Results: []string{"success"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "build", Result: "success", To: "deploy"},
+			{From: "build", Result: "fail", To: "abort"},
+		},
+		Collects: []domain.Collect{{
+			Mode:       domain.CollectAll,
+			Conditions: []domain.WireCondition{{Step: "deploy", Result: "success"}, {Step: "lint", Result: "success"}},
+			To:         "done",
+		}},
+	}
+
+	data, err := renderWorkflowJSON(wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "name": "test-wf",
+  "location": "container",
+  "entry_step": "build",
+  "steps": [
+    {
+      "name": "build",
+      "type": "script",
+      "results": [
+        "success",
+        "fail"
+      ]
+    },
+    {
+      "name": "deploy",
+      "type": "agent",
+      "results": [
+        "success"
+      ]
+    },
+    {
+      "name": "lint",
+      "type": "script",
+      "results": [
+        "success"
+      ]
+    }
+  ],
+  "wires": [
+    {
+      "from": "build",
+      "result": "success",
+      "to": "deploy"
+    },
+    {
+      "from": "build",
+      "result": "fail",
+      "to": "abort"
+    }
+  ],
+  "collects": [
+    {
+      "mode": "all",
+      "conditions": [
+        {
+          "step": "deploy",
+          "result": "success"
+        },
+        {
+          "step": "lint",
+          "result": "success"
+        }
+      ],
+      "to": "done"
+    }
+  ]
+}`
+	if string(data) != want {
+		t.Errorf("renderWorkflowJSON =\n%s\nwant\n%s", data, want)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/cloche-dev/cloche/internal/adapters/sqlite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// defaultLivenessInterval is how often the liveness file is rewritten when
+// [daemon] liveness_interval_seconds is unset.
+const defaultLivenessInterval = 15 * time.Second
+
+// livenessProbe checks one part of the daemon, returning an error when it
+// does not respond.
+type livenessProbe struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// startLiveness runs the liveness loop for path in the background, probing
+// the daemon's gRPC server at addr and the store. The returned func stops it.
+func startLiveness(path string, interval time.Duration, addr string, store *sqlite.Store) (stop func(), err error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	client := pb.NewClocheServiceClient(conn)
+	probes := []livenessProbe{
+		{name: "grpc", check: func(ctx context.Context) error {
+			_, err := client.GetVersion(ctx, &pb.GetVersionRequest{})
+			return err
+		}},
+		{name: "db", check: store.Ping},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runLiveness(ctx, path, interval, probes)
+	}()
+	return func() {
+		cancel()
+		<-done
+		conn.Close()
+	}, nil
+}
+
+// runLiveness rewrites path with the current time every interval, as long as
+// every probe succeeds within that interval, until ctx is cancelled. A
+// supervisor that sees the file go stale can restart the daemon. A failing
+// probe skips the rewrite, and one that never returns stops the loop
+// altogether; either way the file goes stale.
+func runLiveness(ctx context.Context, path string, interval time.Duration, probes []livenessProbe) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	healthy := true
+	for {
+		err := checkLiveness(ctx, interval, probes)
+		if err == nil {
+			err = writeLivenessFile(path, time.Now())
+		}
+		if err != nil && healthy {
+			fmt.Fprintf(os.Stderr, "liveness: not updating %s: %v\n", path, err)
+		} else if err == nil && !healthy {
+			fmt.Fprintf(os.Stderr, "liveness: recovered, updating %s\n", path)
+		}
+		healthy = err == nil
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkLiveness runs each probe with a deadline of timeout and returns the
+// first failure.
+func checkLiveness(ctx context.Context, timeout time.Duration, probes []livenessProbe) error {
+	for _, p := range probes {
+		pctx, cancel := context.WithTimeout(ctx, timeout)
+		err := p.check(pctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s probe: %w", p.name, err)
+		}
+	}
+	return nil
+}
+
+func writeLivenessFile(path string, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(now.UTC().Format(time.RFC3339Nano)+"\n"), 0644)
+}
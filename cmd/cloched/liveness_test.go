@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloche-dev/cloche/internal/adapters/sqlite"
+	"github.com/cloche-dev/cloche/internal/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLiveness_StopsWhileDatabaseStalls(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	path := filepath.Join(t.TempDir(), "run", "cloched.alive")
+	readLiveness := func() string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}
+	// waitForUpdate reports whether the file changes from last within d.
+	waitForUpdate := func(last string, d time.Duration) bool {
+		deadline := time.Now().Add(d)
+		for time.Now().Before(deadline) {
+			if cur := readLiveness(); cur != "" && cur != last {
+				return true
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runLiveness(ctx, path, 20*time.Millisecond, []livenessProbe{{name: "db", check: store.Ping}})
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	require.True(t, waitForUpdate("", time.Second), "liveness file should be written while the database responds")
+
+	// Stall the database: a transaction holds the store's only connection.
+	release := make(chan struct{})
+	stalled := make(chan struct{})
+	txDone := make(chan error, 1)
+	go func() {
+		txDone <- store.Transaction(context.Background(), func(ports.RunStore) error {
+			close(stalled)
+			<-release
+			return nil
+		})
+	}()
+	<-stalled
+
+	// Let any write from a probe that finished before the stall land first.
+	time.Sleep(60 * time.Millisecond)
+	stale := readLiveness()
+	assert.False(t, waitForUpdate(stale, 200*time.Millisecond), "liveness file should not be updated while the database stalls")
+
+	close(release)
+	require.NoError(t, <-txDone)
+	assert.True(t, waitForUpdate(stale, time.Second), "liveness file should be updated again once the database recovers")
+}
+
+func TestCheckLiveness_ReportsFailingProbe(t *testing.T) {
+	probes := []livenessProbe{
+		{name: "grpc", check: func(context.Context) error { return nil }},
+		{name: "db", check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}
+	err := checkLiveness(context.Background(), 10*time.Millisecond, probes)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "db probe")
+}
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	pb "github.com/cloche-dev/cloche/api/clochepb"
 	adaptgrpc "github.com/cloche-dev/cloche/internal/adapters/grpc"
@@ -22,6 +23,7 @@ import (
 	"github.com/cloche-dev/cloche/internal/adapters/sqlite"
 	"github.com/cloche-dev/cloche/internal/adapters/web"
 	"github.com/cloche-dev/cloche/internal/config"
+	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/evolution"
 	"github.com/cloche-dev/cloche/internal/logstream"
 	"github.com/cloche-dev/cloche/internal/ports"
@@ -61,6 +63,7 @@ func main() {
 		os.Exit(1)
 	}
 	defer store.Close()
+	store.SetMaxStoredOutput(globalCfg.Output.StoreChars)
 
 	// Sweep stale runs from a previous daemon crash (pending or running with no live goroutine).
 	if n, err := store.FailStaleRuns(context.Background()); err != nil {
@@ -92,6 +95,7 @@ func main() {
 	srv.SetActivityStore(store)
 	srv.SetLogBroadcaster(broadcaster)
 	srv.SetContainerPool(docker.NewContainerPool(runtime))
+	srv.SetDefaultNetwork(globalCfg.Daemon.DefaultNetwork)
 
 	// Set up evolution trigger
 	evoTrigger := initEvolution(globalCfg, store, store)
@@ -158,12 +162,34 @@ func main() {
 	}
 
 	fmt.Fprintf(os.Stderr, "cloched listening on %s\n", listenAddr)
+	// Let clients without CLOCHE_ADDR find this daemon. The listener's address
+	// is recorded rather than listenAddr so a ":0" port resolves.
+	boundAddr := lis.Addr().String()
+	if err := config.WriteDaemonAddr(boundAddr); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write %s: %v\n", config.DaemonAddrPath(), err)
+	}
+	defer config.RemoveDaemonAddr(boundAddr)
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
 			fmt.Fprintf(os.Stderr, "server error: %v\n", err)
 		}
 	}()
 
+	// Keep the liveness file fresh for process supervisors while the daemon
+	// answers on its gRPC address and the database responds.
+	if livenessFile := envOrConfig("CLOCHE_LIVENESS_FILE", globalCfg.Daemon.LivenessFile, ""); livenessFile != "" {
+		interval := defaultLivenessInterval
+		if n := globalCfg.Daemon.LivenessIntervalSeconds; n > 0 {
+			interval = time.Duration(n) * time.Second
+		}
+		stopLiveness, err := startLiveness(livenessFile, interval, boundAddr, store)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: liveness file disabled: %v\n", err)
+		} else {
+			defer stopLiveness()
+		}
+	}
+
 	// Auto-execute main workflow for active projects (after gRPC is serving).
 	autoRunActiveProjects(store, srv)
 
@@ -220,33 +246,61 @@ func initEvolution(globalCfg *config.Config, evoStore ports.EvolutionStore, capS
 		return nil
 	}
 
+	// newOrchestrator builds a pass for one project/workflow using that
+	// project's config, falling back to the daemon's.
+	newOrchestrator := func(projectDir, workflowName, sinceRunID string) *evolution.Orchestrator {
+		projCfg, err := config.Load(projectDir)
+		if err != nil {
+			projCfg = cfg // fall back to daemon config
+		}
+		return evolution.NewOrchestrator(evolution.OrchestratorConfig{
+			ProjectDir:       projectDir,
+			WorkflowName:     workflowName,
+			LLM:              &evolution.CommandLLMClient{Command: llmCmd},
+			MinConfidence:    projCfg.Evolution.MinConfidence,
+			CollectStates:    collectStates(projCfg.Evolution.CollectStates),
+			LogLLM:           projCfg.Evolution.LogLLM,
+			VerifyEvidence:   projCfg.Evolution.VerifyEvidence,
+			ReflectLogChars:  projCfg.Output.ReflectChars,
+			ReflectDiffChars: projCfg.Evolution.DiffChars,
+			SinceRunID:       sinceRunID,
+		})
+	}
+
 	trigger := evolution.NewTrigger(evolution.TriggerConfig{
 		DebounceSeconds: cfg.Evolution.DebounceSeconds,
+		DebounceScope:   evolution.DebounceScope(cfg.Evolution.DebounceScope),
 		RunFunc: func(projectDir, workflowName, runID string) {
-			// Load per-project config for confidence threshold
-			projCfg, err := config.Load(projectDir)
-			if err != nil {
-				projCfg = cfg // fall back to daemon config
-			}
-
-			llm := &evolution.CommandLLMClient{Command: llmCmd}
-			orch := evolution.NewOrchestrator(evolution.OrchestratorConfig{
-				ProjectDir:    projectDir,
-				WorkflowName:  workflowName,
-				LLM:           llm,
-				MinConfidence: projCfg.Evolution.MinConfidence,
-			})
-
 			ctx := context.Background()
-			if _, err := orch.Run(ctx, runID, evoStore, capStore); err != nil {
+			if _, err := newOrchestrator(projectDir, workflowName, "").Run(ctx, runID, evoStore, capStore); err != nil {
 				fmt.Fprintf(os.Stderr, "evolution error for %s/%s: %v\n", projectDir, workflowName, err)
 			}
 		},
+		EvolveFunc: func(ctx context.Context, projectDir, workflowName, sinceRunID string) (*evolution.EvolutionResult, error) {
+			return newOrchestrator(projectDir, workflowName, sinceRunID).Run(ctx, "", evoStore, capStore)
+		},
 	})
 
 	return trigger
 }
 
+// collectStates converts the [evolution.collect_states] table into the
+// orchestrator's form. The "default" key becomes the fallback entry.
+func collectStates(cfg map[string][]string) map[string][]domain.RunState {
+	if len(cfg) == 0 {
+		return nil
+	}
+	out := make(map[string][]domain.RunState, len(cfg))
+	for classification, states := range cfg {
+		if classification == "default" {
+			classification = ""
+		}
+		for _, st := range states {
+			out[classification] = append(out[classification], domain.RunState(st))
+		}
+	}
+	return out
+}
 
 // autoRunActiveProjects scans known projects for active = true in their config
 // and starts the orchestration loop for each one via EnableLoop.
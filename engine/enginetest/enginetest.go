@@ -0,0 +1,151 @@
+// Package enginetest provides a scripted step executor and assertions for
+// testing workflows, custom step types, and code that embeds the engine,
+// without running real scripts or agents.
+package enginetest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+)
+
+// Step is the step an Executor is asked to run. It aliases the engine's own
+// type so code outside this module can name it.
+type Step = domain.Step
+
+// StepResult is what an Executor returns for a step. It aliases the engine's
+// own type so code outside this module can name it.
+type StepResult = domain.StepResult
+
+// Executor is an engine.StepExecutor that returns predetermined results and
+// records which steps ran. It is safe for concurrent use, so it can drive
+// fanout workflows.
+type Executor struct {
+	mu        sync.Mutex
+	results   map[string]string
+	sequences map[string][]string
+	errors    map[string]error
+	calls     []string
+}
+
+// NewExecutor returns an Executor that answers each step with results[name].
+// Executing a step with no result, sequence, or error configured returns an
+// error, which fails the run unless the step sets continue_on_error.
+func NewExecutor(results map[string]string) *Executor {
+	e := &Executor{
+		results:   make(map[string]string, len(results)),
+		sequences: map[string][]string{},
+		errors:    map[string]error{},
+	}
+	for name, result := range results {
+		e.results[name] = result
+	}
+	return e
+}
+
+// SetSequence makes step return results in order on successive executions,
+// e.g. "fail", "fail", "success" for a step inside a retry loop. Once the
+// sequence is exhausted the last result repeats.
+func (e *Executor) SetSequence(step string, results ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sequences[step] = append([]string(nil), results...)
+}
+
+// SetError makes step fail with err instead of returning a result.
+func (e *Executor) SetError(step string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errors[step] = err
+}
+
+// Execute implements engine.StepExecutor.
+func (e *Executor) Execute(_ context.Context, step *Step) (StepResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, step.Name)
+
+	if err, ok := e.errors[step.Name]; ok {
+		return StepResult{}, err
+	}
+	if seq := e.sequences[step.Name]; len(seq) > 0 {
+		result := seq[0]
+		if len(seq) > 1 {
+			e.sequences[step.Name] = seq[1:]
+		}
+		return StepResult{Result: result}, nil
+	}
+	if result, ok := e.results[step.Name]; ok {
+		return StepResult{Result: result}, nil
+	}
+	return StepResult{}, fmt.Errorf("enginetest: no result configured for step %q", step.Name)
+}
+
+// Calls returns the names of the executed steps in execution order, with a
+// step repeated once per execution.
+func (e *Executor) Calls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.calls...)
+}
+
+// CallCount returns how many times step was executed.
+func (e *Executor) CallCount(step string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := 0
+	for _, name := range e.calls {
+		if name == step {
+			n++
+		}
+	}
+	return n
+}
+
+// AssertCalls reports an error on t unless exactly the steps in want ran, in
+// that order. Use it for workflows without fanout, whose order is fixed.
+func (e *Executor) AssertCalls(t testing.TB, want ...string) bool {
+	t.Helper()
+	got := e.Calls()
+	if strings.Join(got, "\x00") != strings.Join(want, "\x00") {
+		t.Errorf("enginetest: steps ran as %v, want %v", got, want)
+		return false
+	}
+	return true
+}
+
+// AssertOrder reports an error on t unless the steps in want ran in that
+// relative order; other steps may run in between. Use it for fanout
+// workflows, where only the order along each branch is fixed.
+func (e *Executor) AssertOrder(t testing.TB, want ...string) bool {
+	t.Helper()
+	got := e.Calls()
+	i := 0
+	for _, name := range got {
+		if i < len(want) && name == want[i] {
+			i++
+		}
+	}
+	if i < len(want) {
+		t.Errorf("enginetest: steps ran as %v, want %v in that order", got, want)
+		return false
+	}
+	return true
+}
+
+// AssertNotCalled reports an error on t if any of steps ran.
+func (e *Executor) AssertNotCalled(t testing.TB, steps ...string) bool {
+	t.Helper()
+	ok := true
+	for _, step := range steps {
+		if n := e.CallCount(step); n > 0 {
+			t.Errorf("enginetest: step %q ran %d time(s), want none", step, n)
+			ok = false
+		}
+	}
+	return ok
+}
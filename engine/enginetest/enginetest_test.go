@@ -0,0 +1,138 @@
+package enginetest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cloche-dev/cloche/engine/enginetest"
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/engine"
+		"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT captures assertion failures so the helpers' failure paths can be
+// tested without failing the enclosing test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func retryWorkflow() *domain.Workflow {
+	return &domain.Workflow{
+		Name: "retry",
+		Steps: map[string]*domain.Step{
+			"code":  {Name: "code", Type: domain.StepTypeAgent, Results: []string{"success", "fail"}},
+			"check": {Name: "check", Type: domain.StepTypeScript, Results: []string{"pass", "fail"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "code", Result: "success", To: "check"},
+			{From: "code", Result: "fail", To: domain.StepAbort},
+			{From: "check", Result: "pass", To: domain.StepDone},
+			{From: "check", Result: "fail", To: "code"},
+		},
+		EntryStep: "code",
+	}
+}
+
+func TestExecutor_DrivesEngineWithMappedResults(t *testing.T) {
+	exec := enginetest.NewExecutor(map[string]string{"code": "success", "check": "pass"})
+
+	run, err := engine.New(exec).Run(context.Background(), retryWorkflow())
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateSucceeded, run.State)
+	assert.True(t, exec.AssertCalls(t, "code", "check"))
+}
+
+func TestExecutor_SequenceDrivesRetryLoop(t *testing.T) {
+	exec := enginetest.NewExecutor(map[string]string{"code": "success"})
+	exec.SetSequence("check", "fail", "fail", "pass")
+
+	run, err := engine.New(exec).Run(context.Background(), retryWorkflow())
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateSucceeded, run.State)
+	exec.AssertCalls(t, "code", "check", "code", "check", "code", "check")
+	assert.Equal(t, 3, exec.CallCount("check"))
+}
+
+func TestExecutor_ErrorsFailTheRun(t *testing.T) {
+	exec := enginetest.NewExecutor(nil)
+	exec.SetError("code", errors.New("agent crashed"))
+
+	run, err := engine.New(exec).Run(context.Background(), retryWorkflow())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agent crashed")
+	assert.Equal(t, domain.RunStateFailed, run.State)
+	exec.AssertNotCalled(t, "check")
+}
+
+func TestExecutor_ExecuteWithoutEngine(t *testing.T) {
+	// Code outside this module drives the executor through the aliases.
+	exec := enginetest.NewExecutor(map[string]string{"lint": "success"})
+
+	res, err := exec.Execute(context.Background(), &enginetest.Step{Name: "lint"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", res.Result)
+	exec.AssertCalls(t, "lint")
+}
+
+func TestExecutor_UnconfiguredStepErrors(t *testing.T) {
+	exec := enginetest.NewExecutor(map[string]string{"code": "success"})
+
+	_, err := engine.New(exec).Run(context.Background(), retryWorkflow())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no result configured for step "check"`)
+}
+
+func TestExecutor_AssertOrderAllowsInterleavedFanout(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "fanout",
+		Steps: map[string]*domain.Step{
+			"build": {Name: "build", Type: domain.StepTypeScript, Results: []string{"success"}},
+			"lint":  {Name: "lint", Type: domain.StepTypeScript, Results: []string{"success"}},
+			"test":  {Name: "test", Type: domain.StepTypeScript, Results: []string{"success"}},
+			"ship":  {Name: "ship", Type: domain.StepTypeScript, Results: []string{"success"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "build", Result: "success", To: "lint"},
+			{From: "build", Result: "success", To: "test"},
+			{From: "ship", Result: "success", To: domain.StepDone},
+		},
+		Collects: []domain.Collect{{
+			Mode:       domain.CollectAll,
+			Conditions: []domain.WireCondition{{Step: "lint", Result: "success"}, {Step: "test", Result: "success"}},
+			To:         "ship",
+		}},
+		EntryStep: "build",
+	}
+	exec := enginetest.NewExecutor(map[string]string{"build": "success", "lint": "success", "test": "success", "ship": "success"})
+
+	run, err := engine.New(exec).Run(context.Background(), wf)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateSucceeded, run.State)
+	exec.AssertOrder(t, "build", "lint", "ship")
+	exec.AssertOrder(t, "build", "test", "ship")
+}
+
+func TestExecutor_AssertionsReportMismatches(t *testing.T) {
+	exec := enginetest.NewExecutor(map[string]string{"code": "success", "check": "pass"})
+	_, err := engine.New(exec).Run(context.Background(), retryWorkflow())
+	require.NoError(t, err)
+
+	rt := &recordingT{}
+	assert.False(t, exec.AssertCalls(rt, "check", "code"))
+	assert.False(t, exec.AssertOrder(rt, "check", "code"))
+	assert.False(t, exec.AssertNotCalled(rt, "check"))
+	assert.True(t, exec.AssertOrder(rt, "code"))
+	require.Len(t, rt.errors, 3)
+	assert.Contains(t, rt.errors[0], "want [check code]")
+	assert.Contains(t, rt.errors[2], `step "check" ran 1 time(s)`)
+}
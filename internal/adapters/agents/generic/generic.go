@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 type Adapter struct {
 	StatusWriter *protocol.StatusWriter // optional: streams live output lines
 	RunID        string                 // optional: passed as CLOCHE_RUN_ID to child processes
+	TmpDir       string                 // optional: run-scoped scratch dir, passed as CLOCHE_TMPDIR
 }
 
 func New() *Adapter {
@@ -26,7 +28,14 @@ func (a *Adapter) Name() string {
 }
 
 func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string) (domain.StepResult, error) {
-	cmdStr := step.Config["run"]
+	cmdStr, err := step.ExpandParams(step.Config["run"])
+	if err != nil {
+		return domain.StepResult{}, err
+	}
+	filter, err := protocol.StepOutputFilter(step)
+	if err != nil {
+		return domain.StepResult{}, fmt.Errorf("step %q: %w", step.Name, err)
+	}
 	cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
 	cmd.Dir = workDir
 
@@ -37,16 +46,24 @@ func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string
 			"CLOCHE_PROJECT_DIR="+workDir,
 		)
 	}
+	if a.TmpDir != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, "CLOCHE_TMPDIR="+a.TmpDir)
+	}
 
 	var output []byte
-	var err error
 
 	if a.StatusWriter != nil {
-		output, err = a.executeStreaming(cmd, step.Name)
+		output, err = a.executeStreaming(cmd, step.Name, filter)
 	} else {
 		output, err = cmd.CombinedOutput()
+		output = protocol.FilterOutput(filter, output)
 	}
 
+	exitCode := protocol.ExitCode(cmd.ProcessState)
+
 	// Extract result marker before writing logs
 	markerResult, cleanOutput, found := protocol.ExtractResult(output)
 
@@ -64,8 +81,8 @@ func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string
 			if found {
 				result = markerResult
 			}
-			protocol.AppendHistory(workDir, step.Name, result, isAgent, cleanOutput)
-			return domain.StepResult{Result: result}, nil
+			protocol.AppendHistory(workDir, a.RunID, step.Name, result, isAgent, cleanOutput)
+			return domain.StepResult{Result: result, ExitCode: exitCode}, nil
 		}
 		return domain.StepResult{}, err
 	}
@@ -73,9 +90,13 @@ func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string
 	result := "success"
 	if found {
 		result = markerResult
+	} else if r := step.Config["empty_result"]; r != "" && len(bytes.TrimSpace(cleanOutput)) == 0 {
+		// Scripts that exit 0 silently often mean "nothing to do"; let the
+		// workflow route that separately from a real success.
+		result = r
 	}
-	protocol.AppendHistory(workDir, step.Name, result, isAgent, cleanOutput)
-	return domain.StepResult{Result: result}, nil
+	protocol.AppendHistory(workDir, a.RunID, step.Name, result, isAgent, cleanOutput)
+	return domain.StepResult{Result: result, ExitCode: exitCode}, nil
 }
 
 // appendStepLog appends data to the step log file, preserving prior invocations.
@@ -89,8 +110,8 @@ func appendStepLog(path string, data []byte) {
 }
 
 // executeStreaming runs the command and streams output lines through StatusWriter
-// in real-time. Returns the full accumulated output.
-func (a *Adapter) executeStreaming(cmd *exec.Cmd, stepName string) ([]byte, error) {
+// in real-time. Returns the full accumulated output, cleaned by filter.
+func (a *Adapter) executeStreaming(cmd *exec.Cmd, stepName string, filter protocol.OutputFilter) ([]byte, error) {
 	pipe, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -102,9 +123,13 @@ func (a *Adapter) executeStreaming(cmd *exec.Cmd, stepName string) ([]byte, erro
 	}
 
 	var buf bytes.Buffer
+	lines := protocol.NewLineFilter(filter)
 	scanner := bufio.NewScanner(pipe)
 	for scanner.Scan() {
-		line := scanner.Text()
+		line, keep := lines.Line(scanner.Text())
+		if !keep {
+			continue
+		}
 		buf.WriteString(line)
 		buf.WriteByte('\n')
 		a.StatusWriter.Log(stepName, line)
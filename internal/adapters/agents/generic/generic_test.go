@@ -139,6 +139,76 @@ func TestGenericAdapter_MarkerOverridesFailExitCode(t *testing.T) {
 	assert.Equal(t, "bug_fix", sr.Result)
 }
 
+func TestGenericAdapter_EmptyOutputResult(t *testing.T) {
+	adapter := generic.New()
+	step := &domain.Step{
+		Name:    "pending",
+		Type:    domain.StepTypeScript,
+		Results: []string{"success", "fail", "idle"},
+		Config:  map[string]string{"run": "true", "empty_result": "idle"},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "idle", sr.Result)
+
+	// Any output keeps the default success result.
+	step.Config["run"] = "echo migrating"
+	sr, err = adapter.Execute(context.Background(), step, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "success", sr.Result)
+
+	// Without empty_result a silent exit 0 is still success.
+	delete(step.Config, "empty_result")
+	step.Config["run"] = "true"
+	sr, err = adapter.Execute(context.Background(), step, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "success", sr.Result)
+}
+
+func TestGenericAdapter_ExpandsParams(t *testing.T) {
+	adapter := generic.New()
+	step := &domain.Step{
+		Name:    "test",
+		Type:    domain.StepTypeScript,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"run": `test "{{param.target}}" = "./pkg/..."`},
+	}
+	step.ApplyParams(map[string]string{"target": "./pkg/..."})
+
+	sr, err := adapter.Execute(context.Background(), step, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "success", sr.Result)
+
+	// A reference to a param the step does not carry fails before running.
+	step.Config["run"] = "echo {{param.missing}}"
+	_, err = adapter.Execute(context.Background(), step, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `undefined param "missing"`)
+}
+
+func TestGenericAdapter_ReportsExitCode(t *testing.T) {
+	adapter := generic.New()
+	for _, tc := range []struct {
+		run  string
+		want int
+	}{
+		{"true", 0},
+		{"exit 3", 3},
+	} {
+		step := &domain.Step{
+			Name:    "build",
+			Type:    domain.StepTypeScript,
+			Results: []string{"success", "fail"},
+			Config:  map[string]string{"run": tc.run},
+		}
+		sr, err := adapter.Execute(context.Background(), step, t.TempDir())
+		require.NoError(t, err)
+		require.NotNil(t, sr.ExitCode, tc.run)
+		assert.Equal(t, tc.want, *sr.ExitCode, tc.run)
+	}
+}
+
 func TestGenericAdapter_PassesRunIDEnvVar(t *testing.T) {
 	dir := t.TempDir()
 	adapter := generic.New()
@@ -161,6 +231,28 @@ func TestGenericAdapter_PassesRunIDEnvVar(t *testing.T) {
 	assert.Contains(t, string(content), "test-run-42")
 }
 
+func TestGenericAdapter_PassesTmpDirEnvVar(t *testing.T) {
+	dir := t.TempDir()
+	tmpDir := t.TempDir()
+	adapter := generic.New()
+	adapter.TmpDir = tmpDir
+
+	step := &domain.Step{
+		Name:    "scratch",
+		Type:    domain.StepTypeScript,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"run": `echo scratch > "$CLOCHE_TMPDIR/notes.txt"`},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "success", sr.Result)
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "notes.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "scratch\n", string(content))
+}
+
 func TestGenericAdapter_PassesProjectDirEnvVar(t *testing.T) {
 	dir := t.TempDir()
 	adapter := generic.New()
@@ -347,3 +439,71 @@ func TestGenericAdapter_StepLogAccumulates(t *testing.T) {
 	occurrences := bytes.Count(content, []byte("iteration output"))
 	assert.Equal(t, 2, occurrences, "step log should contain output from both invocations")
 }
+
+func TestGenericAdapter_StripsANSIFromCapturedOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	var statusBuf bytes.Buffer
+	adapter := generic.New()
+	adapter.StatusWriter = protocol.NewStatusWriter(&statusBuf)
+
+	step := &domain.Step{
+		Name:    "review",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail", "needs_work"},
+		Config: map[string]string{
+			"run":           `printf '\033[1;34mreviewing\033[0m\n\033[33m-\033[0m\r\033[33m\\\033[0m\r\033[33m|\033[0m\n|\n\033[32mCLOCHE_RESULT:needs_work\033[0m\n'`,
+			"output_filter": "collapse",
+		},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "needs_work", sr.Result, "colored marker should still select the result")
+
+	msgs, err := protocol.ParseStatusStream(statusBuf.Bytes())
+	require.NoError(t, err)
+	var logMessages []string
+	for _, msg := range msgs {
+		if msg.Type == protocol.MsgLog && msg.StepName == "review" {
+			logMessages = append(logMessages, msg.Message)
+		}
+	}
+	assert.Equal(t, []string{"reviewing", "|", "CLOCHE_RESULT:needs_work"}, logMessages)
+
+	content, err := os.ReadFile(filepath.Join(dir, ".cloche", "output", "review.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "reviewing\n|\n", string(content))
+}
+
+func TestGenericAdapter_RawOutputFilter(t *testing.T) {
+	dir := t.TempDir()
+	adapter := generic.New()
+	step := &domain.Step{
+		Name:    "build",
+		Type:    domain.StepTypeScript,
+		Results: []string{"success", "fail"},
+		Config: map[string]string{
+			"run":           `printf '\033[31mred\033[0m\n'`,
+			"output_filter": "raw",
+		},
+	}
+
+	_, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, ".cloche", "output", "build.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "\x1b[31mred\x1b[0m\n", string(content))
+}
+
+func TestGenericAdapter_InvalidOutputFilter(t *testing.T) {
+	step := &domain.Step{
+		Name:   "build",
+		Type:   domain.StepTypeScript,
+		Config: map[string]string{"run": "true", "output_filter": "clean"},
+	}
+
+	_, err := generic.New().Execute(context.Background(), step, t.TempDir())
+	assert.ErrorContains(t, err, "output_filter")
+}
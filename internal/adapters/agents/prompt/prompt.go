@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/protocol"
@@ -24,6 +25,17 @@ var defaultAgentArgs = map[string][]string{
 	"opencode": {"run", "--format", "json", "--dangerously-skip-permissions"},
 }
 
+// systemPromptFlags maps agent commands that accept a system prompt apart
+// from the prompt on stdin to the flag that carries it. Other commands get
+// the step's system prompt as a labeled section at the top of stdin.
+var systemPromptFlags = map[string]string{
+	"claude": "--append-system-prompt",
+}
+
+// maxSystemPromptArg caps a system prompt passed as a single argument, below
+// the kernel's per-argument limit. Longer ones go on stdin instead.
+const maxSystemPromptArg = 64 * 1024
+
 type Adapter struct {
 	Commands           []string // ordered fallback chain of agent commands
 	ExplicitArgs       []string // if non-nil, overrides default args for all commands
@@ -33,6 +45,7 @@ type Adapter struct {
 	ResumeConversation bool                   // when true, resume previous conversation instead of starting new one
 	UsageCommand       string                 // optional: shell command to run after step to capture token usage JSON
 	PrevOutput         string                 // content of the immediate predecessor step's output log
+	PromptChars        int                    // caps PrevOutput and the user prompt fed into the prompt; zero or less is unlimited
 	ExtraEnv           []string               // additional KEY=VALUE env vars injected into the agent process
 	KV                 KVReader               // optional: KV store for {{ $var }} lookups; nil disables non-builtin vars
 }
@@ -80,6 +93,24 @@ func (a *Adapter) argsFor(command string) []string {
 	return nil
 }
 
+// expandEnv resolves "${NAME}" references in an agent command or argument,
+// which the parser leaves for run time, against the agent's environment:
+// ExtraEnv first, then the process environment.
+func (a *Adapter) expandEnv(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	out, _ := domain.ExpandEnvRefs(s, func(name string) (string, bool) {
+		for i := len(a.ExtraEnv) - 1; i >= 0; i-- {
+			if v, ok := strings.CutPrefix(a.ExtraEnv[i], name+"="); ok {
+				return v, true
+			}
+		}
+		return os.LookupEnv(name)
+	})
+	return out
+}
+
 // containsArg checks if an argument list contains a specific flag.
 func containsArg(args []string, flag string) bool {
 	for _, a := range args {
@@ -105,9 +136,13 @@ func ParseCommands(s string) []string {
 }
 
 func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string) (domain.StepResult, error) {
+	filter, err := protocol.StepOutputFilter(step)
+	if err != nil {
+		return domain.StepResult{}, fmt.Errorf("step %q: %w", step.Name, err)
+	}
+
 	// Check attempt count for retry limiting
-	if maxStr, ok := step.Config["max_attempts"]; ok {
-		max, err := strconv.Atoi(maxStr)
+	if max, ok, err := step.Config.Int("max_attempts"); ok {
 		if err == nil {
 			count := readAttemptCount(workDir, a.TaskID, step.Name)
 			if count >= max {
@@ -118,12 +153,12 @@ func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string
 	incrementAttemptCount(workDir, a.TaskID, step.Name)
 
 	// Build the full prompt
-	var fullPrompt string
+	var system, fullPrompt string
 	if a.ResumeConversation {
 		fullPrompt = "retry"
 	} else {
 		var err error
-		fullPrompt, err = a.assemblePrompt(ctx, step, workDir)
+		system, fullPrompt, err = a.assemblePrompt(ctx, step, workDir)
 		if err != nil {
 			return domain.StepResult{}, fmt.Errorf("assembling prompt: %w", err)
 		}
@@ -133,15 +168,17 @@ func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string
 	var lastResult string
 	var lastStdout []byte
 	var lastUsage *domain.TokenUsage
+	var lastExitCode *int
 	var lastErr error
 	var lastCommand string
 	ran := false
 
 	for _, command := range a.Commands {
-		result, stdout, usage, fallbackErr := a.tryCommand(ctx, command, fullPrompt, workDir, step.Name)
+		result, stdout, usage, exitCode, fallbackErr := a.tryCommand(ctx, command, system, fullPrompt, workDir, step.Name, filter)
 		lastResult = result
 		lastStdout = stdout
 		lastUsage = usage
+		lastExitCode = exitCode
 		lastErr = fallbackErr
 		lastCommand = command
 
@@ -193,14 +230,15 @@ func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string
 	if mkErr := os.MkdirAll(outputDir, 0755); mkErr == nil {
 		appendStepLog(filepath.Join(outputDir, step.Name+".log"), lastStdout)
 	}
-	protocol.AppendHistory(workDir, step.Name, result, true, nil)
-	return domain.StepResult{Result: result, Usage: lastUsage}, nil
+	protocol.AppendHistory(workDir, a.RunID, step.Name, result, true, nil)
+	return domain.StepResult{Result: result, Usage: lastUsage, Prompt: withSystemSection(system, fullPrompt), ExitCode: lastExitCode}, nil
 }
 
 // tryCommand executes a single agent command and returns:
 //   - result: the step result name (e.g. "success", "fail")
 //   - stdout: captured stdout bytes
 //   - usage: token usage extracted from result event (nil if not available)
+//   - exitCode: the command's exit code (nil if it did not run to completion)
 //   - fallbackErr: nil if the result is definitive, non-nil if fallback-eligible
 //
 // Fallback-eligible conditions:
@@ -210,12 +248,25 @@ func (a *Adapter) Execute(ctx context.Context, step *domain.Step, workDir string
 // Definitive (non-fallback) conditions:
 //   - Command exited 0
 //   - Command exited non-zero but produced a CLOCHE_RESULT marker
-func (a *Adapter) tryCommand(ctx context.Context, command string, prompt string, workDir string, stepName string) (result string, stdout []byte, usage *domain.TokenUsage, fallbackErr error) {
-	args := a.argsFor(command)
+//
+// A non-empty system prompt is passed by flag to commands that take one and
+// is otherwise prepended to the prompt on stdin. Output is cleaned by filter
+// before it is streamed, returned, or scanned for a result marker.
+func (a *Adapter) tryCommand(ctx context.Context, command string, system, prompt string, workDir string, stepName string, filter protocol.OutputFilter) (result string, stdout []byte, usage *domain.TokenUsage, exitCode *int, fallbackErr error) {
+	command = a.expandEnv(command)
+	var args []string
+	for _, arg := range a.argsFor(command) {
+		args = append(args, a.expandEnv(arg))
+	}
 	// Resume mode: add -c flag to resume previous conversation
 	if a.ResumeConversation {
 		args = append([]string{"-c"}, args...)
 	}
+	if flag, ok := systemPromptFlags[command]; ok && system != "" && len(system) <= maxSystemPromptArg {
+		args = append(append([]string(nil), args...), flag, system)
+	} else {
+		prompt = withSystemSection(system, prompt)
+	}
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Dir = workDir
 	cmd.Stdin = strings.NewReader(prompt)
@@ -230,7 +281,8 @@ func (a *Adapter) tryCommand(ctx context.Context, command string, prompt string,
 		cmd.Stderr = &stderrBuf
 
 		runErr := cmd.Run()
-		stdoutBytes := stdoutBuf.Bytes()
+		exitCode = protocol.ExitCode(cmd.ProcessState)
+		stdoutBytes := protocol.FilterOutput(filter, stdoutBuf.Bytes())
 		result, stdout, fallbackErr = a.classifyResult(command, stdoutBytes, runErr)
 		usage = scanOutputForUsage(stdoutBytes)
 		if usage != nil {
@@ -242,12 +294,12 @@ func (a *Adapter) tryCommand(ctx context.Context, command string, prompt string,
 	// Streaming path: pipe stdout through a scanner so we can emit lines live.
 	pipe, err := cmd.StdoutPipe()
 	if err != nil {
-		return "", nil, nil, fmt.Errorf("command %q stdout pipe: %w", command, err)
+		return "", nil, nil, nil, fmt.Errorf("command %q stdout pipe: %w", command, err)
 	}
 	cmd.Stderr = nil // discard stderr
 
 	if err := cmd.Start(); err != nil {
-		return "", nil, nil, fmt.Errorf("command %q failed to start: %w", command, err)
+		return "", nil, nil, nil, fmt.Errorf("command %q failed to start: %w", command, err)
 	}
 
 	// textBuf accumulates extracted text content for result extraction.
@@ -255,11 +307,23 @@ func (a *Adapter) tryCommand(ctx context.Context, command string, prompt string,
 	var textBuf, rawBuf bytes.Buffer
 	// lineBuf accumulates text deltas into lines for streaming.
 	var lineBuf strings.Builder
+	// Raw lines and streamed text lines are filtered separately: each is
+	// its own stream of lines for collapsing repeats.
+	rawLines, textLines := protocol.NewLineFilter(filter), protocol.NewLineFilter(filter)
+	streamLine := func(line string) {
+		if clean, keep := textLines.Line(line); keep {
+			a.StatusWriter.Log(stepName, clean)
+		}
+	}
 
 	scanner := bufio.NewScanner(pipe)
 	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
 	for scanner.Scan() {
-		raw := scanner.Bytes()
+		line, keep := rawLines.Line(scanner.Text())
+		if !keep {
+			continue
+		}
+		raw := []byte(line)
 		rawBuf.Write(raw)
 		rawBuf.WriteByte('\n')
 
@@ -283,33 +347,34 @@ func (a *Adapter) tryCommand(ctx context.Context, command string, prompt string,
 			if idx < 0 {
 				break
 			}
-			a.StatusWriter.Log(stepName, s[:idx])
+			streamLine(s[:idx])
 			lineBuf.Reset()
 			lineBuf.WriteString(s[idx+1:])
 		}
 	}
 	// Flush any remaining partial line.
 	if lineBuf.Len() > 0 {
-		a.StatusWriter.Log(stepName, lineBuf.String())
+		streamLine(lineBuf.String())
 	}
 	if scanErr := scanner.Err(); scanErr != nil {
 		a.StatusWriter.Log(stepName, fmt.Sprintf("[scan error: %v]", scanErr))
 	}
 
 	waitErr := cmd.Wait()
+	exitCode = protocol.ExitCode(cmd.ProcessState)
 	// Check raw output for agent-level errors (e.g. error_during_execution
 	// from rate limits) before classifying the extracted text.
 	if bytes.Contains(rawBuf.Bytes(), []byte(`"error_during_execution"`)) {
-		return "fail", rawBuf.Bytes(), usage, fmt.Errorf("command %q reported error_during_execution", command)
+		return "fail", rawBuf.Bytes(), usage, exitCode, fmt.Errorf("command %q reported error_during_execution", command)
 	}
 	// Prefer extracted text (stream-json) for result classification; fall back
 	// to raw output for non-JSON commands (scripts, non-claude agents).
-	classifyBuf := textBuf.Bytes()
+	classifyBuf := protocol.FilterOutput(filter, textBuf.Bytes())
 	if len(bytes.TrimSpace(classifyBuf)) == 0 {
 		classifyBuf = rawBuf.Bytes()
 	}
 	result, _, fallbackErr = a.classifyResult(command, classifyBuf, waitErr)
-	return result, rawBuf.Bytes(), usage, fallbackErr
+	return result, rawBuf.Bytes(), usage, exitCode, fallbackErr
 }
 
 // classifyResult interprets the command's exit status and stdout to determine
@@ -591,72 +656,266 @@ func toolInputSummary(input json.RawMessage) string {
 	return ""
 }
 
-func (a *Adapter) assemblePrompt(ctx context.Context, step *domain.Step, workDir string) (string, error) {
-	var parts []string
+// assemblePrompt builds the step's prompt from its sections. The system
+// prompt (the step's "system" config) is returned apart from the rest so
+// agents that accept one separately can be given it that way. When a step
+// sets both, its "prompt" template is labeled "## Prompt" so the two read as
+// distinct sections wherever they end up together.
+func (a *Adapter) assemblePrompt(ctx context.Context, step *domain.Step, workDir string) (system, prompt string, err error) {
+	var template, projectContext, request, results string
 
-	userPrompt := readUserPrompt(workDir, a.TaskID)
+	userPrompt := domain.TruncateOutput(readUserPrompt(workDir, a.TaskID), a.PromptChars)
 
-	// 1. Read system template from step config
-	if tmpl, ok := step.Config["prompt"]; ok {
-		content, err := resolveContent(tmpl, workDir)
-		if err != nil {
-			return "", fmt.Errorf("reading prompt template: %w", err)
-		}
-
-		// New {{ }} resolver pass.
-		resolver := &Resolver{
-			Builtins: map[string]string{
-				"task_id":          a.TaskID,
-				"run_id":           a.RunID,
-				"step_name":        step.Name,
-				"workdir":          workDir,
-				"prev_output":      a.PrevOutput,
-				"task_description": userPrompt,
-			},
-			KV:      a.KV,
-			WorkDir: workDir,
-		}
-		content, err = resolver.Resolve(ctx, content)
+	// 1. Read the system and prompt templates from step config
+	if tmpl, ok := step.Config["system"]; ok {
+		system, err = a.renderTemplate(ctx, step, workDir, tmpl, &userPrompt)
 		if err != nil {
-			return "", fmt.Errorf("prompt template: %w", err)
+			return "", "", fmt.Errorf("system prompt template: %w", err)
 		}
-
-		// Legacy single-brace pass with deprecation warnings (once per pattern).
-		warnFn := func(pattern string) {
-			newName := legacyToNewName(pattern)
-			msg := fmt.Sprintf("WARN [step=%s] %s is deprecated; use {{ $%s }}", step.Name, pattern, newName)
-			if a.StatusWriter != nil {
-				a.StatusWriter.Log(step.Name, msg)
-			} else {
-				log.Printf("%s", msg)
-			}
+	}
+	if tmpl, ok := step.Config["prompt"]; ok {
+		template, err = a.renderTemplate(ctx, step, workDir, tmpl, &userPrompt)
+		if err != nil {
+			return "", "", fmt.Errorf("prompt template: %w", err)
 		}
-		taskDescConsumed := strings.Contains(content, "{task_description}")
-		content = LegacySubstitute(content, userPrompt, a.PrevOutput, warnFn)
-		if taskDescConsumed {
-			userPrompt = "" // consumed — don't append again below
+		if system != "" {
+			template = "## Prompt\n" + template
 		}
+	}
 
-		parts = append(parts, content)
+	// 2. Project context files
+	if files, _ := step.Config.StringList("context_files"); len(files) > 0 {
+		section, err := readContextFiles(files, workDir)
+		if err != nil {
+			return "", "", err
+		}
+		projectContext = section
 	}
 
-	// 2. Append user prompt if not already substituted into template
+	// 3. Append user prompt if not already substituted into template, then
+	// the step's earlier results so a retry knows how it has fared so far.
 	if userPrompt != "" {
-		parts = append(parts, "## User Request\n"+userPrompt)
+		request = "## User Request\n" + userPrompt
 	}
+	request = joinPromptParts(request, a.attemptHistory(step, workDir))
 
-	// 3. Result selection instructions
-	if len(step.Results) > 0 {
+	// 4. Result selection instructions, unless the step conveys the result
+	// protocol itself. The marker is still parsed from the output either way.
+	inject, set, err := step.Config.Bool("inject_result_instructions")
+	if err != nil {
+		return "", "", err
+	}
+	if !set {
+		inject = true
+	}
+	if len(step.Results) > 0 && inject {
 		var resultLines []string
 		resultLines = append(resultLines, "## Result Selection")
 		resultLines = append(resultLines, "When you are finished, output exactly one of the following on its own line:")
 		for _, r := range step.Results {
 			resultLines = append(resultLines, protocol.ResultPrefix+r)
 		}
-		parts = append(parts, strings.Join(resultLines, "\n"))
+		results = strings.Join(resultLines, "\n")
 	}
 
-	return strings.Join(parts, "\n\n"), nil
+	if max, ok, err := step.Config.Int("max_prompt_chars"); ok {
+		if err != nil || max <= 0 {
+			return "", "", fmt.Errorf("max_prompt_chars must be a positive integer, got %q", step.Config["max_prompt_chars"])
+		}
+		system, template, projectContext, err = fitPrompt(max, system, template, projectContext, request, results, a.prevOutput())
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return system, joinPromptParts(template, projectContext, request, results), nil
+}
+
+// renderTemplate resolves a file("...") reference and expands the step's
+// params, {{ }} variables and legacy placeholders in a prompt template. When
+// the template consumes {task_description}, *userPrompt is cleared so it is
+// not appended again as a User Request section.
+func (a *Adapter) renderTemplate(ctx context.Context, step *domain.Step, workDir, tmpl string, userPrompt *string) (string, error) {
+	content, err := resolveContent(tmpl, workDir)
+	if err != nil {
+		return "", err
+	}
+
+	// Expand {{param.<name>}} before the resolver, which would otherwise
+	// pass them through untouched.
+	content, err = step.ExpandParams(content)
+	if err != nil {
+		return "", err
+	}
+
+	// New {{ }} resolver pass.
+	resolver := &Resolver{
+		Builtins: map[string]string{
+			"task_id":          a.TaskID,
+			"run_id":           a.RunID,
+			"step_name":        step.Name,
+			"workdir":          workDir,
+			"prev_output":      a.prevOutput(),
+			"task_description": *userPrompt,
+		},
+		KV:      a.KV,
+		WorkDir: workDir,
+	}
+	content, err = resolver.Resolve(ctx, content)
+	if err != nil {
+		return "", err
+	}
+
+	// Legacy single-brace pass with deprecation warnings (once per pattern).
+	warnFn := func(pattern string) {
+		newName := legacyToNewName(pattern)
+		msg := fmt.Sprintf("WARN [step=%s] %s is deprecated; use {{ $%s }}", step.Name, pattern, newName)
+		if a.StatusWriter != nil {
+			a.StatusWriter.Log(step.Name, msg)
+		} else {
+			log.Printf("%s", msg)
+		}
+	}
+	taskDescConsumed := strings.Contains(content, "{task_description}")
+	content = LegacySubstitute(content, *userPrompt, a.prevOutput(), warnFn)
+	if taskDescConsumed {
+		*userPrompt = "" // consumed — don't append again
+	}
+	return content, nil
+}
+
+// prevOutput returns PrevOutput capped at PromptChars.
+func (a *Adapter) prevOutput() string {
+	return domain.TruncateOutput(a.PrevOutput, a.PromptChars)
+}
+
+// withSystemSection prepends a system prompt to the rest of the prompt as a
+// "## System" section. It returns prompt unchanged when system is empty.
+func withSystemSection(system, prompt string) string {
+	if system == "" {
+		return prompt
+	}
+	return joinPromptParts("## System\n"+system, prompt)
+}
+
+// attemptHistory summarizes the step's earlier invocations in this run from
+// the history log, e.g. "This is attempt 3 of 5. Previous results: fail,
+// fail." It is empty on a step's first attempt.
+func (a *Adapter) attemptHistory(step *domain.Step, workDir string) string {
+	previous := protocol.StepResults(workDir, a.RunID, step.Name)
+	if len(previous) == 0 {
+		return ""
+	}
+	attempt := fmt.Sprintf("This is attempt %d", len(previous)+1)
+	if max, _, err := step.Config.Int("max_attempts"); err == nil && max > 0 {
+		attempt += fmt.Sprintf(" of %d", max)
+	}
+	return fmt.Sprintf("## Attempt History\n%s. Previous results: %s.", attempt, strings.Join(previous, ", "))
+}
+
+// joinPromptParts joins the non-empty prompt sections with blank lines.
+func joinPromptParts(sections ...string) string {
+	var parts []string
+	for _, s := range sections {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// fitPrompt enforces max_prompt_chars, counted in characters, and returns
+// the system prompt, template and project context to use. Feedback (previous
+// step output substituted into the templates) is the lowest-priority part of
+// the prompt, so it is truncated first, then the project context, each with a
+// notice telling the agent what was cut. When the prompt is still too long,
+// the error names the largest remaining source so the user knows what to
+// shrink.
+func fitPrompt(max int, system, template, projectContext, request, results, prevOutput string) (string, string, string, error) {
+	length := func() int {
+		return utf8.RuneCountInString(joinPromptParts(withSystemSection(system, template), projectContext, request, results))
+	}
+	total := length()
+	if total <= max {
+		return system, template, projectContext, nil
+	}
+
+	uses := strings.Count(system, prevOutput) + strings.Count(template, prevOutput)
+	if prevOutput != "" && uses > 0 {
+		prevLen := utf8.RuneCountInString(prevOutput)
+		notice := fmt.Sprintf("\n[previous step output truncated from %d characters to fit max_prompt_chars]", prevLen)
+		keep := prevLen - utf8.RuneCountInString(notice) - (total-max+uses-1)/uses
+		feedback := truncateRunes(prevOutput, keep) + notice
+		if utf8.RuneCountInString(feedback) < prevLen {
+			system = strings.ReplaceAll(system, prevOutput, feedback)
+			template = strings.ReplaceAll(template, prevOutput, feedback)
+			if total = length(); total <= max {
+				return system, template, projectContext, nil
+			}
+		}
+	}
+
+	withSystem := withSystemSection(system, template)
+	rest := utf8.RuneCountInString(joinPromptParts(withSystem, request, results))
+	if projectContext != "" {
+		notice := fmt.Sprintf("\n\n[project context truncated from %d characters to fit max_prompt_chars]", utf8.RuneCountInString(projectContext))
+		room := max - rest - utf8.RuneCountInString(notice)
+		if rest > 0 {
+			room -= len("\n\n")
+		}
+		if room > len("## Project Context") {
+			return system, template, truncateRunes(projectContext, room) + notice, nil
+		}
+		if rest <= max {
+			return system, template, "", nil
+		}
+	}
+
+	largest, size := "prompt template", utf8.RuneCountInString(withSystem)
+	if n := utf8.RuneCountInString(request); n > size {
+		largest, size = "user request", n
+	}
+	if n := utf8.RuneCountInString(results); n > size {
+		largest, size = "result instructions", n
+	}
+	return "", "", "", fmt.Errorf("prompt is %d characters, over max_prompt_chars (%d); largest source is the %s (%d characters)", total, max, largest, size)
+}
+
+// truncateRunes returns the first n runes of s, or "" when n is not positive.
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// readContextFiles reads project-relative context files and formats them as a
+// "## Project Context" section, one "### <path>" subsection per file. A
+// missing file is an error so a typo in context_files does not silently
+// starve the agent of context.
+func readContextFiles(paths []string, workDir string) (string, error) {
+	var b strings.Builder
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(workDir, path))
+		if err != nil {
+			return "", fmt.Errorf("reading context file %q: %w", path, err)
+		}
+		if b.Len() == 0 {
+			b.WriteString("## Project Context")
+		}
+		fmt.Fprintf(&b, "\n\n### %s\n%s", path, strings.TrimRight(string(data), "\n"))
+	}
+	return b.String(), nil
 }
 
 // legacyToNewName maps a legacy placeholder name to the equivalent new-style variable name.
@@ -1,13 +1,17 @@
 package prompt_test
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/cloche-dev/cloche/internal/adapters/agents/prompt"
 	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/protocol"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -133,6 +137,34 @@ func TestPromptAdapter_RespectsMaxAttempts(t *testing.T) {
 	assert.Equal(t, "give-up", sr.Result)
 }
 
+func TestPromptAdapter_RespectsDefaultMaxAttempts(t *testing.T) {
+	dir := t.TempDir()
+	taskID := "test-task"
+
+	attemptDir := filepath.Join(dir, ".cloche", "runs", taskID, "attempt_count")
+	require.NoError(t, os.MkdirAll(attemptDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(attemptDir, "fix"), []byte("3"), 0644))
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "exit 1"}, // would fail if called
+		TaskID:       taskID,
+	}
+
+	// The step sets no max_attempts of its own; the workflow default applies.
+	step := &domain.Step{
+		Name:    "fix",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"fixed", "give-up"},
+		Config:  map[string]string{"prompt": "Fix the code."},
+	}
+	step.ApplyDefaults(map[string]string{"max_attempts": "3"})
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "give-up", sr.Result)
+}
+
 func TestPromptAdapter_CommandFailure(t *testing.T) {
 	dir := t.TempDir()
 
@@ -178,6 +210,389 @@ func TestPromptAdapter_InjectsResultInstructions(t *testing.T) {
 	assert.Contains(t, string(captured), "CLOCHE_RESULT:needs_research")
 }
 
+func TestPromptAdapter_OmitsResultInstructionsWhenDisabled(t *testing.T) {
+	dir := t.TempDir()
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "cat > captured_prompt.txt && echo CLOCHE_RESULT:needs_research"},
+	}
+
+	step := &domain.Step{
+		Name:    "analyze",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail", "needs_research"},
+		Config: map[string]string{
+			"prompt":                     "Analyze the code. Finish with CLOCHE_RESULT and your verdict.",
+			"inject_result_instructions": "false",
+		},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "needs_research", sr.Result, "the marker is still parsed")
+
+	captured, err := os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(captured), "## Result Selection")
+	assert.NotContains(t, string(captured), "CLOCHE_RESULT:success")
+
+	// A value that is not a bool is an error, not a silent default.
+	step.Config["inject_result_instructions"] = "no thanks"
+	_, err = adapter.Execute(context.Background(), step, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid inject_result_instructions "no thanks"`)
+}
+
+func TestPromptAdapter_ReturnsAssembledPrompt(t *testing.T) {
+	dir := t.TempDir()
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "cat > captured_prompt.txt && echo CLOCHE_RESULT:success"},
+	}
+
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"prompt": "Implement the feature."},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+
+	captured, err := os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, string(captured), sr.Prompt, "result carries exactly what the agent read on stdin")
+	assert.Contains(t, sr.Prompt, "Implement the feature.")
+}
+
+func TestPromptAdapter_PromptCharsCapsUserPrompt(t *testing.T) {
+	dir := t.TempDir()
+	promptDir := filepath.Join(dir, ".cloche", "runs", "task-1")
+	require.NoError(t, os.MkdirAll(promptDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(promptDir, "prompt.txt"), []byte(strings.Repeat("u", 100)), 0644))
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "cat > /dev/null && echo CLOCHE_RESULT:success"},
+		TaskID:       "task-1",
+		PromptChars:  10,
+	}
+
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"prompt": "Implement the feature."},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Contains(t, sr.Prompt, strings.Repeat("u", 10)+domain.TruncatedSuffix)
+	assert.NotContains(t, sr.Prompt, strings.Repeat("u", 11))
+}
+
+func TestPromptAdapter_SystemAndPromptSections(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "prompts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prompts", "system.md"), []byte("You are a careful Go reviewer."), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prompts", "review.md"), []byte("Review the diff."), 0644))
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "cat > captured_prompt.txt && echo CLOCHE_RESULT:success"},
+	}
+
+	step := &domain.Step{
+		Name:    "review",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail"},
+		Config: map[string]string{
+			"system": `file("prompts/system.md")`,
+			"prompt": `file("prompts/review.md")`,
+		},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+
+	// An agent without a system prompt flag reads both sections on stdin,
+	// system first.
+	captured, err := os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, string(captured), sr.Prompt)
+	assert.True(t, strings.HasPrefix(sr.Prompt, "## System\nYou are a careful Go reviewer.\n\n## Prompt\nReview the diff."), sr.Prompt)
+	assert.Less(t, strings.Index(sr.Prompt, "## Prompt"), strings.Index(sr.Prompt, "## Result Selection"))
+}
+
+func TestPromptAdapter_SystemPromptFlag(t *testing.T) {
+	dir := t.TempDir()
+
+	// A stand-in claude that records its arguments and stdin.
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > \"$PWD/args.txt\"\ncat > \"$PWD/stdin.txt\"\necho CLOCHE_RESULT:success\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "claude"), []byte(script), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	adapter := &prompt.Adapter{Commands: []string{"claude"}}
+	step := &domain.Step{
+		Name:    "review",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success"},
+		Config: map[string]string{
+			"system": "You are a careful Go reviewer.",
+			"prompt": "Review the diff.",
+		},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "success", sr.Result)
+
+	args, err := os.ReadFile(filepath.Join(dir, "args.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "--append-system-prompt\nYou are a careful Go reviewer.\n")
+
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin.txt"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(stdin), "careful Go reviewer")
+	assert.True(t, strings.HasPrefix(string(stdin), "## Prompt\nReview the diff."), string(stdin))
+
+	// The recorded prompt still carries both sections.
+	assert.Contains(t, sr.Prompt, "## System\nYou are a careful Go reviewer.")
+}
+
+func TestPromptAdapter_IncludesAttemptHistoryOnRetry(t *testing.T) {
+	dir := t.TempDir()
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "cat > captured_prompt.txt && echo CLOCHE_RESULT:fail"},
+	}
+
+	step := &domain.Step{
+		Name:    "fix",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail", "give-up"},
+		Config:  map[string]string{"prompt": "Fix the tests.", "max_attempts": "5"},
+	}
+
+	// First attempt: no history yet.
+	_, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	captured, err := os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(captured), "## Attempt History")
+
+	_, err = adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+
+	assert.Contains(t, sr.Prompt, "## Attempt History\nThis is attempt 3 of 5. Previous results: fail, fail.")
+	assert.Less(t, strings.Index(sr.Prompt, "## Attempt History"), strings.Index(sr.Prompt, "## Result Selection"))
+}
+
+func TestPromptAdapter_AttemptHistoryIsPerRun(t *testing.T) {
+	dir := t.TempDir()
+
+	step := &domain.Step{
+		Name:    "fix",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"prompt": "Fix the tests."},
+	}
+	run := func(runID string) *prompt.Adapter {
+		return &prompt.Adapter{
+			Commands:     []string{"sh"},
+			ExplicitArgs: []string{"-c", "cat > /dev/null && echo CLOCHE_RESULT:fail"},
+			RunID:        runID,
+		}
+	}
+
+	// An earlier run in the same workspace failed twice.
+	first := run("run-1")
+	for i := 0; i < 2; i++ {
+		_, err := first.Execute(context.Background(), step, dir)
+		require.NoError(t, err)
+	}
+
+	// A new run starts its attempt history from scratch.
+	second := run("run-2")
+	sr, err := second.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.NotContains(t, sr.Prompt, "## Attempt History")
+
+	sr, err = second.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Contains(t, sr.Prompt, "## Attempt History\nThis is attempt 2. Previous results: fail.")
+}
+
+func TestPromptAdapter_IncludesContextFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("Run make test before finishing.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "arch.md"), []byte("Hexagonal architecture."), 0644))
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "cat > captured_prompt.txt && echo ok"},
+	}
+
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success"},
+		Config: map[string]string{
+			"prompt":        "Implement the feature.",
+			"context_files": "AGENTS.md,docs/arch.md",
+		},
+	}
+
+	_, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+
+	captured, err := os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(captured), "## Project Context\n\n### AGENTS.md\nRun make test before finishing.\n\n### docs/arch.md\nHexagonal architecture.")
+	assert.Less(t, strings.Index(string(captured), "Implement the feature."), strings.Index(string(captured), "## Project Context"))
+	assert.Less(t, strings.Index(string(captured), "## Project Context"), strings.Index(string(captured), "## Result Selection"))
+
+	// A missing context file fails the step before the agent runs.
+	step.Config["context_files"] = "MISSING.md"
+	_, err = adapter.Execute(context.Background(), step, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `context file "MISSING.md"`)
+}
+
+func TestPromptAdapter_ListArgsKeepSpaces(t *testing.T) {
+	dir := t.TempDir()
+
+	// agent_args = ["-c", "<script>", "value with spaces"]: sh receives the
+	// last element as $0, in one piece.
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success"},
+		Config: map[string]string{
+			"prompt":     "Implement the feature.",
+			"agent_args": domain.EncodeArgs([]string{"-c", `cat > /dev/null && printf '%s' "$0" > arg.txt && echo ok`, "value with spaces"}),
+		},
+	}
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: domain.SplitArgs(step.Config["agent_args"]),
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "success", sr.Result)
+
+	got, err := os.ReadFile(filepath.Join(dir, "arg.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "value with spaces", string(got))
+}
+
+func TestPromptAdapter_MaxPromptChars(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "big.md"), []byte(strings.Repeat("context line\n", 500)), 0644))
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "cat > captured_prompt.txt && echo ok"},
+	}
+
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success"},
+		Config: map[string]string{
+			"prompt":           "Implement the feature.",
+			"context_files":    "big.md",
+			"max_prompt_chars": "1000",
+		},
+	}
+
+	// The project context is truncated first, with a notice; the template and
+	// result instructions survive intact.
+	_, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	captured, err := os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(captured), 1000)
+	assert.Contains(t, string(captured), "Implement the feature.")
+	assert.Contains(t, string(captured), "[project context truncated from")
+	assert.Contains(t, string(captured), "## Result Selection\n")
+
+	// When the template alone is over the cap, the error names it.
+	step.Config["prompt"] = strings.Repeat("x", 2000)
+	_, err = adapter.Execute(context.Background(), step, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "over max_prompt_chars (1000)")
+	assert.Contains(t, err.Error(), "largest source is the prompt template")
+
+	// Feedback (previous step output substituted into the template) is cut
+	// before the project context, which survives when the cut is enough.
+	adapter.PrevOutput = strings.Repeat("y", 2000)
+	step.Config["context_files"] = "small.md"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "small.md"), []byte("context line\n"), 0644))
+	step.Config["prompt"] = "Fix these failures:\n{{ $prev_output }}"
+	_, err = adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	captured, err = os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(captured), 1000)
+	assert.Contains(t, string(captured), "[previous step output truncated from 2000 characters")
+	assert.Contains(t, string(captured), "context line")
+	assert.NotContains(t, string(captured), "[project context truncated")
+
+	// The cap counts characters, and cuts never split one.
+	adapter.PrevOutput = ""
+	step.Config["prompt"] = "Implement the feature."
+	step.Config["context_files"] = "wide.md"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wide.md"), []byte(strings.Repeat("é", 1500)), 0644))
+	_, err = adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	captured, err = os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.True(t, utf8.Valid(captured))
+	assert.LessOrEqual(t, utf8.RuneCount(captured), 1000)
+	assert.Greater(t, len(captured), 1000, "a byte count would have cut more than needed")
+	assert.Contains(t, string(captured), "[project context truncated from")
+
+	step.Config["max_prompt_chars"] = "lots"
+	_, err = adapter.Execute(context.Background(), step, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `max_prompt_chars must be a positive integer, got "lots"`)
+}
+
+func TestPromptAdapter_ExpandsParams(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "review.md"), []byte("Review the code under {{param.target}}."), 0644))
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", "cat > captured_prompt.txt && echo ok"},
+	}
+
+	step := &domain.Step{
+		Name:    "review",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success"},
+		Config:  map[string]string{"prompt": `file("review.md")`},
+	}
+	step.ApplyParams(map[string]string{"target": "internal/dsl"})
+
+	_, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+
+	captured, err := os.ReadFile(filepath.Join(dir, "captured_prompt.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(captured), "Review the code under internal/dsl.")
+}
+
 func TestPromptAdapter_StdoutMarkerSelectsResult(t *testing.T) {
 	dir := t.TempDir()
 
@@ -624,6 +1039,32 @@ func TestPromptAdapter_ExtraEnvPropagatedToAgent(t *testing.T) {
 	assert.Contains(t, string(data), "RUN=run-99")
 }
 
+func TestPromptAdapter_ExpandsEnvInCommandAndArgsAtRunTime(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLOCHE_TEST_SHELL", "sh")
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"${CLOCHE_TEST_SHELL}"},
+		ExplicitArgs: []string{"-c", "cat > /dev/null && echo \"model=$1\" > args.txt && echo CLOCHE_RESULT:success", "agent", "${CLOCHE_TEST_MODEL}"},
+		ExtraEnv:     []string{"CLOCHE_TEST_MODEL=opus"},
+	}
+
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"prompt": "Do something."},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "success", sr.Result)
+
+	data, err := os.ReadFile(filepath.Join(dir, "args.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "model=opus\n", string(data))
+}
+
 func TestParseCommands(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -643,3 +1084,73 @@ func TestParseCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestPromptAdapter_StripsANSIFromCapturedOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", `cat > /dev/null && printf '\033[1mthinking\033[0m\n\033[32mCLOCHE_RESULT:needs_research\033[0m\n'`},
+	}
+	step := &domain.Step{
+		Name:    "analyze",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail", "needs_research"},
+		Config:  map[string]string{"prompt": "Analyze the code."},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "needs_research", sr.Result, "colored marker should still select the result")
+
+	data, err := os.ReadFile(filepath.Join(dir, ".cloche", "output", "analyze.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "thinking\nCLOCHE_RESULT:needs_research\n", string(data))
+}
+
+func TestPromptAdapter_StreamingCollapsesProgress(t *testing.T) {
+	dir := t.TempDir()
+
+	var statusBuf bytes.Buffer
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", `cat > /dev/null && printf '\033[36m.\033[0m\r\033[36m..\033[0m\r\033[36m...\033[0m\n...\n\033[32mCLOCHE_RESULT:success\033[0m\n'`},
+		StatusWriter: protocol.NewStatusWriter(&statusBuf),
+	}
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"prompt": "Build it.", "output_filter": "collapse"},
+	}
+
+	sr, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "success", sr.Result)
+
+	data, err := os.ReadFile(filepath.Join(dir, ".cloche", "output", "implement.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "...\nCLOCHE_RESULT:success\n", string(data))
+}
+
+func TestPromptAdapter_RawOutputFilterKeepsANSI(t *testing.T) {
+	dir := t.TempDir()
+
+	adapter := &prompt.Adapter{
+		Commands:     []string{"sh"},
+		ExplicitArgs: []string{"-c", `cat > /dev/null && printf '\033[1mbold\033[0m\n'`},
+	}
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"prompt": "Build it.", "output_filter": "raw"},
+	}
+
+	_, err := adapter.Execute(context.Background(), step, dir)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, ".cloche", "output", "implement.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "\x1b[1mbold\x1b[0m\n", string(data))
+}
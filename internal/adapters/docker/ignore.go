@@ -16,6 +16,35 @@ type ignorePattern struct {
 	matchBase bool   // no / in pattern → match against basename in any dir
 }
 
+// defaultIgnoreRules keep everything cloche stores under .cloche out of
+// every container copy except the project's workflow definitions and config,
+// so an agent never sees the saved prompts, engine logs, output or evolution
+// transcripts of other runs. The current run's prompt is written into the
+// container separately and its run directory is bind-mounted. The rules apply
+// before .clocheignore, so a project can re-include a path with "!".
+var defaultIgnoreRules = []string{
+	"/.cloche/*",
+	"!/.cloche/*.cloche",
+	"!/.cloche/config.toml",
+	"!/.cloche/prompts/",
+	"!/.cloche/scripts/",
+	"/.gitworktrees/",
+}
+
+// containerIgnorePatterns returns the patterns that filter the project copy
+// into a container: defaultIgnoreRules followed by the project's .clocheignore.
+func containerIgnorePatterns(projectDir string) ([]ignorePattern, error) {
+	var patterns []ignorePattern
+	for _, rule := range defaultIgnoreRules {
+		patterns = append(patterns, parseIgnoreRule(rule))
+	}
+	project, err := parseClocheignore(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	return append(patterns, project...), nil
+}
+
 // parseClocheignore reads a .clocheignore file and returns the parsed patterns.
 // Returns nil (no error) if the file does not exist.
 func parseClocheignore(projectDir string) ([]ignorePattern, error) {
@@ -36,38 +65,42 @@ func parseClocheignore(projectDir string) ([]ignorePattern, error) {
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
+		patterns = append(patterns, parseIgnoreRule(line))
+	}
+	return patterns, scanner.Err()
+}
 
-		p := ignorePattern{}
-
-		if strings.HasPrefix(line, "!") {
-			p.negated = true
-			line = line[1:]
-		}
+// parseIgnoreRule parses one non-blank, non-comment .clocheignore line.
+func parseIgnoreRule(line string) ignorePattern {
+	p := ignorePattern{}
 
-		if strings.HasSuffix(line, "/") {
-			p.dirOnly = true
-			line = strings.TrimRight(line, "/")
-		}
+	if strings.HasPrefix(line, "!") {
+		p.negated = true
+		line = line[1:]
+	}
 
-		// Leading "/" anchors to root; remove it for matching since
-		// we'll match against paths relative to root.
-		if strings.HasPrefix(line, "/") {
-			p.anchored = true
-			line = line[1:]
-		}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
 
-		// If the pattern contains a slash (after stripping leading/trailing),
-		// it's anchored. Otherwise it can match at any depth.
-		if strings.Contains(line, "/") {
-			p.anchored = true
-		} else if !p.anchored {
-			p.matchBase = true
-		}
+	// Leading "/" anchors to root; remove it for matching since
+	// we'll match against paths relative to root.
+	if strings.HasPrefix(line, "/") {
+		p.anchored = true
+		line = line[1:]
+	}
 
-		p.pattern = line
-		patterns = append(patterns, p)
+	// If the pattern contains a slash (after stripping leading/trailing),
+	// it's anchored. Otherwise it can match at any depth.
+	if strings.Contains(line, "/") {
+		p.anchored = true
+	} else if !p.anchored {
+		p.matchBase = true
 	}
-	return patterns, scanner.Err()
+
+	p.pattern = line
+	return p
 }
 
 // isIgnored checks whether a relative path should be excluded.
@@ -162,3 +162,35 @@ func TestIsIgnored_Empty(t *testing.T) {
 	assert.False(t, isIgnored(nil, "anything", false))
 	assert.False(t, isIgnored(nil, "anything", true))
 }
+
+func TestContainerIgnorePatterns_ExcludesRunArtifacts(t *testing.T) {
+	dir := t.TempDir()
+
+	patterns, err := containerIgnorePatterns(dir)
+	require.NoError(t, err)
+
+	assert.True(t, isIgnored(patterns, ".cloche/runs", true))
+	assert.True(t, isIgnored(patterns, ".cloche/logs", true))
+	assert.True(t, isIgnored(patterns, ".cloche/output", true))
+	assert.True(t, isIgnored(patterns, ".cloche/a133", true), "per-run directory")
+	assert.True(t, isIgnored(patterns, ".cloche/evolution", true))
+	assert.True(t, isIgnored(patterns, ".cloche/history.log", false))
+	assert.True(t, isIgnored(patterns, ".gitworktrees", true))
+	assert.False(t, isIgnored(patterns, ".cloche/prompts", true))
+	assert.False(t, isIgnored(patterns, ".cloche/scripts", true))
+	assert.False(t, isIgnored(patterns, ".cloche/develop.cloche", false))
+	assert.False(t, isIgnored(patterns, ".cloche/config.toml", false))
+	assert.False(t, isIgnored(patterns, "src/output", true), "only .cloche output is excluded")
+}
+
+func TestContainerIgnorePatterns_ClocheignoreCanReinclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".clocheignore"), []byte("!/.cloche/logs/\n*.tmp\n"), 0644))
+
+	patterns, err := containerIgnorePatterns(dir)
+	require.NoError(t, err)
+
+	assert.False(t, isIgnored(patterns, ".cloche/logs", true))
+	assert.True(t, isIgnored(patterns, ".cloche/runs", true))
+	assert.True(t, isIgnored(patterns, "scratch.tmp", false))
+}
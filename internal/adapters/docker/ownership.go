@@ -0,0 +1,103 @@
+package docker
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// runningAsRoot reports whether the daemon runs as root, the only case in
+// which files it creates can end up owned by someone other than the project's
+// user.
+func runningAsRoot() bool { return os.Geteuid() == 0 }
+
+// MkdirOwned creates dir and any missing parents with mode 0755, like
+// os.MkdirAll. When the daemon runs as root, each directory it creates takes
+// the owner of its nearest existing ancestor, so run artifacts under a user's
+// .cloche stay removable by that user. Calling it on an existing directory is
+// a no-op.
+func MkdirOwned(dir string) error {
+	var created []string
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		created = append(created, existing)
+		existing = parent
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if len(created) == 0 || !runningAsRoot() {
+		return nil
+	}
+	uid, gid, ok := ownerOf(existing)
+	if !ok {
+		return nil
+	}
+	for _, d := range created {
+		if err := os.Lchown(d, uid, gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdoptOwnership makes everything under dir (not dir itself) owned by dir's
+// owner when the daemon runs as root, and ensures the owner can read and write
+// every file and traverse every directory. Used after copying files out of a
+// container, whose modes are preserved from inside it.
+func AdoptOwnership(dir string) error {
+	uid, gid, ok := ownerOf(dir)
+	if !ok {
+		return nil
+	}
+	chown := runningAsRoot()
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if chown {
+			if err := os.Lchown(path, uid, gid); err != nil {
+				return err
+			}
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		want := info.Mode().Perm() | 0600
+		if d.IsDir() {
+			want |= 0700
+		}
+		if want != info.Mode().Perm() {
+			return os.Chmod(path, want)
+		}
+		return nil
+	})
+}
+
+// ownerOf returns the uid and gid owning path.
+func ownerOf(path string) (uid, gid int, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
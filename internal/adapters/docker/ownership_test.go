@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// skipUnlessRoot skips tests that chown to another user.
+func skipUnlessRoot(t *testing.T) {
+	t.Helper()
+	if !runningAsRoot() {
+		t.Skip("requires root to change file ownership")
+	}
+}
+
+func assertOwner(t *testing.T, path string, uid, gid int) {
+	t.Helper()
+	gotUID, gotGID, ok := ownerOf(path)
+	require.True(t, ok, path)
+	assert.Equal(t, uid, gotUID, "uid of %s", path)
+	assert.Equal(t, gid, gotGID, "gid of %s", path)
+}
+
+func TestMkdirOwned_NewDirsTakeAncestorOwner(t *testing.T) {
+	skipUnlessRoot(t)
+	project := t.TempDir()
+	require.NoError(t, os.Chown(project, 4242, 4343))
+
+	dir := filepath.Join(project, ".cloche", "run-1", "output")
+	require.NoError(t, MkdirOwned(dir))
+
+	assertOwner(t, filepath.Join(project, ".cloche"), 4242, 4343)
+	assertOwner(t, filepath.Join(project, ".cloche", "run-1"), 4242, 4343)
+	assertOwner(t, dir, 4242, 4343)
+}
+
+func TestMkdirOwned_LeavesExistingDirsAlone(t *testing.T) {
+	skipUnlessRoot(t)
+	project := t.TempDir()
+	existing := filepath.Join(project, ".cloche")
+	require.NoError(t, os.Mkdir(existing, 0755))
+	uid, gid, _ := ownerOf(existing)
+	require.NoError(t, os.Chown(project, 4242, 4343))
+
+	require.NoError(t, MkdirOwned(existing))
+	assertOwner(t, existing, uid, gid)
+}
+
+func TestAdoptOwnership_FixesCopiedTree(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	file := filepath.Join(dir, "sub", "implement.log")
+	require.NoError(t, os.WriteFile(file, []byte("output"), 0644))
+	require.NoError(t, os.Chmod(file, 0200))
+
+	if runningAsRoot() {
+		require.NoError(t, os.Chown(dir, 4242, 4343))
+	}
+	require.NoError(t, AdoptOwnership(dir))
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm()&0600, "owner can read and write")
+	if runningAsRoot() {
+		assertOwner(t, filepath.Join(dir, "sub"), 4242, 4343)
+		assertOwner(t, file, 4242, 4343)
+	}
+}
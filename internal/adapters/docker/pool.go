@@ -38,13 +38,16 @@ type ContainerSession struct {
 	mu      sync.Mutex
 	send    func(*pb.DaemonMessage) error
 	pending map[string]chan *pb.StepResult
+	started map[string]func() // request ID -> callback for the agent's StepStarted
 }
 
 // ExecuteStep sends an ExecuteStep command to the in-container agent and blocks
 // until the StepResult is received or the context is cancelled. When resume is
 // true, the ExecuteStep message carries the resume flag so the agent continues
-// an existing LLM conversation rather than starting a fresh one.
-func (cs *ContainerSession) ExecuteStep(ctx context.Context, step *domain.Step, resume bool) (domain.StepResult, error) {
+// an existing LLM conversation rather than starting a fresh one. started, if
+// not nil, is called when the agent reports that the step began executing; the
+// agent may queue a step behind others before it starts.
+func (cs *ContainerSession) ExecuteStep(ctx context.Context, step *domain.Step, resume bool, started func()) (domain.StepResult, error) {
 	reqID := generateRequestID()
 	ch := make(chan *pb.StepResult, 1)
 
@@ -53,11 +56,18 @@ func (cs *ContainerSession) ExecuteStep(ctx context.Context, step *domain.Step,
 		cs.pending = make(map[string]chan *pb.StepResult)
 	}
 	cs.pending[reqID] = ch
+	if started != nil {
+		if cs.started == nil {
+			cs.started = make(map[string]func())
+		}
+		cs.started[reqID] = started
+	}
 	cs.mu.Unlock()
 
 	defer func() {
 		cs.mu.Lock()
 		delete(cs.pending, reqID)
+		delete(cs.started, reqID)
 		cs.mu.Unlock()
 	}()
 
@@ -116,6 +126,18 @@ func (cs *ContainerSession) failAllPending() {
 	}
 }
 
+// deliverStarted runs the started callback registered for a request ID.
+func (cs *ContainerSession) deliverStarted(reqID string) {
+	cs.mu.Lock()
+	started := cs.started[reqID]
+	delete(cs.started, reqID)
+	cs.mu.Unlock()
+
+	if started != nil {
+		started()
+	}
+}
+
 // deliverResult routes a StepResult to the pending channel for its request ID.
 func (cs *ContainerSession) deliverResult(result *pb.StepResult) {
 	cs.mu.Lock()
@@ -449,6 +471,23 @@ func (p *ContainerPool) DeliverResult(containerID string, result *pb.StepResult)
 	entry.sessions[0].deliverResult(result)
 }
 
+// DeliverStarted tells the session that issued the matching ExecuteStep
+// request that the agent has started executing the step.
+func (p *ContainerPool) DeliverStarted(containerID, reqID string) {
+	p.mu.Lock()
+	attemptID := p.resolveAttempt(containerID)
+	var entry *poolEntry
+	if attemptID != "" {
+		entry = p.attempts[attemptID]
+	}
+	p.mu.Unlock()
+
+	if entry == nil || len(entry.sessions) == 0 {
+		return
+	}
+	entry.sessions[0].deliverStarted(reqID)
+}
+
 // FailPendingRequests sends a synthetic "fail" result to all pending ExecuteStep
 // requests for the given container, unblocking any callers waiting on those
 // channels. This is called when an agent session disconnects unexpectedly.
@@ -109,6 +109,13 @@ func (f *fakeRuntime) Inspect(_ context.Context, _ string) (*ports.ContainerStat
 	return &ports.ContainerStatus{Running: true}, nil
 }
 
+func (f *fakeRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (f *fakeRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (f *fakeRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, nil
 }
@@ -466,7 +473,7 @@ func TestContainerPool_FailPendingRequests_UnblocksCallers(t *testing.T) {
 	// Start an ExecuteStep in a goroutine — it will block waiting for a result.
 	done := make(chan error, 1)
 	go func() {
-		_, execErr := sess.ExecuteStep(context.Background(), &domain.Step{Name: "s1", Type: domain.StepTypeScript}, false)
+		_, execErr := sess.ExecuteStep(context.Background(), &domain.Step{Name: "s1", Type: domain.StepTypeScript}, false, nil)
 		done <- execErr
 	}()
 
@@ -484,6 +491,42 @@ func TestContainerPool_FailPendingRequests_UnblocksCallers(t *testing.T) {
 	}
 }
 
+func TestContainerPool_DeliverStarted_RunsStartedCallback(t *testing.T) {
+	rt := &fakeRuntime{}
+	pool := docker.NewContainerPool(rt)
+	ctx := context.Background()
+
+	// Capture the request ID of the dispatched ExecuteStep.
+	reqIDs := make(chan string, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		id := rt.lastStarted()
+		pool.NotifyReadyWithStream(id, func(msg *pb.DaemonMessage) error {
+			reqIDs <- msg.GetExecuteStep().GetRequestId()
+			return nil
+		})
+	}()
+
+	sess, err := pool.SessionFor(ctx, "att-started", ports.ContainerConfig{Image: "img"})
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	go func() {
+		_, _ = sess.ExecuteStep(ctx, &domain.Step{Name: "s1", Type: domain.StepTypeAgent}, false, func() { close(started) })
+	}()
+
+	reqID := <-reqIDs
+	pool.DeliverStarted(sess.ContainerID, "req-other")
+	pool.DeliverStarted(sess.ContainerID, reqID)
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("started callback not called after DeliverStarted")
+	}
+	pool.FailPendingRequests(sess.ContainerID)
+}
+
 func TestContainerPool_FailPendingRequests_UnknownContainerIsNoop(t *testing.T) {
 	rt := &fakeRuntime{}
 	pool := docker.NewContainerPool(rt)
@@ -0,0 +1,67 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// dockerCLI is a package-private hook that runs one docker CLI invocation and
+// returns its stdout and stderr, so tests can fake transient daemon failures.
+var dockerCLI = func(ctx context.Context, args ...string) (stdout, stderr string, err error) {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	err = cmd.Run()
+	return out.String(), errOut.String(), err
+}
+
+// dockerRetryBackoff is the wait before each retry of a transient docker
+// failure; its length bounds the number of retries.
+var dockerRetryBackoff = []time.Duration{500 * time.Millisecond, 2 * time.Second, 5 * time.Second}
+
+// transientDockerErrors are stderr fragments (lowercased) of the message the
+// docker CLI prints when it cannot open a connection to the daemon. The
+// request was never sent, so even a non-idempotent command like "docker
+// create" is safe to repeat. Network errors the daemon relays from elsewhere
+// (e.g. "connection reset by peer" from a registry during a pull) are not
+// listed: the daemon has already acted on those requests.
+var transientDockerErrors = []string{
+	"cannot connect to the docker daemon",
+}
+
+// isTransientDockerError reports whether stderr from a failed docker command
+// says the daemon could not be reached, as opposed to a failure the daemon
+// reported, such as a missing image or a bad flag.
+func isTransientDockerError(stderr string) bool {
+	s := strings.ToLower(stderr)
+	for _, frag := range transientDockerErrors {
+		if strings.Contains(s, frag) {
+			return true
+		}
+	}
+	return false
+}
+
+// runDocker runs docker with args, retrying with backoff while the failure is
+// transient. Any other failure, or running out of retries, returns the last
+// attempt's output and error. Cancelling ctx stops the retries.
+func runDocker(ctx context.Context, args ...string) (stdout, stderr string, err error) {
+	for attempt := 0; ; attempt++ {
+		stdout, stderr, err = dockerCLI(ctx, args...)
+		if err == nil || attempt >= len(dockerRetryBackoff) || !isTransientDockerError(stderr) {
+			return stdout, stderr, err
+		}
+		log.Printf("docker %s: transient failure (attempt %d of %d), retrying: %s",
+			args[0], attempt+1, len(dockerRetryBackoff)+1, strings.TrimSpace(stderr))
+		select {
+		case <-ctx.Done():
+			return stdout, stderr, err
+		case <-time.After(dockerRetryBackoff[attempt]):
+		}
+	}
+}
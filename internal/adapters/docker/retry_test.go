@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDockerCLI replaces the dockerCLI hook with one that returns results in
+// order, and disables the retry backoff. It returns a pointer to the call count.
+func fakeDockerCLI(t *testing.T, results ...func() (string, string, error)) *int {
+	t.Helper()
+	origCLI, origBackoff := dockerCLI, dockerRetryBackoff
+	calls := 0
+	dockerCLI = func(_ context.Context, _ ...string) (string, string, error) {
+		r := results[len(results)-1]
+		if calls < len(results) {
+			r = results[calls]
+		}
+		calls++
+		return r()
+	}
+	dockerRetryBackoff = []time.Duration{0, 0, 0}
+	t.Cleanup(func() { dockerCLI, dockerRetryBackoff = origCLI, origBackoff })
+	return &calls
+}
+
+func daemonUnreachable() (string, string, error) {
+	return "", "Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?\n", errors.New("exit status 1")
+}
+
+func TestRuntime_RetriesTransientDaemonFailure(t *testing.T) {
+	calls := fakeDockerCLI(t,
+		daemonUnreachable,
+		func() (string, string, error) { return "0\n", "", nil },
+	)
+
+	code, err := (&Runtime{}).Wait(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestRuntime_DoesNotRetryRealFailure(t *testing.T) {
+	calls := fakeDockerCLI(t, func() (string, string, error) {
+		return "", "Error response from daemon: No such image: nope:latest\n", errors.New("exit status 1")
+	})
+
+	err := (&Runtime{}).Pull(context.Background(), "nope:latest")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No such image")
+	assert.Equal(t, 1, *calls)
+}
+
+func TestRuntime_DoesNotRetryErrorRelayedByDaemon(t *testing.T) {
+	// The daemon reached the registry and relays its network error; the
+	// request was handled, so it is not a connection failure to retry.
+	calls := fakeDockerCLI(t, func() (string, string, error) {
+		return "", "Error response from daemon: Get \"https://registry-1.docker.io/v2/\": read tcp 10.0.0.2:51234->1.2.3.4:443: read: connection reset by peer\n", errors.New("exit status 1")
+	})
+
+	err := (&Runtime{}).Pull(context.Background(), "alpine:latest")
+	require.Error(t, err)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestRuntime_RetriesAreBounded(t *testing.T) {
+	calls := fakeDockerCLI(t, daemonUnreachable)
+
+	err := (&Runtime{}).Remove(context.Background(), "abc123")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Cannot connect to the Docker daemon")
+	assert.Equal(t, len(dockerRetryBackoff)+1, *calls)
+}
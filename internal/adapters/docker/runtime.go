@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -31,7 +32,121 @@ func (r *Runtime) Start(ctx context.Context, cfg ports.ContainerConfig) (string,
 	startTime := time.Now()
 	log.Printf("runtime.Start: creating container (image=%s workflow=%s attempt=%s)", cfg.Image, cfg.WorkflowName, cfg.AttemptID)
 
-	// Build docker create args
+	args := createArgs(cfg)
+
+	// docker create
+	stdout, stderr, err := runDocker(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("creating container: %s: %w", stderr, err)
+	}
+	containerID := strings.TrimSpace(stdout)
+	log.Printf("runtime.Start: container created %s (%.1fs)", containerID, time.Since(startTime).Seconds())
+
+	// 3. Copy project files into container, leaving out cloche's own run
+	//    artifacts and anything in .clocheignore
+	if cfg.ProjectDir != "" {
+		t := time.Now()
+		log.Printf("runtime.Start: copying project files into %s", containerID)
+		patterns, err := containerIgnorePatterns(cfg.ProjectDir)
+		if err != nil {
+			exec.CommandContext(ctx, "docker", "rm", "-f", containerID).Run()
+			return "", fmt.Errorf("parsing .clocheignore: %w", err)
+		}
+
+		if err := copyProjectToContainer(ctx, cfg.ProjectDir, containerID, patterns); err != nil {
+			exec.CommandContext(ctx, "docker", "rm", "-f", containerID).Run()
+			return "", err
+		}
+		log.Printf("runtime.Start: project copy done for %s (%.1fs)", containerID, time.Since(t).Seconds())
+
+		// Apply override files from .cloche/overrides/ on top of workspace
+		overridesDir := filepath.Join(cfg.ProjectDir, ".cloche", "overrides")
+		if _, err := os.Stat(overridesDir); err == nil {
+			log.Printf("runtime.Start: applying overrides for %s", containerID)
+			overrideCmd := exec.CommandContext(ctx, "docker", "cp", overridesDir+"/.", containerID+":/workspace/")
+			var cpStderr bytes.Buffer
+			overrideCmd.Stderr = &cpStderr
+			if err := overrideCmd.Run(); err != nil {
+				// Non-fatal: log but don't fail the run
+				fmt.Fprintf(os.Stderr, "warning: copying overrides: %s\n", cpStderr.String())
+			}
+			log.Printf("runtime.Start: overrides done for %s", containerID)
+		}
+	}
+
+	// 3b. Write prompt into container (.cloche/runs/ is excluded from the
+	//      project copy, so prompt.txt must be injected separately).
+	if cfg.Prompt != "" && cfg.TaskID != "" {
+		log.Printf("runtime.Start: writing prompt for %s", containerID)
+		promptDir := filepath.Join(os.TempDir(), "cloche-prompt-"+cfg.RunID)
+		runsDir := filepath.Join(promptDir, ".cloche", "runs", cfg.TaskID)
+		if err := os.MkdirAll(runsDir, 0755); err == nil {
+			_ = os.WriteFile(filepath.Join(runsDir, "prompt.txt"), []byte(cfg.Prompt), 0644)
+			cpCmd := exec.CommandContext(ctx, "docker", "cp", promptDir+"/.", containerID+":/workspace/")
+			_ = cpCmd.Run()
+			os.RemoveAll(promptDir)
+		}
+		log.Printf("runtime.Start: prompt done for %s", containerID)
+	}
+
+	// 4. Copy Claude auth files into container (each gets its own copy).
+	// Only copy auth-relevant files — not the full ~/.claude directory
+	// which contains large history, session, and debug data.
+	log.Printf("runtime.Start: copying auth files for %s", containerID)
+	if home, err := os.UserHomeDir(); err == nil {
+		claudeDir := home + "/.claude"
+		// Stage auth files in a temp directory, then docker cp the
+		// directory into the container. This avoids needing `docker exec`
+		// (container isn't running yet) and ensures the directory exists.
+		tmpAuth, tmpErr := os.MkdirTemp("", "cloche-auth")
+		if tmpErr == nil {
+			defer os.RemoveAll(tmpAuth)
+			for _, name := range []string{".credentials.json", "settings.json", "settings.local.json"} {
+				src := filepath.Join(claudeDir, name)
+				if data, err := os.ReadFile(src); err == nil {
+					os.WriteFile(filepath.Join(tmpAuth, name), data, 0644)
+				}
+			}
+			exec.CommandContext(ctx, "docker", "cp", tmpAuth+"/.", containerID+":/home/agent/.claude/").Run()
+		}
+		// ~/.claude.json contains UI state needed by interactive Claude Code
+		// sessions (e.g. "already set up" flag). Copy it for interactive
+		// containers; skip for autonomous runs where it causes cached
+		// rate-limit info to leak between containers.
+		if cfg.Interactive {
+			claudeJSON := home + "/.claude.json"
+			if _, statErr := os.Stat(claudeJSON); statErr == nil {
+				exec.CommandContext(ctx, "docker", "cp", claudeJSON, containerID+":/home/agent/.claude.json").Run()
+			}
+		}
+	}
+	log.Printf("runtime.Start: auth copy done for %s", containerID)
+
+	// 5. Start the container (skip for interactive — Attach handles start).
+	if !cfg.Interactive {
+		log.Printf("runtime.Start: starting container %s", containerID)
+		if _, startStderr, err := runDocker(ctx, "start", containerID); err != nil {
+			exec.CommandContext(ctx, "docker", "rm", "-f", containerID).Run()
+			return "", fmt.Errorf("starting container: %s: %w", startStderr, err)
+		}
+
+		// Verify the container actually transitioned to running. docker start
+		// can return success while the container remains in "created" state on
+		// some hosts. Catching this here surfaces a concrete error instead of
+		// letting SessionFor hang for the full step timeout.
+		log.Printf("runtime.Start: verifying container %s reached running state", containerID)
+		if err := r.waitForRunning(ctx, containerID); err != nil {
+			exec.CommandContext(context.Background(), "docker", "rm", "-f", containerID).Run()
+			return "", fmt.Errorf("container %s: %w", containerID, err)
+		}
+	}
+
+	log.Printf("runtime.Start: container %s ready (total %.1fs)", containerID, time.Since(startTime).Seconds())
+	return containerID, nil
+}
+
+// createArgs builds the "docker create" argument list for cfg.
+func createArgs(cfg ports.ContainerConfig) []string {
 	containerCmd := cfg.Cmd
 	useDefaultCmd := len(containerCmd) == 0
 	if useDefaultCmd {
@@ -51,6 +166,10 @@ func (r *Runtime) Start(ctx context.Context, cfg ports.ContainerConfig) (string,
 		args = append(args, "-i", "-t")
 	}
 
+	for _, label := range cfg.Labels {
+		args = append(args, "--label", label)
+	}
+
 	// Name container uniquely. Use task-attempt-workflow when available
 	// (allows concurrent runs of the same workflow), fall back to run ID.
 	containerName := cfg.RunID
@@ -61,7 +180,7 @@ func (r *Runtime) Start(ctx context.Context, cfg ports.ContainerConfig) (string,
 		args = append(args, "--name", containerName)
 	}
 
-	// Pass run ID, task ID, and attempt ID into container
+	// Pass run ID, task ID, attempt ID, and workflow name into container
 	if cfg.RunID != "" {
 		args = append(args, "-e", "CLOCHE_RUN_ID="+cfg.RunID)
 	}
@@ -71,6 +190,19 @@ func (r *Runtime) Start(ctx context.Context, cfg ports.ContainerConfig) (string,
 	if cfg.AttemptID != "" {
 		args = append(args, "-e", "CLOCHE_ATTEMPT_ID="+cfg.AttemptID)
 	}
+	if cfg.WorkflowName != "" {
+		args = append(args, "-e", "CLOCHE_WORKFLOW_NAME="+cfg.WorkflowName)
+	}
+	if cfg.PromptChars > 0 {
+		args = append(args, "-e", "CLOCHE_PROMPT_CHARS="+strconv.Itoa(cfg.PromptChars))
+	}
+	if len(cfg.Params) > 0 {
+		data, _ := json.Marshal(cfg.Params)
+		args = append(args, "-e", "CLOCHE_PARAMS="+string(data))
+	}
+	if cfg.SavePrompts {
+		args = append(args, "-e", "CLOCHE_SAVE_PROMPTS=1")
+	}
 	// Pass ANTHROPIC_API_KEY into container if set
 	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
 		args = append(args, "-e", "ANTHROPIC_API_KEY")
@@ -94,13 +226,13 @@ func (r *Runtime) Start(ctx context.Context, cfg ports.ContainerConfig) (string,
 	// Bind-mount the host run directory (.cloche/runs/<run-id>) into the container
 	// so that files written by host workflow steps (e.g. task_prompt.md written by
 	// prepare-prompt.sh) are accessible to container steps via clo get task_prompt_path.
-	// .cloche/runs/ is excluded from the project copy by .clocheignore, so without
+	// .cloche/runs/ is always excluded from the project copy, so without
 	// this mount those files would be invisible inside the container.
 	// ProjectDir is empty for resume containers (committed image has workspace state);
 	// skip the mount in that case.
 	if cfg.ProjectDir != "" && cfg.RunID != "" {
 		hostRunDir := filepath.Join(cfg.ProjectDir, ".cloche", "runs", cfg.RunID)
-		if err := os.MkdirAll(hostRunDir, 0755); err == nil {
+		if err := MkdirOwned(hostRunDir); err == nil {
 			args = append(args, "-v", hostRunDir+":/workspace/.cloche/runs/"+cfg.RunID)
 		}
 	}
@@ -123,7 +255,12 @@ func (r *Runtime) Start(ctx context.Context, cfg ports.ContainerConfig) (string,
 		}
 	}
 
-	// No --network none: agent needs network for git push and API access
+	// Containers are networked unless the workflow or the daemon's
+	// default_network asks otherwise: agents need the network for git push
+	// and API access.
+	if cfg.Network != "" {
+		args = append(args, "--network", cfg.Network)
+	}
 
 	if useDefaultCmd {
 		// Start as root to fix ownership of docker-cp'd files, then drop
@@ -160,120 +297,7 @@ func (r *Runtime) Start(ctx context.Context, cfg ports.ContainerConfig) (string,
 		args = append(args, containerCmd...)
 	}
 
-	// docker create
-	createCmd := exec.CommandContext(ctx, "docker", args...)
-	var stdout, stderr bytes.Buffer
-	createCmd.Stdout = &stdout
-	createCmd.Stderr = &stderr
-	if err := createCmd.Run(); err != nil {
-		return "", fmt.Errorf("creating container: %s: %w", stderr.String(), err)
-	}
-	containerID := strings.TrimSpace(stdout.String())
-	log.Printf("runtime.Start: container created %s (%.1fs)", containerID, time.Since(startTime).Seconds())
-
-	// 3. Copy project files into container, respecting .clocheignore
-	if cfg.ProjectDir != "" {
-		t := time.Now()
-		log.Printf("runtime.Start: copying project files into %s", containerID)
-		patterns, err := parseClocheignore(cfg.ProjectDir)
-		if err != nil {
-			exec.CommandContext(ctx, "docker", "rm", "-f", containerID).Run()
-			return "", fmt.Errorf("parsing .clocheignore: %w", err)
-		}
-
-		if err := copyProjectToContainer(ctx, cfg.ProjectDir, containerID, patterns); err != nil {
-			exec.CommandContext(ctx, "docker", "rm", "-f", containerID).Run()
-			return "", err
-		}
-		log.Printf("runtime.Start: project copy done for %s (%.1fs)", containerID, time.Since(t).Seconds())
-
-		// Apply override files from .cloche/overrides/ on top of workspace
-		overridesDir := filepath.Join(cfg.ProjectDir, ".cloche", "overrides")
-		if _, err := os.Stat(overridesDir); err == nil {
-			log.Printf("runtime.Start: applying overrides for %s", containerID)
-			overrideCmd := exec.CommandContext(ctx, "docker", "cp", overridesDir+"/.", containerID+":/workspace/")
-			var cpStderr bytes.Buffer
-			overrideCmd.Stderr = &cpStderr
-			if err := overrideCmd.Run(); err != nil {
-				// Non-fatal: log but don't fail the run
-				fmt.Fprintf(os.Stderr, "warning: copying overrides: %s\n", cpStderr.String())
-			}
-			log.Printf("runtime.Start: overrides done for %s", containerID)
-		}
-	}
-
-	// 3b. Write prompt into container (.cloche/runs/ is excluded by .clocheignore,
-	//      so prompt.txt must be injected separately).
-	if cfg.Prompt != "" && cfg.TaskID != "" {
-		log.Printf("runtime.Start: writing prompt for %s", containerID)
-		promptDir := filepath.Join(os.TempDir(), "cloche-prompt-"+cfg.RunID)
-		runsDir := filepath.Join(promptDir, ".cloche", "runs", cfg.TaskID)
-		if err := os.MkdirAll(runsDir, 0755); err == nil {
-			_ = os.WriteFile(filepath.Join(runsDir, "prompt.txt"), []byte(cfg.Prompt), 0644)
-			cpCmd := exec.CommandContext(ctx, "docker", "cp", promptDir+"/.", containerID+":/workspace/")
-			_ = cpCmd.Run()
-			os.RemoveAll(promptDir)
-		}
-		log.Printf("runtime.Start: prompt done for %s", containerID)
-	}
-
-	// 4. Copy Claude auth files into container (each gets its own copy).
-	// Only copy auth-relevant files — not the full ~/.claude directory
-	// which contains large history, session, and debug data.
-	log.Printf("runtime.Start: copying auth files for %s", containerID)
-	if home, err := os.UserHomeDir(); err == nil {
-		claudeDir := home + "/.claude"
-		// Stage auth files in a temp directory, then docker cp the
-		// directory into the container. This avoids needing `docker exec`
-		// (container isn't running yet) and ensures the directory exists.
-		tmpAuth, tmpErr := os.MkdirTemp("", "cloche-auth")
-		if tmpErr == nil {
-			defer os.RemoveAll(tmpAuth)
-			for _, name := range []string{".credentials.json", "settings.json", "settings.local.json"} {
-				src := filepath.Join(claudeDir, name)
-				if data, err := os.ReadFile(src); err == nil {
-					os.WriteFile(filepath.Join(tmpAuth, name), data, 0644)
-				}
-			}
-			exec.CommandContext(ctx, "docker", "cp", tmpAuth+"/.", containerID+":/home/agent/.claude/").Run()
-		}
-		// ~/.claude.json contains UI state needed by interactive Claude Code
-		// sessions (e.g. "already set up" flag). Copy it for interactive
-		// containers; skip for autonomous runs where it causes cached
-		// rate-limit info to leak between containers.
-		if cfg.Interactive {
-			claudeJSON := home + "/.claude.json"
-			if _, statErr := os.Stat(claudeJSON); statErr == nil {
-				exec.CommandContext(ctx, "docker", "cp", claudeJSON, containerID+":/home/agent/.claude.json").Run()
-			}
-		}
-	}
-	log.Printf("runtime.Start: auth copy done for %s", containerID)
-
-	// 5. Start the container (skip for interactive — Attach handles start).
-	if !cfg.Interactive {
-		log.Printf("runtime.Start: starting container %s", containerID)
-		startCmd := exec.CommandContext(ctx, "docker", "start", containerID)
-		var startStderr bytes.Buffer
-		startCmd.Stderr = &startStderr
-		if err := startCmd.Run(); err != nil {
-			exec.CommandContext(ctx, "docker", "rm", "-f", containerID).Run()
-			return "", fmt.Errorf("starting container: %s: %w", startStderr.String(), err)
-		}
-
-		// Verify the container actually transitioned to running. docker start
-		// can return success while the container remains in "created" state on
-		// some hosts. Catching this here surfaces a concrete error instead of
-		// letting SessionFor hang for the full step timeout.
-		log.Printf("runtime.Start: verifying container %s reached running state", containerID)
-		if err := r.waitForRunning(ctx, containerID); err != nil {
-			exec.CommandContext(context.Background(), "docker", "rm", "-f", containerID).Run()
-			return "", fmt.Errorf("container %s: %w", containerID, err)
-		}
-	}
-
-	log.Printf("runtime.Start: container %s ready (total %.1fs)", containerID, time.Since(startTime).Seconds())
-	return containerID, nil
+	return args
 }
 
 // waitForRunning polls docker inspect until the container reports Running=true,
@@ -320,12 +344,8 @@ func (r *Runtime) waitForRunning(ctx context.Context, containerID string) error
 }
 
 func (r *Runtime) Stop(ctx context.Context, containerID string) error {
-	cmd := exec.CommandContext(ctx, "docker", "stop", containerID)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("stopping container: %s: %w", stderr.String(), err)
+	if _, stderr, err := runDocker(ctx, "stop", containerID); err != nil {
+		return fmt.Errorf("stopping container: %s: %w", stderr, err)
 	}
 	return nil
 }
@@ -347,28 +367,28 @@ func (r *Runtime) AttachOutput(ctx context.Context, containerID string) (io.Read
 }
 
 func (r *Runtime) Wait(ctx context.Context, containerID string) (int, error) {
-	cmd := exec.CommandContext(ctx, "docker", "wait", containerID)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return -1, fmt.Errorf("waiting for container: %s: %w", stderr.String(), err)
+	stdout, stderr, err := runDocker(ctx, "wait", containerID)
+	if err != nil {
+		return -1, fmt.Errorf("waiting for container: %s: %w", stderr, err)
 	}
 
-	code, err := strconv.Atoi(strings.TrimSpace(stdout.String()))
+	code, err := strconv.Atoi(strings.TrimSpace(stdout))
 	if err != nil {
-		return -1, fmt.Errorf("parsing exit code %q: %w", stdout.String(), err)
+		return -1, fmt.Errorf("parsing exit code %q: %w", stdout, err)
 	}
 	return code, nil
 }
 
 func (r *Runtime) CopyFrom(ctx context.Context, containerID string, srcPath, dstPath string) error {
-	cmd := exec.CommandContext(ctx, "docker", "cp", containerID+":"+srcPath, dstPath)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("copying from container: %s: %w", stderr.String(), err)
+	if _, stderr, err := runDocker(ctx, "cp", containerID+":"+srcPath, dstPath); err != nil {
+		return fmt.Errorf("copying from container: %s: %w", stderr, err)
+	}
+	// Files keep their in-container modes (and, for a root daemon, root
+	// ownership); hand them to whoever owns the destination directory.
+	if info, err := os.Stat(dstPath); err == nil && info.IsDir() {
+		if err := AdoptOwnership(dstPath); err != nil {
+			log.Printf("runtime.CopyFrom: fixing ownership of %s: %v", dstPath, err)
+		}
 	}
 	return nil
 }
@@ -387,29 +407,74 @@ func (r *Runtime) Logs(ctx context.Context, containerID string) (string, error)
 }
 
 func (r *Runtime) Remove(ctx context.Context, containerID string) error {
-	cmd := exec.CommandContext(ctx, "docker", "rm", "-f", containerID)
+	if _, stderr, err := runDocker(ctx, "rm", "-f", containerID); err != nil {
+		return fmt.Errorf("removing container: %s: %w", stderr, err)
+	}
+	return nil
+}
+
+// Pull runs "docker pull" for image. The error carries docker's own message,
+// e.g. that the repository does not exist or requires login.
+func (r *Runtime) Pull(ctx context.Context, image string) error {
+	if _, stderr, err := runDocker(ctx, "pull", "--quiet", image); err != nil {
+		return fmt.Errorf("pulling image %s: %s: %w", image, strings.TrimSpace(stderr), err)
+	}
+	return nil
+}
+
+// Exec runs cmd in the running container's /workspace via "docker exec".
+// docker exec's own exit codes overlap the command's (126 and 127 also mean
+// the command could not be run, as in a shell), so a missing or stopped
+// container is caught by inspecting it first and every exit code after that
+// is the command's.
+func (r *Runtime) Exec(ctx context.Context, containerID string, cmd []string) (string, int, error) {
+	if len(cmd) == 0 {
+		return "", -1, fmt.Errorf("exec: empty command")
+	}
+	status, err := r.Inspect(ctx, containerID)
+	if err != nil {
+		return "", -1, fmt.Errorf("exec in container: %w", err)
+	}
+	if !status.Running {
+		return "", -1, fmt.Errorf("exec in container %s: container is not running", containerID)
+	}
+	args := append([]string{"exec", "-w", "/workspace", containerID}, cmd...)
+	c := exec.CommandContext(ctx, "docker", args...)
+	out, err := c.CombinedOutput()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return string(out), exitErr.ExitCode(), nil
+		}
+		return string(out), -1, fmt.Errorf("exec in container: %w", err)
+	}
+	return string(out), 0, nil
+}
+
+// HasImage reports whether image is available locally.
+func (r *Runtime) HasImage(ctx context.Context, image string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Id}}", image)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("removing container: %s: %w", stderr.String(), err)
+		if strings.Contains(strings.ToLower(stderr.String()), "no such image") {
+			return false, nil
+		}
+		return false, fmt.Errorf("inspecting image %s: %s: %w", image, strings.TrimSpace(stderr.String()), err)
 	}
-	return nil
+	return true, nil
 }
 
 func (r *Runtime) Inspect(ctx context.Context, containerID string) (*ports.ContainerStatus, error) {
-	cmd := exec.CommandContext(ctx, "docker", "inspect",
+	stdout, stderr, err := runDocker(ctx, "inspect",
 		"--format", "{{.State.Running}} {{.State.ExitCode}} {{.State.FinishedAt}}",
 		containerID)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("inspecting container: %s: %w", stderr.String(), err)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting container: %s: %w", stderr, err)
 	}
 
-	parts := strings.SplitN(strings.TrimSpace(stdout.String()), " ", 3)
+	parts := strings.SplitN(strings.TrimSpace(stdout), " ", 3)
 	if len(parts) < 3 {
-		return nil, fmt.Errorf("unexpected inspect output: %s", stdout.String())
+		return nil, fmt.Errorf("unexpected inspect output: %s", stdout)
 	}
 
 	running := parts[0] == "true"
@@ -533,14 +598,14 @@ func (r *Runtime) Attach(ctx context.Context, containerID string) (io.ReadWriteC
 }
 
 // ResizeTerminal resizes the pseudo-TTY of a running interactive container
-// using docker exec stty.
+// by running stty in it with Exec.
 func (r *Runtime) ResizeTerminal(ctx context.Context, containerID string, rows, cols int) error {
-	cmd := exec.CommandContext(ctx, "docker", "exec", containerID,
-		"stty", "rows", strconv.Itoa(rows), "cols", strconv.Itoa(cols))
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("resizing terminal: %s: %w", stderr.String(), err)
+	out, code, err := r.Exec(ctx, containerID, []string{"stty", "rows", strconv.Itoa(rows), "cols", strconv.Itoa(cols)})
+	if err != nil {
+		return fmt.Errorf("resizing terminal: %w", err)
+	}
+	if code != 0 {
+		return fmt.Errorf("resizing terminal: stty exited with code %d: %s", code, strings.TrimSpace(out))
 	}
 	return nil
 }
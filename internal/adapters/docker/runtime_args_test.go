@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/cloche-dev/cloche/internal/ports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateArgs_Labels(t *testing.T) {
+	args := createArgs(ports.ContainerConfig{
+		Image:        "cloche-agent:latest",
+		WorkflowName: "develop",
+		Labels:       []string{"team=infra", "cost-center=ci"},
+	})
+
+	var labels []string
+	for i, a := range args {
+		if a == "--label" && i+1 < len(args) {
+			labels = append(labels, args[i+1])
+		}
+	}
+	assert.Equal(t, []string{"team=infra", "cost-center=ci"}, labels)
+	assert.Equal(t, "create", args[0])
+	assert.Contains(t, args, "cloche-agent:latest")
+}
+
+func TestCreateArgs_NoLabels(t *testing.T) {
+	args := createArgs(ports.ContainerConfig{Image: "cloche-agent:latest", WorkflowName: "develop"})
+	assert.NotContains(t, args, "--label")
+}
+
+func TestCreateArgs_PassesRunIdentity(t *testing.T) {
+	args := createArgs(ports.ContainerConfig{
+		Image:        "cloche-agent:latest",
+		WorkflowName: "develop",
+		RunID:        "run-7",
+	})
+
+	var env []string
+	for i, a := range args {
+		if a == "-e" && i+1 < len(args) {
+			env = append(env, args[i+1])
+		}
+	}
+	assert.Contains(t, env, "CLOCHE_RUN_ID=run-7")
+	assert.Contains(t, env, "CLOCHE_WORKFLOW_NAME=develop")
+}
+
+func TestCreateArgs_Network(t *testing.T) {
+	args := createArgs(ports.ContainerConfig{Image: "cloche-agent:latest", WorkflowName: "develop", Network: "none"})
+	i := indexOf(args, "--network")
+	if assert.GreaterOrEqual(t, i, 0) {
+		assert.Equal(t, "none", args[i+1])
+		assert.Less(t, i, indexOf(args, "cloche-agent:latest"), "--network must precede the image")
+	}
+
+	args = createArgs(ports.ContainerConfig{Image: "cloche-agent:latest", WorkflowName: "develop"})
+	assert.NotContains(t, args, "--network")
+}
+
+func indexOf(args []string, s string) int {
+	for i, a := range args {
+		if a == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -81,3 +81,39 @@ func TestCopyProjectToContainer_HappyPath(t *testing.T) {
 	err := copyProjectToContainer(ctx, dir, containerID, patterns)
 	assert.NoError(t, err)
 }
+
+// TestCopyProjectToContainer_OmitsPriorRunArtifacts verifies that output,
+// logs and prompts left in the project by earlier runs are not copied into a
+// new container, even when the project has no .clocheignore.
+func TestCopyProjectToContainer_OmitsPriorRunArtifacts(t *testing.T) {
+	skipDockerIfUnavailable(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":                              "package main",
+		".cloche/develop.cloche":               "workflow \"develop\" {}",
+		".cloche/logs/task-1/a1/develop.log":   "prior run log",
+		".cloche/runs/task-1/prompt.txt":       "prior run prompt",
+		".cloche/old-run/output/implement.log": "legacy run output",
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	containerID := createScratchContainer(t)
+
+	patterns, err := containerIgnorePatterns(dir)
+	require.NoError(t, err)
+	require.NoError(t, copyProjectToContainer(context.Background(), dir, containerID, patterns))
+
+	exists := func(rel string) bool {
+		return exec.Command("docker", "cp", containerID+":/workspace/"+rel, t.TempDir()).Run() == nil
+	}
+	assert.True(t, exists("main.go"))
+	assert.True(t, exists(".cloche/develop.cloche"))
+	assert.False(t, exists(".cloche/logs"), "prior run's output log must not be copied")
+	assert.False(t, exists(".cloche/runs"), "prior run's prompt must not be copied")
+	assert.False(t, exists(".cloche/old-run/output"), "legacy run output must not be copied")
+}
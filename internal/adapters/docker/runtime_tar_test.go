@@ -246,3 +246,38 @@ func TestIsInsideDir(t *testing.T) {
 		assert.Equal(t, tc.inside, got, "isInsideDir(%q, %q)", tc.path, tc.dir)
 	}
 }
+
+func TestWriteTarFromProject_LeavesOutOtherRunsArtifacts(t *testing.T) {
+	projDir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(projDir, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("main.go", "package main")
+	write(".cloche/develop.cloche", "workflow develop {}")
+	write(".cloche/config.toml", "active = true")
+	write(".cloche/prompts/implement.md", "implement it")
+	write(".cloche/scripts/test.sh", "go test ./...")
+	// A sibling run's saved prompt and engine log.
+	write(".cloche/run-other/prompts/implement-1.txt", "secret prompt")
+	write(".cloche/run-other/engine.log", "engine trace")
+	write(".cloche/evolution/llm/transcript.txt", "transcript")
+	write(".cloche/evolution/log.jsonl", "{}")
+
+	patterns, err := containerIgnorePatterns(projDir)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, writeTarFromProject(tw, projDir, patterns))
+	require.NoError(t, tw.Close())
+
+	files, types := collectTarEntries(t, &buf)
+	for _, name := range []string{"main.go", ".cloche/develop.cloche", ".cloche/config.toml", ".cloche/prompts/implement.md", ".cloche/scripts/test.sh"} {
+		assert.Contains(t, files, name)
+	}
+	for name := range types {
+		assert.NotContains(t, name, "run-other", "another run's artifacts must not be copied")
+		assert.NotContains(t, name, "evolution")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"syscall"
 	"testing"
 
 	"github.com/cloche-dev/cloche/internal/adapters/docker"
@@ -70,6 +71,46 @@ func TestDockerRuntime_Wait(t *testing.T) {
 	assert.Equal(t, 0, exitCode)
 }
 
+func TestDockerRuntime_Exec(t *testing.T) {
+	skipIfNoDocker(t)
+
+	rt, err := docker.NewRuntime()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche"), 0755))
+
+	ctx := context.Background()
+	containerID, err := rt.Start(ctx, ports.ContainerConfig{
+		Image:        "alpine:latest",
+		WorkflowName: "test",
+		ProjectDir:   dir,
+		RunID:        "test-run-exec",
+		Cmd:          []string{"sleep", "30"},
+	})
+	require.NoError(t, err)
+	defer rt.Remove(ctx, containerID)
+
+	out, code, err := rt.Exec(ctx, containerID, []string{"echo", "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "hello\n", out)
+
+	_, code, err = rt.Exec(ctx, containerID, []string{"sh", "-c", "exit 3"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+
+	// 126 and 127 are the command's exit codes too, not docker failures.
+	_, code, err = rt.Exec(ctx, containerID, []string{"sh", "-c", "exit 127"})
+	require.NoError(t, err)
+	assert.Equal(t, 127, code)
+
+	// A container that is gone is an error, not an exit code.
+	require.NoError(t, rt.Remove(ctx, containerID))
+	_, _, err = rt.Exec(ctx, containerID, []string{"echo", "hello"})
+	assert.Error(t, err)
+}
+
 func TestDockerRuntime_FilesPresent(t *testing.T) {
 	skipIfNoDocker(t)
 
@@ -96,3 +137,70 @@ func TestDockerRuntime_FilesPresent(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, exitCode, "cat should succeed — file was copied into container")
 }
+
+func TestDockerRuntime_CopiedOutputIsReadableOnHost(t *testing.T) {
+	skipIfNoDocker(t)
+
+	rt, err := docker.NewRuntime()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche"), 0755))
+
+	ctx := context.Background()
+	// Write output the way an agent might: a private file in a nested dir.
+	containerID, err := rt.Start(ctx, ports.ContainerConfig{
+		Image:        "alpine:latest",
+		WorkflowName: "test",
+		ProjectDir:   dir,
+		RunID:        "test-run-own",
+		Cmd: []string{"sh", "-c", "mkdir -p /workspace/.cloche/output/sub" +
+			" && echo done > /workspace/.cloche/output/sub/implement.log" +
+			" && chmod 200 /workspace/.cloche/output/sub/implement.log"},
+	})
+	require.NoError(t, err)
+	defer rt.Remove(ctx, containerID)
+
+	exitCode, err := rt.Wait(ctx, containerID)
+	require.NoError(t, err)
+	require.Equal(t, 0, exitCode)
+
+	outputDst := filepath.Join(dir, ".cloche", "test-run-own", "output")
+	require.NoError(t, docker.MkdirOwned(outputDst))
+	require.NoError(t, rt.CopyFrom(ctx, containerID, "/workspace/.cloche/output/.", outputDst))
+
+	data, err := os.ReadFile(filepath.Join(outputDst, "sub", "implement.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "done\n", string(data))
+
+	// Everything the run left behind must be removable by the project owner.
+	hostRunDir := filepath.Join(dir, ".cloche", "runs", "test-run-own")
+	want, err := os.Stat(filepath.Join(dir, ".cloche"))
+	require.NoError(t, err)
+	for _, p := range []string{outputDst, filepath.Join(outputDst, "sub"), hostRunDir} {
+		got, err := os.Stat(p)
+		require.NoError(t, err)
+		assert.Equal(t, want.Sys().(*syscall.Stat_t).Uid, got.Sys().(*syscall.Stat_t).Uid, "owner of %s", p)
+	}
+}
+
+func TestDockerRuntime_Pull(t *testing.T) {
+	skipIfNoDocker(t)
+
+	rt, err := docker.NewRuntime()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, rt.Pull(ctx, "alpine:latest"))
+	present, err := rt.HasImage(ctx, "alpine:latest")
+	require.NoError(t, err)
+	assert.True(t, present)
+
+	err = rt.Pull(ctx, "cloche-test/does-not-exist:nope")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pulling image cloche-test/does-not-exist:nope")
+
+	present, err = rt.HasImage(ctx, "cloche-test/does-not-exist:nope")
+	require.NoError(t, err)
+	assert.False(t, present)
+}
@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/cloche-dev/cloche/internal/domain"
+)
+
+// DeleteRuns is the bulk counterpart of deleting a single run, behind
+// "cloche rm". It deletes finished runs matching the request's filter along
+// with their captures, containers and on-disk artifacts. Unfinished runs are
+// never matched. Failures on individual items are reported in the response
+// and do not stop the remaining work.
+func (s *ClocheServer) DeleteRuns(ctx context.Context, req *pb.DeleteRunsRequest) (*pb.DeleteRunsResponse, error) {
+	filter := domain.RunListFilter{
+		ProjectDir:   req.ProjectDir,
+		WorkflowName: req.WorkflowName,
+	}
+	if req.State != "" {
+		filter.State = domain.RunState(req.State)
+		switch filter.State {
+		case domain.RunStateSucceeded, domain.RunStateFailed, domain.RunStateCancelled:
+		default:
+			return nil, fmt.Errorf("invalid state %q: only succeeded, failed or cancelled runs can be deleted", req.State)
+		}
+	}
+	if req.OlderThanSeconds > 0 {
+		filter.CompletedBefore = time.Now().Add(-time.Duration(req.OlderThanSeconds) * time.Second)
+	}
+
+	resp := &pb.DeleteRunsResponse{}
+
+	if req.DryRun {
+		runs, err := s.store.ListRunsFiltered(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("listing runs: %w", err)
+		}
+		for _, run := range runs {
+			if run.IsTerminal() {
+				resp.Runs = append(resp.Runs, deletedRunSummary(run))
+			}
+		}
+		return resp, nil
+	}
+
+	runs, err := s.store.DeleteRunsWhere(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("deleting runs: %w", err)
+	}
+	for _, run := range runs {
+		resp.Runs = append(resp.Runs, deletedRunSummary(run))
+	}
+	cleanup := s.cleanupDeletedRuns(ctx, runs)
+	resp.RemovedArtifacts = cleanup.artifacts
+	resp.Errors = append(resp.Errors, cleanup.errors...)
+
+	return resp, nil
+}
+
+func deletedRunSummary(run *domain.Run) *pb.RunSummary {
+	return &pb.RunSummary{
+		RunId:        run.ID,
+		WorkflowName: run.WorkflowName,
+		State:        string(run.State),
+		StartedAt:    run.StartedAt.String(),
+		ErrorMessage: run.ErrorMessage,
+		ContainerId:  run.ContainerID,
+		Title:        run.Title,
+		IsHost:       run.IsHost,
+		ProjectDir:   run.ProjectDir,
+		TaskId:       run.TaskID,
+	}
+}
+
+// runCleanup records what cleanupDeletedRuns removed and what it could not.
+type runCleanup struct {
+	containers []string
+	artifacts  []string // on-disk paths
+	errors     []string
+}
+
+// cleanupDeletedRuns removes the containers and on-disk artifacts of runs
+// that were just deleted from the store (see DeleteRunsWhere). Failures on
+// individual items are recorded and do not stop the remaining work.
+func (s *ClocheServer) cleanupDeletedRuns(ctx context.Context, runs []*domain.Run) *runCleanup {
+	c := &runCleanup{}
+	attempts := map[string]*domain.Run{} // attempt ID -> a deleted run of it
+	for _, run := range runs {
+		if run.ContainerID != "" && s.container != nil {
+			if _, err := s.container.Inspect(ctx, run.ContainerID); err == nil {
+				if err := s.container.Remove(ctx, run.ContainerID); err != nil {
+					c.errors = append(c.errors, fmt.Sprintf("container %s (run %s): %v", run.ContainerID, run.ID, err))
+				} else {
+					c.containers = append(c.containers, run.ContainerID)
+				}
+			}
+		}
+		if run.ProjectDir == "" {
+			continue
+		}
+		paths := []string{filepath.Join(run.ProjectDir, ".cloche", "runs", run.ID)}
+		if run.TaskID != "" && run.AttemptID != "" {
+			paths = append(paths, filepath.Join(runLogDir(run, run.ProjectDir, run.ID), run.ID+".diff"))
+			attempts[run.AttemptID] = run
+		} else {
+			// Legacy runs keep their output under .cloche/<run-id>/.
+			paths = append(paths, filepath.Join(run.ProjectDir, ".cloche", run.ID))
+		}
+		for _, path := range paths {
+			c.remove(path)
+		}
+	}
+
+	// An attempt's log directory is shared by its runs, so it goes only once
+	// none of them remain.
+	for attemptID, run := range attempts {
+		remaining, err := s.store.ListRunsFiltered(ctx, domain.RunListFilter{AttemptID: attemptID, Limit: 1})
+		if err != nil {
+			c.errors = append(c.errors, fmt.Sprintf("attempt %s: %v", attemptID, err))
+			continue
+		}
+		if len(remaining) == 0 {
+			c.remove(runLogDir(run, run.ProjectDir, run.ID))
+		}
+	}
+	return c
+}
+
+// remove removes path if it exists and records the outcome.
+func (c *runCleanup) remove(path string) {
+	if _, err := os.Lstat(path); err != nil {
+		return
+	}
+	if err := os.RemoveAll(path); err != nil {
+		c.errors = append(c.errors, fmt.Sprintf("%s: %v", path, err))
+		return
+	}
+	c.artifacts = append(c.artifacts, path)
+}
@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+)
+
+// Evolve runs an evolution pass for one workflow on demand, behind
+// "cloche evolve". Unlike the post-run trigger it is not debounced and
+// blocks until the pass finishes.
+func (s *ClocheServer) Evolve(ctx context.Context, req *pb.EvolveRequest) (*pb.EvolveResponse, error) {
+	if req.ProjectDir == "" || req.WorkflowName == "" {
+		return nil, fmt.Errorf("project_dir and workflow_name are required")
+	}
+	if s.evolution == nil {
+		return nil, fmt.Errorf("evolution is not enabled on this daemon (needs [evolution] enabled and an LLM command)")
+	}
+	if req.SinceRunId != "" {
+		run, err := s.store.GetRun(ctx, req.SinceRunId)
+		if err != nil {
+			return nil, fmt.Errorf("since run %q: %w", req.SinceRunId, err)
+		}
+		if run.ProjectDir != req.ProjectDir || run.WorkflowName != req.WorkflowName {
+			return nil, fmt.Errorf("since run %q belongs to workflow %q in %s, not %q", req.SinceRunId, run.WorkflowName, run.ProjectDir, req.WorkflowName)
+		}
+	}
+
+	result, err := s.evolution.Evolve(ctx, req.ProjectDir, req.WorkflowName, req.SinceRunId)
+	if err != nil {
+		return nil, fmt.Errorf("evolution pass: %w", err)
+	}
+	resp := &pb.EvolveResponse{
+		EvolutionId:    result.ID,
+		Classification: result.Classification,
+		RunsCollected:  int32(result.RunsCollected),
+		Changes:        int32(len(result.Changes)),
+		KnowledgeDelta: result.KnowledgeDelta,
+	}
+	for _, change := range result.Changes {
+		detail := change.Type + " " + change.File
+		if len(change.Summary) > 0 {
+			detail += ": " + strings.Join(change.Summary, "; ")
+		}
+		resp.ChangeDetails = append(resp.ChangeDetails, detail)
+	}
+	return resp, nil
+}
@@ -0,0 +1,68 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	server "github.com/cloche-dev/cloche/internal/adapters/grpc"
+	"github.com/cloche-dev/cloche/internal/adapters/sqlite"
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/evolution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Evolve(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+	ctx := context.Background()
+
+	run := domain.NewRun("a1-develop", "develop")
+	run.ProjectDir = "/project"
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	srv := server.NewClocheServer(store, nil)
+	req := &pb.EvolveRequest{ProjectDir: "/project", WorkflowName: "develop", SinceRunId: "a1-develop"}
+
+	_, err = srv.Evolve(ctx, req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "evolution is not enabled")
+
+	var gotSince string
+	trigger := evolution.NewTrigger(evolution.TriggerConfig{
+		EvolveFunc: func(_ context.Context, projectDir, workflowName, sinceRunID string) (*evolution.EvolutionResult, error) {
+			gotSince = sinceRunID
+			return &evolution.EvolutionResult{
+				ID:             "evo-1",
+				Classification: "bug",
+				RunsCollected:  3,
+				Changes: []evolution.Change{
+					{Type: "prompt_update", File: ".cloche/prompts/develop.md"},
+					{Type: "add_step", File: ".cloche/develop.cloche", Summary: []string{"added step lint", "wired lint:fail -> abort"}},
+				},
+				KnowledgeDelta: "1 lessons applied",
+			}, nil
+		},
+	})
+	defer trigger.Stop()
+	srv.SetEvolution(trigger)
+
+	resp, err := srv.Evolve(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "a1-develop", gotSince)
+	assert.Equal(t, "evo-1", resp.EvolutionId)
+	assert.Equal(t, "bug", resp.Classification)
+	assert.Equal(t, int32(3), resp.RunsCollected)
+	assert.Equal(t, int32(2), resp.Changes)
+	assert.Equal(t, []string{
+		"prompt_update .cloche/prompts/develop.md",
+		"add_step .cloche/develop.cloche: added step lint; wired lint:fail -> abort",
+	}, resp.ChangeDetails)
+
+	// The since run must belong to the workflow being evolved.
+	_, err = srv.Evolve(ctx, &pb.EvolveRequest{ProjectDir: "/project", WorkflowName: "review", SinceRunId: "a1-develop"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `belongs to workflow "develop"`)
+}
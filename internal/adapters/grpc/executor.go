@@ -41,6 +41,10 @@ type DaemonExecutor struct {
 	// image is the container image to use when starting new containers.
 	image string
 
+	// defaultNetwork is the daemon's default_network, applied to workflows
+	// that configure no networking themselves.
+	defaultNetwork string
+
 	// allWFs is the full set of workflows (host and container) for the project,
 	// keyed by name. Used to resolve workflow_name step targets.
 	allWFs map[string]*domain.Workflow
@@ -65,6 +69,10 @@ type DaemonExecutor struct {
 	// so the in-container agent continues an existing LLM conversation.
 	resumeMode bool
 
+	// pullImage, when set, is called before the first container of a pool key
+	// starts so the image pull is reported rather than hidden inside Start.
+	pullImage func(ctx context.Context, image string) error
+
 	// onContainerStart is called after a container is started with (containerID).
 	// The server uses this to register the container → run mapping so the
 	// AgentSession handler can route StepLog messages to the right run.
@@ -97,9 +105,13 @@ type DaemonExecutorConfig struct {
 	AttemptID  string
 	Image      string
 	AllWFs     map[string]*domain.Workflow
+	// DefaultNetwork is the daemon's default_network. Optional.
+	DefaultNetwork string
 	// ResumeMode, when true, sets resume=true on all ExecuteStep messages so
 	// that the in-container agent continues its previous LLM conversation.
 	ResumeMode bool
+	// PullImage pulls a container image ahead of its first use. Optional.
+	PullImage func(ctx context.Context, image string) error
 	// OnContainerStart is called after a container starts with (containerID).
 	OnContainerStart func(containerID string)
 }
@@ -116,8 +128,10 @@ func NewDaemonExecutor(cfg DaemonExecutorConfig) *DaemonExecutor {
 		taskID:           cfg.TaskID,
 		attemptID:        cfg.AttemptID,
 		image:            cfg.Image,
+		defaultNetwork:   cfg.DefaultNetwork,
 		allWFs:           cfg.AllWFs,
 		resumeMode:       cfg.ResumeMode,
+		pullImage:        cfg.PullImage,
 		onContainerStart: cfg.OnContainerStart,
 		worktrees:        make(map[string][]repoWorktree),
 	}
@@ -270,6 +284,9 @@ func (d *DaemonExecutor) executeWorkflowStep(ctx context.Context, step *domain.S
 	}
 
 	eng := engine.New(d)
+	if w, ok := engine.DecisionLogFromContext(ctx); ok {
+		eng.SetDecisionLog(w)
+	}
 	// For host sub-workflows attach a lightweight status handler so the inner
 	// steps' events (start, output, completion) are broadcast live to the
 	// parent run's log stream and can be read back from full.log.
@@ -526,11 +543,13 @@ func (d *DaemonExecutor) executeContainerStep(ctx context.Context, step *domain.
 		RunID:        hostRunID,
 		TaskID:       d.taskID,
 		AttemptID:    d.attemptID,
-		NetworkAllow: []string{"*"},
+		Labels:       wf.Labels,
+		SavePrompts:  config.SavePromptsEnabled(d.projectDir),
 		// Start agent in session mode (no workflow file argument) so it
 		// connects to the daemon via gRPC and waits for ExecuteStep commands.
 		Cmd: []string{"cloche-agent"},
 	}
+	cfg.Network, cfg.NetworkAllow = resolveNetwork(wf, d.defaultNetwork)
 
 	// Inject workflow-level `container { agent_command = ... }` and
 	// `container { agent_args = ... }` into the step config so the in-container
@@ -550,6 +569,17 @@ func (d *DaemonExecutor) executeContainerStep(ctx context.Context, step *domain.
 			step.Config["agent_args"] = args
 		}
 	}
+	// Snapshotting a step's output stages the whole workspace, so the agent
+	// only does it for steps a later step runs on via base.
+	if wf.UsedAsBase(step.Name) {
+		step.Config["snapshot_output"] = "true"
+	}
+
+	if d.pullImage != nil && d.pool.GetSession(poolKey) == nil {
+		if err := d.pullImage(ctx, image); err != nil {
+			return domain.StepResult{}, fmt.Errorf("daemon executor: pulling image for step %q: %w", step.Name, err)
+		}
+	}
 
 	session, err := d.pool.SessionFor(ctx, poolKey, cfg)
 	if err != nil {
@@ -560,7 +590,9 @@ func (d *DaemonExecutor) executeContainerStep(ctx context.Context, step *domain.
 		d.onContainerStart(session.ContainerID)
 	}
 
-	return session.ExecuteStep(ctx, step, d.resumeMode)
+	// The agent may queue the step behind others sharing the container, so
+	// the step's timeout runs from when the agent starts it.
+	return session.ExecuteStep(ctx, step, d.resumeMode, engine.HoldStepTimeout(ctx))
 }
 
 // extractContainerLogs copies output log files from the container to the host
@@ -579,7 +611,7 @@ func (d *DaemonExecutor) extractContainerLogs(ctx context.Context, containerID,
 	// container step logs (implement.log, test.log, etc.) are preserved
 	// without colliding with the host workflow's own log files.
 	subDir := filepath.Join(hostLogDir, stepName)
-	if err := os.MkdirAll(subDir, 0755); err != nil {
+	if err := docker.MkdirOwned(subDir); err != nil {
 		log.Printf("daemon executor: failed to create log subdir %s: %v", subDir, err)
 		return
 	}
@@ -710,7 +742,7 @@ func (h *innerHostStatusHandler) OnStepStart(_ *domain.Run, step *domain.Step) {
 	})
 }
 
-func (h *innerHostStatusHandler) OnStepComplete(_ *domain.Run, step *domain.Step, result string, _ *domain.TokenUsage) {
+func (h *innerHostStatusHandler) OnStepComplete(_ *domain.Run, step *domain.Step, sr domain.StepResult) {
 	if h.logBroadcast == nil {
 		return
 	}
@@ -726,7 +758,7 @@ func (h *innerHostStatusHandler) OnStepComplete(_ *domain.Run, step *domain.Step
 	h.logBroadcast.Publish(h.hostRunID, logstream.LogLine{
 		Timestamp: time.Now().Format(time.RFC3339),
 		Type:      "status",
-		Content:   "step_completed: " + step.Name + " -> " + result,
+		Content:   "step_completed: " + step.Name + " -> " + sr.Result,
 		StepName:  step.Name,
 	})
 }
@@ -420,6 +420,39 @@ func TestDaemonExecutor_ContainerStep_StepLevelAgentWins(t *testing.T) {
 		"step-level agent_args should NOT be overwritten by container-block default")
 }
 
+// TestDaemonExecutor_ContainerStep_SnapshotsOnlyBaseSteps verifies that only
+// steps a later step runs on via base are marked for an output snapshot.
+func TestDaemonExecutor_ContainerStep_SnapshotsOnlyBaseSteps(t *testing.T) {
+	rt := &recordingContainerRuntime{}
+	pool := docker.NewContainerPool(rt)
+
+	wf := buildContainerWFForTest("develop")
+	wf.Steps["review"] = &domain.Step{
+		Name:    "review",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success"},
+		Config:  map[string]string{"base": "step.step1.output"},
+	}
+
+	de := NewDaemonExecutor(DaemonExecutorConfig{
+		Pool:       pool,
+		ProjectDir: t.TempDir(),
+		AttemptID:  "att-snapshot",
+		Image:      "daemon-default:latest",
+		AllWFs:     map[string]*domain.Workflow{"develop": wf},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx = engine.WithWorkflow(ctx, wf)
+
+	_, _ = de.Execute(ctx, wf.Steps["step1"])
+	_, _ = de.Execute(ctx, wf.Steps["review"])
+
+	assert.Equal(t, "true", wf.Steps["step1"].Config["snapshot_output"])
+	assert.NotContains(t, wf.Steps["review"].Config, "snapshot_output")
+}
+
 // TestDaemonExecutor_ProductionWiring validates the full construction path that
 // the daemon uses: daemonExecutorFor → host.Runner.RunNamed → engine. This
 // exercises the wiring that unit tests bypass by constructing DaemonExecutor
@@ -625,6 +658,13 @@ func (r *recordingContainerRuntime) Remove(_ context.Context, _ string) error {
 func (r *recordingContainerRuntime) Inspect(_ context.Context, _ string) (*ports.ContainerStatus, error) {
 	return &ports.ContainerStatus{}, nil
 }
+func (r *recordingContainerRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (r *recordingContainerRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (r *recordingContainerRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, nil
 }
@@ -635,6 +675,10 @@ type fakeRunStore struct{}
 
 func (f *fakeRunStore) CreateRun(_ context.Context, _ *domain.Run) error { return nil }
 func (f *fakeRunStore) UpdateRun(_ context.Context, _ *domain.Run) error { return nil }
+func (f *fakeRunStore) UpdateRunState(_ context.Context, _ string, _ domain.RunState) error {
+	return nil
+}
+func (f *fakeRunStore) UpdateActiveSteps(_ context.Context, _ string, _ []string) error { return nil }
 func (f *fakeRunStore) GetRun(_ context.Context, _ string) (*domain.Run, error) {
 	return nil, fmt.Errorf("not found")
 }
@@ -651,6 +695,9 @@ func (f *fakeRunStore) ListRunsByProject(_ context.Context, _ string, _ time.Tim
 func (f *fakeRunStore) ListRunsFiltered(_ context.Context, _ domain.RunListFilter) ([]*domain.Run, error) {
 	return nil, nil
 }
+func (f *fakeRunStore) DeleteRunsWhere(_ context.Context, _ domain.RunListFilter) ([]*domain.Run, error) {
+	return nil, nil
+}
 func (f *fakeRunStore) ListProjects(_ context.Context) ([]string, error)  { return nil, nil }
 func (f *fakeRunStore) ListChildRuns(_ context.Context, _ string) ([]*domain.Run, error) {
 	return nil, nil
@@ -692,6 +739,13 @@ func (e *errContainerRuntime) Remove(_ context.Context, _ string) error
 func (e *errContainerRuntime) Inspect(_ context.Context, _ string) (*ports.ContainerStatus, error) {
 	return &ports.ContainerStatus{}, nil
 }
+func (e *errContainerRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (e *errContainerRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (e *errContainerRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, nil
 }
@@ -727,6 +781,13 @@ func (r *copyTrackingRuntime) Remove(_ context.Context, _ string) error
 func (r *copyTrackingRuntime) Inspect(_ context.Context, _ string) (*ports.ContainerStatus, error) {
 	return &ports.ContainerStatus{}, nil
 }
+func (r *copyTrackingRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (r *copyTrackingRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (r *copyTrackingRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, nil
 }
@@ -1170,7 +1231,7 @@ func TestInnerHostStatusHandler_BroadcastsEvents(t *testing.T) {
 
 	step := &domain.Step{Name: "checkout", Type: domain.StepTypeScript}
 	h.OnStepStart(nil, step)
-	h.OnStepComplete(nil, step, "success", nil)
+	h.OnStepComplete(nil, step, domain.StepResult{Result: "success"})
 
 	// Collect published lines from channel (non-blocking).
 	var lines []logstream.LogLine
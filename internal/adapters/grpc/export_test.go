@@ -79,9 +79,9 @@ func (s *ClocheServer) TrackRun(runID, containerID, projectDir, workflowName str
 	s.trackRun(runID, containerID, projectDir, workflowName, keepContainer)
 }
 
-// AgentNameForStep exposes agentNameForStep for testing.
-func AgentNameForStep(projectDir, workflowName, stepName string) string {
-	return agentNameForStep(projectDir, workflowName, stepName)
+// LaunchResumeContainer exposes launchResumeContainer for testing.
+func (s *ClocheServer) LaunchResumeContainer(run *domain.Run, image string, cmd []string) {
+	s.launchResumeContainer(run, image, cmd)
 }
 
 // SaveBroadcasterHistory exposes saveBroadcasterHistory for testing.
@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/host"
+)
+
+// slotRecheckInterval is how often a queued run checks whether it was stopped
+// while waiting for a workflow slot.
+var slotRecheckInterval = time.Second
+
+// workflowLimiter enforces per-workflow max_concurrent limits. Runs beyond a
+// workflow's limit wait in launch order until an earlier run of the same
+// workflow finishes. The zero value is ready to use.
+type workflowLimiter struct {
+	mu     sync.Mutex
+	queues map[string]*workflowQueue // project_dir + "\x00" + workflow name
+}
+
+type workflowQueue struct {
+	active  int
+	waiters []slotWaiter
+}
+
+type slotWaiter struct {
+	runID string
+	ready chan struct{}
+}
+
+// acquire returns a channel that is closed once the caller holds one of the
+// key's limit slots. The caller must eventually call release, or abandon if
+// it gives up before the channel is closed.
+func (l *workflowLimiter) acquire(key, runID string, limit int) <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.queues == nil {
+		l.queues = make(map[string]*workflowQueue)
+	}
+	q := l.queues[key]
+	if q == nil {
+		q = &workflowQueue{}
+		l.queues[key] = q
+	}
+	ready := make(chan struct{})
+	if q.active < limit {
+		q.active++
+		close(ready)
+		return ready
+	}
+	q.waiters = append(q.waiters, slotWaiter{runID: runID, ready: ready})
+	return ready
+}
+
+// release frees a slot, handing it to the longest-waiting run if there is one.
+func (l *workflowLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked(key)
+}
+
+func (l *workflowLimiter) releaseLocked(key string) {
+	q := l.queues[key]
+	if q == nil {
+		return
+	}
+	if len(q.waiters) > 0 {
+		next := q.waiters[0]
+		q.waiters = q.waiters[1:]
+		close(next.ready)
+		return
+	}
+	q.active--
+	if q.active <= 0 {
+		delete(l.queues, key)
+	}
+}
+
+// abandon withdraws a waiter returned by acquire. If the slot was granted in
+// the meantime it is released instead.
+func (l *workflowLimiter) abandon(key string, ready <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if q := l.queues[key]; q != nil {
+		for i, w := range q.waiters {
+			if w.ready == ready {
+				q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+				return
+			}
+		}
+	}
+	l.releaseLocked(key)
+}
+
+// position returns the 1-based place of runID among the runs waiting for a
+// slot of its workflow, or 0 when the run is not queued.
+func (l *workflowLimiter) position(runID string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, q := range l.queues {
+		for i, w := range q.waiters {
+			if w.runID == runID {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// waitForWorkflowSlot blocks a pending run until its workflow is below its
+// max_concurrent limit. The returned func releases the slot and must be
+// called when the run finishes. If the run leaves the pending state while
+// queued (e.g. it is stopped), errRunNotPending is returned.
+func (s *ClocheServer) waitForWorkflowSlot(ctx context.Context, projectDir, workflowName, runID string) (func(), error) {
+	limit := workflowMaxConcurrent(projectDir, workflowName)
+	if limit <= 0 {
+		return func() {}, nil
+	}
+	key := projectDir + "\x00" + workflowName
+	ready := s.workflowSlots.acquire(key, runID, limit)
+	release := func() { s.workflowSlots.release(key) }
+
+	select {
+	case <-ready:
+		return release, nil
+	default:
+	}
+	log.Printf("run %s: queued behind other %s runs (max_concurrent=%d)", runID, workflowName, limit)
+
+	ticker := time.NewTicker(slotRecheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ready:
+			return release, nil
+		case <-ticker.C:
+			run, err := s.store.GetRun(ctx, runID)
+			if err == nil && run.State != domain.RunStatePending {
+				s.workflowSlots.abandon(key, ready)
+				return nil, errRunNotPending
+			}
+		}
+	}
+}
+
+// workflowMaxConcurrent returns the max_concurrent setting of the named
+// workflow, or 0 when it sets none or cannot be loaded.
+func workflowMaxConcurrent(projectDir, workflowName string) int {
+	wfs, err := host.FindAllWorkflows(projectDir)
+	if err != nil {
+		return 0
+	}
+	if wf, ok := wfs[workflowName]; ok {
+		return wf.MaxConcurrent()
+	}
+	return 0
+}
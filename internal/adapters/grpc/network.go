@@ -0,0 +1,34 @@
+package grpc
+
+import (
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/host"
+)
+
+// resolveNetwork returns the network mode and allowed hosts for a container
+// of wf. A workflow that sets network or network_allow in its container block
+// gets exactly that; any other workflow, or a nil one, gets daemonDefault.
+// Networked containers without an allow list may reach any host.
+func resolveNetwork(wf *domain.Workflow, daemonDefault string) (mode string, allow []string) {
+	if wf != nil {
+		mode, allow = wf.Network()
+	}
+	if mode == "" && len(allow) == 0 {
+		mode = daemonDefault
+	}
+	if len(allow) == 0 {
+		allow = []string{"*"}
+	}
+	return mode, allow
+}
+
+// workflowNetwork resolves the container network of the named workflow, as
+// resolveNetwork does. A workflow that cannot be loaded gets the daemon
+// default.
+func (s *ClocheServer) workflowNetwork(projectDir, workflowName string) (string, []string) {
+	var wf *domain.Workflow
+	if wfs, err := host.FindAllWorkflows(projectDir); err == nil {
+		wf = wfs[workflowName]
+	}
+	return resolveNetwork(wf, s.defaultNetwork)
+}
@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowNetwork_DaemonDefaultAndOverride(t *testing.T) {
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	workflow := func(name, container string) {
+		src := "workflow " + name + " {\n" + container + `
+  step code {
+    prompt = "do it"
+    results = [success]
+  }
+  code:success -> done
+}
+`
+		require.NoError(t, os.WriteFile(filepath.Join(clocheDir, name+".cloche"), []byte(src), 0644))
+	}
+	workflow("plain", "")
+	workflow("bridged", "  container {\n    network = \"bridge\"\n  }\n")
+	workflow("allowed", "  container {\n    network_allow = [\"api.example.com\", \"github.com\"]\n  }\n")
+
+	s := &ClocheServer{defaultNetwork: "cloche-only"}
+
+	mode, allow := s.workflowNetwork(dir, "plain")
+	assert.Equal(t, "cloche-only", mode, "a workflow without network config inherits the daemon default")
+	assert.Equal(t, []string{"*"}, allow)
+
+	mode, allow = s.workflowNetwork(dir, "bridged")
+	assert.Equal(t, "bridge", mode)
+	assert.Equal(t, []string{"*"}, allow)
+
+	mode, allow = s.workflowNetwork(dir, "allowed")
+	assert.Empty(t, mode, "network_allow opts out of the daemon default")
+	assert.Equal(t, []string{"api.example.com", "github.com"}, allow)
+
+	// Without a daemon default, containers keep the runtime's network.
+	s.defaultNetwork = ""
+	mode, allow = s.workflowNetwork(dir, "plain")
+	assert.Empty(t, mode)
+	assert.Equal(t, []string{"*"}, allow)
+}
@@ -0,0 +1,145 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/cloche-dev/cloche/internal/domain"
+)
+
+// Prune is the single housekeeping entry point behind "cloche prune". Each
+// phase is opt-in via the request and runs in order: old runs are deleted
+// first so that the snapshot and container sweeps see what they left behind.
+// Failures on individual items are reported in the response and do not stop
+// the remaining work.
+func (s *ClocheServer) Prune(ctx context.Context, req *pb.PruneRequest) (*pb.PruneResponse, error) {
+	var runs []*domain.Run
+	var err error
+	if req.ProjectDir != "" {
+		runs, err = s.store.ListRunsByProject(ctx, req.ProjectDir, time.Time{})
+	} else {
+		runs, err = s.store.ListRuns(ctx, time.Time{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing runs: %w", err)
+	}
+
+	// Project directories are gathered before runs are deleted so snapshots
+	// left behind by deleted runs are still found.
+	projectDirs := map[string]bool{}
+	if req.ProjectDir != "" {
+		projectDirs[req.ProjectDir] = true
+	}
+	for _, run := range runs {
+		if run.ProjectDir != "" {
+			projectDirs[run.ProjectDir] = true
+		}
+	}
+
+	resp := &pb.PruneResponse{}
+
+	if req.RunsOlderThanSeconds > 0 {
+		cutoff := time.Now().Add(-time.Duration(req.RunsOlderThanSeconds) * time.Second)
+		runs = s.pruneRuns(ctx, runs, req.ProjectDir, cutoff, resp)
+	}
+	if req.Containers {
+		s.pruneContainers(ctx, runs, resp)
+	}
+	if req.Snapshots {
+		liveAttempts := map[string]bool{}
+		for _, run := range runs {
+			if run.AttemptID != "" {
+				liveAttempts[run.AttemptID] = true
+			}
+		}
+		for dir := range projectDirs {
+			pruneSnapshots(dir, liveAttempts, resp)
+		}
+		sort.Strings(resp.RemovedSnapshots)
+	}
+
+	return resp, nil
+}
+
+// pruneRuns deletes finished runs in projectDir (every project when empty)
+// that completed before cutoff, along with their captures, log records,
+// containers and on-disk artifacts, and returns the runs that remain.
+func (s *ClocheServer) pruneRuns(ctx context.Context, runs []*domain.Run, projectDir string, cutoff time.Time, resp *pb.PruneResponse) []*domain.Run {
+	deleted, err := s.store.DeleteRunsWhere(ctx, domain.RunListFilter{ProjectDir: projectDir, CompletedBefore: cutoff})
+	if err != nil {
+		resp.Errors = append(resp.Errors, fmt.Sprintf("deleting runs: %v", err))
+		return runs
+	}
+	gone := make(map[string]bool, len(deleted))
+	for _, run := range deleted {
+		gone[run.ID] = true
+		resp.DeletedRuns = append(resp.DeletedRuns, run.ID)
+	}
+	cleanup := s.cleanupDeletedRuns(ctx, deleted)
+	resp.RemovedContainers = append(resp.RemovedContainers, cleanup.containers...)
+	resp.Errors = append(resp.Errors, cleanup.errors...)
+
+	var remaining []*domain.Run
+	for _, run := range runs {
+		if !gone[run.ID] {
+			remaining = append(remaining, run)
+		}
+	}
+	return remaining
+}
+
+// pruneContainers removes containers that are still present for finished
+// runs. Containers retained with --keep-container and containers of runs the
+// daemon is still tracking are left alone.
+func (s *ClocheServer) pruneContainers(ctx context.Context, runs []*domain.Run, resp *pb.PruneResponse) {
+	if s.container == nil {
+		return
+	}
+	for _, run := range runs {
+		if !run.IsTerminal() || run.ContainerID == "" || run.ContainerKept {
+			continue
+		}
+		s.mu.Lock()
+		_, tracked := s.runIDs[run.ID]
+		s.mu.Unlock()
+		if tracked {
+			continue
+		}
+		if _, err := s.container.Inspect(ctx, run.ContainerID); err != nil {
+			continue // already gone
+		}
+		if err := s.container.Remove(ctx, run.ContainerID); err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("container %s (run %s): %v", run.ContainerID, run.ID, err))
+			continue
+		}
+		resp.RemovedContainers = append(resp.RemovedContainers, run.ContainerID)
+	}
+}
+
+// pruneSnapshots removes per-attempt snapshot directories under projectDir
+// (.cloche/runs/<taskID>/snapshots/<attemptID>) whose attempt has no
+// remaining run.
+func pruneSnapshots(projectDir string, liveAttempts map[string]bool, resp *pb.PruneResponse) {
+	dirs, err := filepath.Glob(filepath.Join(projectDir, ".cloche", "runs", "*", "snapshots", "*"))
+	if err != nil {
+		return
+	}
+	for _, dir := range dirs {
+		if liveAttempts[filepath.Base(dir)] {
+			continue
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("snapshot %s: %v", dir, err))
+			continue
+		}
+		resp.RemovedSnapshots = append(resp.RemovedSnapshots, dir)
+	}
+}
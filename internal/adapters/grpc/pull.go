@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloche-dev/cloche/internal/config"
+	"github.com/cloche-dev/cloche/internal/logstream"
+	"github.com/cloche-dev/cloche/internal/ports"
+)
+
+// Image pull policies for [daemon] pull_policy.
+const (
+	pullMissing = "missing"
+	pullAlways  = "always"
+	pullNever   = "never"
+)
+
+// pullImage pulls image ahead of starting a container, as the project's pull
+// policy requires, so a large pull shows up as a status line on the run's log
+// stream instead of a run that looks stuck in pending. runID may be empty
+// when there is no run to report to. Images the project builds itself are
+// never pulled.
+func (s *ClocheServer) pullImage(ctx context.Context, projectDir, runID, image string) error {
+	if s.container == nil || image == "" || s.projectBuildsImage(projectDir) {
+		return nil
+	}
+
+	cfg, err := config.LoadMerged(projectDir)
+	if err != nil {
+		return err
+	}
+	policy := pullMissing
+	if cfg.Daemon.PullPolicy != "" {
+		policy = cfg.Daemon.PullPolicy
+	}
+	switch policy {
+	case pullNever:
+		return nil
+	case pullMissing:
+		checker, ok := s.container.(ports.ImageChecker)
+		if !ok {
+			return nil
+		}
+		present, err := checker.HasImage(ctx, image)
+		if err != nil {
+			return err
+		}
+		if present {
+			return nil
+		}
+	case pullAlways:
+		// Pull below. config.Load rejects any other policy.
+	}
+
+	log.Printf("pulling image %s (run %q)", image, runID)
+	s.publishRunStatus(runID, "pulling image "+image+"...")
+	start := time.Now()
+	if err := s.container.Pull(ctx, image); err != nil {
+		return err
+	}
+	s.publishRunStatus(runID, fmt.Sprintf("pulled image %s in %s", image, time.Since(start).Round(time.Second)))
+	return nil
+}
+
+// projectBuildsImage reports whether the runtime builds the project's image
+// from .cloche/Dockerfile (see ports.ImageEnsurer). The local build is then
+// the image to run, and a pull would replace it or fail for a local-only tag.
+func (s *ClocheServer) projectBuildsImage(projectDir string) bool {
+	if _, ok := s.container.(ports.ImageEnsurer); !ok {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(projectDir, ".cloche", "Dockerfile"))
+	return err == nil
+}
+
+// publishRunStatus broadcasts a run-level status line to live log followers.
+func (s *ClocheServer) publishRunStatus(runID, content string) {
+	if s.logBroadcast == nil || runID == "" {
+		return
+	}
+	s.logBroadcast.Start(runID)
+	s.logBroadcast.Publish(runID, logstream.LogLine{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Type:      "status",
+		Content:   content,
+	})
+}
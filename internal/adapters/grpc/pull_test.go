@@ -0,0 +1,94 @@
+package grpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloche-dev/cloche/internal/logstream"
+	"github.com/cloche-dev/cloche/internal/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pullRuntime records pulls and reports images in present as local.
+type pullRuntime struct {
+	ports.ContainerRuntime
+	present map[string]bool
+	pulled  []string
+}
+
+func (r *pullRuntime) Pull(_ context.Context, image string) error {
+	r.pulled = append(r.pulled, image)
+	return nil
+}
+
+func (r *pullRuntime) HasImage(_ context.Context, image string) (bool, error) {
+	return r.present[image], nil
+}
+
+func TestPullImage_Policy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	projectDir := t.TempDir()
+	writePolicy := func(policy string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".cloche"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".cloche", "config.toml"),
+			[]byte("[daemon]\npull_policy = \""+policy+"\"\n"), 0644))
+	}
+
+	rt := &pullRuntime{present: map[string]bool{"local:latest": true}}
+	s := &ClocheServer{container: rt, logBroadcast: logstream.NewBroadcaster()}
+	ctx := context.Background()
+
+	// Default "missing": only absent images are pulled, with status lines.
+	sub := s.logBroadcast.Subscribe("run-1")
+	require.NoError(t, s.pullImage(ctx, projectDir, "run-1", "local:latest"))
+	require.NoError(t, s.pullImage(ctx, projectDir, "run-1", "remote:1.0"))
+	assert.Equal(t, []string{"remote:1.0"}, rt.pulled)
+	assert.Equal(t, "pulling image remote:1.0...", (<-sub.C).Content)
+	assert.Contains(t, (<-sub.C).Content, "pulled image remote:1.0")
+
+	writePolicy("always")
+	require.NoError(t, s.pullImage(ctx, projectDir, "", "local:latest"))
+	assert.Equal(t, []string{"remote:1.0", "local:latest"}, rt.pulled)
+
+	writePolicy("never")
+	require.NoError(t, s.pullImage(ctx, projectDir, "", "remote:1.0"))
+	assert.Len(t, rt.pulled, 2)
+
+	writePolicy("sometimes")
+	err := s.pullImage(ctx, projectDir, "", "remote:1.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `daemon.pull_policy: unknown policy "sometimes"`)
+}
+
+// buildingRuntime is a pullRuntime that builds images from the project's
+// Dockerfile.
+type buildingRuntime struct {
+	pullRuntime
+}
+
+func (r *buildingRuntime) EnsureImage(_ context.Context, _, _ string) error { return nil }
+
+func TestPullImage_SkipsProjectBuiltImage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	projectDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".cloche"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".cloche", "config.toml"),
+		[]byte("[daemon]\npull_policy = \"always\"\n"), 0644))
+
+	rt := &buildingRuntime{}
+	s := &ClocheServer{container: rt}
+	ctx := context.Background()
+
+	// Without a project Dockerfile the image comes from a registry.
+	require.NoError(t, s.pullImage(ctx, projectDir, "", "cloche-agent:latest"))
+	assert.Equal(t, []string{"cloche-agent:latest"}, rt.pulled)
+
+	// With one, EnsureImage builds it locally and "always" must not pull
+	// over the build.
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".cloche", "Dockerfile"), []byte("FROM scratch\n"), 0644))
+	require.NoError(t, s.pullImage(ctx, projectDir, "", "cloche-agent:latest"))
+	assert.Len(t, rt.pulled, 1)
+}
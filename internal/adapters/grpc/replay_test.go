@@ -0,0 +1,218 @@
+package grpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/cloche-dev/cloche/api/clochepb"
+	"github.com/cloche-dev/cloche/internal/adapters/sqlite"
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/logstream"
+	"github.com/cloche-dev/cloche/internal/ports"
+	"github.com/cloche-dev/cloche/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayRuntime serves a recorded agent status stream as a container's output.
+type replayRuntime struct {
+	ports.ContainerRuntime
+	output  io.ReadCloser
+	removed bool
+}
+
+func (r *replayRuntime) AttachOutput(context.Context, string) (io.ReadCloser, error) {
+	return r.output, nil
+}
+
+func (r *replayRuntime) Wait(context.Context, string) (int, error)              { return 0, nil }
+func (r *replayRuntime) CopyFrom(context.Context, string, string, string) error { return nil }
+func (r *replayRuntime) Logs(context.Context, string) (string, error)           { return "", nil }
+
+func (r *replayRuntime) Remove(context.Context, string) error {
+	r.removed = true
+	return nil
+}
+
+// replayResult is what a replayed run left behind. Log lines are grouped by
+// type because stdout lines and agent session lines reach the broadcaster from
+// different goroutines; only the order within each type is deterministic.
+type replayResult struct {
+	run      *domain.Run
+	captures []*domain.StepExecution
+	lines    map[string][]string // live log line contents by type
+	removed  bool                // whether the container was removed
+}
+
+// replayFixture plays testdata/replay/<name>/status.jsonl through trackRun the
+// way an agent emits it: every message on the container's stdout, and step
+// events also over the agent session. The fixture's develop.cloche is
+// installed as the run's workflow.
+func replayFixture(t *testing.T, name string) replayResult {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	fixture := filepath.Join("testdata", "replay", name)
+
+	projectDir := t.TempDir()
+	wfSrc, err := os.ReadFile(filepath.Join(fixture, "develop.cloche"))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".cloche"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".cloche", "develop.cloche"), wfSrc, 0644))
+
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	ctx := context.Background()
+	run := domain.NewRun("develop", "develop")
+	run.ProjectDir = projectDir
+	run.Start()
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	pr, pw := io.Pipe()
+	rt := &replayRuntime{output: pr}
+	s := NewClocheServerWithCaptures(store, store, rt, "cloche-agent:latest")
+	s.SetLogBroadcaster(logstream.NewBroadcaster())
+	s.logBroadcast.Start(run.ID)
+	sub := s.logBroadcast.Subscribe(run.ID)
+
+	tracked := make(chan struct{})
+	go func() {
+		defer close(tracked)
+		s.trackRun(run.ID, "container-1", projectDir, "develop", false)
+	}()
+
+	f, err := os.Open(filepath.Join(fixture, "status.jsonl"))
+	require.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var msg protocol.StatusMessage
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		_, err := fmt.Fprintf(pw, "%s\n", scanner.Bytes())
+		require.NoError(t, err)
+
+		switch msg.Type {
+		case protocol.MsgStepStarted:
+			s.recordStepStart(ctx, run.ID, msg.StepName, 0)
+		case protocol.MsgStepCompleted:
+			result := &pb.StepResult{Result: msg.Result}
+			if msg.InputTokens != 0 || msg.OutputTokens != 0 {
+				result.TokenUsage = &pb.TokenUsage{InputTokens: msg.InputTokens, OutputTokens: msg.OutputTokens}
+			}
+			s.recordStepComplete(ctx, run.ID, msg.StepName, result, 0, 0)
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.NoError(t, pw.Close())
+
+	select {
+	case <-tracked:
+	case <-time.After(10 * time.Second):
+		t.Fatal("trackRun did not finish after the status stream ended")
+	}
+
+	res := replayResult{lines: map[string][]string{}, removed: rt.removed}
+	for line := range sub.C {
+		res.lines[line.Type] = append(res.lines[line.Type], line.Content)
+	}
+	res.run, err = store.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	res.captures, err = store.GetCaptures(ctx, run.ID)
+	require.NoError(t, err)
+	return res
+}
+
+// completedSteps lists the "step:result" of each completion capture, in order.
+func completedSteps(t *testing.T, captures []*domain.StepExecution) []string {
+	t.Helper()
+	var steps []string
+	for _, c := range captures {
+		if c.CompletedAt.IsZero() {
+			continue
+		}
+		assert.False(t, c.InvalidResult, "step %s result %q should be declared", c.StepName, c.Result)
+		steps = append(steps, c.StepName+":"+c.Result)
+	}
+	return steps
+}
+
+func TestReplay_Success(t *testing.T) {
+	res := replayFixture(t, "success")
+
+	assert.Equal(t, domain.RunStateSucceeded, res.run.State)
+	assert.Equal(t, "Add a greeting endpoint", res.run.Title)
+	assert.Empty(t, res.run.ErrorMessage)
+	assert.Equal(t, 2, res.run.StepCount)
+	assert.Equal(t, 2, res.run.StepAttempts)
+	assert.False(t, res.run.ContainerKept)
+	assert.True(t, res.removed, "a succeeded run's container is removed")
+
+	assert.Equal(t, []string{"implement:success", "test:success"}, completedSteps(t, res.captures))
+	for _, c := range res.captures {
+		if c.StepName == "implement" && !c.CompletedAt.IsZero() {
+			require.NotNil(t, c.Usage)
+			assert.Equal(t, int64(5120), c.Usage.InputTokens)
+			assert.Equal(t, int64(830), c.Usage.OutputTokens)
+		}
+	}
+
+	assert.Equal(t, []string{"Reading handlers.go", "Added GET /greeting"}, res.lines["llm"])
+	assert.Equal(t, []string{
+		"step_started: implement",
+		"step_completed: implement -> success",
+		"step_started: test",
+		"step_completed: test -> success",
+	}, res.lines["status"])
+}
+
+func TestReplay_RetryThenFail(t *testing.T) {
+	res := replayFixture(t, "retry-then-fail")
+
+	assert.Equal(t, domain.RunStateFailed, res.run.State)
+	assert.Equal(t, "step fix gave up after 1 attempt", res.run.ErrorMessage)
+	assert.Equal(t, 3, res.run.StepCount)
+	assert.Equal(t, 5, res.run.StepAttempts)
+	assert.True(t, res.run.ContainerKept, "a failed run's container is kept for debugging")
+	assert.False(t, res.removed)
+
+	assert.Equal(t, []string{
+		"implement:success",
+		"test:fail",
+		"fix:success",
+		"test:fail",
+		"fix:give-up",
+	}, completedSteps(t, res.captures))
+
+	assert.Equal(t, []string{"TestGreeting expects a trailing newline"}, res.lines["llm"])
+	assert.Equal(t, []string{
+		"step_started: implement",
+		"step_completed: implement -> success",
+		"step_started: test",
+		"step_completed: test -> fail",
+		"step_started: fix",
+		"step_completed: fix -> success",
+		"step_started: test",
+		"step_completed: test -> fail",
+		"step_started: fix",
+		"step_completed: fix -> give-up",
+	}, res.lines["status"])
+}
+
+func TestReplay_Cancelled(t *testing.T) {
+	res := replayFixture(t, "cancelled")
+
+	assert.Equal(t, domain.RunStateCancelled, res.run.State)
+	assert.Empty(t, res.run.ErrorMessage)
+	assert.True(t, res.run.ContainerKept, "a cancelled run's container is kept for debugging")
+	assert.False(t, res.removed)
+
+	assert.Equal(t, []string{"implement:fail"}, completedSteps(t, res.captures))
+}
@@ -4,12 +4,14 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,6 +32,7 @@ import (
 	"github.com/cloche-dev/cloche/internal/ports"
 	"github.com/cloche-dev/cloche/internal/project"
 	"github.com/cloche-dev/cloche/internal/protocol"
+	"github.com/cloche-dev/cloche/internal/runcontext"
 	"github.com/cloche-dev/cloche/internal/version"
 	rpcgrpc "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -47,6 +50,7 @@ type ClocheServer struct {
 	container       ports.ContainerRuntime
 	pool            *docker.ContainerPool // optional; manages agent sessions for DaemonExecutor
 	defaultImage    string
+	defaultNetwork  string // [daemon] default_network; see SetDefaultNetwork
 	evolution       *evolution.Trigger
 	logBroadcast    *logstream.Broadcaster
 	shutdownFn      func()
@@ -58,6 +62,7 @@ type ClocheServer struct {
 	loops           map[string]*host.Loop          // project_dir -> orchestration loop
 	activityLoggers map[string]*activitylog.Logger // project_dir -> activity logger
 	projects        map[string]*domain.Project     // project_dir -> cached project (refreshed on run)
+	workflowSlots   workflowLimiter                // per-workflow max_concurrent queues
 
 	// extractResultsFn is the function used to extract run results. Defaults to
 	// docker.ExtractResults; may be overridden in tests.
@@ -225,6 +230,13 @@ func (s *ClocheServer) SetShutdownFunc(fn func()) {
 	s.shutdownFn = fn
 }
 
+// SetDefaultNetwork sets the network mode for containers of workflows that
+// configure no networking themselves ([daemon] default_network). Empty leaves
+// it to the runtime's default.
+func (s *ClocheServer) SetDefaultNetwork(mode string) {
+	s.defaultNetwork = mode
+}
+
 // SetContainerPool attaches a ContainerPool so the AgentSession handler can
 // register agent streams for step dispatch by the DaemonExecutor.
 func (s *ClocheServer) SetContainerPool(pool *docker.ContainerPool) {
@@ -296,6 +308,42 @@ func (s *ClocheServer) AgentSession(stream pb.ClocheService_AgentSessionServer)
 	// Populated on StepStarted, consumed on StepResult.
 	pendingStepNames := make(map[string]string) // reqID → stepName
 
+	// The agent numbers its step events from 1 for each session. runSeq
+	// offsets them past the captures the run already has, so events from a
+	// restarted agent still sort after the earlier ones.
+	seqBase := int64(-1)
+	runSeq := func(rid string, seq int64) int64 {
+		if seq == 0 || s.captures == nil {
+			return 0
+		}
+		if seqBase < 0 {
+			base, err := s.captures.MaxCaptureSeq(ctx, rid)
+			if err != nil {
+				log.Printf("agent session: reading capture sequence for run %s: %v", rid, err)
+			}
+			seqBase = base
+		}
+		return seqBase + seq
+	}
+
+	// promptAttempt numbers the prompts saved under [runs] save_prompts. The
+	// setting and the run's earlier attempts are read on the first prompt, so
+	// a restarted agent keeps counting where the previous one stopped. Zero
+	// means the prompt is not saved.
+	var promptAttempts map[string]int
+	promptsLoaded := false
+	promptAttempt := func(rid, stepName string) int {
+		if !promptsLoaded {
+			promptsLoaded = true
+			promptAttempts = s.promptAttempts(ctx, rid)
+		}
+		if promptAttempts == nil {
+			return 0
+		}
+		promptAttempts[stepName]++
+		return promptAttempts[stepName]
+	}
+
 	// Loop receiving messages from the agent.
 	for {
 		msg, err := stream.Recv()
@@ -318,8 +366,11 @@ func (s *ClocheServer) AgentSession(stream pb.ClocheService_AgentSessionServer)
 			if started.RequestId != "" && started.StepName != "" {
 				pendingStepNames[started.RequestId] = started.StepName
 			}
+			if started.RequestId != "" {
+				s.pool.DeliverStarted(containerID, started.RequestId)
+			}
 			if rid := resolveRunID(); rid != "" && started.StepName != "" {
-				s.recordStepStart(ctx, rid, started.StepName)
+				s.recordStepStart(ctx, rid, started.StepName, runSeq(rid, started.Seq))
 			}
 
 		case *pb.AgentMessage_StepLog:
@@ -346,7 +397,11 @@ func (s *ClocheServer) AgentSession(stream pb.ClocheService_AgentSessionServer)
 			stepName := pendingStepNames[result.RequestId]
 			delete(pendingStepNames, result.RequestId)
 			if rid := resolveRunID(); rid != "" && stepName != "" {
-				s.recordStepComplete(ctx, rid, stepName, result)
+				attempt := 0
+				if result.PromptText != "" {
+					attempt = promptAttempt(rid, stepName)
+				}
+				s.recordStepComplete(ctx, rid, stepName, result, runSeq(rid, result.Seq), attempt)
 			}
 			s.pool.DeliverResult(containerID, result)
 
@@ -387,18 +442,19 @@ func (s *ClocheServer) AgentSession(stream pb.ClocheService_AgentSessionServer)
 
 // recordStepStart records that a step has started: updates the run in the store,
 // saves a capture entry, and broadcasts a log line to live-stream subscribers.
-func (s *ClocheServer) recordStepStart(ctx context.Context, runID, stepName string) {
+func (s *ClocheServer) recordStepStart(ctx context.Context, runID, stepName string, seq int64) {
 	run, err := s.store.GetRun(ctx, runID)
 	if err != nil {
 		return
 	}
 	now := time.Now()
 	run.RecordStepStart(stepName)
-	_ = s.store.UpdateRun(ctx, run)
+	_ = s.store.UpdateActiveSteps(ctx, runID, run.ActiveSteps)
 	if s.captures != nil {
 		_ = s.captures.SaveCapture(ctx, runID, &domain.StepExecution{
 			StepName:  stepName,
 			StartedAt: now,
+			Seq:       seq,
 		})
 	}
 	if s.logBroadcast != nil {
@@ -414,8 +470,10 @@ func (s *ClocheServer) recordStepStart(ctx context.Context, runID, stepName stri
 // recordStepComplete records that a step has completed: updates the run in the
 // store, saves a capture entry with optional token usage, and broadcasts a log
 // line to live-stream subscribers. When result.Skipped is true, the step is
-// recorded as skipped rather than completed.
-func (s *ClocheServer) recordStepComplete(ctx context.Context, runID, stepName string, result *pb.StepResult) {
+// recorded as skipped rather than completed. seq orders the capture within the
+// run; zero lets the store assign the next one. A non-zero promptAttempt saves
+// result.PromptText as that attempt's prompt.
+func (s *ClocheServer) recordStepComplete(ctx context.Context, runID, stepName string, result *pb.StepResult, seq int64, promptAttempt int) {
 	run, err := s.store.GetRun(ctx, runID)
 	if err != nil {
 		return
@@ -426,21 +484,39 @@ func (s *ClocheServer) recordStepComplete(ctx context.Context, runID, stepName s
 	} else {
 		run.RecordStepComplete(stepName, result.Result)
 	}
-	_ = s.store.UpdateRun(ctx, run)
+	_ = s.store.UpdateActiveSteps(ctx, runID, run.ActiveSteps)
 	if s.captures != nil {
 		exec := &domain.StepExecution{
 			StepName:    stepName,
 			Result:      result.Result,
 			Skipped:     result.Skipped,
 			CompletedAt: now,
+			Seq:         seq,
+			GitRef:      result.GitRef,
+		}
+		step := loadWorkflowStep(run.ProjectDir, run.WorkflowName, stepName)
+		if step != nil && !stepDeclaresResult(step, result.Result) {
+			log.Printf("run %s: step %q reported undeclared result %q (declared: %v)", runID, stepName, result.Result, step.Results)
+			exec.InvalidResult = true
 		}
 		if result.TokenUsage != nil {
+			var agentName string
+			if step != nil {
+				agentName = step.Config["agent"]
+			}
 			exec.Usage = &domain.TokenUsage{
 				InputTokens:  result.TokenUsage.InputTokens,
 				OutputTokens: result.TokenUsage.OutputTokens,
-				AgentName:    agentNameForStep(run.ProjectDir, run.WorkflowName, stepName),
+				AgentName:    agentName,
 			}
 		}
+		if result.ExitCode != nil {
+			code := int(*result.ExitCode)
+			exec.ExitCode = &code
+		}
+		if result.PromptText != "" && promptAttempt > 0 {
+			exec.PromptPath = savePrompt(run, stepName, promptAttempt, result.PromptText)
+		}
 		_ = s.captures.SaveCapture(ctx, runID, exec)
 	}
 	statusMsg := "step_completed: " + stepName + " -> " + result.Result
@@ -457,27 +533,86 @@ func (s *ClocheServer) recordStepComplete(ctx context.Context, runID, stepName s
 	}
 }
 
-// agentNameForStep returns the agent name configured for the given step in the
-// workflow file. Returns an empty string if the workflow cannot be loaded or
-// the step has no agent configured.
-func agentNameForStep(projectDir, workflowName, stepName string) string {
-	if projectDir == "" || workflowName == "" || stepName == "" {
+// promptAttempts returns how many times each step of runID has completed so
+// far, for numbering saved prompts, or nil when the run's project does not set
+// [runs] save_prompts.
+func (s *ClocheServer) promptAttempts(ctx context.Context, runID string) map[string]int {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil || !config.SavePromptsEnabled(run.ProjectDir) {
+		return nil
+	}
+	counts := make(map[string]int)
+	if captures, err := s.captures.GetCaptures(ctx, runID); err == nil {
+		for _, c := range captures {
+			if !c.CompletedAt.IsZero() {
+				counts[c.StepName]++
+			}
+		}
+	}
+	return counts
+}
+
+// savePrompt writes the prompt an agent step received to
+// .cloche/<run-id>/prompts/<step>.<attempt>.txt. Returns the project-relative
+// path, or "" when nothing was saved.
+func savePrompt(run *domain.Run, stepName string, attempt int, promptText string) string {
+	rel := filepath.Join(".cloche", run.ID, "prompts", fmt.Sprintf("%s.%d.txt", stepName, attempt))
+	path := filepath.Join(run.ProjectDir, rel)
+	if err := docker.MkdirOwned(filepath.Dir(path)); err != nil {
+		log.Printf("run %s: failed to save prompt for step %q: %v", run.ID, stepName, err)
+		return ""
+	}
+	if err := os.WriteFile(path, []byte(promptText), 0644); err != nil {
+		log.Printf("run %s: failed to save prompt for step %q: %v", run.ID, stepName, err)
 		return ""
 	}
+	return rel
+}
+
+// loadWorkflowStep parses the container workflow file and returns the named
+// step, or nil when the workflow cannot be loaded or has no such step.
+func loadWorkflowStep(projectDir, workflowName, stepName string) *domain.Step {
+	if projectDir == "" || workflowName == "" || stepName == "" {
+		return nil
+	}
 	wfPath := filepath.Join(projectDir, ".cloche", workflowName+".cloche")
 	data, err := os.ReadFile(wfPath)
 	if err != nil {
-		return ""
+		return nil
 	}
 	wf, err := dsl.ParseForContainer(string(data))
 	if err != nil {
-		return ""
+		return nil
 	}
-	step, ok := wf.Steps[stepName]
-	if !ok {
-		return ""
+	return wf.Steps[stepName]
+}
+
+// checkContainerParams checks that every param override names a param the
+// container workflow declares. The agent applies the overrides inside the
+// container.
+func checkContainerParams(projectDir, workflowName string, params map[string]string) error {
+	if len(params) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(projectDir, ".cloche", workflowName+".cloche"))
+	if err != nil {
+		return err
+	}
+	wf, err := dsl.ParseForContainer(string(data))
+	if err != nil {
+		return err
+	}
+	return wf.OverrideParams(params)
+}
+
+// stepDeclaresResult reports whether result is one of the step's declared results.
+func stepDeclaresResult(step *domain.Step, result string) bool {
+	for _, r := range step.Results {
+		if r == result {
+			return true
+		}
 	}
-	return step.Config["agent"]
+	return false
 }
 
 // failInFlightSteps marks every active step in the run as failed. This is
@@ -604,11 +739,23 @@ func (s *ClocheServer) RunWorkflow(ctx context.Context, req *pb.RunWorkflowReque
 
 	// Check if this is a host workflow (has host {} block).
 	if hostWFs, err := host.FindHostWorkflows(req.ProjectDir); err == nil {
-		if _, isHost := hostWFs[workflowName]; isHost {
+		if wf, isHost := hostWFs[workflowName]; isHost {
+			// Check overrides now; the runner applies them in the background
+			// where an error could only be logged.
+			if err := wf.OverrideParams(req.Params); err != nil {
+				return nil, err
+			}
 			return s.runHostWorkflow(ctx, req)
 		}
 	}
 
+	if err := host.CheckWorkflowFile(req.ProjectDir, workflowName); err != nil {
+		return nil, err
+	}
+	if err := checkContainerParams(req.ProjectDir, workflowName, req.Params); err != nil {
+		return nil, err
+	}
+
 	if s.container == nil {
 		return nil, fmt.Errorf("no container runtime configured")
 	}
@@ -636,7 +783,7 @@ func (s *ClocheServer) RunWorkflow(ctx context.Context, req *pb.RunWorkflowReque
 	// Write prompt to .cloche/runs/<task-id>/prompt.txt
 	if req.Prompt != "" {
 		promptPath := filepath.Join(req.ProjectDir, ".cloche", "runs", run.TaskID, "prompt.txt")
-		if err := os.MkdirAll(filepath.Dir(promptPath), 0755); err != nil {
+		if err := docker.MkdirOwned(filepath.Dir(promptPath)); err != nil {
 			return nil, fmt.Errorf("creating runs dir: %w", err)
 		}
 		if err := os.WriteFile(promptPath, []byte(req.Prompt), 0644); err != nil {
@@ -649,7 +796,7 @@ func (s *ClocheServer) RunWorkflow(ctx context.Context, req *pb.RunWorkflowReque
 
 	// Set temp_file_dir built-in KV key and create the directory.
 	tempFileDir := filepath.Join(".cloche", "runs", runID)
-	if err := os.MkdirAll(filepath.Join(req.ProjectDir, tempFileDir), 0755); err != nil {
+	if err := docker.MkdirOwned(filepath.Join(req.ProjectDir, tempFileDir)); err != nil {
 		log.Printf("run %s: creating temp_file_dir: %v", runID, err)
 	} else if err := s.store.SetContextKey(ctx, run.TaskID, run.AttemptID, runID, "temp_file_dir", tempFileDir); err != nil {
 		log.Printf("run %s: seeding temp_file_dir: %v", runID, err)
@@ -672,6 +819,69 @@ func (s *ClocheServer) RunWorkflow(ctx context.Context, req *pb.RunWorkflowReque
 	return &pb.RunWorkflowResponse{RunId: runID, TaskId: run.TaskID, AttemptId: run.AttemptID}, nil
 }
 
+// runSyncPollInterval is how often RunWorkflowSync re-reads the run record
+// while waiting for it to finish.
+var runSyncPollInterval = 500 * time.Millisecond
+
+// RunWorkflowSync starts a workflow via RunWorkflow and blocks until the run
+// reaches a terminal state. If the client's context ends first, the context
+// error is returned and the run is left to continue in the background.
+func (s *ClocheServer) RunWorkflowSync(ctx context.Context, req *pb.RunWorkflowRequest) (*pb.RunWorkflowSyncResponse, error) {
+	started, err := s.RunWorkflow(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.waitForRun(ctx, started.RunId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.RunWorkflowSyncResponse{
+		RunId:        run.ID,
+		TaskId:       started.TaskId,
+		AttemptId:    started.AttemptId,
+		State:        string(run.State),
+		ErrorMessage: run.ErrorMessage,
+	}
+	if resp.TaskId == "" {
+		resp.TaskId = run.TaskID
+	}
+	if resp.AttemptId == "" {
+		resp.AttemptId = run.AttemptID
+	}
+	return resp, nil
+}
+
+// waitForRun polls the store until the run is succeeded, failed, or
+// cancelled. Host runs are recorded by a background goroutine, so lookup
+// errors are tolerated until the record first appears.
+func (s *ClocheServer) waitForRun(ctx context.Context, runID string) (*domain.Run, error) {
+	ticker := time.NewTicker(runSyncPollInterval)
+	defer ticker.Stop()
+
+	seen := false
+	for {
+		run, err := s.store.GetRun(ctx, runID)
+		switch {
+		case err == nil:
+			seen = true
+			switch run.State {
+			case domain.RunStateSucceeded, domain.RunStateFailed, domain.RunStateCancelled:
+				return run, nil
+			}
+		case seen && ctx.Err() == nil:
+			return nil, fmt.Errorf("reading run %s: %w", runID, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 // runHostWorkflow dispatches a host workflow via the host runner, returning
 // immediately while the workflow runs in a background goroutine.
 func (s *ClocheServer) runHostWorkflow(ctx context.Context, req *pb.RunWorkflowRequest) (*pb.RunWorkflowResponse, error) {
@@ -699,6 +909,7 @@ func (s *ClocheServer) runHostWorkflow(ctx context.Context, req *pb.RunWorkflowR
 		Executor:     s.daemonExecutorFor(req.ProjectDir, taskID, attemptID),
 		TaskID:       taskID,
 		AttemptID:    attemptID,
+		Params:       req.Params,
 	}
 
 	runCtx, cancel := context.WithCancel(context.Background())
@@ -1242,9 +1453,11 @@ func (s *ClocheServer) runResumedContainerWorkflow(
 		WorkflowName: wf.Name,
 		AttemptID:    run.AttemptID,
 		TaskID:       run.TaskID,
-		NetworkAllow: []string{"*"},
+		Labels:       wf.Labels,
+		SavePrompts:  config.SavePromptsEnabled(run.ProjectDir),
 		// ProjectDir intentionally empty: committed image has the workspace state.
 	}
+	cfg.Network, cfg.NetworkAllow = resolveNetwork(wf, s.defaultNetwork)
 
 	session, err := s.pool.StartFromImage(ctx, poolKey, image, cfg)
 	if err != nil {
@@ -1282,12 +1495,13 @@ func (s *ClocheServer) runResumedContainerWorkflow(
 
 	// Run the engine with the DaemonExecutor in resume mode.
 	exec := NewDaemonExecutor(DaemonExecutorConfig{
-		Pool:       s.pool,
-		ProjectDir: run.ProjectDir,
-		AttemptID:  run.AttemptID,
-		Image:      image,
-		AllWFs:     allWFs,
-		ResumeMode: true,
+		Pool:           s.pool,
+		ProjectDir:     run.ProjectDir,
+		AttemptID:      run.AttemptID,
+		Image:          image,
+		AllWFs:         allWFs,
+		ResumeMode:     true,
+		DefaultNetwork: s.defaultNetwork,
 	})
 	eng := engine.New(exec)
 	eng.SetPreloadedResults(preloaded)
@@ -1303,6 +1517,8 @@ func (s *ClocheServer) runResumedContainerWorkflow(
 			errMsg := ""
 			if runErr != nil {
 				errMsg = runErr.Error()
+			} else if finalRun != nil {
+				errMsg = finalRun.ErrorMessage
 			}
 			storedRun.Fail(errMsg)
 		}
@@ -1401,24 +1617,50 @@ func (s *ClocheServer) resumeContainerRunLegacy(ctx context.Context, run *domain
 	return &pb.RunWorkflowResponse{RunId: newRunID, AttemptId: newAttempt.ID}, nil
 }
 
+// workflowLabels returns the container labels declared by the named workflow,
+// or nil when the workflow cannot be loaded.
+func workflowLabels(projectDir, workflowName string) []string {
+	wfs, err := host.FindAllWorkflows(projectDir)
+	if err != nil {
+		return nil
+	}
+	if wf, ok := wfs[workflowName]; ok {
+		return wf.Labels
+	}
+	return nil
+}
+
 // launchResumeContainer starts a new container from a committed image with
 // the resume command, then tracks it to completion.
 func (s *ClocheServer) launchResumeContainer(run *domain.Run, image string, cmd []string) {
 	ctx := context.Background()
 
-	containerID, err := s.container.Start(ctx, ports.ContainerConfig{
+	release, err := s.waitForWorkflowSlot(ctx, run.ProjectDir, run.WorkflowName, run.ID)
+	if err != nil {
+		log.Printf("run %s: not starting resume container: %v", run.ID, err)
+		return
+	}
+	defer release()
+
+	network, networkAllow := s.workflowNetwork(run.ProjectDir, run.WorkflowName)
+	containerID, err := s.startContainer(ctx, run.ProjectDir, run.ID, ports.ContainerConfig{
 		Image:        image,
 		WorkflowName: run.WorkflowName,
 		ProjectDir:   run.ProjectDir,
 		RunID:        run.ID,
 		TaskID:       run.TaskID,
 		AttemptID:    run.AttemptID,
-		NetworkAllow: []string{"*"},
+		Network:      network,
+		NetworkAllow: networkAllow,
 		Cmd:          cmd,
+		Labels:       workflowLabels(run.ProjectDir, run.WorkflowName),
+		SavePrompts:  config.SavePromptsEnabled(run.ProjectDir),
 	})
 	if err != nil {
-		run.Fail(fmt.Sprintf("failed to start resume container: %v", err))
-		_ = s.store.UpdateRun(ctx, run)
+		if cur, _ := s.store.GetRun(ctx, run.ID); cur != nil {
+			cur.Fail(fmt.Sprintf("failed to start resume container: %v", err))
+			_ = s.store.UpdateRun(ctx, cur)
+		}
 		log.Printf("run %s: failed to start resume container: %v", run.ID, err)
 		return
 	}
@@ -1428,8 +1670,11 @@ func (s *ClocheServer) launchResumeContainer(run *domain.Run, image string, cmd
 	s.containerRun[containerID] = run.ID
 	s.mu.Unlock()
 
-	run.ContainerID = containerID
-	_ = s.store.UpdateRun(ctx, run)
+	if err := s.markRunStarted(ctx, run.ID, containerID, run.BaseSHA); err != nil {
+		log.Printf("run %s: discarding resume container %s: %v", run.ID, containerID, err)
+		s.discardStartedContainer(ctx, run.ID, containerID, err)
+		return
+	}
 
 	s.trackRun(run.ID, containerID, run.ProjectDir, run.WorkflowName, true)
 }
@@ -1444,6 +1689,18 @@ func (s *ClocheServer) launchAndTrack(runID, image string, keepContainer bool, s
 	// Parse the workflow name (strip any ":step" suffix that was already extracted).
 	workflowName, _, _ := strings.Cut(req.WorkflowName, ":")
 
+	// Runs of a workflow with max_concurrent stay pending until a slot frees
+	// up. The slot is held until trackRun returns.
+	release, err := s.waitForWorkflowSlot(ctx, req.ProjectDir, workflowName, runID)
+	if err != nil {
+		log.Printf("run %s: not starting container: %v", runID, err)
+		if s.logBroadcast != nil {
+			s.logBroadcast.Finish(runID)
+		}
+		return
+	}
+	defer release()
+
 	// Auto-rebuild image if the project Dockerfile has changed since last build.
 	if ensurer, ok := s.container.(ports.ImageEnsurer); ok {
 		if err := ensurer.EnsureImage(ctx, req.ProjectDir, image); err != nil {
@@ -1461,6 +1718,19 @@ func (s *ClocheServer) launchAndTrack(runID, image string, keepContainer bool, s
 		}
 	}
 
+	if err := s.pullImage(ctx, req.ProjectDir, runID, image); err != nil {
+		run, _ := s.store.GetRun(ctx, runID)
+		if run != nil {
+			run.Fail(fmt.Sprintf("failed to pull image: %v", err))
+			_ = s.store.UpdateRun(ctx, run)
+		}
+		if s.logBroadcast != nil {
+			s.logBroadcast.Finish(runID)
+		}
+		log.Printf("run %s: failed to pull image: %v", runID, err)
+		return
+	}
+
 	baseSHA := gitHEAD(req.ProjectDir)
 
 	// Look up the task/attempt IDs from the run record for container env and naming.
@@ -1504,16 +1774,22 @@ func (s *ClocheServer) launchAndTrack(runID, image string, keepContainer bool, s
 		log.Printf("run %s: no baseSHA resolved for %s, seeding container from live tree", runID, req.ProjectDir)
 	}
 
-	containerID, err := s.container.Start(ctx, ports.ContainerConfig{
+	network, networkAllow := s.workflowNetwork(req.ProjectDir, workflowName)
+	containerID, err := s.startContainer(ctx, req.ProjectDir, runID, ports.ContainerConfig{
 		Image:        image,
 		WorkflowName: workflowName,
 		ProjectDir:   seedDir,
 		RunID:        runID,
 		TaskID:       taskID,
 		AttemptID:    attemptID,
-		NetworkAllow: []string{"*"},
+		Network:      network,
+		NetworkAllow: networkAllow,
 		Cmd:          cmd,
 		Prompt:       req.Prompt,
+		Labels:       workflowLabels(req.ProjectDir, workflowName),
+		PromptChars:  promptOutputLimit(req.ProjectDir),
+		SavePrompts:  config.SavePromptsEnabled(req.ProjectDir),
+		Params:       req.Params,
 	})
 	if err != nil {
 		run, _ := s.store.GetRun(ctx, runID)
@@ -1534,12 +1810,13 @@ func (s *ClocheServer) launchAndTrack(runID, image string, keepContainer bool, s
 	s.containerRun[containerID] = runID
 	s.mu.Unlock()
 
-	run, _ := s.store.GetRun(ctx, runID)
-	if run != nil {
-		run.Start()
-		run.ContainerID = containerID
-		run.BaseSHA = baseSHA
-		_ = s.store.UpdateRun(ctx, run)
+	if err := s.markRunStarted(ctx, runID, containerID, baseSHA); err != nil {
+		log.Printf("run %s: discarding container %s: %v", runID, containerID, err)
+		s.discardStartedContainer(ctx, runID, containerID, err)
+		if s.logBroadcast != nil {
+			s.logBroadcast.Finish(runID)
+		}
+		return
 	}
 
 	// Pre-create the extraction worktree+branch now that we have baseSHA. The
@@ -1565,6 +1842,67 @@ func (s *ClocheServer) launchAndTrack(runID, image string, keepContainer bool, s
 	s.trackRun(runID, containerID, req.ProjectDir, workflowName, keepContainer)
 }
 
+// errRunNotPending reports that a run left the pending state (e.g. it was
+// stopped) while its container was starting.
+var errRunNotPending = errors.New("run is no longer pending")
+
+// markRunStarted moves a pending run to running and records its container.
+// The read and the write happen in one transaction when the store supports
+// it, so a concurrent StopRun is never overwritten.
+//
+// Recovery semantics for the create → start → update sequence:
+//   - daemon crash before the update: the run is still pending and the
+//     startup sweep marks it failed;
+//   - container start fails: launchAndTrack (or launchResumeContainer)
+//     marks the run failed;
+//   - the update fails or the run is no longer pending: the caller discards
+//     the container (discardStartedContainer), so no container keeps running
+//     against a stale row.
+func (s *ClocheServer) markRunStarted(ctx context.Context, runID, containerID, baseSHA string) error {
+	update := func(store ports.RunStore) error {
+		run, err := store.GetRun(ctx, runID)
+		if err != nil {
+			return err
+		}
+		if run.State != domain.RunStatePending {
+			return fmt.Errorf("%w (state %s)", errRunNotPending, run.State)
+		}
+		run.Start()
+		run.ContainerID = containerID
+		run.BaseSHA = baseSHA
+		return store.UpdateRun(ctx, run)
+	}
+	if tx, ok := s.store.(ports.RunTransactor); ok {
+		return tx.Transaction(ctx, update)
+	}
+	return update(s.store)
+}
+
+// discardStartedContainer stops and removes a container whose run could not
+// be marked running, drops it from the tracking maps, and fails the run if it
+// is still pending. A run that was stopped meanwhile keeps its state.
+func (s *ClocheServer) discardStartedContainer(ctx context.Context, runID, containerID string, cause error) {
+	if err := s.container.Stop(ctx, containerID); err != nil {
+		log.Printf("run %s: stopping discarded container %s: %v", runID, containerID, err)
+	}
+	if err := s.container.Remove(ctx, containerID); err != nil {
+		log.Printf("run %s: removing discarded container %s: %v", runID, containerID, err)
+	}
+
+	s.mu.Lock()
+	delete(s.runIDs, runID)
+	delete(s.containerRun, containerID)
+	s.mu.Unlock()
+
+	if errors.Is(cause, errRunNotPending) {
+		return
+	}
+	if run, err := s.store.GetRun(ctx, runID); err == nil && run.State == domain.RunStatePending {
+		run.Fail(fmt.Sprintf("failed to record container start: %v", cause))
+		_ = s.store.UpdateRun(ctx, run)
+	}
+}
+
 // runLogDir returns the directory where extracted log files for a run are stored.
 // For v2 runs (with AttemptID and TaskID), uses .cloche/logs/<taskID>/<attemptID>/.
 // Falls back to the legacy .cloche/<runID>/output/ path for older runs.
@@ -1575,9 +1913,52 @@ func runLogDir(run *domain.Run, projectDir, runID string) string {
 	return filepath.Join(projectDir, ".cloche", runID, "output")
 }
 
+// deadLetterRun fails a run whose tracker could not follow it to completion,
+// because its output could not be attached or trackRun panicked, so no run is
+// left running with nobody watching it. The container is stopped and removed,
+// live-log subscribers are released and the project's loop is halted.
+func (s *ClocheServer) deadLetterRun(runID, containerID, projectDir, reason string) {
+	ctx := context.Background()
+	log.Printf("run %s: abandoning run: %s", runID, reason)
+
+	if run, err := s.store.GetRun(ctx, runID); err == nil && run != nil && !run.IsTerminal() {
+		run.Fail(reason)
+		if err := s.store.UpdateRun(ctx, run); err != nil {
+			log.Printf("run %s: recording failure: %v", runID, err)
+		}
+	}
+
+	if containerID != "" && s.container != nil {
+		if err := s.container.Stop(ctx, containerID); err != nil {
+			log.Printf("run %s: stopping container %s: %v", runID, containerID, err)
+		}
+		if err := s.container.Remove(ctx, containerID); err != nil {
+			log.Printf("run %s: removing container %s: %v", runID, containerID, err)
+		}
+	}
+
+	if s.logBroadcast != nil {
+		s.logBroadcast.Finish(runID)
+	}
+	s.mu.Lock()
+	delete(s.runIDs, runID)
+	delete(s.containerRun, containerID)
+	s.mu.Unlock()
+
+	s.stopProjectLoop(projectDir, fmt.Sprintf("%s for run %s", reason, runID))
+}
+
 func (s *ClocheServer) trackRun(runID, containerID, projectDir, workflowName string, keepContainer bool) {
 	ctx := context.Background()
 
+	// A panic anywhere below must not leave the run running forever.
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("run %s: tracker panicked: %v\n%s", runID, r, debug.Stack())
+			s.deadLetterRun(runID, containerID, projectDir, fmt.Sprintf("run tracker crashed: %v", r))
+		}
+	}()
+
 	// Determine the log extraction directory upfront. v2 runs with AttemptID
 	// use .cloche/logs/<taskID>/<attemptID>/; older runs use the legacy path.
 	initialRun, _ := s.store.GetRun(ctx, runID)
@@ -1591,18 +1972,7 @@ func (s *ClocheServer) trackRun(runID, containerID, projectDir, workflowName str
 	// Attach to agent output
 	reader, err := s.container.AttachOutput(ctx, containerID)
 	if err != nil {
-		log.Printf("failed to attach to output for run %s: %v", runID, err)
-		if run, rerr := s.store.GetRun(ctx, runID); rerr == nil && run != nil && run.State == domain.RunStateRunning {
-			run.Fail(fmt.Sprintf("failed to attach to container output: %v", err))
-			_ = s.store.UpdateRun(ctx, run)
-		}
-		if s.logBroadcast != nil {
-			s.logBroadcast.Finish(runID)
-		}
-		s.mu.Lock()
-		delete(s.runIDs, runID)
-		s.mu.Unlock()
-		s.stopProjectLoop(projectDir, fmt.Sprintf("container output attach failed for run %s: %v", runID, err))
+		s.deadLetterRun(runID, containerID, projectDir, fmt.Sprintf("failed to attach to container output: %v", err))
 		return
 	}
 
@@ -1654,7 +2024,7 @@ func (s *ClocheServer) trackRun(runID, containerID, projectDir, workflowName str
 	}
 
 	// Extract step output files from container before it's removed
-	if err := os.MkdirAll(outputDst, 0755); err == nil {
+	if err := docker.MkdirOwned(outputDst); err == nil {
 		if cpErr := s.container.CopyFrom(ctx, containerID, "/workspace/.cloche/output/.", outputDst); cpErr != nil {
 			log.Printf("run %s: failed to extract output: %v", runID, cpErr)
 		}
@@ -1708,7 +2078,7 @@ func (s *ClocheServer) trackRun(runID, containerID, projectDir, workflowName str
 			log.Printf("run %s: skipping branch extraction (no pre-created worktree)", runID)
 		default:
 			log.Printf("run %s: extracting results to branch %s (baseSHA=%s)", runID, wt.Branch, extractRun.BaseSHA)
-			if _, err := s.extractResultsFn(ctx, docker.ExtractOptions{
+			if res, err := s.extractResultsFn(ctx, docker.ExtractOptions{
 				ContainerID:  containerID,
 				WorktreeDir:  wt.Dir,
 				Branch:       wt.Branch,
@@ -1720,6 +2090,9 @@ func (s *ClocheServer) trackRun(runID, containerID, projectDir, workflowName str
 				log.Printf("run %s: failed to extract results to branch: %v", runID, err)
 			} else {
 				log.Printf("run %s: branch %s updated", runID, wt.Branch)
+				if err := saveRunDiff(ctx, wt.Dir, extractRun.BaseSHA, res.CommitSHA, filepath.Join(outputDst, runID+".diff")); err != nil {
+					log.Printf("run %s: saving diff: %v", runID, err)
+				}
 			}
 		}
 	}
@@ -1733,6 +2106,9 @@ func (s *ClocheServer) trackRun(runID, containerID, projectDir, workflowName str
 		unexpectedExit := false
 		if reportedResult == "succeeded" {
 			run.Complete(domain.RunStateSucceeded)
+		} else if reportedResult == string(domain.RunStateCancelled) {
+			// The agent was stopped (e.g. "docker stop") and wound down.
+			run.Complete(domain.RunStateCancelled)
 		} else if reportedResult != "" {
 			if reportedError != "" {
 				run.Fail(reportedError)
@@ -1775,6 +2151,10 @@ func (s *ClocheServer) trackRun(runID, containerID, projectDir, workflowName str
 	runFinal, _ := s.store.GetRun(ctx, runID)
 	runFailed := runFinal != nil && (runFinal.State == domain.RunStateFailed || runFinal.State == domain.RunStateCancelled)
 
+	if runFinal != nil && runFinal.State == domain.RunStateSucceeded {
+		cleanSucceededPrompt(projectDir, runFinal)
+	}
+
 	if keepContainer || runFailed {
 		reason := "--keep-container"
 		if runFailed {
@@ -1811,12 +2191,36 @@ func (s *ClocheServer) trackRun(runID, containerID, projectDir, workflowName str
 	s.mu.Unlock()
 }
 
+// cleanSucceededPrompt removes the prompt.txt written for a succeeded run when
+// the project sets [runs] clean_succeeded_prompts. The task directory is only
+// removed once empty, since other attempts of the task and their snapshots may
+// share it. Failed runs never reach here and keep their prompt for debugging.
+func cleanSucceededPrompt(projectDir string, run *domain.Run) {
+	if projectDir == "" || run.TaskID == "" {
+		return
+	}
+	cfg, err := config.Load(projectDir)
+	if err != nil || !cfg.Runs.CleanSucceededPrompts {
+		return
+	}
+	if err := os.Remove(runcontext.PromptPath(projectDir, run.TaskID)); err != nil && !os.IsNotExist(err) {
+		log.Printf("run %s: failed to remove prompt: %v", run.ID, err)
+		return
+	}
+	_ = os.Remove(runcontext.RunDir(projectDir, run.TaskID)) // fails harmlessly if not empty
+}
+
 func (s *ClocheServer) ListRuns(ctx context.Context, req *pb.ListRunsRequest) (*pb.ListRunsResponse, error) {
+	order, err := domain.ParseRunOrder(req.Sort)
+	if err != nil {
+		return nil, err
+	}
 	filter := domain.RunListFilter{
 		ProjectDir: req.ProjectDir,
 		State:      domain.RunState(req.State),
 		TaskID:     req.TaskId,
 		Limit:      int(req.Limit),
+		Order:      order,
 	}
 
 	// Unless --all is set, default to last hour
@@ -1845,6 +2249,11 @@ func (s *ClocheServer) ListRuns(ctx context.Context, req *pb.ListRunsRequest) (*
 			IsHost:       run.IsHost,
 			ProjectDir:   run.ProjectDir,
 			TaskId:       run.TaskID,
+			StepCount:    int32(run.StepCount),
+			AttemptCount: int32(run.StepAttempts),
+		}
+		if run.IsTerminal() && !run.StartedAt.IsZero() && run.CompletedAt.After(run.StartedAt) {
+			sum.DurationSeconds = int64(run.CompletedAt.Sub(run.StartedAt) / time.Second)
 		}
 		// Populate waiting step info for waiting runs.
 		if run.State == domain.RunStateWaiting && hasHPS {
@@ -2050,6 +2459,13 @@ func (s *ClocheServer) Complete(ctx context.Context, req *pb.CompleteRequest) (*
 	case "delete":
 		completions = s.recentRunIDs(ctx, projectDir)
 
+	case "rm":
+		if prev == "--workflow" || prev == "-w" {
+			completions = s.workflowNames(projectDir)
+		} else {
+			completions = []string{"--failed", "--older-than", "--workflow", "--dry-run", "--yes", "--project", "--all"}
+		}
+
 	case "resume":
 		completions = s.recentRunIDs(ctx, projectDir)
 
@@ -2409,6 +2825,24 @@ func (s *ClocheServer) findRunByTaskAndAttempt(ctx context.Context, taskID, atte
 	return nil, fmt.Errorf("no run found for task %q with attempt %q", taskID, attemptID)
 }
 
+// statusOutputLimit returns the [output] status_chars cap for projectDir.
+func statusOutputLimit(projectDir string) int {
+	cfg, err := config.LoadMerged(projectDir)
+	if err != nil {
+		return 0
+	}
+	return cfg.Output.StatusChars
+}
+
+// promptOutputLimit returns the [output] prompt_chars cap for projectDir.
+func promptOutputLimit(projectDir string) int {
+	cfg, err := config.LoadMerged(projectDir)
+	if err != nil {
+		return 0
+	}
+	return cfg.Output.PromptChars
+}
+
 func (s *ClocheServer) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
 	// Resolve the run to inspect. The Id field (task/attempt/step ID) takes
 	// priority over the legacy run_id field for backward compatibility.
@@ -2431,11 +2865,17 @@ func (s *ClocheServer) GetStatus(ctx context.Context, req *pb.GetStatusRequest)
 		WorkflowName: run.WorkflowName,
 		State:        string(run.State),
 		CurrentStep:  strings.Join(run.ActiveSteps, ","),
-		ErrorMessage: run.ErrorMessage,
+		ErrorMessage: domain.TruncateOutput(run.ErrorMessage, statusOutputLimit(run.ProjectDir)),
 		ContainerId:  run.ContainerID,
 		Title:        run.Title,
 		IsHost:       run.IsHost,
 	}
+	if !run.StartedAt.IsZero() {
+		resp.StartedAt = run.StartedAt.UTC().Format(time.RFC3339Nano)
+	}
+	if run.State == domain.RunStatePending {
+		resp.QueuePosition = int32(s.workflowSlots.position(run.ID))
+	}
 
 	// Check container liveness
 	if run.ContainerID != "" && s.container != nil {
@@ -2471,6 +2911,8 @@ func (s *ClocheServer) GetStatus(ctx context.Context, req *pb.GetStatusRequest)
 					StartedAt:   exec.StartedAt.String(),
 					CompletedAt: exec.CompletedAt.String(),
 					Skipped:     exec.Skipped,
+					PromptPath:  exec.PromptPath,
+					ExitCode:    exitCodeProto(exec.ExitCode),
 				}
 				if exec.Usage != nil {
 					se.InputTokens = exec.Usage.InputTokens
@@ -2488,6 +2930,8 @@ func (s *ClocheServer) GetStatus(ctx context.Context, req *pb.GetStatusRequest)
 				StartedAt:   exec.StartedAt.String(),
 				CompletedAt: exec.CompletedAt.String(),
 				Skipped:     exec.Skipped,
+				PromptPath:  exec.PromptPath,
+				ExitCode:    exitCodeProto(exec.ExitCode),
 			}
 			if exec.Usage != nil {
 				se.InputTokens = exec.Usage.InputTokens
@@ -2501,6 +2945,15 @@ func (s *ClocheServer) GetStatus(ctx context.Context, req *pb.GetStatusRequest)
 	return resp, nil
 }
 
+// exitCodeProto converts a step exit code to its optional wire form.
+func exitCodeProto(code *int) *int32 {
+	if code == nil {
+		return nil
+	}
+	c := int32(*code)
+	return &c
+}
+
 func (s *ClocheServer) StreamLogs(req *pb.StreamLogsRequest, stream rpcgrpc.ServerStreamingServer[pb.LogEntry]) error {
 	ctx := stream.Context()
 
@@ -2565,13 +3018,9 @@ func (s *ClocheServer) StreamLogs(req *pb.StreamLogsRequest, stream rpcgrpc.Serv
 		return fmt.Errorf("captures store not configured")
 	}
 
-	// Fall back to capture-based streaming
-	captures, err := s.captures.GetCaptures(ctx, req.RunId)
-	if err != nil {
-		return fmt.Errorf("getting captures: %w", err)
-	}
-
-	for _, exec := range captures {
+	// Fall back to capture-based streaming. Captures are streamed rather than
+	// loaded up front so long retry loops don't materialize every row.
+	err = s.captures.GetCaptureStream(ctx, req.RunId, func(exec *domain.StepExecution) error {
 		// Captures are stored as separate rows: one for step_started (no Result)
 		// and one for step_completed (has Result).
 		if exec.Result == "" {
@@ -2599,10 +3048,12 @@ func (s *ClocheServer) StreamLogs(req *pb.StreamLogsRequest, stream rpcgrpc.Serv
 					output = string(data)
 				}
 			}
-			if err := sendContentChunked(stream, "step_completed", exec.StepName, exec.Result, exec.CompletedAt.String(), applyLimit(output, limit)); err != nil {
-				return err
-			}
+			return sendContentChunked(stream, "step_completed", exec.StepName, exec.Result, exec.CompletedAt.String(), applyLimit(output, limit))
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("streaming captures: %w", err)
 	}
 
 	// Send run completion entry (re-read state in case it settled during static serving)
@@ -3330,15 +3781,23 @@ func (s *ClocheServer) daemonExecutorFor(projectDir, taskID, attemptID string) e
 	}
 	var de *DaemonExecutor
 	de = NewDaemonExecutor(DaemonExecutorConfig{
-		Pool:         s.pool,
-		Store:        s.store,
-		LogStore:     s.logStore,
-		LogBroadcast: s.logBroadcast,
-		ProjectDir:   projectDir,
-		TaskID:       taskID,
-		AttemptID:    attemptID,
-		Image:        image,
-		AllWFs:       allWFs,
+		Pool:           s.pool,
+		Store:          s.store,
+		LogStore:       s.logStore,
+		LogBroadcast:   s.logBroadcast,
+		ProjectDir:     projectDir,
+		TaskID:         taskID,
+		AttemptID:      attemptID,
+		Image:          image,
+		AllWFs:         allWFs,
+		DefaultNetwork: s.defaultNetwork,
+		PullImage: func(ctx context.Context, image string) error {
+			runID := ""
+			if de.hostExec != nil {
+				runID = de.hostExec.HostRunID
+			}
+			return s.pullImage(ctx, projectDir, runID, image)
+		},
 		OnContainerStart: func(containerID string) {
 			// Register the container → host run mapping so the AgentSession
 			// handler can route StepLog messages to the correct run for
@@ -3745,7 +4204,7 @@ func (s *ClocheServer) Shutdown(ctx context.Context, req *pb.ShutdownRequest) (*
 //   - <workflow>-<step>.log: raw LLM output lines for each step that has broadcaster
 //     content but no existing log file on disk
 func (s *ClocheServer) saveBroadcasterHistory(runID string, history []logstream.LogLine, outputDst, workflowName string) {
-	if err := os.MkdirAll(outputDst, 0755); err != nil {
+	if err := docker.MkdirOwned(outputDst); err != nil {
 		log.Printf("run %s: saveBroadcasterHistory: failed to create dir %s: %v", runID, outputDst, err)
 		return
 	}
@@ -3864,6 +4323,22 @@ func gitHEAD(dir string) string {
 	return strings.TrimSpace(string(out))
 }
 
+// saveRunDiff writes the changes between baseSHA and commitSHA to path, so
+// evolution can show the reflector what a run changed. The run's .cloche
+// directory is left out.
+func saveRunDiff(ctx context.Context, dir, baseSHA, commitSHA, path string) error {
+	if baseSHA == "" || commitSHA == "" {
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "diff", baseSHA, commitSHA, "--", ".", ":(exclude).cloche")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git diff: %w", err)
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
 // gitResolveRef resolves a ref name to its commit SHA on the project's git
 // repository. Tries the bare ref first, then `origin/<ref>` so callers can
 // pass either a local branch ("add_repos") or a remote-only ref name. Returns
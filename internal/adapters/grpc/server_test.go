@@ -152,6 +152,50 @@ func TestServer_ListRuns_FilterByTaskId(t *testing.T) {
 	assert.Equal(t, "run-task-a", resp.Runs[0].RunId)
 }
 
+func TestServer_ListRuns_StepAggregates(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	start := time.Now().Add(-90 * time.Second)
+
+	done := domain.NewRun("run-done", "wf")
+	done.State = domain.RunStateRunning
+	done.StartedAt = start
+	require.NoError(t, store.CreateRun(ctx, done))
+	for _, result := range []string{"fail", "success"} {
+		require.NoError(t, store.SaveCapture(ctx, "run-done", &domain.StepExecution{StepName: "build", Result: result, CompletedAt: time.Now()}))
+	}
+	require.NoError(t, store.SaveCapture(ctx, "run-done", &domain.StepExecution{StepName: "test", Result: "success", CompletedAt: time.Now()}))
+	done.CompletedAt = start.Add(75 * time.Second)
+	done.State = domain.RunStateSucceeded
+	require.NoError(t, store.UpdateRun(ctx, done))
+
+	active := domain.NewRun("run-active", "wf")
+	active.State = domain.RunStateRunning
+	active.StartedAt = start
+	require.NoError(t, store.CreateRun(ctx, active))
+	require.NoError(t, store.SaveCapture(ctx, "run-active", &domain.StepExecution{StepName: "build", Result: "success", CompletedAt: time.Now()}))
+
+	srv := server.NewClocheServer(store, nil)
+	resp, err := srv.ListRuns(ctx, &pb.ListRunsRequest{All: true})
+	require.NoError(t, err)
+	require.Len(t, resp.Runs, 2)
+
+	byID := map[string]*pb.RunSummary{}
+	for _, r := range resp.Runs {
+		byID[r.RunId] = r
+	}
+	assert.Equal(t, int32(2), byID["run-done"].StepCount)
+	assert.Equal(t, int32(3), byID["run-done"].AttemptCount)
+	assert.Equal(t, int64(75), byID["run-done"].DurationSeconds)
+
+	assert.Zero(t, byID["run-active"].StepCount)
+	assert.Zero(t, byID["run-active"].AttemptCount)
+	assert.Zero(t, byID["run-active"].DurationSeconds)
+}
+
 func TestServer_GetStatus_NotFound(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -222,6 +266,119 @@ func TestServer_RunWorkflow(t *testing.T) {
 	assert.Equal(t, "succeeded", status.State)
 }
 
+func TestServer_RunWorkflow_PassesParamsToContainer(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", "ci.cloche"), []byte(`workflow ci {
+  params {
+    target = "./..."
+  }
+  step test {
+    run = "go test {{param.target}}"
+    results = [success]
+  }
+  test:success -> done
+}
+`), 0644))
+
+	// The "agent" records the params it was started with.
+	out := filepath.Join(dir, "params.out")
+	done, _ := json.Marshal(protocol.StatusMessage{Type: protocol.MsgRunCompleted, Result: "succeeded"})
+	agentPath := filepath.Join(dir, "agent.sh")
+	require.NoError(t, os.WriteFile(agentPath, []byte("#!/bin/sh\nprintf '%s' \"$CLOCHE_PARAMS\" > "+out+"\necho '"+string(done)+"'\n"), 0755))
+
+	srv := server.NewClocheServerWithCaptures(store, store, local.NewRuntime(agentPath), "")
+
+	_, err = srv.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{
+		WorkflowName: "ci",
+		ProjectDir:   dir,
+		Params:       map[string]string{"nope": "x"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `has no param "nope"`)
+
+	resp, err := srv.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{
+		WorkflowName: "ci",
+		ProjectDir:   dir,
+		Params:       map[string]string{"target": "./internal/..."},
+	})
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := srv.GetStatus(context.Background(), &pb.GetStatusRequest{RunId: resp.RunId})
+		require.NoError(t, err)
+		if status.State == "succeeded" {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"target":"./internal/..."}`, string(data))
+}
+
+func TestServer_RunWorkflow_CleansSucceededPromptKeepsFailed(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", "config.toml"),
+		[]byte("[runs]\nclean_succeeded_prompts = true\n"), 0644))
+
+	writeScript := func(name, result string) {
+		data, _ := json.Marshal(protocol.StatusMessage{Type: protocol.MsgRunCompleted, Result: result})
+		script := "#!/bin/sh\necho '" + string(data) + "'\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", name+".cloche"), []byte(script), 0755))
+	}
+	writeScript("pass", "succeeded")
+	writeScript("fail", "failed")
+
+	rt := local.NewRuntime("sh")
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "")
+	ctx := context.Background()
+
+	start := func(workflow string) *domain.Run {
+		resp, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{
+			WorkflowName: workflow,
+			ProjectDir:   dir,
+			Prompt:       "do the thing",
+		})
+		require.NoError(t, err)
+		run, err := store.GetRun(ctx, resp.RunId)
+		require.NoError(t, err)
+		return run
+	}
+	passRun := start("pass")
+	failRun := start("fail")
+
+	waitState := func(runID, want string) {
+		require.Eventually(t, func() bool {
+			status, err := srv.GetStatus(ctx, &pb.GetStatusRequest{RunId: runID})
+			return err == nil && status.State == want
+		}, 5*time.Second, 50*time.Millisecond)
+	}
+	waitState(passRun.ID, "succeeded")
+	waitState(failRun.ID, "failed")
+
+	passDir := filepath.Join(dir, ".cloche", "runs", passRun.TaskID)
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(passDir)
+		return os.IsNotExist(err)
+	}, 5*time.Second, 50*time.Millisecond, "succeeded run's prompt dir should be removed")
+
+	promptData, err := os.ReadFile(filepath.Join(dir, ".cloche", "runs", failRun.TaskID, "prompt.txt"))
+	require.NoError(t, err, "failed run's prompt should be kept")
+	assert.Equal(t, "do the thing", string(promptData))
+}
+
 func TestServer_RunWorkflow_SetsTempFileDirKV(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -735,6 +892,38 @@ func TestServer_GetStatus_ContainerID(t *testing.T) {
 	resp, err := srv.GetStatus(ctx, &pb.GetStatusRequest{RunId: "cid-test"})
 	require.NoError(t, err)
 	assert.Equal(t, "4647e7e70e3fabc123def456", resp.ContainerId)
+
+	startedAt, err := time.Parse(time.RFC3339Nano, resp.StartedAt)
+	require.NoError(t, err)
+	assert.True(t, run.StartedAt.Equal(startedAt), "started_at round-trips the run start")
+}
+
+func TestServer_GetStatus_ErrorMessageRespectsStatusLimit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+	store.SetMaxStoredOutput(0)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", "config.toml"), []byte("[output]\nstatus_chars = 12\n"), 0644))
+
+	ctx := context.Background()
+	run := domain.NewRun("status-cap", "develop")
+	run.ProjectDir = dir
+	run.Fail(strings.Repeat("e", 300))
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	srv := server.NewClocheServer(store, nil)
+	resp, err := srv.GetStatus(ctx, &pb.GetStatusRequest{RunId: "status-cap"})
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("e", 12)+domain.TruncatedSuffix, resp.ErrorMessage)
+
+	// The stored message is untouched; only the status view is capped.
+	stored, err := store.GetRun(ctx, "status-cap")
+	require.NoError(t, err)
+	assert.Len(t, stored.ErrorMessage, 300)
 }
 
 func TestServer_GetStatus_WaitingStep(t *testing.T) {
@@ -875,6 +1064,39 @@ func TestServer_RunWorkflow_NoRuntime(t *testing.T) {
 	assert.Contains(t, err.Error(), "no container runtime configured")
 }
 
+func TestServer_RunWorkflow_WorkflowFileNameMismatch(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "dev.cloche"), []byte(`workflow develop {
+  step code {
+    prompt = "write it"
+    results = [success]
+  }
+  code:success -> done
+}`), 0644))
+
+	srv := server.NewClocheServer(store, nil)
+
+	// The file's own name does not match what it declares.
+	_, err = srv.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{WorkflowName: "dev", ProjectDir: dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `.cloche/dev.cloche declares workflow "develop", not "dev"`)
+
+	// The declared name is found, but in a file runs would not load.
+	_, err = srv.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{WorkflowName: "develop", ProjectDir: dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `workflow "develop" is declared in .cloche/dev.cloche, but runs load .cloche/develop.cloche`)
+
+	runs, err := store.ListRuns(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, runs, "a mismatched workflow does not create a run")
+}
+
 // trackingRuntime wraps a local.Runtime and tracks Remove calls.
 type trackingRuntime struct {
 	*local.Runtime
@@ -2981,6 +3203,13 @@ func (m *mockStopRuntime) Inspect(_ context.Context, _ string) (*ports.Container
 	return &ports.ContainerStatus{}, nil
 }
 
+func (m *mockStopRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (m *mockStopRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (m *mockStopRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, fmt.Errorf("attach not supported in mock")
 }
@@ -3984,6 +4213,13 @@ func (r *consoleRuntime) AttachOutput(_ context.Context, _ string) (io.ReadClose
 	return io.NopCloser(strings.NewReader("")), nil
 }
 
+func (r *consoleRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (r *consoleRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (r *consoleRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return r.attachConn, nil
 }
@@ -4260,6 +4496,13 @@ func (m *mockInspectRuntime) Inspect(_ context.Context, _ string) (*ports.Contai
 	}
 	return &ports.ContainerStatus{Running: true}, nil
 }
+func (m *mockInspectRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (m *mockInspectRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (m *mockInspectRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, fmt.Errorf("attach not supported")
 }
@@ -4474,6 +4717,96 @@ func TestTrackRun_AttachOutputFailureStopsProjectLoop(t *testing.T) {
 	assert.False(t, loop.Running(), "loop should be stopped after attach failure")
 }
 
+// deadLetterRuntime records Stop and Remove calls, and panics in Wait when
+// panicOnWait is set, to exercise the tracker's dead-letter path.
+type deadLetterRuntime struct {
+	mockInspectRuntime
+	panicOnWait bool
+	mu          sync.Mutex
+	stopped     []string
+	removed     []string
+}
+
+func (r *deadLetterRuntime) Wait(ctx context.Context, id string) (int, error) {
+	if r.panicOnWait {
+		panic("wait exploded")
+	}
+	return r.mockInspectRuntime.Wait(ctx, id)
+}
+
+func (r *deadLetterRuntime) Stop(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = append(r.stopped, id)
+	return nil
+}
+
+func (r *deadLetterRuntime) Remove(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removed = append(r.removed, id)
+	return nil
+}
+
+func TestTrackRun_AttachOutputFailureCleansUpContainer(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+
+	run := domain.NewRun("attach-dl-run-1", "develop")
+	run.ProjectDir = "/project/attach-dl"
+	run.Start()
+	run.ContainerID = "container-attach-dl-1"
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	rt := &deadLetterRuntime{mockInspectRuntime: mockInspectRuntime{attachErr: fmt.Errorf("daemon gone")}}
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "")
+	srv.AddActiveRun("attach-dl-run-1", "container-attach-dl-1")
+
+	srv.TrackRun("attach-dl-run-1", "container-attach-dl-1", "/project/attach-dl", "develop", false)
+
+	updated, err := store.GetRun(ctx, "attach-dl-run-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateFailed, updated.State)
+	assert.Equal(t, "failed to attach to container output: daemon gone", updated.ErrorMessage)
+	assert.Equal(t, []string{"container-attach-dl-1"}, rt.stopped)
+	assert.Equal(t, []string{"container-attach-dl-1"}, rt.removed)
+}
+
+func TestTrackRun_PanicMarksRunFailed(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+
+	run := domain.NewRun("panic-run-1", "develop")
+	run.ProjectDir = "/project/panic"
+	run.Start()
+	run.ContainerID = "container-panic-1"
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	rt := &deadLetterRuntime{panicOnWait: true}
+	broadcaster := logstream.NewBroadcaster()
+
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "")
+	srv.SetLogBroadcaster(broadcaster)
+	srv.AddActiveRun("panic-run-1", "container-panic-1")
+
+	require.NotPanics(t, func() {
+		srv.TrackRun("panic-run-1", "container-panic-1", "/project/panic", "develop", false)
+	})
+
+	updated, err := store.GetRun(ctx, "panic-run-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateFailed, updated.State)
+	assert.Equal(t, "run tracker crashed: wait exploded", updated.ErrorMessage)
+	assert.Equal(t, []string{"container-panic-1"}, rt.removed)
+	assert.False(t, broadcaster.IsActive("panic-run-1"))
+}
+
 // ---- AgentSession tests ----
 
 // fakeAgentStream implements pb.ClocheService_AgentSessionServer for testing.
@@ -4692,6 +5025,13 @@ func (n *nopRuntime) AttachOutput(_ context.Context, _ string) (io.ReadCloser, e
 func (n *nopRuntime) Inspect(_ context.Context, _ string) (*ports.ContainerStatus, error) {
 	return &ports.ContainerStatus{}, nil
 }
+func (n *nopRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (n *nopRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (n *nopRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, nil
 }
@@ -4806,6 +5146,26 @@ func (f *fakeRunStore) UpdateRun(_ context.Context, r *domain.Run) error {
 	f.runs[r.ID] = &cp
 	return nil
 }
+func (f *fakeRunStore) UpdateRunState(_ context.Context, id string, state domain.RunState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.runs[id]
+	if !ok {
+		return fmt.Errorf("not found: %s", id)
+	}
+	r.State = state
+	return nil
+}
+func (f *fakeRunStore) UpdateActiveSteps(_ context.Context, id string, steps []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.runs[id]
+	if !ok {
+		return fmt.Errorf("not found: %s", id)
+	}
+	r.ActiveSteps = append([]string(nil), steps...)
+	return nil
+}
 func (f *fakeRunStore) DeleteRun(_ context.Context, id string) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -4821,6 +5181,9 @@ func (f *fakeRunStore) ListRunsByProject(_ context.Context, _ string, _ time.Tim
 func (f *fakeRunStore) ListRunsFiltered(_ context.Context, _ domain.RunListFilter) ([]*domain.Run, error) {
 	return nil, nil
 }
+func (f *fakeRunStore) DeleteRunsWhere(_ context.Context, _ domain.RunListFilter) ([]*domain.Run, error) {
+	return nil, nil
+}
 func (f *fakeRunStore) ListChildRuns(_ context.Context, _ string) ([]*domain.Run, error) {
 	return nil, nil
 }
@@ -4853,82 +5216,234 @@ func newTestLoop(projectDir string, store ports.RunStore) *host.Loop {
 		})
 }
 
-func TestAgentNameForStep(t *testing.T) {
-	dir := t.TempDir()
-	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche"), 0755))
+func TestAgentSession_FlagsUndeclaredStepResult(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
 
-	wf := `workflow develop {
-  agent claude {
-    command = "claude"
-  }
+	ctx := context.Background()
+	// The "success" result kicks off a best-effort workspace snapshot goroutine
+	// that may still write under the project dir after AgentSession returns,
+	// so clean up without failing the test (t.TempDir would).
+	dir, err := os.MkdirTemp("", "invalid-result-")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", "develop.cloche"), []byte(`workflow develop {
   step build {
-    agent = claude
-    prompt = "build the thing"
+    run = "make"
     results = [success, fail]
   }
   build:success -> done
   build:fail -> abort
 }
-`
-	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", "develop.cloche"), []byte(wf), 0644))
+`), 0644))
 
-	t.Run("found", func(t *testing.T) {
-		name := server.AgentNameForStep(dir, "develop", "build")
-		assert.Equal(t, "claude", name)
-	})
+	run := domain.NewRun("run-invalid-1", "develop")
+	run.ProjectDir = dir
+	run.Start()
+	require.NoError(t, store.CreateRun(ctx, run))
 
-	t.Run("unknown step", func(t *testing.T) {
-		name := server.AgentNameForStep(dir, "develop", "nonexistent")
-		assert.Equal(t, "", name)
-	})
+	rt := &fakeDockerRuntime{}
+	pool := newFakePoolWithRuntime(rt)
+	srv := server.NewClocheServerWithCaptures(store, store, rt.asContainerRuntime(), "")
+	srv.SetContainerPool(pool)
+	srv.RegisterContainerRun("ctr-invalid-1", "run-invalid-1")
 
-	t.Run("missing workflow file", func(t *testing.T) {
-		name := server.AgentNameForStep(dir, "nonexistent", "build")
-		assert.Equal(t, "", name)
-	})
+	stream := newFakeAgentStream(ctx)
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_Ready{Ready: &pb.AgentReady{RunId: "ctr-invalid-1"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepStarted{StepStarted: &pb.StepStarted{RequestId: "req-1", StepName: "build"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepResult{StepResult: &pb.StepResult{RequestId: "req-1", Result: "succcess"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepStarted{StepStarted: &pb.StepStarted{RequestId: "req-2", StepName: "build"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepResult{StepResult: &pb.StepResult{RequestId: "req-2", Result: "success"}}})
+	stream.close()
 
-	t.Run("empty args", func(t *testing.T) {
-		name := server.AgentNameForStep("", "develop", "build")
-		assert.Equal(t, "", name)
-	})
+	require.NoError(t, srv.AgentSession(stream))
+
+	caps, err := store.GetCaptures(ctx, "run-invalid-1")
+	require.NoError(t, err)
+	invalid := map[string]bool{}
+	for _, c := range caps {
+		if c.Result != "" {
+			invalid[c.Result] = c.InvalidResult
+		}
+	}
+	require.Contains(t, invalid, "succcess")
+	require.Contains(t, invalid, "success")
+	assert.True(t, invalid["succcess"], "undeclared result should be flagged")
+	assert.False(t, invalid["success"], "declared result should not be flagged")
 }
 
-// TestServer_StreamLogs_CompoundStepName verifies that a compound step name
-// (e.g. "develop:implement") serves logs from the sub-workflow's extracted
-// log subdirectory (.cloche/logs/<task>/<attempt>/develop/implement.log).
-// This is the addressing scheme used when a host workflow step runs a
-// container sub-workflow that times out or fails mid-execution.
-func TestServer_StreamLogs_CompoundStepName(t *testing.T) {
+func TestAgentSession_RestartedAgentSeqSortsAfterEarlierCaptures(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
 	defer store.Close()
 
 	ctx := context.Background()
-	dir := t.TempDir()
-
-	// The main host run. Sub-workflow step logs are indexed under this run.
-	run := domain.NewRun("main-csn1", "main")
-	run.TaskID = "TASK-CSN"
-	run.AttemptID = "csn1"
-	run.ProjectDir = dir
+	run := domain.NewRun("run-seq-1", "develop")
+	run.ProjectDir = t.TempDir()
 	run.Start()
-	run.Complete(domain.RunStateFailed)
 	require.NoError(t, store.CreateRun(ctx, run))
 
-	// Write the sub-workflow step logs that extractContainerLogs would create:
-	//   .cloche/logs/<taskID>/<attemptID>/develop/implement.log
-	logDir := filepath.Join(dir, ".cloche", "logs", "TASK-CSN", "csn1")
-	subDir := filepath.Join(logDir, "develop")
-	require.NoError(t, os.MkdirAll(subDir, 0755))
-	require.NoError(t, os.WriteFile(
-		filepath.Join(subDir, "implement.log"),
-		[]byte("implement step output\n"),
-		0644,
-	))
-	require.NoError(t, os.WriteFile(
-		filepath.Join(subDir, "llm-implement.log"),
-		[]byte("implement llm output\n"),
-		0644,
+	rt := &fakeDockerRuntime{}
+	pool := newFakePoolWithRuntime(rt)
+	srv := server.NewClocheServerWithCaptures(store, store, rt.asContainerRuntime(), "")
+	srv.SetContainerPool(pool)
+	srv.RegisterContainerRun("ctr-seq-1", "run-seq-1")
+
+	// Each agent session numbers its events from 1; the second session
+	// stands in for an agent restarted within the same run.
+	for _, step := range []string{"implement", "test"} {
+		stream := newFakeAgentStream(ctx)
+		stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_Ready{Ready: &pb.AgentReady{RunId: "ctr-seq-1"}}})
+		stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepStarted{StepStarted: &pb.StepStarted{RequestId: "req-" + step, StepName: step, Seq: 1}}})
+		stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepResult{StepResult: &pb.StepResult{RequestId: "req-" + step, Result: "fail", Seq: 2}}})
+		stream.close()
+		require.NoError(t, srv.AgentSession(stream))
+	}
+
+	caps, err := store.GetCaptures(ctx, "run-seq-1")
+	require.NoError(t, err)
+	require.NotEmpty(t, caps)
+	var maxImplement, minTest int64 = 0, 1 << 62
+	for _, c := range caps {
+		switch c.StepName {
+		case "implement":
+			if c.Seq > maxImplement {
+				maxImplement = c.Seq
+			}
+		case "test":
+			if c.Seq < minTest {
+				minTest = c.Seq
+			}
+		}
+	}
+	assert.Greater(t, minTest, maxImplement, "restarted agent's captures should sort after the earlier ones")
+	assert.Equal(t, "test", caps[len(caps)-1].StepName)
+}
+
+func TestAgentSession_SavesPromptPerAttempt(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", "config.toml"), []byte("[runs]\nsave_prompts = true\n"), 0644))
+
+	run := domain.NewRun("run-prompts-1", "develop")
+	run.ProjectDir = dir
+	run.Start()
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	rt := &fakeDockerRuntime{}
+	pool := newFakePoolWithRuntime(rt)
+	srv := server.NewClocheServerWithCaptures(store, store, rt.asContainerRuntime(), "")
+	srv.SetContainerPool(pool)
+	srv.RegisterContainerRun("ctr-prompts-1", "run-prompts-1")
+
+	stream := newFakeAgentStream(ctx)
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_Ready{Ready: &pb.AgentReady{RunId: "ctr-prompts-1"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepStarted{StepStarted: &pb.StepStarted{RequestId: "req-1", StepName: "implement"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepResult{StepResult: &pb.StepResult{RequestId: "req-1", Result: "fail", PromptText: "first prompt"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepStarted{StepStarted: &pb.StepStarted{RequestId: "req-2", StepName: "implement"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepResult{StepResult: &pb.StepResult{RequestId: "req-2", Result: "fail", PromptText: "second prompt"}}})
+	stream.close()
+
+	require.NoError(t, srv.AgentSession(stream))
+
+	caps, err := store.GetCaptures(ctx, "run-prompts-1")
+	require.NoError(t, err)
+	var paths []string
+	for _, c := range caps {
+		if c.PromptPath != "" {
+			paths = append(paths, c.PromptPath)
+		}
+	}
+	require.Equal(t, []string{
+		filepath.Join(".cloche", "run-prompts-1", "prompts", "implement.1.txt"),
+		filepath.Join(".cloche", "run-prompts-1", "prompts", "implement.2.txt"),
+	}, paths)
+
+	for i, want := range []string{"first prompt", "second prompt"} {
+		data, err := os.ReadFile(filepath.Join(dir, paths[i]))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+}
+
+func TestAgentSession_DoesNotSavePromptByDefault(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	run := domain.NewRun("run-prompts-2", "develop")
+	run.ProjectDir = dir
+	run.Start()
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	rt := &fakeDockerRuntime{}
+	pool := newFakePoolWithRuntime(rt)
+	srv := server.NewClocheServerWithCaptures(store, store, rt.asContainerRuntime(), "")
+	srv.SetContainerPool(pool)
+	srv.RegisterContainerRun("ctr-prompts-2", "run-prompts-2")
+
+	stream := newFakeAgentStream(ctx)
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_Ready{Ready: &pb.AgentReady{RunId: "ctr-prompts-2"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepStarted{StepStarted: &pb.StepStarted{RequestId: "req-1", StepName: "implement"}}})
+	stream.push(&pb.AgentMessage{Payload: &pb.AgentMessage_StepResult{StepResult: &pb.StepResult{RequestId: "req-1", Result: "fail", PromptText: "a prompt"}}})
+	stream.close()
+
+	require.NoError(t, srv.AgentSession(stream))
+
+	caps, err := store.GetCaptures(ctx, "run-prompts-2")
+	require.NoError(t, err)
+	for _, c := range caps {
+		assert.Empty(t, c.PromptPath)
+	}
+	assert.NoDirExists(t, filepath.Join(dir, ".cloche", "run-prompts-2", "prompts"))
+}
+
+// TestServer_StreamLogs_CompoundStepName verifies that a compound step name
+// (e.g. "develop:implement") serves logs from the sub-workflow's extracted
+// log subdirectory (.cloche/logs/<task>/<attempt>/develop/implement.log).
+// This is the addressing scheme used when a host workflow step runs a
+// container sub-workflow that times out or fails mid-execution.
+func TestServer_StreamLogs_CompoundStepName(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// The main host run. Sub-workflow step logs are indexed under this run.
+	run := domain.NewRun("main-csn1", "main")
+	run.TaskID = "TASK-CSN"
+	run.AttemptID = "csn1"
+	run.ProjectDir = dir
+	run.Start()
+	run.Complete(domain.RunStateFailed)
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	// Write the sub-workflow step logs that extractContainerLogs would create:
+	//   .cloche/logs/<taskID>/<attemptID>/develop/implement.log
+	logDir := filepath.Join(dir, ".cloche", "logs", "TASK-CSN", "csn1")
+	subDir := filepath.Join(logDir, "develop")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(subDir, "implement.log"),
+		[]byte("implement step output\n"),
+		0644,
+	))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(subDir, "llm-implement.log"),
+		[]byte("implement llm output\n"),
+		0644,
 	))
 
 	srv := server.NewClocheServerWithCaptures(store, store, nil, "")
@@ -5232,3 +5747,623 @@ func TestEnableLoop_StopsNestedLoopWhenParentEnabled(t *testing.T) {
 	dirs := srv.ActiveLoopDirs()
 	assert.Equal(t, []string{parentDir}, dirs)
 }
+
+// writeSyncHostWorkflow writes a single-step host workflow running cmd.
+func writeSyncHostWorkflow(t *testing.T, cmd string) string {
+	t.Helper()
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	wf := fmt.Sprintf(`workflow main {
+  host {}
+
+  step work {
+    run = %q
+    results = [success, fail]
+  }
+  work:success -> done
+  work:fail -> abort
+}`, cmd)
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "host.cloche"), []byte(wf), 0644))
+	return dir
+}
+
+func TestServer_RunWorkflowSync_ReturnsTerminalState(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	srv := server.NewClocheServerWithCaptures(store, store, nil, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dir := writeSyncHostWorkflow(t, "echo ok")
+	resp, err := srv.RunWorkflowSync(ctx, &pb.RunWorkflowRequest{WorkflowName: "main", ProjectDir: dir})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.RunId)
+	assert.Equal(t, string(domain.RunStateSucceeded), resp.State)
+	assert.Empty(t, resp.ErrorMessage)
+
+	dir = writeSyncHostWorkflow(t, "exit 1")
+	resp, err = srv.RunWorkflowSync(ctx, &pb.RunWorkflowRequest{WorkflowName: "main", ProjectDir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.RunStateFailed), resp.State)
+}
+
+func TestServer_RunWorkflowSync_RespectsClientDeadline(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	srv := server.NewClocheServerWithCaptures(store, store, nil, "")
+	dir := writeSyncHostWorkflow(t, "sleep 2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = srv.RunWorkflowSync(ctx, &pb.RunWorkflowRequest{WorkflowName: "main", ProjectDir: dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DeadlineExceeded")
+	assert.Less(t, time.Since(start), 2*time.Second, "wait should end at the client deadline")
+
+	// The run keeps going after the caller gives up; let it finish before
+	// the temp project directory is removed.
+	require.Eventually(t, func() bool {
+		runs, err := store.ListRuns(context.Background(), time.Time{})
+		if err != nil || len(runs) == 0 {
+			return false
+		}
+		return runs[0].State == domain.RunStateSucceeded
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+// gatedStartRuntime blocks Start until release is closed, so tests can act on
+// the run between its creation and the container coming up.
+type gatedStartRuntime struct {
+	mockStopRuntime
+	entered chan struct{}
+	release chan struct{}
+	mu      sync.Mutex
+	removed []string
+}
+
+func newGatedStartRuntime() *gatedStartRuntime {
+	return &gatedStartRuntime{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (m *gatedStartRuntime) Start(ctx context.Context, cfg ports.ContainerConfig) (string, error) {
+	close(m.entered)
+	<-m.release
+	return m.mockStopRuntime.Start(ctx, cfg)
+}
+
+func (m *gatedStartRuntime) Remove(_ context.Context, containerID string) error {
+	m.mu.Lock()
+	m.removed = append(m.removed, containerID)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *gatedStartRuntime) removedIDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.removed...)
+}
+
+// failingTxStore fails every transaction, simulating a DB error after the
+// container has started.
+type failingTxStore struct {
+	*sqlite.Store
+}
+
+func (s failingTxStore) Transaction(_ context.Context, _ func(ports.RunStore) error) error {
+	return fmt.Errorf("database is locked")
+}
+
+func writeContainerWorkflow(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "develop.cloche"), []byte(`workflow develop {
+  step code {
+    prompt = "write code"
+    results = [success]
+  }
+  code:success -> done
+}`), 0644))
+	return dir
+}
+
+func TestServer_RunWorkflow_StoppedBeforeStartDiscardsContainer(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	rt := newGatedStartRuntime()
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "img")
+
+	resp, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "develop", ProjectDir: writeContainerWorkflow(t)})
+	require.NoError(t, err)
+
+	// The run exists but its container is still starting; stop it now.
+	<-rt.entered
+	_, err = srv.StopRun(ctx, &pb.StopRunRequest{TaskId: resp.TaskId})
+	require.NoError(t, err)
+	close(rt.release)
+
+	require.Eventually(t, func() bool {
+		return len(rt.removedIDs()) == 1
+	}, 5*time.Second, 10*time.Millisecond, "container started after stop should be removed")
+	assert.Equal(t, []string{"cid-new"}, rt.removedIDs())
+
+	run, err := store.GetRun(ctx, resp.RunId)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateCancelled, run.State, "stopped run must not be overwritten to running")
+	assert.Empty(t, run.ContainerID)
+}
+
+func TestServer_RunWorkflow_StartRecordFailureDiscardsContainer(t *testing.T) {
+	base, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer base.Close()
+	store := failingTxStore{base}
+
+	ctx := context.Background()
+	rt := newGatedStartRuntime()
+	close(rt.release)
+	srv := server.NewClocheServerWithCaptures(store, base, rt, "img")
+
+	resp, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "develop", ProjectDir: writeContainerWorkflow(t)})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(rt.removedIDs()) == 1
+	}, 5*time.Second, 10*time.Millisecond, "container should be removed when the run cannot be marked running")
+	assert.Contains(t, rt.stopped, "cid-new")
+
+	run, err := store.GetRun(ctx, resp.RunId)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateFailed, run.State)
+	assert.Contains(t, run.ErrorMessage, "database is locked")
+}
+
+func TestServer_LaunchResumeContainer_MarksRunStarted(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	rt := newGatedStartRuntime()
+	close(rt.release)
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "img")
+
+	run := domain.NewRun("develop-resume", "develop")
+	run.ProjectDir = writeContainerWorkflow(t)
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	go srv.LaunchResumeContainer(run, "img-committed", []string{"cloche-agent"})
+
+	require.Eventually(t, func() bool {
+		got, err := store.GetRun(ctx, run.ID)
+		return err == nil && got.ContainerID == "cid-new"
+	}, 5*time.Second, 10*time.Millisecond, "resumed run should record its container")
+	got, err := store.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	assert.False(t, got.StartedAt.IsZero(), "resumed run should be marked started")
+}
+
+func TestServer_LaunchResumeContainer_StoppedBeforeStartDiscardsContainer(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	rt := newGatedStartRuntime()
+	close(rt.release)
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "img")
+
+	run := domain.NewRun("develop-resume", "develop")
+	run.ProjectDir = writeContainerWorkflow(t)
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	// The run is stopped while its container starts; the caller's copy is stale.
+	stopped, err := store.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	stopped.Complete(domain.RunStateCancelled)
+	require.NoError(t, store.UpdateRun(ctx, stopped))
+
+	srv.LaunchResumeContainer(run, "img-committed", []string{"cloche-agent"})
+
+	assert.Equal(t, []string{"cid-new"}, rt.removedIDs())
+	got, err := store.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateCancelled, got.State, "stopped run must not be overwritten")
+	assert.Empty(t, got.ContainerID)
+}
+
+func TestServer_RunWorkflow_StartTimeoutFailsRun(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	dir := writeContainerWorkflow(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", "config.toml"),
+		[]byte("[daemon]\nstart_timeout_seconds = 1\n"), 0644))
+
+	ctx := context.Background()
+	rt := newGatedStartRuntime()
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "img")
+
+	resp, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "develop", ProjectDir: dir})
+	require.NoError(t, err)
+	<-rt.entered
+
+	require.Eventually(t, func() bool {
+		run, err := store.GetRun(ctx, resp.RunId)
+		return err == nil && run.State == domain.RunStateFailed
+	}, 5*time.Second, 20*time.Millisecond, "run should fail when Start does not return in time")
+	run, err := store.GetRun(ctx, resp.RunId)
+	require.NoError(t, err)
+	assert.Contains(t, run.ErrorMessage, "container start timed out after 1s")
+
+	// A container that eventually comes up is discarded, and the run stays failed.
+	close(rt.release)
+	require.Eventually(t, func() bool {
+		return len(rt.removedIDs()) == 1
+	}, 5*time.Second, 10*time.Millisecond, "late container should be removed")
+	run, err = store.GetRun(ctx, resp.RunId)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateFailed, run.State)
+	assert.Empty(t, run.ContainerID)
+}
+
+// pullingStartRuntime is a gatedStartRuntime whose image is not present
+// locally, so Start has to pull it first.
+type pullingStartRuntime struct {
+	*gatedStartRuntime
+}
+
+func (m pullingStartRuntime) HasImage(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (m pullingStartRuntime) AttachOutput(context.Context, string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func TestServer_RunWorkflow_StartTimeoutSkipsImagePull(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	dir := writeContainerWorkflow(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cloche", "config.toml"),
+		[]byte("[daemon]\nstart_timeout_seconds = 1\npull_policy = \"never\"\n"), 0644))
+
+	ctx := context.Background()
+	rt := pullingStartRuntime{newGatedStartRuntime()}
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "img")
+
+	resp, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "develop", ProjectDir: dir})
+	require.NoError(t, err)
+	<-rt.entered
+
+	// Start is pulling the image, so it outlives the timeout without failing the run.
+	time.Sleep(1500 * time.Millisecond)
+	run, err := store.GetRun(ctx, resp.RunId)
+	require.NoError(t, err)
+	assert.NotEqual(t, domain.RunStateFailed, run.State)
+
+	close(rt.release)
+	require.Eventually(t, func() bool {
+		run, err := store.GetRun(ctx, resp.RunId)
+		return err == nil && run.ContainerID != ""
+	}, 5*time.Second, 20*time.Millisecond, "run should get its container once the pull finishes")
+	run, err = store.GetRun(ctx, resp.RunId)
+	require.NoError(t, err)
+	assert.NotContains(t, run.ErrorMessage, "timed out")
+}
+
+// workflowStartRuntime gives each started container its own ID and keeps it
+// running until finish is called, recording which workflows started.
+type workflowStartRuntime struct {
+	mockStopRuntime
+	mu      sync.Mutex
+	started []string // container IDs, in start order
+}
+
+func (m *workflowStartRuntime) Start(_ context.Context, cfg ports.ContainerConfig) (string, error) {
+	m.mu.Lock()
+	id := fmt.Sprintf("cid-%s-%d", cfg.WorkflowName, len(m.started))
+	m.started = append(m.started, id)
+	m.mu.Unlock()
+	m.bwInit(id)
+	return id, nil
+}
+
+func (m *workflowStartRuntime) AttachOutput(_ context.Context, _ string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (m *workflowStartRuntime) startedIDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.started...)
+}
+
+func (m *workflowStartRuntime) finish(id string) { m.bwHalt(id) }
+
+func TestServer_RunWorkflow_MaxConcurrentSerializesWorkflow(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "build.cloche"), []byte(`workflow build {
+  max_concurrent = 1
+  step compile {
+    run = "make"
+    results = [success]
+  }
+  compile:success -> done
+}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "lint.cloche"), []byte(`workflow lint {
+  step check {
+    run = "make lint"
+    results = [success]
+  }
+  check:success -> done
+}`), 0644))
+
+	ctx := context.Background()
+	rt := &workflowStartRuntime{}
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "img")
+
+	first, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "build", ProjectDir: dir})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(rt.startedIDs()) == 1 },
+		5*time.Second, 10*time.Millisecond)
+
+	second, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "build", ProjectDir: dir})
+	require.NoError(t, err)
+	_, err = srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "lint", ProjectDir: dir})
+	require.NoError(t, err)
+
+	// The other workflow starts alongside; the second build waits its turn.
+	require.Eventually(t, func() bool { return len(rt.startedIDs()) == 2 },
+		5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"cid-build-0", "cid-lint-1"}, rt.startedIDs())
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, rt.startedIDs(), 2, "second build must not start while the first is running")
+	run, err := store.GetRun(ctx, second.RunId)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatePending, run.State)
+
+	// Once the first build finishes, the queued one starts.
+	rt.finish("cid-build-0")
+	require.Eventually(t, func() bool { return len(rt.startedIDs()) == 3 },
+		5*time.Second, 10*time.Millisecond, "queued build should start after the first finishes")
+	assert.Equal(t, "cid-build-2", rt.startedIDs()[2])
+
+	run, err = store.GetRun(ctx, first.RunId)
+	require.NoError(t, err)
+	assert.NotEqual(t, domain.RunStateRunning, run.State, "first build should have finished")
+}
+
+func TestServer_GetStatus_QueuePosition(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "build.cloche"), []byte(`workflow build {
+  max_concurrent = 1
+  step compile {
+    run = "make"
+    results = [success]
+  }
+  compile:success -> done
+}`), 0644))
+
+	ctx := context.Background()
+	rt := &workflowStartRuntime{}
+	srv := server.NewClocheServerWithCaptures(store, store, rt, "img")
+
+	first, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "build", ProjectDir: dir})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(rt.startedIDs()) == 1 },
+		5*time.Second, 10*time.Millisecond)
+
+	var queued []string
+	for i := 0; i < 2; i++ {
+		resp, err := srv.RunWorkflow(ctx, &pb.RunWorkflowRequest{WorkflowName: "build", ProjectDir: dir})
+		require.NoError(t, err)
+		queued = append(queued, resp.RunId)
+		want := int32(i + 1)
+		require.Eventually(t, func() bool {
+			st, err := srv.GetStatus(ctx, &pb.GetStatusRequest{RunId: resp.RunId})
+			return err == nil && st.QueuePosition == want
+		}, 5*time.Second, 10*time.Millisecond, "queued run %d should report position %d", i, want)
+	}
+
+	st, err := srv.GetStatus(ctx, &pb.GetStatusRequest{RunId: first.RunId})
+	require.NoError(t, err)
+	assert.Zero(t, st.QueuePosition, "running run has no queue position")
+
+	// When the running build finishes, the head of the queue starts and the
+	// run behind it moves up.
+	rt.finish("cid-build-0")
+	require.Eventually(t, func() bool { return len(rt.startedIDs()) == 2 },
+		5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		st, err := srv.GetStatus(ctx, &pb.GetStatusRequest{RunId: queued[1]})
+		return err == nil && st.QueuePosition == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	st, err = srv.GetStatus(ctx, &pb.GetStatusRequest{RunId: queued[0]})
+	require.NoError(t, err)
+	assert.Zero(t, st.QueuePosition, "started run has no queue position")
+}
+
+// pruneRuntime is a ContainerRuntime whose containers exist until removed.
+type pruneRuntime struct {
+	nopRuntime
+	mu      sync.Mutex
+	live    map[string]bool
+	removed []string
+}
+
+func (r *pruneRuntime) Inspect(_ context.Context, id string) (*ports.ContainerStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.live[id] {
+		return nil, fmt.Errorf("no such container: %s", id)
+	}
+	return &ports.ContainerStatus{}, nil
+}
+
+func (r *pruneRuntime) Remove(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.live, id)
+	r.removed = append(r.removed, id)
+	return nil
+}
+
+func TestServer_Prune_RunsSnapshotsAndContainers(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	old := time.Now().Add(-40 * 24 * time.Hour)
+
+	addRun := func(id, attemptID string, state domain.RunState, completedAt time.Time, containerID string, kept bool) {
+		run := domain.NewRun(id, "develop")
+		run.ProjectDir = dir
+		run.TaskID = "TASK-1"
+		run.AttemptID = attemptID
+		run.State = state
+		run.StartedAt = completedAt.Add(-time.Minute)
+		run.CompletedAt = completedAt
+		run.ContainerID = containerID
+		run.ContainerKept = kept
+		require.NoError(t, store.CreateRun(ctx, run))
+	}
+	addRun("old-failed", "a1", domain.RunStateFailed, old, "ctr-old", true)
+	addRun("old-running", "a2", domain.RunStateRunning, time.Time{}, "ctr-running", false)
+	addRun("recent-done", "a3", domain.RunStateSucceeded, time.Now(), "ctr-recent", false)
+	addRun("recent-kept", "a4", domain.RunStateFailed, time.Now(), "ctr-kept", true)
+
+	for _, attempt := range []string{"a1", "a3", "gone"} {
+		snap := filepath.Join(dir, ".cloche", "runs", "TASK-1", "snapshots", attempt)
+		require.NoError(t, os.MkdirAll(snap, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(snap, "implement.tar"), []byte("tar"), 0644))
+	}
+
+	// The old run's log file and its index row go with it.
+	oldLog := filepath.Join(dir, ".cloche", "logs", "TASK-1", "a1", "full.log")
+	require.NoError(t, os.MkdirAll(filepath.Dir(oldLog), 0755))
+	require.NoError(t, os.WriteFile(oldLog, []byte("log"), 0644))
+	require.NoError(t, store.SaveLogFile(ctx, &ports.LogFileEntry{RunID: "old-failed", FileType: "full", FilePath: oldLog}))
+
+	rt := &pruneRuntime{live: map[string]bool{
+		"ctr-old": true, "ctr-running": true, "ctr-recent": true, "ctr-kept": true,
+	}}
+	srv := server.NewClocheServer(store, rt)
+
+	resp, err := srv.Prune(ctx, &pb.PruneRequest{
+		ProjectDir:           dir,
+		RunsOlderThanSeconds: int64((30 * 24 * time.Hour).Seconds()),
+		Snapshots:            true,
+		Containers:           true,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Errors)
+
+	assert.Equal(t, []string{"old-failed"}, resp.DeletedRuns)
+	_, err = store.GetRun(ctx, "old-failed")
+	assert.Error(t, err, "old finished run should be deleted")
+	_, err = store.GetRun(ctx, "old-running")
+	assert.NoError(t, err, "unfinished runs are never pruned")
+
+	// The deleted run's container goes with it even though it was kept; the
+	// recent unkept container is swept; kept and active containers remain.
+	assert.ElementsMatch(t, []string{"ctr-old", "ctr-recent"}, resp.RemovedContainers)
+	assert.ElementsMatch(t, []string{"ctr-old", "ctr-recent"}, rt.removed)
+
+	logs, err := store.GetLogFiles(ctx, "old-failed")
+	require.NoError(t, err)
+	assert.Empty(t, logs, "log_files rows of pruned runs are deleted")
+	assert.NoFileExists(t, oldLog)
+
+	snapRoot := filepath.Join(dir, ".cloche", "runs", "TASK-1", "snapshots")
+	assert.Equal(t, []string{filepath.Join(snapRoot, "a1"), filepath.Join(snapRoot, "gone")}, resp.RemovedSnapshots)
+	assert.DirExists(t, filepath.Join(snapRoot, "a3"))
+}
+
+func TestServer_DeleteRuns_RemovesMatchingRunsAndArtifacts(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	logDir := filepath.Join(dir, ".cloche", "logs", "TASK-1")
+
+	addRun := func(id, attemptID string, state domain.RunState) {
+		run := domain.NewRun(id, "develop")
+		run.ProjectDir = dir
+		run.TaskID = "TASK-1"
+		run.AttemptID = attemptID
+		run.State = state
+		run.CompletedAt = time.Now()
+		run.ContainerID = "ctr-" + id
+		require.NoError(t, store.CreateRun(ctx, run))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cloche", "runs", id), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(logDir, attemptID), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(logDir, attemptID, id+".diff"), []byte("diff"), 0644))
+	}
+	addRun("a1-develop", "a1", domain.RunStateFailed)
+	addRun("a2-develop", "a2", domain.RunStateFailed)
+	addRun("a2-review", "a2", domain.RunStateSucceeded)
+
+	rt := &pruneRuntime{live: map[string]bool{"ctr-a1-develop": true}}
+	srv := server.NewClocheServer(store, rt)
+
+	preview, err := srv.DeleteRuns(ctx, &pb.DeleteRunsRequest{ProjectDir: dir, State: "failed", DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, preview.Runs, 2)
+	_, err = store.GetRun(ctx, "a1-develop")
+	require.NoError(t, err, "dry run must not delete")
+
+	resp, err := srv.DeleteRuns(ctx, &pb.DeleteRunsRequest{ProjectDir: dir, State: "failed"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Errors)
+	var ids []string
+	for _, r := range resp.Runs {
+		ids = append(ids, r.RunId)
+	}
+	assert.ElementsMatch(t, []string{"a1-develop", "a2-develop"}, ids)
+	assert.Equal(t, []string{"ctr-a1-develop"}, rt.removed)
+
+	// a1 has no runs left, so its log directory goes; a2 keeps the review run's files.
+	assert.NoDirExists(t, filepath.Join(logDir, "a1"))
+	assert.NoFileExists(t, filepath.Join(logDir, "a2", "a2-develop.diff"))
+	assert.FileExists(t, filepath.Join(logDir, "a2", "a2-review.diff"))
+	assert.NoDirExists(t, filepath.Join(dir, ".cloche", "runs", "a1-develop"))
+	assert.DirExists(t, filepath.Join(dir, ".cloche", "runs", "a2-review"))
+	_, err = store.GetRun(ctx, "a2-review")
+	assert.NoError(t, err)
+
+	_, err = srv.DeleteRuns(ctx, &pb.DeleteRunsRequest{ProjectDir: dir, State: "running"})
+	assert.ErrorContains(t, err, "only succeeded, failed or cancelled")
+}
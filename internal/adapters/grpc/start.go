@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloche-dev/cloche/internal/config"
+	"github.com/cloche-dev/cloche/internal/ports"
+)
+
+// errStartTimeout reports a container runtime that did not return from Start
+// within [daemon] start_timeout_seconds.
+var errStartTimeout = errors.New("container start timed out")
+
+// startContainer starts a run's container, giving up after the project's
+// start timeout so a wedged runtime fails the run instead of leaving it
+// pending forever. ctx is passed to Start unchanged because some runtimes tie
+// the container's lifetime to it, so a call that outlives the timeout keeps
+// running in the background; if it later yields a container, that container
+// is discarded. The timeout covers starting the container only: a Start that
+// must first pull its image (pull_policy = "never" leaves the pull to the
+// runtime) is not timed.
+func (s *ClocheServer) startContainer(ctx context.Context, projectDir, runID string, cfg ports.ContainerConfig) (string, error) {
+	timeout := startTimeout(projectDir)
+	if timeout <= 0 || s.imageMissing(ctx, cfg.Image, timeout) {
+		return s.container.Start(ctx, cfg)
+	}
+
+	type result struct {
+		id  string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		id, err := s.container.Start(ctx, cfg)
+		done <- result{id, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.id, r.err
+	case <-timer.C:
+	}
+
+	err := fmt.Errorf("%w after %s; the container runtime may be unresponsive", errStartTimeout, timeout)
+	go func() {
+		r := <-done
+		if r.err == nil && r.id != "" {
+			log.Printf("run %s: container %s started after the start timeout", runID, r.id)
+			s.discardStartedContainer(context.Background(), runID, r.id, err)
+		}
+	}()
+	return "", err
+}
+
+// imageMissing reports whether the runtime says image is not available
+// locally, so starting a container from it will pull it first. The check is
+// bounded by timeout; a runtime that cannot answer in time is treated as
+// having the image, leaving Start to the timeout.
+func (s *ClocheServer) imageMissing(ctx context.Context, image string, timeout time.Duration) bool {
+	checker, ok := s.container.(ports.ImageChecker)
+	if !ok || image == "" {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	present, err := checker.HasImage(ctx, image)
+	return err == nil && !present
+}
+
+// startTimeout returns [daemon] start_timeout_seconds for projectDir.
+// Zero or less disables the timeout.
+func startTimeout(projectDir string) time.Duration {
+	cfg, err := config.LoadMerged(projectDir)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(cfg.Daemon.StartTimeoutSeconds) * time.Second
+}
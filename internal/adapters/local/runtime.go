@@ -2,11 +2,13 @@ package local
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -46,7 +48,17 @@ func (r *Runtime) Start(ctx context.Context, cfg ports.ContainerConfig) (string,
 
 	cmd := exec.CommandContext(ctx, agentCmd[0], agentCmd[1:]...)
 	cmd.Dir = cfg.ProjectDir
-	cmd.Env = append(os.Environ(), "CLOCHE_RUN_ID="+cfg.RunID)
+	cmd.Env = append(os.Environ(), "CLOCHE_RUN_ID="+cfg.RunID, "CLOCHE_WORKFLOW_NAME="+cfg.WorkflowName)
+	if cfg.PromptChars > 0 {
+		cmd.Env = append(cmd.Env, "CLOCHE_PROMPT_CHARS="+strconv.Itoa(cfg.PromptChars))
+	}
+	if len(cfg.Params) > 0 {
+		data, _ := json.Marshal(cfg.Params)
+		cmd.Env = append(cmd.Env, "CLOCHE_PARAMS="+string(data))
+	}
+	if cfg.SavePrompts {
+		cmd.Env = append(cmd.Env, "CLOCHE_SAVE_PROMPTS=1")
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -138,6 +150,37 @@ func (r *Runtime) Wait(ctx context.Context, containerID string) (int, error) {
 	}
 }
 
+// Pull is a no-op: local processes run without images.
+func (r *Runtime) Pull(ctx context.Context, image string) error {
+	return nil
+}
+
+// Exec runs cmd in the process's project directory, the local equivalent of
+// the container workspace.
+func (r *Runtime) Exec(ctx context.Context, containerID string, cmd []string) (string, int, error) {
+	r.mu.Lock()
+	mp, ok := r.processes[containerID]
+	r.mu.Unlock()
+
+	if !ok {
+		return "", -1, fmt.Errorf("process %q not found", containerID)
+	}
+	if len(cmd) == 0 {
+		return "", -1, fmt.Errorf("exec: empty command")
+	}
+
+	c := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
+	c.Dir = mp.projectDir
+	out, err := c.CombinedOutput()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return string(out), exitErr.ExitCode(), nil
+		}
+		return string(out), -1, fmt.Errorf("running command: %w", err)
+	}
+	return string(out), 0, nil
+}
+
 func (r *Runtime) Attach(ctx context.Context, containerID string) (io.ReadWriteCloser, error) {
 	return nil, fmt.Errorf("attach not supported in local mode")
 }
@@ -3,6 +3,8 @@ package local_test
 import (
 	"context"
 	"io"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/cloche-dev/cloche/internal/adapters/local"
@@ -47,6 +49,46 @@ func TestLocalRuntime_AttachOutput(t *testing.T) {
 	assert.Equal(t, 0, exitCode)
 }
 
+func TestLocalRuntime_PassesPromptChars(t *testing.T) {
+	rt := local.NewRuntime("sh")
+
+	id, err := rt.Start(context.Background(), ports.ContainerConfig{
+		ProjectDir:  t.TempDir(),
+		Cmd:         []string{"sh", "-c", "echo limit=$CLOCHE_PROMPT_CHARS"},
+		PromptChars: 1234,
+	})
+	require.NoError(t, err)
+
+	reader, err := rt.AttachOutput(context.Background(), id)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "limit=1234")
+
+	_, err = rt.Wait(context.Background(), id)
+	require.NoError(t, err)
+}
+
+func TestLocalRuntime_PassesParams(t *testing.T) {
+	rt := local.NewRuntime("sh")
+
+	id, err := rt.Start(context.Background(), ports.ContainerConfig{
+		ProjectDir: t.TempDir(),
+		Cmd:        []string{"sh", "-c", "echo params=$CLOCHE_PARAMS"},
+		Params:     map[string]string{"target": "./..."},
+	})
+	require.NoError(t, err)
+
+	reader, err := rt.AttachOutput(context.Background(), id)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `params={"target":"./..."}`)
+
+	_, err = rt.Wait(context.Background(), id)
+	require.NoError(t, err)
+}
+
 func TestLocalRuntime_NonZeroExit(t *testing.T) {
 	rt := local.NewRuntime("sh")
 
@@ -78,6 +120,32 @@ func TestLocalRuntime_Stop(t *testing.T) {
 	assert.NotEqual(t, 0, exitCode) // killed process exits non-zero
 }
 
+func TestLocalRuntime_Exec(t *testing.T) {
+	rt := local.NewRuntime("sh")
+	dir := t.TempDir()
+
+	id, err := rt.Start(context.Background(), ports.ContainerConfig{
+		ProjectDir: dir,
+		Cmd:        []string{"sh", "-c", "sleep 60"},
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = rt.Stop(context.Background(), id)
+		_, _ = rt.Wait(context.Background(), id)
+	}()
+
+	out, code, err := rt.Exec(context.Background(), id, []string{"pwd"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	resolved, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	assert.Equal(t, resolved, strings.TrimSpace(out))
+
+	_, code, err = rt.Exec(context.Background(), id, []string{"sh", "-c", "exit 3"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+}
+
 func TestLocalRuntime_NotFound(t *testing.T) {
 	rt := local.NewRuntime("sh")
 
@@ -89,4 +157,7 @@ func TestLocalRuntime_NotFound(t *testing.T) {
 
 	_, err = rt.AttachOutput(context.Background(), "nonexistent")
 	assert.Error(t, err)
+
+	_, _, err = rt.Exec(context.Background(), "nonexistent", []string{"true"})
+	assert.Error(t, err)
 }
@@ -161,14 +161,14 @@ func (s *Store) MigrateProjectLogs(projectDir string) error {
 		return nil
 	}
 
-	if err := migrateProjectRuns(s.db, projectDir); err != nil {
+	if err := migrateProjectRuns(s.conn, projectDir); err != nil {
 		return err
 	}
 
 	// Clean up old .cloche/<run-id>/ directories that may still contain
 	// orphaned runtime state (prompt.txt, context.json) from before the
 	// move to .cloche/runs/<task-id>/.
-	cleanupOldRunDirs(s.db, projectDir)
+	cleanupOldRunDirs(s.conn, projectDir)
 
 	s.db.Exec(`INSERT OR IGNORE INTO _migrations (id, applied_at) VALUES (?, ?)`,
 		migrationID, time.Now().UTC().Format(time.RFC3339))
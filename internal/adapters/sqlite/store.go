@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/cloche-dev/cloche/internal/activitylog"
@@ -13,8 +14,24 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+// dbtx is the query surface shared by *sql.DB and *sql.Tx, so Store methods
+// run unchanged inside a Transaction.
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type Store struct {
-	db *sql.DB
+	db   dbtx
+	conn *sql.DB
+	inTx bool
+
+	// maxStoredOutput caps run error messages and step logs written to the
+	// database; see SetMaxStoredOutput.
+	maxStoredOutput int
 }
 
 func NewStore(dsn string) (*Store, error) {
@@ -38,11 +55,47 @@ func NewStore(dsn string) (*Store, error) {
 		return nil, fmt.Errorf("migrating: %w", err)
 	}
 
-	return &Store{db: db}, nil
+	return &Store{db: db, conn: db, maxStoredOutput: defaultMaxStoredOutput}, nil
+}
+
+// SetMaxStoredOutput sets the byte cap applied to agent output (run error
+// messages and step logs) before it is persisted. Zero or less disables the
+// cap. Full output remains available in the step log files.
+func (s *Store) SetMaxStoredOutput(n int) {
+	s.maxStoredOutput = n
 }
 
 func (s *Store) Close() error {
-	return s.db.Close()
+	return s.conn.Close()
+}
+
+// Ping runs a trivial query. All access shares one connection, so Ping blocks
+// (until ctx expires) while another caller holds it, which makes it a probe for
+// a stalled database rather than just a reachable one.
+func (s *Store) Ping(ctx context.Context) error {
+	var one int
+	return s.conn.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+// Transaction runs fn against a Store bound to a single SQL transaction,
+// committing if fn returns nil and rolling back otherwise. Calls on a Store
+// that is already inside a transaction run fn directly.
+func (s *Store) Transaction(ctx context.Context, fn func(tx ports.RunStore) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	if err := fn(&Store{db: tx, conn: s.conn, inTx: true, maxStoredOutput: s.maxStoredOutput}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
 }
 
 func migrate(db *sql.DB) error {
@@ -90,6 +143,10 @@ func migrate(db *sql.DB) error {
 		`ALTER TABLE step_executions ADD COLUMN input_tokens INTEGER DEFAULT 0`,
 		`ALTER TABLE step_executions ADD COLUMN output_tokens INTEGER DEFAULT 0`,
 		`ALTER TABLE step_executions ADD COLUMN agent_name TEXT DEFAULT ''`,
+		`ALTER TABLE step_executions ADD COLUMN invalid_result INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE step_executions ADD COLUMN seq INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE step_executions ADD COLUMN prompt_path TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE step_executions ADD COLUMN exit_code INTEGER`,
 	}
 	for _, stmt := range alterStmts {
 		db.Exec(stmt) // ignore "duplicate column" errors
@@ -139,6 +196,15 @@ func migrate(db *sql.DB) error {
 	// Idempotent — ignored if column already exists.
 	db.Exec(`ALTER TABLE attempts ADD COLUMN previous_attempt_id TEXT NOT NULL DEFAULT ''`)
 
+	// v5: Track when each run row was last written, for ListRunsFiltered's
+	// updated_at ordering. Added after the v3 rebuild of the runs table.
+	db.Exec(`ALTER TABLE runs ADD COLUMN updated_at TEXT NOT NULL DEFAULT ''`)
+
+	// v6: Per-run step aggregates, recorded when a run finishes so listings
+	// need not count captures for every run.
+	db.Exec(`ALTER TABLE runs ADD COLUMN step_count INTEGER NOT NULL DEFAULT 0`)
+	db.Exec(`ALTER TABLE runs ADD COLUMN step_attempts INTEGER NOT NULL DEFAULT 0`)
+
 	_, errAL := db.Exec(`CREATE TABLE IF NOT EXISTS attempt_logs (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		attempt_id TEXT NOT NULL,
@@ -166,6 +232,23 @@ func migrate(db *sql.DB) error {
 	if err2 != nil {
 		return err2
 	}
+	// Idempotent — ignored if the columns already exist.
+	db.Exec(`ALTER TABLE evolution_log ADD COLUMN classification_rationale TEXT NOT NULL DEFAULT ''`)
+	db.Exec(`ALTER TABLE evolution_log ADD COLUMN classification_confidence TEXT NOT NULL DEFAULT ''`)
+	// One record per trigger run. Older databases may already hold
+	// duplicates; those are kept as they are and go without the index, which
+	// SaveEvolution does not rely on.
+	var duplicates int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM (
+		SELECT 1 FROM evolution_log GROUP BY project_dir, workflow_name, trigger_run_id HAVING COUNT(*) > 1
+	)`).Scan(&duplicates); err != nil {
+		return fmt.Errorf("checking evolution_log for duplicates: %w", err)
+	}
+	if duplicates == 0 {
+		if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS evolution_log_trigger ON evolution_log (project_dir, workflow_name, trigger_run_id)`); err != nil {
+			return fmt.Errorf("evolution_log trigger index: %w", err)
+		}
+	}
 
 	_, errKV := db.Exec(`CREATE TABLE IF NOT EXISTS context_kv (
 		task_id    TEXT NOT NULL,
@@ -236,23 +319,24 @@ func migrate(db *sql.DB) error {
 
 func (s *Store) CreateRun(ctx context.Context, run *domain.Run) error {
 	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO runs (id, workflow_name, state, active_steps, started_at, completed_at, project_dir, error_message, container_id, base_sha, container_kept, title, is_host, parent_run_id, task_id, task_title, attempt_id, parent_step_name)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO runs (id, workflow_name, state, active_steps, started_at, completed_at, project_dir, error_message, container_id, base_sha, container_kept, title, is_host, parent_run_id, task_id, task_title, attempt_id, parent_step_name, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		run.ID, run.WorkflowName, string(run.State), run.ActiveStepsString(),
-		formatTime(run.StartedAt), formatTime(run.CompletedAt), run.ProjectDir, truncateErrorMessage(run.ErrorMessage), run.ContainerID, run.BaseSHA, boolToInt(run.ContainerKept), run.Title, boolToInt(run.IsHost), run.ParentRunID, run.TaskID, run.TaskTitle, run.AttemptID, nullableString(run.ParentStepName),
+		formatTime(run.StartedAt), formatTime(run.CompletedAt), run.ProjectDir, domain.TruncateOutput(run.ErrorMessage, s.maxStoredOutput), run.ContainerID, run.BaseSHA, boolToInt(run.ContainerKept), run.Title, boolToInt(run.IsHost), run.ParentRunID, run.TaskID, run.TaskTitle, run.AttemptID, nullableString(run.ParentStepName),
+		updatedAtNow(),
 	)
 	return err
 }
 
 // runSelectCols is the standard column list for scanning a Run row.
-const runSelectCols = `pk, id, workflow_name, state, active_steps, started_at, completed_at, project_dir, COALESCE(error_message,''), COALESCE(container_id,''), COALESCE(base_sha,''), COALESCE(container_kept,0), COALESCE(title,''), COALESCE(is_host,0), COALESCE(parent_run_id,''), COALESCE(task_id,''), COALESCE(task_title,''), COALESCE(attempt_id,''), COALESCE(parent_step_name,'')`
+const runSelectCols = `pk, id, workflow_name, state, active_steps, started_at, completed_at, project_dir, COALESCE(error_message,''), COALESCE(container_id,''), COALESCE(base_sha,''), COALESCE(container_kept,0), COALESCE(title,''), COALESCE(is_host,0), COALESCE(parent_run_id,''), COALESCE(task_id,''), COALESCE(task_title,''), COALESCE(attempt_id,''), COALESCE(parent_step_name,''), step_count, step_attempts`
 
 // scanRun scans a single row into a *domain.Run.
 func scanRun(scanner interface{ Scan(...any) error }) (*domain.Run, error) {
 	run := &domain.Run{}
 	var activeSteps, startedAt, completedAt string
 	var containerKept, isHost int
-	err := scanner.Scan(&run.PK, &run.ID, &run.WorkflowName, &run.State, &activeSteps, &startedAt, &completedAt, &run.ProjectDir, &run.ErrorMessage, &run.ContainerID, &run.BaseSHA, &containerKept, &run.Title, &isHost, &run.ParentRunID, &run.TaskID, &run.TaskTitle, &run.AttemptID, &run.ParentStepName)
+	err := scanner.Scan(&run.PK, &run.ID, &run.WorkflowName, &run.State, &activeSteps, &startedAt, &completedAt, &run.ProjectDir, &run.ErrorMessage, &run.ContainerID, &run.BaseSHA, &containerKept, &run.Title, &isHost, &run.ParentRunID, &run.TaskID, &run.TaskTitle, &run.AttemptID, &run.ParentStepName, &run.StepCount, &run.StepAttempts)
 	if err != nil {
 		return nil, err
 	}
@@ -290,27 +374,82 @@ func (s *Store) GetRunByAttempt(ctx context.Context, attemptID, id string) (*dom
 }
 
 func (s *Store) UpdateRun(ctx context.Context, run *domain.Run) error {
+	if err := s.updateRun(ctx, run); err != nil {
+		return err
+	}
+	if run.IsTerminal() {
+		return s.recordRunAggregates(ctx, run)
+	}
+	return nil
+}
+
+func (s *Store) updateRun(ctx context.Context, run *domain.Run) error {
 	// Use pk when available (populated after reads); fall back to
 	// attempt_id+id composite which is unique by schema constraint.
 	if run.PK != 0 {
 		_, err := s.db.ExecContext(ctx,
-			`UPDATE runs SET state = ?, active_steps = ?, started_at = ?, completed_at = ?, error_message = ?, container_id = ?, base_sha = ?, container_kept = ?, title = ?, is_host = ?, parent_run_id = ?, task_id = ?, task_title = ?, attempt_id = ?, parent_step_name = ? WHERE pk = ?`,
+			`UPDATE runs SET state = ?, active_steps = ?, started_at = ?, completed_at = ?, error_message = ?, container_id = ?, base_sha = ?, container_kept = ?, title = ?, is_host = ?, parent_run_id = ?, task_id = ?, task_title = ?, attempt_id = ?, parent_step_name = ?, updated_at = ? WHERE pk = ?`,
 			string(run.State), run.ActiveStepsString(),
 			formatTime(run.StartedAt), formatTime(run.CompletedAt),
-			truncateErrorMessage(run.ErrorMessage), run.ContainerID, run.BaseSHA, boolToInt(run.ContainerKept), run.Title, boolToInt(run.IsHost), run.ParentRunID, run.TaskID, run.TaskTitle, run.AttemptID, nullableString(run.ParentStepName), run.PK,
+			domain.TruncateOutput(run.ErrorMessage, s.maxStoredOutput), run.ContainerID, run.BaseSHA, boolToInt(run.ContainerKept), run.Title, boolToInt(run.IsHost), run.ParentRunID, run.TaskID, run.TaskTitle, run.AttemptID, nullableString(run.ParentStepName), updatedAtNow(), run.PK,
 		)
 		return err
 	}
 	_, err := s.db.ExecContext(ctx,
-		`UPDATE runs SET state = ?, active_steps = ?, started_at = ?, completed_at = ?, error_message = ?, container_id = ?, base_sha = ?, container_kept = ?, title = ?, is_host = ?, parent_run_id = ?, task_id = ?, task_title = ?, attempt_id = ?, parent_step_name = ? WHERE attempt_id = ? AND id = ?`,
+		`UPDATE runs SET state = ?, active_steps = ?, started_at = ?, completed_at = ?, error_message = ?, container_id = ?, base_sha = ?, container_kept = ?, title = ?, is_host = ?, parent_run_id = ?, task_id = ?, task_title = ?, attempt_id = ?, parent_step_name = ?, updated_at = ? WHERE attempt_id = ? AND id = ?`,
 		string(run.State), run.ActiveStepsString(),
 		formatTime(run.StartedAt), formatTime(run.CompletedAt),
-		truncateErrorMessage(run.ErrorMessage), run.ContainerID, run.BaseSHA, boolToInt(run.ContainerKept), run.Title, boolToInt(run.IsHost), run.ParentRunID, run.TaskID, run.TaskTitle, run.AttemptID, nullableString(run.ParentStepName),
+		domain.TruncateOutput(run.ErrorMessage, s.maxStoredOutput), run.ContainerID, run.BaseSHA, boolToInt(run.ContainerKept), run.Title, boolToInt(run.IsHost), run.ParentRunID, run.TaskID, run.TaskTitle, run.AttemptID, nullableString(run.ParentStepName), updatedAtNow(),
 		run.AttemptID, run.ID,
 	)
 	return err
 }
 
+// runAggregatesSQL counts a run's completed captures: distinct steps and
+// executions including retries. Start captures carry no completion time.
+const runAggregatesSQL = `step_count = (SELECT COUNT(DISTINCT step_name) FROM step_executions WHERE run_id = runs.id AND completed_at != ''),
+	step_attempts = (SELECT COUNT(*) FROM step_executions WHERE run_id = runs.id AND completed_at != '')`
+
+// recordRunAggregates persists the step counts of a finished run and copies
+// them onto run.
+func (s *Store) recordRunAggregates(ctx context.Context, run *domain.Run) error {
+	where, args := `attempt_id = ? AND id = ?`, []any{run.AttemptID, run.ID}
+	if run.PK != 0 {
+		where, args = `pk = ?`, []any{run.PK}
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE runs SET `+runAggregatesSQL+` WHERE `+where, args...); err != nil {
+		return fmt.Errorf("recording step counts: %w", err)
+	}
+	return s.db.QueryRowContext(ctx, `SELECT step_count, step_attempts FROM runs WHERE `+where, args...).
+		Scan(&run.StepCount, &run.StepAttempts)
+}
+
+// UpdateRunState sets only the state column of the latest run with the given ID.
+func (s *Store) UpdateRunState(ctx context.Context, id string, state domain.RunState) error {
+	return s.updateRunColumn(ctx, id, "state", string(state))
+}
+
+// UpdateActiveSteps sets only the active_steps column of the latest run with
+// the given ID.
+func (s *Store) UpdateActiveSteps(ctx context.Context, id string, steps []string) error {
+	return s.updateRunColumn(ctx, id, "active_steps", strings.Join(steps, ","))
+}
+
+// updateRunColumn writes one column of the run row GetRun would return for id.
+// column must be a fixed identifier, never caller input.
+func (s *Store) updateRunColumn(ctx context.Context, id, column, value string) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE runs SET `+column+` = ?, updated_at = ? WHERE pk = (SELECT MAX(pk) FROM runs WHERE id = ?)`,
+		value, updatedAtNow(), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("run %q not found", id)
+	}
+	return nil
+}
+
 func (s *Store) DeleteRun(ctx context.Context, id string) error {
 	_, err := s.db.ExecContext(ctx, `DELETE FROM step_executions WHERE run_id = ?`, id)
 	if err != nil {
@@ -371,42 +510,105 @@ func (s *Store) ListRunsByProject(ctx context.Context, projectDir string, since
 }
 
 func (s *Store) ListRunsFiltered(ctx context.Context, filter domain.RunListFilter) ([]*domain.Run, error) {
-	query := `SELECT ` + runSelectCols + ` FROM runs WHERE 1=1`
+	where, args := runFilterWhere(filter)
+	query := `SELECT ` + runSelectCols + ` FROM runs WHERE ` + where
+
+	switch filter.Order {
+	case domain.RunOrderCompleted:
+		query += ` ORDER BY CASE WHEN COALESCE(completed_at,'') = '' THEN 0 ELSE 1 END, completed_at DESC, started_at DESC`
+	case domain.RunOrderUpdated:
+		// Rows written before updated_at existed fall back to their latest timestamp.
+		query += ` ORDER BY COALESCE(NULLIF(updated_at,''), NULLIF(completed_at,''), started_at) DESC`
+	default:
+		query += ` ORDER BY CASE WHEN state = 'running' THEN 0 ELSE 1 END, started_at DESC`
+	}
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRuns(rows)
+}
+
+// runFilterWhere builds the WHERE clause and arguments selecting the runs
+// that match filter. Order and Limit are not part of it.
+func runFilterWhere(filter domain.RunListFilter) (string, []interface{}) {
+	where := `1=1`
 	var args []interface{}
 
 	if filter.ProjectDir != "" {
-		query += ` AND project_dir = ?`
+		where += ` AND project_dir = ?`
 		args = append(args, filter.ProjectDir)
 	}
 	if filter.State != "" {
-		query += ` AND state = ?`
+		where += ` AND state = ?`
 		args = append(args, string(filter.State))
 	}
 	if filter.TaskID != "" {
-		query += ` AND task_id = ?`
+		where += ` AND task_id = ?`
 		args = append(args, filter.TaskID)
 	}
 	if filter.AttemptID != "" {
-		query += ` AND attempt_id = ?`
+		where += ` AND attempt_id = ?`
 		args = append(args, filter.AttemptID)
 	}
+	if filter.WorkflowName != "" {
+		where += ` AND workflow_name = ?`
+		args = append(args, filter.WorkflowName)
+	}
 	if !filter.Since.IsZero() {
-		query += ` AND (completed_at >= ? OR state IN ('running', 'pending'))`
+		where += ` AND (completed_at >= ? OR state IN ('running', 'pending'))`
 		args = append(args, formatTime(filter.Since))
 	}
-
-	query += ` ORDER BY CASE WHEN state = 'running' THEN 0 ELSE 1 END, started_at DESC`
-
-	if filter.Limit > 0 {
-		query += fmt.Sprintf(` LIMIT %d`, filter.Limit)
+	if !filter.CompletedBefore.IsZero() {
+		where += ` AND COALESCE(completed_at,'') != '' AND completed_at < ?`
+		args = append(args, formatTime(filter.CompletedBefore))
 	}
+	return where, args
+}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+// DeleteRunsWhere deletes the finished (succeeded, failed or cancelled) runs
+// matching filter, along with their step executions and log file records, and
+// returns the deleted runs. Unfinished runs are never deleted. filter.Order
+// and filter.Limit are ignored.
+func (s *Store) DeleteRunsWhere(ctx context.Context, filter domain.RunListFilter) ([]*domain.Run, error) {
+	where, args := runFilterWhere(filter)
+	where += ` AND state IN ('succeeded', 'failed', 'cancelled')`
+
+	var deleted []*domain.Run
+	err := s.Transaction(ctx, func(tx ports.RunStore) error {
+		db := tx.(*Store).db
+		rows, err := db.QueryContext(ctx, `SELECT `+runSelectCols+` FROM runs WHERE `+where+` ORDER BY started_at DESC`, args...)
+		if err != nil {
+			return err
+		}
+		runs, err := scanRuns(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		for _, run := range runs {
+			for _, table := range []string{"step_executions", "log_files"} {
+				if _, err := db.ExecContext(ctx, `DELETE FROM `+table+` WHERE run_id = ?`, run.ID); err != nil {
+					return fmt.Errorf("deleting %s for run %s: %w", table, run.ID, err)
+				}
+			}
+		}
+		if _, err := db.ExecContext(ctx, `DELETE FROM runs WHERE `+where, args...); err != nil {
+			return err
+		}
+		deleted = runs
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	return scanRuns(rows)
+	return deleted, nil
 }
 
 func (s *Store) ListProjects(ctx context.Context) ([]string, error) {
@@ -447,34 +649,95 @@ func (s *Store) SaveCapture(ctx context.Context, runID string, exec *domain.Step
 		outputTokens = exec.Usage.OutputTokens
 		agentName = exec.Usage.AgentName
 	}
+	// Captures without an emit-time sequence (host runs, server-synthesized
+	// entries) take the run's next sequence number as they are stored.
 	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO step_executions (run_id, step_name, result, started_at, completed_at, logs, git_ref, input_tokens, output_tokens, agent_name)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO step_executions (run_id, step_name, result, started_at, completed_at, logs, git_ref, input_tokens, output_tokens, agent_name, invalid_result, seq, prompt_path, exit_code)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		   CASE WHEN ? != 0 THEN ? ELSE (SELECT COALESCE(MAX(seq), 0) + 1 FROM step_executions WHERE run_id = ?) END, ?, ?)`,
 		runID, exec.StepName, exec.Result,
 		formatTime(exec.StartedAt), formatTime(exec.CompletedAt),
-		exec.Logs, exec.GitRef, inputTokens, outputTokens, agentName,
+		domain.TruncateOutput(exec.Logs, s.maxStoredOutput), exec.GitRef, inputTokens, outputTokens, agentName,
+		boolToInt(exec.InvalidResult), exec.Seq, exec.Seq, runID, exec.PromptPath, exec.ExitCode,
 	)
 	return err
 }
 
+func (s *Store) MaxCaptureSeq(ctx context.Context, runID string) (int64, error) {
+	var seq int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(seq), 0) FROM step_executions WHERE run_id = ?`, runID).Scan(&seq)
+	return seq, err
+}
+
 func (s *Store) GetCaptures(ctx context.Context, runID string) ([]*domain.StepExecution, error) {
+	var execs []*domain.StepExecution
+	err := s.GetCaptureStream(ctx, runID, func(e *domain.StepExecution) error {
+		execs = append(execs, e)
+		return nil
+	})
+	return execs, err
+}
+
+// captureStreamBatchSize is the number of step_executions rows
+// GetCaptureStream reads per query.
+const captureStreamBatchSize = 256
+
+// GetCaptureStream calls fn for each capture of the run in emit order (seq,
+// with the row id breaking ties, so rows written before the seq column existed
+// keep their insertion order). Rows are read in fixed-size pages so that at most one page is held in
+// memory, and the connection is released before fn runs, so fn may use the
+// store. A non-nil error from fn stops iteration and is returned.
+func (s *Store) GetCaptureStream(ctx context.Context, runID string, fn func(*domain.StepExecution) error) error {
+	var afterSeq, afterID int64
+	for {
+		page, lastSeq, lastID, err := s.capturePage(ctx, runID, afterSeq, afterID)
+		if err != nil {
+			return err
+		}
+		for _, e := range page {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if len(page) < captureStreamBatchSize {
+			return nil
+		}
+		afterSeq, afterID = lastSeq, lastID
+	}
+}
+
+// capturePage reads up to captureStreamBatchSize captures ordered after
+// (afterSeq, afterID) and returns them along with the seq and id of the last
+// row read.
+func (s *Store) capturePage(ctx context.Context, runID string, afterSeq, afterID int64) ([]*domain.StepExecution, int64, int64, error) {
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT step_name, result, started_at, completed_at, COALESCE(logs,''), COALESCE(git_ref,''), COALESCE(input_tokens,0), COALESCE(output_tokens,0), COALESCE(agent_name,'')
-		 FROM step_executions WHERE run_id = ? ORDER BY id`, runID)
+		`SELECT id, seq, step_name, result, started_at, completed_at, COALESCE(logs,''), COALESCE(git_ref,''), COALESCE(input_tokens,0), COALESCE(output_tokens,0), COALESCE(agent_name,''), invalid_result, prompt_path, exit_code
+		 FROM step_executions WHERE run_id = ? AND (seq > ? OR (seq = ? AND id > ?))
+		 ORDER BY seq, id LIMIT ?`, runID, afterSeq, afterSeq, afterID, captureStreamBatchSize)
 	if err != nil {
-		return nil, err
+		return nil, 0, 0, err
 	}
 	defer rows.Close()
 
 	var execs []*domain.StepExecution
+	lastSeq, lastID := afterSeq, afterID
 	for rows.Next() {
 		e := &domain.StepExecution{}
 		var startedAt, completedAt string
 		var inputTokens, outputTokens int64
 		var agentName string
-		if err := rows.Scan(&e.StepName, &e.Result, &startedAt, &completedAt, &e.Logs, &e.GitRef, &inputTokens, &outputTokens, &agentName); err != nil {
-			return nil, err
+		var invalid int
+		var exitCode sql.NullInt64
+		if err := rows.Scan(&lastID, &e.Seq, &e.StepName, &e.Result, &startedAt, &completedAt, &e.Logs, &e.GitRef, &inputTokens, &outputTokens, &agentName, &invalid, &e.PromptPath, &exitCode); err != nil {
+			return nil, 0, 0, err
 		}
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			e.ExitCode = &code
+		}
+		lastSeq = e.Seq
+		e.InvalidResult = invalid != 0
 		e.StartedAt = parseTime(startedAt)
 		e.CompletedAt = parseTime(completedAt)
 		if inputTokens > 0 || outputTokens > 0 || agentName != "" {
@@ -486,7 +749,7 @@ func (s *Store) GetCaptures(ctx context.Context, runID string) ([]*domain.StepEx
 		}
 		execs = append(execs, e)
 	}
-	return execs, rows.Err()
+	return execs, lastSeq, lastID, rows.Err()
 }
 
 func (s *Store) QueryUsage(ctx context.Context, q ports.UsageQuery) ([]domain.UsageSummary, error) {
@@ -598,26 +861,63 @@ func scanLogFiles(rows *sql.Rows) ([]*ports.LogFileEntry, error) {
 	return entries, rows.Err()
 }
 
+// SaveEvolution records an evolution keyed by its project, workflow and
+// trigger run. Saving again for the same trigger updates the earlier record in
+// place, keeping its ID, so a retried pipeline does not leave duplicates
+// behind.
 func (s *Store) SaveEvolution(ctx context.Context, entry *ports.EvolutionEntry) error {
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO evolution_log (id, project_dir, workflow_name, trigger_run_id, created_at, classification, changes_json, knowledge_delta)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE evolution_log SET created_at = ?, classification = ?, classification_rationale = ?, classification_confidence = ?, changes_json = ?, knowledge_delta = ?
+		 WHERE project_dir = ? AND workflow_name = ? AND trigger_run_id = ?`,
+		formatTime(entry.CreatedAt), entry.Classification, entry.ClassificationRationale, entry.ClassificationConfidence, entry.ChangesJSON, entry.KnowledgeDelta,
+		entry.ProjectDir, entry.WorkflowName, entry.TriggerRunID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO evolution_log (id, project_dir, workflow_name, trigger_run_id, created_at, classification, classification_rationale, classification_confidence, changes_json, knowledge_delta)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		entry.ID, entry.ProjectDir, entry.WorkflowName, entry.TriggerRunID,
-		formatTime(entry.CreatedAt), entry.Classification, entry.ChangesJSON, entry.KnowledgeDelta,
+		formatTime(entry.CreatedAt), entry.Classification, entry.ClassificationRationale, entry.ClassificationConfidence, entry.ChangesJSON, entry.KnowledgeDelta,
 	)
 	return err
 }
 
 func (s *Store) GetLastEvolution(ctx context.Context, projectDir, workflowName string) (*ports.EvolutionEntry, error) {
 	row := s.db.QueryRowContext(ctx,
-		`SELECT id, project_dir, workflow_name, trigger_run_id, created_at, COALESCE(classification,''), changes_json, COALESCE(knowledge_delta,'')
+		`SELECT id, project_dir, workflow_name, trigger_run_id, created_at, COALESCE(classification,''), classification_rationale, classification_confidence, changes_json, COALESCE(knowledge_delta,'')
 		 FROM evolution_log WHERE project_dir = ? AND workflow_name = ? ORDER BY created_at DESC LIMIT 1`,
 		projectDir, workflowName)
 
 	entry := &ports.EvolutionEntry{}
 	var createdAt string
 	err := row.Scan(&entry.ID, &entry.ProjectDir, &entry.WorkflowName, &entry.TriggerRunID,
-		&createdAt, &entry.Classification, &entry.ChangesJSON, &entry.KnowledgeDelta)
+		&createdAt, &entry.Classification, &entry.ClassificationRationale, &entry.ClassificationConfidence, &entry.ChangesJSON, &entry.KnowledgeDelta)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry.CreatedAt = parseTime(createdAt)
+	return entry, nil
+}
+
+// GetEvolution returns the evolution with the given ID, or nil if there is
+// none.
+func (s *Store) GetEvolution(ctx context.Context, id string) (*ports.EvolutionEntry, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, project_dir, workflow_name, trigger_run_id, created_at, COALESCE(classification,''), classification_rationale, classification_confidence, changes_json, COALESCE(knowledge_delta,'')
+		 FROM evolution_log WHERE id = ?`, id)
+
+	entry := &ports.EvolutionEntry{}
+	var createdAt string
+	err := row.Scan(&entry.ID, &entry.ProjectDir, &entry.WorkflowName, &entry.TriggerRunID,
+		&createdAt, &entry.Classification, &entry.ClassificationRationale, &entry.ClassificationConfidence, &entry.ChangesJSON, &entry.KnowledgeDelta)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -629,21 +929,26 @@ func (s *Store) GetLastEvolution(ctx context.Context, projectDir, workflowName s
 }
 
 func (s *Store) ListRunsSince(ctx context.Context, projectDir, workflowName, sinceRunID string) ([]*domain.Run, error) {
-	var rows *sql.Rows
-	var err error
+	return s.ListRunsSinceInStates(ctx, projectDir, workflowName, sinceRunID, nil)
+}
 
-	if sinceRunID == "" {
-		rows, err = s.db.QueryContext(ctx,
-			`SELECT `+runSelectCols+`
-			 FROM runs WHERE project_dir = ? AND workflow_name = ? ORDER BY started_at ASC`,
-			projectDir, workflowName)
-	} else {
-		rows, err = s.db.QueryContext(ctx,
-			`SELECT `+runSelectCols+`
-			 FROM runs WHERE project_dir = ? AND workflow_name = ? AND started_at > (SELECT started_at FROM runs WHERE id = ?)
-			 ORDER BY started_at ASC`,
-			projectDir, workflowName, sinceRunID)
+func (s *Store) ListRunsSinceInStates(ctx context.Context, projectDir, workflowName, sinceRunID string, states []domain.RunState) ([]*domain.Run, error) {
+	query := `SELECT ` + runSelectCols + ` FROM runs WHERE project_dir = ? AND workflow_name = ?`
+	args := []interface{}{projectDir, workflowName}
+
+	if sinceRunID != "" {
+		query += ` AND started_at > (SELECT started_at FROM runs WHERE id = ?)`
+		args = append(args, sinceRunID)
 	}
+	if len(states) > 0 {
+		query += ` AND state IN (?` + strings.Repeat(`, ?`, len(states)-1) + `)`
+		for _, st := range states {
+			args = append(args, string(st))
+		}
+	}
+	query += ` ORDER BY started_at ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -814,11 +1119,10 @@ func (s *Store) GetAttemptLogs(ctx context.Context, attemptID string) ([]*ports.
 }
 
 func (s *Store) FailStaleAttempts(ctx context.Context) (int64, error) {
-	now := formatTime(time.Now())
 	res, err := s.db.ExecContext(ctx,
 		`UPDATE attempts SET result = 'failed', ended_at = ?
 		 WHERE result = 'running'`,
-		now,
+		formatTime(time.Now()),
 	)
 	if err != nil {
 		return 0, err
@@ -827,11 +1131,10 @@ func (s *Store) FailStaleAttempts(ctx context.Context) (int64, error) {
 }
 
 func (s *Store) FailStaleRuns(ctx context.Context) (int64, error) {
-	now := formatTime(time.Now())
 	res, err := s.db.ExecContext(ctx,
-		`UPDATE runs SET state = 'failed', completed_at = ?, error_message = 'daemon restarted while run was active'
+		`UPDATE runs SET state = 'failed', completed_at = ?, error_message = 'daemon restarted while run was active', updated_at = ?
 		 WHERE state IN ('pending', 'running', 'waiting')`,
-		now,
+		formatTime(time.Now()), updatedAtNow(),
 	)
 	if err != nil {
 		return 0, err
@@ -842,11 +1145,10 @@ func (s *Store) FailStaleRuns(ctx context.Context) (int64, error) {
 // ParkRunsByProject marks all resumable (pending/running/waiting) runs in a project
 // as 'parked' so they are not failed at daemon restart and can be reviewed by the operator.
 func (s *Store) ParkRunsByProject(ctx context.Context, projectDir string) (int64, error) {
-	now := formatTime(time.Now())
 	res, err := s.db.ExecContext(ctx,
-		`UPDATE runs SET state = 'parked', completed_at = ?
+		`UPDATE runs SET state = 'parked', completed_at = ?, updated_at = ?
 		 WHERE project_dir = ? AND state IN ('pending', 'running', 'waiting')`,
-		now, projectDir,
+		formatTime(time.Now()), updatedAtNow(), projectDir,
 	)
 	if err != nil {
 		return 0, err
@@ -936,6 +1238,12 @@ func (s *Store) ListHumanPolls(ctx context.Context, runID string) ([]*ports.Huma
 	return records, rows.Err()
 }
 
+// updatedAtNow returns the current time for a run's updated_at column. The
+// fixed-width UTC layout keeps string comparison in time order.
+func updatedAtNow() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.000000000Z")
+}
+
 func formatTime(t time.Time) string {
 	if t.IsZero() {
 		return ""
@@ -1184,14 +1492,6 @@ func (s *Store) ReadActivityEntries(ctx context.Context, projectDir string, opts
 	return entries, rows.Err()
 }
 
-const maxErrorMessageLen = 1000
-
-// truncateErrorMessage caps s to maxErrorMessageLen characters to prevent
-// oversized agent output dumps from bloating the database. Full error
-// details remain available in step logs.
-func truncateErrorMessage(s string) string {
-	if len(s) <= maxErrorMessageLen {
-		return s
-	}
-	return s[:maxErrorMessageLen] + "... (truncated)"
-}
+// defaultMaxStoredOutput caps stored agent output so oversized dumps don't
+// bloat the database. Matches config's default [output] store_chars.
+const defaultMaxStoredOutput = 1000
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -54,6 +55,103 @@ func TestRunStore_Update(t *testing.T) {
 	assert.Equal(t, domain.RunStateSucceeded, got.State)
 }
 
+func TestRunStore_UpdateRunState(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	run := domain.NewRun("run-1", "test-workflow")
+	run.Title = "keep me"
+	run.Start()
+	run.RecordStepStart("build")
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	require.NoError(t, store.UpdateRunState(ctx, "run-1", domain.RunStateWaiting))
+
+	got, err := store.GetRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateWaiting, got.State)
+	assert.Equal(t, "keep me", got.Title)
+	assert.Equal(t, []string{"build"}, got.ActiveSteps)
+
+	assert.Error(t, store.UpdateRunState(ctx, "missing", domain.RunStateFailed))
+}
+
+func TestRunStore_UpdateActiveSteps(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	older := domain.NewRun("run-1", "test-workflow")
+	older.AttemptID = "a1"
+	older.Start()
+	require.NoError(t, store.CreateRun(ctx, older))
+	newer := domain.NewRun("run-1", "test-workflow")
+	newer.AttemptID = "a2"
+	newer.Start()
+	require.NoError(t, store.CreateRun(ctx, newer))
+
+	require.NoError(t, store.UpdateActiveSteps(ctx, "run-1", []string{"build", "lint"}))
+
+	got, err := store.GetRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "a2", got.AttemptID)
+	assert.Equal(t, []string{"build", "lint"}, got.ActiveSteps)
+	assert.Equal(t, domain.RunStateRunning, got.State)
+
+	// Only the latest row for the ID is touched, matching GetRun.
+	first, err := store.GetRunByAttempt(ctx, "a1", "run-1")
+	require.NoError(t, err)
+	assert.Empty(t, first.ActiveSteps)
+
+	require.NoError(t, store.UpdateActiveSteps(ctx, "run-1", nil))
+	got, err = store.GetRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Empty(t, got.ActiveSteps)
+
+	assert.Error(t, store.UpdateActiveSteps(ctx, "missing", []string{"x"}))
+}
+
+func benchmarkStore(b *testing.B) (*sqlite.Store, *domain.Run) {
+	b.Helper()
+	store, err := sqlite.NewStore(filepath.Join(b.TempDir(), "bench.db"))
+	require.NoError(b, err)
+	b.Cleanup(func() { store.Close() })
+
+	run := domain.NewRun("run-1", "bench-workflow")
+	run.Title = "benchmark run"
+	run.Start()
+	require.NoError(b, store.CreateRun(context.Background(), run))
+	run, err = store.GetRun(context.Background(), "run-1")
+	require.NoError(b, err)
+	return store, run
+}
+
+func BenchmarkStore_UpdateRun(b *testing.B) {
+	store, run := benchmarkStore(b)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		run.ActiveSteps = []string{fmt.Sprintf("step-%d", i%4)}
+		if err := store.UpdateRun(ctx, run); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStore_UpdateActiveSteps(b *testing.B) {
+	store, _ := benchmarkStore(b)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := store.UpdateActiveSteps(ctx, "run-1", []string{fmt.Sprintf("step-%d", i%4)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestRunStore_List(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -79,6 +177,63 @@ func TestRunStore_GetNotFound(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRunStore_TransactionCommits(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateRun(ctx, domain.NewRun("tx-1", "wf")))
+
+	err = store.Transaction(ctx, func(tx ports.RunStore) error {
+		run, err := tx.GetRun(ctx, "tx-1")
+		if err != nil {
+			return err
+		}
+		run.Start()
+		run.ContainerID = "cid-1"
+		return tx.UpdateRun(ctx, run)
+	})
+	require.NoError(t, err)
+
+	got, err := store.GetRun(ctx, "tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateRunning, got.State)
+	assert.Equal(t, "cid-1", got.ContainerID)
+}
+
+func TestRunStore_TransactionRollsBackOnError(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateRun(ctx, domain.NewRun("tx-1", "wf")))
+
+	boom := fmt.Errorf("boom")
+	err = store.Transaction(ctx, func(tx ports.RunStore) error {
+		run, err := tx.GetRun(ctx, "tx-1")
+		if err != nil {
+			return err
+		}
+		run.Start()
+		if err := tx.UpdateRun(ctx, run); err != nil {
+			return err
+		}
+		if err := tx.CreateRun(ctx, domain.NewRun("tx-2", "wf")); err != nil {
+			return err
+		}
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+
+	got, err := store.GetRun(ctx, "tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatePending, got.State, "update inside a failed transaction must not persist")
+	_, err = store.GetRun(ctx, "tx-2")
+	assert.Error(t, err, "create inside a failed transaction must not persist")
+}
+
 func TestStore_DeleteRun(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -109,6 +264,61 @@ func TestStore_DeleteRun(t *testing.T) {
 	assert.Empty(t, caps)
 }
 
+func TestStore_DeleteRunsWhere(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	create := func(id, workflow string, state domain.RunState, completedAgo time.Duration) {
+		r := domain.NewRun(id, workflow)
+		r.ProjectDir = "/proj"
+		r.State = state
+		r.StartedAt = now.Add(-completedAgo - time.Hour)
+		if completedAgo > 0 {
+			r.CompletedAt = now.Add(-completedAgo)
+		}
+		require.NoError(t, store.CreateRun(ctx, r))
+		require.NoError(t, store.SaveCapture(ctx, id, &domain.StepExecution{StepName: "step1", StartedAt: r.StartedAt}))
+	}
+	create("old-failed", "develop", domain.RunStateFailed, 72*time.Hour)
+	create("new-failed", "develop", domain.RunStateFailed, time.Hour)
+	create("old-failed-other", "review", domain.RunStateFailed, 72*time.Hour)
+	create("old-succeeded", "develop", domain.RunStateSucceeded, 72*time.Hour)
+	create("running", "develop", domain.RunStateRunning, 0)
+
+	deleted, err := store.DeleteRunsWhere(ctx, domain.RunListFilter{
+		State:           domain.RunStateFailed,
+		WorkflowName:    "develop",
+		CompletedBefore: now.Add(-24 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, "old-failed", deleted[0].ID)
+
+	_, err = store.GetRun(ctx, "old-failed")
+	assert.Error(t, err)
+	caps, err := store.GetCaptures(ctx, "old-failed")
+	require.NoError(t, err)
+	assert.Empty(t, caps)
+	for _, id := range []string{"new-failed", "old-failed-other", "old-succeeded", "running"} {
+		_, err := store.GetRun(ctx, id)
+		assert.NoError(t, err, "run %s should remain", id)
+		caps, err := store.GetCaptures(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, caps, 1, "captures of %s should remain", id)
+	}
+
+	// Without a state filter only finished runs are deleted.
+	deleted, err = store.DeleteRunsWhere(ctx, domain.RunListFilter{WorkflowName: "develop"})
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+	run, err := store.GetRun(ctx, "running")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateRunning, run.State)
+}
+
 func TestRunProjectDir(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -193,6 +403,38 @@ func TestListRunsSinceEmpty(t *testing.T) {
 	assert.Len(t, runs, 2)
 }
 
+func TestListRunsSinceInStates(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	states := []domain.RunState{domain.RunStateSucceeded, domain.RunStateFailed, domain.RunStateCancelled, domain.RunStateFailed}
+	for i, id := range []string{"run-1", "run-2", "run-3", "run-4"} {
+		r := domain.NewRun(id, "develop")
+		r.ProjectDir = "/project"
+		r.StartedAt = time.Now().Add(time.Duration(i) * time.Minute)
+		r.State = states[i]
+		require.NoError(t, store.CreateRun(ctx, r))
+	}
+
+	runs, err := store.ListRunsSinceInStates(ctx, "/project", "develop", "run-1",
+		[]domain.RunState{domain.RunStateFailed, domain.RunStateCancelled})
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, "run-2", runs[0].ID)
+	assert.Equal(t, "run-3", runs[1].ID)
+	assert.Equal(t, "run-4", runs[2].ID)
+
+	runs, err = store.ListRunsSinceInStates(ctx, "/project", "develop", "", []domain.RunState{domain.RunStateFailed})
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+
+	runs, err = store.ListRunsSinceInStates(ctx, "/project", "develop", "", nil)
+	require.NoError(t, err)
+	assert.Len(t, runs, 4)
+}
+
 func TestGetLastEvolution(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -207,18 +449,125 @@ func TestGetLastEvolution(t *testing.T) {
 
 	// Save one
 	require.NoError(t, store.SaveEvolution(ctx, &ports.EvolutionEntry{
-		ID:           "evo-1",
-		ProjectDir:   "/project",
-		WorkflowName: "develop",
-		TriggerRunID: "run-1",
-		CreatedAt:    time.Now(),
-		ChangesJSON:  "[]",
+		ID:                       "evo-1",
+		ProjectDir:               "/project",
+		WorkflowName:             "develop",
+		TriggerRunID:             "run-1",
+		CreatedAt:                time.Now(),
+		Classification:           "bug",
+		ClassificationRationale:  "fixes a crash",
+		ClassificationConfidence: "high",
+		ChangesJSON:              "[]",
 	}))
 
 	entry, err = store.GetLastEvolution(ctx, "/project", "develop")
 	require.NoError(t, err)
 	require.NotNil(t, entry)
 	assert.Equal(t, "evo-1", entry.ID)
+	assert.Equal(t, "bug", entry.Classification)
+	assert.Equal(t, "fixes a crash", entry.ClassificationRationale)
+	assert.Equal(t, "high", entry.ClassificationConfidence)
+
+	byID, err := store.GetEvolution(ctx, "evo-1")
+	require.NoError(t, err)
+	require.NotNil(t, byID)
+	assert.Equal(t, "fixes a crash", byID.ClassificationRationale)
+
+	missing, err := store.GetEvolution(ctx, "evo-missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestSaveEvolutionUpsertsByTriggerRun(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	base := time.Now()
+
+	// Two pipeline runs for the same trigger, e.g. a retried trigger.
+	require.NoError(t, store.SaveEvolution(ctx, &ports.EvolutionEntry{
+		ID:             "evo-1",
+		ProjectDir:     "/project",
+		WorkflowName:   "develop",
+		TriggerRunID:   "run-1",
+		CreatedAt:      base,
+		Classification: "bug",
+		ChangesJSON:    "1 changes",
+	}))
+	require.NoError(t, store.SaveEvolution(ctx, &ports.EvolutionEntry{
+		ID:             "evo-2",
+		ProjectDir:     "/project",
+		WorkflowName:   "develop",
+		TriggerRunID:   "run-1",
+		CreatedAt:      base.Add(time.Minute),
+		Classification: "feedback",
+		ChangesJSON:    "2 changes",
+	}))
+
+	// The record keeps its original ID and takes the new contents.
+	last, err := store.GetLastEvolution(ctx, "/project", "develop")
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.Equal(t, "evo-1", last.ID)
+	assert.Equal(t, "feedback", last.Classification)
+	assert.Equal(t, "2 changes", last.ChangesJSON)
+
+	missing, err := store.GetEvolution(ctx, "evo-2")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestMigrateKeepsDuplicateEvolutionRecords(t *testing.T) {
+	// A database from before evolution records were keyed by trigger run
+	// can hold several for one trigger. Opening it must not delete any.
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	legacyDB, err := sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	_, err = legacyDB.Exec(`CREATE TABLE evolution_log (
+		id TEXT PRIMARY KEY,
+		project_dir TEXT NOT NULL,
+		workflow_name TEXT NOT NULL,
+		trigger_run_id TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		classification TEXT,
+		changes_json TEXT NOT NULL,
+		knowledge_delta TEXT
+	)`)
+	require.NoError(t, err)
+	for i, id := range []string{"evo-1", "evo-2"} {
+		_, err = legacyDB.Exec(
+			`INSERT INTO evolution_log (id, project_dir, workflow_name, trigger_run_id, created_at, changes_json) VALUES (?, ?, ?, ?, ?, ?)`,
+			id, "/project", "develop", "run-1", time.Now().Add(time.Duration(i)*time.Minute).UTC().Format(time.RFC3339Nano), "[]",
+		)
+		require.NoError(t, err)
+	}
+	require.NoError(t, legacyDB.Close())
+
+	store, err := sqlite.NewStore(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, id := range []string{"evo-1", "evo-2"} {
+		got, err := store.GetEvolution(ctx, id)
+		require.NoError(t, err)
+		assert.NotNil(t, got, id)
+	}
+
+	// Saving for the same trigger still works without the unique index.
+	require.NoError(t, store.SaveEvolution(ctx, &ports.EvolutionEntry{
+		ID:           "evo-3",
+		ProjectDir:   "/project",
+		WorkflowName: "develop",
+		TriggerRunID: "run-1",
+		CreatedAt:    time.Now().Add(time.Hour),
+		ChangesJSON:  "[]",
+	}))
+	missing, err := store.GetEvolution(ctx, "evo-3")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
 }
 
 func TestRunTitle(t *testing.T) {
@@ -393,6 +742,186 @@ func TestRunErrorMessage_Truncation(t *testing.T) {
 	assert.Equal(t, "short error", got3.ErrorMessage)
 }
 
+func TestStore_SetMaxStoredOutput(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+	store.SetMaxStoredOutput(20)
+
+	ctx := context.Background()
+	long := strings.Repeat("x", 200)
+
+	run := domain.NewRun("cap-1", "develop")
+	run.Fail(long)
+	require.NoError(t, store.CreateRun(ctx, run))
+	require.NoError(t, store.SaveCapture(ctx, "cap-1", &domain.StepExecution{
+		StepName:  "code",
+		StartedAt: time.Now(),
+		Logs:      long,
+	}))
+
+	got, err := store.GetRun(ctx, "cap-1")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 20)+domain.TruncatedSuffix, got.ErrorMessage)
+
+	caps, err := store.GetCaptures(ctx, "cap-1")
+	require.NoError(t, err)
+	require.Len(t, caps, 1)
+	assert.Equal(t, strings.Repeat("x", 20)+domain.TruncatedSuffix, caps[0].Logs)
+
+	// Disabling the cap stores output verbatim.
+	store.SetMaxStoredOutput(0)
+	run2 := domain.NewRun("cap-2", "develop")
+	run2.Fail(long)
+	require.NoError(t, store.CreateRun(ctx, run2))
+	got2, err := store.GetRun(ctx, "cap-2")
+	require.NoError(t, err)
+	assert.Equal(t, long, got2.ErrorMessage)
+}
+
+func TestStore_GetCaptureStream(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	run := domain.NewRun("stream-1", "develop")
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	// More rows than a single page so the stream has to continue across pages.
+	const n = 600
+	for i := 0; i < n; i++ {
+		require.NoError(t, store.SaveCapture(ctx, "stream-1", &domain.StepExecution{
+			StepName: fmt.Sprintf("step-%03d", i),
+			Result:   "success",
+		}))
+	}
+
+	all, err := store.GetCaptures(ctx, "stream-1")
+	require.NoError(t, err)
+	require.Len(t, all, n)
+
+	var streamed []string
+	err = store.GetCaptureStream(ctx, "stream-1", func(e *domain.StepExecution) error {
+		streamed = append(streamed, e.StepName)
+		// The connection is not held while fn runs, so the store stays usable.
+		_, getErr := store.GetRun(ctx, "stream-1")
+		return getErr
+	})
+	require.NoError(t, err)
+	require.Len(t, streamed, n)
+	for i, e := range all {
+		assert.Equal(t, e.StepName, streamed[i])
+	}
+
+	// An error from fn stops the stream.
+	stop := fmt.Errorf("stop")
+	count := 0
+	err = store.GetCaptureStream(ctx, "stream-1", func(*domain.StepExecution) error {
+		count++
+		if count == 3 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 3, count)
+}
+
+func TestStore_GetCapturesOrderedBySeq(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	run := domain.NewRun("seq-1", "develop")
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	// Fanout branches can be stored in a different order than they were
+	// emitted; reads follow the emit-time sequence.
+	for _, c := range []struct {
+		step string
+		seq  int64
+	}{
+		{"review", 30},
+		{"implement", 10},
+		{"test", 20},
+	} {
+		require.NoError(t, store.SaveCapture(ctx, "seq-1", &domain.StepExecution{
+			StepName: c.step,
+			Result:   "success",
+			Seq:      c.seq,
+		}))
+	}
+
+	caps, err := store.GetCaptures(ctx, "seq-1")
+	require.NoError(t, err)
+	require.Len(t, caps, 3)
+	assert.Equal(t, "implement", caps[0].StepName)
+	assert.Equal(t, "test", caps[1].StepName)
+	assert.Equal(t, "review", caps[2].StepName)
+	assert.Equal(t, int64(10), caps[0].Seq)
+}
+
+func TestStore_SaveCaptureAssignsPerRunSeq(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, id := range []string{"seq-a", "seq-b"} {
+		require.NoError(t, store.CreateRun(ctx, domain.NewRun(id, "develop")))
+	}
+
+	max, err := store.MaxCaptureSeq(ctx, "seq-a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), max, "a run without captures starts at zero")
+
+	// Captures saved without a sequence continue from the run's highest one,
+	// independently for each run.
+	for _, step := range []string{"implement", "test", "review"} {
+		require.NoError(t, store.SaveCapture(ctx, "seq-a", &domain.StepExecution{StepName: step, Result: "success"}))
+	}
+	require.NoError(t, store.SaveCapture(ctx, "seq-b", &domain.StepExecution{StepName: "build", Result: "success"}))
+
+	caps, err := store.GetCaptures(ctx, "seq-a")
+	require.NoError(t, err)
+	require.Len(t, caps, 3)
+	for i, step := range []string{"implement", "test", "review"} {
+		assert.Equal(t, step, caps[i].StepName)
+		assert.Equal(t, int64(i+1), caps[i].Seq)
+	}
+
+	caps, err = store.GetCaptures(ctx, "seq-b")
+	require.NoError(t, err)
+	require.Len(t, caps, 1)
+	assert.Equal(t, int64(1), caps[0].Seq)
+
+	max, err = store.MaxCaptureSeq(ctx, "seq-a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), max)
+}
+
+func TestStore_CaptureExitCode(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.CreateRun(ctx, domain.NewRun("exit-1", "develop")))
+
+	code := 2
+	require.NoError(t, store.SaveCapture(ctx, "exit-1", &domain.StepExecution{StepName: "test", Result: "fail", ExitCode: &code}))
+	require.NoError(t, store.SaveCapture(ctx, "exit-1", &domain.StepExecution{StepName: "review", Result: "success"}))
+
+	caps, err := store.GetCaptures(ctx, "exit-1")
+	require.NoError(t, err)
+	require.Len(t, caps, 2)
+	require.NotNil(t, caps[0].ExitCode)
+	assert.Equal(t, 2, *caps[0].ExitCode)
+	assert.Nil(t, caps[1].ExitCode, "a capture without an exit code reads back as unknown")
+}
+
 func TestRunErrorMessageInList(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -1630,6 +2159,83 @@ func TestQueryUsage_ZeroWindowNoBurnRate(t *testing.T) {
 	assert.Equal(t, int64(150), summaries[0].TotalTokens)
 }
 
+func TestListRunsFiltered_Order(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	create := func(id string, state domain.RunState, started, completed time.Duration) {
+		r := domain.NewRun(id, "develop")
+		r.State = state
+		r.StartedAt = now.Add(-started)
+		if completed > 0 {
+			r.CompletedAt = now.Add(-completed)
+		}
+		require.NoError(t, store.CreateRun(ctx, r))
+	}
+	create("touched", domain.RunStateSucceeded, 3*time.Hour, 2*time.Hour)
+	create("long", domain.RunStateSucceeded, 5*time.Hour, time.Minute) // old start, just finished
+	create("quick", domain.RunStateFailed, time.Hour, 50*time.Minute)
+	create("active", domain.RunStateRunning, 2*time.Hour, 0)
+	require.NoError(t, store.UpdateRunState(ctx, "touched", domain.RunStateFailed))
+
+	ids := func(order domain.RunOrder) []string {
+		runs, err := store.ListRunsFiltered(ctx, domain.RunListFilter{Order: order})
+		require.NoError(t, err)
+		var out []string
+		for _, r := range runs {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"active", "quick", "touched", "long"}, ids(""))
+	assert.Equal(t, []string{"active", "quick", "touched", "long"}, ids(domain.RunOrderStarted))
+	assert.Equal(t, []string{"active", "long", "quick", "touched"}, ids(domain.RunOrderCompleted))
+	assert.Equal(t, []string{"touched", "active", "quick", "long"}, ids(domain.RunOrderUpdated))
+}
+
+func TestListRunsFiltered_StaleAndParkedRunsTouchUpdatedAt(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	create := func(id, projectDir string, state domain.RunState) {
+		r := domain.NewRun(id, "develop")
+		r.State = state
+		r.ProjectDir = projectDir
+		r.StartedAt = now.Add(-time.Hour)
+		if state == domain.RunStateSucceeded {
+			r.CompletedAt = now.Add(-time.Minute)
+		}
+		require.NoError(t, store.CreateRun(ctx, r))
+	}
+	create("parked", "/parked", domain.RunStateRunning)
+	create("stale", "/other", domain.RunStateRunning)
+	create("done", "/other", domain.RunStateSucceeded)
+	require.NoError(t, store.UpdateRunState(ctx, "done", domain.RunStateFailed))
+
+	_, err = store.ParkRunsByProject(ctx, "/parked")
+	require.NoError(t, err)
+	_, err = store.FailStaleRuns(ctx)
+	require.NoError(t, err)
+
+	runs, err := store.ListRunsFiltered(ctx, domain.RunListFilter{Order: domain.RunOrderUpdated})
+	require.NoError(t, err)
+	var ids []string
+	for _, r := range runs {
+		ids = append(ids, r.ID)
+		if r.ID != "done" {
+			assert.WithinDuration(t, time.Now(), r.CompletedAt, time.Minute, r.ID)
+		}
+	}
+	assert.Equal(t, []string{"stale", "parked", "done"}, ids)
+}
+
 func TestListRunsFiltered_NoFilters(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -1649,6 +2255,44 @@ func TestListRunsFiltered_NoFilters(t *testing.T) {
 	assert.Len(t, runs, 3)
 }
 
+func TestUpdateRun_RecordsStepAggregatesOnCompletion(t *testing.T) {
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	run := domain.NewRun("agg-1", "develop")
+	run.Start()
+	require.NoError(t, store.CreateRun(ctx, run))
+
+	now := time.Now()
+	for _, c := range []struct{ step, result string }{
+		{"implement", "success"},
+		{"test", "fail"},
+		{"test", "success"},
+	} {
+		require.NoError(t, store.SaveCapture(ctx, "agg-1", &domain.StepExecution{StepName: c.step, StartedAt: now}))
+		require.NoError(t, store.SaveCapture(ctx, "agg-1", &domain.StepExecution{StepName: c.step, Result: c.result, CompletedAt: now}))
+	}
+
+	// Counts are only recorded once the run finishes.
+	require.NoError(t, store.UpdateRun(ctx, run))
+	got, err := store.GetRun(ctx, "agg-1")
+	require.NoError(t, err)
+	assert.Zero(t, got.StepCount)
+	assert.Zero(t, got.StepAttempts)
+
+	run.Complete(domain.RunStateSucceeded)
+	require.NoError(t, store.UpdateRun(ctx, run))
+	assert.Equal(t, 2, run.StepCount)
+	assert.Equal(t, 3, run.StepAttempts)
+
+	got, err = store.GetRun(ctx, "agg-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.StepCount)
+	assert.Equal(t, 3, got.StepAttempts)
+}
+
 func TestRunStore_ParentStepName_RoundTrip(t *testing.T) {
 	store, err := sqlite.NewStore(":memory:")
 	require.NoError(t, err)
@@ -2195,10 +2195,11 @@ func (h *Handler) handleAPIWorkflows(w http.ResponseWriter, r *http.Request) {
 		Implicit bool   `json:"implicit,omitempty"`
 	}
 	type apiStepDef struct {
-		Name    string            `json:"name"`
-		Type    string            `json:"type"`
-		Results []string          `json:"results"`
-		Config  map[string]string `json:"config"`
+		Name            string            `json:"name"`
+		Type            string            `json:"type"`
+		Results         []string          `json:"results"`
+		ResultsInferred bool              `json:"results_inferred,omitempty"`
+		Config          map[string]string `json:"config"`
 	}
 	type apiWorkflow struct {
 		Name      string       `json:"name"`
@@ -2226,10 +2227,11 @@ func (h *Handler) handleAPIWorkflows(w http.ResponseWriter, r *http.Request) {
 			var steps []apiStepDef
 			for _, s := range wf.Steps {
 				steps = append(steps, apiStepDef{
-					Name:    s.Name,
-					Type:    string(s.Type),
-					Results: s.Results,
-					Config:  s.Config,
+					Name:            s.Name,
+					Type:            string(s.Type),
+					Results:         s.Results,
+					ResultsInferred: s.ResultsInferred,
+					Config:          s.Config,
 				})
 			}
 			sort.Slice(steps, func(i, j int) bool { return steps[i].Name < steps[j].Name })
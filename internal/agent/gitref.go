@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// stepRefPrefix namespaces the refs that keep each step's output snapshot
+// reachable, so git gc does not collect them mid-run.
+const stepRefPrefix = "refs/cloche/steps/"
+
+// snapshotPathspec covers the whole working tree except .cloche, whose logs
+// and run state must keep accumulating when a later step checks out an
+// earlier snapshot.
+var snapshotPathspec = []string{"--", ".", ":(exclude).cloche"}
+
+// snapshotWorkspace records the working tree of workDir (tracked and
+// untracked, non-ignored files) as a commit on top of HEAD and points
+// refs/cloche/steps/<step> at it. HEAD, the index, and branches are left
+// alone. Returns the commit SHA, or "" when workDir is not a git repository.
+func snapshotWorkspace(ctx context.Context, workDir, stepName string) (string, error) {
+	if _, err := runGit(ctx, workDir, "", "rev-parse", "--git-dir"); err != nil {
+		return "", nil
+	}
+	index, cleanup, err := workingTreeIndex(ctx, workDir)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	tree, err := runGit(ctx, workDir, index, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	args := []string{"commit-tree", tree, "-m", "cloche: output of step " + stepName}
+	if head, err := runGit(ctx, workDir, "", "rev-parse", "--verify", "-q", "HEAD"); err == nil {
+		args = append(args, "-p", head)
+	}
+	sha, err := runGit(ctx, workDir, "", args...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := runGit(ctx, workDir, "", "update-ref", stepRefPrefix+stepName, sha); err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// checkoutSnapshot makes the working tree of workDir match the snapshot
+// commit sha: files are restored to their snapshot content and files the
+// snapshot does not have are removed. Ignored files and .cloche are left
+// alone, as are HEAD and the index.
+func checkoutSnapshot(ctx context.Context, workDir, sha string) error {
+	index, cleanup, err := workingTreeIndex(ctx, workDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	_, err = runGit(ctx, workDir, index, "read-tree", "--reset", "-u", sha)
+	return err
+}
+
+// workingTreeIndex builds a throwaway index file describing the current
+// working tree of workDir, so snapshots and checkouts never disturb the
+// repository's real index. The returned cleanup removes it.
+func workingTreeIndex(ctx context.Context, workDir string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "cloche-index-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	index := filepath.Join(dir, "index")
+
+	if _, err := runGit(ctx, workDir, "", "rev-parse", "--verify", "-q", "HEAD"); err == nil {
+		if _, err := runGit(ctx, workDir, index, "read-tree", "HEAD"); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+	if _, err := runGit(ctx, workDir, index, append([]string{"add", "-A"}, snapshotPathspec...)...); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return index, cleanup, nil
+}
+
+// runGit runs git in workDir, using index as the index file when non-empty,
+// and returns its trimmed stdout. Snapshot commits are authored by cloche so
+// they work in containers without a configured git identity.
+func runGit(ctx context.Context, workDir, index string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=cloche", "GIT_AUTHOR_EMAIL=cloche@localhost",
+		"GIT_COMMITTER_NAME=cloche", "GIT_COMMITTER_EMAIL=cloche@localhost",
+	)
+	if index != "" {
+		cmd.Env = append(cmd.Env, "GIT_INDEX_FILE="+index)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -1,16 +1,17 @@
 package agent
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/cloche-dev/cloche/api/clochepb"
@@ -25,20 +26,56 @@ import (
 
 // SessionConfig holds configuration for the bidirectional session handler.
 type SessionConfig struct {
-	Addr      string // daemon gRPC address (CLOCHE_ADDR)
-	RunID     string
-	AttemptID string
-	TaskID    string
-	WorkDir   string
+	Addr         string // daemon gRPC address (CLOCHE_ADDR)
+	RunID        string
+	WorkflowName string // workflow the container was started for (CLOCHE_WORKFLOW_NAME)
+	AttemptID    string
+	TaskID       string
+	WorkDir      string
+	// Concurrency caps how many agent steps run at once. Fanout branches
+	// dispatched to one container share its workspace, so the default (0 or
+	// 1) runs agent steps one at a time; raise it only for workflows whose
+	// parallel branches touch disjoint files. Script and human steps are not
+	// limited.
+	Concurrency int
+	// PromptChars caps the user prompt and previous step output fed into
+	// agent prompts (CLOCHE_PROMPT_CHARS). Zero or less is unlimited.
+	PromptChars int
+	// Params overrides the workflow params resolved into each step's config
+	// (CLOCHE_PARAMS, from "cloche run --param").
+	Params map[string]string
+	// SavePrompts sends each agent step's assembled prompt with its result
+	// (CLOCHE_SAVE_PROMPTS, from [runs] save_prompts) so the daemon can save
+	// it. Prompts are left out otherwise since they can be large.
+	SavePrompts bool
+	// StatusSink, when set, receives every status message the session emits
+	// (step started and completed, log lines, errors) in addition to the
+	// messages streamed to the daemon.
+	StatusSink protocol.StatusSink
+	// Stdout receives the run_completed status line the session emits when
+	// it is cancelled, as JSON. The daemon's run tracker reads it from the
+	// container's output. Defaults to os.Stdout.
+	Stdout io.Writer
 }
 
+// shutdownGrace bounds how long a cancelled session (e.g. the agent got
+// SIGTERM from "docker stop") waits for its in-flight steps to wind down and
+// report before it exits. It stays under docker stop's default 10s timeout so
+// the results reach the daemon before the container is killed.
+var shutdownGrace = 5 * time.Second
+
 // Session handles the bidirectional AgentSession gRPC stream.
 // It connects to the daemon, sends AgentReady, then loops receiving
 // ExecuteStep commands, dispatching them to the appropriate adapter
 // and streaming results back.
 type Session struct {
 	cfg            SessionConfig
-	stepLogOffsets map[string]int64 // tracks bytes already written to full.log per step
+	logMu          sync.Mutex        // guards stepLogOffsets
+	stepLogOffsets map[string]int64  // tracks bytes already written to full.log per step
+	refMu          sync.Mutex        // guards stepRefs
+	stepRefs       map[string]string // step name -> snapshot of the workspace it last left behind
+	lastSeq        atomic.Int64      // last sequence number handed out by nextSeq
+	status         *protocol.StatusWriter
 }
 
 // NewSession creates a new Session with the given config.
@@ -46,9 +83,18 @@ func NewSession(cfg SessionConfig) *Session {
 	return &Session{cfg: cfg}
 }
 
+// nextSeq returns the sequence number for a StepStarted or StepResult event:
+// a counter that starts at 1 for the session and increases by one per event.
+// The daemon offsets it past the run's earlier captures.
+func (s *Session) nextSeq() int64 {
+	return s.lastSeq.Add(1)
+}
+
 // Run connects to the daemon, opens the AgentSession stream, sends
 // AgentReady, and handles commands until a Shutdown is received or
-// the context is cancelled.
+// the context is cancelled. On cancellation the in-flight steps are cancelled
+// and given up to shutdownGrace to report their results, then a cancelled
+// run_completed status is emitted before Run returns.
 func (s *Session) Run(ctx context.Context) error {
 	conn, err := grpc.NewClient(s.cfg.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -56,8 +102,12 @@ func (s *Session) Run(ctx context.Context) error {
 	}
 	defer conn.Close()
 
+	// The stream outlives ctx so cancelled steps can still report.
+	streamCtx, closeStream := context.WithCancel(context.WithoutCancel(ctx))
+	defer closeStream()
+
 	client := pb.NewClocheServiceClient(conn)
-	stream, err := client.AgentSession(ctx)
+	stream, err := client.AgentSession(streamCtx)
 	if err != nil {
 		return fmt.Errorf("opening AgentSession: %w", err)
 	}
@@ -84,6 +134,15 @@ func (s *Session) Run(ctx context.Context) error {
 	}
 	defer ulog.Close()
 
+	// Scratch space for this run's steps, exposed as CLOCHE_TMPDIR. It lives
+	// inside the container under Docker and in the host's temp dir for local
+	// runs, and is removed when the session ends.
+	tmpDir, err := os.MkdirTemp("", "cloche-run-")
+	if err != nil {
+		return fmt.Errorf("creating run temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
 	// Thread-safe send over the stream.
 	var sendMu sync.Mutex
 	send := func(msg *pb.AgentMessage) error {
@@ -92,19 +151,33 @@ func (s *Session) Run(ctx context.Context) error {
 		return stream.Send(msg)
 	}
 
-	// grpcStatusWriter translates StatusWriter MsgLog entries into StepLog gRPC messages.
-	gsw := newGRPCStatusWriter(send)
-	sw := protocol.NewStatusWriter(gsw)
+	// Status messages go to the daemon (log lines as StepLog, run completion
+	// on stdout) and to the configured sink, if any.
+	stdout := s.cfg.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	sinks := statusSinks{grpcLogSink{send: send}, stdoutRunSink{enc: json.NewEncoder(stdout)}}
+	if s.cfg.StatusSink != nil {
+		sinks = append(sinks, s.cfg.StatusSink)
+	}
+	sw := protocol.NewStatusSinkWriter(sinks)
+	sw.RunID = s.cfg.RunID
+	sw.WorkflowName = s.cfg.WorkflowName
+	s.status = sw
 
 	// Set up adapters, wiring them to the gRPC status writer for live log streaming.
 	genericAdapter := generic.New()
 	genericAdapter.RunID = s.cfg.RunID
 	genericAdapter.StatusWriter = sw
+	genericAdapter.TmpDir = tmpDir
 
 	promptAdapter := prompt.New()
 	promptAdapter.RunID = s.cfg.RunID
 	promptAdapter.TaskID = s.cfg.TaskID
+	promptAdapter.PromptChars = s.cfg.PromptChars
 	promptAdapter.StatusWriter = sw
+	promptAdapter.ExtraEnv = []string{"CLOCHE_TMPDIR=" + tmpDir}
 
 	// Apply agent command override from environment.
 	if cmd, ok := os.LookupEnv("CLOCHE_AGENT_COMMAND"); ok {
@@ -122,14 +195,60 @@ func (s *Session) Run(ctx context.Context) error {
 		runID:     s.cfg.RunID,
 	}
 
-	// Per-step context for cancellation support.
+	// slots bounds the number of agent steps executing at once; queued agent
+	// steps wait for a free slot before they start. Other step types run
+	// straight away.
+	concurrency := s.cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+
+	// Per-step contexts for cancellation support, keyed by request ID.
 	var stepMu sync.Mutex
-	var stepCancel context.CancelFunc
+	stepCancels := map[string]context.CancelFunc{}
+	cancelSteps := func(requestID string) {
+		stepMu.Lock()
+		defer stepMu.Unlock()
+		for id, cancel := range stepCancels {
+			if requestID == "" || id == requestID {
+				cancel()
+			}
+		}
+	}
+
+	// On cancellation, wind the steps down and close the stream, which ends
+	// the receive loop below.
+	var inflight sync.WaitGroup
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		select {
+		case <-ctx.Done():
+		case <-streamCtx.Done():
+			return
+		}
+		log.Printf("agent: cancelled, stopping in-flight steps")
+		cancelSteps("")
+		stepsDone := make(chan struct{})
+		go func() {
+			inflight.Wait()
+			close(stepsDone)
+		}()
+		select {
+		case <-stepsDone:
+		case <-time.After(shutdownGrace):
+			log.Printf("agent: steps still running after %s, exiting anyway", shutdownGrace)
+		}
+		s.status.RunCompleted(string(domain.RunStateCancelled))
+		closeStream()
+	}()
 
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
 			if ctx.Err() != nil {
+				<-shutdownDone
 				return nil // context cancelled, clean exit
 			}
 			return fmt.Errorf("receiving from AgentSession: %w", err)
@@ -141,34 +260,38 @@ func (s *Session) Run(ctx context.Context) error {
 
 			stepCtx, cancel := context.WithCancel(ctx)
 			stepMu.Lock()
-			if stepCancel != nil {
-				// Safety: cancel any previously active step (shouldn't happen per protocol).
-				stepCancel()
-			}
-			stepCancel = cancel
+			stepCancels[cmd.RequestId] = cancel
 			stepMu.Unlock()
 
-			// Execute in a goroutine so we can receive StepCancelled concurrently.
+			// Execute in a goroutine so we can receive StepCancelled and
+			// further (fanout) steps concurrently.
+			inflight.Add(1)
 			go func(c *pb.ExecuteStep, sCtx context.Context, sCancel context.CancelFunc) {
-				defer sCancel()
+				defer inflight.Done()
+				defer func() {
+					stepMu.Lock()
+					delete(stepCancels, c.RequestId)
+					stepMu.Unlock()
+					sCancel()
+				}()
+				if domain.StepType(c.StepType) == domain.StepTypeAgent {
+					select {
+					case slots <- struct{}{}:
+					case <-sCtx.Done():
+						return // cancelled while queued; the daemon no longer waits for it
+					}
+					defer func() { <-slots }()
+				}
 				s.executeStep(sCtx, c, genericAdapter, promptAdapter, kvClient, ulog, send)
 			}(cmd, stepCtx, cancel)
 
 		case *pb.DaemonMessage_StepCancelled:
-			stepMu.Lock()
-			if stepCancel != nil {
-				log.Printf("agent: cancelling step (request_id=%s)", payload.StepCancelled.RequestId)
-				stepCancel()
-			}
-			stepMu.Unlock()
+			log.Printf("agent: cancelling step (request_id=%s)", payload.StepCancelled.RequestId)
+			cancelSteps(payload.StepCancelled.RequestId)
 
 		case *pb.DaemonMessage_Shutdown:
 			log.Printf("agent: received Shutdown from daemon, exiting")
-			stepMu.Lock()
-			if stepCancel != nil {
-				stepCancel()
-			}
-			stepMu.Unlock()
+			cancelSteps("")
 			return nil
 
 		default:
@@ -189,11 +312,13 @@ func (s *Session) executeStep(
 	send func(*pb.AgentMessage) error,
 ) {
 	// Signal step start.
+	s.status.StepStarted(cmd.StepName)
 	_ = send(&pb.AgentMessage{
 		Payload: &pb.AgentMessage_StepStarted{
 			StepStarted: &pb.StepStarted{
 				RequestId: cmd.RequestId,
 				StepName:  cmd.StepName,
+				Seq:       s.nextSeq(),
 			},
 		},
 	})
@@ -203,25 +328,29 @@ func (s *Session) executeStep(
 		Type:   domain.StepType(cmd.StepType),
 		Config: cmd.Config,
 	}
+	step.ApplyParams(s.cfg.Params)
 
-	// Apply per-step agent overrides from config.
+	// Apply per-step agent overrides from config to a copy of the adapter, so
+	// concurrently running steps do not see each other's overrides.
+	stepAdapter := *promptAdapter
+	promptAdapter = &stepAdapter
 	if agentCmd := cmd.Config["agent_command"]; agentCmd != "" {
 		promptAdapter.Commands = prompt.ParseCommands(agentCmd)
 	}
 	if agentArgs := cmd.Config["agent_args"]; agentArgs != "" {
-		promptAdapter.ExplicitArgs = strings.Fields(agentArgs)
+		promptAdapter.ExplicitArgs = domain.SplitArgs(agentArgs)
 	}
 
 	// Handle conversation resume flag.
 	if cmd.Resume {
 		promptAdapter.ResumeConversation = true
-		defer func() { promptAdapter.ResumeConversation = false }()
 	}
 
 	// Skip check: if the step declares a skip command, run it first.
 	// Exit 0 → skip the step; non-zero / timeout → run normally.
 	if skipCmd, hasSkip := cmd.Config["skip"]; hasSkip && skipCmd != "" {
 		if wire, skipped := s.runSkipScript(ctx, skipCmd, cmd.StepName, ulog); skipped {
+			s.status.StepCompleted(cmd.StepName, wire, nil)
 			var tokenUsage *pb.TokenUsage
 			_ = send(&pb.AgentMessage{
 				Payload: &pb.AgentMessage_StepResult{
@@ -230,6 +359,7 @@ func (s *Session) executeStep(
 						Result:     wire,
 						Skipped:    true,
 						TokenUsage: tokenUsage,
+						Seq:        s.nextSeq(),
 					},
 				},
 			})
@@ -240,24 +370,18 @@ func (s *Session) executeStep(
 	var sr domain.StepResult
 	var execErr error
 
-	switch step.Type {
-	case domain.StepTypeScript:
-		sr, execErr = genericAdapter.Execute(ctx, step, s.cfg.WorkDir)
-		s.sessionLogStepOutput(s.cfg.WorkDir, step.Name, ulog, logstream.TypeScript)
-	case domain.StepTypeAgent:
-		if _, ok := step.Config["run"]; ok {
-			sr, execErr = genericAdapter.Execute(ctx, step, s.cfg.WorkDir)
-			s.sessionLogStepOutput(s.cfg.WorkDir, step.Name, ulog, logstream.TypeScript)
-		} else {
-			sr, execErr = promptAdapter.Execute(ctx, step, s.cfg.WorkDir)
-			sessionCopyToLLMLog(s.cfg.WorkDir, step.Name)
-			s.sessionLogStepOutput(s.cfg.WorkDir, step.Name, ulog, logstream.TypeLLM)
-		}
-	case domain.StepTypeHuman:
-		sr, execErr = s.executeHumanStep(ctx, step, s.cfg.WorkDir)
-		s.sessionLogStepOutput(s.cfg.WorkDir, step.Name, ulog, logstream.TypeScript)
-	default:
-		execErr = fmt.Errorf("unknown step type: %s", step.Type)
+	// base = step.<name>.output: start from the tree that step left behind.
+	if err := s.checkoutBase(ctx, step); err != nil {
+		execErr = err
+	} else {
+		sr, execErr = s.runStep(ctx, step, genericAdapter, promptAdapter, ulog)
+	}
+
+	// The daemon sets snapshot_output on steps that a later step uses as its
+	// base; other steps skip the snapshot.
+	var gitRef string
+	if execErr == nil && step.Config["snapshot_output"] == "true" {
+		gitRef = s.recordStepRef(ctx, step.Name)
 	}
 
 	// Record step result in KV store.
@@ -281,7 +405,9 @@ func (s *Session) executeStep(
 			result = "fail"
 		}
 		log.Printf("agent: step %q error: %v", cmd.StepName, execErr)
+		s.status.Error(cmd.StepName, execErr.Error())
 	}
+	s.status.StepCompleted(cmd.StepName, result, sr.Usage)
 
 	var tokenUsage *pb.TokenUsage
 	if sr.Usage != nil {
@@ -290,6 +416,15 @@ func (s *Session) executeStep(
 			OutputTokens: sr.Usage.OutputTokens,
 		}
 	}
+	var exitCode *int32
+	if sr.ExitCode != nil {
+		code := int32(*sr.ExitCode)
+		exitCode = &code
+	}
+	var promptText string
+	if s.cfg.SavePrompts {
+		promptText = sr.Prompt
+	}
 
 	_ = send(&pb.AgentMessage{
 		Payload: &pb.AgentMessage_StepResult{
@@ -297,11 +432,92 @@ func (s *Session) executeStep(
 				RequestId:  cmd.RequestId,
 				Result:     result,
 				TokenUsage: tokenUsage,
+				Seq:        s.nextSeq(),
+				PromptText: promptText,
+				GitRef:     gitRef,
+				ExitCode:   exitCode,
 			},
 		},
 	})
 }
 
+// runStep dispatches a step to the adapter for its type and copies its new
+// output to the unified log.
+func (s *Session) runStep(
+	ctx context.Context,
+	step *domain.Step,
+	genericAdapter *generic.Adapter,
+	promptAdapter *prompt.Adapter,
+	ulog *logstream.Writer,
+) (domain.StepResult, error) {
+	var sr domain.StepResult
+	var err error
+	switch step.Type {
+	case domain.StepTypeScript:
+		sr, err = genericAdapter.Execute(ctx, step, s.cfg.WorkDir)
+		s.sessionLogStepOutput(s.cfg.WorkDir, step.Name, ulog, logstream.TypeScript)
+	case domain.StepTypeAgent:
+		if _, ok := step.Config["run"]; ok {
+			sr, err = genericAdapter.Execute(ctx, step, s.cfg.WorkDir)
+			s.sessionLogStepOutput(s.cfg.WorkDir, step.Name, ulog, logstream.TypeScript)
+		} else {
+			sr, err = promptAdapter.Execute(ctx, step, s.cfg.WorkDir)
+			sessionCopyToLLMLog(s.cfg.WorkDir, step.Name)
+			s.sessionLogStepOutput(s.cfg.WorkDir, step.Name, ulog, logstream.TypeLLM)
+		}
+	case domain.StepTypeHuman:
+		sr, err = s.executeHumanStep(ctx, step, s.cfg.WorkDir)
+		s.sessionLogStepOutput(s.cfg.WorkDir, step.Name, ulog, logstream.TypeScript)
+	default:
+		err = fmt.Errorf("unknown step type: %s", step.Type)
+	}
+	return sr, err
+}
+
+// checkoutBase restores the workspace to the snapshot recorded for the step
+// named by step's base config, if it sets one. It is an error for that step
+// not to have completed earlier in this session.
+func (s *Session) checkoutBase(ctx context.Context, step *domain.Step) error {
+	base, ok, err := step.BaseStep()
+	if !ok {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("step %q: %w", step.Name, err)
+	}
+	s.refMu.Lock()
+	sha := s.stepRefs[base]
+	s.refMu.Unlock()
+	if sha == "" {
+		return fmt.Errorf("step %q: base step %q has no recorded output in this run", step.Name, base)
+	}
+	if err := checkoutSnapshot(ctx, s.cfg.WorkDir, sha); err != nil {
+		return fmt.Errorf("step %q: checking out output of %q: %w", step.Name, base, err)
+	}
+	return nil
+}
+
+// recordStepRef snapshots the workspace a step left behind so later steps
+// can use it as their base, and returns the snapshot commit ("" when the
+// workspace is not a git repository or the snapshot failed).
+func (s *Session) recordStepRef(ctx context.Context, stepName string) string {
+	sha, err := snapshotWorkspace(ctx, s.cfg.WorkDir, stepName)
+	if err != nil {
+		log.Printf("agent: snapshotting output of step %q: %v", stepName, err)
+		return ""
+	}
+	if sha == "" {
+		return ""
+	}
+	s.refMu.Lock()
+	defer s.refMu.Unlock()
+	if s.stepRefs == nil {
+		s.stepRefs = make(map[string]string)
+	}
+	s.stepRefs[stepName] = sha
+	return sha
+}
+
 // sessionLogStepOutput reads the per-step log file and writes only new bytes
 // (since the last call for this step) to the unified log. This ensures each
 // iteration's output appears exactly once in full.log even when the step log
@@ -312,6 +528,8 @@ func (s *Session) sessionLogStepOutput(workDir, stepName string, ulog *logstream
 	if err != nil || len(data) == 0 {
 		return
 	}
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
 	if s.stepLogOffsets == nil {
 		s.stepLogOffsets = make(map[string]int64)
 	}
@@ -339,10 +557,9 @@ func sessionCopyToLLMLog(workDir, stepName string) {
 // executeHumanStep runs a poll step inside the container using a standalone
 // polling loop. Modeled on the host executor's executeHumanStepStandalone.
 func (s *Session) executeHumanStep(ctx context.Context, step *domain.Step, workDir string) (domain.StepResult, error) {
-	intervalStr := step.Config["interval"]
-	interval, err := time.ParseDuration(intervalStr)
+	interval, _, err := step.Config.Duration("interval")
 	if err != nil {
-		return domain.StepResult{}, fmt.Errorf("human step %q: invalid interval %q: %w", step.Name, intervalStr, err)
+		return domain.StepResult{}, fmt.Errorf("human step %q: %w", step.Name, err)
 	}
 
 	// Maximum time allowed for a single invocation before the step is failed.
@@ -533,46 +750,39 @@ func (g *grpcKVReader) Get(ctx context.Context, key string) (string, bool, error
 	return resp.Value, resp.Found, nil
 }
 
-// grpcStatusWriter is an io.Writer that buffers lines from a protocol.StatusWriter
-// and forwards MsgLog entries to the daemon as StepLog gRPC messages.
-type grpcStatusWriter struct {
-	send func(*pb.AgentMessage) error
-	buf  []byte
+// statusSinks fans each status message out to several sinks.
+type statusSinks []protocol.StatusSink
+
+func (ss statusSinks) Status(msg protocol.StatusMessage) {
+	for _, sink := range ss {
+		sink.Status(msg)
+	}
 }
 
-func newGRPCStatusWriter(send func(*pb.AgentMessage) error) *grpcStatusWriter {
-	return &grpcStatusWriter{send: send}
+// stdoutRunSink writes MsgRunCompleted status messages to the agent's
+// stdout as JSON lines, where the daemon's run tracker picks them up. Other
+// messages reach the daemon over the session stream.
+type stdoutRunSink struct {
+	enc *json.Encoder
 }
 
-// Write buffers incoming bytes and processes complete newline-terminated JSON lines.
-func (w *grpcStatusWriter) Write(p []byte) (int, error) {
-	w.buf = append(w.buf, p...)
-	for {
-		idx := bytes.IndexByte(w.buf, '\n')
-		if idx < 0 {
-			break
-		}
-		line := w.buf[:idx]
-		w.buf = w.buf[idx+1:]
-		w.processLine(line)
+func (o stdoutRunSink) Status(msg protocol.StatusMessage) {
+	if msg.Type == protocol.MsgRunCompleted {
+		_ = o.enc.Encode(msg)
 	}
-	return len(p), nil
 }
 
-// processLine parses a single JSON-encoded StatusMessage and, if it is a MsgLog
-// entry, sends a corresponding StepLog message over the gRPC stream.
-func (w *grpcStatusWriter) processLine(line []byte) {
-	if len(bytes.TrimSpace(line)) == 0 {
-		return
-	}
-	var msg protocol.StatusMessage
-	if err := json.Unmarshal(line, &msg); err != nil {
-		return
-	}
+// grpcLogSink forwards MsgLog status messages to the daemon as StepLog gRPC
+// messages. Other message types are reported to the daemon separately.
+type grpcLogSink struct {
+	send func(*pb.AgentMessage) error
+}
+
+func (g grpcLogSink) Status(msg protocol.StatusMessage) {
 	if msg.Type != protocol.MsgLog {
 		return
 	}
-	_ = w.send(&pb.AgentMessage{
+	_ = g.send(&pb.AgentMessage{
 		Payload: &pb.AgentMessage_StepLog{
 			StepLog: &pb.StepLog{
 				StepName:  msg.StepName,
@@ -1,15 +1,20 @@
 package agent_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	pb "github.com/cloche-dev/cloche/api/clochepb"
 	"github.com/cloche-dev/cloche/internal/agent"
+	"github.com/cloche-dev/cloche/internal/protocol"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
@@ -25,6 +30,9 @@ type fakeAgentSessionServer struct {
 	results  chan *pb.StepResult
 	logs     chan *pb.StepLog
 	started  chan *pb.StepStarted
+	// fanout dispatches every step before waiting for any result, the way
+	// the daemon's engine does for parallel branches.
+	fanout bool
 }
 
 func newFakeServer(steps []*pb.ExecuteStep) *fakeAgentSessionServer {
@@ -49,6 +57,32 @@ func (f *fakeAgentSessionServer) AgentSession(stream pb.ClocheService_AgentSessi
 	}
 	f.gotReady <- ready.Ready
 
+	if f.fanout {
+		for _, step := range f.steps {
+			if err := stream.Send(&pb.DaemonMessage{
+				Payload: &pb.DaemonMessage_ExecuteStep{ExecuteStep: step},
+			}); err != nil {
+				return err
+			}
+		}
+		for pending := len(f.steps); pending > 0; {
+			agentMsg, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			switch p := agentMsg.Payload.(type) {
+			case *pb.AgentMessage_StepStarted:
+				f.started <- p.StepStarted
+			case *pb.AgentMessage_StepResult:
+				f.results <- p.StepResult
+				pending--
+			}
+		}
+		return stream.Send(&pb.DaemonMessage{
+			Payload: &pb.DaemonMessage_Shutdown{Shutdown: &pb.Shutdown{}},
+		})
+	}
+
 	// Send each step command and collect results.
 	for _, step := range f.steps {
 		if err := stream.Send(&pb.DaemonMessage{
@@ -172,6 +206,145 @@ func TestSession_ExecuteScriptStep(t *testing.T) {
 	}
 }
 
+func TestSession_ParamsOverrideStepConfig(t *testing.T) {
+	// The daemon resolves the workflow's param defaults into step config;
+	// the session's params (CLOCHE_PARAMS) override them.
+	srv := newFakeServer([]*pb.ExecuteStep{
+		{
+			StepName: "test",
+			StepType: "script",
+			Config: map[string]string{
+				"run":          `test "{{param.target}}" = "./internal/..."`,
+				"param.target": "./...",
+			},
+			RequestId: "req-1",
+		},
+	})
+	addr := startFakeServer(t, srv)
+
+	sess := agent.NewSession(agent.SessionConfig{
+		Addr:    addr,
+		RunID:   "run-params",
+		WorkDir: t.TempDir(),
+		Params:  map[string]string{"target": "./internal/..."},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sess.Run(ctx))
+
+	select {
+	case result := <-srv.results:
+		assert.Equal(t, "success", result.Result)
+	default:
+		t.Fatal("StepResult not received")
+	}
+}
+
+func TestSession_RunTmpDirIsRemovedAfterSession(t *testing.T) {
+	// The first step leaves a file in CLOCHE_TMPDIR and records the path;
+	// the second finds the file there.
+	srv := newFakeServer([]*pb.ExecuteStep{
+		{
+			StepName:  "write",
+			StepType:  "script",
+			Config:    map[string]string{"run": `echo scratch > "$CLOCHE_TMPDIR/notes.txt" && echo "$CLOCHE_TMPDIR" > tmpdir.txt`},
+			RequestId: "req-write",
+		},
+		{
+			StepName:  "read",
+			StepType:  "script",
+			Config:    map[string]string{"run": `[ "$(cat "$CLOCHE_TMPDIR/notes.txt")" = scratch ]`},
+			RequestId: "req-read",
+		},
+	})
+	addr := startFakeServer(t, srv)
+
+	dir := t.TempDir()
+	sess := agent.NewSession(agent.SessionConfig{Addr: addr, RunID: "run-tmp", WorkDir: dir})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sess.Run(ctx))
+
+	assert.Equal(t, map[string]string{"req-write": "success", "req-read": "success"}, drainResults(srv))
+
+	recorded, err := os.ReadFile(filepath.Join(dir, "tmpdir.txt"))
+	require.NoError(t, err)
+	tmpDir := strings.TrimSpace(string(recorded))
+	require.NotEmpty(t, tmpDir)
+	assert.NoDirExists(t, tmpDir)
+}
+
+func TestSession_BaseChecksOutPriorStepOutput(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	script := func(name, run string, config map[string]string) *pb.ExecuteStep {
+		cfg := map[string]string{"run": run}
+		for k, v := range config {
+			cfg[k] = v
+		}
+		return &pb.ExecuteStep{StepName: name, StepType: "script", Config: cfg, RequestId: "req-" + name}
+	}
+	srv := newFakeServer([]*pb.ExecuteStep{
+		script("implement", "echo implemented > code.txt", map[string]string{"snapshot_output": "true"}),
+		script("rework", "echo reworked > code.txt && echo extra > extra.txt", map[string]string{"snapshot_output": "true"}),
+		// review sees implement's tree: rework's edit and new file are gone.
+		script("review", `[ "$(cat code.txt)" = implemented ] && [ ! -e extra.txt ]`,
+			map[string]string{"base": "step.implement.output"}),
+	})
+	addr := startFakeServer(t, srv)
+
+	sess := agent.NewSession(agent.SessionConfig{Addr: addr, RunID: "run-base", WorkDir: dir})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sess.Run(ctx))
+
+	refs := map[string]string{}
+	for len(srv.results) > 0 {
+		r := <-srv.results
+		assert.Equal(t, "success", r.Result, r.RequestId)
+		refs[r.RequestId] = r.GitRef
+	}
+	require.Len(t, refs, 3)
+	assert.NotEmpty(t, refs["req-implement"], "each step's output is snapshotted")
+	assert.NotEqual(t, refs["req-implement"], refs["req-rework"])
+	assert.Empty(t, refs["req-review"], "steps no later step builds on are not snapshotted")
+
+	// The snapshots do not move HEAD.
+	out, err := exec.Command("git", "-C", dir, "log", "--oneline").Output()
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(out), "\n"))
+}
+
+func TestSession_BaseWithoutPriorOutputFails(t *testing.T) {
+	srv := newFakeServer([]*pb.ExecuteStep{
+		{
+			StepName:  "review",
+			StepType:  "script",
+			Config:    map[string]string{"run": "true", "base": "step.implement.output"},
+			RequestId: "req-review",
+		},
+	})
+	addr := startFakeServer(t, srv)
+
+	sess := agent.NewSession(agent.SessionConfig{Addr: addr, RunID: "run-base", WorkDir: t.TempDir()})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sess.Run(ctx))
+
+	assert.Equal(t, map[string]string{"req-review": "fail"}, drainResults(srv))
+}
+
 func TestSession_ExecuteAgentStep(t *testing.T) {
 	dir := t.TempDir()
 
@@ -210,6 +383,45 @@ func TestSession_ExecuteAgentStep(t *testing.T) {
 	}
 }
 
+func TestSession_SendsPromptOnlyWhenSavingPrompts(t *testing.T) {
+	for _, save := range []bool{false, true} {
+		dir := t.TempDir()
+		mockAgent := filepath.Join(dir, "mock-agent.sh")
+		require.NoError(t, os.WriteFile(mockAgent, []byte("#!/bin/sh\ncat > /dev/null\necho 'agent output'\n"), 0755))
+
+		srv := newFakeServer([]*pb.ExecuteStep{
+			{
+				StepName:  "implement",
+				StepType:  "agent",
+				Config:    map[string]string{"prompt": "Do something.", "agent_command": mockAgent},
+				RequestId: "req-1",
+			},
+		})
+		addr := startFakeServer(t, srv)
+
+		sess := agent.NewSession(agent.SessionConfig{
+			Addr:        addr,
+			RunID:       "run-prompt",
+			WorkDir:     dir,
+			SavePrompts: save,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		require.NoError(t, sess.Run(ctx))
+		cancel()
+
+		select {
+		case result := <-srv.results:
+			if save {
+				assert.Contains(t, result.PromptText, "Do something.")
+			} else {
+				assert.Empty(t, result.PromptText)
+			}
+		default:
+			t.Fatal("StepResult not received")
+		}
+	}
+}
+
 func TestSession_StepLogStreaming(t *testing.T) {
 	// A script step that emits multiple lines.
 	srv := newFakeServer([]*pb.ExecuteStep{
@@ -346,6 +558,187 @@ done:
 	assert.Equal(t, "success", results[1].Result)
 }
 
+func TestSession_StatusSinkSeesMessageSequence(t *testing.T) {
+	srv := newFakeServer([]*pb.ExecuteStep{
+		{StepName: "build", StepType: "script", Config: map[string]string{"run": "echo built"}, RequestId: "req-b"},
+		{StepName: "test", StepType: "script", Config: map[string]string{"run": "echo tested"}, RequestId: "req-t"},
+	})
+	addr := startFakeServer(t, srv)
+
+	rec := &protocol.StatusRecorder{}
+	sess := agent.NewSession(agent.SessionConfig{
+		Addr:         addr,
+		RunID:        "run-sink",
+		WorkflowName: "develop",
+		WorkDir:      t.TempDir(),
+		StatusSink:   rec,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sess.Run(ctx))
+
+	type event struct {
+		Type         protocol.MessageType
+		Step, Detail string
+	}
+	var got []event
+	for _, m := range rec.Messages() {
+		assert.Equal(t, "run-sink", m.RunID)
+		assert.Equal(t, "develop", m.WorkflowName)
+		got = append(got, event{m.Type, m.StepName, m.Result + m.Message})
+	}
+	assert.Equal(t, []event{
+		{protocol.MsgStepStarted, "build", ""},
+		{protocol.MsgLog, "build", "built"},
+		{protocol.MsgStepCompleted, "build", "success"},
+		{protocol.MsgStepStarted, "test", ""},
+		{protocol.MsgLog, "test", "tested"},
+		{protocol.MsgStepCompleted, "test", "success"},
+	}, got)
+}
+
+func TestSession_CancelReportsStepAndEmitsCancelledCompletion(t *testing.T) {
+	srv := newFakeServer([]*pb.ExecuteStep{
+		{StepName: "slow", StepType: "script", Config: map[string]string{"run": "exec sleep 30"}, RequestId: "req-slow"},
+	})
+	addr := startFakeServer(t, srv)
+
+	rec := &protocol.StatusRecorder{}
+	var stdout bytes.Buffer
+	sess := agent.NewSession(agent.SessionConfig{
+		Addr:       addr,
+		RunID:      "run-cancel",
+		WorkDir:    t.TempDir(),
+		StatusSink: rec,
+		Stdout:     &stdout,
+	})
+
+	// Cancel as the agent's SIGTERM handler does once the step is running.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-srv.started
+		cancel()
+	}()
+
+	start := time.Now()
+	require.NoError(t, sess.Run(ctx))
+	assert.Less(t, time.Since(start), 10*time.Second, "the step should be killed, not waited out")
+
+	// The cancelled step still reported its result to the daemon.
+	select {
+	case result := <-srv.results:
+		assert.Equal(t, "req-slow", result.RequestId)
+		assert.Equal(t, "fail", result.Result)
+	case <-time.After(time.Second):
+		t.Fatal("StepResult not received for the cancelled step")
+	}
+
+	msgs := rec.Messages()
+	require.NotEmpty(t, msgs)
+	last := msgs[len(msgs)-1]
+	assert.Equal(t, protocol.MsgRunCompleted, last.Type)
+	assert.Equal(t, "cancelled", last.Result)
+
+	// The daemon's run tracker reads the completion from the agent's stdout.
+	var line protocol.StatusMessage
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &line), stdout.String())
+	assert.Equal(t, protocol.MsgRunCompleted, line.Type)
+	assert.Equal(t, "cancelled", line.Result)
+	assert.Equal(t, "run-cancel", line.RunID)
+}
+
+// drainResults returns the results the fake server collected, keyed by
+// request ID.
+func drainResults(srv *fakeAgentSessionServer) map[string]string {
+	got := map[string]string{}
+	for {
+		select {
+		case r := <-srv.results:
+			got[r.RequestId] = r.Result
+		default:
+			return got
+		}
+	}
+}
+
+// scriptedAgentStep returns an agent step whose agent command is a shell
+// script running body in the workspace. The step succeeds if body does.
+func scriptedAgentStep(t *testing.T, name, body string) *pb.ExecuteStep {
+	t.Helper()
+	agentCmd := filepath.Join(t.TempDir(), name+"-agent.sh")
+	require.NoError(t, os.WriteFile(agentCmd, []byte("#!/bin/sh\ncat > /dev/null\n("+body+") || exit 1\necho done\n"), 0755))
+	return &pb.ExecuteStep{
+		StepName:  name,
+		StepType:  "agent",
+		Config:    map[string]string{"prompt": "Do something.", "agent_command": agentCmd},
+		RequestId: "req-" + name,
+	}
+}
+
+// rendezvous is a step script that announces itself and waits for the
+// other step, so it succeeds only if both run at the same time.
+func rendezvous(name, other string) string {
+	return "touch " + name + ".started; for i in $(seq 1 50); do [ -f " + other + ".started ] && exit 0; sleep 0.05; done; exit 1"
+}
+
+func TestSession_FanoutStepsDoNotClobberSharedWorkspace(t *testing.T) {
+	// Each step writes the same file, waits, and fails unless its own
+	// content is still there. Run together in one workspace, one of them
+	// would see the other's write.
+	writer := func(name string) *pb.ExecuteStep {
+		return scriptedAgentStep(t, name, "echo "+name+" > shared.txt && sleep 0.3 && [ \"$(cat shared.txt)\" = "+name+" ]")
+	}
+	srv := newFakeServer([]*pb.ExecuteStep{writer("left"), writer("right")})
+	srv.fanout = true
+	addr := startFakeServer(t, srv)
+
+	sess := agent.NewSession(agent.SessionConfig{Addr: addr, RunID: "run-fanout", WorkDir: t.TempDir()})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sess.Run(ctx))
+
+	assert.Equal(t, map[string]string{"req-left": "success", "req-right": "success"}, drainResults(srv))
+}
+
+func TestSession_ConcurrencyRunsFanoutStepsTogether(t *testing.T) {
+	srv := newFakeServer([]*pb.ExecuteStep{
+		scriptedAgentStep(t, "left", rendezvous("left", "right")),
+		scriptedAgentStep(t, "right", rendezvous("right", "left")),
+	})
+	srv.fanout = true
+	addr := startFakeServer(t, srv)
+
+	sess := agent.NewSession(agent.SessionConfig{Addr: addr, RunID: "run-concurrent", WorkDir: t.TempDir(), Concurrency: 2})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sess.Run(ctx))
+
+	assert.Equal(t, map[string]string{"req-left": "success", "req-right": "success"}, drainResults(srv))
+}
+
+func TestSession_ScriptStepsAreNotLimitedByConcurrency(t *testing.T) {
+	script := func(name, other string) *pb.ExecuteStep {
+		return &pb.ExecuteStep{
+			StepName:  name,
+			StepType:  "script",
+			Config:    map[string]string{"run": rendezvous(name, other)},
+			RequestId: "req-" + name,
+		}
+	}
+	srv := newFakeServer([]*pb.ExecuteStep{script("left", "right"), script("right", "left")})
+	srv.fanout = true
+	addr := startFakeServer(t, srv)
+
+	sess := agent.NewSession(agent.SessionConfig{Addr: addr, RunID: "run-scripts", WorkDir: t.TempDir()})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sess.Run(ctx))
+
+	assert.Equal(t, map[string]string{"req-left": "success", "req-right": "success"}, drainResults(srv))
+}
+
 // TestSession_GRPCStatusWriter_LogForwarding verifies that log lines emitted
 // by an adapter's StatusWriter reach the daemon as StepLog messages.
 func TestSession_GRPCStatusWriter_LogForwarding(t *testing.T) {
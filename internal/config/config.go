@@ -1,10 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+	"github.com/cloche-dev/cloche/internal/domain"
 )
 
 type DaemonConfig struct {
@@ -16,16 +19,45 @@ type DaemonConfig struct {
 	Runtime    string `toml:"runtime"`
 	AgentPath  string `toml:"agent_path"`
 	LLMCommand string `toml:"llm_command"`
+	// PullPolicy controls pulling the container image before a run starts:
+	// "missing" (default) pulls only absent images, "always" pulls every
+	// time, "never" leaves it to the runtime.
+	PullPolicy string `toml:"pull_policy"`
+	// StartTimeoutSeconds fails a run whose container has not started within
+	// this many seconds. Zero or less waits indefinitely.
+	StartTimeoutSeconds int `toml:"start_timeout_seconds"`
+	// DefaultNetwork is the network mode given to containers of workflows
+	// that set neither network nor network_allow, e.g. a named network that
+	// can only reach the daemon. Empty uses the runtime's default. "none" is
+	// rejected: it would cut the in-container agent off from the daemon.
+	DefaultNetwork string `toml:"default_network"`
+	// LivenessFile is rewritten every LivenessIntervalSeconds while the
+	// daemon's gRPC server and database respond, for process supervisors to
+	// watch. Empty disables it.
+	LivenessFile            string `toml:"liveness_file"`
+	LivenessIntervalSeconds int    `toml:"liveness_interval_seconds"`
 }
 
 type EvolutionConfig struct {
 	Enabled          bool   `toml:"enabled"`
 	DebounceSeconds  int    `toml:"debounce_seconds"`
+	DebounceScope    string `toml:"debounce_scope"` // "workflow" (default) or "project"
 	MinConfidence    string `toml:"min_confidence"`
 	MaxPromptBullets int    `toml:"max_prompt_bullets"`
 	PopulationEnabled  bool `toml:"population_enabled"`
 	MaxCandidates      int  `toml:"max_candidates"`
 	MinRunsToPromote   int  `toml:"min_runs_to_promote"`
+	// CollectStates maps a run classification ("bug", "feature", ...) to the
+	// run states collected for that evolution pass. The "default" key applies
+	// to classifications without their own entry. Unset collects every run.
+	CollectStates map[string][]string `toml:"collect_states"`
+	// LogLLM writes each evolution LLM call to .cloche/evolution/llm/.
+	LogLLM bool `toml:"log_llm"`
+	// VerifyEvidence checks reflector lessons against the collected runs.
+	VerifyEvidence bool `toml:"verify_evidence"`
+	// DiffChars caps each run's diff of changes shown to the reflector.
+	// Zero leaves diffs out of the reflection prompt.
+	DiffChars int `toml:"diff_chars"`
 }
 
 type OrchestrationConfig struct {
@@ -37,6 +69,15 @@ type OrchestrationConfig struct {
 	MaxConsecutiveFailures int     `toml:"max_consecutive_failures"` // halt loop after N consecutive failures (default: 3, must be > 0)
 }
 
+// OutputConfig caps agent output separately for each place it is consumed.
+// Limits are in bytes; zero or less means unlimited.
+type OutputConfig struct {
+	StoreChars   int `toml:"store_chars"`   // run error messages and step logs persisted in the database
+	StatusChars  int `toml:"status_chars"`  // error text returned by cloche status
+	PromptChars  int `toml:"prompt_chars"`  // previous step output fed into agent prompts
+	ReflectChars int `toml:"reflect_chars"` // per-step log excerpt in evolution reflection prompts
+}
+
 type AgentCodexConfig struct {
 	UsageCommand string `toml:"usage_command"`
 }
@@ -63,6 +104,22 @@ type GitConfig struct {
 	SSHKey string `toml:"ssh_key"`
 }
 
+// RunsConfig controls what the daemon leaves behind in the project after a
+// container run finishes. Failed and cancelled runs are never cleaned so their
+// inputs stay available for debugging.
+type RunsConfig struct {
+	// CleanSucceededPrompts removes .cloche/runs/<task-id>/prompt.txt (and the
+	// directory, once empty) when a run succeeds.
+	CleanSucceededPrompts bool `toml:"clean_succeeded_prompts"`
+	// SavePrompts writes the full prompt each agent step received to
+	// .cloche/<run-id>/prompts/<step>.<attempt>.txt and records the path on
+	// the step's capture.
+	SavePrompts bool `toml:"save_prompts"`
+	// EngineLog writes the engine's routing decisions (steps launched, wires
+	// and collects fired) for each run to .cloche/<run-id>/engine.log.
+	EngineLog bool `toml:"engine_log"`
+}
+
 // RepositoryConfig describes a repository entry declared in a project's
 // .cloche/config.toml via [[repositories]]. Path is stored as declared
 // (relative to the project root).
@@ -77,10 +134,22 @@ type Config struct {
 	Daemon        DaemonConfig        `toml:"daemon"`
 	Evolution     EvolutionConfig     `toml:"evolution"`
 	Orchestration OrchestrationConfig `toml:"orchestration"`
+	Output        OutputConfig        `toml:"output"`
 	Agents        AgentsConfig        `toml:"agents"`
 	Agent         AgentConfig         `toml:"agent"`
 	Git           GitConfig           `toml:"git"`
+	Runs          RunsConfig          `toml:"runs"`
 	Repositories  []RepositoryConfig  `toml:"repositories"`
+
+	// meta records which keys the config file set, so merging can tell an
+	// explicit value from a default.
+	meta toml.MetaData
+}
+
+// isSet reports whether the loaded file set the given key path, e.g.
+// isSet("output", "prompt_chars").
+func (c *Config) isSet(key ...string) bool {
+	return c.meta.IsDefined(key...)
 }
 
 func defaults() Config {
@@ -88,20 +157,31 @@ func defaults() Config {
 		Agent: AgentConfig{
 			Mode: "prompt",
 		},
+		Daemon: DaemonConfig{
+			StartTimeoutSeconds: 300,
+		},
 		Evolution: EvolutionConfig{
 			Enabled:          true,
 			DebounceSeconds:  30,
+			DebounceScope:    "workflow",
 			MinConfidence:    "medium",
 			MaxPromptBullets: 50,
 			PopulationEnabled:  false,
 			MaxCandidates:      5,
 			MinRunsToPromote:   5,
+			DiffChars:          2000,
 		},
 		Orchestration: OrchestrationConfig{
 			Concurrency:            1,
 			StaggerSeconds:         1.0,
 			MaxConsecutiveFailures: 3,
 		},
+		Output: OutputConfig{
+			StoreChars:   1000,
+			StatusChars:  1000,
+			PromptChars:  64000,
+			ReflectChars: 500,
+		},
 	}
 }
 
@@ -118,12 +198,69 @@ func Load(projectDir string) (*Config, error) {
 		return nil, err
 	}
 
-	if _, err := toml.Decode(string(data), &cfg); err != nil {
+	if err := decode(data, &cfg); err != nil {
 		return nil, err
 	}
 	return &cfg, nil
 }
 
+// SavePromptsEnabled reports whether the project in projectDir sets [runs]
+// save_prompts. A missing project dir or an unreadable config counts as off.
+func SavePromptsEnabled(projectDir string) bool {
+	if projectDir == "" {
+		return false
+	}
+	cfg, err := Load(projectDir)
+	return err == nil && cfg.Runs.SavePrompts
+}
+
+// decode parses TOML config data over cfg and rejects values that would
+// otherwise be accepted silently and misbehave later.
+func decode(data []byte, cfg *Config) error {
+	meta, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return err
+	}
+	cfg.meta = meta
+	return cfg.validate()
+}
+
+// validate checks settings whose typos the TOML decoder cannot catch.
+func (c *Config) validate() error {
+	if strings.TrimSpace(c.Daemon.DefaultNetwork) == domain.NetworkNone {
+		return fmt.Errorf("daemon.default_network: %q would cut the in-container agent off from the daemon; use a network that can reach it", domain.NetworkNone)
+	}
+	switch c.Daemon.PullPolicy {
+	case "", "missing", "always", "never":
+	default:
+		return fmt.Errorf(`daemon.pull_policy: unknown policy %q (want "missing", "always" or "never")`, c.Daemon.PullPolicy)
+	}
+	switch c.Evolution.DebounceScope {
+	case "", "workflow", "project":
+	default:
+		return fmt.Errorf(`evolution.debounce_scope: unknown scope %q (want "workflow" or "project")`, c.Evolution.DebounceScope)
+	}
+	for classification, states := range c.Evolution.CollectStates {
+		for _, st := range states {
+			if !collectableStates[domain.RunState(st)] {
+				return fmt.Errorf("evolution.collect_states.%s: unknown run state %q", classification, st)
+			}
+		}
+	}
+	return nil
+}
+
+// collectableStates are the run states evolution.collect_states may name.
+var collectableStates = map[domain.RunState]bool{
+	domain.RunStateSucceeded: true,
+	domain.RunStateFailed:    true,
+	domain.RunStateCancelled: true,
+	domain.RunStateParked:    true,
+	domain.RunStateRunning:   true,
+	domain.RunStateWaiting:   true,
+	domain.RunStatePending:   true,
+}
+
 // LoadGlobal reads the global daemon config from ~/.config/cloche/config.
 // Returns defaults if the file does not exist.
 func LoadGlobal() (*Config, error) {
@@ -143,7 +280,7 @@ func LoadGlobal() (*Config, error) {
 		return nil, err
 	}
 
-	if _, err := toml.Decode(string(data), &cfg); err != nil {
+	if err := decode(data, &cfg); err != nil {
 		return nil, err
 	}
 	return &cfg, nil
@@ -178,6 +315,23 @@ func mergeInto(dst, src *Config) {
 	if src.Git.SSHKey != "" {
 		dst.Git.SSHKey = src.Git.SSHKey
 	}
+	if src.Daemon.PullPolicy != "" {
+		dst.Daemon.PullPolicy = src.Daemon.PullPolicy
+	}
+	if src.isSet("daemon", "start_timeout_seconds") {
+		dst.Daemon.StartTimeoutSeconds = src.Daemon.StartTimeoutSeconds
+	}
+	// Store limits are daemon-wide (one database), so only the read-side
+	// limits are overridable per project.
+	if src.isSet("output", "status_chars") {
+		dst.Output.StatusChars = src.Output.StatusChars
+	}
+	if src.isSet("output", "prompt_chars") {
+		dst.Output.PromptChars = src.Output.PromptChars
+	}
+	if src.isSet("output", "reflect_chars") {
+		dst.Output.ReflectChars = src.Output.ReflectChars
+	}
 }
 
 // StateDir returns the path to ~/.config/cloche/ and ensures it exists.
@@ -210,6 +364,44 @@ func DefaultAddr() string {
 	return "0.0.0.0:50051"
 }
 
+// DaemonAddrPath returns the file cloched writes its listen address to so
+// clients can find it: ~/.config/cloche/daemon.addr
+func DaemonAddrPath() string {
+	return filepath.Join(StateDir(), "daemon.addr")
+}
+
+// WriteDaemonAddr records addr in the daemon address file.
+func WriteDaemonAddr(addr string) error {
+	if _, err := EnsureStateDir(); err != nil {
+		return err
+	}
+	return os.WriteFile(DaemonAddrPath(), []byte(addr+"\n"), 0644)
+}
+
+// RemoveDaemonAddr deletes the daemon address file if it still holds addr,
+// leaving it alone when another daemon has since taken it over.
+func RemoveDaemonAddr(addr string) {
+	data, err := os.ReadFile(DaemonAddrPath())
+	if err == nil && strings.TrimSpace(string(data)) == addr {
+		_ = os.Remove(DaemonAddrPath())
+	}
+}
+
+// ClientAddr returns the daemon address a client should dial: CLOCHE_ADDR
+// when set, else the address the running daemon recorded in DaemonAddrPath,
+// else DefaultAddr.
+func ClientAddr() string {
+	if addr := os.Getenv("CLOCHE_ADDR"); addr != "" {
+		return addr
+	}
+	if data, err := os.ReadFile(DaemonAddrPath()); err == nil {
+		if addr := strings.TrimSpace(string(data)); addr != "" {
+			return addr
+		}
+	}
+	return DefaultAddr()
+}
+
 // defaultGlobalConfigContent is written to ~/.config/cloche/config on first init.
 var defaultGlobalConfigContent = `# Cloche global daemon configuration
 # This file is read by cloched on startup.
@@ -268,7 +460,7 @@ func LoadGlobalFrom(path string) (*Config, error) {
 		return nil, err
 	}
 
-	if _, err := toml.Decode(string(data), &cfg); err != nil {
+	if err := decode(data, &cfg); err != nil {
 		return nil, err
 	}
 	return &cfg, nil
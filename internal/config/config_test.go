@@ -18,6 +18,7 @@ func TestLoadEvolutionConfig(t *testing.T) {
 [evolution]
 enabled = true
 debounce_seconds = 45
+debounce_scope = "project"
 min_confidence = "high"
 max_prompt_bullets = 30
 `), 0644)
@@ -26,6 +27,7 @@ max_prompt_bullets = 30
 	require.NoError(t, err)
 	assert.True(t, cfg.Evolution.Enabled)
 	assert.Equal(t, 45, cfg.Evolution.DebounceSeconds)
+	assert.Equal(t, "project", cfg.Evolution.DebounceScope)
 	assert.Equal(t, "high", cfg.Evolution.MinConfidence)
 	assert.Equal(t, 30, cfg.Evolution.MaxPromptBullets)
 }
@@ -37,10 +39,73 @@ func TestLoadEvolutionConfigDefaults(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, cfg.Evolution.Enabled)
 	assert.Equal(t, 30, cfg.Evolution.DebounceSeconds)
+	assert.Equal(t, "workflow", cfg.Evolution.DebounceScope)
 	assert.Equal(t, "medium", cfg.Evolution.MinConfidence)
 	assert.Equal(t, 50, cfg.Evolution.MaxPromptBullets)
 }
 
+func TestLoadEvolutionCollectStates(t *testing.T) {
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	os.MkdirAll(clocheDir, 0755)
+
+	os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte(`
+[evolution.collect_states]
+bug = ["failed", "cancelled"]
+default = ["succeeded", "failed"]
+`), 0644)
+
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"failed", "cancelled"}, cfg.Evolution.CollectStates["bug"])
+	assert.Equal(t, []string{"succeeded", "failed"}, cfg.Evolution.CollectStates["default"])
+}
+
+func TestLoadEvolutionCollectStatesRejectsUnknownState(t *testing.T) {
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	os.MkdirAll(clocheDir, 0755)
+
+	os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte(`
+[evolution.collect_states]
+bug = ["failed", "succeded"]
+`), 0644)
+
+	_, err := Load(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `evolution.collect_states.bug: unknown run state "succeded"`)
+}
+
+func TestLoadRejectsUnknownDebounceScope(t *testing.T) {
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	os.MkdirAll(clocheDir, 0755)
+
+	os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte(`
+[evolution]
+debounce_scope = "projects"
+`), 0644)
+
+	_, err := Load(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `evolution.debounce_scope: unknown scope "projects"`)
+}
+
+func TestLoadRejectsUnknownPullPolicy(t *testing.T) {
+	dir := t.TempDir()
+	clocheDir := filepath.Join(dir, ".cloche")
+	os.MkdirAll(clocheDir, 0755)
+
+	os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte(`
+[daemon]
+pull_policy = "if-missing"
+`), 0644)
+
+	_, err := Load(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `daemon.pull_policy: unknown policy "if-missing"`)
+}
+
 func TestLoadDaemonConfig(t *testing.T) {
 	dir := t.TempDir()
 	clocheDir := filepath.Join(dir, ".cloche")
@@ -55,6 +120,7 @@ db = "/var/lib/cloche/data.db"
 runtime = "local"
 agent_path = "/usr/local/bin/cloche-agent"
 llm_command = "claude"
+start_timeout_seconds = 60
 `), 0644)
 
 	cfg, err := Load(dir)
@@ -66,6 +132,7 @@ llm_command = "claude"
 	assert.Equal(t, "local", cfg.Daemon.Runtime)
 	assert.Equal(t, "/usr/local/bin/cloche-agent", cfg.Daemon.AgentPath)
 	assert.Equal(t, "claude", cfg.Daemon.LLMCommand)
+	assert.Equal(t, 60, cfg.Daemon.StartTimeoutSeconds)
 }
 
 func TestLoadDaemonConfigDefaults(t *testing.T) {
@@ -73,12 +140,13 @@ func TestLoadDaemonConfigDefaults(t *testing.T) {
 
 	cfg, err := Load(dir)
 	require.NoError(t, err)
-	// All daemon fields default to zero values (empty strings)
+	// Daemon string fields default to empty; the start timeout has a default.
 	assert.Equal(t, "", cfg.Daemon.Listen)
 	assert.Equal(t, "", cfg.Daemon.HTTP)
 	assert.Equal(t, "", cfg.Daemon.Image)
 	assert.Equal(t, "", cfg.Daemon.DB)
 	assert.Equal(t, "", cfg.Daemon.Runtime)
+	assert.Equal(t, 300, cfg.Daemon.StartTimeoutSeconds)
 }
 
 func TestLoadGlobalFrom(t *testing.T) {
@@ -286,6 +354,102 @@ email = "project-bot@example.com"
 	assert.Equal(t, "project-bot@example.com", cfg.Git.Email)
 }
 
+func TestLoadOutputConfigDefaults(t *testing.T) {
+	cfg, err := Load(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, OutputConfig{StoreChars: 1000, StatusChars: 1000, PromptChars: 64000, ReflectChars: 500}, cfg.Output)
+}
+
+func TestLoadMergedOutputLimits(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	globalDir := filepath.Join(home, ".config", "cloche")
+	require.NoError(t, os.MkdirAll(globalDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte(`
+[output]
+store_chars = 5000
+status_chars = 200
+prompt_chars = 8000
+`), 0644))
+
+	projectDir := t.TempDir()
+	clocheDir := filepath.Join(projectDir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte(`
+[output]
+store_chars = 10
+prompt_chars = 2000
+`), 0644))
+
+	cfg, err := LoadMerged(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, 5000, cfg.Output.StoreChars, "store limit is daemon-wide")
+	assert.Equal(t, 200, cfg.Output.StatusChars, "unset project value falls through to global")
+	assert.Equal(t, 2000, cfg.Output.PromptChars)
+	assert.Equal(t, 500, cfg.Output.ReflectChars)
+}
+
+func TestLoadMergedOutputLimitsExplicitDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	globalDir := filepath.Join(home, ".config", "cloche")
+	require.NoError(t, os.MkdirAll(globalDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte(`
+[output]
+prompt_chars = 8000
+reflect_chars = 0
+`), 0644))
+
+	// A project may set a limit back to the built-in default, or to zero.
+	projectDir := t.TempDir()
+	clocheDir := filepath.Join(projectDir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte(`
+[output]
+prompt_chars = 64000
+status_chars = 0
+`), 0644))
+
+	cfg, err := LoadMerged(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, 64000, cfg.Output.PromptChars)
+	assert.Equal(t, 0, cfg.Output.StatusChars)
+	assert.Equal(t, 0, cfg.Output.ReflectChars, "global zero disables the cap")
+}
+
+func TestLoadMergedStartTimeoutExplicitDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	globalDir := filepath.Join(home, ".config", "cloche")
+	require.NoError(t, os.MkdirAll(globalDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte(`
+[daemon]
+start_timeout_seconds = 60
+`), 0644))
+
+	// A project setting the built-in default still overrides the global value.
+	projectDir := t.TempDir()
+	clocheDir := filepath.Join(projectDir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte(`
+[daemon]
+start_timeout_seconds = 300
+`), 0644))
+
+	cfg, err := LoadMerged(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, 300, cfg.Daemon.StartTimeoutSeconds)
+
+	// A project that leaves it unset keeps the global value.
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte("[daemon]\n"), 0644))
+	cfg, err = LoadMerged(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, 60, cfg.Daemon.StartTimeoutSeconds)
+}
+
 func TestLoadMergedNoFiles(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -383,3 +547,49 @@ func TestWriteGlobalConfigIfAbsent_HTTPDefault(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "localhost:8080", cfg.Daemon.HTTP)
 }
+
+func TestClientAddr_DiscoveryPrecedence(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CLOCHE_ADDR", "")
+
+	// Nothing published: the default socket.
+	assert.Equal(t, DefaultAddr(), ClientAddr())
+
+	// A running daemon's published address wins over the default.
+	require.NoError(t, WriteDaemonAddr("127.0.0.1:50051"))
+	assert.Equal(t, "127.0.0.1:50051", ClientAddr())
+
+	// CLOCHE_ADDR wins over the published address.
+	t.Setenv("CLOCHE_ADDR", "10.0.0.5:6000")
+	assert.Equal(t, "10.0.0.5:6000", ClientAddr())
+	t.Setenv("CLOCHE_ADDR", "")
+
+	// A daemon only removes the file while it still names its own address.
+	RemoveDaemonAddr("127.0.0.1:6000")
+	assert.Equal(t, "127.0.0.1:50051", ClientAddr())
+	RemoveDaemonAddr("127.0.0.1:50051")
+	assert.Equal(t, DefaultAddr(), ClientAddr())
+}
+
+func TestLoadRejectsDefaultNetworkNone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[daemon]\ndefault_network = \"none\"\n"), 0644))
+
+	_, err := LoadGlobalFrom(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `daemon.default_network: "none" would cut the in-container agent off from the daemon`)
+}
+
+func TestSavePromptsEnabled(t *testing.T) {
+	dir := t.TempDir()
+	assert.False(t, SavePromptsEnabled(dir), "no config file")
+	assert.False(t, SavePromptsEnabled(""), "no project dir")
+
+	clocheDir := filepath.Join(dir, ".cloche")
+	os.MkdirAll(clocheDir, 0755)
+	os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte("[runs]\nsave_prompts = true\n"), 0644)
+	assert.True(t, SavePromptsEnabled(dir))
+
+	os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte("[runs\n"), 0644)
+	assert.False(t, SavePromptsEnabled(dir), "unreadable config")
+}
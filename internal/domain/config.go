@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StepConfig is the flat config of a step or workflow: string values under
+// keys that may be dotted (e.g. "container.image"). The typed accessors
+// coerce a value so consumers share one parser and one error message. Each
+// returns ok=false when the key is unset; err reports a value that is set
+// but malformed.
+type StepConfig map[string]string
+
+// Int returns the key's value as an integer.
+func (c StepConfig) Int(key string) (n int, ok bool, err error) {
+	raw, ok := c[key]
+	if !ok {
+		return 0, false, nil
+	}
+	n, err = strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		return 0, true, fmt.Errorf("invalid %s %q: must be an integer", key, raw)
+	}
+	return n, true, nil
+}
+
+// Bool returns the key's value as a boolean. It accepts the values
+// strconv.ParseBool does, such as "true", "false", "1" and "0".
+func (c StepConfig) Bool(key string) (b bool, ok bool, err error) {
+	raw, ok := c[key]
+	if !ok {
+		return false, false, nil
+	}
+	b, err = strconv.ParseBool(strings.TrimSpace(raw))
+	if err != nil {
+		return false, true, fmt.Errorf("invalid %s %q: must be true or false", key, raw)
+	}
+	return b, true, nil
+}
+
+// Duration returns the key's value as a Go duration such as "30m" or "1h30m".
+func (c StepConfig) Duration(key string) (d time.Duration, ok bool, err error) {
+	raw, ok := c[key]
+	if !ok {
+		return 0, false, nil
+	}
+	d, err = time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil {
+		return 0, true, fmt.Errorf("invalid %s %q: must be a duration like 30s or 5m", key, raw)
+	}
+	return d, true, nil
+}
+
+// StringList returns the key's value split on commas, with surrounding space
+// trimmed and empty entries dropped. List syntax in the DSL
+// (context_files = ["a.md", "b.md"]) is stored this way.
+func (c StepConfig) StringList(key string) (list []string, ok bool) {
+	raw, ok := c[key]
+	if !ok {
+		return nil, false
+	}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, true
+}
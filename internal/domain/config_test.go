@@ -0,0 +1,83 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepConfig_Int(t *testing.T) {
+	cfg := domain.StepConfig{"max_attempts": " 3 ", "bad": "three"}
+
+	n, ok, err := cfg.Int("max_attempts")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+
+	n, ok, err = cfg.Int("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Zero(t, n)
+
+	_, ok, err = cfg.Int("bad")
+	assert.True(t, ok)
+	assert.EqualError(t, err, `invalid bad "three": must be an integer`)
+}
+
+func TestStepConfig_Bool(t *testing.T) {
+	cfg := domain.StepConfig{"on": "true", "off": "0", "bad": "yes"}
+
+	b, ok, err := cfg.Bool("on")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, b)
+
+	b, ok, err = cfg.Bool("off")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, b)
+
+	_, ok, err = cfg.Bool("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = cfg.Bool("bad")
+	assert.True(t, ok)
+	assert.EqualError(t, err, `invalid bad "yes": must be true or false`)
+}
+
+func TestStepConfig_Duration(t *testing.T) {
+	cfg := domain.StepConfig{"timeout": "1h30m", "bad": "90"}
+
+	d, ok, err := cfg.Duration("timeout")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Minute, d)
+
+	_, ok, err = cfg.Duration("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = cfg.Duration("bad")
+	assert.True(t, ok)
+	assert.EqualError(t, err, `invalid bad "90": must be a duration like 30s or 5m`)
+}
+
+func TestStepConfig_StringList(t *testing.T) {
+	cfg := domain.StepConfig{"context_files": "README.md, docs/a.md,,", "empty": ""}
+
+	list, ok := cfg.StringList("context_files")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"README.md", "docs/a.md"}, list)
+
+	list, ok = cfg.StringList("empty")
+	assert.True(t, ok)
+	assert.Empty(t, list)
+
+	list, ok = cfg.StringList("missing")
+	assert.False(t, ok)
+	assert.Nil(t, list)
+}
@@ -0,0 +1,103 @@
+package domain
+
+import "sort"
+
+// WorkflowDiff is the graph-level difference between two versions of a
+// workflow: steps, explicit wires, and collects that were added or removed.
+// Implicit wires (the parser's timeout and token-limit fallbacks) are ignored
+// since they follow from the steps themselves.
+type WorkflowDiff struct {
+	AddedSteps      []string
+	RemovedSteps    []string
+	AddedWires      []Wire
+	RemovedWires    []Wire
+	AddedCollects   []Collect
+	RemovedCollects []Collect
+}
+
+// DiffWorkflows compares before and after structurally. Steps are reported in
+// name order; wires and collects in declaration order.
+func DiffWorkflows(before, after *Workflow) WorkflowDiff {
+	var d WorkflowDiff
+	for name := range after.Steps {
+		if _, ok := before.Steps[name]; !ok {
+			d.AddedSteps = append(d.AddedSteps, name)
+		}
+	}
+	for name := range before.Steps {
+		if _, ok := after.Steps[name]; !ok {
+			d.RemovedSteps = append(d.RemovedSteps, name)
+		}
+	}
+	sort.Strings(d.AddedSteps)
+	sort.Strings(d.RemovedSteps)
+
+	d.AddedWires = wiresMissingFrom(after.Wiring, before.Wiring)
+	d.RemovedWires = wiresMissingFrom(before.Wiring, after.Wiring)
+	d.AddedCollects = collectsMissingFrom(after.Collects, before.Collects)
+	d.RemovedCollects = collectsMissingFrom(before.Collects, after.Collects)
+	return d
+}
+
+// Empty reports whether the two workflows have the same graph.
+func (d WorkflowDiff) Empty() bool {
+	return len(d.AddedSteps) == 0 && len(d.RemovedSteps) == 0 &&
+		len(d.AddedWires) == 0 && len(d.RemovedWires) == 0 &&
+		len(d.AddedCollects) == 0 && len(d.RemovedCollects) == 0
+}
+
+// Summary describes the diff as one human-readable line per change, e.g.
+// "added step lint" or "wired lint:fail -> abort". Removals come first, so a
+// rerouted wire reads as "unwired ... " followed by "wired ...".
+func (d WorkflowDiff) Summary() []string {
+	var lines []string
+	for _, name := range d.RemovedSteps {
+		lines = append(lines, "removed step "+name)
+	}
+	for _, w := range d.RemovedWires {
+		lines = append(lines, "unwired "+w.String())
+	}
+	for _, c := range d.RemovedCollects {
+		lines = append(lines, "removed "+c.String())
+	}
+	for _, name := range d.AddedSteps {
+		lines = append(lines, "added step "+name)
+	}
+	for _, w := range d.AddedWires {
+		lines = append(lines, "wired "+w.String())
+	}
+	for _, c := range d.AddedCollects {
+		lines = append(lines, "added "+c.String())
+	}
+	return lines
+}
+
+// wiresMissingFrom returns the explicit wires in ws that are not in other.
+func wiresMissingFrom(ws, other []Wire) []Wire {
+	present := make(map[string]bool, len(other))
+	for _, w := range other {
+		present[w.String()] = true
+	}
+	var missing []Wire
+	for _, w := range ws {
+		if !w.Implicit && !present[w.String()] {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
+
+// collectsMissingFrom returns the collects in cs that are not in other.
+func collectsMissingFrom(cs, other []Collect) []Collect {
+	present := make(map[string]bool, len(other))
+	for _, c := range other {
+		present[c.String()] = true
+	}
+	var missing []Collect
+	for _, c := range cs {
+		if !present[c.String()] {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
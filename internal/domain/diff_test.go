@@ -0,0 +1,61 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffWorkflows(t *testing.T) {
+	before := &domain.Workflow{
+		Name: "develop",
+		Steps: map[string]*domain.Step{
+			"code":   {Name: "code"},
+			"test":   {Name: "test"},
+			"legacy": {Name: "legacy"},
+		},
+		Wiring: []domain.Wire{
+			{From: "code", Result: "success", To: "test"},
+			{From: "test", Result: "success", To: domain.StepDone},
+			{From: "legacy", Result: "success", To: domain.StepDone},
+			{From: "code", Result: "timeout", To: domain.StepAbort, Implicit: true},
+		},
+		Collects: []domain.Collect{
+			{Mode: domain.CollectAll, Conditions: []domain.WireCondition{{Step: "test", Result: "success"}}, To: domain.StepDone},
+		},
+	}
+	after := &domain.Workflow{
+		Name: "develop",
+		Steps: map[string]*domain.Step{
+			"code": {Name: "code"},
+			"test": {Name: "test"},
+			"lint": {Name: "lint"},
+		},
+		Wiring: []domain.Wire{
+			{From: "code", Result: "success", To: "test"},
+			{From: "test", Result: "success", To: "lint"},
+			{From: "lint", Result: "fail", To: domain.StepAbort, Cleanup: "test"},
+			// Implicit wires for new steps are not reported.
+			{From: "lint", Result: "timeout", To: domain.StepAbort, Implicit: true},
+		},
+		Collects: []domain.Collect{
+			{Mode: domain.CollectAny, Conditions: []domain.WireCondition{{Step: "test", Result: "success"}, {Step: "lint", Result: "success"}}, To: domain.StepDone},
+		},
+	}
+
+	diff := domain.DiffWorkflows(before, after)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, []string{
+		"removed step legacy",
+		"unwired test:success -> done",
+		"unwired legacy:success -> done",
+		"removed collect all(test:success) -> done",
+		"added step lint",
+		"wired test:success -> lint",
+		"wired lint:fail -> abort(test)",
+		"added collect any(test:success, lint:success) -> done",
+	}, diff.Summary())
+
+	assert.True(t, domain.DiffWorkflows(after, after).Empty())
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "regexp"
+
+// envRefPattern matches "${NAME}" references and the "$${" escape.
+var envRefPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnvRefs replaces "${NAME}" references in s with the value lookup
+// returns for NAME. "$${" produces a literal "${". Names lookup does not
+// find expand to "" and are returned in missing, in order of appearance.
+func ExpandEnvRefs(s string, lookup func(string) (string, bool)) (out string, missing []string) {
+	out = envRefPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "$${" {
+			return "${"
+		}
+		name := m[2 : len(m)-1]
+		val, ok := lookup(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return val
+	})
+	return out, missing
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "unicode/utf8"
+
+// TruncatedSuffix marks output cut short by TruncateOutput.
+const TruncatedSuffix = "... (truncated)"
+
+// TruncateOutput caps s to max bytes, appending TruncatedSuffix when anything
+// was removed. The cut backs off to a rune boundary so multi-byte characters
+// are never split. A max of zero or less means unlimited.
+func TruncateOutput(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + TruncatedSuffix
+}
@@ -0,0 +1,21 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	assert.Equal(t, "short", domain.TruncateOutput("short", 10))
+	assert.Equal(t, "abcde"+domain.TruncatedSuffix, domain.TruncateOutput("abcdefghij", 5))
+	assert.Equal(t, strings.Repeat("x", 50), domain.TruncateOutput(strings.Repeat("x", 50), 0), "zero means unlimited")
+}
+
+func TestTruncateOutput_RuneBoundary(t *testing.T) {
+	// "é" is two bytes; cutting at byte 2 would split it.
+	got := domain.TruncateOutput("aéb", 2)
+	assert.Equal(t, "a"+domain.TruncatedSuffix, got)
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
@@ -21,23 +22,66 @@ const (
 
 // RunListFilter holds optional filters for listing runs.
 type RunListFilter struct {
-	ProjectDir string
-	State      RunState
-	TaskID     string
-	AttemptID  string
-	Limit      int
-	Since      time.Time
+	ProjectDir   string
+	State        RunState
+	TaskID       string
+	AttemptID    string
+	WorkflowName string
+	Limit        int
+	Since        time.Time
+	// CompletedBefore, when set, matches only finished runs that completed
+	// before it.
+	CompletedBefore time.Time
+	// Order picks the sort key. The zero value sorts by start time.
+	Order RunOrder
+}
+
+// RunOrder selects how listed runs are sorted. Every order puts the most
+// recent runs first.
+type RunOrder string
+
+const (
+	// RunOrderStarted lists running runs first, then by start time.
+	RunOrderStarted RunOrder = "started_at"
+	// RunOrderCompleted lists unfinished runs first, then by completion time,
+	// so recently finished runs are not buried under long-running old ones.
+	RunOrderCompleted RunOrder = "completed_at"
+	// RunOrderUpdated sorts by the last time the run record changed.
+	RunOrderUpdated RunOrder = "updated_at"
+)
+
+// ParseRunOrder validates a run ordering name. An empty name is
+// RunOrderStarted.
+func ParseRunOrder(s string) (RunOrder, error) {
+	switch RunOrder(s) {
+	case "", RunOrderStarted:
+		return RunOrderStarted, nil
+	case RunOrderCompleted, RunOrderUpdated:
+		return RunOrder(s), nil
+	}
+	return "", fmt.Errorf("unknown run order %q (want %s, %s or %s)", s, RunOrderStarted, RunOrderCompleted, RunOrderUpdated)
 }
 
 type StepExecution struct {
 	StepName    string
 	Result      string
 	Skipped     bool        // true when the step's skip script bypassed execution
+	// InvalidResult is true when Result is not among the step's declared
+	// results (e.g. an agent typo), so downstream consumers can ignore it.
+	InvalidResult bool
+	// Seq orders captures within a run by when the event was emitted rather
+	// than when it was stored. Zero means unset.
+	Seq int64
 	StartedAt   time.Time
 	CompletedAt time.Time
 	Logs        string
 	GitRef      string      // output state
 	Usage       *TokenUsage // optional token usage for agent steps
+	// PromptPath is the project-relative file holding the prompt the agent
+	// received for this attempt, when [runs] save_prompts is set.
+	PromptPath string
+	// ExitCode is the exit code of the step's process; nil when unknown.
+	ExitCode *int
 }
 
 func (e *StepExecution) Duration() time.Duration {
@@ -65,6 +109,11 @@ type Run struct {
 	TaskID         string // optional task ID this run is associated with
 	TaskTitle      string // title from the task tracker, for display after the task leaves the active snapshot
 	AttemptID      string // ID of the attempt this run belongs to (v2)
+	// StepCount and StepAttempts are recorded by the store when the run
+	// finishes: the distinct steps that completed and the step executions
+	// including retries. Both are zero for unfinished runs.
+	StepCount    int
+	StepAttempts int
 }
 
 func NewRun(id, workflowName string) *Run {
@@ -152,6 +201,17 @@ func (r *Run) Fail(msg string) {
 	r.ErrorMessage = msg
 }
 
+// IsTerminal returns true if the run has finished and will not change state
+// again. Parked runs are not terminal: they can be unparked and resumed.
+func (r *Run) IsTerminal() bool {
+	switch r.State {
+	case RunStateSucceeded, RunStateFailed, RunStateCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
 // FindFirstFailedStep returns the name of the first step that produced a
 // failure result (fail/error) in the run's step executions.
 // Returns empty string if no failed step is found.
@@ -22,5 +22,9 @@ type UsageSummary struct {
 type StepResult struct {
 	Result  string
 	Usage   *TokenUsage
-	Skipped bool // true when the step's skip script decided to bypass execution
+	Skipped bool   // true when the step's skip script decided to bypass execution
+	Prompt  string // assembled prompt sent to the agent, for agent prompt steps
+	// ExitCode is the exit code of the step's process; nil when no process
+	// ran to completion.
+	ExitCode *int
 }
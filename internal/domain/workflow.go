@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -46,7 +49,54 @@ type Step struct {
 	Name    string
 	Type    StepType
 	Results []string
-	Config  map[string]string
+	Config  StepConfig
+	// ResultsInferred is set by the parser when a script step declares no
+	// results and gets the default [success, fail] from its exit code.
+	ResultsInferred bool
+}
+
+// DefaultErrorResult is the result reported in place of an execution error
+// when a step sets continue_on_error without an explicit error_result.
+const DefaultErrorResult = "fail"
+
+// ContinueOnErrorResult reports whether the step has continue_on_error
+// enabled and, if so, the result to report when its execution errors.
+func (s *Step) ContinueOnErrorResult() (string, bool) {
+	if on, _, err := s.Config.Bool("continue_on_error"); err != nil || !on {
+		return "", false
+	}
+	if r := s.Config["error_result"]; r != "" {
+		return r, true
+	}
+	return DefaultErrorResult, true
+}
+
+// BaseStep returns the step named by the step's base config, written
+// "step.<name>.output": the step runs on the working tree that step left
+// behind. ok is false when the step sets no base; err reports a malformed
+// reference.
+func (s *Step) BaseStep() (name string, ok bool, err error) {
+	ref, ok := s.Config["base"]
+	if !ok {
+		return "", false, nil
+	}
+	parts := strings.Split(ref, ".")
+	if len(parts) != 3 || parts[0] != "step" || parts[1] == "" || parts[2] != "output" {
+		return "", true, fmt.Errorf("base must be step.<name>.output, got %q", ref)
+	}
+	return parts[1], true, nil
+}
+
+// UsedAsBase reports whether any step of the workflow runs on the output of
+// the step named name (base = step.<name>.output), and so needs that output
+// snapshotted.
+func (w *Workflow) UsedAsBase(name string) bool {
+	for _, step := range w.Steps {
+		if base, ok, err := step.BaseStep(); ok && err == nil && base == name {
+			return true
+		}
+	}
+	return false
 }
 
 type Wire struct {
@@ -54,6 +104,18 @@ type Wire struct {
 	Result   string
 	To       string
 	Implicit bool
+	// Cleanup names a step to run before the run is finalized as failed.
+	// Only meaningful when To is StepAbort ("-> abort(cleanup)").
+	Cleanup string
+}
+
+// String renders the wire in DSL syntax, e.g. "test:fail -> abort(cleanup)".
+func (w Wire) String() string {
+	s := fmt.Sprintf("%s:%s -> %s", w.From, w.Result, w.To)
+	if w.Cleanup != "" {
+		s += "(" + w.Cleanup + ")"
+	}
+	return s
 }
 
 type CollectMode string
@@ -74,12 +136,43 @@ type Collect struct {
 	To         string
 }
 
+// String renders the collect in DSL syntax, e.g.
+// "collect all(lint:success, test:success) -> done".
+func (c Collect) String() string {
+	conds := make([]string, len(c.Conditions))
+	for i, cond := range c.Conditions {
+		conds[i] = cond.Step + ":" + cond.Result
+	}
+	return fmt.Sprintf("collect %s(%s) -> %s", c.Mode, strings.Join(conds, ", "), c.To)
+}
+
 // Agent declares a named agent with a command and arguments.
 // Steps reference agents by identifier via the "agent" config key.
 type Agent struct {
 	Name    string
 	Command string
-	Args    string
+	Args    string // agent_args encoding; see SplitArgs
+}
+
+// EncodeArgs encodes an argument list written with list syntax
+// (agent_args = ["--flag", "value with spaces"]) as a config value that
+// SplitArgs turns back into the same arguments.
+func EncodeArgs(args []string) string {
+	data, _ := json.Marshal(args)
+	return string(data)
+}
+
+// SplitArgs returns the arguments in an agent_args config value. A value
+// produced by EncodeArgs keeps its argument boundaries; any other string is
+// split on whitespace, as agent_args always was.
+func SplitArgs(value string) []string {
+	if strings.HasPrefix(value, "[") {
+		var args []string
+		if err := json.Unmarshal([]byte(value), &args); err == nil {
+			return args
+		}
+	}
+	return strings.Fields(value)
 }
 
 // DefaultContainerID is the implicit container id for container workflows that do not
@@ -95,8 +188,18 @@ type Workflow struct {
 	Wiring    []Wire
 	Collects  []Collect
 	EntryStep string
-	Config    map[string]string // workflow-level config (e.g. "container.image")
+	Config    StepConfig        // workflow-level config (e.g. "container.image")
 	Repos     []string          // repositories this workflow consumes; names refer to [[repositories]] entries in config.toml
+	Labels    []string          // "key=value" labels applied to the workflow's container (docker create --label)
+	// ContextFiles are project-relative files whose contents are included in
+	// every agent step's prompt under "## Project Context".
+	ContextFiles []string
+	// Params are named values declared in the workflow's params block, keyed
+	// by name. Step config references them as {{param.<name>}}.
+	Params map[string]string
+	// Defaults are step config values from the workflow's defaults block,
+	// applied to agent steps that do not set the key themselves.
+	Defaults map[string]string
 }
 
 // ContainerID returns the container id for this workflow.
@@ -113,11 +216,38 @@ func (w *Workflow) ContainerID() string {
 	return DefaultContainerID
 }
 
+// MaxConcurrent returns the workflow's max_concurrent setting: how many runs
+// of this workflow the daemon starts at once. Zero means no per-workflow limit.
+func (w *Workflow) MaxConcurrent() int {
+	n, _, err := w.Config.Int("max_concurrent")
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// NetworkNone is the network mode that cuts a container off from every
+// network. Neither a workflow nor the daemon default may use it, since the
+// in-container agent talks to the daemon over the network.
+const NetworkNone = "none"
+
+// Network returns the networking settings of the workflow's container block:
+// the network mode (container.network, e.g. "bridge" or a named network) and the hosts
+// the container may reach (container.network_allow). Both are empty when the
+// workflow leaves networking to the daemon's default.
+func (w *Workflow) Network() (mode string, allow []string) {
+	allow, _ = w.Config.StringList("container.network_allow")
+	return strings.TrimSpace(w.Config["container.network"]), allow
+}
+
 func (w *Workflow) Validate() error {
 	if w.EntryStep == "" {
 		return fmt.Errorf("workflow %q: no entry step defined", w.Name)
 	}
 	if _, ok := w.Steps[w.EntryStep]; !ok {
+		if _, explicit := w.Config["start"]; explicit {
+			return fmt.Errorf("workflow %q: start step %q not found", w.Name, w.EntryStep)
+		}
 		return fmt.Errorf("workflow %q: entry step %q not found", w.Name, w.EntryStep)
 	}
 
@@ -131,6 +261,12 @@ func (w *Workflow) Validate() error {
 		if wire.To != StepDone && wire.To != StepAbort {
 			reachable[wire.To] = true
 		}
+		if wire.Cleanup != "" {
+			if _, ok := w.Steps[wire.Cleanup]; !ok {
+				return fmt.Errorf("workflow %q: abort cleanup step %q not found", w.Name, wire.Cleanup)
+			}
+			reachable[wire.Cleanup] = true
+		}
 	}
 
 	// Validate collects and count their results as wired
@@ -182,6 +318,32 @@ func (w *Workflow) Validate() error {
 		}
 	}
 
+	for name, step := range w.Steps {
+		if result, ok := step.ContinueOnErrorResult(); ok && !wired[name][result] {
+			return fmt.Errorf("workflow %q: step %q sets continue_on_error but error result %q is not a wired result", w.Name, name, result)
+		}
+		if result := step.Config["empty_result"]; result != "" && !wired[name][result] {
+			return fmt.Errorf("workflow %q: step %q sets empty_result but %q is not a wired result", w.Name, name, result)
+		}
+	}
+
+	// Validate base references
+	for name, step := range w.Steps {
+		base, ok, err := step.BaseStep()
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("workflow %q: step %q: %w", w.Name, name, err)
+		}
+		if _, exists := w.Steps[base]; !exists {
+			return fmt.Errorf("workflow %q: step %q base references unknown step %q", w.Name, name, base)
+		}
+		if w.Location == LocationHost {
+			return fmt.Errorf("workflow %q: step %q sets base, which is only supported in container workflows", w.Name, name)
+		}
+	}
+
 	// Validate agent references
 	for name, step := range w.Steps {
 		if agentRef, ok := step.Config["agent"]; ok {
@@ -194,9 +356,30 @@ func (w *Workflow) Validate() error {
 		}
 	}
 
+	// Validate param references in inline step config
+	for name, step := range w.Steps {
+		for _, value := range step.Config {
+			for _, ref := range paramRefPattern.FindAllStringSubmatch(value, -1) {
+				if _, ok := w.Params[ref[1]]; !ok {
+					return fmt.Errorf("workflow %q: step %q references undeclared param %q", w.Name, name, ref[1])
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
+// Resolve copies workflow-level settings (agents, context_files, params and
+// defaults) into each step's config, so executors and adapters only need to
+// look at the step. Call it once after the workflow is validated.
+func (w *Workflow) Resolve() {
+	w.ResolveAgents()
+	w.ResolveContextFiles()
+	w.ResolveParams()
+	w.ResolveDefaults()
+}
+
 // ResolveAgents expands agent references in steps to agent_command/agent_args config.
 // Must be called after Validate to ensure references are valid.
 func (w *Workflow) ResolveAgents() {
@@ -219,6 +402,130 @@ func (w *Workflow) ResolveAgents() {
 	}
 }
 
+// ResolveContextFiles copies the workflow's context_files into the config of
+// each agent step that does not set its own, so adapters only need to look at
+// the step.
+func (w *Workflow) ResolveContextFiles() {
+	if len(w.ContextFiles) == 0 {
+		return
+	}
+	for _, step := range w.Steps {
+		step.ApplyContextFiles(w.ContextFiles)
+	}
+}
+
+// ApplyContextFiles sets the step's context_files config to files unless the
+// step is not an agent step or already declares its own.
+func (s *Step) ApplyContextFiles(files []string) {
+	if s.Type != StepTypeAgent || len(files) == 0 {
+		return
+	}
+	if _, has := s.Config["context_files"]; has {
+		return
+	}
+	if s.Config == nil {
+		s.Config = map[string]string{}
+	}
+	s.Config["context_files"] = strings.Join(files, ",")
+}
+
+// DefaultableStepKeys lists the step config keys a workflow defaults block
+// may set.
+var DefaultableStepKeys = map[string]bool{
+	"max_attempts":               true,
+	"inject_result_instructions": true,
+	"max_prompt_chars":           true,
+	"output_filter":              true,
+}
+
+// ResolveDefaults copies the workflow's defaults into the config of each
+// agent step that does not set its own, so adapters only need to look at the
+// step.
+func (w *Workflow) ResolveDefaults() {
+	for _, step := range w.Steps {
+		step.ApplyDefaults(w.Defaults)
+	}
+}
+
+// ApplyDefaults sets each default on the step unless the step is not an agent
+// step or already declares that key. Step-level values always win.
+func (s *Step) ApplyDefaults(defaults map[string]string) {
+	if s.Type != StepTypeAgent || len(defaults) == 0 {
+		return
+	}
+	if s.Config == nil {
+		s.Config = map[string]string{}
+	}
+	for key, value := range defaults {
+		if _, has := s.Config[key]; !has {
+			s.Config[key] = value
+		}
+	}
+}
+
+// paramConfigPrefix prefixes the step config keys that carry resolved
+// workflow params, so adapters can expand references with only the step.
+const paramConfigPrefix = "param."
+
+// paramRefPattern matches a {{param.<name>}} reference in step config.
+var paramRefPattern = regexp.MustCompile(`\{\{\s*param\.([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// ResolveParams copies the workflow's params into the config of every step.
+func (w *Workflow) ResolveParams() {
+	for _, step := range w.Steps {
+		step.ApplyParams(w.Params)
+	}
+}
+
+// OverrideParams replaces param defaults with the given values (e.g. from
+// "cloche run --param") and re-resolves them into step config. Overriding a
+// param the workflow does not declare is an error.
+func (w *Workflow) OverrideParams(overrides map[string]string) error {
+	for name := range overrides {
+		if _, ok := w.Params[name]; !ok {
+			return fmt.Errorf("workflow %q has no param %q", w.Name, name)
+		}
+	}
+	for name, value := range overrides {
+		w.Params[name] = value
+	}
+	w.ResolveParams()
+	return nil
+}
+
+// ApplyParams sets a "param.<name>" config key on the step for each param.
+// Params are workflow-wide, so existing values are overwritten.
+func (s *Step) ApplyParams(params map[string]string) {
+	if len(params) == 0 {
+		return
+	}
+	if s.Config == nil {
+		s.Config = map[string]string{}
+	}
+	for name, value := range params {
+		s.Config[paramConfigPrefix+name] = value
+	}
+}
+
+// ExpandParams replaces {{param.<name>}} references in text with the param
+// values resolved into the step's config. Referencing a param the step does
+// not carry is an error.
+func (s *Step) ExpandParams(text string) (string, error) {
+	var missing string
+	out := paramRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := paramRefPattern.FindStringSubmatch(ref)[1]
+		value, ok := s.Config[paramConfigPrefix+name]
+		if !ok && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("step %q: undefined param %q", s.Name, missing)
+	}
+	return out, nil
+}
+
 // ValidateLocation checks that step types are compatible with the workflow location.
 // workflow_name steps are allowed in both host and container workflows.
 func (w *Workflow) ValidateLocation() error {
@@ -229,6 +536,7 @@ func (w *Workflow) ValidateLocation() error {
 // Keys with a "container." prefix are also allowed.
 var knownStepConfigKeys = map[string]bool{
 	"prompt":        true,
+	"system":        true,
 	"run":           true,
 	"max_attempts":  true,
 	"timeout":       true,
@@ -246,29 +554,193 @@ var knownStepConfigKeys = map[string]bool{
 	// skip script: optional shell command run before the step; exit 0 means skip
 	"skip":          true,
 	"token-limit":   true,
+	// continue_on_error: convert execution errors into error_result (default "fail")
+	"continue_on_error": true,
+	"error_result":      true,
+	// empty_result: result for a script step that exits 0 without output
+	"empty_result": true,
+	// inject_result_instructions: "false" omits the Result Selection block
+	// from an agent step's prompt
+	"inject_result_instructions": true,
+	// context_files: files included in agent prompts (overrides the workflow list)
+	"context_files": true,
+	// max_prompt_chars: cap on an agent step's assembled prompt
+	"max_prompt_chars": true,
+	// base: step.<name>.output — run on the tree a prior step left behind
+	"base": true,
+	// output_filter: "strip" (agent default), "collapse" or "raw" (script
+	// default) cleanup of ANSI escapes and progress redraws in captured output
+	"output_filter": true,
+}
+
+// ConfigKeyRef names a config key set on a step.
+type ConfigKeyRef struct {
+	Step string
+	Key  string
+}
+
+// UnknownConfigKeys returns the step config keys that are not recognized
+// (likely typos), sorted by step and key.
+func (w *Workflow) UnknownConfigKeys() []ConfigKeyRef {
+	var refs []ConfigKeyRef
+	for name, step := range w.Steps {
+		for key := range step.Config {
+			if knownStepConfigKeys[key] {
+				continue
+			}
+			if strings.HasPrefix(key, "container.") || strings.HasPrefix(key, "host.") || strings.HasPrefix(key, paramConfigPrefix) {
+				continue
+			}
+			refs = append(refs, ConfigKeyRef{Step: name, Key: key})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Step != refs[j].Step {
+			return refs[i].Step < refs[j].Step
+		}
+		return refs[i].Key < refs[j].Key
+	})
+	return refs
 }
 
 // ValidateConfig checks step config keys against known keys and returns
 // warnings for any unrecognized keys (likely typos).
 func (w *Workflow) ValidateConfig() []string {
 	var warnings []string
+	for _, ref := range w.UnknownConfigKeys() {
+		warnings = append(warnings, fmt.Sprintf(
+			"workflow %q: step %q has unrecognized config key %q", w.Name, ref.Step, ref.Key))
+	}
+	return warnings
+}
+
+// UnboundedLoop is a cycle in a workflow's wiring that an agent step can keep
+// going forever: nothing in the loop sets max_attempts, and every result of
+// every step in it leads back into the loop.
+type UnboundedLoop struct {
+	Step  string   // the agent step with no way out
+	Steps []string // every step of the loop, sorted
+}
+
+// UnboundedLoops returns the agent steps caught in a retry loop with no
+// give-up path, sorted by step. Such a loop ends only when the engine hits
+// its step limit. A loop is bounded when any of its steps sets max_attempts
+// (directly or through the workflow defaults), since the engine then
+// synthesizes give-up; it is free when any of its steps, of any type, has a
+// result wired out of the loop. The implicit timeout and token-limit wires to
+// abort every step gets do not count as a way out.
+func (w *Workflow) UnboundedLoops() []UnboundedLoop {
+	// Build the step graph from wires and collects. done and abort are not
+	// steps, so wires to them are always exits.
+	adj := make(map[string][]string)
+	type stepResult struct{ step, result string }
+	targets := make(map[stepResult][]string)
+	for _, wire := range w.Wiring {
+		adj[wire.From] = append(adj[wire.From], wire.To)
+		if wire.Implicit {
+			continue
+		}
+		sr := stepResult{wire.From, wire.Result}
+		targets[sr] = append(targets[sr], wire.To)
+	}
+	for _, c := range w.Collects {
+		for _, cond := range c.Conditions {
+			adj[cond.Step] = append(adj[cond.Step], c.To)
+			sr := stepResult{cond.Step, cond.Result}
+			targets[sr] = append(targets[sr], c.To)
+		}
+	}
+
+	// reaches[name] holds the steps reachable from name over one or more
+	// wires; a step is in a loop when it reaches itself, and the loop is
+	// every step it reaches that leads back to it.
+	reaches := make(map[string]map[string]bool, len(w.Steps))
+	var walk func(name string, seen map[string]bool)
+	walk = func(name string, seen map[string]bool) {
+		for _, next := range adj[name] {
+			if _, ok := w.Steps[next]; ok && !seen[next] {
+				seen[next] = true
+				walk(next, seen)
+			}
+		}
+	}
+	for name := range w.Steps {
+		seen := make(map[string]bool)
+		walk(name, seen)
+		reaches[name] = seen
+	}
+
+	var loops []UnboundedLoop
 	for name, step := range w.Steps {
-		for key := range step.Config {
-			if knownStepConfigKeys[key] {
-				continue
+		if step.Type != StepTypeAgent || !reaches[name][name] {
+			continue
+		}
+		var members []string
+		for other := range reaches[name] {
+			if reaches[other][name] {
+				members = append(members, other)
 			}
-			if strings.HasPrefix(key, "container.") || strings.HasPrefix(key, "host.") {
+		}
+		sort.Strings(members)
+		if w.loopBounded(members) {
+			continue
+		}
+		inLoop := make(map[string]bool, len(members))
+		for _, m := range members {
+			inLoop[m] = true
+		}
+		escapes := false
+		for sr, tos := range targets {
+			if !inLoop[sr.step] {
 				continue
 			}
-			warnings = append(warnings, fmt.Sprintf(
-				"workflow %q: step %q has unrecognized config key %q", w.Name, name, key))
+			for _, to := range tos {
+				if !reaches[to][name] {
+					escapes = true
+				}
+			}
+		}
+		if !escapes {
+			loops = append(loops, UnboundedLoop{Step: name, Steps: members})
+		}
+	}
+	sort.Slice(loops, func(i, j int) bool { return loops[i].Step < loops[j].Step })
+	return loops
+}
+
+// loopBounded reports whether any step of the loop sets a positive
+// max_attempts, counting a default from the workflow for agent steps.
+func (w *Workflow) loopBounded(members []string) bool {
+	for _, name := range members {
+		step := w.Steps[name]
+		n, ok, err := step.Config.Int("max_attempts")
+		if !ok && step.Type == StepTypeAgent {
+			n, _, err = StepConfig(w.Defaults).Int("max_attempts")
 		}
+		if err == nil && n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateLoops returns a warning for each agent step caught in a retry loop
+// with no give-up path (see UnboundedLoops).
+func (w *Workflow) ValidateLoops() []string {
+	var warnings []string
+	for _, loop := range w.UnboundedLoops() {
+		warnings = append(warnings, fmt.Sprintf(
+			"workflow %q: agent step %q loops through %s with no way out; set max_attempts and wire give-up, or wire a result out of the loop",
+			w.Name, loop.Step, strings.Join(loop.Steps, ", ")))
 	}
 	return warnings
 }
 
 // NextSteps returns all target step names wired from the given (stepName, result) pair.
 // Multiple targets indicate fanout — parallel branches launched by the engine.
+// Targets are always returned in wiring declaration order, and the engine
+// starts them in that order, so step records, status output, and collects see
+// a predictable sequence. Callers may rely on this ordering.
 func (w *Workflow) NextSteps(stepName, result string) ([]string, error) {
 	var targets []string
 	for _, wire := range w.Wiring {
@@ -282,6 +754,17 @@ func (w *Workflow) NextSteps(stepName, result string) ([]string, error) {
 	return targets, nil
 }
 
+// AbortCleanup returns the cleanup step named by an "abort(cleanup)" wire for
+// the given step and result, or "" when the abort has no cleanup target.
+func (w *Workflow) AbortCleanup(stepName, result string) string {
+	for _, wire := range w.Wiring {
+		if wire.From == stepName && wire.Result == result && wire.To == StepAbort && wire.Cleanup != "" {
+			return wire.Cleanup
+		}
+	}
+	return ""
+}
+
 // Deprecated: NextStep returns the first target only. Use NextSteps for fanout support.
 func (w *Workflow) NextStep(stepName, result string) (string, error) {
 	targets, err := w.NextSteps(stepName, result)
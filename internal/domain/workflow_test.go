@@ -146,6 +146,72 @@ func TestWorkflow_NextSteps_Fanout(t *testing.T) {
 	assert.Equal(t, []string{"test", "lint"}, next)
 }
 
+func TestWorkflow_NextSteps_FanoutKeepsDeclarationOrder(t *testing.T) {
+	// Targets are deliberately not in alphabetical order, and unrelated wires
+	// are interleaved between them.
+	wf := &domain.Workflow{
+		Name: "fanout-order",
+		Wiring: []domain.Wire{
+			{From: "code", Result: "success", To: "zeta"},
+			{From: "zeta", Result: "pass", To: domain.StepDone},
+			{From: "code", Result: "success", To: "alpha"},
+			{From: "code", Result: "fail", To: domain.StepAbort},
+			{From: "code", Result: "success", To: "mid"},
+		},
+	}
+
+	for i := 0; i < 20; i++ {
+		next, err := wf.NextSteps("code", "success")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"zeta", "alpha", "mid"}, next)
+	}
+}
+
+func TestWorkflow_Validate_Base(t *testing.T) {
+	newWF := func(base string) *domain.Workflow {
+		return &domain.Workflow{
+			Name: "review",
+			Steps: map[string]*domain.Step{
+				"implement": {Name: "implement", Type: domain.StepTypeAgent, Results: []string{"success"}},
+				"review": {
+					Name:    "review",
+					Type:    domain.StepTypeAgent,
+					Results: []string{"success"},
+					Config:  map[string]string{"base": base},
+				},
+			},
+			Wiring: []domain.Wire{
+				{From: "implement", Result: "success", To: "review"},
+				{From: "review", Result: "success", To: domain.StepDone},
+			},
+			EntryStep: "implement",
+		}
+	}
+
+	wf := newWF("step.implement.output")
+	require.NoError(t, wf.Validate())
+	name, ok, err := wf.Steps["review"].BaseStep()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "implement", name)
+	assert.True(t, wf.UsedAsBase("implement"))
+	assert.False(t, wf.UsedAsBase("review"))
+
+	err = newWF("implement").Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `base must be step.<name>.output, got "implement"`)
+
+	err = newWF("step.missing.output").Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `base references unknown step "missing"`)
+
+	wf = newWF("step.implement.output")
+	wf.Location = domain.LocationHost
+	err = wf.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only supported in container workflows")
+}
+
 func TestWorkflow_Validate_CollectValid(t *testing.T) {
 	wf := &domain.Workflow{
 		Name: "parallel",
@@ -266,6 +332,75 @@ func TestWorkflow_ValidateConfig_UnknownKey(t *testing.T) {
 	assert.Contains(t, warnings[0], "unrecognized")
 }
 
+// retryLoopWorkflow wires test to fix on every result and fix back to test
+// on success and to itself on fail, a loop nothing ever leaves.
+func retryLoopWorkflow() *domain.Workflow {
+	return &domain.Workflow{
+		Name:      "develop",
+		EntryStep: "test",
+		Steps: map[string]*domain.Step{
+			"test": {Name: "test", Type: domain.StepTypeScript, Results: []string{"success", "fail"}, Config: map[string]string{"run": "make test"}},
+			"fix":  {Name: "fix", Type: domain.StepTypeAgent, Results: []string{"success", "fail"}, Config: map[string]string{"prompt": "fix it"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "test", Result: "success", To: "fix"},
+			{From: "test", Result: "fail", To: "fix"},
+			{From: "fix", Result: "success", To: "test"},
+			{From: "fix", Result: "fail", To: "fix"},
+		},
+	}
+}
+
+func TestWorkflow_UnboundedLoops_FlagsLoopWithoutGiveUp(t *testing.T) {
+	wf := retryLoopWorkflow()
+
+	loops := wf.UnboundedLoops()
+	require.Len(t, loops, 1)
+	assert.Equal(t, "fix", loops[0].Step)
+	assert.Equal(t, []string{"fix", "test"}, loops[0].Steps)
+
+	warnings := wf.ValidateLoops()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `agent step "fix"`)
+	assert.Contains(t, warnings[0], "max_attempts")
+
+	// The implicit timeout wire to abort is not a way out of the loop.
+	wf.Steps["fix"].Results = append(wf.Steps["fix"].Results, "timeout")
+	wf.Wiring = append(wf.Wiring, domain.Wire{From: "fix", Result: "timeout", To: domain.StepAbort, Implicit: true})
+	require.Len(t, wf.UnboundedLoops(), 1)
+}
+
+func TestWorkflow_UnboundedLoops_GiveUpPaths(t *testing.T) {
+	t.Run("max_attempts on the step", func(t *testing.T) {
+		wf := retryLoopWorkflow()
+		wf.Steps["fix"].Config["max_attempts"] = "3"
+		wf.Steps["fix"].Results = append(wf.Steps["fix"].Results, "give-up")
+		wf.Wiring = append(wf.Wiring, domain.Wire{From: "fix", Result: "give-up", To: domain.StepAbort})
+		assert.Empty(t, wf.UnboundedLoops())
+	})
+	t.Run("max_attempts from defaults", func(t *testing.T) {
+		wf := retryLoopWorkflow()
+		wf.Defaults = map[string]string{"max_attempts": "3"}
+		assert.Empty(t, wf.UnboundedLoops())
+	})
+	t.Run("result wired out of the loop", func(t *testing.T) {
+		wf := retryLoopWorkflow()
+		wf.Wiring[3] = domain.Wire{From: "fix", Result: "fail", To: domain.StepAbort}
+		assert.Empty(t, wf.UnboundedLoops())
+	})
+	t.Run("script step result wired out of the loop", func(t *testing.T) {
+		// The agent retries until the script check passes.
+		wf := retryLoopWorkflow()
+		wf.Wiring[0] = domain.Wire{From: "test", Result: "success", To: domain.StepDone}
+		assert.Empty(t, wf.UnboundedLoops())
+	})
+	t.Run("loop of script steps only", func(t *testing.T) {
+		wf := retryLoopWorkflow()
+		wf.Steps["fix"].Type = domain.StepTypeScript
+		assert.Empty(t, wf.UnboundedLoops())
+	})
+}
+
 func TestWorkflow_ValidateLocation_ContainerAllowsWorkflowStep(t *testing.T) {
 	wf := &domain.Workflow{
 		Name:     "develop",
@@ -542,3 +677,37 @@ func TestWorkflow_ContainerID_EmptyStringTreatedAsDefault(t *testing.T) {
 	}
 	assert.Equal(t, domain.DefaultContainerID, wf.ContainerID())
 }
+
+func TestWorkflow_Validate_ContinueOnErrorResultMustBeWired(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "w",
+		Steps: map[string]*domain.Step{
+			"lint": {Name: "lint", Results: []string{"success"},
+				Config: map[string]string{"continue_on_error": "true"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "lint", Result: "success", To: domain.StepDone},
+		},
+		EntryStep: "lint",
+	}
+	err := wf.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `error result "fail" is not a wired result`)
+}
+
+func TestWorkflow_Validate_EmptyResultMustBeWired(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "w",
+		Steps: map[string]*domain.Step{
+			"check": {Name: "check", Results: []string{"success"},
+				Config: map[string]string{"run": "true", "empty_result": "idle"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "check", Result: "success", To: domain.StepDone},
+		},
+		EntryStep: "check",
+	}
+	err := wf.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `sets empty_result but "idle" is not a wired result`)
+}
@@ -0,0 +1,169 @@
+package dsl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+)
+
+// Severity classifies a Diagnostic. Values match the LSP DiagnosticSeverity
+// numbering so editor integrations can pass them through unchanged.
+type Severity int
+
+const (
+	SeverityError   Severity = 1
+	SeverityWarning Severity = 2
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic is a problem found in a .cloche source. Pos and End are byte
+// offsets into the input (input[Pos:End] is the offending text); Line and Col
+// are the 1-based rune position of Pos.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Line     int
+	Col      int
+	Pos      int
+	End      int
+}
+
+// positionPrefix matches the "line N col M: " prefix parser errors carry,
+// which is redundant once the position is part of the Diagnostic.
+var positionPrefix = regexp.MustCompile(`^line \d+ col \d+: `)
+
+// Diagnostics parses and validates a .cloche source and reports every problem
+// an editor should show: a parse error (parsing stops at the first one),
+// workflow validation errors, and unrecognized step config keys as warnings.
+// Diagnostics are ordered by position. A valid file yields none.
+func Diagnostics(input string) []Diagnostic {
+	p := &Parser{lexer: NewLexer(input), location: domain.LocationContainer}
+	p.advance() // load current
+	p.advance() // load peek
+	workflows, err := p.parseWorkflows()
+	if err != nil {
+		return []Diagnostic{newDiagnostic(SeverityError, positionPrefix.ReplaceAllString(err.Error(), ""), p.current)}
+	}
+
+	decls := indexDecls(input)
+	var diags []Diagnostic
+	for name, wf := range workflows {
+		at := decls.workflows[name]
+		if err := wf.Validate(); err != nil {
+			diags = append(diags, newDiagnostic(SeverityError, err.Error(), at))
+		}
+		for _, ref := range wf.UnknownConfigKeys() {
+			tok, ok := decls.keys[[3]string{name, ref.Step, ref.Key}]
+			if !ok {
+				tok = at
+			}
+			diags = append(diags, newDiagnostic(SeverityWarning,
+				fmt.Sprintf("step %q has unrecognized config key %q", ref.Step, ref.Key), tok))
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Pos != diags[j].Pos {
+			return diags[i].Pos < diags[j].Pos
+		}
+		return diags[i].Message < diags[j].Message
+	})
+	return diags
+}
+
+func newDiagnostic(sev Severity, msg string, tok Token) Diagnostic {
+	return Diagnostic{
+		Severity: sev,
+		Message:  msg,
+		Line:     tok.Line,
+		Col:      tok.Col,
+		Pos:      tok.Pos,
+		End:      tok.End,
+	}
+}
+
+// declIndex locates declarations in a source by name so diagnostics raised
+// against the parsed workflow can point back at the text.
+type declIndex struct {
+	workflows map[string]Token    // workflow name token
+	keys      map[[3]string]Token // {workflow, step, key} -> first token of the key
+}
+
+// indexDecls scans the token stream for "workflow <name> {", "step <name> {"
+// directly inside a workflow, and "<key> =" inside a step, including keys in
+// sub-blocks such as "container { image = ... }". It does not validate
+// structure; it only needs to agree with the parser on files the parser
+// accepts.
+func indexDecls(input string) declIndex {
+	idx := declIndex{
+		workflows: map[string]Token{},
+		keys:      map[[3]string]Token{},
+	}
+
+	var toks []Token
+	lx := NewLexer(input)
+	for {
+		tok := lx.NextToken()
+		toks = append(toks, tok)
+		if tok.Type == TokenEOF {
+			break
+		}
+	}
+	next := func(i int) Token {
+		if i+1 < len(toks) {
+			return toks[i+1]
+		}
+		return toks[len(toks)-1]
+	}
+
+	depth := 0
+	var wf, step, pendingStep string
+	var prefix []string // sub-block names inside the current step
+	for i, tok := range toks {
+		switch {
+		case tok.Type == TokenLBrace:
+			depth++
+			if depth == 2 {
+				step, pendingStep = pendingStep, ""
+			}
+		case tok.Type == TokenRBrace:
+			depth--
+			switch {
+			case depth > 1 && len(prefix) > 0:
+				prefix = prefix[:len(prefix)-1]
+			case depth == 1:
+				step = ""
+			case depth <= 0:
+				depth, wf = 0, ""
+			}
+		case tok.Type != TokenIdent:
+		case depth == 0 && tok.Literal == "workflow" && next(i).Type == TokenIdent:
+			wf = next(i).Literal
+			idx.workflows[wf] = next(i)
+		case depth == 1 && tok.Literal == "step" && next(i).Type == TokenIdent:
+			pendingStep = next(i).Literal
+		case depth >= 2 && step != "" && next(i).Type == TokenLBrace:
+			prefix = append(prefix, tok.Literal)
+		case depth >= 2 && step != "" && next(i).Type == TokenEquals:
+			key := strings.Join(append(append([]string{}, prefix...), tok.Literal), ".")
+			ref := [3]string{wf, step, key}
+			if _, seen := idx.keys[ref]; !seen {
+				idx.keys[ref] = tok
+			}
+		}
+	}
+	return idx
+}
@@ -0,0 +1,72 @@
+package dsl_test
+
+import (
+	"testing"
+
+	"github.com/cloche-dev/cloche/internal/dsl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostics_ValidFile(t *testing.T) {
+	input := `workflow ok {
+  step build {
+    run = "make"
+    results = [success, fail]
+    container {
+      image = "golang:1.22"
+    }
+  }
+  build:success -> done
+  build:fail -> abort
+}`
+	assert.Empty(t, dsl.Diagnostics(input))
+}
+
+func TestDiagnostics_ParseError(t *testing.T) {
+	input := "workflow broken {\n  step build {\n    run = \"make\"\n    results = [success\n  }\n}"
+	diags := dsl.Diagnostics(input)
+	require.Len(t, diags, 1)
+
+	d := diags[0]
+	assert.Equal(t, dsl.SeverityError, d.Severity)
+	assert.Equal(t, 5, d.Line)
+	assert.Equal(t, 3, d.Col)
+	assert.Equal(t, "}", input[d.Pos:d.End])
+	assert.NotContains(t, d.Message, "line 5", "position lives in the diagnostic, not the message")
+}
+
+func TestDiagnostics_ErrorsAndWarnings(t *testing.T) {
+	input := `workflow good {
+  step build {
+    run = "make"
+    results = [success]
+  }
+  build:success -> done
+}
+
+workflow bad {
+  step code {
+    prompt = "write it"
+    tiemout = "5m"
+    results = [success, fail]
+  }
+  code:success -> done
+}`
+	diags := dsl.Diagnostics(input)
+	require.Len(t, diags, 2)
+
+	// Validation error: "fail" is not wired. Reported at the workflow name.
+	assert.Equal(t, dsl.SeverityError, diags[0].Severity)
+	assert.Equal(t, "bad", input[diags[0].Pos:diags[0].End])
+	assert.Equal(t, 9, diags[0].Line)
+	assert.Contains(t, diags[0].Message, "fail")
+
+	// Lint warning: misspelled key, reported at the key itself.
+	assert.Equal(t, dsl.SeverityWarning, diags[1].Severity)
+	assert.Equal(t, "tiemout", input[diags[1].Pos:diags[1].End])
+	assert.Equal(t, 12, diags[1].Line)
+	assert.Equal(t, 5, diags[1].Col)
+	assert.Contains(t, diags[1].Message, `"tiemout"`)
+	assert.Equal(t, "warning", diags[1].Severity.String())
+}
@@ -0,0 +1,39 @@
+package dsl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+)
+
+// rawStepFields are step fields whose string values are passed to a shell or
+// an agent verbatim. "${VAR}" in these is left for the shell to expand, or
+// for the prompt adapter in the case of agent_command and agent_args, so it
+// resolves against the environment the step runs in rather than the one
+// that parsed the workflow.
+var rawStepFields = map[string]bool{
+	"run":           true,
+	"poll":          true,
+	"skip":          true,
+	"prompt":        true,
+	"system":        true,
+	"agent_command": true,
+	"usage_command": true,
+}
+
+// expandEnv replaces "${NAME}" references in a string literal with the value
+// of the environment variable NAME. "$${" produces a literal "${". Undefined
+// variables expand to "" unless the parser is strict, in which case they are
+// reported as an error at the literal's position.
+func (p *Parser) expandEnv(tok Token) (string, error) {
+	if !strings.Contains(tok.Literal, "${") {
+		return tok.Literal, nil
+	}
+	out, missing := domain.ExpandEnvRefs(tok.Literal, os.LookupEnv)
+	if p.strictEnv && len(missing) > 0 {
+		return "", fmt.Errorf("line %d col %d: undefined environment variable %q", tok.Line, tok.Col, missing[0])
+	}
+	return out, nil
+}
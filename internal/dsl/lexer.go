@@ -1,25 +1,29 @@
 package dsl
 
+import "unicode/utf8"
+
 type Lexer struct {
-	input []rune
-	pos   int
-	line  int
-	col   int
+	src    string
+	input  []rune
+	pos    int
+	offset int // byte offset of input[pos] in src
+	line   int
+	col    int
 }
 
 func NewLexer(input string) *Lexer {
-	return &Lexer{input: []rune(input), pos: 0, line: 1, col: 1}
+	return &Lexer{src: input, input: []rune(input), pos: 0, line: 1, col: 1}
 }
 
 func (l *Lexer) NextToken() Token {
 	l.skipWhitespaceAndComments()
 
 	if l.pos >= len(l.input) {
-		return Token{Type: TokenEOF, Line: l.line, Col: l.col}
+		return Token{Type: TokenEOF, Line: l.line, Col: l.col, Pos: l.offset, End: l.offset}
 	}
 
 	ch := l.input[l.pos]
-	tok := Token{Line: l.line, Col: l.col}
+	tok := Token{Line: l.line, Col: l.col, Pos: l.offset}
 
 	switch ch {
 	case '{':
@@ -90,6 +94,7 @@ func (l *Lexer) NextToken() Token {
 		}
 	}
 
+	tok.End = l.offset
 	return tok
 }
 
@@ -101,6 +106,10 @@ func (l *Lexer) advance() {
 		} else {
 			l.col++
 		}
+		// Decode from the source rather than re-encoding the rune so invalid
+		// UTF-8 bytes (which []rune turns into U+FFFD) still count as one byte.
+		_, size := utf8.DecodeRuneInString(l.src[l.offset:])
+		l.offset += size
 		l.pos++
 	}
 }
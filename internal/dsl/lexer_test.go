@@ -175,3 +175,42 @@ func lexAll(l *dsl.Lexer) []dsl.Token {
 	}
 	return tokens
 }
+
+func TestLexer_ByteOffsets(t *testing.T) {
+	input := "step a {\n  prompt = \"h\\\"é\"\n}"
+	tokens := lexAll(dsl.NewLexer(input))
+	require.Len(t, tokens, 8)
+
+	for _, tok := range tokens {
+		if tok.Type != dsl.TokenString && tok.Type != dsl.TokenEOF {
+			assert.Equal(t, tok.Literal, input[tok.Pos:tok.End])
+		}
+	}
+
+	str := tokens[5]
+	require.Equal(t, dsl.TokenString, str.Type)
+	assert.Equal(t, `"h\"é"`, input[str.Pos:str.End])
+	assert.Equal(t, 20, str.Pos)
+	assert.Equal(t, 27, str.End) // é is two bytes
+
+	closing := tokens[6]
+	assert.Equal(t, dsl.TokenRBrace, closing.Type)
+	assert.Equal(t, 28, closing.Pos)
+	assert.Equal(t, 3, closing.Line)
+	assert.Equal(t, 1, closing.Col)
+
+	eof := tokens[7]
+	assert.Equal(t, len(input), eof.Pos)
+	assert.Equal(t, len(input), eof.End)
+}
+
+func TestLexer_ByteOffsetsAfterMultiByteRunes(t *testing.T) {
+	// Line/Col count runes while Pos counts bytes.
+	input := "// 日本語\nworkflow"
+	tok := dsl.NewLexer(input).NextToken()
+	assert.Equal(t, "workflow", tok.Literal)
+	assert.Equal(t, 2, tok.Line)
+	assert.Equal(t, 1, tok.Col)
+	assert.Equal(t, len("// 日本語\n"), tok.Pos)
+	assert.Equal(t, "workflow", input[tok.Pos:tok.End])
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/cloche-dev/cloche/internal/domain"
 )
 
 // StepDef describes a step to add to a workflow.
@@ -21,6 +23,13 @@ type WireDef struct {
 	To     string
 }
 
+// CollectDef describes a collect clause to add.
+type CollectDef struct {
+	Mode       string // "all" or "any"
+	Conditions []domain.WireCondition
+	To         string
+}
+
 // CollectAddition describes a condition to add to an existing collect clause.
 type CollectAddition struct {
 	CollectTarget string
@@ -66,28 +75,10 @@ func (m *Mutator) AddStep(input string, step StepDef) (string, error) {
 
 	stepText := sb.String()
 
-	// Find the position after the last step block's closing brace.
-	// We look for the pattern of a step closing brace (indented "}")
-	// followed by either a blank line, a wire, a collect, or the workflow closing brace.
-	// Strategy: find the last "step NAME {" and then its matching "}"
-	re := regexp.MustCompile(`(?m)(^  \})\n`)
-	matches := re.FindAllStringIndex(input, -1)
-	if len(matches) == 0 {
-		return "", fmt.Errorf("could not find step block closing brace in workflow")
-	}
-
-	// Find the last step block by searching for "step " keyword before each closing brace
-	lastStepEnd := -1
-	for i := len(matches) - 1; i >= 0; i-- {
-		// Check if there's a "step " keyword before this closing brace
-		prefix := input[:matches[i][0]]
-		lastStep := strings.LastIndex(prefix, "  step ")
-		if lastStep >= 0 {
-			lastStepEnd = matches[i][1]
-			break
-		}
-	}
-
+	// Insert on the line after the last step block's closing brace. Locating
+	// it from tokens rather than raw text keeps "step" or "}" inside comments
+	// and strings from being mistaken for structure.
+	lastStepEnd := lastStepBlockEnd(input)
 	if lastStepEnd == -1 {
 		return "", fmt.Errorf("could not find last step block in workflow")
 	}
@@ -111,7 +102,7 @@ func (m *Mutator) AddWiring(input string, wires []WireDef) (string, error) {
 	}
 
 	// Find the last closing brace (the workflow's closing brace)
-	lastBrace := strings.LastIndex(input, "}")
+	lastBrace := lastClosingBrace(input)
 	if lastBrace == -1 {
 		return "", fmt.Errorf("could not find workflow closing brace")
 	}
@@ -136,7 +127,7 @@ func (m *Mutator) RewireResult(input string, from, result, oldTo, newTo string)
 		return "", fmt.Errorf("invalid wire pattern: %w", err)
 	}
 
-	loc := re.FindStringIndex(input)
+	loc := findInCode(input, re)
 	if loc == nil {
 		return "", fmt.Errorf("wire %s:%s -> %s not found in workflow", from, result, oldTo)
 	}
@@ -158,22 +149,20 @@ func (m *Mutator) UpdateCollect(input string, addition CollectAddition) (string,
 	pattern := fmt.Sprintf(`(collect\s+(?:all|any)\()([^)]*?)(\)\s*->\s*%s)`, regexp.QuoteMeta(addition.CollectTarget))
 	re := regexp.MustCompile(pattern)
 
-	match := re.FindStringIndex(input)
+	match := findInCode(input, re)
 	if match == nil {
 		return "", fmt.Errorf("could not find collect clause targeting %q", addition.CollectTarget)
 	}
-
-	submatches := re.FindStringSubmatch(input)
-	if len(submatches) < 4 {
+	if len(match) < 8 {
 		return "", fmt.Errorf("could not parse collect clause")
 	}
 
 	// Add the new condition
-	existingConditions := strings.TrimSpace(submatches[2])
+	existingConditions := strings.TrimSpace(input[match[4]:match[5]])
 	newCondition := fmt.Sprintf("%s:%s", addition.Step, addition.Result)
 	updatedConditions := existingConditions + ", " + newCondition
 
-	replacement := submatches[1] + updatedConditions + submatches[3]
+	replacement := input[match[2]:match[3]] + updatedConditions + input[match[6]:match[7]]
 	result := input[:match[0]] + replacement + input[match[1]:]
 
 	if _, err := Parse(result); err != nil {
@@ -182,3 +171,125 @@ func (m *Mutator) UpdateCollect(input string, addition CollectAddition) (string,
 
 	return result, nil
 }
+
+// AddCollect appends a new collect clause to the workflow text, inserted
+// before the closing brace of the workflow like AddWiring.
+func (m *Mutator) AddCollect(input string, def CollectDef) (string, error) {
+	mode := domain.CollectMode(def.Mode)
+	if mode != domain.CollectAll && mode != domain.CollectAny {
+		return "", fmt.Errorf("collect mode must be %q or %q, got %q", domain.CollectAll, domain.CollectAny, def.Mode)
+	}
+	if len(def.Conditions) == 0 {
+		return "", fmt.Errorf("collect targeting %q has no conditions", def.To)
+	}
+	line := domain.Collect{Mode: mode, Conditions: def.Conditions, To: def.To}.String()
+
+	lastBrace := lastClosingBrace(input)
+	if lastBrace == -1 {
+		return "", fmt.Errorf("could not find workflow closing brace")
+	}
+
+	result := input[:lastBrace] + "  " + line + "\n" + input[lastBrace:]
+
+	if _, err := Parse(result); err != nil {
+		return "", fmt.Errorf("validation failed after adding collect: %w", err)
+	}
+
+	return result, nil
+}
+
+// The Mutator edits workflow text in place so that everything it does not
+// touch, including the author's // comments, survives byte for byte. The
+// helpers below locate edit points from lexer tokens, which never start
+// inside a comment or string, so commented-out wires or braces in comments
+// are not mistaken for live structure.
+
+// tokenize returns the tokens of input up to and including EOF.
+func tokenize(input string) []Token {
+	l := NewLexer(input)
+	var toks []Token
+	for {
+		tok := l.NextToken()
+		toks = append(toks, tok)
+		if tok.Type == TokenEOF {
+			return toks
+		}
+	}
+}
+
+// lastClosingBrace returns the byte offset of the last "}" outside comments
+// and strings, or -1 if there is none.
+func lastClosingBrace(input string) int {
+	toks := tokenize(input)
+	for i := len(toks) - 1; i >= 0; i-- {
+		if toks[i].Type == TokenRBrace {
+			return toks[i].Pos
+		}
+	}
+	return -1
+}
+
+// lastStepBlockEnd returns the offset just past the line holding the closing
+// brace of the last step block, so a trailing comment on that line stays with
+// its step. It returns -1 if the text has no step block.
+func lastStepBlockEnd(input string) int {
+	toks := tokenize(input)
+	end := -1
+	depth := 0
+	for i, tok := range toks {
+		switch tok.Type {
+		case TokenLBrace:
+			depth++
+		case TokenRBrace:
+			depth--
+		case TokenIdent:
+			if depth != 1 || tok.Literal != "step" || i+2 >= len(toks) ||
+				toks[i+1].Type != TokenIdent || toks[i+2].Type != TokenLBrace {
+				continue
+			}
+			if close := matchingBrace(toks, i+2); close != -1 {
+				end = toks[close].End
+			}
+		}
+	}
+	if end == -1 {
+		return -1
+	}
+	if nl := strings.IndexByte(input[end:], '\n'); nl >= 0 {
+		return end + nl + 1
+	}
+	return len(input)
+}
+
+// matchingBrace returns the index of the token closing the "{" at toks[open],
+// or -1 if it is unbalanced.
+func matchingBrace(toks []Token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i].Type {
+		case TokenLBrace:
+			depth++
+		case TokenRBrace:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// findInCode returns the submatch indices of the first match of re that
+// begins at a token, skipping matches inside comments and strings.
+func findInCode(input string, re *regexp.Regexp) []int {
+	starts := make(map[int]bool)
+	for _, tok := range tokenize(input) {
+		starts[tok.Pos] = true
+	}
+	for _, loc := range re.FindAllStringSubmatchIndex(input, -1) {
+		if starts[loc[0]] {
+			return loc
+		}
+	}
+	return nil
+}
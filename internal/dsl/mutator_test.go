@@ -3,6 +3,7 @@ package dsl
 import (
 	"testing"
 
+	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -194,3 +195,158 @@ func TestMutatorUpdateCollect(t *testing.T) {
 	require.Len(t, wf.Collects, 1)
 	assert.Len(t, wf.Collects[0].Conditions, 3)
 }
+
+func TestMutatorAddCollect(t *testing.T) {
+	input := `workflow develop {
+  step test {
+    run = "make test"
+    results = [success, fail]
+  }
+
+  step lint {
+    run = "golint ./..."
+    results = [success, fail]
+  }
+
+  step scan {
+    run = "gosec ./..."
+    results = [success, fail]
+  }
+
+  test:success -> lint
+  test:success -> scan
+  test:fail -> abort
+  lint:fail -> abort
+  scan:fail -> abort
+}`
+
+	m := &Mutator{}
+	result, err := m.AddCollect(input, CollectDef{
+		Mode: "all",
+		Conditions: []domain.WireCondition{
+			{Step: "lint", Result: "success"},
+			{Step: "scan", Result: "success"},
+		},
+		To: "done",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result, "collect all(lint:success, scan:success) -> done")
+
+	wf, err := Parse(result)
+	require.NoError(t, err)
+	require.Len(t, wf.Collects, 1)
+	assert.Equal(t, domain.CollectAll, wf.Collects[0].Mode)
+	assert.Equal(t, "done", wf.Collects[0].To)
+	assert.Equal(t, []domain.WireCondition{
+		{Step: "lint", Result: "success"},
+		{Step: "scan", Result: "success"},
+	}, wf.Collects[0].Conditions)
+
+	_, err = m.AddCollect(input, CollectDef{Mode: "some", Conditions: wf.Collects[0].Conditions, To: "done"})
+	assert.ErrorContains(t, err, "collect mode")
+
+	_, err = m.AddCollect(input, CollectDef{Mode: "any", To: "done"})
+	assert.ErrorContains(t, err, "no conditions")
+}
+
+func TestMutatorAddStepAndWiring_StructuralDiff(t *testing.T) {
+	input := `workflow develop {
+  step test {
+    run = "make test"
+    results = [success, fail]
+  }
+
+  test:success -> done
+  test:fail -> abort
+}`
+
+	m := &Mutator{}
+	updated, err := m.AddStep(input, StepDef{
+		Name:    "lint",
+		Type:    "script",
+		Config:  map[string]string{"run": `"make lint"`},
+		Results: []string{"success", "fail"},
+	})
+	require.NoError(t, err)
+	updated, err = m.AddWiring(updated, []WireDef{
+		{From: "lint", Result: "success", To: "done"},
+		{From: "lint", Result: "fail", To: "abort"},
+	})
+	require.NoError(t, err)
+
+	before, err := Parse(input)
+	require.NoError(t, err)
+	after, err := Parse(updated)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"added step lint",
+		"wired lint:success -> done",
+		"wired lint:fail -> abort",
+	}, domain.DiffWorkflows(before, after).Summary())
+}
+
+const commentedWorkflow = `// Main development loop.
+workflow develop {
+  // Run the suite first; flaky tests are retried by the agent.
+  step test {
+    run = "make test" // fast subset only
+    results = [success, fail]
+  } // end test
+
+  // Wiring
+  test:success -> done
+  // test:fail -> done
+  test:fail -> abort
+} // keep trailing braces } in comments
+`
+
+func TestMutatorAddStep_PreservesComments(t *testing.T) {
+	m := &Mutator{}
+	result, err := m.AddStep(commentedWorkflow, StepDef{
+		Name:    "lint",
+		Type:    "script",
+		Config:  map[string]string{"run": `"make lint"`},
+		Results: []string{"success", "fail"},
+	})
+	require.NoError(t, err)
+
+	// Everything the mutation did not add is carried over verbatim.
+	assert.Equal(t, `// Main development loop.
+workflow develop {
+  // Run the suite first; flaky tests are retried by the agent.
+  step test {
+    run = "make test" // fast subset only
+    results = [success, fail]
+  } // end test
+
+  step lint {
+    run = "make lint"
+    results = [success, fail]
+  }
+
+  // Wiring
+  test:success -> done
+  // test:fail -> done
+  test:fail -> abort
+} // keep trailing braces } in comments
+`, result)
+}
+
+func TestMutatorAddWiring_IgnoresBraceInComment(t *testing.T) {
+	m := &Mutator{}
+	result, err := m.AddWiring(commentedWorkflow, []WireDef{{From: "test", Result: "fail", To: "test"}})
+	require.NoError(t, err)
+	assert.Contains(t, result, "  test:fail -> abort\n  test:fail -> test\n} // keep trailing braces } in comments\n")
+}
+
+func TestMutatorRewireResult_SkipsCommentedWire(t *testing.T) {
+	m := &Mutator{}
+	result, err := m.RewireResult(commentedWorkflow, "test", "fail", "done", "abort")
+	assert.ErrorContains(t, err, "not found")
+	assert.Empty(t, result)
+
+	result, err = m.RewireResult(commentedWorkflow, "test", "fail", "abort", "test")
+	require.NoError(t, err)
+	assert.Contains(t, result, "  // test:fail -> done\n  test:fail -> test\n")
+}
@@ -4,16 +4,17 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/protocol"
 )
 
 type Parser struct {
-	lexer    *Lexer
-	current  Token
-	peek     Token
-	location domain.WorkflowLocation
+	lexer     *Lexer
+	current   Token
+	peek      Token
+	location  domain.WorkflowLocation
+	strictEnv bool
 }
 
 // ParseOption configures the parser.
@@ -26,6 +27,14 @@ func WithLocation(loc domain.WorkflowLocation) ParseOption {
 	}
 }
 
+// WithStrictEnv makes references to undefined environment variables in
+// "${NAME}" interpolations a parse error instead of expanding to "".
+func WithStrictEnv() ParseOption {
+	return func(p *Parser) {
+		p.strictEnv = true
+	}
+}
+
 func Parse(input string, opts ...ParseOption) (*domain.Workflow, error) {
 	p := &Parser{lexer: NewLexer(input)}
 	for _, opt := range opts {
@@ -43,8 +52,11 @@ func ParseForHost(input string) (*domain.Workflow, error) {
 
 // ParseAllForHost parses a host.cloche file that may contain multiple workflows.
 // Returns a map of workflow name to workflow definition.
-func ParseAllForHost(input string) (map[string]*domain.Workflow, error) {
+func ParseAllForHost(input string, opts ...ParseOption) (map[string]*domain.Workflow, error) {
 	p := &Parser{lexer: NewLexer(input), location: domain.LocationHost}
+	for _, opt := range opts {
+		opt(p)
+	}
 	p.advance() // load current
 	p.advance() // load peek
 
@@ -75,11 +87,19 @@ func ParseForContainer(input string) (*domain.Workflow, error) {
 // ParseAll parses a .cloche file that may contain multiple workflows.
 // Workflows default to LocationContainer but a "host { }" block overrides
 // the location to LocationHost, so any .cloche file can define host workflows.
-func ParseAll(input string) (map[string]*domain.Workflow, error) {
+func ParseAll(input string, opts ...ParseOption) (map[string]*domain.Workflow, error) {
 	p := &Parser{lexer: NewLexer(input), location: domain.LocationContainer}
+	for _, opt := range opts {
+		opt(p)
+	}
 	p.advance() // load current
 	p.advance() // load peek
+	return p.parseWorkflows()
+}
 
+// parseWorkflows parses workflows until EOF. On error, p.current is the token
+// the parser stopped at.
+func (p *Parser) parseWorkflows() (map[string]*domain.Workflow, error) {
 	workflows := make(map[string]*domain.Workflow)
 	for p.current.Type != TokenEOF {
 		wf, err := p.parseWorkflow()
@@ -171,6 +191,14 @@ func (p *Parser) parseWorkflow() (*domain.Workflow, error) {
 				return nil, err
 			}
 			wf.Collects = append(wf.Collects, collect)
+		} else if p.current.Type == TokenIdent && p.current.Literal == "params" && p.peek.Type == TokenLBrace {
+			if err := p.parseParams(wf); err != nil {
+				return nil, err
+			}
+		} else if p.current.Type == TokenIdent && p.current.Literal == "defaults" && p.peek.Type == TokenLBrace {
+			if err := p.parseDefaults(wf); err != nil {
+				return nil, err
+			}
 		} else if p.current.Type == TokenIdent && p.peek.Type == TokenLBrace {
 			if err := p.parseWorkflowConfig(wf); err != nil {
 				return nil, err
@@ -200,12 +228,57 @@ func (p *Parser) parseWorkflow() (*domain.Workflow, error) {
 		return nil, err
 	}
 
+	// An explicit "start = <step>" overrides the first-declared-step default.
+	// Existence of the named step is checked by Workflow.Validate.
+	if start, ok := wf.Config["start"]; ok {
+		wf.EntryStep = start
+	}
+
 	if wf.Location != "" {
 		if err := wf.ValidateLocation(); err != nil {
 			return nil, err
 		}
 	}
 
+	// Post-parse fixup: a script step that declares no results reports by its
+	// exit code, so it gets [success, fail]. With continue_on_error the author
+	// has said a failure should not stop the run, so an unwired fail follows
+	// the step's success wires.
+	for name, step := range wf.Steps {
+		if step.Type != domain.StepTypeScript || len(step.Results) > 0 {
+			continue
+		}
+		step.Results = []string{"success", "fail"}
+		step.ResultsInferred = true
+		if result, ok := step.ContinueOnErrorResult(); !ok || result != "fail" {
+			continue
+		}
+		var successWires []domain.Wire
+		hasFailWire := false
+		for _, w := range wf.Wiring {
+			if w.From != name {
+				continue
+			}
+			switch w.Result {
+			case "success":
+				successWires = append(successWires, w)
+			case "fail":
+				hasFailWire = true
+			}
+		}
+		if hasFailWire {
+			continue
+		}
+		for _, w := range successWires {
+			wf.Wiring = append(wf.Wiring, domain.Wire{
+				From:     name,
+				Result:   "fail",
+				To:       w.To,
+				Implicit: true,
+			})
+		}
+	}
+
 	// Post-parse fixup: ensure every step has a "timeout" result and wire.
 	// If no timeout wire is declared, add an implicit wire to abort.
 	for name, step := range wf.Steps {
@@ -296,6 +369,35 @@ func (p *Parser) parseWorkflowField(wf *domain.Workflow) error {
 		}
 		wf.Repos = repos
 		return nil
+	case "context_files":
+		files, err := p.parseStringList()
+		if err != nil {
+			return err
+		}
+		wf.ContextFiles = files
+		return nil
+	case "labels":
+		labels, err := p.parseStringList()
+		if err != nil {
+			return err
+		}
+		for _, l := range labels {
+			if k, _, ok := strings.Cut(l, "="); !ok || k == "" {
+				return fmt.Errorf("line %d col %d: label %q must be in key=value form", keyTok.Line, keyTok.Col, l)
+			}
+		}
+		wf.Labels = labels
+		return nil
+	case "start":
+		if _, exists := wf.Config["start"]; exists {
+			return fmt.Errorf("line %d col %d: duplicate workflow field \"start\"", keyTok.Line, keyTok.Col)
+		}
+		stepTok, err := p.expect(TokenIdent)
+		if err != nil {
+			return fmt.Errorf("start must name a step: %w", err)
+		}
+		wf.Config["start"] = stepTok.Literal
+		return nil
 	case "token-limit":
 		negative := false
 		if p.current.Type == TokenIllegal && p.current.Literal == "-" {
@@ -318,6 +420,19 @@ func (p *Parser) parseWorkflowField(wf *domain.Workflow) error {
 		}
 		wf.Config["token-limit"] = numStr
 		return nil
+	case "max_concurrent":
+		if p.current.Type != TokenInt {
+			return fmt.Errorf("line %d col %d: max_concurrent must be an integer, got %q",
+				p.current.Line, p.current.Col, p.current.Literal)
+		}
+		numStr := p.current.Literal
+		p.advance()
+		if n, err := strconv.Atoi(numStr); err != nil || n < 1 {
+			return fmt.Errorf("line %d col %d: max_concurrent must be 1 or greater, got %q",
+				keyTok.Line, keyTok.Col, numStr)
+		}
+		wf.Config["max_concurrent"] = numStr
+		return nil
 	default:
 		return fmt.Errorf("line %d col %d: unknown workflow field %q", keyTok.Line, keyTok.Col, keyTok.Literal)
 	}
@@ -358,10 +473,25 @@ func (p *Parser) parseWorkflowConfig(wf *domain.Workflow) error {
 			return err
 		}
 
-		val, err := p.parseValue()
+		var val string
+		if keyTok.Literal == "agent_args" {
+			val, err = p.parseArgsValue()
+		} else if rawStepFields[keyTok.Literal] {
+			val, err = p.parseRawValue()
+		} else if p.current.Type == TokenLBracket {
+			var values []string
+			values, err = p.parseStringList()
+			val = strings.Join(values, ",")
+		} else {
+			val, err = p.parseValue()
+		}
 		if err != nil {
 			return err
 		}
+		if prefix == "container" && keyTok.Literal == "network" && strings.TrimSpace(val) == domain.NetworkNone {
+			return fmt.Errorf("line %d col %d: container network %q would cut the agent off from the daemon; use a network that can reach it",
+				keyTok.Line, keyTok.Col, domain.NetworkNone)
+		}
 
 		wf.Config[prefix+"."+keyTok.Literal] = val
 	}
@@ -373,6 +503,84 @@ func (p *Parser) parseWorkflowConfig(wf *domain.Workflow) error {
 	return nil
 }
 
+// parseParams handles a `params { name = "default" ... }` block, whose values
+// step config references as {{param.<name>}}.
+func (p *Parser) parseParams(wf *domain.Workflow) error {
+	p.advance() // consume "params"
+	if _, err := p.expect(TokenLBrace); err != nil {
+		return err
+	}
+	if wf.Params == nil {
+		wf.Params = make(map[string]string)
+	}
+
+	for p.current.Type != TokenRBrace && p.current.Type != TokenEOF {
+		keyTok, err := p.expect(TokenIdent)
+		if err != nil {
+			return fmt.Errorf("expected param name: %w", err)
+		}
+		if _, err := p.expect(TokenEquals); err != nil {
+			return err
+		}
+		val, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		if _, exists := wf.Params[keyTok.Literal]; exists {
+			return fmt.Errorf("line %d col %d: duplicate param %q", keyTok.Line, keyTok.Col, keyTok.Literal)
+		}
+		wf.Params[keyTok.Literal] = val
+	}
+
+	_, err := p.expect(TokenRBrace)
+	return err
+}
+
+// parseDefaults handles a `defaults { max_attempts = 3 }` block, whose values
+// apply to every agent step that does not set the key itself.
+func (p *Parser) parseDefaults(wf *domain.Workflow) error {
+	p.advance() // consume "defaults"
+	if _, err := p.expect(TokenLBrace); err != nil {
+		return err
+	}
+	if wf.Defaults == nil {
+		wf.Defaults = make(map[string]string)
+	}
+
+	for p.current.Type != TokenRBrace && p.current.Type != TokenEOF {
+		keyTok, err := p.expect(TokenIdent)
+		if err != nil {
+			return fmt.Errorf("expected default name: %w", err)
+		}
+		if !domain.DefaultableStepKeys[keyTok.Literal] {
+			return fmt.Errorf("line %d col %d: %q cannot be set in defaults (supported: max_attempts, inject_result_instructions, max_prompt_chars, output_filter)",
+				keyTok.Line, keyTok.Col, keyTok.Literal)
+		}
+		if _, err := p.expect(TokenEquals); err != nil {
+			return err
+		}
+		if keyTok.Literal == "max_attempts" && p.current.Type != TokenInt {
+			return fmt.Errorf("max_attempts must be a numeric value, not a string (line %d, col %d)", p.current.Line, p.current.Col)
+		}
+		val, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		if keyTok.Literal == "output_filter" {
+			if _, err := protocol.ParseOutputFilter(val); err != nil {
+				return fmt.Errorf("line %d col %d: %w", keyTok.Line, keyTok.Col, err)
+			}
+		}
+		if _, exists := wf.Defaults[keyTok.Literal]; exists {
+			return fmt.Errorf("line %d col %d: duplicate default %q", keyTok.Line, keyTok.Col, keyTok.Literal)
+		}
+		wf.Defaults[keyTok.Literal] = val
+	}
+
+	_, err := p.expect(TokenRBrace)
+	return err
+}
+
 func (p *Parser) parseAgent() (*domain.Agent, error) {
 	p.advance() // consume "agent"
 
@@ -397,7 +605,14 @@ func (p *Parser) parseAgent() (*domain.Agent, error) {
 			return nil, err
 		}
 
-		val, err := p.parseValue()
+		var val string
+		if keyTok.Literal == "args" {
+			val, err = p.parseArgsValue()
+		} else if keyTok.Literal == "command" {
+			val, err = p.parseRawValue()
+		} else {
+			val, err = p.parseValue()
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -484,12 +699,11 @@ func (p *Parser) parseStep() (*domain.Step, error) {
 		step.Type = domain.StepTypeWorkflow
 	case hasPoll:
 		// Poll step: requires interval.
-		intervalStr := step.Config["interval"]
-		if intervalStr == "" {
+		if step.Config["interval"] == "" {
 			return nil, fmt.Errorf("step %q: poll step requires an 'interval' field", step.Name)
 		}
-		if _, err := time.ParseDuration(intervalStr); err != nil {
-			return nil, fmt.Errorf("step %q: invalid interval %q: %v", step.Name, intervalStr, err)
+		if _, _, err := step.Config.Duration("interval"); err != nil {
+			return nil, fmt.Errorf("step %q: %v", step.Name, err)
 		}
 		step.Type = domain.StepTypeHuman
 	default:
@@ -532,6 +746,12 @@ func (p *Parser) parseStepField(step *domain.Step, prefix string) error {
 			return err
 		}
 		step.Results = results
+	} else if key == "agent_args" {
+		val, err := p.parseArgsValue()
+		if err != nil {
+			return err
+		}
+		step.Config[key] = val
 	} else if p.current.Type == TokenLBracket {
 		values, err := p.parseStringList()
 		if err != nil {
@@ -562,10 +782,23 @@ func (p *Parser) parseStepField(step *domain.Step, prefix string) error {
 		if key == "max_attempts" && p.current.Type != TokenInt {
 			return fmt.Errorf("max_attempts must be a numeric value, not a string (line %d, col %d)", p.current.Line, p.current.Col)
 		}
+		if rawStepFields[key] {
+			val, err := p.parseRawValue()
+			if err != nil {
+				return err
+			}
+			step.Config[key] = val
+			return nil
+		}
 		val, err := p.parseValue()
 		if err != nil {
 			return err
 		}
+		if key == "output_filter" {
+			if _, err := protocol.ParseOutputFilter(val); err != nil {
+				return fmt.Errorf("line %d col %d: %w", keyTok.Line, keyTok.Col, err)
+			}
+		}
 		step.Config[key] = val
 	}
 
@@ -597,6 +830,12 @@ func (p *Parser) parseIdentList() ([]string, error) {
 }
 
 func (p *Parser) parseStringList() ([]string, error) {
+	return p.parseStrings(true)
+}
+
+// parseStrings parses a list of string literals, expanding "${NAME}"
+// references in each item when expand is set.
+func (p *Parser) parseStrings(expand bool) ([]string, error) {
 	if _, err := p.expect(TokenLBracket); err != nil {
 		return nil, err
 	}
@@ -607,7 +846,13 @@ func (p *Parser) parseStringList() ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
-		items = append(items, tok.Literal)
+		item := tok.Literal
+		if expand {
+			if item, err = p.expandEnv(tok); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, item)
 		if p.current.Type == TokenComma {
 			p.advance()
 		}
@@ -620,12 +865,38 @@ func (p *Parser) parseStringList() ([]string, error) {
 	return items, nil
 }
 
-func (p *Parser) parseValue() (string, error) {
+// parseArgsValue parses an agent_args value: either a string, split on
+// whitespace when used, or a list of strings whose boundaries are kept (see
+// domain.SplitArgs). "${NAME}" references are kept for the prompt adapter to
+// expand when the step runs.
+func (p *Parser) parseArgsValue() (string, error) {
+	if p.current.Type != TokenLBracket {
+		return p.parseRawValue()
+	}
+	args, err := p.parseStrings(false)
+	if err != nil {
+		return "", err
+	}
+	return domain.EncodeArgs(args), nil
+}
+
+// parseRawValue parses a value like parseValue but leaves "${NAME}"
+// references in a string literal unexpanded.
+func (p *Parser) parseRawValue() (string, error) {
 	if p.current.Type == TokenString {
 		tok := p.current
 		p.advance()
 		return tok.Literal, nil
 	}
+	return p.parseValue()
+}
+
+func (p *Parser) parseValue() (string, error) {
+	if p.current.Type == TokenString {
+		tok := p.current
+		p.advance()
+		return p.expandEnv(tok)
+	}
 
 	if p.current.Type == TokenInt {
 		tok := p.current
@@ -694,11 +965,26 @@ func (p *Parser) parseWire() (domain.Wire, error) {
 		return domain.Wire{}, err
 	}
 
-	return domain.Wire{
+	wire := domain.Wire{
 		From:   fromTok.Literal,
 		Result: resultTok.Literal,
 		To:     toTok.Literal,
-	}, nil
+	}
+
+	// "abort(cleanup)" runs the named step before the run is finalized as failed.
+	if toTok.Literal == domain.StepAbort && p.current.Type == TokenLParen {
+		p.advance()
+		cleanupTok, err := p.expect(TokenIdent)
+		if err != nil {
+			return domain.Wire{}, fmt.Errorf("expected cleanup step in abort(...): %w", err)
+		}
+		if _, err := p.expect(TokenRParen); err != nil {
+			return domain.Wire{}, err
+		}
+		wire.Cleanup = cleanupTok.Literal
+	}
+
+	return wire, nil
 }
 
 // parseGlobalWire parses a "result -> target" directive at workflow level.
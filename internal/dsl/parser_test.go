@@ -1,6 +1,7 @@
 package dsl_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cloche-dev/cloche/internal/domain"
@@ -67,6 +68,162 @@ func TestParser_MinimalWorkflow(t *testing.T) {
 	assert.Equal(t, "build", wf.EntryStep)
 }
 
+func TestParser_ExplicitStart(t *testing.T) {
+	input := `workflow develop {
+  start = implement
+
+  step test {
+    run = "go test ./..."
+    results = [success, fail]
+  }
+
+  step implement {
+    prompt = "do it"
+    results = [success]
+  }
+
+  implement:success -> test
+  test:success -> done
+  test:fail -> abort
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	assert.Equal(t, "implement", wf.EntryStep)
+	require.NoError(t, wf.Validate())
+}
+
+func TestParser_AbortWithCleanup(t *testing.T) {
+	input := `workflow develop {
+  step code {
+    run = "make"
+    results = [success, fail]
+  }
+
+  step cleanup {
+    run = "git reset --hard"
+    results = [success]
+  }
+
+  code:success -> done
+  code:fail -> abort(cleanup)
+  cleanup:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	require.NoError(t, wf.Validate())
+
+	var abortWire *domain.Wire
+	for i := range wf.Wiring {
+		if wf.Wiring[i].From == "code" && wf.Wiring[i].Result == "fail" {
+			abortWire = &wf.Wiring[i]
+		}
+	}
+	require.NotNil(t, abortWire)
+	assert.Equal(t, domain.StepAbort, abortWire.To)
+	assert.Equal(t, "cleanup", abortWire.Cleanup)
+}
+
+func TestParser_AbortWithUnknownCleanup(t *testing.T) {
+	input := `workflow develop {
+  step code {
+    run = "make"
+    results = [success, fail]
+  }
+  code:success -> done
+  code:fail -> abort(nope)
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	err = wf.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `abort cleanup step "nope" not found`)
+}
+
+func TestParser_ExplicitStartUnknownStep(t *testing.T) {
+	input := `workflow develop {
+  start = nope
+  step build {
+    run = "make"
+    results = [success]
+  }
+  build:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	err = wf.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `start step "nope" not found`)
+}
+
+func TestParser_DuplicateStart(t *testing.T) {
+	input := `workflow develop {
+  start = build
+  start = build
+  step build {
+    run = "make"
+    results = [success]
+  }
+  build:success -> done
+}`
+
+	_, err := dsl.Parse(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate workflow field")
+}
+
+func TestParser_WorkflowLabels(t *testing.T) {
+	input := `workflow develop {
+  labels = ["team=infra", "cost-center=ci"]
+  step build {
+    run = "make"
+    results = [success]
+  }
+  build:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team=infra", "cost-center=ci"}, wf.Labels)
+}
+
+func TestParser_WorkflowLabelsRequireKeyValue(t *testing.T) {
+	input := `workflow develop {
+  labels = ["infra"]
+  step build {
+    run = "make"
+    results = [success]
+  }
+  build:success -> done
+}`
+
+	_, err := dsl.Parse(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key=value")
+}
+
+func TestParser_MaxConcurrent(t *testing.T) {
+	input := `workflow build {
+  max_concurrent = 1
+  step compile {
+    run = "make"
+    results = [success]
+  }
+  compile:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, wf.MaxConcurrent())
+
+	_, err = dsl.Parse(strings.Replace(input, "max_concurrent = 1", "max_concurrent = 0", 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_concurrent must be 1 or greater")
+}
+
 func TestParser_SyntaxError(t *testing.T) {
 	input := `workflow { }`
 	_, err := dsl.Parse(input)
@@ -94,6 +251,24 @@ func TestParser_ContainerBlock(t *testing.T) {
 	assert.Equal(t, "docs.python.org,internal.example.com", code.Config["container.network_allow"])
 }
 
+func TestParser_WorkflowContainerNetworkNoneRejected(t *testing.T) {
+	input := `workflow isolated {
+  container {
+    network = "none"
+  }
+
+  step code {
+    prompt = "do something"
+    results = [success]
+  }
+  code:success -> done
+}`
+
+	_, err := dsl.Parse(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `container network "none" would cut the agent off from the daemon`)
+}
+
 func TestParser_WorkflowContainerBlock(t *testing.T) {
 	input := `workflow with-image {
   container {
@@ -1060,3 +1235,485 @@ func TestParser_PollStep_Validate(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, wf.Validate())
 }
+
+func TestParser_EnvInterpolation(t *testing.T) {
+	t.Setenv("CLOCHE_TEST_REGISTRY", "registry.example.com")
+	input := `workflow develop {
+  container {
+    image = "${CLOCHE_TEST_REGISTRY}/agent:latest"
+  }
+  labels = ["registry=${CLOCHE_TEST_REGISTRY}"]
+
+  step build {
+    run = "echo ${HOME} $${literal}"
+    results = [success]
+  }
+  build:success -> done
+}`
+
+	wf, err := dsl.Parse(input, dsl.WithStrictEnv())
+	require.NoError(t, err)
+	assert.Equal(t, "registry.example.com/agent:latest", wf.Config["container.image"])
+	assert.Equal(t, []string{"registry=registry.example.com"}, wf.Labels)
+	assert.Equal(t, "echo ${HOME} $${literal}", wf.Steps["build"].Config["run"], "run scripts are left for the shell")
+}
+
+func TestParser_EnvInterpolationEscape(t *testing.T) {
+	input := `workflow develop {
+  container {
+    image = "$${NOT_A_VAR}"
+  }
+  step build {
+    run = "make"
+    results = [success]
+  }
+  build:success -> done
+}`
+
+	wf, err := dsl.Parse(input, dsl.WithStrictEnv())
+	require.NoError(t, err)
+	assert.Equal(t, "${NOT_A_VAR}", wf.Config["container.image"])
+}
+
+func TestParser_EnvInterpolationUndefined(t *testing.T) {
+	input := `workflow develop {
+  container {
+    image = "${CLOCHE_TEST_UNDEFINED_VAR}/agent"
+  }
+  step build {
+    run = "make"
+    results = [success]
+  }
+  build:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	assert.Equal(t, "/agent", wf.Config["container.image"], "undefined variables expand to empty outside strict mode")
+
+	_, err = dsl.Parse(input, dsl.WithStrictEnv())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `undefined environment variable "CLOCHE_TEST_UNDEFINED_VAR"`)
+	assert.Contains(t, err.Error(), "line 3")
+}
+
+func TestParser_EnvInterpolationDeferredForAgentFields(t *testing.T) {
+	t.Setenv("CLOCHE_TEST_AGENT", "parse-time")
+	input := `workflow develop {
+  container {
+    agent_command = "${CLOCHE_TEST_AGENT}"
+    agent_args = ["--model", "${CLOCHE_TEST_AGENT}"]
+  }
+  agent custom {
+    command = "${CLOCHE_TEST_AGENT}/bin/agent"
+    args = "--key ${CLOCHE_TEST_AGENT}"
+  }
+  step implement {
+    prompt = "Implement it."
+    agent_command = "${CLOCHE_TEST_AGENT},claude"
+    agent_args = ["--flag", "${CLOCHE_TEST_AGENT}"]
+    usage_command = "usage --for ${CLOCHE_TEST_AGENT}"
+    results = [success]
+  }
+  implement:success -> done
+}`
+
+	wf, err := dsl.Parse(input, dsl.WithStrictEnv())
+	require.NoError(t, err)
+	step := wf.Steps["implement"]
+	assert.Equal(t, "${CLOCHE_TEST_AGENT},claude", step.Config["agent_command"])
+	assert.Equal(t, []string{"--flag", "${CLOCHE_TEST_AGENT}"}, domain.SplitArgs(step.Config["agent_args"]))
+	assert.Equal(t, "usage --for ${CLOCHE_TEST_AGENT}", step.Config["usage_command"])
+	assert.Equal(t, "${CLOCHE_TEST_AGENT}", wf.Config["container.agent_command"])
+	assert.Equal(t, []string{"--model", "${CLOCHE_TEST_AGENT}"}, domain.SplitArgs(wf.Config["container.agent_args"]))
+	assert.Equal(t, "${CLOCHE_TEST_AGENT}/bin/agent", wf.Agents["custom"].Command)
+	assert.Equal(t, "--key ${CLOCHE_TEST_AGENT}", wf.Agents["custom"].Args)
+}
+
+func TestParser_ContextFiles(t *testing.T) {
+	input := `workflow develop {
+  context_files = ["AGENTS.md", "docs/arch.md"]
+  step implement {
+    prompt = "do it"
+    results = [success]
+  }
+  step test {
+    run = "make test"
+    results = [success]
+  }
+  implement:success -> test
+  test:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AGENTS.md", "docs/arch.md"}, wf.ContextFiles)
+
+	wf.ResolveContextFiles()
+	assert.Equal(t, "AGENTS.md,docs/arch.md", wf.Steps["implement"].Config["context_files"])
+	_, scriptHas := wf.Steps["test"].Config["context_files"]
+	assert.False(t, scriptHas, "script steps do not get context files")
+}
+
+func TestParser_Base(t *testing.T) {
+	input := `workflow develop {
+  step implement {
+    prompt = "do it"
+    results = [success]
+  }
+  step review {
+    prompt = "review it"
+    base = step.implement.output
+    results = [success]
+  }
+  implement:success -> review
+  review:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	require.NoError(t, wf.Validate())
+	assert.Equal(t, "step.implement.output", wf.Steps["review"].Config["base"])
+	assert.Empty(t, wf.UnknownConfigKeys())
+}
+
+func TestParser_AgentArgsList(t *testing.T) {
+	input := `workflow develop {
+  container {
+    agent_args = ["--append-system-prompt", "be brief"]
+  }
+  agent reviewer {
+    command = "claude"
+    args    = ["--model", "opus", "--system-prompt", "review carefully"]
+  }
+  step implement {
+    prompt     = "do it"
+    agent_args = ["-p", "--allowedTools", "Bash(go test:*) Edit"]
+    results    = [success]
+  }
+  step review {
+    prompt     = "review it"
+    agent      = reviewer
+    results    = [success]
+  }
+  step legacy {
+    prompt     = "again"
+    agent_args = "--model sonnet -p"
+    results    = [success]
+  }
+  implement:success -> review
+  review:success -> legacy
+  legacy:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	wf.ResolveAgents()
+
+	assert.Equal(t, []string{"-p", "--allowedTools", "Bash(go test:*) Edit"}, domain.SplitArgs(wf.Steps["implement"].Config["agent_args"]))
+	assert.Equal(t, []string{"--model", "opus", "--system-prompt", "review carefully"}, domain.SplitArgs(wf.Steps["review"].Config["agent_args"]))
+	assert.Equal(t, []string{"--append-system-prompt", "be brief"}, domain.SplitArgs(wf.Config["container.agent_args"]))
+	// The string form still splits on whitespace.
+	assert.Equal(t, []string{"--model", "sonnet", "-p"}, domain.SplitArgs(wf.Steps["legacy"].Config["agent_args"]))
+}
+
+func TestParser_Params(t *testing.T) {
+	input := `workflow ci {
+  params {
+    target = "./..."
+    tags = "integration"
+  }
+  step test {
+    run = "go test -tags {{param.tags}} {{ param.target }}"
+    results = [success]
+  }
+  test:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"target": "./...", "tags": "integration"}, wf.Params)
+
+	wf.ResolveParams()
+	cmd, err := wf.Steps["test"].ExpandParams(wf.Steps["test"].Config["run"])
+	require.NoError(t, err)
+	assert.Equal(t, "go test -tags integration ./...", cmd)
+	assert.Empty(t, wf.ValidateConfig(), "resolved param keys are not unknown config keys")
+
+	require.NoError(t, wf.OverrideParams(map[string]string{"target": "./internal/..."}))
+	cmd, err = wf.Steps["test"].ExpandParams(wf.Steps["test"].Config["run"])
+	require.NoError(t, err)
+	assert.Equal(t, "go test -tags integration ./internal/...", cmd)
+
+	err = wf.OverrideParams(map[string]string{"tagret": "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no param "tagret"`)
+}
+
+func TestParser_ParamsUndeclaredReference(t *testing.T) {
+	input := `workflow ci {
+  params {
+    target = "./..."
+  }
+  step test {
+    run = "go test {{param.pkg}}"
+    results = [success]
+  }
+  test:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	err = wf.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `undeclared param "pkg"`)
+}
+
+func TestParser_Defaults(t *testing.T) {
+	input := `workflow develop {
+  defaults {
+    max_attempts = 3
+  }
+  step implement {
+    prompt = "Implement it."
+    results = [success, fail, give-up]
+  }
+  step fix {
+    prompt = "Fix it."
+    max_attempts = 5
+    results = [success, fail, give-up]
+  }
+  step test {
+    run = "go test ./..."
+    results = [success, fail]
+  }
+  implement:success -> test
+  test:fail -> fix
+  fix:success -> test
+  test:success -> done
+  implement:fail -> abort
+  implement:give-up -> abort
+  fix:fail -> abort
+  fix:give-up -> abort
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"max_attempts": "3"}, wf.Defaults)
+
+	wf.ResolveDefaults()
+	assert.Equal(t, "3", wf.Steps["implement"].Config["max_attempts"], "agent step inherits the default")
+	assert.Equal(t, "5", wf.Steps["fix"].Config["max_attempts"], "step-level value overrides the default")
+	assert.NotContains(t, wf.Steps["test"].Config, "max_attempts", "defaults apply to agent steps only")
+}
+
+func TestParser_DefaultsRejectsUnsupportedKey(t *testing.T) {
+	input := `workflow develop {
+  defaults {
+    prompt = "Do it."
+  }
+  step implement {
+    results = [success]
+  }
+  implement:success -> done
+}`
+
+	_, err := dsl.Parse(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"prompt" cannot be set in defaults`)
+}
+
+func TestParser_DefaultsRejectsTimeout(t *testing.T) {
+	input := `workflow develop {
+  defaults {
+    timeout = "10m"
+  }
+  step implement {
+    prompt = "Do it."
+    results = [success]
+  }
+  implement:success -> done
+}`
+
+	_, err := dsl.Parse(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"timeout" cannot be set in defaults`)
+}
+
+func TestParser_RejectsUnknownOutputFilter(t *testing.T) {
+	step := `workflow develop {
+  step implement {
+    prompt = "Do it."
+    output_filter = "clean"
+    results = [success]
+  }
+  implement:success -> done
+}`
+	_, err := dsl.Parse(step)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 4 col 5: output_filter must be "strip", "collapse" or "raw", got "clean"`)
+
+	defaults := `workflow develop {
+  defaults {
+    output_filter = "ansi"
+  }
+  step implement {
+    prompt = "Do it."
+    results = [success]
+  }
+  implement:success -> done
+}`
+	_, err = dsl.Parse(defaults)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 3 col 5: output_filter must be`)
+
+	diags := dsl.Diagnostics(step)
+	require.Len(t, diags, 1)
+	assert.Equal(t, dsl.SeverityError, diags[0].Severity)
+	assert.Contains(t, diags[0].Message, "output_filter must be")
+}
+
+func TestParser_FanoutKeepsDeclarationOrder(t *testing.T) {
+	input := `workflow ci {
+  step code {
+    prompt = "Write it."
+    results = [success]
+  }
+  step zeta {
+    run = "true"
+    results = [success]
+  }
+  step alpha {
+    run = "true"
+    results = [success]
+  }
+  code:success -> zeta
+  code:success -> alpha
+  zeta:success -> done
+  alpha:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	next, err := wf.NextSteps("code", "success")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zeta", "alpha"}, next)
+}
+
+func TestParser_ScriptStepInfersResults(t *testing.T) {
+	input := `workflow notify {
+  step build {
+    run = "make build"
+    results = [success, fail]
+  }
+
+  step announce {
+    run = "./notify.sh"
+  }
+
+  build:success -> announce
+  build:fail -> abort
+  announce:success -> done
+  announce:fail -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	require.NoError(t, wf.Validate())
+
+	announce := wf.Steps["announce"]
+	assert.True(t, announce.ResultsInferred)
+	assert.Equal(t, []string{"success", "fail", "timeout", "token-limit"}, announce.Results)
+
+	build := wf.Steps["build"]
+	assert.False(t, build.ResultsInferred, "declared results are not inferred")
+}
+
+func TestParser_InferredResultsRequireFailWire(t *testing.T) {
+	input := `workflow notify {
+  step announce {
+    run = "./notify.sh"
+  }
+
+  announce:success -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	err = wf.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `result "fail" is not wired`)
+}
+
+func TestParser_InferredResultsContinueOnError(t *testing.T) {
+	input := `workflow notify {
+  step announce {
+    run = "./notify.sh"
+    continue_on_error = true
+  }
+
+  step deploy {
+    run = "make deploy"
+  }
+
+  announce:success -> deploy
+  deploy:success -> done
+  deploy:fail -> abort
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+	require.NoError(t, wf.Validate())
+
+	var failWires []domain.Wire
+	for _, w := range wf.Wiring {
+		if w.From == "announce" && w.Result == "fail" {
+			failWires = append(failWires, w)
+		}
+	}
+	require.Len(t, failWires, 1)
+	assert.Equal(t, "deploy", failWires[0].To)
+	assert.True(t, failWires[0].Implicit)
+}
+
+func TestParser_InferredResultsKeepExplicitFailWire(t *testing.T) {
+	input := `workflow notify {
+  step announce {
+    run = "./notify.sh"
+    continue_on_error = true
+  }
+
+  announce:success -> done
+  announce:fail -> abort
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+
+	var failWires []domain.Wire
+	for _, w := range wf.Wiring {
+		if w.From == "announce" && w.Result == "fail" {
+			failWires = append(failWires, w)
+		}
+	}
+	require.Len(t, failWires, 1)
+	assert.Equal(t, domain.StepAbort, failWires[0].To)
+	assert.False(t, failWires[0].Implicit)
+}
+
+func TestParser_AgentStepResultsNotInferred(t *testing.T) {
+	input := `workflow review {
+  step review {
+    prompt = "Review the change"
+  }
+
+  review:timeout -> done
+}`
+
+	wf, err := dsl.Parse(input)
+	require.NoError(t, err)
+
+	review := wf.Steps["review"]
+	assert.False(t, review.ResultsInferred)
+	assert.Equal(t, []string{"timeout", "token-limit"}, review.Results)
+}
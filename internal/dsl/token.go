@@ -65,4 +65,10 @@ type Token struct {
 	Literal string
 	Line    int
 	Col     int
+	// Pos and End are the byte offsets of the token's first byte and one past
+	// its last byte in the source, so input[Pos:End] is the token's exact
+	// source text (quotes and escapes included for strings). Line and Col
+	// count runes; Pos and End count bytes.
+	Pos int
+	End int
 }
@@ -2,6 +2,9 @@ package engine
 
 import (
 	"context"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/cloche-dev/cloche/internal/domain"
 )
@@ -11,6 +14,8 @@ type contextKey int
 const (
 	stepTriggerKey contextKey = iota
 	workflowKey
+	decisionLogKey
+	stepClockKey
 )
 
 // StepTrigger carries information about the step and result that triggered
@@ -43,3 +48,105 @@ func WorkflowFromContext(ctx context.Context) (*domain.Workflow, bool) {
 	wf, ok := ctx.Value(workflowKey).(*domain.Workflow)
 	return wf, ok
 }
+
+// WithDecisionLog returns a new context carrying the engine's decision log.
+// The engine sets this before calling StepExecutor.Execute so that executors
+// running sub-workflows can pass it on to their own engine.
+func WithDecisionLog(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, decisionLogKey, w)
+}
+
+// DecisionLogFromContext retrieves the decision log from the context, if present.
+func DecisionLogFromContext(ctx context.Context) (io.Writer, bool) {
+	w, ok := ctx.Value(decisionLogKey).(io.Writer)
+	return w, ok
+}
+
+// stepClock is a step's context while the step has a timeout. It behaves
+// like a context.WithTimeout context, except that an executor can hold the
+// timeout while the step waits to start elsewhere.
+type stepClock struct {
+	context.Context // cancelled with context.DeadlineExceeded when time runs out
+	cancel          context.CancelCauseFunc
+
+	mu       sync.Mutex
+	timeout  time.Duration
+	timer    *time.Timer
+	deadline time.Time // zero while held
+}
+
+// withStepClock returns a context for a step that times out after timeout,
+// and a function that releases it.
+func withStepClock(parent context.Context, timeout time.Duration) (*stepClock, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	c := &stepClock{Context: ctx, cancel: cancel, timeout: timeout}
+	c.startLocked()
+	return c, func() {
+		c.mu.Lock()
+		c.timer.Stop()
+		c.mu.Unlock()
+		cancel(context.Canceled)
+	}
+}
+
+func (c *stepClock) startLocked() {
+	c.deadline = time.Now().Add(c.timeout)
+	c.timer = time.AfterFunc(c.timeout, func() { c.cancel(context.DeadlineExceeded) })
+}
+
+// Deadline reports when the step times out, or nothing while it is held.
+func (c *stepClock) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, !c.deadline.IsZero()
+}
+
+// Err reports context.DeadlineExceeded once the step has timed out.
+func (c *stepClock) Err() error {
+	if c.Context.Err() == nil {
+		return nil
+	}
+	return context.Cause(c.Context)
+}
+
+func (c *stepClock) Value(key any) any {
+	if key == stepClockKey {
+		return c
+	}
+	return c.Context.Value(key)
+}
+
+// hold stops the clock and returns a function that restarts it with the full
+// timeout. Nothing is held once the step has timed out or finished.
+func (c *stepClock) hold() (start func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deadline.IsZero() || !c.timer.Stop() {
+		return func() {}
+	}
+	c.deadline = time.Time{}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.Context.Err() == nil {
+				c.startLocked()
+			}
+		})
+	}
+}
+
+// HoldStepTimeout pauses the running step's timeout and returns a function
+// that starts it again from the full timeout. Executors that hand a step to
+// something that may queue it (such as the in-container agent) hold the
+// timeout until the step actually begins executing, so time spent waiting
+// does not count against it. Both are no-ops for steps without a timeout or
+// whose timeout has already run out.
+func HoldStepTimeout(ctx context.Context) (start func()) {
+	c, _ := ctx.Value(stepClockKey).(*stepClock)
+	if c == nil {
+		return func() {}
+	}
+	return c.hold()
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
-	"strconv"
 	"sync"
 	"time"
 
@@ -43,7 +43,10 @@ func (f StepExecutorFunc) Execute(ctx context.Context, step *domain.Step) (domai
 // StatusHandler receives notifications about workflow execution progress.
 type StatusHandler interface {
 	OnStepStart(run *domain.Run, step *domain.Step)
-	OnStepComplete(run *domain.Run, step *domain.Step, result string, usage *domain.TokenUsage)
+	// OnStepComplete is called when a step finishes with a declared result.
+	// sr.Result is the result the engine routes on, which may differ from
+	// the executor's (e.g. token-limit).
+	OnStepComplete(run *domain.Run, step *domain.Step, sr domain.StepResult)
 	// OnStepSkipped is called when a step's skip script exits 0, bypassing
 	// execution. wire is the result the skip script chose for routing.
 	OnStepSkipped(run *domain.Run, step *domain.Step, wire string)
@@ -52,10 +55,10 @@ type StatusHandler interface {
 
 type noopStatus struct{}
 
-func (noopStatus) OnStepStart(*domain.Run, *domain.Step)                               {}
-func (noopStatus) OnStepComplete(*domain.Run, *domain.Step, string, *domain.TokenUsage) {}
-func (noopStatus) OnStepSkipped(*domain.Run, *domain.Step, string)                     {}
-func (noopStatus) OnRunComplete(*domain.Run)                                            {}
+func (noopStatus) OnStepStart(*domain.Run, *domain.Step)                       {}
+func (noopStatus) OnStepComplete(*domain.Run, *domain.Step, domain.StepResult) {}
+func (noopStatus) OnStepSkipped(*domain.Run, *domain.Step, string)             {}
+func (noopStatus) OnRunComplete(*domain.Run)                                   {}
 
 type Engine struct {
 	executor         StepExecutor
@@ -64,6 +67,7 @@ type Engine struct {
 	defaultTimeout   time.Duration
 	preloadedResults map[string]string // step_name -> result for resume mode
 	startStep        string            // when non-empty, override wf.EntryStep
+	decisionLog      io.Writer         // when non-nil, receives one line per routing decision
 }
 
 func New(executor StepExecutor) *Engine {
@@ -102,11 +106,38 @@ func (e *Engine) SetPreloadedResults(results map[string]string) {
 	e.preloadedResults = results
 }
 
+// SetDecisionLog makes the engine write a line to w for every routing
+// decision it makes: which step launched and why, each result, the wires and
+// collects it fired, and how the run ended. Lines are prefixed with the
+// workflow name, since sub-workflow engines share the log of the run that
+// started them. Used to debug workflows that take an unexpected branch.
+func (e *Engine) SetDecisionLog(w io.Writer) {
+	if _, ok := w.(*syncWriter); !ok && w != nil {
+		w = &syncWriter{w: w}
+	}
+	e.decisionLog = w
+}
+
+// syncWriter serializes writes to a decision log shared by the engines of
+// concurrently running sub-workflows.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
 // stepResult is sent from worker goroutines back to the main event loop.
 type stepResult struct {
 	stepName string
 	result   string
 	usage    *domain.TokenUsage
+	exitCode *int
+	prompt   string
 	err      error
 	skipped  bool // true when the executor's skip script bypassed execution
 }
@@ -125,6 +156,9 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 	for _, w := range wf.ValidateConfig() {
 		log.Printf("WARNING: %s", w)
 	}
+	for _, w := range wf.ValidateLoops() {
+		log.Printf("WARNING: %s", w)
+	}
 
 	run := domain.NewRun(generateRunID(), wf.Name)
 	run.Start()
@@ -161,6 +195,18 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 	stepCount := 0
 	doneCount := 0
 	aborted := false
+	var abortReason string // why the run first aborted; becomes the run's error message
+	abort := func(reason string) {
+		if !aborted {
+			abortReason = reason
+		}
+		aborted = true
+	}
+	decide := func(format string, args ...any) {
+		if e.decisionLog != nil {
+			fmt.Fprintf(e.decisionLog, "%s [%s] %s\n", time.Now().Format(time.RFC3339), wf.Name, fmt.Sprintf(format, args...))
+		}
+	}
 	var runErr error
 	stepLaunchCounts := make(map[string]int)
 	var workflowOutputTokens int64
@@ -184,10 +230,11 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 		// Enforce max_attempts: when a step with max_attempts has been executed
 		// that many times already (skipped invocations don't count), synthesize
 		// "give-up" instead of executing again.
-		if maxAttemptsStr, hasMax := step.Config["max_attempts"]; hasMax {
-			if maxAttempts, parseErr := strconv.Atoi(maxAttemptsStr); parseErr == nil && maxAttempts > 0 {
+		if maxAttempts, hasMax, parseErr := step.Config.Int("max_attempts"); hasMax {
+			if parseErr == nil && maxAttempts > 0 {
 				if stepLaunchCounts[stepName] >= maxAttempts {
 					log.Printf("engine: step %q max_attempts (%d) exhausted, synthesizing give-up", step.Name, maxAttempts)
+					decide("%s: max_attempts (%d) exhausted, synthesizing give-up", step.Name, maxAttempts)
 					activeCount++
 					run.RecordStepStart(step.Name)
 					e.status.OnStepStart(run, step)
@@ -203,6 +250,7 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 
 		// token-limit = 0: short-circuit before calling the executor.
 		if stepTokenLimit(step, DefaultStepTokenLimit) == 0 {
+			decide("%s: token-limit is 0, synthesizing token-limit", step.Name)
 			activeCount++
 			run.RecordStepStart(step.Name)
 			e.status.OnStepStart(run, step)
@@ -212,6 +260,11 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 			return nil
 		}
 
+		if trigger.PrevStep == "" {
+			decide("launch %s (entry step)", step.Name)
+		} else {
+			decide("launch %s (after %s:%s)", step.Name, trigger.PrevStep, trigger.PrevResult)
+		}
 		activeCount++
 		run.RecordStepStart(step.Name)
 		e.status.OnStepStart(run, step)
@@ -220,6 +273,7 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 		// instead of executing. This allows completed steps to be
 		// skipped while preserving the wiring logic.
 		if preloadedResult, ok := e.preloadedResults[stepName]; ok {
+			decide("%s: replaying preloaded result %q", step.Name, preloadedResult)
 			go func(name, result string) {
 				results <- stepResult{stepName: name, result: result}
 			}(step.Name, preloadedResult)
@@ -229,14 +283,21 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 		go func(s *domain.Step, t StepTrigger, baseCtx context.Context) {
 			stepCtx := baseCtx
 			if d := stepTimeout(s, e.defaultTimeout); d > 0 {
-				var cancel context.CancelFunc
-				stepCtx, cancel = context.WithTimeout(baseCtx, d)
+				var cancel func()
+				stepCtx, cancel = withStepClock(baseCtx, d)
 				defer cancel()
+			} else {
+				// Keep a sub-workflow step without a timeout from holding
+				// its parent step's.
+				stepCtx = context.WithValue(baseCtx, stepClockKey, (*stepClock)(nil))
 			}
 			stepCtx = WithStepTrigger(stepCtx, t)
 			stepCtx = WithWorkflow(stepCtx, wf)
+			if e.decisionLog != nil {
+				stepCtx = WithDecisionLog(stepCtx, e.decisionLog)
+			}
 			sr, err := e.executor.Execute(stepCtx, s)
-			results <- stepResult{stepName: s.Name, result: sr.Result, usage: sr.Usage, err: err, skipped: sr.Skipped}
+			results <- stepResult{stepName: s.Name, result: sr.Result, usage: sr.Usage, exitCode: sr.ExitCode, prompt: sr.Prompt, err: err, skipped: sr.Skipped}
 		}(step, trigger, ctx)
 
 		return nil
@@ -279,11 +340,13 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 				// Skip path: validate the chosen wire, record as skipped, do not
 				// count this invocation against max_attempts.
 				if !isResultDeclared(step, sr.result) {
+					decide("%s returned undeclared result %q; failing run", sr.stepName, sr.result)
 					run.RecordStepComplete(sr.stepName, sr.result)
 					run.Complete(domain.RunStateFailed)
 					e.status.OnRunComplete(run)
 					return run, fmt.Errorf("step %q skip script returned undeclared wire %q", sr.stepName, sr.result)
 				}
+				decide("%s skipped -> %s", sr.stepName, sr.result)
 				run.RecordStepSkipped(sr.stepName, sr.result)
 				e.status.OnStepSkipped(run, step, sr.result)
 			} else {
@@ -302,7 +365,14 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 							run.RecordStepComplete(sr.stepName, "error")
 							continue
 						}
+					} else if result, ok := step.ContinueOnErrorResult(); ok {
+						// continue_on_error: report the configured result and
+						// follow its wiring instead of failing the run.
+						log.Printf("engine: step %q execution failed, continuing with %q: %v", sr.stepName, result, sr.err)
+						decide("%s: execution failed, continue_on_error reports %q: %v", sr.stepName, result, sr.err)
+						sr = stepResult{stepName: sr.stepName, result: result}
 					} else {
+						decide("%s: execution failed; failing run: %v", sr.stepName, sr.err)
 						run.RecordStepComplete(sr.stepName, "error")
 						run.Complete(domain.RunStateFailed)
 						e.status.OnRunComplete(run)
@@ -320,17 +390,19 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 
 				// Step-level token-limit enforcement: override result if output tokens exceeded.
 				if limit := stepTokenLimit(step, DefaultStepTokenLimit); limit != -1 && sr.usage != nil && sr.usage.OutputTokens > limit {
+					decide("%s: output tokens (%d) over token-limit %d, replacing %q with token-limit", sr.stepName, sr.usage.OutputTokens, limit, sr.result)
 					sr.result = "token-limit"
 				}
 
+				decide("%s -> %s", sr.stepName, sr.result)
 				run.RecordStepComplete(sr.stepName, sr.result)
-				e.status.OnStepComplete(run, step, sr.result, sr.usage)
+				e.status.OnStepComplete(run, step, domain.StepResult{Result: sr.result, Usage: sr.usage, ExitCode: sr.exitCode, Prompt: sr.prompt})
 
 				// Workflow-level token accumulation and enforcement.
 				if sr.usage != nil {
 					workflowOutputTokens += sr.usage.OutputTokens
 					if wfLimit := workflowTokenLimit(wf, DefaultWorkflowTokenLimit); wfLimit != -1 && workflowOutputTokens >= wfLimit {
-						aborted = true
+						abort(fmt.Sprintf("workflow output tokens (%d) reached token-limit %d", workflowOutputTokens, wfLimit))
 					}
 				}
 			}
@@ -341,19 +413,31 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 				// No wire found. Check if any collect handles this (step, result).
 				if !collectHandled[sr.stepName][sr.result] {
 					// Neither wires nor collects handle this result.
+					decide("%s:%s has no wire or collect; failing run", sr.stepName, sr.result)
 					run.Complete(domain.RunStateFailed)
 					e.status.OnRunComplete(run)
 					return run, wireErr
 				}
 				// Collect handles it; no wire targets to launch.
+				decide("%s:%s has no wire; left to collects", sr.stepName, sr.result)
 			} else {
 				// Process wire targets.
 				for _, target := range nextSteps {
+					decide("wire %s:%s -> %s fired", sr.stepName, sr.result, target)
 					switch target {
 					case domain.StepDone:
 						doneCount++
 					case domain.StepAbort:
-						aborted = true
+						abort(fmt.Sprintf("step %q result %q is wired to abort", sr.stepName, sr.result))
+						// abort(cleanup): run the cleanup step; the run still
+						// finalizes as failed once it drains.
+						if cleanup := wf.AbortCleanup(sr.stepName, sr.result); cleanup != "" {
+							if err := launchStep(cleanup, StepTrigger{PrevStep: sr.stepName, PrevResult: sr.result}); err != nil {
+								run.Complete(domain.RunStateFailed)
+								e.status.OnRunComplete(run)
+								return run, err
+							}
+						}
 					default:
 						if err := launchStep(target, StepTrigger{PrevStep: sr.stepName, PrevResult: sr.result}); err != nil {
 							run.Complete(domain.RunStateFailed)
@@ -369,9 +453,11 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 				if cs.fired {
 					continue
 				}
+				matched := false
 				for i, cond := range cs.collect.Conditions {
 					if cond.Step == sr.stepName && cond.Result == sr.result {
 						cs.satisfied[i] = true
+						matched = true
 					}
 				}
 
@@ -384,13 +470,14 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 				}
 
 				if shouldFire {
+					decide("%s fired on %s:%s", cs.collect, sr.stepName, sr.result)
 					cs.fired = true
 					target := cs.collect.To
 					switch target {
 					case domain.StepDone:
 						doneCount++
 					case domain.StepAbort:
-						aborted = true
+						abort(fmt.Sprintf("%s fired on %s:%s", cs.collect, sr.stepName, sr.result))
 					default:
 						if err := launchStep(target, StepTrigger{PrevStep: sr.stepName, PrevResult: sr.result}); err != nil {
 							run.Complete(domain.RunStateFailed)
@@ -398,6 +485,8 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 							return run, err
 						}
 					}
+				} else if matched {
+					decide("%s waiting: %d of %d conditions met", cs.collect, len(cs.satisfied), len(cs.collect.Conditions))
 				}
 			}
 		}
@@ -405,12 +494,13 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 
 	// Determine final state.
 	if cancelled {
+		decide("run cancelled")
 		run.Complete(domain.RunStateCancelled)
 		e.status.OnRunComplete(run)
 		return run, fmt.Errorf("workflow cancelled: %w", context.Canceled)
 	}
-	if aborted || runErr != nil {
-		run.Complete(domain.RunStateFailed)
+	if aborted {
+		run.Fail(abortReason)
 	} else if doneCount > 0 {
 		run.Complete(domain.RunStateSucceeded)
 	} else {
@@ -418,6 +508,13 @@ func (e *Engine) Run(ctx context.Context, wf *domain.Workflow) (*domain.Run, err
 		runErr = fmt.Errorf("workflow %q: no branches reached done", wf.Name)
 	}
 
+	if runErr != nil {
+		decide("run %s: %v", run.State, runErr)
+	} else if run.ErrorMessage != "" {
+		decide("run %s: %s", run.State, run.ErrorMessage)
+	} else {
+		decide("run %s", run.State)
+	}
 	e.status.OnRunComplete(run)
 	return run, runErr
 }
@@ -453,10 +550,8 @@ func isContextError(err error) bool {
 // first, then falls back to a type-specific default (human steps default to
 // domain.DefaultHumanStepTimeout), then the provided global default.
 func stepTimeout(step *domain.Step, defaultTimeout time.Duration) time.Duration {
-	if raw, ok := step.Config["timeout"]; ok {
-		if d, err := time.ParseDuration(raw); err == nil {
-			return d
-		}
+	if d, ok, err := step.Config.Duration("timeout"); ok && err == nil {
+		return d
 	}
 	if step.Type == domain.StepTypeHuman {
 		return HumanStepDefaultTimeout
@@ -467,10 +562,8 @@ func stepTimeout(step *domain.Step, defaultTimeout time.Duration) time.Duration
 // stepTokenLimit returns the output-token limit for a step. Returns the
 // configured value if present, otherwise defaultLimit. -1 means disabled.
 func stepTokenLimit(step *domain.Step, defaultLimit int64) int64 {
-	if raw, ok := step.Config["token-limit"]; ok {
-		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
-			return n
-		}
+	if n, ok, err := step.Config.Int("token-limit"); ok && err == nil {
+		return int64(n)
 	}
 	return defaultLimit
 }
@@ -478,10 +571,8 @@ func stepTokenLimit(step *domain.Step, defaultLimit int64) int64 {
 // workflowTokenLimit returns the cumulative output-token limit for a workflow.
 // Returns the configured value if present, otherwise defaultLimit. -1 means disabled.
 func workflowTokenLimit(wf *domain.Workflow, defaultLimit int64) int64 {
-	if raw, ok := wf.Config["token-limit"]; ok {
-		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
-			return n
-		}
+	if n, ok, err := wf.Config.Int("token-limit"); ok && err == nil {
+		return int64(n)
 	}
 	return defaultLimit
 }
@@ -1,31 +1,21 @@
 package engine_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/engine"
+	"github.com/cloche-dev/cloche/engine/enginetest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-type fakeExecutor struct {
-	mu      sync.Mutex
-	results map[string]string
-	called  []string
-}
-
-func (f *fakeExecutor) Execute(_ context.Context, step *domain.Step) (domain.StepResult, error) {
-	f.mu.Lock()
-	defer f.mu.Unlock()
-	f.called = append(f.called, step.Name)
-	return domain.StepResult{Result: f.results[step.Name]}, nil
-}
-
 func TestEngine_LinearWorkflow(t *testing.T) {
 	wf := &domain.Workflow{
 		Name: "linear",
@@ -40,13 +30,13 @@ func TestEngine_LinearWorkflow(t *testing.T) {
 		EntryStep: "build",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{"build": "success", "test": "pass"}}
+	exec := enginetest.NewExecutor(map[string]string{"build": "success", "test": "pass"})
 	eng := engine.New(exec)
 
 	run, err := eng.Run(context.Background(), wf)
 	require.NoError(t, err)
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
-	assert.Equal(t, []string{"build", "test"}, exec.called)
+	assert.Equal(t, []string{"build", "test"}, exec.Calls())
 }
 
 func TestEngine_RetryLoop(t *testing.T) {
@@ -83,6 +73,55 @@ func TestEngine_RetryLoop(t *testing.T) {
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
 }
 
+func TestEngine_DecisionLogRecordsRetryLoop(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "retry",
+		Steps: map[string]*domain.Step{
+			"code":  {Name: "code", Type: domain.StepTypeAgent, Results: []string{"success", "fail"}},
+			"check": {Name: "check", Type: domain.StepTypeScript, Results: []string{"pass", "fail"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "code", Result: "success", To: "check"},
+			{From: "code", Result: "fail", To: domain.StepAbort},
+			{From: "check", Result: "pass", To: domain.StepDone},
+			{From: "check", Result: "fail", To: "code"},
+		},
+		EntryStep: "code",
+	}
+
+	exec := enginetest.NewExecutor(map[string]string{"code": "success"})
+	exec.SetSequence("check", "fail", "pass")
+
+	var buf bytes.Buffer
+	eng := engine.New(exec)
+	eng.SetDecisionLog(&buf)
+	run, err := eng.Run(context.Background(), wf)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateSucceeded, run.State)
+
+	var decisions []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		_, decision, ok := strings.Cut(line, " ") // drop the timestamp
+		require.True(t, ok, line)
+		decisions = append(decisions, decision)
+	}
+	assert.Equal(t, []string{
+		"[retry] launch code (entry step)",
+		"[retry] code -> success",
+		"[retry] wire code:success -> check fired",
+		"[retry] launch check (after code:success)",
+		"[retry] check -> fail",
+		"[retry] wire check:fail -> code fired",
+		"[retry] launch code (after check:fail)",
+		"[retry] code -> success",
+		"[retry] wire code:success -> check fired",
+		"[retry] launch check (after code:success)",
+		"[retry] check -> pass",
+		"[retry] wire check:pass -> done fired",
+		"[retry] run succeeded",
+	}, decisions)
+}
+
 func TestEngine_Abort(t *testing.T) {
 	wf := &domain.Workflow{
 		Name: "abort-test",
@@ -96,12 +135,37 @@ func TestEngine_Abort(t *testing.T) {
 		EntryStep: "code",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{"code": "fail"}}
+	exec := enginetest.NewExecutor(map[string]string{"code": "fail"})
 	eng := engine.New(exec)
 
 	run, err := eng.Run(context.Background(), wf)
 	require.NoError(t, err)
 	assert.Equal(t, domain.RunStateFailed, run.State)
+	assert.Equal(t, `step "code" result "fail" is wired to abort`, run.ErrorMessage)
+}
+
+func TestEngine_AbortWithCleanup(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "abort-cleanup",
+		Steps: map[string]*domain.Step{
+			"code":    {Name: "code", Type: domain.StepTypeAgent, Results: []string{"success", "fail"}},
+			"cleanup": {Name: "cleanup", Type: domain.StepTypeScript, Results: []string{"success"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "code", Result: "success", To: domain.StepDone},
+			{From: "code", Result: "fail", To: domain.StepAbort, Cleanup: "cleanup"},
+			{From: "cleanup", Result: "success", To: domain.StepDone},
+		},
+		EntryStep: "code",
+	}
+
+	exec := enginetest.NewExecutor(map[string]string{"code": "fail", "cleanup": "success"})
+	eng := engine.New(exec)
+
+	run, err := eng.Run(context.Background(), wf)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateFailed, run.State, "cleanup reaching done must not rescue an aborted run")
+	assert.Equal(t, []string{"code", "cleanup"}, exec.Calls())
 }
 
 func TestEngine_ContextCancellation(t *testing.T) {
@@ -119,7 +183,7 @@ func TestEngine_ContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	exec := &fakeExecutor{results: map[string]string{"slow": "done"}}
+	exec := enginetest.NewExecutor(map[string]string{"slow": "done"})
 	eng := engine.New(exec)
 
 	run, err := eng.Run(ctx, wf)
@@ -144,20 +208,54 @@ func TestEngine_Fanout(t *testing.T) {
 		EntryStep: "code",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{
+	exec := enginetest.NewExecutor(map[string]string{
 		"code": "success", "test": "success", "lint": "success",
-	}}
+	})
 	eng := engine.New(exec)
 
 	run, err := eng.Run(context.Background(), wf)
 	require.NoError(t, err)
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
 	// All three steps must have been called
-	exec.mu.Lock()
-	assert.Contains(t, exec.called, "code")
-	assert.Contains(t, exec.called, "test")
-	assert.Contains(t, exec.called, "lint")
-	exec.mu.Unlock()
+	assert.Contains(t, exec.Calls(), "code")
+	assert.Contains(t, exec.Calls(), "test")
+	assert.Contains(t, exec.Calls(), "lint")
+}
+
+func TestEngine_FanoutLaunchesInDeclarationOrder(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "fanout-order",
+		Steps: map[string]*domain.Step{
+			"code":  {Name: "code", Type: domain.StepTypeAgent, Results: []string{"success"}},
+			"zeta":  {Name: "zeta", Type: domain.StepTypeScript, Results: []string{"success"}},
+			"alpha": {Name: "alpha", Type: domain.StepTypeScript, Results: []string{"success"}},
+			"mid":   {Name: "mid", Type: domain.StepTypeScript, Results: []string{"success"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "code", Result: "success", To: "zeta"},
+			{From: "code", Result: "success", To: "alpha"},
+			{From: "code", Result: "success", To: "mid"},
+			{From: "zeta", Result: "success", To: domain.StepDone},
+			{From: "alpha", Result: "success", To: domain.StepDone},
+			{From: "mid", Result: "success", To: domain.StepDone},
+		},
+		EntryStep: "code",
+	}
+
+	exec := enginetest.NewExecutor(map[string]string{
+		"code": "success", "zeta": "success", "alpha": "success", "mid": "success",
+	})
+	run, err := engine.New(exec).Run(context.Background(), wf)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateSucceeded, run.State)
+
+	// Branches execute concurrently, but they are started (and recorded) in
+	// the order their wires were declared.
+	var started []string
+	for _, se := range run.StepExecutions {
+		started = append(started, se.StepName)
+	}
+	assert.Equal(t, []string{"code", "zeta", "alpha", "mid"}, started)
 }
 
 func TestEngine_CollectAll(t *testing.T) {
@@ -187,17 +285,75 @@ func TestEngine_CollectAll(t *testing.T) {
 		EntryStep: "code",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{
+	exec := enginetest.NewExecutor(map[string]string{
 		"code": "success", "test": "success", "lint": "success", "merge": "success",
-	}}
+	})
 	eng := engine.New(exec)
 
 	run, err := eng.Run(context.Background(), wf)
 	require.NoError(t, err)
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
-	exec.mu.Lock()
-	assert.Contains(t, exec.called, "merge")
-	exec.mu.Unlock()
+	assert.Contains(t, exec.Calls(), "merge")
+}
+
+func TestEngine_CollectAbortRecordsReason(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "collect-abort",
+		Steps: map[string]*domain.Step{
+			"code":  {Name: "code", Type: domain.StepTypeAgent, Results: []string{"success"}},
+			"test":  {Name: "test", Type: domain.StepTypeScript, Results: []string{"success", "fail"}},
+			"lint":  {Name: "lint", Type: domain.StepTypeScript, Results: []string{"success", "fail"}},
+			"merge": {Name: "merge", Type: domain.StepTypeScript, Results: []string{"success"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "code", Result: "success", To: "test"},
+			{From: "code", Result: "success", To: "lint"},
+			{From: "merge", Result: "success", To: domain.StepDone},
+		},
+		Collects: []domain.Collect{
+			{
+				Mode: domain.CollectAll,
+				Conditions: []domain.WireCondition{
+					{Step: "test", Result: "success"},
+					{Step: "lint", Result: "success"},
+				},
+				To: "merge",
+			},
+			{
+				Mode: domain.CollectAll,
+				Conditions: []domain.WireCondition{
+					{Step: "test", Result: "fail"},
+					{Step: "lint", Result: "fail"},
+				},
+				To: domain.StepAbort,
+			},
+		},
+		EntryStep: "code",
+	}
+
+	// Serialize test before lint so the firing condition is deterministic.
+	testDone := make(chan struct{})
+	exec := engine.StepExecutorFunc(func(_ context.Context, step *domain.Step) (domain.StepResult, error) {
+		switch step.Name {
+		case "code":
+			return domain.StepResult{Result: "success"}, nil
+		case "test":
+			defer close(testDone)
+			return domain.StepResult{Result: "fail"}, nil
+		case "lint":
+			<-testDone
+			return domain.StepResult{Result: "fail"}, nil
+		}
+		return domain.StepResult{Result: "success"}, nil
+	})
+
+	run, err := engine.New(exec).Run(context.Background(), wf)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateFailed, run.State)
+	assert.Equal(t, "collect all(test:fail, lint:fail) -> abort fired on lint:fail", run.ErrorMessage)
+	for _, exec := range run.StepExecutions {
+		assert.NotEqual(t, "merge", exec.StepName, "the success collect must not fire")
+	}
 }
 
 func TestEngine_CollectAny(t *testing.T) {
@@ -229,17 +385,15 @@ func TestEngine_CollectAny(t *testing.T) {
 		EntryStep: "code",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{
+	exec := enginetest.NewExecutor(map[string]string{
 		"code": "success", "test": "success", "lint": "success", "quick": "success",
-	}}
+	})
 	eng := engine.New(exec)
 
 	run, err := eng.Run(context.Background(), wf)
 	require.NoError(t, err)
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
-	exec.mu.Lock()
-	assert.Contains(t, exec.called, "quick")
-	exec.mu.Unlock()
+	assert.Contains(t, exec.Calls(), "quick")
 }
 
 func TestEngine_UndeclaredResultAborts(t *testing.T) {
@@ -255,7 +409,7 @@ func TestEngine_UndeclaredResultAborts(t *testing.T) {
 		EntryStep: "code",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{"code": "unknown"}}
+	exec := enginetest.NewExecutor(map[string]string{"code": "unknown"})
 	eng := engine.New(exec)
 
 	run, err := eng.Run(context.Background(), wf)
@@ -340,6 +494,115 @@ func TestEngine_StepTimeoutOverridesDefault(t *testing.T) {
 	assert.Less(t, elapsed, 5*time.Second, "default timeout should fire quickly")
 }
 
+// queuedExecutor holds the step's timeout while the step waits in a queue for
+// queueFor, then runs it for runFor.
+type queuedExecutor struct {
+	queueFor, runFor time.Duration
+}
+
+func (q *queuedExecutor) Execute(ctx context.Context, step *domain.Step) (domain.StepResult, error) {
+	start := engine.HoldStepTimeout(ctx)
+	time.Sleep(q.queueFor)
+	start()
+	select {
+	case <-ctx.Done():
+		return domain.StepResult{}, fmt.Errorf("step %q timed out: %w", step.Name, ctx.Err())
+	case <-time.After(q.runFor):
+		return domain.StepResult{Result: "success"}, nil
+	}
+}
+
+func TestEngine_HeldStepTimeoutStartsWhenStepStarts(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "held-timeout",
+		Steps: map[string]*domain.Step{
+			"queued": {
+				Name:    "queued",
+				Type:    domain.StepTypeAgent,
+				Results: []string{"success", "fail"},
+				Config:  map[string]string{"prompt": "do stuff", "timeout": "200ms"},
+			},
+		},
+		Wiring: []domain.Wire{
+			{From: "queued", Result: "success", To: domain.StepDone},
+			{From: "queued", Result: "fail", To: domain.StepAbort},
+		},
+		EntryStep: "queued",
+	}
+
+	// Queued and running together take longer than the timeout, but the
+	// run alone does not.
+	run, err := engine.New(&queuedExecutor{queueFor: 300 * time.Millisecond, runFor: 100 * time.Millisecond}).Run(context.Background(), wf)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateSucceeded, run.State)
+
+	// Once started, the step still times out.
+	run, err = engine.New(&queuedExecutor{queueFor: 50 * time.Millisecond, runFor: 5 * time.Second}).Run(context.Background(), wf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Equal(t, domain.RunStateFailed, run.State)
+}
+
+func TestEngine_ContinueOnErrorFollowsFailWire(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "continue-on-error",
+		Steps: map[string]*domain.Step{
+			"lint": {Name: "lint", Type: domain.StepTypeScript, Results: []string{"success", "fail"},
+				Config: map[string]string{"continue_on_error": "true"}},
+			"build": {Name: "build", Type: domain.StepTypeScript, Results: []string{"success"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "lint", Result: "success", To: "build"},
+			{From: "lint", Result: "fail", To: "build"},
+			{From: "build", Result: "success", To: domain.StepDone},
+		},
+		EntryStep: "lint",
+	}
+
+	var mu sync.Mutex
+	var called []string
+	exec := engine.StepExecutorFunc(func(_ context.Context, step *domain.Step) (domain.StepResult, error) {
+		mu.Lock()
+		called = append(called, step.Name)
+		mu.Unlock()
+		if step.Name == "lint" {
+			return domain.StepResult{}, fmt.Errorf("linter crashed")
+		}
+		return domain.StepResult{Result: "success"}, nil
+	})
+
+	run, err := engine.New(exec).Run(context.Background(), wf)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateSucceeded, run.State)
+	assert.Equal(t, []string{"lint", "build"}, called)
+	require.NotEmpty(t, run.StepExecutions)
+	assert.Equal(t, "fail", run.StepExecutions[0].Result)
+}
+
+func TestEngine_ContinueOnErrorCustomResult(t *testing.T) {
+	wf := &domain.Workflow{
+		Name: "continue-on-error-result",
+		Steps: map[string]*domain.Step{
+			"lint": {Name: "lint", Type: domain.StepTypeScript, Results: []string{"success", "crashed"},
+				Config: map[string]string{"continue_on_error": "true", "error_result": "crashed"}},
+		},
+		Wiring: []domain.Wire{
+			{From: "lint", Result: "success", To: domain.StepDone},
+			{From: "lint", Result: "crashed", To: domain.StepAbort},
+		},
+		EntryStep: "lint",
+	}
+
+	exec := engine.StepExecutorFunc(func(context.Context, *domain.Step) (domain.StepResult, error) {
+		return domain.StepResult{}, fmt.Errorf("linter crashed")
+	})
+
+	run, err := engine.New(exec).Run(context.Background(), wf)
+	require.NoError(t, err, "the crash is routed through the crashed wire, not surfaced as an error")
+	assert.Equal(t, domain.RunStateFailed, run.State)
+	assert.Equal(t, "crashed", run.StepExecutions[0].Result)
+}
+
 func TestEngine_StepErrorIncludesStepName(t *testing.T) {
 	wf := &domain.Workflow{
 		Name: "error-info",
@@ -836,10 +1099,10 @@ type skippedStatusHandler struct {
 
 type noopStatus struct{}
 
-func (noopStatus) OnStepStart(*domain.Run, *domain.Step)                               {}
-func (noopStatus) OnStepComplete(*domain.Run, *domain.Step, string, *domain.TokenUsage) {}
-func (noopStatus) OnStepSkipped(*domain.Run, *domain.Step, string)                     {}
-func (noopStatus) OnRunComplete(*domain.Run)                                            {}
+func (noopStatus) OnStepStart(*domain.Run, *domain.Step)                       {}
+func (noopStatus) OnStepComplete(*domain.Run, *domain.Step, domain.StepResult) {}
+func (noopStatus) OnStepSkipped(*domain.Run, *domain.Step, string)             {}
+func (noopStatus) OnRunComplete(*domain.Run)                                   {}
 
 func (h *skippedStatusHandler) OnStepSkipped(_ *domain.Run, step *domain.Step, wire string) {
 	h.mu.Lock()
@@ -1008,9 +1271,9 @@ func TestEngine_SkipCustomWire(t *testing.T) {
 func TestEngine_SkipResumeReplaysWire(t *testing.T) {
 	// When a step was previously skipped and its result is preloaded for resume,
 	// the engine replays the wire without re-executing (preloaded path).
-	exec := &fakeExecutor{results: map[string]string{
+	exec := enginetest.NewExecutor(map[string]string{
 		"next": "success",
-	}}
+	})
 
 	wf := &domain.Workflow{
 		Name: "skip-resume",
@@ -1042,8 +1305,6 @@ func TestEngine_SkipResumeReplaysWire(t *testing.T) {
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
 
 	// "first" should not have been executed (preloaded); "next" should have
-	exec.mu.Lock()
-	assert.NotContains(t, exec.called, "first")
-	assert.Contains(t, exec.called, "next")
-	exec.mu.Unlock()
+	assert.NotContains(t, exec.Calls(), "first")
+	assert.Contains(t, exec.Calls(), "next")
 }
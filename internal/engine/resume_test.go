@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/cloche-dev/cloche/engine/enginetest"
 	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/engine"
 	"github.com/stretchr/testify/assert"
@@ -29,9 +30,9 @@ func TestEngine_ResumeFromStep(t *testing.T) {
 		EntryStep: "build",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{
+	exec := enginetest.NewExecutor(map[string]string{
 		"build": "success", "test": "pass", "deploy": "success",
-	}}
+	})
 	eng := engine.New(exec)
 
 	// Preload build as completed — test and deploy should actually execute
@@ -44,11 +45,9 @@ func TestEngine_ResumeFromStep(t *testing.T) {
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
 
 	// build should NOT have been called (preloaded)
-	exec.mu.Lock()
-	assert.NotContains(t, exec.called, "build")
-	assert.Contains(t, exec.called, "test")
-	assert.Contains(t, exec.called, "deploy")
-	exec.mu.Unlock()
+	assert.NotContains(t, exec.Calls(), "build")
+	assert.Contains(t, exec.Calls(), "test")
+	assert.Contains(t, exec.Calls(), "deploy")
 }
 
 func TestEngine_ResumeSkipsMultipleSteps(t *testing.T) {
@@ -71,9 +70,9 @@ func TestEngine_ResumeSkipsMultipleSteps(t *testing.T) {
 		EntryStep: "a",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{
+	exec := enginetest.NewExecutor(map[string]string{
 		"a": "ok", "b": "ok", "c": "ok", "d": "ok",
-	}}
+	})
 	eng := engine.New(exec)
 	eng.SetPreloadedResults(map[string]string{
 		"a": "ok",
@@ -84,12 +83,10 @@ func TestEngine_ResumeSkipsMultipleSteps(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
 
-	exec.mu.Lock()
-	assert.NotContains(t, exec.called, "a")
-	assert.NotContains(t, exec.called, "b")
-	assert.Contains(t, exec.called, "c")
-	assert.Contains(t, exec.called, "d")
-	exec.mu.Unlock()
+	assert.NotContains(t, exec.Calls(), "a")
+	assert.NotContains(t, exec.Calls(), "b")
+	assert.Contains(t, exec.Calls(), "c")
+	assert.Contains(t, exec.Calls(), "d")
 }
 
 func TestEngine_ResumeWithFanout(t *testing.T) {
@@ -111,9 +108,9 @@ func TestEngine_ResumeWithFanout(t *testing.T) {
 		EntryStep: "code",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{
+	exec := enginetest.NewExecutor(map[string]string{
 		"code": "success", "test": "pass", "lint": "pass",
-	}}
+	})
 	eng := engine.New(exec)
 	eng.SetPreloadedResults(map[string]string{
 		"code": "success",
@@ -123,11 +120,9 @@ func TestEngine_ResumeWithFanout(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
 
-	exec.mu.Lock()
-	assert.NotContains(t, exec.called, "code")
-	assert.Contains(t, exec.called, "test")
-	assert.Contains(t, exec.called, "lint")
-	exec.mu.Unlock()
+	assert.NotContains(t, exec.Calls(), "code")
+	assert.Contains(t, exec.Calls(), "test")
+	assert.Contains(t, exec.Calls(), "lint")
 }
 
 func TestEngine_ResumePreloadedStepsRecordedInRun(t *testing.T) {
@@ -145,9 +140,9 @@ func TestEngine_ResumePreloadedStepsRecordedInRun(t *testing.T) {
 		EntryStep: "build",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{
+	exec := enginetest.NewExecutor(map[string]string{
 		"build": "success", "test": "pass",
-	}}
+	})
 	eng := engine.New(exec)
 	eng.SetPreloadedResults(map[string]string{
 		"build": "success",
@@ -186,7 +181,7 @@ func TestEngine_ResumeEmptyPreloadedRunsNormally(t *testing.T) {
 		EntryStep: "build",
 	}
 
-	exec := &fakeExecutor{results: map[string]string{"build": "success"}}
+	exec := enginetest.NewExecutor(map[string]string{"build": "success"})
 	eng := engine.New(exec)
 	eng.SetPreloadedResults(map[string]string{})
 
@@ -194,7 +189,5 @@ func TestEngine_ResumeEmptyPreloadedRunsNormally(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, domain.RunStateSucceeded, run.State)
 
-	exec.mu.Lock()
-	assert.Contains(t, exec.called, "build")
-	exec.mu.Unlock()
+	assert.Contains(t, exec.Calls(), "build")
 }
@@ -18,7 +18,7 @@ type AuditLogger struct {
 
 // Log appends an EvolutionResult as a JSONL entry.
 func (a *AuditLogger) Log(result *EvolutionResult) error {
-	logPath := filepath.Join(a.ProjectDir, ".cloche", "evolution", "log.jsonl")
+	logPath := a.logPath()
 	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
 		return fmt.Errorf("creating evolution log dir: %w", err)
 	}
@@ -41,6 +41,51 @@ func (a *AuditLogger) Log(result *EvolutionResult) error {
 	return nil
 }
 
+// logPath returns the path of the evolution audit log.
+func (a *AuditLogger) logPath() string {
+	return filepath.Join(a.ProjectDir, ".cloche", "evolution", "log.jsonl")
+}
+
+// readLog returns every well-formed entry in the audit log, oldest first.
+func (a *AuditLogger) readLog() ([]*EvolutionResult, error) {
+	data, err := os.ReadFile(a.logPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading evolution log: %w", err)
+	}
+
+	var results []*EvolutionResult
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var r EvolutionResult
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			continue // skip malformed lines
+		}
+		results = append(results, &r)
+	}
+	return results, nil
+}
+
+// Find returns the logged evolution with the given ID, or nil if the audit
+// log has no such entry.
+func (a *AuditLogger) Find(id string) (*EvolutionResult, error) {
+	results, err := a.readLog()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
 // Snapshot copies a file to the snapshots directory and returns the snapshot filename.
 func (a *AuditLogger) Snapshot(relativePath string) (string, error) {
 	srcPath := filepath.Join(a.ProjectDir, relativePath)
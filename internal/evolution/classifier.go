@@ -3,6 +3,7 @@ package evolution
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 )
 
@@ -11,12 +12,27 @@ type Classifier struct {
 	LLM LLMClient
 }
 
-type classifyResponse struct {
-	Classification string `json:"classification"`
+// Classification is the classifier's verdict on a run prompt, with the
+// model's reasoning kept for the evolution audit trail.
+type Classification struct {
+	Category   string `json:"classification"`
+	Rationale  string `json:"rationale"`
+	Confidence string `json:"confidence"` // "high", "medium" or "low"
 }
 
+// defaultCategory is used whenever the model's answer cannot be trusted.
+const defaultCategory = "feature"
+
 // Classify categorizes a run prompt into: bug, feedback, feature, enhancement, chore.
 func (c *Classifier) Classify(ctx context.Context, runPrompt string) (string, error) {
+	result, err := c.ClassifyDetailed(ctx, runPrompt)
+	return result.Category, err
+}
+
+// ClassifyDetailed is like Classify but also returns the model's rationale
+// and confidence. Failed calls and unusable responses fall back to the
+// "feature" category with low confidence and a rationale saying why.
+func (c *Classifier) ClassifyDetailed(ctx context.Context, runPrompt string) (Classification, error) {
 	systemPrompt := `You are a classifier for software development tasks. Given a task description, classify it into exactly one category:
 
 - bug: fixing something broken, a defect, vulnerability, or regression
@@ -25,26 +41,36 @@ func (c *Classifier) Classify(ctx context.Context, runPrompt string) (string, er
 - enhancement: improving existing functionality
 - chore: maintenance tasks, dependency updates, CI changes
 
-Respond with JSON: {"classification": "<category>"}
+Respond with JSON: {"classification": "<category>", "rationale": "<one sentence explaining why>", "confidence": "high|medium|low"}
 Do not include any other text.`
 
 	response, err := c.LLM.Complete(ctx, systemPrompt, runPrompt)
 	if err != nil {
-		return "feature", nil // default on error
+		return fallbackClassification(fmt.Sprintf("classifier call failed: %v", err)), nil
 	}
 
-	var resp classifyResponse
+	var resp Classification
 	// Try to parse JSON from the response - it might have extra text
 	response = strings.TrimSpace(response)
 	if err := json.Unmarshal([]byte(response), &resp); err != nil {
-		return "feature", nil // default on parse error
+		return fallbackClassification("unparseable classifier response"), nil
 	}
 
 	// Validate the classification
-	switch resp.Classification {
+	switch resp.Category {
 	case "bug", "feedback", "feature", "enhancement", "chore":
-		return resp.Classification, nil
 	default:
-		return "feature", nil
+		return fallbackClassification(fmt.Sprintf("unrecognized classification %q", resp.Category)), nil
 	}
+	switch resp.Confidence {
+	case "high", "medium", "low":
+	default:
+		resp.Confidence = "low"
+	}
+	resp.Rationale = strings.TrimSpace(resp.Rationale)
+	return resp, nil
+}
+
+func fallbackClassification(rationale string) Classification {
+	return Classification{Category: defaultCategory, Rationale: rationale, Confidence: "low"}
 }
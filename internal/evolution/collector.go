@@ -14,6 +14,12 @@ import (
 type Collector struct {
 	ProjectDir   string
 	WorkflowName string
+	// States restricts collection to runs in these states (e.g. failed and
+	// cancelled for bug-focused passes). Empty collects every run.
+	States []domain.RunState
+	// SinceRunID, when set, collects runs started after this run instead of
+	// after the trigger run of the last evolution.
+	SinceRunID string
 }
 
 // Collect gathers runs, captures, knowledge base, prompts, and workflow.
@@ -23,6 +29,7 @@ func (c *Collector) Collect(ctx context.Context, evoStore ports.EvolutionStore,
 		ProjectDir:     c.ProjectDir,
 		WorkflowName:   c.WorkflowName,
 		Captures:       make(map[string][]*domain.StepExecution),
+		Diffs:          make(map[string]string),
 		CurrentPrompts: make(map[string]string),
 	}
 
@@ -50,20 +57,37 @@ func (c *Collector) Collect(ctx context.Context, evoStore ports.EvolutionStore,
 
 	// 4. Get runs from store (if available)
 	if evoStore != nil {
-		var sinceRunID string
-		if last, err := evoStore.GetLastEvolution(ctx, c.ProjectDir, c.WorkflowName); err == nil && last != nil {
-			sinceRunID = last.TriggerRunID
+		sinceRunID := c.SinceRunID
+		if sinceRunID == "" {
+			if last, err := evoStore.GetLastEvolution(ctx, c.ProjectDir, c.WorkflowName); err == nil && last != nil {
+				sinceRunID = last.TriggerRunID
+			}
+		}
+		var runs []*domain.Run
+		var err error
+		if len(c.States) > 0 {
+			runs, err = evoStore.ListRunsSinceInStates(ctx, c.ProjectDir, c.WorkflowName, sinceRunID, c.States)
+		} else {
+			runs, err = evoStore.ListRunsSince(ctx, c.ProjectDir, c.WorkflowName, sinceRunID)
 		}
-		runs, err := evoStore.ListRunsSince(ctx, c.ProjectDir, c.WorkflowName, sinceRunID)
 		if err != nil {
 			return nil, err
 		}
 		data.Runs = runs
+		for _, run := range runs {
+			if diff, err := os.ReadFile(c.runDiffPath(run)); err == nil && len(diff) > 0 {
+				data.Diffs[run.ID] = string(diff)
+			}
+		}
 
 		// 5. Get captures for each run
 		if capStore != nil {
 			for _, run := range runs {
-				caps, err := capStore.GetCaptures(ctx, run.ID)
+				var caps []*domain.StepExecution
+				err := capStore.GetCaptureStream(ctx, run.ID, func(c *domain.StepExecution) error {
+					caps = append(caps, c)
+					return nil
+				})
 				if err != nil {
 					continue
 				}
@@ -75,6 +99,15 @@ func (c *Collector) Collect(ctx context.Context, evoStore ports.EvolutionStore,
 	return data, nil
 }
 
+// runDiffPath returns where the daemon saves the diff of a run's extracted
+// changes: <run-id>.diff in the run's log directory.
+func (c *Collector) runDiffPath(run *domain.Run) string {
+	if run.TaskID != "" && run.AttemptID != "" {
+		return filepath.Join(c.ProjectDir, ".cloche", "logs", run.TaskID, run.AttemptID, run.ID+".diff")
+	}
+	return filepath.Join(c.ProjectDir, ".cloche", run.ID, "output", run.ID+".diff")
+}
+
 // extractPromptFiles finds file("path") references in workflow text.
 func extractPromptFiles(workflow string) []string {
 	re := regexp.MustCompile(`file\("([^"]+)"\)`)
@@ -2,11 +2,14 @@ package evolution
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/dsl"
@@ -62,6 +65,7 @@ type listRunsSinceCall struct {
 	projectDir   string
 	workflowName string
 	sinceRunID   string
+	states       []domain.RunState
 }
 
 func (m *mockEvolutionStore) SaveEvolution(ctx context.Context, entry *ports.EvolutionEntry) error {
@@ -77,10 +81,24 @@ func (m *mockEvolutionStore) GetLastEvolution(ctx context.Context, projectDir, w
 }
 
 func (m *mockEvolutionStore) ListRunsSince(ctx context.Context, projectDir, workflowName, sinceRunID string) ([]*domain.Run, error) {
-	m.listCalls = append(m.listCalls, listRunsSinceCall{projectDir, workflowName, sinceRunID})
+	m.listCalls = append(m.listCalls, listRunsSinceCall{projectDir, workflowName, sinceRunID, nil})
 	return m.runs, nil
 }
 
+func (m *mockEvolutionStore) ListRunsSinceInStates(ctx context.Context, projectDir, workflowName, sinceRunID string, states []domain.RunState) ([]*domain.Run, error) {
+	m.listCalls = append(m.listCalls, listRunsSinceCall{projectDir, workflowName, sinceRunID, states})
+	var out []*domain.Run
+	for _, r := range m.runs {
+		for _, st := range states {
+			if r.State == st {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
 type mockCaptureStore struct {
 	captures map[string][]*domain.StepExecution
 }
@@ -96,6 +114,19 @@ func (m *mockCaptureStore) GetCaptures(ctx context.Context, runID string) ([]*do
 	return nil, nil
 }
 
+func (m *mockCaptureStore) GetCaptureStream(ctx context.Context, runID string, fn func(*domain.StepExecution) error) error {
+	for _, c := range m.captures[runID] {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockCaptureStore) MaxCaptureSeq(ctx context.Context, runID string) (int64, error) {
+	return 0, nil
+}
+
 // ===========================================================================
 // Curator corruption scenarios
 // ===========================================================================
@@ -274,6 +305,70 @@ func TestCuratorCorruption_RaceConcurrentApply(t *testing.T) {
 	assert.Contains(t, string(content), "Updated by goroutine")
 }
 
+func TestCuratorContentLoss_LossyResponseRejected(t *testing.T) {
+	// The LLM returns a well-formed prompt that silently drops most of the
+	// existing rules. It passes the sanity check but must not be written.
+	original := "# Implementation Prompt\n\nWrite good code.\n\n## Guidelines\n\n- Follow best practices\n- Use proper error handling\n- Write tests\n\n## Learned Rules\n\n- Always validate inputs\n- Never trust user data\n"
+	dir, promptPath := setupCuratorDir(t, original)
+
+	lossy := "# Implementation Prompt\n\nWrite good code.\n\n## Learned Rules\n\n- Always validate inputs\n- Handle errors explicitly\n"
+	llm := &callTrackingLLM{responses: []string{lossy, lossy}}
+
+	audit := &AuditLogger{ProjectDir: dir}
+	c := &Curator{LLM: llm, Audit: audit}
+	lesson := &Lesson{
+		Target:          ".cloche/prompts/implement.md",
+		Insight:         "Missing error handling",
+		SuggestedAction: "Handle errors explicitly",
+	}
+
+	change, err := c.Apply(context.Background(), dir, lesson)
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	assert.Equal(t, "prompt_update_rollback", change.Type)
+	assert.Contains(t, change.Reason, "dropped existing content")
+
+	// One retry was attempted, with a reminder to keep existing content.
+	require.Len(t, llm.calls, 2)
+	assert.Contains(t, llm.calls[1].user, "dropped existing content")
+
+	// Nothing was written.
+	content, err := os.ReadFile(promptPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+}
+
+func TestCuratorContentLoss_RetryRecovers(t *testing.T) {
+	original := "# Prompt\n\n## Guidelines\n\n- Rule one\n- Rule two\n- Rule three\n"
+	dir, promptPath := setupCuratorDir(t, original)
+
+	lossy := "# Prompt\n\n- New rule\n"
+	complete := "# Prompt\n\n## Guidelines\n\n- Rule one\n- Rule two\n- Rule three\n\n## Learned Rules\n\n- New rule\n"
+	llm := &callTrackingLLM{responses: []string{lossy, complete}}
+
+	c := &Curator{LLM: llm, Audit: &AuditLogger{ProjectDir: dir}}
+	change, err := c.Apply(context.Background(), dir, &Lesson{
+		Target:          ".cloche/prompts/implement.md",
+		Insight:         "Insight",
+		SuggestedAction: "New rule",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	assert.Equal(t, "prompt_update", change.Type)
+
+	content, err := os.ReadFile(promptPath)
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(complete), string(content))
+}
+
+func TestPreservesContent(t *testing.T) {
+	original := "# Title\n\n## Rules\n\n- a\n- b\n- c\n"
+	assert.True(t, preservesContent(original, original+"- d\n"), "superset")
+	assert.True(t, preservesContent(original, "# Title\n\n## Rules\n\n- a\n- b (refined)\n- c\n"), "one rule refined in place")
+	assert.False(t, preservesContent(original, "# Title\n\n- a\n- b\n- c\n"), "heading dropped")
+	assert.False(t, preservesContent(original, "# Title\n\n## Rules\n\n- a\n"), "two rules dropped")
+}
+
 // ===========================================================================
 // Rollback sanity check tests
 // ===========================================================================
@@ -747,6 +842,24 @@ func TestCollectorListRunsSinceCalledWithCorrectRunID(t *testing.T) {
 	assert.Equal(t, "run-99", evoStore.listCalls[0].sinceRunID)
 }
 
+func TestCollectorExplicitSinceOverridesLastEvolution(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".cloche"), 0755)
+	os.WriteFile(filepath.Join(dir, ".cloche", "develop.cloche"),
+		[]byte(`workflow develop { step s { run = "echo hi" results = [success] } s:success -> done }`), 0644)
+
+	evoStore := &mockEvolutionStore{
+		lastEvolution: &ports.EvolutionEntry{ID: "evo-42", TriggerRunID: "run-99"},
+	}
+
+	c := &Collector{ProjectDir: dir, WorkflowName: "develop", SinceRunID: "run-10"}
+	_, err := c.Collect(context.Background(), evoStore, nil)
+	require.NoError(t, err)
+
+	require.Len(t, evoStore.listCalls, 1)
+	assert.Equal(t, "run-10", evoStore.listCalls[0].sinceRunID)
+}
+
 func TestCollectorListRunsSinceEmptyWhenNoLastEvolution(t *testing.T) {
 	dir := t.TempDir()
 	os.MkdirAll(filepath.Join(dir, ".cloche"), 0755)
@@ -767,6 +880,77 @@ func TestCollectorListRunsSinceEmptyWhenNoLastEvolution(t *testing.T) {
 	assert.Equal(t, "", evoStore.listCalls[0].sinceRunID)
 }
 
+func TestCollectorStatesFilterCollectsOnlyFailedRuns(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".cloche"), 0755)
+	os.WriteFile(filepath.Join(dir, ".cloche", "develop.cloche"),
+		[]byte(`workflow develop { step s { run = "echo hi" results = [success] } s:success -> done }`), 0644)
+
+	evoStore := &mockEvolutionStore{runs: []*domain.Run{
+		{ID: "run-1", WorkflowName: "develop", State: domain.RunStateSucceeded},
+		{ID: "run-2", WorkflowName: "develop", State: domain.RunStateFailed},
+		{ID: "run-3", WorkflowName: "develop", State: domain.RunStateCancelled},
+		{ID: "run-4", WorkflowName: "develop", State: domain.RunStateSucceeded},
+	}}
+
+	c := &Collector{
+		ProjectDir:   dir,
+		WorkflowName: "develop",
+		States:       []domain.RunState{domain.RunStateFailed, domain.RunStateCancelled},
+	}
+	data, err := c.Collect(context.Background(), evoStore, nil)
+	require.NoError(t, err)
+
+	require.Len(t, data.Runs, 2)
+	assert.Equal(t, "run-2", data.Runs[0].ID)
+	assert.Equal(t, "run-3", data.Runs[1].ID)
+	require.Len(t, evoStore.listCalls, 1)
+	assert.Equal(t, c.States, evoStore.listCalls[0].states)
+}
+
+func TestOrchestratorCollectStatesChosenByClassification(t *testing.T) {
+	dir := setupOrchestratorDir(t, `workflow develop {
+  step implement {
+    prompt = file(".cloche/prompts/implement.md")
+    results = [success, fail]
+  }
+  implement:success -> done
+  implement:fail -> abort
+}`, "# Prompt\n", "")
+
+	failedOnly := []domain.RunState{domain.RunStateFailed, domain.RunStateCancelled}
+	evoStore := &mockEvolutionStore{runs: []*domain.Run{
+		{ID: "run-1", WorkflowName: "develop", State: domain.RunStateSucceeded},
+		{ID: "run-2", WorkflowName: "develop", State: domain.RunStateFailed},
+	}}
+
+	orch := NewOrchestrator(OrchestratorConfig{
+		ProjectDir:    dir,
+		WorkflowName:  "develop",
+		LLM:           &scriptedLLM{responses: []string{`{"classification": "bug"}`, `{"lessons": []}`}},
+		MinConfidence: "medium",
+		CollectStates: map[string][]domain.RunState{"bug": failedOnly},
+	})
+	_, err := orch.Run(context.Background(), "run-2", evoStore, nil)
+	require.NoError(t, err)
+	require.Len(t, evoStore.listCalls, 1)
+	assert.Equal(t, failedOnly, evoStore.listCalls[0].states)
+
+	// A feature pass has no entry and no fallback, so every run is collected.
+	evoStore.listCalls = nil
+	orch = NewOrchestrator(OrchestratorConfig{
+		ProjectDir:    dir,
+		WorkflowName:  "develop",
+		LLM:           &scriptedLLM{responses: []string{`{"classification": "feature"}`, `{"lessons": []}`}},
+		MinConfidence: "medium",
+		CollectStates: map[string][]domain.RunState{"bug": failedOnly},
+	})
+	_, err = orch.Run(context.Background(), "run-2", evoStore, nil)
+	require.NoError(t, err)
+	require.Len(t, evoStore.listCalls, 1)
+	assert.Nil(t, evoStore.listCalls[0].states)
+}
+
 func TestCollectorWithCaptureStoreMock(t *testing.T) {
 	dir := t.TempDir()
 	os.MkdirAll(filepath.Join(dir, ".cloche"), 0755)
@@ -958,3 +1142,110 @@ func TestIntegration_FullCycleWithStores(t *testing.T) {
 	require.Len(t, evoStore.listCalls, 1)
 	assert.Equal(t, "run-99", evoStore.listCalls[0].sinceRunID)
 }
+
+// projectSharedLLM serves the classifier, reflector, and curator stages for
+// one workflow. Curator responses append a rule to whatever is on disk at call
+// time, so lost updates show up as a missing rule. Every call is recorded in a
+// shared trace so interleaving across workflows is observable.
+type projectSharedLLM struct {
+	workflow   string
+	promptPath string
+	rule       string
+	calls      int
+
+	traceMu *sync.Mutex
+	trace   *[]string
+}
+
+func (p *projectSharedLLM) Complete(ctx context.Context, system, user string) (string, error) {
+	p.traceMu.Lock()
+	*p.trace = append(*p.trace, p.workflow)
+	p.traceMu.Unlock()
+
+	// Widen the window for interleaving if passes were not serialized.
+	time.Sleep(20 * time.Millisecond)
+
+	p.calls++
+	switch p.calls {
+	case 1:
+		return `{"classification": "bug"}`, nil
+	case 2:
+		return fmt.Sprintf(`{"lessons": [{"id": "L-%s", "category": "prompt_improvement", "target": ".cloche/prompts/implement.md", "insight": "%s", "suggested_action": "%s", "evidence": ["run-1"], "confidence": "high"}]}`,
+			p.workflow, p.rule, p.rule), nil
+	default:
+		current, err := os.ReadFile(p.promptPath)
+		if err != nil {
+			return "", err
+		}
+		return appendLessonDirectly(string(current), &Lesson{Insight: p.rule, SuggestedAction: "apply"}), nil
+	}
+}
+
+func TestOrchestratorConcurrentWorkflowsSameProjectSerialize(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".cloche", "prompts"), 0755)
+	promptPath := filepath.Join(dir, ".cloche", "prompts", "implement.md")
+	os.WriteFile(promptPath, []byte("# Prompt\n\nWrite good code.\n"), 0644)
+	for _, name := range []string{"develop", "review"} {
+		os.WriteFile(filepath.Join(dir, ".cloche", name+".cloche"), []byte(fmt.Sprintf(`workflow %s {
+  step implement {
+    prompt = file(".cloche/prompts/implement.md")
+    results = [success, fail]
+  }
+  implement:success -> done
+  implement:fail -> abort
+}`, name)), 0644)
+	}
+
+	var traceMu sync.Mutex
+	var trace []string
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"develop", "review"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			orch := NewOrchestrator(OrchestratorConfig{
+				ProjectDir:   dir,
+				WorkflowName: name,
+				LLM: &projectSharedLLM{
+					workflow:   name,
+					promptPath: promptPath,
+					rule:       "Rule from " + name,
+					traceMu:    &traceMu,
+					trace:      &trace,
+				},
+				MinConfidence: "medium",
+			})
+			_, err := orch.Run(context.Background(), "run-"+name, nil, nil)
+			assert.NoError(t, err)
+		}(name)
+	}
+	wg.Wait()
+
+	// Each pass makes three LLM calls; serialized passes never interleave.
+	require.Len(t, trace, 6)
+	switches := 0
+	for i := 1; i < len(trace); i++ {
+		if trace[i] != trace[i-1] {
+			switches++
+		}
+	}
+	assert.Equal(t, 1, switches, "evolution passes interleaved: %v", trace)
+
+	// Neither pass overwrote the other's prompt update.
+	content, err := os.ReadFile(promptPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Rule from develop")
+	assert.Contains(t, string(content), "Rule from review")
+
+	// The audit log holds one well-formed line per pass.
+	logData, err := os.ReadFile(filepath.Join(dir, ".cloche", "evolution", "log.jsonl"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(logData)), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var entry EvolutionResult
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry), "malformed audit line: %s", line)
+	}
+}
@@ -31,11 +31,16 @@ func lessonAlreadyPresent(currentPrompt string, lesson *Lesson) bool {
 // ErrSanityCheckFailed indicates the curated content failed the prompt sanity check.
 var ErrSanityCheckFailed = fmt.Errorf("curation output failed sanity check")
 
+// ErrContentLoss indicates the curator dropped existing prompt content, even
+// after a retry.
+var ErrContentLoss = fmt.Errorf("curation output dropped existing content")
+
 // Curate performs core curation logic: reads the current prompt, calls the LLM to
 // merge the lesson, and returns the updated prompt content as a string. All corruption
 // safeguards (conversational guard, code-fence stripping, sanity check) are applied.
 // Returns ("", nil) when the lesson is already present and no update is needed.
-// Returns ("", ErrSanityCheckFailed) when the curated output fails the sanity check.
+// Returns ("", ErrSanityCheckFailed) when the curated output fails the sanity check,
+// and ("", ErrContentLoss) when it drops existing content twice in a row.
 func (c *Curator) Curate(ctx context.Context, projectDir string, lesson *Lesson) (string, error) {
 	targetPath := filepath.Join(projectDir, lesson.Target)
 	current, err := os.ReadFile(targetPath)
@@ -62,6 +67,31 @@ Rules:
 	userPrompt := fmt.Sprintf("## Current Prompt Content\n```\n%s\n```\n\n## Lesson to Merge\nInsight: %s\nSuggested Action: %s",
 		string(current), lesson.Insight, lesson.SuggestedAction)
 
+	updated, err := c.generate(ctx, systemPrompt, userPrompt, string(current), lesson)
+	if err != nil {
+		return "", err
+	}
+
+	// Guard against truncation: the curator must keep the existing rules.
+	// Retry once with an explicit reminder before giving up on the change.
+	if !preservesContent(string(current), updated) {
+		retryPrompt := userPrompt + "\n\n## Warning\nYour previous response dropped existing content. " +
+			"Return the COMPLETE current prompt with the lesson merged in; do not remove or summarize any existing lines."
+		updated, err = c.generate(ctx, systemPrompt, retryPrompt, string(current), lesson)
+		if err != nil {
+			return "", err
+		}
+		if !preservesContent(string(current), updated) {
+			return "", ErrContentLoss
+		}
+	}
+
+	return updated, nil
+}
+
+// generate runs one curator LLM call and applies the corruption safeguards
+// (code-fence stripping, conversational guard, sanity check).
+func (c *Curator) generate(ctx context.Context, systemPrompt, userPrompt, current string, lesson *Lesson) (string, error) {
 	raw, err := c.LLM.Complete(ctx, systemPrompt, userPrompt)
 	if err != nil {
 		return "", fmt.Errorf("curator LLM call: %w", err)
@@ -72,7 +102,7 @@ Rules:
 	// Validate that the LLM output is actual prompt content, not meta-conversation
 	if isConversationalResponse(updated) {
 		// Fallback: append the lesson directly rather than trusting the LLM
-		updated = appendLessonDirectly(string(current), lesson)
+		updated = appendLessonDirectly(current, lesson)
 	}
 
 	// Sanity check the curated content
@@ -83,19 +113,59 @@ Rules:
 	return updated, nil
 }
 
+// preservesContent reports whether updated still carries the original
+// prompt. Every markdown heading must survive, and at most one other
+// non-blank line (or 10% of them, whichever is larger) may be missing, which
+// leaves room for the curator to refine a rule in place without letting it
+// silently truncate the document.
+func preservesContent(original, updated string) bool {
+	present := make(map[string]bool)
+	for _, line := range strings.Split(updated, "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+
+	var lines, missing int
+	for _, line := range strings.Split(original, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if !present[line] {
+				return false
+			}
+			continue
+		}
+		lines++
+		if !present[line] {
+			missing++
+		}
+	}
+
+	allowed := lines / 10
+	if allowed < 1 {
+		allowed = 1
+	}
+	return missing <= allowed
+}
+
 // Apply curates a lesson into the target prompt file.
 func (c *Curator) Apply(ctx context.Context, projectDir string, lesson *Lesson) (*Change, error) {
 	updated, err := c.Curate(ctx, projectDir, lesson)
-	if err == ErrSanityCheckFailed {
-		// Snapshot before rollback reporting
+	if err == ErrSanityCheckFailed || err == ErrContentLoss {
+		// Snapshot before rollback reporting; nothing is written to the target.
 		var snapName string
 		if c.Audit != nil {
 			snapName, _ = c.Audit.Snapshot(lesson.Target)
 		}
+		why := "written content failed sanity check"
+		if err == ErrContentLoss {
+			why = "curator dropped existing content"
+		}
 		return &Change{
 			Type:     "prompt_update_rollback",
 			File:     lesson.Target,
-			Reason:   fmt.Sprintf("curation rolled back: written content failed sanity check (lesson: %s)", lesson.Insight),
+			Reason:   fmt.Sprintf("curation rolled back: %s (lesson: %s)", why, lesson.Insight),
 			Snapshot: snapName,
 		}, nil
 	}
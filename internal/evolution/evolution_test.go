@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/dsl"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,6 +56,22 @@ func TestCollectorGathersData(t *testing.T) {
 	assert.Equal(t, "develop", data.WorkflowName)
 }
 
+func TestCollectorReadsRunDiffs(t *testing.T) {
+	dir := t.TempDir()
+	logDir := filepath.Join(dir, ".cloche", "logs", "task-1", "a1")
+	require.NoError(t, os.MkdirAll(logDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(logDir, "a1-develop.diff"), []byte("+added line\n"), 0644))
+
+	store := &mockEvolutionStore{runs: []*domain.Run{
+		{ID: "a1-develop", WorkflowName: "develop", TaskID: "task-1", AttemptID: "a1"},
+		{ID: "a2-develop", WorkflowName: "develop", TaskID: "task-1", AttemptID: "a2"},
+	}}
+	c := &Collector{ProjectDir: dir, WorkflowName: "develop"}
+	data, err := c.Collect(context.Background(), store, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a1-develop": "+added line\n"}, data.Diffs)
+}
+
 func TestCollectorNoKnowledgeBase(t *testing.T) {
 	dir := t.TempDir()
 	os.MkdirAll(filepath.Join(dir, ".cloche"), 0755)
@@ -106,6 +123,49 @@ func TestClassifierCategorizesRun(t *testing.T) {
 	}
 }
 
+func TestClassifierCapturesRationale(t *testing.T) {
+	llm := &fakeLLM{response: `{"classification": "bug", "rationale": "The task fixes a crash on empty input.", "confidence": "high"}`}
+	c := &Classifier{LLM: llm}
+
+	got, err := c.ClassifyDetailed(context.Background(), "fix crash")
+	require.NoError(t, err)
+	assert.Equal(t, Classification{
+		Category:   "bug",
+		Rationale:  "The task fixes a crash on empty input.",
+		Confidence: "high",
+	}, got)
+}
+
+func TestClassifierFallbacksExplainThemselves(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		rationale string
+	}{
+		{"malformed JSON", `Sure! This looks like a bug.`, "unparseable classifier response"},
+		{"unknown category", `{"classification": "refactor", "rationale": "cleanup", "confidence": "high"}`, `unrecognized classification "refactor"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Classifier{LLM: &fakeLLM{response: tt.response}}
+			got, err := c.ClassifyDetailed(context.Background(), "some prompt")
+			require.NoError(t, err)
+			assert.Equal(t, "feature", got.Category)
+			assert.Equal(t, tt.rationale, got.Rationale)
+			assert.Equal(t, "low", got.Confidence)
+		})
+	}
+}
+
+func TestClassifierNormalizesMissingConfidence(t *testing.T) {
+	c := &Classifier{LLM: &fakeLLM{response: `{"classification": "chore", "rationale": "bumps a dependency"}`}}
+	got, err := c.ClassifyDetailed(context.Background(), "bump deps")
+	require.NoError(t, err)
+	assert.Equal(t, "chore", got.Category)
+	assert.Equal(t, "bumps a dependency", got.Rationale)
+	assert.Equal(t, "low", got.Confidence)
+}
+
 // --- Reflector tests ---
 
 func TestReflectorExtractsLessons(t *testing.T) {
@@ -134,6 +194,83 @@ func TestReflectorExtractsLessons(t *testing.T) {
 	assert.Equal(t, "high", lessons[0].Confidence)
 }
 
+func TestReflectorCapsStepLogs(t *testing.T) {
+	data := &CollectedData{
+		Runs: []*domain.Run{{ID: "run-1", WorkflowName: "develop", State: domain.RunStateFailed}},
+		Captures: map[string][]*domain.StepExecution{
+			"run-1": {{StepName: "test", Result: "fail", Logs: strings.Repeat("L", 2000)}},
+		},
+	}
+
+	t.Run("configured", func(t *testing.T) {
+		llm := &callTrackingLLM{responses: []string{`{"lessons": []}`}}
+		r := &Reflector{LLM: llm, MinConfidence: "medium", LogChars: 40}
+		_, err := r.Reflect(context.Background(), data, "bug")
+		require.NoError(t, err)
+		require.Len(t, llm.calls, 1)
+		assert.Contains(t, llm.calls[0].user, "Logs: "+strings.Repeat("L", 40)+domain.TruncatedSuffix)
+		assert.NotContains(t, llm.calls[0].user, strings.Repeat("L", 41))
+	})
+
+	t.Run("zero disables the cap", func(t *testing.T) {
+		llm := &callTrackingLLM{responses: []string{`{"lessons": []}`}}
+		r := &Reflector{LLM: llm, MinConfidence: "medium", LogChars: 0}
+		_, err := r.Reflect(context.Background(), data, "bug")
+		require.NoError(t, err)
+		require.Len(t, llm.calls, 1)
+		assert.Contains(t, llm.calls[0].user, "Logs: "+strings.Repeat("L", 2000))
+		assert.NotContains(t, llm.calls[0].user, domain.TruncatedSuffix)
+	})
+}
+
+func TestReflectorIncludesRunDiffs(t *testing.T) {
+	data := &CollectedData{
+		Runs: []*domain.Run{
+			{ID: "a1-develop", WorkflowName: "develop", State: domain.RunStateFailed},
+			{ID: "a2-develop", WorkflowName: "develop", State: domain.RunStateSucceeded},
+		},
+		Diffs: map[string]string{
+			"a1-develop": "+\tdata, _ := os.ReadFile(path)\n",
+			"a2-develop": strings.Repeat("D", 500),
+		},
+	}
+
+	llm := &callTrackingLLM{responses: []string{`{"lessons": []}`}}
+	r := &Reflector{LLM: llm, MinConfidence: "medium", DiffChars: 100}
+	_, err := r.Reflect(context.Background(), data, "bug")
+	require.NoError(t, err)
+	require.Len(t, llm.calls, 1)
+	user := llm.calls[0].user
+	assert.Contains(t, user, "- Changes made:\n```diff\n+\tdata, _ := os.ReadFile(path)\n")
+	assert.Contains(t, user, strings.Repeat("D", 100)+domain.TruncatedSuffix)
+	assert.NotContains(t, user, strings.Repeat("D", 101))
+
+	// Without DiffChars, diffs stay out of the prompt.
+	llm = &callTrackingLLM{responses: []string{`{"lessons": []}`}}
+	r = &Reflector{LLM: llm, MinConfidence: "medium"}
+	_, err = r.Reflect(context.Background(), data, "bug")
+	require.NoError(t, err)
+	assert.NotContains(t, llm.calls[0].user, "Changes made")
+}
+
+func TestReflectorDiffBudgetFavorsNewestRuns(t *testing.T) {
+	data := &CollectedData{Diffs: map[string]string{}}
+	for i := 0; i < reflectDiffBudgetRuns+2; i++ {
+		id := fmt.Sprintf("run-%02d", i)
+		data.Runs = append(data.Runs, &domain.Run{ID: id, WorkflowName: "develop"})
+		data.Diffs[id] = strings.Repeat("x", 50) + id
+	}
+
+	llm := &callTrackingLLM{responses: []string{`{"lessons": []}`}}
+	r := &Reflector{LLM: llm, MinConfidence: "medium", DiffChars: 20}
+	_, err := r.Reflect(context.Background(), data, "bug")
+	require.NoError(t, err)
+	user := llm.calls[0].user
+	assert.Equal(t, reflectDiffBudgetRuns, strings.Count(user, "Changes made"))
+	assert.NotContains(t, user, "### Run run-00 (workflow: develop, state: )\n- Changes made")
+	assert.Contains(t, user, "### Run run-11 (workflow: develop, state: )\n- Changes made")
+}
+
 func TestReflectorFiltersLowConfidence(t *testing.T) {
 	lessonsJSON, _ := json.Marshal(map[string]any{
 		"lessons": []map[string]any{
@@ -151,6 +288,67 @@ func TestReflectorFiltersLowConfidence(t *testing.T) {
 	assert.Equal(t, "L2", lessons[0].ID)
 }
 
+func TestReflectorVerifyEvidenceFiltersFabricatedEvidence(t *testing.T) {
+	lessonsJSON, _ := json.Marshal(map[string]any{
+		"lessons": []map[string]any{
+			// All evidence fabricated — dropped.
+			{"id": "L1", "category": "prompt_improvement", "confidence": "high",
+				"evidence": []string{"run-x", "run-y", "run-z", "run-w"}},
+			// Claims high with 4 runs, but only 2 exist — downgraded to medium.
+			{"id": "L2", "category": "prompt_improvement", "confidence": "high",
+				"evidence": []string{"run-1", "run-2", "run-fake-1", "run-fake-2"}},
+			// Claims high with 4 real runs — kept as high.
+			{"id": "L3", "category": "prompt_improvement", "confidence": "high",
+				"evidence": []string{"run-1", "run-2", "run-3", "run-4"}},
+			// Duplicated evidence counts once — one real run is only low.
+			{"id": "L4", "category": "prompt_improvement", "confidence": "medium",
+				"evidence": []string{"run-1", "run-1", "run-1"}},
+		},
+	})
+
+	data := &CollectedData{Runs: []*domain.Run{
+		{ID: "run-1"}, {ID: "run-2"}, {ID: "run-3"}, {ID: "run-4"},
+	}}
+
+	r := &Reflector{LLM: &fakeLLM{response: string(lessonsJSON)}, MinConfidence: "low", VerifyEvidence: true}
+	lessons, err := r.Reflect(context.Background(), data, "bug")
+	require.NoError(t, err)
+	require.Len(t, lessons, 3)
+
+	assert.Equal(t, "L2", lessons[0].ID)
+	assert.Equal(t, "medium", lessons[0].Confidence)
+	assert.Equal(t, []string{"run-1", "run-2"}, lessons[0].Evidence)
+
+	assert.Equal(t, "L3", lessons[1].ID)
+	assert.Equal(t, "high", lessons[1].Confidence)
+
+	assert.Equal(t, "L4", lessons[2].ID)
+	assert.Equal(t, "low", lessons[2].Confidence)
+	assert.Equal(t, []string{"run-1"}, lessons[2].Evidence)
+
+	// With a medium threshold the downgraded L4 no longer qualifies.
+	r.MinConfidence = "medium"
+	lessons, err = r.Reflect(context.Background(), data, "bug")
+	require.NoError(t, err)
+	require.Len(t, lessons, 2)
+	assert.Equal(t, "L2", lessons[0].ID)
+	assert.Equal(t, "L3", lessons[1].ID)
+}
+
+func TestReflectorTrustsEvidenceWhenVerificationOff(t *testing.T) {
+	lessonsJSON, _ := json.Marshal(map[string]any{
+		"lessons": []map[string]any{
+			{"id": "L1", "category": "prompt_improvement", "confidence": "high", "evidence": []string{"run-x"}},
+		},
+	})
+
+	r := &Reflector{LLM: &fakeLLM{response: string(lessonsJSON)}, MinConfidence: "medium"}
+	lessons, err := r.Reflect(context.Background(), &CollectedData{}, "bug")
+	require.NoError(t, err)
+	require.Len(t, lessons, 1)
+	assert.Equal(t, "high", lessons[0].Confidence)
+}
+
 func TestConfidenceLevel(t *testing.T) {
 	assert.Equal(t, 3, confidenceLevel("high"))
 	assert.Equal(t, 2, confidenceLevel("medium"))
@@ -769,6 +967,13 @@ func TestOrchestratorNewStepWiredIntoGraph(t *testing.T) {
 	require.Len(t, result.Changes, 2) // add_script + add_step
 	assert.Equal(t, "add_script", result.Changes[0].Type)
 	assert.Equal(t, "add_step", result.Changes[1].Type)
+	assert.Equal(t, []string{
+		"unwired test:success -> done",
+		"added step security-scan",
+		"wired test:success -> security-scan",
+		"wired security-scan:success -> done",
+		"wired security-scan:fail -> abort",
+	}, result.Changes[1].Summary)
 
 	// Read the updated workflow and verify the new step is reachable
 	wfContent, err := os.ReadFile(filepath.Join(dir, ".cloche", "develop.cloche"))
@@ -1196,3 +1401,79 @@ func TestOrchestratorSecondRunProducesNoChanges(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, result2.Changes, "second run should produce zero changes")
 }
+
+func TestLoggingLLMClientWritesPromptAndResponse(t *testing.T) {
+	dir := t.TempDir()
+	client := &LoggingLLMClient{LLM: &fakeLLM{response: "the answer"}, ProjectDir: dir}
+
+	resp, err := client.Complete(context.Background(), "system text", "user text")
+	require.NoError(t, err)
+	assert.Equal(t, "the answer", resp)
+
+	paths := client.Drain()
+	require.Len(t, paths, 1)
+	assert.True(t, strings.HasPrefix(paths[0], filepath.Join(".cloche", "evolution", "llm")+string(filepath.Separator)))
+
+	data, err := os.ReadFile(filepath.Join(dir, paths[0]))
+	require.NoError(t, err)
+	var rec LLMCallRecord
+	require.NoError(t, json.Unmarshal(data, &rec))
+	assert.Equal(t, "system text", rec.SystemPrompt)
+	assert.Equal(t, "user text", rec.UserPrompt)
+	assert.Equal(t, "the answer", rec.Response)
+	assert.Empty(t, rec.Error)
+
+	assert.Empty(t, client.Drain(), "Drain should reset the written list")
+}
+
+func TestOrchestratorLogLLMReferencedFromAuditLog(t *testing.T) {
+	dir := setupOrchestratorDir(t, `workflow develop {
+  step implement {
+    prompt = file(".cloche/prompts/implement.md")
+    results = [success, fail]
+  }
+  implement:success -> done
+  implement:fail -> abort
+}`, "# Prompt\n", "")
+
+	orch := NewOrchestrator(OrchestratorConfig{
+		ProjectDir:    dir,
+		WorkflowName:  "develop",
+		LLM:           &scriptedLLM{responses: []string{`{"classification": "bug"}`, `{"lessons": []}`}},
+		MinConfidence: "medium",
+		LogLLM:        true,
+	})
+	result, err := orch.Run(context.Background(), "run-1", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, result.LLMLogs, 2)
+
+	logged, err := orch.audit.Find(result.ID)
+	require.NoError(t, err)
+	require.NotNil(t, logged)
+	assert.Equal(t, result.LLMLogs, logged.LLMLogs)
+	for _, p := range logged.LLMLogs {
+		assert.FileExists(t, filepath.Join(dir, p))
+	}
+}
+
+func TestOrchestratorLogLLMOffByDefault(t *testing.T) {
+	dir := setupOrchestratorDir(t, `workflow develop {
+  step implement {
+    prompt = file(".cloche/prompts/implement.md")
+    results = [success, fail]
+  }
+  implement:success -> done
+  implement:fail -> abort
+}`, "# Prompt\n", "")
+
+	orch := NewOrchestrator(OrchestratorConfig{
+		ProjectDir:    dir,
+		WorkflowName:  "develop",
+		LLM:           &scriptedLLM{responses: []string{`{"classification": "bug"}`, `{"lessons": []}`}},
+		MinConfidence: "medium",
+	})
+	result, err := orch.Run(context.Background(), "run-1", nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.LLMLogs)
+	assert.NoDirExists(t, filepath.Join(dir, ".cloche", "evolution", "llm"))
+}
@@ -0,0 +1,83 @@
+package evolution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LLMCallRecord is the on-disk form of one logged LLM call.
+type LLMCallRecord struct {
+	Timestamp    string `json:"timestamp"`
+	SystemPrompt string `json:"system_prompt"`
+	UserPrompt   string `json:"user_prompt"`
+	Response     string `json:"response"`
+	Error        string `json:"error,omitempty"`
+}
+
+// LoggingLLMClient wraps an LLMClient and writes every Complete call to
+// .cloche/evolution/llm/<timestamp>.json so bad evolution changes can be
+// traced back to the prompt and response that produced them.
+type LoggingLLMClient struct {
+	LLM        LLMClient
+	ProjectDir string
+
+	mu      sync.Mutex
+	written []string // project-relative paths written since the last Drain
+}
+
+// Complete forwards the call to the wrapped client and logs it. Logging
+// failures never fail the call itself.
+func (l *LoggingLLMClient) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	response, err := l.LLM.Complete(ctx, systemPrompt, userPrompt)
+
+	now := time.Now()
+	rec := LLMCallRecord{
+		Timestamp:    now.Format(time.RFC3339Nano),
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+		Response:     response,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if rel, logErr := l.write(now, &rec); logErr == nil {
+		l.mu.Lock()
+		l.written = append(l.written, rel)
+		l.mu.Unlock()
+	}
+
+	return response, err
+}
+
+// Drain returns the project-relative paths of the log files written since
+// the previous call and resets the list.
+func (l *LoggingLLMClient) Drain() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	paths := l.written
+	l.written = nil
+	return paths
+}
+
+// write stores rec under a timestamped name and returns its project-relative path.
+func (l *LoggingLLMClient) write(now time.Time, rec *LLMCallRecord) (string, error) {
+	rel := filepath.Join(".cloche", "evolution", "llm", now.Format("20060102T150405.000000000")+".json")
+	path := filepath.Join(l.ProjectDir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("creating llm log dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshaling llm call: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("writing llm log: %w", err)
+	}
+	return rel, nil
+}
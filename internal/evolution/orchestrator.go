@@ -6,8 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/dsl"
 	"github.com/cloche-dev/cloche/internal/ports"
 )
@@ -19,6 +21,26 @@ type OrchestratorConfig struct {
 	LLM              LLMClient
 	MinConfidence    string
 	MaxPromptBullets int
+	// CollectStates maps a run classification (bug, feature, ...) to the run
+	// states the collector should include for that pass. The "" key is the
+	// fallback for classifications without an entry. A nil map, or no
+	// matching entry, collects runs in every state.
+	CollectStates map[string][]domain.RunState
+	// LogLLM records every LLM call of a pass under .cloche/evolution/llm/
+	// and lists the files in the pass's audit log entry.
+	LogLLM bool
+	// VerifyEvidence checks lesson evidence against the collected runs
+	// instead of trusting the reflector's self-reported confidence.
+	VerifyEvidence bool
+	// ReflectLogChars caps each step's log excerpt in the reflection prompt
+	// (see Reflector.LogChars).
+	ReflectLogChars int
+	// ReflectDiffChars caps each run's diff in the reflection prompt (see
+	// Reflector.DiffChars).
+	ReflectDiffChars int
+	// SinceRunID overrides the collection window start (see
+	// Collector.SinceRunID). Used by on-demand passes to reconsider a range.
+	SinceRunID string
 }
 
 // Orchestrator wires all evolution pipeline stages together.
@@ -31,16 +53,23 @@ type Orchestrator struct {
 	scriptGen  *ScriptGenerator
 	mutator    *dsl.Mutator
 	audit      *AuditLogger
+	llmLog     *LoggingLLMClient // nil unless LogLLM is set
 }
 
 // NewOrchestrator creates a fully wired evolution pipeline.
 func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 	audit := &AuditLogger{ProjectDir: cfg.ProjectDir, MaxPromptBullets: cfg.MaxPromptBullets}
+	var llmLog *LoggingLLMClient
+	if cfg.LogLLM {
+		llmLog = &LoggingLLMClient{LLM: cfg.LLM, ProjectDir: cfg.ProjectDir}
+		cfg.LLM = llmLog
+	}
 	return &Orchestrator{
 		cfg:        cfg,
-		collector:  &Collector{ProjectDir: cfg.ProjectDir, WorkflowName: cfg.WorkflowName},
+		llmLog:     llmLog,
+		collector:  &Collector{ProjectDir: cfg.ProjectDir, WorkflowName: cfg.WorkflowName, SinceRunID: cfg.SinceRunID},
 		classifier: &Classifier{LLM: cfg.LLM},
-		reflector:  &Reflector{LLM: cfg.LLM, MinConfidence: cfg.MinConfidence},
+		reflector:  &Reflector{LLM: cfg.LLM, MinConfidence: cfg.MinConfidence, VerifyEvidence: cfg.VerifyEvidence, LogChars: cfg.ReflectLogChars, DiffChars: cfg.ReflectDiffChars},
 		curator:    &Curator{LLM: cfg.LLM, Audit: audit},
 		scriptGen:  &ScriptGenerator{LLM: cfg.LLM},
 		mutator:    &dsl.Mutator{},
@@ -48,18 +77,48 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 	}
 }
 
-// Run executes the full evolution pipeline.
+// projectLocks holds one mutex per project directory. Evolution passes for
+// different workflows in the same project share prompt files, the knowledge
+// directory, and the audit log, so they must not run concurrently.
+var projectLocks sync.Map // cleaned projectDir -> *sync.Mutex
+
+// lockProject blocks until the evolution lock for projectDir is held and
+// returns the function that releases it.
+func lockProject(projectDir string) func() {
+	v, _ := projectLocks.LoadOrStore(filepath.Clean(projectDir), &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Run executes the full evolution pipeline. Passes for the same project are
+// serialized; a concurrent call waits for the in-flight pass to finish. An
+// empty triggerRunID (an on-demand pass) records the newest collected run as
+// the trigger, so the next automatic pass starts after it.
 func (o *Orchestrator) Run(ctx context.Context, triggerRunID string, evoStore ports.EvolutionStore, capStore ports.CaptureStore) (*EvolutionResult, error) {
-	// Stage 1: Collect
+	unlock := lockProject(o.cfg.ProjectDir)
+	defer unlock()
+
+	// Discard call logs left over from a pass that ended in an error.
+	o.drainLLMLogs()
+
+	// Stage 1: Classify the triggering run. Classification happens before
+	// collection so it can narrow which runs are collected.
+	verdict, err := o.classifier.ClassifyDetailed(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("classifier: %w", err)
+	}
+	classification := verdict.Category
+
+	// Stage 2: Collect
+	o.collector.States = o.collectStates(classification)
 	data, err := o.collector.Collect(ctx, evoStore, capStore)
 	if err != nil {
 		return nil, fmt.Errorf("collector: %w", err)
 	}
 
-	// Stage 2: Classify the triggering run
-	classification, err := o.classifier.Classify(ctx, "")
-	if err != nil {
-		return nil, fmt.Errorf("classifier: %w", err)
+	if triggerRunID == "" && len(data.Runs) > 0 {
+		triggerRunID = data.Runs[len(data.Runs)-1].ID
 	}
 
 	// Stage 3: Reflect
@@ -82,16 +141,21 @@ func (o *Orchestrator) Run(ctx context.Context, triggerRunID string, evoStore po
 	}
 
 	result := &EvolutionResult{
-		ID:             fmt.Sprintf("evo-%d", time.Now().UnixNano()),
-		ProjectDir:     o.cfg.ProjectDir,
-		WorkflowName:   o.cfg.WorkflowName,
-		TriggerRunID:   triggerRunID,
-		Timestamp:      time.Now().Format(time.RFC3339),
-		Classification: classification,
+		ID:                       fmt.Sprintf("evo-%d", time.Now().UnixNano()),
+		ProjectDir:               o.cfg.ProjectDir,
+		WorkflowName:             o.cfg.WorkflowName,
+		TriggerRunID:             triggerRunID,
+		Timestamp:                time.Now().Format(time.RFC3339),
+		Classification:           classification,
+		ClassificationRationale:  verdict.Rationale,
+		ClassificationConfidence: verdict.Confidence,
+		RunsCollected:            len(data.Runs),
+		Lessons:                  lessons,
 	}
 
 	if len(lessons) == 0 {
 		// No actionable lessons — log and return
+		result.LLMLogs = o.drainLLMLogs()
 		o.audit.Log(result)
 		return result, nil
 	}
@@ -119,25 +183,45 @@ func (o *Orchestrator) Run(ctx context.Context, triggerRunID string, evoStore po
 	// Stage 5: Audit
 	o.audit.UpdateKnowledge(o.cfg.WorkflowName, lessons)
 	result.KnowledgeDelta = fmt.Sprintf("%d lessons applied", len(lessons))
+	result.LLMLogs = o.drainLLMLogs()
 	o.audit.Log(result)
 
 	// Save to store if available
 	if evoStore != nil {
 		evoStore.SaveEvolution(ctx, &ports.EvolutionEntry{
-			ID:             result.ID,
-			ProjectDir:     result.ProjectDir,
-			WorkflowName:   result.WorkflowName,
-			TriggerRunID:   result.TriggerRunID,
-			CreatedAt:      time.Now(),
-			Classification: result.Classification,
-			ChangesJSON:    fmt.Sprintf("%d changes", len(result.Changes)),
-			KnowledgeDelta: result.KnowledgeDelta,
+			ID:                       result.ID,
+			ProjectDir:               result.ProjectDir,
+			WorkflowName:             result.WorkflowName,
+			TriggerRunID:             result.TriggerRunID,
+			CreatedAt:                time.Now(),
+			Classification:           result.Classification,
+			ClassificationRationale:  result.ClassificationRationale,
+			ClassificationConfidence: result.ClassificationConfidence,
+			ChangesJSON:              fmt.Sprintf("%d changes", len(result.Changes)),
+			KnowledgeDelta:           result.KnowledgeDelta,
 		})
 	}
 
 	return result, nil
 }
 
+// drainLLMLogs returns the LLM call logs written during this pass, if
+// logging is enabled.
+func (o *Orchestrator) drainLLMLogs() []string {
+	if o.llmLog == nil {
+		return nil
+	}
+	return o.llmLog.Drain()
+}
+
+// collectStates returns the run states to collect for a classification.
+func (o *Orchestrator) collectStates(classification string) []domain.RunState {
+	if states, ok := o.cfg.CollectStates[classification]; ok {
+		return states
+	}
+	return o.cfg.CollectStates[""]
+}
+
 // handleNewStep generates a script/prompt file and adds the step + wiring to the workflow.
 func (o *Orchestrator) handleNewStep(ctx context.Context, data *CollectedData, lesson *Lesson, result *EvolutionResult) error {
 	// Generate the script or prompt file
@@ -237,12 +321,16 @@ func (o *Orchestrator) handleNewStep(ctx context.Context, data *CollectedData, l
 		return fmt.Errorf("writing updated workflow: %w", err)
 	}
 
-	result.Changes = append(result.Changes, Change{
+	change := Change{
 		Type:     "add_step",
 		File:     wfRelPath,
 		Reason:   lesson.Insight,
 		Snapshot: snapName,
-	})
+	}
+	if existingWf != nil {
+		change.Summary = domain.DiffWorkflows(existingWf, finalWf).Summary()
+	}
+	result.Changes = append(result.Changes, change)
 
 	return nil
 }
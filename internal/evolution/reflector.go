@@ -5,14 +5,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/cloche-dev/cloche/internal/domain"
 )
 
 // Reflector examines execution traces and extracts structured lessons.
 type Reflector struct {
 	LLM           LLMClient
 	MinConfidence string // "low", "medium", "high"
+	// VerifyEvidence stops trusting the LLM's self-reported evidence and
+	// confidence: evidence run IDs not present in the collected runs are
+	// discarded, and each lesson's confidence is capped at the tier its real
+	// evidence count supports (see evidenceTier). Lessons left with no real
+	// evidence are dropped.
+	VerifyEvidence bool
+	// LogChars caps each step's log excerpt in the reflection prompt. Zero
+	// or less disables the cap.
+	LogChars int
+	// DiffChars caps each run's diff of changes in the reflection prompt.
+	// Zero or less leaves diffs out. All diffs together are held to
+	// reflectDiffBudgetRuns times DiffChars, spent on the newest runs first.
+	DiffChars int
 }
 
+// reflectDiffBudgetRuns is how many full-size diffs fit in the prompt's
+// total diff budget.
+const reflectDiffBudgetRuns = 10
+
 type reflectResponse struct {
 	Lessons []Lesson `json:"lessons"`
 }
@@ -67,18 +86,25 @@ Do not include any other text.`
 	}
 
 	if len(data.Runs) > 0 {
+		diffs := r.diffExcerpts(data)
 		parts = append(parts, "## Run History")
 		for _, run := range data.Runs {
 			runInfo := fmt.Sprintf("### Run %s (workflow: %s, state: %s)", run.ID, run.WorkflowName, run.State)
 			if caps, ok := data.Captures[run.ID]; ok {
 				for _, cap := range caps {
 					stepInfo := fmt.Sprintf("- Step %s: result=%s", cap.StepName, cap.Result)
+					if cap.InvalidResult {
+						stepInfo += " (undeclared result)"
+					}
 					if cap.Logs != "" {
-						stepInfo += "\n  Logs: " + truncate(cap.Logs, 500)
+						stepInfo += "\n  Logs: " + domain.TruncateOutput(cap.Logs, r.LogChars)
 					}
 					runInfo += "\n" + stepInfo
 				}
 			}
+			if diff, ok := diffs[run.ID]; ok {
+				runInfo += "\n- Changes made:\n```diff\n" + diff + "\n```"
+			}
 			parts = append(parts, runInfo)
 		}
 	}
@@ -96,10 +122,15 @@ Do not include any other text.`
 		return nil, fmt.Errorf("parsing reflector response: %w", err)
 	}
 
+	lessons := resp.Lessons
+	if r.VerifyEvidence {
+		lessons = verifyEvidence(lessons, data)
+	}
+
 	// Filter by minimum confidence
 	minLevel := confidenceLevel(r.MinConfidence)
 	var filtered []Lesson
-	for _, l := range resp.Lessons {
+	for _, l := range lessons {
 		if confidenceLevel(l.Confidence) >= minLevel {
 			filtered = append(filtered, l)
 		}
@@ -121,9 +152,72 @@ Do not include any other text.`
 	return filtered, nil
 }
 
-func truncate(s string, max int) string {
-	if len(s) <= max {
-		return s
+// evidenceTier returns the highest confidence a lesson can claim with n
+// distinct evidence runs, mirroring the tiers described to the LLM:
+// high needs 4+, medium 2-3, low 1.
+func evidenceTier(n int) string {
+	switch {
+	case n >= 4:
+		return "high"
+	case n >= 2:
+		return "medium"
+	case n == 1:
+		return "low"
+	default:
+		return ""
+	}
+}
+
+// verifyEvidence keeps only evidence run IDs that exist in the collected
+// data, downgrades confidence claims the remaining evidence cannot support,
+// and drops lessons with no real evidence at all.
+func verifyEvidence(lessons []Lesson, data *CollectedData) []Lesson {
+	known := make(map[string]bool, len(data.Runs))
+	for _, run := range data.Runs {
+		known[run.ID] = true
+	}
+
+	var verified []Lesson
+	for _, l := range lessons {
+		seen := make(map[string]bool, len(l.Evidence))
+		var real []string
+		for _, id := range l.Evidence {
+			if known[id] && !seen[id] {
+				seen[id] = true
+				real = append(real, id)
+			}
+		}
+		tier := evidenceTier(len(real))
+		if tier == "" {
+			continue
+		}
+		l.Evidence = real
+		if confidenceLevel(l.Confidence) > confidenceLevel(tier) {
+			l.Confidence = tier
+		}
+		verified = append(verified, l)
+	}
+	return verified
+}
+
+// diffExcerpts returns the truncated diff to show for each run, keeping the
+// total within the diff budget. Newer runs are served first because they
+// reflect the current prompts.
+func (r *Reflector) diffExcerpts(data *CollectedData) map[string]string {
+	if r.DiffChars <= 0 || len(data.Diffs) == 0 {
+		return nil
+	}
+	budget := r.DiffChars * reflectDiffBudgetRuns
+	excerpts := make(map[string]string)
+	for i := len(data.Runs) - 1; i >= 0 && budget > 0; i-- {
+		id := data.Runs[i].ID
+		diff, ok := data.Diffs[id]
+		if !ok {
+			continue
+		}
+		limit := min(r.DiffChars, budget)
+		excerpts[id] = domain.TruncateOutput(diff, limit)
+		budget -= min(len(diff), limit)
 	}
-	return s[:max] + "..."
+	return excerpts
 }
@@ -1,11 +1,14 @@
 package evolution
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -38,6 +41,67 @@ func validateScriptPath(p string) error {
 	return fmt.Errorf("path must start with scripts/ or .cloche/scripts/")
 }
 
+// unsafeScriptPatterns are commands a generated checker has no business
+// running. A checker only reads the project and reports; anything that wipes
+// the filesystem or ships data off the machine is refused outright.
+var unsafeScriptPatterns = []struct {
+	re     *regexp.Regexp
+	reason string
+}{
+	{regexp.MustCompile(`\brm\s+(-[a-zA-Z]*\s+|--[a-z-]+\s+)*(/|/\*|~/?|\$\{?HOME\}?/?)(\s|;|&|\||$)`), "removes the root or home directory"},
+	{regexp.MustCompile(`--no-preserve-root`), "removes the root directory"},
+	{regexp.MustCompile(`\bmkfs(\.\w+)?\b|\bdd\b[^\n]*\bof=/dev/`), "overwrites a device"},
+	{regexp.MustCompile(`:\(\)\s*\{[^}]*:\s*\|\s*:`), "is a fork bomb"},
+	{regexp.MustCompile(`\b(curl|wget)\b[^\n|]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`), "pipes a download into a shell"},
+	{regexp.MustCompile(`\bcurl\b[^\n]*\s(-d|--data[a-z-]*|-F|--form|-T|--upload-file)\b`), "uploads data over the network"},
+	{regexp.MustCompile(`\bwget\b[^\n]*\s--post-(data|file)\b`), "uploads data over the network"},
+	{regexp.MustCompile(`(^|[;&|(]\s*|\bsudo\s+)(nc|ncat|netcat|socat)\s`), "opens a raw network connection"},
+	{regexp.MustCompile(`/dev/(tcp|udp)/`), "opens a raw network connection"},
+}
+
+// validateScriptContent refuses a generated script that lacks a shebang,
+// matches one of unsafeScriptPatterns, or fails a shell syntax check.
+func validateScriptContent(content string) error {
+	if !strings.HasPrefix(content, "#!") {
+		return fmt.Errorf("script content must start with a shebang line (e.g. #!/bin/bash)")
+	}
+	for _, line := range strings.Split(content, "\n") {
+		code := strings.TrimSpace(line)
+		if strings.HasPrefix(code, "#") {
+			continue
+		}
+		for _, p := range unsafeScriptPatterns {
+			if p.re.MatchString(code) {
+				return fmt.Errorf("script %s: %q", p.reason, code)
+			}
+		}
+	}
+	return checkScriptSyntax(content)
+}
+
+// checkScriptSyntax parses the script with "sh -n" (or "bash -n" for a bash
+// shebang) without running it. The check is skipped when no such shell is
+// installed.
+func checkScriptSyntax(content string) error {
+	shell := "sh"
+	firstLine, _, _ := strings.Cut(content, "\n")
+	if strings.Contains(firstLine, "bash") {
+		shell = "bash"
+	}
+	path, err := exec.LookPath(shell)
+	if err != nil {
+		return nil
+	}
+	cmd := exec.Command(path, "-n")
+	cmd.Stdin = strings.NewReader(content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("script has a syntax error: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
 // Generate creates a script file based on the lesson.
 func (g *ScriptGenerator) Generate(ctx context.Context, projectDir string, lesson *Lesson) (*GeneratedScript, error) {
 	systemPrompt := `You are a script generator for software validation workflows.
@@ -75,9 +139,9 @@ Do not include any other text.`
 		return nil, fmt.Errorf("invalid script path %q: %w", resp.Path, err)
 	}
 
-	// Validate content starts with a shebang line
-	if !strings.HasPrefix(resp.Content, "#!") {
-		return nil, fmt.Errorf("script content must start with a shebang line (e.g. #!/bin/bash)")
+	// Refuse unsafe or unparseable content before anything touches disk
+	if err := validateScriptContent(resp.Content); err != nil {
+		return nil, err
 	}
 
 	// Resolve full path and verify it stays within projectDir
@@ -1,21 +1,51 @@
 package evolution
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"time"
 )
 
+// DebounceScope selects how Fire events are grouped into debounce windows.
+type DebounceScope string
+
+const (
+	// DebounceScopeWorkflow debounces each project+workflow pair independently.
+	DebounceScopeWorkflow DebounceScope = "workflow"
+	// DebounceScopeProject shares one debounce window across every workflow in
+	// a project, so evolution passes touching the same knowledge base never
+	// overlap. When the window closes, each workflow that fired runs in turn.
+	DebounceScopeProject DebounceScope = "project"
+)
+
 // TriggerConfig configures the evolution trigger.
 type TriggerConfig struct {
 	DebounceSeconds int
+	DebounceScope   DebounceScope // defaults to DebounceScopeWorkflow
 	RunFunc         func(projectDir, workflowName, runID string)
+	// EvolveFunc runs an on-demand pass (see Trigger.Evolve). A non-empty
+	// sinceRunID overrides the collection window. Optional.
+	EvolveFunc func(ctx context.Context, projectDir, workflowName, sinceRunID string) (*EvolutionResult, error)
+}
+
+// ErrEvolveUnsupported is returned by Trigger.Evolve when no EvolveFunc is
+// configured.
+var ErrEvolveUnsupported = errors.New("on-demand evolution is not configured")
+
+// pendingRun is a workflow waiting for its debounce window to close.
+type pendingRun struct {
+	workflowName string
+	runID        string
 }
 
-// Trigger debounces evolution pipeline runs per project+workflow key.
+// Trigger debounces evolution pipeline runs per key. The key is
+// project+workflow or just the project, depending on DebounceScope.
 type Trigger struct {
-	cfg    TriggerConfig
-	mu     sync.Mutex
-	timers map[string]*time.Timer
+	cfg     TriggerConfig
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	pending map[string][]pendingRun
 }
 
 // NewTrigger creates a new evolution trigger.
@@ -23,17 +53,29 @@ func NewTrigger(cfg TriggerConfig) *Trigger {
 	if cfg.DebounceSeconds <= 0 {
 		cfg.DebounceSeconds = 30
 	}
+	if cfg.DebounceScope == "" {
+		cfg.DebounceScope = DebounceScopeWorkflow
+	}
 	return &Trigger{
-		cfg:    cfg,
-		timers: make(map[string]*time.Timer),
+		cfg:     cfg,
+		timers:  make(map[string]*time.Timer),
+		pending: make(map[string][]pendingRun),
+	}
+}
+
+// debounceKey returns the key under which a Fire event is debounced.
+func (t *Trigger) debounceKey(projectDir, workflowName string) string {
+	if t.cfg.DebounceScope == DebounceScopeProject {
+		return projectDir
 	}
+	return projectDir + ":" + workflowName
 }
 
 // Fire schedules an evolution run for the given project+workflow.
 // If a run is already pending for this key, it resets the debounce timer
 // and uses the latest runID.
 func (t *Trigger) Fire(projectDir, workflowName, runID string) {
-	key := projectDir + ":" + workflowName
+	key := t.debounceKey(projectDir, workflowName)
 
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -42,15 +84,42 @@ func (t *Trigger) Fire(projectDir, workflowName, runID string) {
 		timer.Stop()
 	}
 
+	runs := t.pending[key]
+	replaced := false
+	for i := range runs {
+		if runs[i].workflowName == workflowName {
+			runs[i].runID = runID
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		runs = append(runs, pendingRun{workflowName: workflowName, runID: runID})
+	}
+	t.pending[key] = runs
+
 	t.timers[key] = time.AfterFunc(time.Duration(t.cfg.DebounceSeconds)*time.Second, func() {
 		t.mu.Lock()
 		delete(t.timers, key)
+		runs := t.pending[key]
+		delete(t.pending, key)
 		t.mu.Unlock()
 
-		t.cfg.RunFunc(projectDir, workflowName, runID)
+		for _, r := range runs {
+			t.cfg.RunFunc(projectDir, r.workflowName, r.runID)
+		}
 	})
 }
 
+// Evolve runs an evolution pass immediately, outside the debounce window,
+// and returns its result.
+func (t *Trigger) Evolve(ctx context.Context, projectDir, workflowName, sinceRunID string) (*EvolutionResult, error) {
+	if t.cfg.EvolveFunc == nil {
+		return nil, ErrEvolveUnsupported
+	}
+	return t.cfg.EvolveFunc(ctx, projectDir, workflowName, sinceRunID)
+}
+
 // Stop cancels all pending timers.
 func (t *Trigger) Stop() {
 	t.mu.Lock()
@@ -59,5 +128,6 @@ func (t *Trigger) Stop() {
 	for key, timer := range t.timers {
 		timer.Stop()
 		delete(t.timers, key)
+		delete(t.pending, key)
 	}
 }
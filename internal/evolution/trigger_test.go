@@ -1,6 +1,7 @@
 package evolution
 
 import (
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -87,3 +88,53 @@ func TestTriggerStopCancelsPending(t *testing.T) {
 	// Should not have fired since we stopped before debounce
 	assert.Equal(t, int32(0), count.Load())
 }
+
+func TestTriggerProjectScopeSharesWindowAcrossWorkflows(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive int
+	var ran []string
+	trigger := NewTrigger(TriggerConfig{
+		DebounceSeconds: 1,
+		DebounceScope:   DebounceScopeProject,
+		RunFunc: func(projectDir, workflowName, runID string) {
+			mu.Lock()
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+			ran = append(ran, workflowName+"/"+runID)
+			mu.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			mu.Lock()
+			active--
+			mu.Unlock()
+		},
+	})
+	defer trigger.Stop()
+
+	trigger.Fire("/project", "develop", "run-1")
+	time.Sleep(500 * time.Millisecond)
+	// A different workflow in the same project resets the shared window.
+	trigger.Fire("/project", "review", "run-2")
+	trigger.Fire("/project", "develop", "run-3")
+
+	// The first window would have closed by now under workflow scope.
+	time.Sleep(700 * time.Millisecond)
+	mu.Lock()
+	assert.Empty(t, ran, "shared window should have been reset by the later fire")
+	mu.Unlock()
+
+	time.Sleep(1 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"develop/run-3", "review/run-2"}, ran)
+	assert.Equal(t, 1, maxActive, "workflows in one project window should run one at a time")
+}
+
+func TestTriggerWorkflowScopeIsDefault(t *testing.T) {
+	trigger := NewTrigger(TriggerConfig{RunFunc: func(string, string, string) {}})
+	assert.Equal(t, DebounceScopeWorkflow, trigger.cfg.DebounceScope)
+}
@@ -15,6 +15,7 @@ type LLMClient interface {
 type CollectedData struct {
 	Runs            []*domain.Run
 	Captures        map[string][]*domain.StepExecution // run_id -> step executions
+	Diffs           map[string]string                  // run_id -> changes the run made, when captured
 	KnowledgeBase   string                             // contents of knowledge/<workflow>.jsonl
 	CurrentPrompts  map[string]string                  // relative path -> content
 	CurrentWorkflow string                             // .cloche file content
@@ -37,14 +38,21 @@ type Lesson struct {
 
 // EvolutionResult records what an evolution pass produced.
 type EvolutionResult struct {
-	ID             string   `json:"id"`
-	ProjectDir     string   `json:"project_dir"`
-	WorkflowName   string   `json:"workflow_name"`
-	TriggerRunID   string   `json:"trigger_run_id"`
-	Timestamp      string   `json:"timestamp"`
-	Classification string   `json:"classification"`
-	Changes        []Change `json:"changes"`
-	KnowledgeDelta string   `json:"knowledge_delta"`
+	ID             string `json:"id"`
+	ProjectDir     string `json:"project_dir"`
+	WorkflowName   string `json:"workflow_name"`
+	TriggerRunID   string `json:"trigger_run_id"`
+	Timestamp      string `json:"timestamp"`
+	Classification string `json:"classification"`
+	// ClassificationRationale and ClassificationConfidence record why the
+	// classifier chose Classification.
+	ClassificationRationale  string   `json:"classification_rationale,omitempty"`
+	ClassificationConfidence string   `json:"classification_confidence,omitempty"`
+	RunsCollected            int      `json:"runs_collected"`
+	Lessons                  []Lesson `json:"lessons,omitempty"` // reflector findings the pass acted on
+	Changes                  []Change `json:"changes"`
+	KnowledgeDelta           string   `json:"knowledge_delta"`
+	LLMLogs                  []string `json:"llm_logs,omitempty"` // project-relative LLM call logs, when enabled
 }
 
 // Change describes a single file modification made by evolution.
@@ -53,6 +61,9 @@ type Change struct {
 	File     string `json:"file"`
 	Reason   string `json:"reason"`
 	Snapshot string `json:"snapshot"`
+	// Summary lists graph-level edits for workflow changes, e.g.
+	// "added step lint" or "wired lint:fail -> abort".
+	Summary []string `json:"summary,omitempty"`
 }
 
 // RunScoreRecord captures a simple score outcome for a candidate on a single run.
@@ -1,6 +1,7 @@
 package host
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
@@ -76,6 +77,17 @@ func (e *Executor) gitIdentityEnv() []string {
 	return out
 }
 
+// promptOutputLimit returns the [output] prompt_chars cap applied to prior
+// step output before it is fed into an agent prompt.
+func (e *Executor) promptOutputLimit() int {
+	cfg, err := config.LoadMerged(e.ProjectDir)
+	if err != nil {
+		log.Printf("host executor: loading output limits from config: %v", err)
+		return 0
+	}
+	return cfg.Output.PromptChars
+}
+
 // composeSSHCommand builds a GIT_SSH_COMMAND value that uses the given key
 // exclusively. "~" is expanded to the user's home directory.
 func composeSSHCommand(keyPath string) string {
@@ -146,8 +158,7 @@ func (e *Executor) Execute(ctx context.Context, step *domain.Step) (domain.StepR
 	var err error
 	switch step.Type {
 	case domain.StepTypeScript:
-		r, e2 := e.executeScript(ctx, step)
-		result, err = domain.StepResult{Result: r}, e2
+		result, err = e.executeScript(ctx, step)
 	case domain.StepTypeAgent:
 		result, err = e.executeAgent(ctx, step)
 	case domain.StepTypeHuman:
@@ -169,8 +180,15 @@ func (e *Executor) Execute(ctx context.Context, step *domain.Step) (domain.StepR
 }
 
 // executeScript runs a shell command on the host.
-func (e *Executor) executeScript(ctx context.Context, step *domain.Step) (string, error) {
-	cmdStr := step.Config["run"]
+func (e *Executor) executeScript(ctx context.Context, step *domain.Step) (domain.StepResult, error) {
+	cmdStr, err := step.ExpandParams(step.Config["run"])
+	if err != nil {
+		return domain.StepResult{}, err
+	}
+	filter, err := protocol.StepOutputFilter(step)
+	if err != nil {
+		return domain.StepResult{}, fmt.Errorf("step %q: %w", step.Name, err)
+	}
 	cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
 	cmd.Dir = e.scriptDir()
 	// Build env from parent, filtering out CLOCHE_* vars so they don't
@@ -213,6 +231,8 @@ func (e *Executor) executeScript(ctx context.Context, step *domain.Step) (string
 	}
 
 	output, err := cmd.CombinedOutput()
+	exitCode := protocol.ExitCode(cmd.ProcessState)
+	output = protocol.FilterOutput(filter, output)
 
 	// Extract result marker
 	markerResult, cleanOutput, found := protocol.ExtractResult(output)
@@ -224,23 +244,25 @@ func (e *Executor) executeScript(ctx context.Context, step *domain.Step) (string
 
 	if err != nil {
 		if ctx.Err() != nil {
-			return "timeout", nil
+			return domain.StepResult{Result: "timeout"}, nil
 		}
 		if _, ok := err.(*exec.ExitError); ok {
 			result := "fail"
 			if found {
 				result = markerResult
 			}
-			return result, nil
+			return domain.StepResult{Result: result, ExitCode: exitCode}, nil
 		}
-		return "", err
+		return domain.StepResult{}, err
 	}
 
 	result := "success"
 	if found {
 		result = markerResult
+	} else if r := step.Config["empty_result"]; r != "" && len(bytes.TrimSpace(cleanOutput)) == 0 {
+		result = r
 	}
-	return result, nil
+	return domain.StepResult{Result: result, ExitCode: exitCode}, nil
 }
 
 // skipTimeout is the maximum time allowed for a skip script to run.
@@ -340,7 +362,7 @@ func (e *Executor) executeAgent(ctx context.Context, step *domain.Step) (domain.
 		adapter.Commands = prompt.ParseCommands(cmd)
 	}
 	if args := step.Config["agent_args"]; args != "" {
-		adapter.ExplicitArgs = strings.Fields(args)
+		adapter.ExplicitArgs = domain.SplitArgs(args)
 	}
 
 	// Populate usage_command from config.toml [agents.codex] if the agent is codex.
@@ -401,6 +423,7 @@ func (e *Executor) executeAgent(ctx context.Context, step *domain.Step) (domain.
 	}
 
 	adapter.PrevOutput = promptContent
+	adapter.PromptChars = e.promptOutputLimit()
 
 	if promptContent != "" {
 		promptPath := filepath.Join(e.ProjectDir, ".cloche", "runs", e.TaskID, "prompt.txt")
@@ -486,19 +509,15 @@ func (e *Executor) findPrevOutput(step *domain.Step) string {
 // The step's timeout (default 72h) is enforced by the context passed in from
 // the engine.
 func (e *Executor) executeHumanStep(ctx context.Context, step *domain.Step) (string, error) {
-	intervalStr := step.Config["interval"]
-	interval, err := time.ParseDuration(intervalStr)
+	interval, _, err := step.Config.Duration("interval")
 	if err != nil {
-		return "", fmt.Errorf("human step %q: invalid interval %q: %w", step.Name, intervalStr, err)
+		return "", fmt.Errorf("human step %q: %w", step.Name, err)
 	}
 
 	// Mark the run as waiting so cloche list/status surfaces it distinctly.
 	if e.Store != nil && e.HostRunID != "" {
-		if run, getErr := e.Store.GetRun(ctx, e.HostRunID); getErr == nil {
-			run.State = domain.RunStateWaiting
-			if updateErr := e.Store.UpdateRun(ctx, run); updateErr != nil {
-				log.Printf("host executor: setting run %q to waiting: %v", e.HostRunID, updateErr)
-			}
+		if updateErr := e.Store.UpdateRunState(ctx, e.HostRunID, domain.RunStateWaiting); updateErr != nil {
+			log.Printf("host executor: setting run %q to waiting: %v", e.HostRunID, updateErr)
 		}
 	}
 
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cloche-dev/cloche/internal/adapters/sqlite"
 	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/engine"
 	"github.com/cloche-dev/cloche/internal/logstream"
@@ -88,6 +89,24 @@ func (f *fakeStore) UpdateRun(_ context.Context, run *domain.Run) error {
 	f.runs[run.ID] = run
 	return nil
 }
+func (f *fakeStore) UpdateRunState(_ context.Context, id string, state domain.RunState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r, ok := f.runs[id]; ok {
+		r.State = state
+		return nil
+	}
+	return os.ErrNotExist
+}
+func (f *fakeStore) UpdateActiveSteps(_ context.Context, id string, steps []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r, ok := f.runs[id]; ok {
+		r.ActiveSteps = append([]string(nil), steps...)
+		return nil
+	}
+	return os.ErrNotExist
+}
 func (f *fakeStore) DeleteRun(_ context.Context, id string) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -103,6 +122,9 @@ func (f *fakeStore) ListRunsByProject(_ context.Context, _ string, _ time.Time)
 func (f *fakeStore) ListRunsFiltered(_ context.Context, _ domain.RunListFilter) ([]*domain.Run, error) {
 	return nil, nil
 }
+func (f *fakeStore) DeleteRunsWhere(_ context.Context, _ domain.RunListFilter) ([]*domain.Run, error) {
+	return nil, nil
+}
 func (f *fakeStore) ListProjects(_ context.Context) ([]string, error) {
 	return nil, nil
 }
@@ -245,6 +267,8 @@ func TestExecutor_ScriptStep_Failure(t *testing.T) {
 	result, err := executor.Execute(context.Background(), step)
 	require.NoError(t, err)
 	assert.Equal(t, "fail", result.Result)
+	require.NotNil(t, result.ExitCode)
+	assert.Equal(t, 1, *result.ExitCode)
 }
 
 func TestExecutor_ScriptStep_ResultMarker(t *testing.T) {
@@ -274,6 +298,33 @@ func TestExecutor_ScriptStep_ResultMarker(t *testing.T) {
 	assert.Contains(t, string(data), "some output")
 }
 
+func TestExecutor_ScriptStep_OutputFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "output")
+	executor := &Executor{ProjectDir: tmpDir, OutputDir: outputDir}
+
+	step := &domain.Step{
+		Name:    "build",
+		Type:    domain.StepTypeScript,
+		Results: []string{"success", "fail"},
+		Config:  map[string]string{"run": `printf '\033[32mok\033[0m\n'`},
+	}
+
+	// Script steps keep their output as written unless they ask for cleanup.
+	_, err := executor.Execute(context.Background(), step)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(outputDir, "build.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "\x1b[32mok\x1b[0m\n", string(data))
+
+	step.Config["output_filter"] = "strip"
+	_, err = executor.Execute(context.Background(), step)
+	require.NoError(t, err)
+	data, err = os.ReadFile(filepath.Join(outputDir, "build.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "\x1b[32mok\x1b[0m\nok\n", string(data))
+}
+
 func TestEngine_HostWorkflow_AbortOnScriptFail(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, "output")
@@ -483,6 +534,36 @@ func TestRunner_RunWithID(t *testing.T) {
 	assert.Equal(t, domain.RunStateSucceeded, hostRun.State)
 }
 
+func TestRunner_WritesEngineLogWhenEnabled(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	clocheDir := filepath.Join(tmpDir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	hostCloche := `workflow main {
+  host {}
+
+  step greet {
+    run     = "echo hi"
+    results = [success, fail]
+  }
+
+  greet:success -> done
+  greet:fail    -> abort
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "host.cloche"), []byte(hostCloche), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte("[runs]\nengine_log = true\n"), 0644))
+
+	runner := &Runner{Store: &fakeStore{runs: map[string]*domain.Run{}}}
+	_, err := runner.RunWithID(context.Background(), tmpDir, "engine-log-run")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(clocheDir, "engine-log-run", "engine.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[main] launch greet (entry step)")
+	assert.Contains(t, string(data), "[main] wire greet:success -> done fired")
+	assert.Contains(t, string(data), "[main] run succeeded")
+}
+
 func TestRunner_WithTaskID(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -949,6 +1030,49 @@ func TestExecutor_AgentStep_PrevOutput(t *testing.T) {
 	assert.Equal(t, "the task description", string(data))
 }
 
+func TestExecutor_AgentStep_PrevOutputRespectsPromptLimit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "output")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".cloche"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".cloche", "config.toml"), []byte("[output]\nprompt_chars = 16\n"), 0644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "prepare.log"), []byte(strings.Repeat("p", 500)), 0644))
+
+	received := filepath.Join(tmpDir, "received.txt")
+	mockAgent := filepath.Join(tmpDir, "mock-agent.sh")
+	require.NoError(t, os.WriteFile(mockAgent, []byte("#!/bin/sh\ncat > "+received+"\necho 'processed prompt'\n"), 0755))
+
+	executor := &Executor{
+		ProjectDir: tmpDir,
+		OutputDir:  outputDir,
+		HostRunID:  "test-host-run",
+		TaskID:     "test-task-id",
+		Wires: []domain.Wire{
+			{From: "prepare", Result: "success", To: "implement"},
+		},
+	}
+
+	step := &domain.Step{
+		Name:    "implement",
+		Type:    domain.StepTypeAgent,
+		Results: []string{"success", "fail"},
+		Config: map[string]string{
+			"prompt":        "You are a coding assistant.",
+			"agent_command": mockAgent,
+		},
+	}
+
+	_, err := executor.Execute(context.Background(), step)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(received)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), strings.Repeat("p", 16)+domain.TruncatedSuffix)
+	assert.NotContains(t, string(data), strings.Repeat("p", 17))
+}
+
 func TestExecutor_AgentStep_PromptStep(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, "output")
@@ -1088,6 +1212,57 @@ func TestRunner_HostWorkflow_AgentStep(t *testing.T) {
 	assert.Equal(t, domain.RunStateSucceeded, hostRun.State)
 }
 
+func TestRunner_HostWorkflow_SavesPrompts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mockAgent := filepath.Join(tmpDir, "mock-agent.sh")
+	require.NoError(t, os.WriteFile(mockAgent, []byte("#!/bin/sh\ncat > /dev/null\necho 'agent implemented'\n"), 0755))
+
+	clocheDir := filepath.Join(tmpDir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "config.toml"), []byte("[runs]\nsave_prompts = true\n"), 0644))
+	hostCloche := `workflow main {
+  host {
+    agent_command = "` + mockAgent + `"
+  }
+
+  step implement {
+    prompt = "Implement the feature."
+    results = [success, fail]
+  }
+
+  implement:success -> done
+  implement:fail    -> abort
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "host.cloche"), []byte(hostCloche), 0644))
+
+	store, err := sqlite.NewStore(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	runner := &Runner{
+		Store:    store,
+		Captures: store,
+	}
+
+	result, err := runner.Run(context.Background(), tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateSucceeded, result.State)
+
+	caps, err := store.GetCaptures(context.Background(), result.RunID)
+	require.NoError(t, err)
+	var paths []string
+	for _, c := range caps {
+		if c.PromptPath != "" {
+			paths = append(paths, c.PromptPath)
+		}
+	}
+	require.Equal(t, []string{filepath.Join(".cloche", result.RunID, "prompts", "implement.1.txt")}, paths)
+	data, err := os.ReadFile(filepath.Join(tmpDir, paths[0]))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Implement the feature.")
+}
+
 func TestRunner_HostWorkflow_AgentStepOverridesWorkflowCommand(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -1163,6 +1338,44 @@ func TestRunner_PersistsHostRunOnFailure(t *testing.T) {
 	assert.False(t, hostRun.CompletedAt.IsZero())
 }
 
+func TestRunner_CancelledRunCompletesCancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	clocheDir := filepath.Join(tmpDir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	hostCloche := `workflow main {
+  host {}
+
+  step wait {
+    run     = "exec sleep 30"
+    results = [success, fail]
+  }
+
+  wait:success -> done
+  wait:fail    -> abort
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "host.cloche"), []byte(hostCloche), 0644))
+
+	store := &fakeStore{runs: map[string]*domain.Run{}}
+	runner := &Runner{Store: store}
+
+	// Cancel the way StopRun does once the step is under way.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := runner.Run(ctx, tmpDir)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second, "the step should be killed, not waited out")
+	assert.Equal(t, domain.RunStateCancelled, result.State)
+
+	hostRun, err := store.GetRun(context.Background(), result.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStateCancelled, hostRun.State)
+	assert.Empty(t, hostRun.ErrorMessage)
+	assert.False(t, hostRun.CompletedAt.IsZero())
+}
+
 // --- RunNamed tests ---
 
 func TestRunner_RunNamed_Main(t *testing.T) {
@@ -1287,6 +1500,31 @@ func TestRunner_RunNamed_NotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found")
 }
 
+func TestRunner_RunNamed_FileDeclaresDifferentName(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	clocheDir := filepath.Join(tmpDir, ".cloche")
+	require.NoError(t, os.MkdirAll(clocheDir, 0755))
+	// release.cloche declares "ship", so "release" matches no declaration.
+	hostCloche := `workflow ship {
+  host {}
+
+  step tag {
+    run     = "echo tagged"
+    results = [success, fail]
+  }
+  tag:success -> done
+  tag:fail    -> abort
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(clocheDir, "release.cloche"), []byte(hostCloche), 0644))
+
+	runner := &Runner{Store: &fakeStore{runs: map[string]*domain.Run{}}}
+
+	_, err := runner.RunNamed(context.Background(), tmpDir, "release")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `.cloche/release.cloche declares workflow "ship", not "release"`)
+}
+
 // --- ExtraEnv tests ---
 
 func TestExecutor_ScriptStep_ExtraEnv(t *testing.T) {
@@ -1620,7 +1858,7 @@ func TestHostStatusHandler_ReadsStepLogFile(t *testing.T) {
 	}
 
 	step := &domain.Step{Name: "build"}
-	handler.OnStepComplete(nil, step, "success", nil)
+	handler.OnStepComplete(nil, step, domain.StepResult{Result: "success"})
 
 	// Verify only one .log file exists (no duplicate .out file)
 	entries, err := os.ReadDir(outputDir)
@@ -1639,7 +1877,7 @@ func TestHostStatusHandler_NoLogFileWhenNoOutput(t *testing.T) {
 	}
 
 	step := &domain.Step{Name: "missing"}
-	handler.OnStepComplete(nil, step, "success", nil)
+	handler.OnStepComplete(nil, step, domain.StepResult{Result: "success"})
 
 	// No .log file should be created
 	_, err := os.Stat(filepath.Join(outputDir, "missing.log"))
@@ -1670,7 +1908,7 @@ func TestHostStatusHandler_WorkflowStep_AppendsRawContent(t *testing.T) {
 	}
 
 	step := &domain.Step{Name: "develop", Type: domain.StepTypeWorkflow}
-	handler.OnStepComplete(nil, step, "success", nil)
+	handler.OnStepComplete(nil, step, domain.StepResult{Result: "success"})
 	logWriter.Close()
 
 	data, err := os.ReadFile(filepath.Join(outputDir, "full.log"))
@@ -1706,7 +1944,7 @@ func TestHostStatusHandler_WorkflowStep_NoBroadcastForContent(t *testing.T) {
 	}
 
 	step := &domain.Step{Name: "develop", Type: domain.StepTypeWorkflow}
-	handler.OnStepComplete(nil, step, "success", nil)
+	handler.OnStepComplete(nil, step, domain.StepResult{Result: "success"})
 
 	// Drain the subscriber channel.
 	var contents []string
@@ -2240,14 +2478,14 @@ func TestHostStatusHandler_DeltaLogging(t *testing.T) {
 	// Write iteration 1 output.
 	step := &domain.Step{Name: "fix"}
 	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "fix.log"), []byte("iter1\n"), 0644))
-	handler.OnStepComplete(nil, step, "fail", nil)
+	handler.OnStepComplete(nil, step, domain.StepResult{Result: "fail"})
 
 	// Append iteration 2 output (simulating loop iteration).
 	f, err := os.OpenFile(filepath.Join(outputDir, "fix.log"), os.O_APPEND|os.O_WRONLY, 0644)
 	require.NoError(t, err)
 	_, _ = f.WriteString("iter2\n")
 	_ = f.Close()
-	handler.OnStepComplete(nil, step, "fail", nil)
+	handler.OnStepComplete(nil, step, domain.StepResult{Result: "fail"})
 
 	require.NoError(t, logWriter.Close())
 
@@ -6,11 +6,14 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/cloche-dev/cloche/internal/activitylog"
 	"github.com/cloche-dev/cloche/internal/adapters/agents/prompt"
+	"github.com/cloche-dev/cloche/internal/adapters/docker"
+	"github.com/cloche-dev/cloche/internal/config"
 	"github.com/cloche-dev/cloche/internal/domain"
 	"github.com/cloche-dev/cloche/internal/dsl"
 	"github.com/cloche-dev/cloche/internal/engine"
@@ -34,6 +37,7 @@ type Runner struct {
 	AttemptID     string                   // optional attempt ID for v2 tracking
 	ParentRunID   string                   // optional parent run ID (links this run to another in the UI)
 	ExtraEnv       []string                 // additional KEY=VALUE env vars passed to all steps
+	Params         map[string]string        // overrides for the workflow's declared params
 	SkipRunRecord  bool                     // when true, don't persist a run record to the store
 }
 
@@ -80,6 +84,9 @@ func (r *Runner) runNamedWorkflow(ctx context.Context, projectDir string, workfl
 	if err != nil {
 		return nil, err
 	}
+	if err := wf.OverrideParams(r.Params); err != nil {
+		return nil, err
+	}
 
 	// Create output directory for step outputs.
 	// v2 runs use .cloche/logs/<taskID>/<attemptID>/; ephemeral runs
@@ -147,7 +154,7 @@ func (r *Runner) runNamedWorkflow(ctx context.Context, projectDir string, workfl
 		hostExec.AgentCommands = prompt.ParseCommands(cmd)
 	}
 	if args := wf.Config["host.agent_args"]; args != "" {
-		hostExec.AgentArgs = strings.Fields(args)
+		hostExec.AgentArgs = domain.SplitArgs(args)
 	}
 
 	// Use the custom executor if provided (e.g. DaemonExecutor for workflow_name
@@ -180,6 +187,12 @@ func (r *Runner) runNamedWorkflow(ctx context.Context, projectDir string, workfl
 	}
 
 	eng := engine.New(stepExec)
+	if !r.SkipRunRecord {
+		if f := openEngineLog(projectDir, orchRunID); f != nil {
+			defer f.Close()
+			eng.SetDecisionLog(f)
+		}
+	}
 	eng.SetStatusHandler(&hostStatusHandler{
 		projectDir:   projectDir,
 		orchRunID:    orchRunID,
@@ -192,6 +205,7 @@ func (r *Runner) runNamedWorkflow(ctx context.Context, projectDir string, workfl
 		taskID:       r.TaskID,
 		attemptID:    r.AttemptID,
 		workflowName: wf.Name,
+		savePrompts:  config.SavePromptsEnabled(projectDir),
 	})
 
 	run, runErr := eng.Run(ctx, wf)
@@ -217,20 +231,12 @@ func (r *Runner) runNamedWorkflow(ctx context.Context, projectDir string, workfl
 	}
 
 	// Persist final state. Use context.Background() because ctx may have been
-	// cancelled if the run was stopped externally (cloche stop). Skip
-	// overwriting a Cancelled state set by StopRun.
+	// cancelled if the run was stopped externally (cloche stop).
 	if !r.SkipRunRecord {
 		cleanupCtx := context.Background()
 		hostRun, _ := r.Store.GetRun(cleanupCtx, orchRunID)
 		if hostRun != nil {
-			if hostRun.State != domain.RunStateCancelled {
-				if runErr != nil {
-					hostRun.Fail(runErr.Error())
-				} else {
-					hostRun.Complete(result.State)
-				}
-			}
-			hostRun.ActiveSteps = nil
+			finishHostRun(hostRun, run, runErr)
 			_ = r.Store.UpdateRun(cleanupCtx, hostRun)
 		}
 	}
@@ -256,6 +262,27 @@ func (r *Runner) runNamedWorkflow(ctx context.Context, projectDir string, workfl
 	return result, nil
 }
 
+// finishHostRun records the engine's outcome on the stored host run. A
+// Cancelled state already set by StopRun is kept, and a run the engine wound
+// down because its context was cancelled is recorded as cancelled rather than
+// failed with the context error.
+func finishHostRun(hostRun, engRun *domain.Run, runErr error) {
+	switch {
+	case hostRun.State == domain.RunStateCancelled:
+	case engRun != nil && engRun.State == domain.RunStateCancelled:
+		hostRun.Complete(domain.RunStateCancelled)
+	case runErr != nil:
+		hostRun.Fail(runErr.Error())
+	case engRun != nil && engRun.ErrorMessage != "":
+		hostRun.Fail(engRun.ErrorMessage) // e.g. which wire or collect aborted
+	case engRun != nil:
+		hostRun.Complete(engRun.State)
+	default:
+		hostRun.Complete(domain.RunStateFailed)
+	}
+	hostRun.ActiveSteps = nil
+}
+
 // ResumeRun resumes a failed host workflow run from a specific step.
 // Steps before resumeFrom are replayed from their stored results.
 func (r *Runner) ResumeRun(ctx context.Context, run *domain.Run, resumeFrom string) (*RunResult, error) {
@@ -335,7 +362,7 @@ func (r *Runner) ResumeRun(ctx context.Context, run *domain.Run, resumeFrom stri
 		hostExec.AgentCommands = prompt.ParseCommands(cmd)
 	}
 	if args := wf.Config["host.agent_args"]; args != "" {
-		hostExec.AgentArgs = strings.Fields(args)
+		hostExec.AgentArgs = domain.SplitArgs(args)
 	}
 
 	// Build preloaded results from previously completed steps
@@ -367,6 +394,10 @@ func (r *Runner) ResumeRun(ctx context.Context, run *domain.Run, resumeFrom stri
 
 	eng := engine.New(stepExec)
 	eng.SetPreloadedResults(preloaded)
+	if f := openEngineLog(run.ProjectDir, run.ID); f != nil {
+		defer f.Close()
+		eng.SetDecisionLog(f)
+	}
 	eng.SetStatusHandler(&hostStatusHandler{
 		projectDir:   run.ProjectDir,
 		orchRunID:    run.ID,
@@ -379,6 +410,7 @@ func (r *Runner) ResumeRun(ctx context.Context, run *domain.Run, resumeFrom stri
 		taskID:       r.TaskID,
 		attemptID:    r.AttemptID,
 		workflowName: wf.Name,
+		savePrompts:  config.SavePromptsEnabled(run.ProjectDir),
 	})
 
 	engRun, runErr := eng.Run(ctx, wf)
@@ -402,20 +434,12 @@ func (r *Runner) ResumeRun(ctx context.Context, run *domain.Run, resumeFrom stri
 	}
 
 	// Persist final state. Use context.Background() because ctx may have been
-	// cancelled if the run was stopped externally (cloche stop). Skip
-	// overwriting a Cancelled state set by StopRun.
+	// cancelled if the run was stopped externally (cloche stop).
 	{
 		cleanupCtx := context.Background()
 		hostRun, _ := r.Store.GetRun(cleanupCtx, run.ID)
 		if hostRun != nil {
-			if hostRun.State != domain.RunStateCancelled {
-				if runErr != nil {
-					hostRun.Fail(runErr.Error())
-				} else {
-					hostRun.Complete(result.State)
-				}
-			}
-			hostRun.ActiveSteps = nil
+			finishHostRun(hostRun, engRun, runErr)
 			_ = r.Store.UpdateRun(cleanupCtx, hostRun)
 		}
 	}
@@ -526,7 +550,7 @@ func (r *Runner) ResumeRunAsNewAttempt(ctx context.Context, oldRun *domain.Run,
 		hostExec.AgentCommands = prompt.ParseCommands(cmd)
 	}
 	if args := wf.Config["host.agent_args"]; args != "" {
-		hostExec.AgentArgs = strings.Fields(args)
+		hostExec.AgentArgs = domain.SplitArgs(args)
 	}
 
 	var stepExec engine.StepExecutor = hostExec
@@ -553,6 +577,10 @@ func (r *Runner) ResumeRunAsNewAttempt(ctx context.Context, oldRun *domain.Run,
 
 	eng := engine.New(stepExec)
 	eng.SetPreloadedResults(preloaded)
+	if f := openEngineLog(oldRun.ProjectDir, newRunID); f != nil {
+		defer f.Close()
+		eng.SetDecisionLog(f)
+	}
 	eng.SetStatusHandler(&hostStatusHandler{
 		projectDir:   oldRun.ProjectDir,
 		orchRunID:    newRunID,
@@ -565,6 +593,7 @@ func (r *Runner) ResumeRunAsNewAttempt(ctx context.Context, oldRun *domain.Run,
 		taskID:       r.TaskID,
 		attemptID:    r.AttemptID,
 		workflowName: wf.Name,
+		savePrompts:  config.SavePromptsEnabled(oldRun.ProjectDir),
 	})
 
 	engRun, runErr := eng.Run(ctx, wf)
@@ -589,19 +618,12 @@ func (r *Runner) ResumeRunAsNewAttempt(ctx context.Context, oldRun *domain.Run,
 
 	// Persist final state using the new run record. Use context.Background()
 	// because ctx may have been cancelled if the run was stopped externally
-	// (cloche stop). Skip overwriting a Cancelled state set by StopRun.
+	// (cloche stop).
 	{
 		cleanupCtx := context.Background()
 		hostRunFinal, _ := r.Store.GetRun(cleanupCtx, newRunID)
 		if hostRunFinal != nil {
-			if hostRunFinal.State != domain.RunStateCancelled {
-				if runErr != nil {
-					hostRunFinal.Fail(runErr.Error())
-				} else {
-					hostRunFinal.Complete(result.State)
-				}
-			}
-			hostRunFinal.ActiveSteps = nil
+			finishHostRun(hostRunFinal, engRun, runErr)
 			_ = r.Store.UpdateRun(cleanupCtx, hostRunFinal)
 		}
 	}
@@ -623,6 +645,27 @@ func (r *Runner) ResumeRunAsNewAttempt(ctx context.Context, oldRun *domain.Run,
 	return result, nil
 }
 
+// openEngineLog opens .cloche/<run-id>/engine.log for appending when the
+// project sets [runs] engine_log, so a resumed run adds to the log of the run
+// it continues. Returns nil when the log is disabled or cannot be opened.
+func openEngineLog(projectDir, runID string) *os.File {
+	cfg, err := config.Load(projectDir)
+	if err != nil || !cfg.Runs.EngineLog {
+		return nil
+	}
+	path := filepath.Join(projectDir, ".cloche", runID, "engine.log")
+	if err := docker.MkdirOwned(filepath.Dir(path)); err != nil {
+		log.Printf("host workflow [%s]: creating engine log: %v", runID, err)
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("host workflow [%s]: opening engine log: %v", runID, err)
+		return nil
+	}
+	return f
+}
+
 // copySuccessfulStepOutputs copies step output files for steps that completed
 // successfully before the resume point from oldDir to newDir. This gives the
 // new attempt access to prior step outputs without re-executing those steps.
@@ -762,14 +805,77 @@ func findHostWorkflow(projectDir, workflowName string) (*domain.Workflow, error)
 			continue
 		}
 		if wf, ok := workflows[workflowName]; ok && wf.Location == domain.LocationHost {
-			wf.ResolveAgents()
+			wf.Resolve()
 			return wf, nil
 		}
 	}
 
+	// A file named after the workflow that declares something else is the
+	// likely culprit; say so instead of only reporting the miss.
+	if _, err := os.Stat(filepath.Join(clocheDir, workflowName+".cloche")); err == nil {
+		if err := CheckWorkflowFile(projectDir, workflowName); err != nil {
+			return nil, fmt.Errorf("host workflow %q not found: %w", workflowName, err)
+		}
+	}
 	return nil, fmt.Errorf("host workflow %q not found in any .cloche file", workflowName)
 }
 
+// CheckWorkflowFile reports a mismatch between a workflow name and the file
+// named after it. Container runs load .cloche/<name>.cloche, so a file
+// dev.cloche declaring workflow "develop" — or develop declared in some other
+// file — only fails later and confusingly. The error names both the file and
+// the declarations. A missing or unparseable file with no declaration
+// elsewhere is not reported here.
+func CheckWorkflowFile(projectDir, workflowName string) error {
+	fileName := workflowName + ".cloche"
+	data, err := os.ReadFile(filepath.Join(projectDir, ".cloche", fileName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil
+		}
+		if path := findWorkflowDeclaration(projectDir, workflowName); path != "" {
+			return fmt.Errorf("workflow %q is declared in .cloche/%s, but runs load .cloche/%s; rename the file to match the workflow",
+				workflowName, filepath.Base(path), fileName)
+		}
+		return nil
+	}
+
+	workflows, err := dsl.ParseAll(string(data))
+	if err != nil {
+		return nil
+	}
+	if _, ok := workflows[workflowName]; ok {
+		return nil
+	}
+	declared := make([]string, 0, len(workflows))
+	for name := range workflows {
+		declared = append(declared, fmt.Sprintf("%q", name))
+	}
+	sort.Strings(declared)
+	return fmt.Errorf(".cloche/%s declares workflow %s, not %q; rename the file or the declaration so they match",
+		fileName, strings.Join(declared, ", "), workflowName)
+}
+
+// findWorkflowDeclaration returns the path of the .cloche file that declares
+// workflowName, or "" if none does.
+func findWorkflowDeclaration(projectDir, workflowName string) string {
+	entries, _ := filepath.Glob(filepath.Join(projectDir, ".cloche", "*.cloche"))
+	for _, path := range entries {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		workflows, err := dsl.ParseAll(string(data))
+		if err != nil {
+			continue
+		}
+		if _, ok := workflows[workflowName]; ok {
+			return path
+		}
+	}
+	return ""
+}
+
 // FindAllWorkflows returns all workflows across all .cloche files, keyed by name.
 // Returns an error if the same workflow name appears in more than one file.
 func FindAllWorkflows(projectDir string) (map[string]*domain.Workflow, error) {
@@ -798,7 +904,7 @@ func FindAllWorkflows(projectDir string) (map[string]*domain.Workflow, error) {
 				return nil, fmt.Errorf("duplicate workflow name %q: defined in both %s and %s", name, prev, filename)
 			}
 			seenIn[name] = filename
-			wf.ResolveAgents()
+			wf.Resolve()
 			all[name] = wf
 		}
 	}
@@ -838,6 +944,8 @@ type hostStatusHandler struct {
 	attemptID      string              // propagated to activity log entries
 	workflowName   string              // propagated to activity log entries
 	stepLogOffsets map[string]int64    // tracks bytes already written to full.log per step
+	savePrompts    bool                // [runs] save_prompts, read once per run
+	promptAttempts map[string]int      // prompts saved per step, seeded from the run's captures
 }
 
 func (h *hostStatusHandler) OnStepStart(_ *domain.Run, step *domain.Step) {
@@ -846,7 +954,7 @@ func (h *hostStatusHandler) OnStepStart(_ *domain.Run, step *domain.Step) {
 	if h.store != nil {
 		if r, err := h.store.GetRun(context.Background(), h.orchRunID); err == nil {
 			r.RecordStepStart(step.Name)
-			_ = h.store.UpdateRun(context.Background(), r)
+			_ = h.store.UpdateActiveSteps(context.Background(), h.orchRunID, r.ActiveSteps)
 		}
 	}
 	if h.captures != nil {
@@ -878,21 +986,28 @@ func (h *hostStatusHandler) OnStepStart(_ *domain.Run, step *domain.Step) {
 	}
 }
 
-func (h *hostStatusHandler) OnStepComplete(_ *domain.Run, step *domain.Step, result string, usage *domain.TokenUsage) {
+func (h *hostStatusHandler) OnStepComplete(_ *domain.Run, step *domain.Step, sr domain.StepResult) {
 	now := time.Now()
+	result := sr.Result
 	log.Printf("host workflow [%s]: step %q completed with result %q", h.orchRunID, step.Name, result)
 	if h.store != nil {
 		if r, err := h.store.GetRun(context.Background(), h.orchRunID); err == nil {
 			r.RecordStepComplete(step.Name, result)
-			_ = h.store.UpdateRun(context.Background(), r)
+			_ = h.store.UpdateActiveSteps(context.Background(), h.orchRunID, r.ActiveSteps)
 		}
 	}
+	var promptPath string
+	if h.savePrompts && sr.Prompt != "" {
+		promptPath = h.savePrompt(step.Name, sr.Prompt)
+	}
 	if h.captures != nil {
 		_ = h.captures.SaveCapture(context.Background(), h.orchRunID, &domain.StepExecution{
 			StepName:    step.Name,
 			Result:      result,
 			CompletedAt: now,
-			Usage:       usage,
+			Usage:       sr.Usage,
+			ExitCode:    sr.ExitCode,
+			PromptPath:  promptPath,
 		})
 	}
 	if h.activityLog != nil {
@@ -958,13 +1073,44 @@ func (h *hostStatusHandler) OnStepComplete(_ *domain.Run, step *domain.Step, res
 	}
 }
 
+// savePrompt writes the prompt an agent step received to
+// .cloche/<run-id>/prompts/<step>.<attempt>.txt, where attempt counts the
+// step's executions in the run (1-based). Returns the project-relative path,
+// or "" when nothing was saved.
+func (h *hostStatusHandler) savePrompt(stepName, prompt string) string {
+	if h.promptAttempts == nil {
+		h.promptAttempts = make(map[string]int)
+		if h.captures != nil {
+			if captures, err := h.captures.GetCaptures(context.Background(), h.orchRunID); err == nil {
+				for _, c := range captures {
+					if !c.CompletedAt.IsZero() {
+						h.promptAttempts[c.StepName]++
+					}
+				}
+			}
+		}
+	}
+	h.promptAttempts[stepName]++
+	rel := filepath.Join(".cloche", h.orchRunID, "prompts", fmt.Sprintf("%s.%d.txt", stepName, h.promptAttempts[stepName]))
+	path := filepath.Join(h.projectDir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("host workflow [%s]: saving prompt for step %q: %v", h.orchRunID, stepName, err)
+		return ""
+	}
+	if err := os.WriteFile(path, []byte(prompt), 0644); err != nil {
+		log.Printf("host workflow [%s]: saving prompt for step %q: %v", h.orchRunID, stepName, err)
+		return ""
+	}
+	return rel
+}
+
 func (h *hostStatusHandler) OnStepSkipped(_ *domain.Run, step *domain.Step, wire string) {
 	now := time.Now()
 	log.Printf("host workflow [%s]: step %q skipped, wire %q", h.orchRunID, step.Name, wire)
 	if h.store != nil {
 		if r, err := h.store.GetRun(context.Background(), h.orchRunID); err == nil {
 			r.RecordStepSkipped(step.Name, wire)
-			_ = h.store.UpdateRun(context.Background(), r)
+			_ = h.store.UpdateActiveSteps(context.Background(), h.orchRunID, r.ActiveSteps)
 		}
 	}
 	if h.captures != nil {
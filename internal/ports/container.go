@@ -16,6 +16,9 @@ type ContainerConfig struct {
 	Image        string
 	WorkflowName string
 	ProjectDir   string
+	// Network is the container network mode (e.g. "bridge" or a named
+	// network). Empty uses the runtime's default.
+	Network      string
 	NetworkAllow []string
 	RunID        string
 	TaskID       string // task ID for runtime state paths (.cloche/runs/<task-id>/)
@@ -23,6 +26,10 @@ type ContainerConfig struct {
 	Cmd          []string // override container command; defaults to ["cloche-agent", WorkflowName]
 	Prompt       string // prompt text to write into .cloche/runs/<task-id>/prompt.txt in container
 	Interactive  bool   // allocate TTY and keep stdin open (-it flags)
+	Labels       []string // "key=value" container labels from the workflow's labels field
+	PromptChars  int      // [output] prompt_chars cap for prompt input inside the container; zero is unlimited
+	Params       map[string]string // workflow param overrides ("cloche run --param") applied by the agent
+	SavePrompts  bool              // [runs] save_prompts: the agent reports each agent step's assembled prompt
 }
 
 type ContainerRuntime interface {
@@ -38,6 +45,13 @@ type ContainerRuntime interface {
 	// bidirectional I/O. Requires the container to have been started with
 	// Interactive=true in its ContainerConfig.
 	Attach(ctx context.Context, containerID string) (io.ReadWriteCloser, error)
+	// Pull fetches image from its registry so a following Start does not
+	// block on an implicit pull. Runtimes without images treat it as a no-op.
+	Pull(ctx context.Context, image string) error
+	// Exec runs cmd inside a running container, in its workspace directory,
+	// and returns the command's combined output and exit code. A non-zero
+	// exit is not an error; err reports only a failure to run the command.
+	Exec(ctx context.Context, containerID string, cmd []string) (output string, exitCode int, err error)
 }
 
 // ImageEnsurer is an optional interface that a ContainerRuntime may implement
@@ -47,6 +61,13 @@ type ImageEnsurer interface {
 	EnsureImage(ctx context.Context, projectDir, image string) error
 }
 
+// ImageChecker is an optional interface for runtimes that can tell whether an
+// image is already available locally. The "missing" pull policy uses it to
+// skip pulls; runtimes without it are never pulled under that policy.
+type ImageChecker interface {
+	HasImage(ctx context.Context, image string) (bool, error)
+}
+
 // ContainerCommitter is an optional interface for creating an image from a
 // stopped container's filesystem state. Used for resume: the committed image
 // preserves all step outputs and workspace changes from the failed run.
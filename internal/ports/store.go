@@ -20,11 +20,22 @@ type RunStore interface {
 	CreateRun(ctx context.Context, run *domain.Run) error
 	GetRun(ctx context.Context, id string) (*domain.Run, error)
 	GetRunByAttempt(ctx context.Context, attemptID, id string) (*domain.Run, error)
+	// UpdateRun writes the whole run. When the run is finished it also
+	// records the run's step aggregates (domain.Run.StepCount and
+	// StepAttempts) from its captures.
 	UpdateRun(ctx context.Context, run *domain.Run) error
+	// UpdateRunState and UpdateActiveSteps write a single column of the latest
+	// run with the given ID. Use them instead of UpdateRun for the frequent
+	// small updates made while a run is in progress.
+	UpdateRunState(ctx context.Context, id string, state domain.RunState) error
+	UpdateActiveSteps(ctx context.Context, id string, steps []string) error
 	DeleteRun(ctx context.Context, id string) error
 	ListRuns(ctx context.Context, since time.Time) ([]*domain.Run, error)
 	ListRunsByProject(ctx context.Context, projectDir string, since time.Time) ([]*domain.Run, error)
 	ListRunsFiltered(ctx context.Context, filter domain.RunListFilter) ([]*domain.Run, error)
+	// DeleteRunsWhere deletes the finished runs matching filter, with their
+	// step executions, and returns them. Unfinished runs are left alone.
+	DeleteRunsWhere(ctx context.Context, filter domain.RunListFilter) ([]*domain.Run, error)
 	ListProjects(ctx context.Context) ([]string, error)
 	ListChildRuns(ctx context.Context, parentRunID string) ([]*domain.Run, error)
 	QueryUsage(ctx context.Context, q UsageQuery) ([]domain.UsageSummary, error)
@@ -38,6 +49,14 @@ type RunStore interface {
 	FailStaleAttempts(ctx context.Context) (int64, error)
 }
 
+// RunTransactor is an optional interface that a RunStore may implement to
+// apply a group of writes atomically. fn receives a RunStore bound to the
+// transaction; if fn returns an error, none of its writes are applied. Keep fn
+// short — implementations may block other store access until it returns.
+type RunTransactor interface {
+	Transaction(ctx context.Context, fn func(tx RunStore) error) error
+}
+
 // ProjectMigrator is an optional interface that a RunStore may implement
 // to perform per-project data migrations (e.g., moving log files to v2 layout).
 type ProjectMigrator interface {
@@ -47,6 +66,13 @@ type ProjectMigrator interface {
 type CaptureStore interface {
 	SaveCapture(ctx context.Context, runID string, exec *domain.StepExecution) error
 	GetCaptures(ctx context.Context, runID string) ([]*domain.StepExecution, error)
+	// GetCaptureStream calls fn for each capture of the run in the same order
+	// as GetCaptures without loading them all at once. Iteration stops at the
+	// first error returned by fn.
+	GetCaptureStream(ctx context.Context, runID string, fn func(*domain.StepExecution) error) error
+	// MaxCaptureSeq returns the highest capture sequence number stored for
+	// the run, or 0 when it has none.
+	MaxCaptureSeq(ctx context.Context, runID string) (int64, error)
 }
 
 type LogFileEntry struct {
@@ -92,8 +118,12 @@ type EvolutionEntry struct {
 	TriggerRunID   string
 	CreatedAt      time.Time
 	Classification string
-	ChangesJSON    string
-	KnowledgeDelta string
+	// ClassificationRationale and ClassificationConfidence are the
+	// classifier's reasoning for Classification.
+	ClassificationRationale  string
+	ClassificationConfidence string
+	ChangesJSON              string
+	KnowledgeDelta           string
 }
 
 // ActivityStore persists and retrieves project activity log entries.
@@ -103,9 +133,14 @@ type ActivityStore interface {
 }
 
 type EvolutionStore interface {
+	// SaveEvolution upserts by (ProjectDir, WorkflowName, TriggerRunID):
+	// re-running the pipeline for the same trigger updates its record.
 	SaveEvolution(ctx context.Context, entry *EvolutionEntry) error
 	GetLastEvolution(ctx context.Context, projectDir, workflowName string) (*EvolutionEntry, error)
 	ListRunsSince(ctx context.Context, projectDir, workflowName, sinceRunID string) ([]*domain.Run, error)
+	// ListRunsSinceInStates is ListRunsSince restricted to runs whose state
+	// is one of states. An empty states slice matches every run.
+	ListRunsSinceInStates(ctx context.Context, projectDir, workflowName, sinceRunID string, states []domain.RunState) ([]*domain.Run, error)
 }
 
 // HumanPollRecord tracks the polling state of a human step within a run.
@@ -0,0 +1,114 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+)
+
+// OutputFilter selects how step output is cleaned before it is streamed,
+// logged, and scanned for a result marker. It is set per step with the
+// output_filter config key.
+type OutputFilter string
+
+const (
+	// OutputFilterStrip removes ANSI escape sequences. It is the default for
+	// agent steps.
+	OutputFilterStrip OutputFilter = "strip"
+	// OutputFilterCollapse also resolves carriage-return redraws to the text
+	// left on screen and drops consecutive repeats of a line, so spinners and
+	// progress bars leave one line instead of hundreds.
+	OutputFilterCollapse OutputFilter = "collapse"
+	// OutputFilterRaw passes output through unchanged. It is the default for
+	// script steps.
+	OutputFilterRaw OutputFilter = "raw"
+)
+
+// ParseOutputFilter returns the filter named by an output_filter config
+// value. An empty value selects OutputFilterStrip.
+func ParseOutputFilter(s string) (OutputFilter, error) {
+	switch f := OutputFilter(strings.TrimSpace(s)); f {
+	case "":
+		return OutputFilterStrip, nil
+	case OutputFilterStrip, OutputFilterCollapse, OutputFilterRaw:
+		return f, nil
+	default:
+		return "", fmt.Errorf("output_filter must be %q, %q or %q, got %q",
+			OutputFilterStrip, OutputFilterCollapse, OutputFilterRaw, s)
+	}
+}
+
+// StepOutputFilter returns the filter selected by step's output_filter.
+// Agent steps default to OutputFilterStrip; other steps default to
+// OutputFilterRaw, so script output is logged as the script wrote it unless
+// the step asks for cleanup.
+func StepOutputFilter(step *domain.Step) (OutputFilter, error) {
+	v := step.Config["output_filter"]
+	if strings.TrimSpace(v) == "" && step.Type != domain.StepTypeAgent {
+		return OutputFilterRaw, nil
+	}
+	return ParseOutputFilter(v)
+}
+
+// ansiSequence matches CSI sequences (colors, cursor movement, erase),
+// OSC sequences (titles, hyperlinks) terminated by BEL or ST, and the
+// remaining short escapes such as charset selection ("ESC ( B").
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)|\x1b[ -/]*[0-~]")
+
+// LineFilter applies an OutputFilter to output one line at a time, for
+// adapters that stream lines as they arrive. Collapsing repeats needs the
+// previous line, so use one LineFilter per output stream.
+type LineFilter struct {
+	mode OutputFilter
+	prev string
+}
+
+// NewLineFilter returns a LineFilter for mode.
+func NewLineFilter(mode OutputFilter) *LineFilter {
+	return &LineFilter{mode: mode}
+}
+
+// Line returns the cleaned line and whether to keep it. Lines dropped as
+// repeats report false.
+func (f *LineFilter) Line(line string) (string, bool) {
+	if f.mode == OutputFilterRaw {
+		return line, true
+	}
+	line = ansiSequence.ReplaceAllString(line, "")
+	if f.mode != OutputFilterCollapse {
+		return line, true
+	}
+	// A redrawn line is whatever the last carriage return left on screen;
+	// a trailing one is just a CRLF ending.
+	if i := strings.LastIndexByte(strings.TrimRight(line, "\r"), '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+	if line != "" && line == f.prev {
+		return "", false
+	}
+	f.prev = line
+	return line, true
+}
+
+// FilterOutput applies mode to a whole buffer of output.
+func FilterOutput(mode OutputFilter, output []byte) []byte {
+	if mode == OutputFilterRaw || len(output) == 0 {
+		return output
+	}
+	f := NewLineFilter(mode)
+	var buf bytes.Buffer
+	for i, line := range bytes.Split(output, []byte("\n")) {
+		clean, keep := f.Line(string(line))
+		if !keep {
+			continue
+		}
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(clean)
+	}
+	return buf.Bytes()
+}
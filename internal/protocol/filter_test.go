@@ -0,0 +1,62 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/cloche-dev/cloche/internal/domain"
+	"github.com/cloche-dev/cloche/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputFilter(t *testing.T) {
+	f, err := protocol.ParseOutputFilter("")
+	require.NoError(t, err)
+	assert.Equal(t, protocol.OutputFilterStrip, f)
+
+	f, err = protocol.ParseOutputFilter("collapse")
+	require.NoError(t, err)
+	assert.Equal(t, protocol.OutputFilterCollapse, f)
+
+	_, err = protocol.ParseOutputFilter("ansi")
+	assert.ErrorContains(t, err, "output_filter")
+}
+
+func TestStepOutputFilter_DefaultsByStepType(t *testing.T) {
+	agent := &domain.Step{Type: domain.StepTypeAgent, Config: map[string]string{}}
+	f, err := protocol.StepOutputFilter(agent)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.OutputFilterStrip, f)
+
+	script := &domain.Step{Type: domain.StepTypeScript, Config: map[string]string{}}
+	f, err = protocol.StepOutputFilter(script)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.OutputFilterRaw, f)
+
+	script.Config["output_filter"] = "collapse"
+	f, err = protocol.StepOutputFilter(script)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.OutputFilterCollapse, f)
+}
+
+func TestFilterOutput_StripsANSI(t *testing.T) {
+	output := []byte("\x1b[1;32mPASS\x1b[0m ok\n\x1b]0;title\x07\x1b[2Kdone\x1b(B\n")
+	assert.Equal(t, "PASS ok\ndone\n", string(protocol.FilterOutput(protocol.OutputFilterStrip, output)))
+}
+
+func TestFilterOutput_StripKeepsRepeatsAndRedraws(t *testing.T) {
+	output := []byte("a\na\n10%\r50%\n")
+	assert.Equal(t, string(output), string(protocol.FilterOutput(protocol.OutputFilterStrip, output)))
+}
+
+func TestFilterOutput_CollapsesProgress(t *testing.T) {
+	output := []byte("start\n\x1b[33m⠋\x1b[0m Thinking\r\x1b[33m⠙\x1b[0m Thinking\r\x1b[33m⠹\x1b[0m Working\n" +
+		"⠹ Working\n⠹ Working\nline\r\n\nend\n")
+	assert.Equal(t, "start\n⠹ Working\nline\r\n\nend\n",
+		string(protocol.FilterOutput(protocol.OutputFilterCollapse, output)))
+}
+
+func TestFilterOutput_Raw(t *testing.T) {
+	output := []byte("\x1b[31mred\x1b[0m\nx\nx\n")
+	assert.Equal(t, string(output), string(protocol.FilterOutput(protocol.OutputFilterRaw, output)))
+}
@@ -1,28 +1,41 @@
 package protocol
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 )
 
 const historyFile = ".cloche/history.log"
 
-// AppendHistory appends a step completion entry to the history log.
+// historyEntryPattern matches the header line AppendHistory writes for a
+// step completion, capturing the step name, result and, when recorded, the
+// run ID.
+var historyEntryPattern = regexp.MustCompile(`^\[[^\]]*\] step:(\S+) result:(\S+)(?: run:(\S+))?`)
+
+// AppendHistory appends a step completion entry for run runID to the history
+// log. The log is kept across runs in a workspace, so the run ID lets
+// StepResults tell this run's attempts from earlier ones.
 // For agent steps, pass nil for output (only the header is recorded).
 // For script steps, the full cleaned output is included, indented with "  | ".
-func AppendHistory(workDir, stepName, result string, isAgent bool, output []byte) {
+func AppendHistory(workDir, runID, stepName, result string, isAgent bool, output []byte) {
 	path := filepath.Join(workDir, historyFile)
 	_ = os.MkdirAll(filepath.Dir(path), 0755)
 
 	ts := time.Now().UTC().Format(time.RFC3339)
+	header := fmt.Sprintf("[%s] step:%s result:%s", ts, stepName, result)
+	if runID != "" {
+		header += " run:" + runID
+	}
 	var entry string
 	if isAgent {
-		entry = fmt.Sprintf("[%s] step:%s result:%s (agent)\n\n", ts, stepName, result)
+		entry = header + " (agent)\n\n"
 	} else {
-		entry = fmt.Sprintf("[%s] step:%s result:%s\n", ts, stepName, result)
+		entry = header + "\n"
 		if len(output) > 0 {
 			trimmed := strings.TrimRight(string(output), "\n")
 			if trimmed != "" {
@@ -57,3 +70,24 @@ func AppendHistoryMarker(workDir, marker string) {
 	defer f.Close()
 	_, _ = f.WriteString(entry)
 }
+
+// StepResults returns the results recorded in the history log for stepName
+// in run runID, oldest first. An empty runID matches every entry. It returns
+// nil when the log is missing or has no matching entries.
+func StepResults(workDir, runID, stepName string) []string {
+	f, err := os.Open(filepath.Join(workDir, historyFile))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var results []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := historyEntryPattern.FindStringSubmatch(scanner.Text())
+		if m != nil && m[1] == stepName && (runID == "" || m[3] == runID) {
+			results = append(results, m[2])
+		}
+	}
+	return results
+}
@@ -0,0 +1,34 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/cloche-dev/cloche/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepResults(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, protocol.StepResults(dir, "run-1", "fix"), "missing history log")
+
+	protocol.AppendHistoryMarker(dir, "workflow:start")
+	protocol.AppendHistory(dir, "run-1", "test", "fail", false, []byte("FAIL: TestAdd\n"))
+	protocol.AppendHistory(dir, "run-1", "fix", "fail", true, nil)
+	protocol.AppendHistory(dir, "run-1", "test", "fail", false, nil)
+	protocol.AppendHistory(dir, "run-1", "fix", "success", true, nil)
+
+	assert.Equal(t, []string{"fail", "success"}, protocol.StepResults(dir, "run-1", "fix"))
+	assert.Equal(t, []string{"fail", "fail"}, protocol.StepResults(dir, "run-1", "test"))
+	assert.Nil(t, protocol.StepResults(dir, "run-1", "implement"))
+}
+
+func TestStepResults_OnlyCountsTheGivenRun(t *testing.T) {
+	dir := t.TempDir()
+	protocol.AppendHistory(dir, "run-1", "fix", "fail", true, nil)
+	protocol.AppendHistory(dir, "run-1", "fix", "success", true, nil)
+	protocol.AppendHistory(dir, "run-2", "fix", "fail", true, nil)
+
+	assert.Equal(t, []string{"fail"}, protocol.StepResults(dir, "run-2", "fix"))
+	assert.Nil(t, protocol.StepResults(dir, "run-3", "fix"))
+	assert.Equal(t, []string{"fail", "success", "fail"}, protocol.StepResults(dir, "", "fix"))
+}
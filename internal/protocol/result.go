@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"bytes"
+	"os"
 	"strings"
 )
 
@@ -29,3 +30,13 @@ func ExtractResult(output []byte) (result string, cleanOutput []byte, found bool
 	}
 	return result, joined, found
 }
+
+// ExitCode returns the exit code of a finished process, or nil when the
+// process never ran to completion or was killed by a signal.
+func ExitCode(state *os.ProcessState) *int {
+	if state == nil || !state.Exited() {
+		return nil
+	}
+	code := state.ExitCode()
+	return &code
+}
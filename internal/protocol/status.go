@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/cloche-dev/cloche/internal/domain"
@@ -22,14 +23,18 @@ const (
 )
 
 type StatusMessage struct {
-	Type         MessageType `json:"type"`
-	StepName     string      `json:"step_name,omitempty"`
-	Result       string      `json:"result,omitempty"`
-	Message      string      `json:"message,omitempty"`
-	Timestamp    time.Time   `json:"timestamp"`
-	InputTokens  int64       `json:"input_tokens,omitempty"`
-	OutputTokens int64       `json:"output_tokens,omitempty"`
-	AgentName    string      `json:"agent_name,omitempty"`
+	Type MessageType `json:"type"`
+	// RunID and WorkflowName attribute the message to its run, so consumers of
+	// a shared stream or a persisted raw log can tell runs apart.
+	RunID        string    `json:"run_id,omitempty"`
+	WorkflowName string    `json:"workflow_name,omitempty"`
+	StepName     string    `json:"step_name,omitempty"`
+	Result       string    `json:"result,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	InputTokens  int64     `json:"input_tokens,omitempty"`
+	OutputTokens int64     `json:"output_tokens,omitempty"`
+	AgentName    string    `json:"agent_name,omitempty"`
 }
 
 // flusher is an optional interface for writers that support explicit flushing.
@@ -37,7 +42,21 @@ type flusher interface {
 	Flush() error
 }
 
+// StatusSink receives each status message a StatusWriter emits, already
+// stamped with its run, workflow and timestamp. The writer delivers messages
+// one at a time, in emission order.
+type StatusSink interface {
+	Status(msg StatusMessage)
+}
+
+// StatusWriter encodes status messages as JSON lines and hands them to its
+// Sink, if set. It is safe for concurrent use by steps running in parallel.
 type StatusWriter struct {
+	RunID        string     // stamped on every message
+	WorkflowName string     // stamped on every message
+	Sink         StatusSink // optional: receives every message
+
+	mu  sync.Mutex
 	w   io.Writer
 	enc *json.Encoder
 }
@@ -46,6 +65,12 @@ func NewStatusWriter(w io.Writer) *StatusWriter {
 	return &StatusWriter{w: w, enc: json.NewEncoder(w)}
 }
 
+// NewStatusSinkWriter returns a StatusWriter that only delivers messages to
+// sink, without encoding them.
+func NewStatusSinkWriter(sink StatusSink) *StatusWriter {
+	return &StatusWriter{Sink: sink}
+}
+
 func (s *StatusWriter) StepStarted(stepName string) {
 	s.write(StatusMessage{Type: MsgStepStarted, StepName: stepName})
 }
@@ -77,14 +102,43 @@ func (s *StatusWriter) Error(stepName, message string) {
 }
 
 func (s *StatusWriter) write(msg StatusMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg.RunID = s.RunID
+	msg.WorkflowName = s.WorkflowName
 	msg.Timestamp = time.Now()
-	_ = s.enc.Encode(msg)
-	// Flush the underlying writer if it supports it, to ensure real-time delivery.
-	if f, ok := s.w.(flusher); ok {
-		_ = f.Flush()
+	if s.enc != nil {
+		_ = s.enc.Encode(msg)
+		// Flush the underlying writer if it supports it, to ensure real-time delivery.
+		if f, ok := s.w.(flusher); ok {
+			_ = f.Flush()
+		}
+	}
+	if s.Sink != nil {
+		s.Sink.Status(msg)
 	}
 }
 
+// StatusRecorder is a StatusSink that keeps every message it receives, for
+// tests that assert on the exact sequence a component emits.
+type StatusRecorder struct {
+	mu   sync.Mutex
+	msgs []StatusMessage
+}
+
+func (r *StatusRecorder) Status(msg StatusMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, msg)
+}
+
+// Messages returns a copy of the messages recorded so far.
+func (r *StatusRecorder) Messages() []StatusMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]StatusMessage(nil), r.msgs...)
+}
+
 func ParseStatusStream(data []byte) ([]StatusMessage, error) {
 	var msgs []StatusMessage
 	dec := json.NewDecoder(bytes.NewReader(data))
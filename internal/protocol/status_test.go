@@ -2,6 +2,7 @@ package protocol_test
 
 import (
 	"bytes"
+	"sync"
 	"testing"
 
 	"github.com/cloche-dev/cloche/internal/protocol"
@@ -58,3 +59,72 @@ func TestStatusWriter_LogMessage(t *testing.T) {
 	assert.Equal(t, protocol.MsgLog, msgs[0].Type)
 	assert.Equal(t, "running tests...", msgs[0].Message)
 }
+
+func TestStatusWriter_StampsRunAndWorkflow(t *testing.T) {
+	var buf bytes.Buffer
+	w := protocol.NewStatusWriter(&buf)
+	w.RunID = "develop-abc123"
+	w.WorkflowName = "develop"
+
+	w.StepStarted("code")
+	w.Log("code", "editing files")
+	w.StepCompleted("code", "success", nil)
+
+	msgs, err := protocol.ParseStatusStream(buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+	for _, msg := range msgs {
+		assert.Equal(t, "develop-abc123", msg.RunID, "%s message", msg.Type)
+		assert.Equal(t, "develop", msg.WorkflowName, "%s message", msg.Type)
+	}
+	assert.Contains(t, buf.String(), `"run_id":"develop-abc123","workflow_name":"develop"`)
+}
+
+func TestStatusWriter_SinkReceivesMessages(t *testing.T) {
+	var buf bytes.Buffer
+	rec := &protocol.StatusRecorder{}
+	w := protocol.NewStatusWriter(&buf)
+	w.RunID = "develop-abc123"
+	w.Sink = rec
+
+	w.StepStarted("code")
+	w.StepCompleted("code", "success", nil)
+
+	msgs := rec.Messages()
+	require.Len(t, msgs, 2)
+	assert.Equal(t, protocol.MsgStepStarted, msgs[0].Type)
+	assert.Equal(t, protocol.MsgStepCompleted, msgs[1].Type)
+	assert.Equal(t, "success", msgs[1].Result)
+	assert.Equal(t, "develop-abc123", msgs[1].RunID)
+	assert.False(t, msgs[1].Timestamp.IsZero())
+
+	encoded, err := protocol.ParseStatusStream(buf.Bytes())
+	require.NoError(t, err)
+	assert.Len(t, encoded, 2, "the JSON stream is still written")
+
+	sinkOnly := protocol.NewStatusSinkWriter(rec)
+	sinkOnly.Log("code", "hello")
+	assert.Len(t, rec.Messages(), 3)
+}
+
+func TestStatusWriter_ConcurrentStepsKeepLinesIntact(t *testing.T) {
+	var buf bytes.Buffer
+	w := protocol.NewStatusWriter(&buf)
+	w.RunID = "run-1"
+
+	var wg sync.WaitGroup
+	for _, step := range []string{"lint", "test"} {
+		wg.Add(1)
+		go func(step string) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				w.Log(step, "line")
+			}
+		}(step)
+	}
+	wg.Wait()
+
+	msgs, err := protocol.ParseStatusStream(buf.Bytes())
+	require.NoError(t, err)
+	assert.Len(t, msgs, 100)
+}
@@ -70,6 +70,13 @@ func (r *kvTestRuntime) Logs(_ context.Context, _ string) (string, error) { retu
 func (r *kvTestRuntime) Inspect(_ context.Context, _ string) (*ports.ContainerStatus, error) {
 	return &ports.ContainerStatus{Running: true}, nil
 }
+func (r *kvTestRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (r *kvTestRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (r *kvTestRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, nil
 }
@@ -137,6 +137,13 @@ func (f *fakeContainerRuntime) Logs(_ context.Context, _ string) (string, error)
 func (f *fakeContainerRuntime) Inspect(_ context.Context, _ string) (*ports.ContainerStatus, error) {
 	return &ports.ContainerStatus{Running: true}, nil
 }
+func (f *fakeContainerRuntime) Pull(_ context.Context, _ string) error {
+	return nil
+}
+func (f *fakeContainerRuntime) Exec(_ context.Context, _ string, _ []string) (string, int, error) {
+	return "", 0, nil
+}
+
 func (f *fakeContainerRuntime) Attach(_ context.Context, _ string) (io.ReadWriteCloser, error) {
 	return nil, nil
 }
@@ -212,6 +219,24 @@ func (s *fakeRunStore) UpdateRun(_ context.Context, run *domain.Run) error {
 	s.runs[run.ID] = run
 	return nil
 }
+func (s *fakeRunStore) UpdateRunState(_ context.Context, id string, state domain.RunState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.runs[id]; ok {
+		r.State = state
+		return nil
+	}
+	return fmt.Errorf("run not found")
+}
+func (s *fakeRunStore) UpdateActiveSteps(_ context.Context, id string, steps []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.runs[id]; ok {
+		r.ActiveSteps = append([]string(nil), steps...)
+		return nil
+	}
+	return fmt.Errorf("run not found")
+}
 func (s *fakeRunStore) DeleteRun(_ context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -227,6 +252,9 @@ func (s *fakeRunStore) ListRunsByProject(_ context.Context, _ string, _ time.Tim
 func (s *fakeRunStore) ListRunsFiltered(_ context.Context, _ domain.RunListFilter) ([]*domain.Run, error) {
 	return nil, nil
 }
+func (s *fakeRunStore) DeleteRunsWhere(_ context.Context, _ domain.RunListFilter) ([]*domain.Run, error) {
+	return nil, nil
+}
 func (s *fakeRunStore) ListProjects(_ context.Context) ([]string, error) { return nil, nil }
 func (s *fakeRunStore) ListChildRuns(_ context.Context, parentRunID string) ([]*domain.Run, error) {
 	s.mu.Lock()
@@ -855,7 +883,7 @@ func TestWorkflowRefactor_AgentDisconnect(t *testing.T) {
 	}
 	ch := make(chan stepOutcome, 1)
 	go func() {
-		result, err := sess.ExecuteStep(ctx, step, false)
+		result, err := sess.ExecuteStep(ctx, step, false, nil)
 		ch <- stepOutcome{result, err}
 	}()
 